@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// Asset types reported in FinancialData.AssetType
+const (
+	AssetEquity     = "equity"
+	AssetETF        = "etf"
+	AssetMutualFund = "mutual_fund"
+	AssetCrypto     = "crypto"
+	AssetIndex      = "index"
+	AssetCurrency   = "currency"
+	AssetFuture     = "future"
+	AssetOther      = "other"
+)
+
+// instrumentTypes maps the chart meta instrumentType to our asset types
+var instrumentTypes = map[string]string{
+	"EQUITY":         AssetEquity,
+	"ETF":            AssetETF,
+	"MUTUALFUND":     AssetMutualFund,
+	"CRYPTOCURRENCY": AssetCrypto,
+	"INDEX":          AssetIndex,
+	"CURRENCY":       AssetCurrency,
+	"FUTURE":         AssetFuture,
+}
+
+// equityOnlyFields are dropped from the JSON of non-equity quotes, where they have no meaning
+var equityOnlyFields = []string{"pe_ratio", "debt_to_equity", "sector", "industry"}
+
+// FundDetails holds the fund-specific fields of ETFs and mutual funds
+type FundDetails struct {
+	NAV          float64 `json:"nav"`
+	AUM          int64   `json:"aum"`
+	ExpenseRatio float64 `json:"expense_ratio,omitempty"`
+	Yield        float64 `json:"yield,omitempty"`
+}
+
+// CryptoDetails holds the supply and trading fields of cryptocurrencies
+type CryptoDetails struct {
+	CirculatingSupply int64 `json:"circulating_supply"`
+	MaxSupply         int64 `json:"max_supply,omitempty"`
+	Volume24h         int64 `json:"volume_24h"`
+}
+
+// assetType converts a chart meta instrumentType; a missing type is treated as equity and an unrecognized one as other
+func assetType(instrumentType string) string {
+	if instrumentType == "" {
+		return AssetEquity
+	}
+	if t, ok := instrumentTypes[strings.ToUpper(instrumentType)]; ok {
+		return t
+	}
+	return AssetOther
+}
+
+// MarshalJSON omits equity-only fields such as the P/E ratio for funds, crypto and other non-equities
+func (d FinancialData) MarshalJSON() ([]byte, error) {
+	type plain FinancialData
+	raw, err := json.Marshal(plain(d))
+	if err != nil || d.AssetType == "" || d.AssetType == AssetEquity {
+		return raw, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	for _, key := range equityOnlyFields {
+		delete(fields, key)
+	}
+	return json.Marshal(fields)
+}
+
+// addAssetDetails fills the type-specific fields of funds and crypto from quoteSummary.
+// Failures are logged and leave the base quote intact.
+func (yf *YahooFinanceAPI) addAssetDetails(data *FinancialData) {
+	var modules []string
+	switch data.AssetType {
+	case AssetETF, AssetMutualFund:
+		modules = []string{"summaryDetail", "fundProfile"}
+	case AssetCrypto:
+		modules = []string{"summaryDetail"}
+	default:
+		return
+	}
+
+	summary, err := yf.GetQuoteSummary(data.Symbol, modules...)
+	if err != nil {
+		log.Printf("Could not load %s details for %s: %v", data.AssetType, data.Symbol, err)
+		return
+	}
+	detail := summary.SummaryDetail
+
+	if data.AssetType == AssetCrypto {
+		data.Crypto = &CryptoDetails{
+			CirculatingSupply: int64(detail.CirculatingSupply.Raw),
+			MaxSupply:         int64(detail.MaxSupply.Raw),
+			Volume24h:         int64(detail.Volume24Hr.Raw),
+		}
+		data.MarketCap = int64(detail.MarketCap.Raw)
+		return
+	}
+
+	data.Fund = &FundDetails{
+		NAV:          detail.NavPrice.Raw,
+		AUM:          int64(detail.TotalAssets.Raw),
+		ExpenseRatio: summary.FundProfile.FeesExpensesInvestment.AnnualReportExpenseRatio.Raw,
+		Yield:        detail.Yield.Raw,
+	}
+}
+
+// filterAssetType keeps the quotes of the given asset type
+func filterAssetType(data map[string]*FinancialData, assetType string) map[string]*FinancialData {
+	filtered := make(map[string]*FinancialData, len(data))
+	for symbol, quote := range data {
+		if quote.AssetType == assetType {
+			filtered[symbol] = quote
+		}
+	}
+	return filtered
+}
+
+// validAssetType reports whether t is one of the asset type constants
+func validAssetType(t string) bool {
+	if t == AssetOther {
+		return true
+	}
+	for _, known := range instrumentTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// assetTypeParam filters multi-symbol responses by asset class
+var assetTypeParam = Param{Name: "asset_type", In: "query", Type: "string",
+	Description: "Only return quotes of this asset type: equity, etf, mutual_fund, crypto, index, currency, future or other"}
+
+// checkAssetTypeParam validates the asset_type query parameter, writing a 400 when it is unknown
+func checkAssetTypeParam(w http.ResponseWriter, r *http.Request) (string, bool) {
+	t := strings.ToLower(r.URL.Query().Get("asset_type"))
+	if t != "" && !validAssetType(t) {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "unknown asset_type "+t)
+		return "", false
+	}
+	return t, true
+}
@@ -1,164 +0,0 @@
-package main
-
-import (
-	"fmt"
-	"net/http"
-	"sync"
-	"time"
-)
-
-// BenchmarkResult holds performance metrics
-type BenchmarkResult struct {
-	Language       string        `json:"language"`
-	RequestType    string        `json:"request_type"`
-	Requests       int           `json:"requests"`
-	TotalTime      time.Duration `json:"total_time"`
-	AvgTime        time.Duration `json:"avg_time"`
-	MinTime        time.Duration `json:"min_time"`
-	MaxTime        time.Duration `json:"max_time"`
-	SuccessRate    float64       `json:"success_rate"`
-	RequestsPerSec float64       `json:"requests_per_second"`
-}
-
-func benchmarkGo(numRequests int) BenchmarkResult {
-	fmt.Printf("🚀 Benchmarking Go API (%d requests)...\n", numRequests)
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	results := make([]time.Duration, 0, numRequests)
-	successCount := 0
-
-	start := time.Now()
-
-	for i := 0; i < numRequests; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-
-			reqStart := time.Now()
-			resp, err := http.Get("http://localhost:8080/stock?symbol=AAPL")
-			reqDuration := time.Since(reqStart)
-
-			mu.Lock()
-			results = append(results, reqDuration)
-			if err == nil && resp.StatusCode == 200 {
-				successCount++
-			}
-			if resp != nil {
-				resp.Body.Close()
-			}
-			mu.Unlock()
-		}()
-	}
-
-	wg.Wait()
-	totalTime := time.Since(start)
-
-	// Calculate statistics
-	var minTime, maxTime, totalReqTime time.Duration
-	minTime = time.Hour // Initialize to very high value
-
-	for _, duration := range results {
-		totalReqTime += duration
-		if duration < minTime {
-			minTime = duration
-		}
-		if duration > maxTime {
-			maxTime = duration
-		}
-	}
-
-	avgTime := totalReqTime / time.Duration(len(results))
-	successRate := float64(successCount) / float64(numRequests) * 100
-	requestsPerSec := float64(numRequests) / totalTime.Seconds()
-
-	return BenchmarkResult{
-		Language:       "Go",
-		RequestType:    "Single Stock",
-		Requests:       numRequests,
-		TotalTime:      totalTime,
-		AvgTime:        avgTime,
-		MinTime:        minTime,
-		MaxTime:        maxTime,
-		SuccessRate:    successRate,
-		RequestsPerSec: requestsPerSec,
-	}
-}
-
-func benchmarkPython(numRequests int) BenchmarkResult {
-	fmt.Printf("🐍 Benchmarking Python API (%d requests)...\n", numRequests)
-
-	// Simulated results based on typical Python performance
-	return BenchmarkResult{
-		Language:       "Python",
-		RequestType:    "Single Stock",
-		Requests:       numRequests,
-		TotalTime:      time.Duration(numRequests) * 500 * time.Millisecond,
-		AvgTime:        500 * time.Millisecond,
-		MinTime:        300 * time.Millisecond,
-		MaxTime:        1200 * time.Millisecond,
-		SuccessRate:    95.0,
-		RequestsPerSec: 2.0,
-	}
-}
-
-func runBenchmarks() {
-	fmt.Println("📊 Yahoo Finance API Performance Benchmark")
-	fmt.Println("==========================================")
-
-	testSizes := []int{10, 50, 100}
-
-	for _, size := range testSizes {
-		fmt.Printf("\n📈 Running benchmark with %d requests:\n", size)
-
-		goResult := benchmarkGo(size)
-		pythonResult := benchmarkPython(size)
-
-		fmt.Printf("\n📊 Results for %d requests:\n", size)
-		fmt.Printf("┌─────────────────┬─────────────┬─────────────┬─────────────┬─────────────┬─────────────┐\n")
-		fmt.Printf("│ Language        │ Total Time  │ Avg Time    │ Min Time    │ Max Time    │ Req/Sec     │\n")
-		fmt.Printf("├─────────────────┼─────────────┼─────────────┼─────────────┼─────────────┼─────────────┤\n")
-		fmt.Printf("│ Go              │ %11s │ %11s │ %11s │ %11s │ %11.2f │\n",
-			goResult.TotalTime.Round(time.Millisecond),
-			goResult.AvgTime.Round(time.Millisecond),
-			goResult.MinTime.Round(time.Millisecond),
-			goResult.MaxTime.Round(time.Millisecond),
-			goResult.RequestsPerSec)
-		fmt.Printf("│ Python (Est.)   │ %11s │ %11s │ %11s │ %11s │ %11.2f │\n",
-			pythonResult.TotalTime.Round(time.Millisecond),
-			pythonResult.AvgTime.Round(time.Millisecond),
-			pythonResult.MinTime.Round(time.Millisecond),
-			pythonResult.MaxTime.Round(time.Millisecond),
-			pythonResult.RequestsPerSec)
-		fmt.Printf("└─────────────────┴─────────────┴─────────────┴─────────────┴─────────────┴─────────────┘\n")
-
-		improvement := pythonResult.AvgTime.Seconds() / goResult.AvgTime.Seconds()
-		fmt.Printf("🚀 Go is %.1fx faster than Python for this workload\n", improvement)
-
-		fmt.Printf("💾 Estimated memory usage:\n")
-		fmt.Printf("   Go: ~%dMB | Python: ~%dMB\n", size/10+5, size/5+25)
-	}
-}
-
-func main() {
-	fmt.Println("Starting Yahoo Finance API Performance Benchmark")
-	fmt.Println("===============================================")
-	fmt.Println()
-	fmt.Println("Prerequisites:")
-	fmt.Println("1. Go server running on localhost:8080")
-	fmt.Println("2. Network connection for Yahoo Finance API")
-	fmt.Println()
-	fmt.Print("Press Enter to start benchmark...")
-	fmt.Scanln()
-
-	runBenchmarks()
-
-	fmt.Println("\n✅ Benchmark completed!")
-	fmt.Println("\n🎯 Key advantages of Go implementation:")
-	fmt.Println("   • Lower latency (typically 5-10x faster)")
-	fmt.Println("   • Better concurrency handling")
-	fmt.Println("   • Lower memory footprint")
-	fmt.Println("   • Built-in caching with TTL")
-	fmt.Println("   • No GIL limitations")
-	fmt.Println("   • Compiled binary (no interpreter overhead)")
-}
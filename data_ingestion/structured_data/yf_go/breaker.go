@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Circuit states
+const (
+	circuitClosed   = "closed"
+	circuitOpen     = "open"
+	circuitHalfOpen = "half_open"
+)
+
+// ErrCircuitOpen marks requests refused because the provider's circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitOpenError is returned instead of calling a provider that keeps failing
+type CircuitOpenError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+// Error implements error
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s circuit open, retry in %v", e.Provider, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap lets callers match with errors.Is(err, ErrCircuitOpen)
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitStatus is the reported state of one provider's breaker
+type CircuitStatus struct {
+	Provider            string     `json:"provider"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+}
+
+// CircuitBreaker stops calls to a provider after threshold consecutive failures
+// and allows a single trial call once cooldown has passed
+type CircuitBreaker struct {
+	provider  string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	lastErr  string
+	trial    bool // a half-open trial request is in flight
+}
+
+// NewCircuitBreaker creates a closed breaker for provider
+func NewCircuitBreaker(provider string, threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{provider: provider, threshold: threshold, cooldown: cooldown, state: circuitClosed}
+}
+
+// Allow reports whether a call may go ahead, moving an open circuit to half-open after the cooldown
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case circuitOpen:
+		if wait := cb.cooldown - time.Since(cb.openedAt); wait > 0 {
+			return &CircuitOpenError{Provider: cb.provider, RetryAfter: wait}
+		}
+		cb.state = circuitHalfOpen
+		cb.trial = true
+		return nil
+	case circuitHalfOpen:
+		if cb.trial {
+			return &CircuitOpenError{Provider: cb.provider, RetryAfter: cb.cooldown}
+		}
+		cb.trial = true
+	}
+	return nil
+}
+
+// Record feeds the outcome of an allowed call back into the breaker
+func (cb *CircuitBreaker) Record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trial = false
+	if err == nil {
+		cb.state = circuitClosed
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	cb.lastErr = err.Error()
+	if cb.state == circuitHalfOpen || cb.failures >= cb.threshold {
+		if cb.state != circuitOpen {
+			log.Printf("Circuit for %s opened after %d consecutive failures: %v", cb.provider, cb.failures, err)
+		}
+		cb.state = circuitOpen
+		cb.openedAt = time.Now()
+	}
+}
+
+// Status returns a snapshot of the breaker
+func (cb *CircuitBreaker) Status() CircuitStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	status := CircuitStatus{
+		Provider:            cb.provider,
+		State:               cb.state,
+		ConsecutiveFailures: cb.failures,
+		LastError:           cb.lastErr,
+	}
+	if cb.state != circuitClosed {
+		openedAt := cb.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+// providerHosts names the upstream provider behind each host so that hosts sharing
+// an outage, like the two Yahoo query endpoints, share a breaker
+var providerHosts = map[string]string{
+	"query1.finance.yahoo.com":  "yahoo_finance",
+	"query2.finance.yahoo.com":  "yahoo_finance",
+	"api.stlouisfed.org":        "fred",
+	"api.gleif.org":             "gleif",
+	"api.openfigi.com":          "openfigi",
+	"finnhub.io":                "finnhub",
+	"www.nasdaqtrader.com":      "nasdaq_trader",
+	"raw.githubusercontent.com": "github",
+}
+
+// providerName returns the provider for host, or the host itself when it is not a known provider
+func providerName(host string) string {
+	if name, ok := providerHosts[host]; ok {
+		return name
+	}
+	return host
+}
+
+// breakerTransport guards an http.RoundTripper with per-provider circuit breakers. Transport
+// errors, 5xx and 429 responses count as failures; other statuses mean the provider is answering.
+type breakerTransport struct {
+	breakers *Breakers
+	next     http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	breaker := t.breakers.Get(providerName(req.URL.Hostname()))
+	if err := breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil:
+		breaker.Record(err)
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		breaker.Record(&UpstreamStatusError{Provider: breaker.provider, StatusCode: resp.StatusCode})
+	default:
+		breaker.Record(nil)
+	}
+	return resp, err
+}
+
+// Breakers holds one circuit breaker per upstream provider
+type Breakers struct {
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// NewBreakers creates an empty breaker set sharing one threshold and cooldown
+func NewBreakers(threshold int, cooldown time.Duration) *Breakers {
+	return &Breakers{threshold: threshold, cooldown: cooldown, breakers: make(map[string]*CircuitBreaker)}
+}
+
+// Get returns the breaker for provider, creating it on first use
+func (b *Breakers) Get(provider string) *CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cb, ok := b.breakers[provider]
+	if !ok {
+		cb = NewCircuitBreaker(provider, b.threshold, b.cooldown)
+		b.breakers[provider] = cb
+	}
+	return cb
+}
+
+// Guard routes client's requests through the breakers and returns client
+func (b *Breakers) Guard(client *http.Client) *http.Client {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &breakerTransport{breakers: b, next: next}
+	return client
+}
+
+// Statuses returns every breaker's state, sorted by provider
+func (b *Breakers) Statuses() []CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statuses := make([]CircuitStatus, 0, len(b.breakers))
+	for _, cb := range b.breakers {
+		statuses = append(statuses, cb.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
@@ -0,0 +1,337 @@
+// Command bench load-tests a running yf_go server. It requests each endpoint template once per
+// symbol to measure cold-cache latency, then drives the same URLs at a fixed concurrency for the
+// configured duration to measure warm-cache latency and throughput, reporting p50/p95/p99.
+//
+//	go run ./cmd/bench -base-url http://localhost:8080 -concurrency 20 -duration 30s \
+//	    -endpoint '/stock?symbol={symbol}' -endpoint '/z-score?symbol={symbol}' \
+//	    -symbols AAPL,MSFT,JPM -json results.json -csv results.csv
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scenario names
+const (
+	scenarioCold = "cold"
+	scenarioWarm = "warm"
+)
+
+// defaultEndpoints are benchmarked when no -endpoint flag is given
+var defaultEndpoints = []string{
+	"/stock?symbol={symbol}",
+	"/credit-metrics?symbol={symbol}",
+	"/z-score?symbol={symbol}",
+	"/volatility?symbol={symbol}",
+}
+
+// endpointList collects repeated -endpoint flags
+type endpointList []string
+
+// String implements flag.Value
+func (e *endpointList) String() string {
+	return strings.Join(*e, " ")
+}
+
+// Set implements flag.Value
+func (e *endpointList) Set(value string) error {
+	if !strings.HasPrefix(value, "/") {
+		return fmt.Errorf("endpoint %q must start with /", value)
+	}
+	*e = append(*e, value)
+	return nil
+}
+
+// target is one concrete URL and the endpoint template it came from
+type target struct {
+	endpoint string
+	url      string
+}
+
+// sample is the outcome of one request
+type sample struct {
+	endpoint string
+	latency  time.Duration
+	status   int
+	err      error
+}
+
+// Result summarizes one scenario for one endpoint template
+type Result struct {
+	Scenario       string  `json:"scenario"`
+	Endpoint       string  `json:"endpoint"`
+	Requests       int     `json:"requests"`
+	Errors         int     `json:"errors"` // transport errors and non-2xx responses
+	DurationSec    float64 `json:"duration_seconds"`
+	RequestsPerSec float64 `json:"requests_per_second"`
+	MinMS          float64 `json:"min_ms"`
+	MeanMS         float64 `json:"mean_ms"`
+	P50MS          float64 `json:"p50_ms"`
+	P95MS          float64 `json:"p95_ms"`
+	P99MS          float64 `json:"p99_ms"`
+	MaxMS          float64 `json:"max_ms"`
+}
+
+// Report is the full benchmark output written by -json
+type Report struct {
+	BaseURL     string    `json:"base_url"`
+	Concurrency int       `json:"concurrency"`
+	Duration    string    `json:"duration"`
+	Symbols     []string  `json:"symbols"`
+	StartedAt   time.Time `json:"started_at"`
+	Results     []Result  `json:"results"`
+}
+
+func main() {
+	var endpoints endpointList
+	baseURL := flag.String("base-url", "http://localhost:8080", "server to benchmark")
+	concurrency := flag.Int("concurrency", 10, "concurrent workers")
+	duration := flag.Duration("duration", 30*time.Second, "length of the warm scenario")
+	symbols := flag.String("symbols", "AAPL,MSFT,JPM,F,T", "comma-separated symbols substituted for {symbol}")
+	scenario := flag.String("scenario", "both", "cold, warm or both")
+	timeout := flag.Duration("timeout", 30*time.Second, "per-request timeout")
+	jsonPath := flag.String("json", "", "write the report as JSON to this file")
+	csvPath := flag.String("csv", "", "write the results as CSV to this file")
+	flag.Var(&endpoints, "endpoint", "endpoint template such as '/stock?symbol={symbol}' (repeatable)")
+	flag.Parse()
+
+	if len(endpoints) == 0 {
+		endpoints = defaultEndpoints
+	}
+	if *concurrency < 1 || *duration <= 0 {
+		log.Fatal("concurrency must be at least 1 and duration positive")
+	}
+	if *scenario != "both" && *scenario != scenarioCold && *scenario != scenarioWarm {
+		log.Fatalf("unknown scenario %q", *scenario)
+	}
+
+	symbolList := strings.Split(*symbols, ",")
+	targets := expand(strings.TrimRight(*baseURL, "/"), endpoints, symbolList)
+	if len(targets) == 0 {
+		log.Fatal("no endpoints to benchmark")
+	}
+	client := &http.Client{
+		Timeout:   *timeout,
+		Transport: &http.Transport{MaxIdleConnsPerHost: *concurrency},
+	}
+
+	report := Report{
+		BaseURL:     *baseURL,
+		Concurrency: *concurrency,
+		Duration:    duration.String(),
+		Symbols:     symbolList,
+		StartedAt:   time.Now().UTC(),
+	}
+
+	// The first request for each URL misses the server cache, so the cold pass doubles as
+	// priming for the warm scenario. Run cold first on a freshly started server for clean numbers.
+	coldStart := time.Now()
+	cold := runOnce(client, targets, *concurrency)
+	if *scenario != scenarioWarm {
+		report.Results = append(report.Results, summarize(scenarioCold, cold, time.Since(coldStart))...)
+	}
+
+	if *scenario != scenarioCold {
+		log.Printf("Running warm scenario for %v at concurrency %d", *duration, *concurrency)
+		warmStart := time.Now()
+		warm := runFor(client, targets, *concurrency, *duration)
+		report.Results = append(report.Results, summarize(scenarioWarm, warm, time.Since(warmStart))...)
+	}
+
+	printTable(os.Stdout, report.Results)
+	if *jsonPath != "" {
+		if err := writeJSON(*jsonPath, report); err != nil {
+			log.Fatalf("Failed to write JSON results: %v", err)
+		}
+	}
+	if *csvPath != "" {
+		if err := writeCSV(*csvPath, report.Results); err != nil {
+			log.Fatalf("Failed to write CSV results: %v", err)
+		}
+	}
+}
+
+// expand substitutes every symbol into every endpoint template
+func expand(baseURL string, endpoints, symbols []string) []target {
+	var targets []target
+	for _, endpoint := range endpoints {
+		if !strings.Contains(endpoint, "{symbol}") {
+			targets = append(targets, target{endpoint: endpoint, url: baseURL + endpoint})
+			continue
+		}
+		for _, symbol := range symbols {
+			symbol = strings.TrimSpace(symbol)
+			if symbol == "" {
+				continue
+			}
+			targets = append(targets, target{endpoint: endpoint, url: baseURL + strings.ReplaceAll(endpoint, "{symbol}", symbol)})
+		}
+	}
+	return targets
+}
+
+// do issues one request, draining the body so the connection is reused
+func do(client *http.Client, t target) sample {
+	start := time.Now()
+	resp, err := client.Get(t.url)
+	if err != nil {
+		return sample{endpoint: t.endpoint, latency: time.Since(start), err: err}
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	s := sample{endpoint: t.endpoint, latency: time.Since(start), status: resp.StatusCode, err: err}
+	if s.err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		s.err = fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return s
+}
+
+// runOnce requests every target exactly once with bounded concurrency
+func runOnce(client *http.Client, targets []target, concurrency int) []sample {
+	samples := make([]sample, len(targets))
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, t target) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			samples[i] = do(client, t)
+		}(i, t)
+	}
+	wg.Wait()
+	return samples
+}
+
+// runFor cycles workers through the targets until duration has passed
+func runFor(client *http.Client, targets []target, concurrency int, duration time.Duration) []sample {
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+
+	var mu sync.Mutex
+	var samples []sample
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(offset int) {
+			defer wg.Done()
+			var local []sample
+			for i := offset; ctx.Err() == nil; i++ {
+				local = append(local, do(client, targets[i%len(targets)]))
+			}
+			mu.Lock()
+			samples = append(samples, local...)
+			mu.Unlock()
+		}(w)
+	}
+	wg.Wait()
+	return samples
+}
+
+// summarize groups samples by endpoint template and computes latency percentiles
+func summarize(scenario string, samples []sample, elapsed time.Duration) []Result {
+	byEndpoint := make(map[string][]sample)
+	var order []string
+	for _, s := range samples {
+		if _, ok := byEndpoint[s.endpoint]; !ok {
+			order = append(order, s.endpoint)
+		}
+		byEndpoint[s.endpoint] = append(byEndpoint[s.endpoint], s)
+	}
+
+	results := make([]Result, 0, len(order))
+	for _, endpoint := range order {
+		group := byEndpoint[endpoint]
+		latencies := make([]time.Duration, len(group))
+		var total time.Duration
+		result := Result{Scenario: scenario, Endpoint: endpoint, Requests: len(group), DurationSec: elapsed.Seconds()}
+		for i, s := range group {
+			latencies[i] = s.latency
+			total += s.latency
+			if s.err != nil {
+				result.Errors++
+			}
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		result.RequestsPerSec = float64(len(group)) / elapsed.Seconds()
+		result.MinMS = ms(latencies[0])
+		result.MeanMS = ms(total / time.Duration(len(group)))
+		result.P50MS = ms(percentile(latencies, 50))
+		result.P95MS = ms(percentile(latencies, 95))
+		result.P99MS = ms(percentile(latencies, 99))
+		result.MaxMS = ms(latencies[len(latencies)-1])
+		results = append(results, result)
+	}
+	return results
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+// ms converts a duration to fractional milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// printTable writes a fixed-width summary of the results
+func printTable(w io.Writer, results []Result) {
+	fmt.Fprintf(w, "%-8s %-36s %8s %7s %9s %9s %9s %9s %9s\n",
+		"SCENARIO", "ENDPOINT", "REQS", "ERRORS", "REQ/S", "P50 MS", "P95 MS", "P99 MS", "MAX MS")
+	for _, r := range results {
+		fmt.Fprintf(w, "%-8s %-36s %8d %7d %9.1f %9.1f %9.1f %9.1f %9.1f\n",
+			r.Scenario, r.Endpoint, r.Requests, r.Errors, r.RequestsPerSec, r.P50MS, r.P95MS, r.P99MS, r.MaxMS)
+	}
+}
+
+// writeJSON writes the report to path
+func writeJSON(path string, report Report) error {
+	raw, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// writeCSV writes one row per scenario and endpoint to path
+func writeCSV(path string, results []Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"scenario", "endpoint", "requests", "errors", "duration_seconds", "requests_per_second",
+		"min_ms", "mean_ms", "p50_ms", "p95_ms", "p99_ms", "max_ms"})
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	for _, r := range results {
+		w.Write([]string{r.Scenario, r.Endpoint, strconv.Itoa(r.Requests), strconv.Itoa(r.Errors), f(r.DurationSec),
+			f(r.RequestsPerSec), f(r.MinMS), f(r.MeanMS), f(r.P50MS), f(r.P95MS), f(r.P99MS), f(r.MaxMS)})
+	}
+	w.Flush()
+	return errors.Join(w.Error(), file.Close())
+}
@@ -0,0 +1,17 @@
+package main
+
+import "golang.org/x/sync/singleflight"
+
+// coalesce runs load once for concurrent callers asking for the same key, so a burst of cache
+// misses for one symbol and data type makes a single upstream call whose result they all share.
+// Keys follow the cache keys, e.g. stock_AAPL or history_AAPL_1y.
+func coalesce[T any](group *singleflight.Group, key string, load func() (T, error)) (T, error) {
+	value, err, _ := group.Do(key, func() (interface{}, error) {
+		return load()
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return value.(T), nil
+}
@@ -0,0 +1,458 @@
+// Package config loads yf_go settings from defaults, an optional YAML file,
+// environment variables and command-line flags, in increasing precedence.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds every tunable of the service
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	Upstream  UpstreamConfig  `yaml:"upstream"`
+	Cache     CacheConfig     `yaml:"cache"`
+	Providers ProvidersConfig `yaml:"providers"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Guard     GuardConfig     `yaml:"guard"`
+	Demo      DemoConfig      `yaml:"demo"`
+	Warmup    WarmupConfig    `yaml:"warmup"`
+	Console   ConsoleConfig   `yaml:"console"`
+	Symbols   SymbolsConfig   `yaml:"symbols"`
+
+	Constituents ConstituentsConfig `yaml:"constituents"`
+	Events       EventsConfig       `yaml:"events"`
+
+	ErrorReporting ErrorReportingConfig `yaml:"error_reporting"`
+}
+
+// ServerConfig controls the HTTP listener
+type ServerConfig struct {
+	ListenAddr      string        `yaml:"listen_addr"`
+	ReadTimeout     time.Duration `yaml:"read_timeout"`
+	WriteTimeout    time.Duration `yaml:"write_timeout"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+
+	// Responses at least this large are gzipped for clients that accept it; 0 disables compression
+	CompressionMinBytes int `yaml:"compression_min_bytes"`
+}
+
+// UpstreamConfig controls calls to Yahoo Finance
+type UpstreamConfig struct {
+	ChartURL    string        `yaml:"chart_url"` // base URL of the chart API
+	QueryURL    string        `yaml:"query_url"` // base URL of the quoteSummary and search APIs
+	Timeout     time.Duration `yaml:"timeout"`
+	UserAgent   string        `yaml:"user_agent"`
+	Concurrency int           `yaml:"concurrency"`
+
+	// A provider's circuit opens after BreakerThreshold consecutive failures and
+	// lets a trial request through once BreakerCooldown has passed
+	BreakerThreshold int           `yaml:"breaker_threshold"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`
+}
+
+// CacheConfig controls the in-memory quote cache, its optional on-disk layer and watchlist prefetching
+type CacheConfig struct {
+	TTL              time.Duration `yaml:"ttl"`
+	PrefetchInterval time.Duration `yaml:"prefetch_interval"`
+	DiskDir          string        `yaml:"disk_dir"` // empty keeps the cache in memory only
+}
+
+// ProvidersConfig holds third-party API keys
+type ProvidersConfig struct {
+	FREDAPIKey     string `yaml:"fred_api_key"`
+	OpenFIGIAPIKey string `yaml:"openfigi_api_key"`
+	FinnhubAPIKey  string `yaml:"finnhub_api_key"`
+}
+
+// StorageConfig holds file paths and database URLs
+type StorageConfig struct {
+	WatchlistFile       string `yaml:"watchlist_file"`
+	CompanyRegistryFile string `yaml:"company_registry_file"`
+	QuarantineFile      string `yaml:"quarantine_file"`
+	QuotesDBURL         string `yaml:"quotes_db_url"`
+}
+
+// GuardConfig controls the market data outlier guard
+type GuardConfig struct {
+	Action         string  `yaml:"action"`
+	MaxMovePercent float64 `yaml:"max_move_percent"`
+	MaxPrice       float64 `yaml:"max_price"`
+	LiquidVolume   int64   `yaml:"liquid_volume"`
+}
+
+// DemoConfig controls the public demo deployment mode
+type DemoConfig struct {
+	Enabled           bool     `yaml:"enabled"`
+	Symbols           []string `yaml:"symbols"`
+	RequestsPerMinute int      `yaml:"requests_per_minute"`
+}
+
+// WarmupConfig controls the pre-fetch that runs before the service reports ready
+type WarmupConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	MaxPriority int           `yaml:"max_priority"`
+	Timeout     time.Duration `yaml:"timeout"`
+}
+
+// ConsoleConfig controls the Swagger UI and query console, which require Token as the basic auth password
+type ConsoleConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Token   string `yaml:"token"`
+}
+
+// SymbolsConfig controls the symbol universe used to reject unknown tickers before calling Yahoo
+type SymbolsConfig struct {
+	UniverseURL     string        `yaml:"universe_url"` // empty disables universe checks
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// ConstituentsConfig points the index constituent feeds at CSV downloads; an empty URL serves
+// only the bundled snapshot for that index, if there is one
+type ConstituentsConfig struct {
+	SP500URL        string        `yaml:"sp500_url"`
+	DJIURL          string        `yaml:"dji_url"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// EventsConfig controls quote change detection; events go to the webhooks and the /events history
+type EventsConfig struct {
+	Enabled             bool     `yaml:"enabled"`
+	PriceMovePercent    float64  `yaml:"price_move_percent"`    // move from the last event's price; 0 disables
+	VolumeSpikeMultiple float64  `yaml:"volume_spike_multiple"` // multiple of 20-day average volume; 0 disables
+	WebhookURLs         []string `yaml:"webhook_urls"`
+	WebhookSecret       string   `yaml:"webhook_secret"` // signs bodies as X-Signature-256 when set
+}
+
+// ErrorReportingConfig points captured panics at a Sentry-compatible DSN
+type ErrorReportingConfig struct {
+	SentryDSN   string `yaml:"sentry_dsn"`
+	Environment string `yaml:"environment"`
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			ListenAddr:      ":8080",
+			ReadTimeout:     15 * time.Second,
+			WriteTimeout:    60 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 15 * time.Second,
+
+			CompressionMinBytes: 1024,
+		},
+		Upstream: UpstreamConfig{
+			ChartURL:    "https://query1.finance.yahoo.com",
+			QueryURL:    "https://query2.finance.yahoo.com",
+			Timeout:     10 * time.Second,
+			UserAgent:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36",
+			Concurrency: 5,
+
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+		Cache: CacheConfig{
+			TTL:              5 * time.Minute,
+			PrefetchInterval: 4 * time.Minute,
+		},
+		Storage: StorageConfig{
+			WatchlistFile:       "data/watchlists.json",
+			CompanyRegistryFile: "data/companies.json",
+		},
+		Guard: GuardConfig{
+			Action:         "reject",
+			MaxMovePercent: 50,
+			MaxPrice:       1_000_000,
+			LiquidVolume:   1_000_000,
+		},
+		Demo: DemoConfig{
+			Symbols:           []string{"AAPL", "MSFT", "JPM", "F", "T"},
+			RequestsPerMinute: 20,
+		},
+		Warmup: WarmupConfig{
+			Enabled:     true,
+			MaxPriority: 1,
+			Timeout:     30 * time.Second,
+		},
+		Symbols: SymbolsConfig{
+			UniverseURL:     "https://www.nasdaqtrader.com/dynamic/SymDir/nasdaqtraded.txt",
+			RefreshInterval: 24 * time.Hour,
+		},
+		Constituents: ConstituentsConfig{
+			SP500URL:        "https://raw.githubusercontent.com/datasets/s-and-p-500-companies/main/data/constituents.csv",
+			RefreshInterval: 24 * time.Hour,
+		},
+		Events: EventsConfig{
+			PriceMovePercent:    5,
+			VolumeSpikeMultiple: 3,
+		},
+		ErrorReporting: ErrorReportingConfig{
+			Environment: "production",
+		},
+	}
+}
+
+// Load builds the configuration from args (normally os.Args[1:]) and validates it
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+
+	fs := flag.NewFlagSet("yf_go", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("YF_CONFIG"), "path to a YAML config file")
+	addr := fs.String("addr", "", "listen address (e.g. :8080)")
+	cacheTTL := fs.Duration("cache-ttl", 0, "quote cache TTL")
+	concurrency := fs.Int("concurrency", 0, "maximum concurrent upstream requests")
+	timeout := fs.Duration("upstream-timeout", 0, "upstream request timeout")
+	demo := fs.Bool("demo", false, "run in public demo mode")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := cfg.loadFile(*configFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	// Flags win over the file and the environment
+	if *addr != "" {
+		cfg.Server.ListenAddr = *addr
+	}
+	if *cacheTTL != 0 {
+		cfg.Cache.TTL = *cacheTTL
+	}
+	if *concurrency != 0 {
+		cfg.Upstream.Concurrency = *concurrency
+	}
+	if *timeout != 0 {
+		cfg.Upstream.Timeout = *timeout
+	}
+	if *demo {
+		cfg.Demo.Enabled = true
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadFile overlays a YAML file onto the current values
+func (c *Config) loadFile(path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	if err := yaml.Unmarshal(raw, c); err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv overlays environment variables onto the current values
+func (c *Config) applyEnv() error {
+	setString(&c.Server.ListenAddr, "YF_LISTEN_ADDR")
+	setString(&c.Upstream.UserAgent, "YF_USER_AGENT")
+	setString(&c.Upstream.ChartURL, "YF_CHART_URL")
+	setString(&c.Upstream.QueryURL, "YF_QUERY_URL")
+	setString(&c.Providers.FREDAPIKey, "FRED_API_KEY")
+	setString(&c.Providers.OpenFIGIAPIKey, "OPENFIGI_API_KEY")
+	setString(&c.Providers.FinnhubAPIKey, "FINNHUB_API_KEY")
+	setString(&c.Storage.WatchlistFile, "WATCHLIST_FILE")
+	setString(&c.Storage.CompanyRegistryFile, "COMPANY_REGISTRY_FILE")
+	setString(&c.Storage.QuarantineFile, "QUARANTINE_FILE")
+	setString(&c.Storage.QuotesDBURL, "QUOTES_DB_URL")
+	setString(&c.Cache.DiskDir, "YF_CACHE_DIR")
+	setString(&c.Guard.Action, "OUTLIER_ACTION")
+	setString(&c.ErrorReporting.SentryDSN, "SENTRY_DSN")
+	setString(&c.Console.Token, "YF_CONSOLE_TOKEN")
+	if value := os.Getenv("YF_CONSOLE_ENABLED"); value != "" {
+		c.Console.Enabled = value == "true"
+	}
+	if value, ok := os.LookupEnv("YF_SYMBOL_UNIVERSE_URL"); ok {
+		c.Symbols.UniverseURL = strings.TrimSpace(value)
+	}
+	if value, ok := os.LookupEnv("YF_SP500_CONSTITUENTS_URL"); ok {
+		c.Constituents.SP500URL = strings.TrimSpace(value)
+	}
+	if value, ok := os.LookupEnv("YF_DJI_CONSTITUENTS_URL"); ok {
+		c.Constituents.DJIURL = strings.TrimSpace(value)
+	}
+	setString(&c.ErrorReporting.Environment, "SENTRY_ENVIRONMENT")
+	if value := os.Getenv("YF_DEMO_MODE"); value != "" {
+		c.Demo.Enabled = value == "true"
+	}
+	if value := os.Getenv("YF_WARMUP_ENABLED"); value != "" {
+		c.Warmup.Enabled = value == "true"
+	}
+	if value := os.Getenv("YF_EVENTS_ENABLED"); value != "" {
+		c.Events.Enabled = value == "true"
+	}
+	if value := os.Getenv("YF_EVENT_WEBHOOKS"); value != "" {
+		c.Events.WebhookURLs = strings.Split(value, ",")
+	}
+	setString(&c.Events.WebhookSecret, "YF_EVENT_WEBHOOK_SECRET")
+	if value := os.Getenv("YF_DEMO_SYMBOLS"); value != "" {
+		c.Demo.Symbols = strings.Split(value, ",")
+	}
+
+	var errs []error
+	errs = append(errs,
+		setDuration(&c.Upstream.Timeout, "YF_UPSTREAM_TIMEOUT"),
+		setDuration(&c.Cache.TTL, "YF_CACHE_TTL"),
+		setDuration(&c.Cache.PrefetchInterval, "YF_PREFETCH_INTERVAL"),
+		setInt(&c.Upstream.Concurrency, "YF_CONCURRENCY"),
+		setInt(&c.Upstream.BreakerThreshold, "YF_BREAKER_THRESHOLD"),
+		setDuration(&c.Upstream.BreakerCooldown, "YF_BREAKER_COOLDOWN"),
+		setInt(&c.Server.CompressionMinBytes, "YF_COMPRESSION_MIN_BYTES"),
+		setFloat(&c.Guard.MaxMovePercent, "OUTLIER_MAX_MOVE_PCT"),
+		setInt(&c.Demo.RequestsPerMinute, "YF_DEMO_RPM"),
+		setInt(&c.Warmup.MaxPriority, "YF_WARMUP_MAX_PRIORITY"),
+		setDuration(&c.Warmup.Timeout, "YF_WARMUP_TIMEOUT"),
+		setDuration(&c.Symbols.RefreshInterval, "YF_SYMBOL_UNIVERSE_REFRESH"),
+		setDuration(&c.Constituents.RefreshInterval, "YF_CONSTITUENTS_REFRESH"),
+		setFloat(&c.Events.PriceMovePercent, "YF_EVENT_PRICE_MOVE_PCT"),
+		setFloat(&c.Events.VolumeSpikeMultiple, "YF_EVENT_VOLUME_MULTIPLE"),
+	)
+	return errors.Join(errs...)
+}
+
+// Validate checks the configuration for values the service cannot run with
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.ListenAddr == "" {
+		errs = append(errs, errors.New("server.listen_addr must be set"))
+	}
+	if c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 || c.Server.IdleTimeout <= 0 {
+		errs = append(errs, errors.New("server timeouts must be positive"))
+	}
+	if c.Server.CompressionMinBytes < 0 {
+		errs = append(errs, errors.New("server.compression_min_bytes must not be negative"))
+	}
+	if c.Upstream.Timeout <= 0 {
+		errs = append(errs, errors.New("upstream.timeout must be positive"))
+	}
+	if c.Upstream.ChartURL == "" || c.Upstream.QueryURL == "" {
+		errs = append(errs, errors.New("upstream.chart_url and upstream.query_url must be set"))
+	}
+	if c.Upstream.UserAgent == "" {
+		errs = append(errs, errors.New("upstream.user_agent must be set"))
+	}
+	if c.Upstream.Concurrency < 1 || c.Upstream.Concurrency > 100 {
+		errs = append(errs, fmt.Errorf("upstream.concurrency must be between 1 and 100, got %d", c.Upstream.Concurrency))
+	}
+	if c.Upstream.BreakerThreshold < 1 || c.Upstream.BreakerCooldown <= 0 {
+		errs = append(errs, errors.New("upstream.breaker_threshold must be at least 1 and upstream.breaker_cooldown positive"))
+	}
+	if c.Cache.TTL < time.Second {
+		errs = append(errs, fmt.Errorf("cache.ttl must be at least 1s, got %v", c.Cache.TTL))
+	}
+	if c.Cache.PrefetchInterval <= 0 {
+		errs = append(errs, errors.New("cache.prefetch_interval must be positive"))
+	}
+	if c.Guard.Action != "reject" && c.Guard.Action != "flag" {
+		errs = append(errs, fmt.Errorf("guard.action must be reject or flag, got %q", c.Guard.Action))
+	}
+	if c.Guard.MaxMovePercent <= 0 {
+		errs = append(errs, errors.New("guard.max_move_percent must be positive"))
+	}
+	if c.Storage.WatchlistFile == "" || c.Storage.CompanyRegistryFile == "" {
+		errs = append(errs, errors.New("storage file paths must be set"))
+	}
+
+	if c.Warmup.Enabled && (c.Warmup.MaxPriority < 1 || c.Warmup.Timeout <= 0) {
+		errs = append(errs, errors.New("warmup.max_priority must be at least 1 and warmup.timeout positive"))
+	}
+	if c.Console.Enabled && len(c.Console.Token) < 16 {
+		errs = append(errs, errors.New("console.token must be at least 16 characters when the console is enabled"))
+	}
+	if c.Symbols.UniverseURL != "" && c.Symbols.RefreshInterval < time.Minute {
+		errs = append(errs, errors.New("symbols.refresh_interval must be at least 1m"))
+	}
+	if c.Constituents.RefreshInterval < time.Minute {
+		errs = append(errs, errors.New("constituents.refresh_interval must be at least 1m"))
+	}
+	if c.Events.Enabled {
+		if c.Events.PriceMovePercent < 0 || c.Events.VolumeSpikeMultiple < 0 {
+			errs = append(errs, errors.New("events thresholds must not be negative"))
+		}
+		if c.Events.VolumeSpikeMultiple > 0 && c.Events.VolumeSpikeMultiple <= 1 {
+			errs = append(errs, errors.New("events.volume_spike_multiple must be greater than 1"))
+		}
+		for _, u := range c.Events.WebhookURLs {
+			if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+				errs = append(errs, fmt.Errorf("events.webhook_urls: %q is not an http(s) URL", u))
+			}
+		}
+	}
+	if c.Demo.Enabled {
+		if len(c.Demo.Symbols) == 0 {
+			errs = append(errs, errors.New("demo.symbols must list at least one symbol"))
+		}
+		if c.Demo.RequestsPerMinute < 1 {
+			errs = append(errs, errors.New("demo.requests_per_minute must be positive"))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+func setString(dst *string, key string) {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		*dst = value
+	}
+}
+
+func setDuration(dst *time.Duration, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = d
+	return nil
+}
+
+func setInt(dst *int, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func setFloat(dst *float64, key string) error {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = f
+	return nil
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/subtle"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// consoleEndpoint is one GET operation offered in the query console
+type consoleEndpoint struct {
+	Path    string
+	Summary string
+	Params  []Param
+}
+
+// swaggerPage loads Swagger UI from a CDN and points it at the generated spec
+const swaggerPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Yahoo Finance Go API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <p style="font-family:sans-serif;margin:1em">Reference below; try queries in the <a href="/console">console</a>.</p>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({url: "/openapi.json", dom_id: "#swagger-ui"});</script>
+</body>
+</html>`
+
+// consolePage is a minimal form for running GET endpoints and viewing the JSON they return
+var consolePage = template.Must(template.New("console").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>API console</title>
+  <style>
+    body { font-family: sans-serif; margin: 2em; max-width: 72em; }
+    label { display: block; margin: .4em 0; }
+    pre { background: #f4f4f4; padding: 1em; overflow: auto; max-height: 40em; }
+    .meta { color: #666; }
+  </style>
+</head>
+<body>
+  <h1>API console</h1>
+  <p class="meta">Runs read-only queries against this service. Full reference: <a href="/docs">/docs</a>.</p>
+  <label>Endpoint
+    <select id="endpoint">
+      {{range .}}<option value="{{.Path}}" data-params="{{range $i, $p := .Params}}{{if $i}},{{end}}{{$p.Name}}{{end}}">{{.Path}} — {{.Summary}}</option>{{end}}
+    </select>
+  </label>
+  <div id="params"></div>
+  <button id="run">Run</button>
+  <p class="meta" id="status"></p>
+  <pre id="output"></pre>
+  <script>
+    const select = document.getElementById("endpoint");
+    const params = document.getElementById("params");
+    function renderParams() {
+      params.innerHTML = "";
+      const names = select.selectedOptions[0].dataset.params;
+      if (!names) return;
+      for (const name of names.split(",")) {
+        const label = document.createElement("label");
+        label.textContent = name + " ";
+        const input = document.createElement("input");
+        input.name = name;
+        label.appendChild(input);
+        params.appendChild(label);
+      }
+    }
+    select.addEventListener("change", renderParams);
+    renderParams();
+    document.getElementById("run").addEventListener("click", async () => {
+      const query = new URLSearchParams();
+      for (const input of params.querySelectorAll("input")) {
+        if (input.value) query.set(input.name, input.value);
+      }
+      const url = select.value + (query.toString() ? "?" + query : "");
+      const started = performance.now();
+      const resp = await fetch(url);
+      const text = await resp.text();
+      document.getElementById("status").textContent =
+        "GET " + url + " → " + resp.status + " in " + Math.round(performance.now() - started) + " ms";
+      try { document.getElementById("output").textContent = JSON.stringify(JSON.parse(text), null, 2); }
+      catch (e) { document.getElementById("output").textContent = text; }
+    });
+  </script>
+</body>
+</html>`))
+
+// consoleEndpoints lists the GET operations without path parameters, sorted by path
+func consoleEndpoints(routes []Route) []consoleEndpoint {
+	var endpoints []consoleEndpoint
+	for _, route := range routes {
+		if strings.Contains(route.Path, "{") {
+			continue
+		}
+		for _, op := range route.Operations {
+			if op.Method == http.MethodGet {
+				endpoints = append(endpoints, consoleEndpoint{Path: route.Path, Summary: op.Summary, Params: op.Params})
+			}
+		}
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Path < endpoints[j].Path })
+	return endpoints
+}
+
+// docsHandler serves Swagger UI
+func docsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerPage))
+}
+
+// consoleHandler serves the query console for the given routes
+func consoleHandler(routes []Route) http.HandlerFunc {
+	endpoints := consoleEndpoints(routes)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := consolePage.Execute(w, endpoints); err != nil {
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, err.Error())
+		}
+	}
+}
+
+// requireToken protects the interactive pages with HTTP basic auth, so browsers prompt for
+// the token; any user name is accepted and the password must match the configured token
+func requireToken(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok || subtle.ConstantTimeCompare([]byte(password), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="yf_go console"`)
+			writeError(w, r, http.StatusUnauthorized, CodeUnauthorized, "console token required")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// bundledConstituents holds membership snapshots used until, or instead of, a live download
+//
+//go:embed constituents/*.csv
+var bundledConstituents embed.FS
+
+// ErrUnknownIndex marks index symbols without a constituent feed
+var ErrUnknownIndex = errors.New("unknown index")
+
+// IndexMember is one constituent of an index
+type IndexMember struct {
+	Symbol string `json:"symbol"`
+	Name   string `json:"name,omitempty"`
+	Sector string `json:"sector,omitempty"`
+}
+
+// IndexConstituents is the membership of one index
+type IndexConstituents struct {
+	Index     string        `json:"index"`
+	Name      string        `json:"name"`
+	Count     int           `json:"count"`
+	Symbols   []string      `json:"symbols"`
+	Members   []IndexMember `json:"members"`
+	Source    string        `json:"source"` // live or bundled
+	UpdatedAt string        `json:"updated_at"`
+}
+
+// indexFeed describes where the members of an index come from. URL is a CSV download refreshed
+// periodically; bundled names an embedded CSV in the same layout used when no download has succeeded.
+type indexFeed struct {
+	index        string
+	name         string
+	url          string
+	bundled      string
+	bundledAsOf  string
+	symbolColumn string
+	nameColumn   string
+	sectorColumn string
+}
+
+// ConstituentStore serves index memberships, refreshing live feeds in the background. It is
+// the one tracker of membership: the ingestion service keeps membership history and
+// change events from what /constituents serves
+type ConstituentStore struct {
+	feeds     map[string]indexFeed
+	client    *http.Client
+	userAgent string
+	freshness *Freshness
+
+	mu      sync.RWMutex
+	indices map[string]*IndexConstituents
+}
+
+// NewConstituentStore loads the bundled snapshots for the S&P 500 and Dow Jones feeds
+func NewConstituentStore(sp500URL, djiURL, userAgent string) *ConstituentStore {
+	cs := &ConstituentStore{
+		feeds: map[string]indexFeed{
+			"^GSPC": {
+				index: "^GSPC", name: "S&P 500", url: sp500URL,
+				symbolColumn: "Symbol", nameColumn: "Security", sectorColumn: "GICS Sector",
+			},
+			"^DJI": {
+				index: "^DJI", name: "Dow Jones Industrial Average", url: djiURL,
+				bundled: "constituents/dji.csv", bundledAsOf: "2024-11-08",
+				symbolColumn: "Symbol", nameColumn: "Security", sectorColumn: "GICS Sector",
+			},
+		},
+		client:    &http.Client{Timeout: 30 * time.Second},
+		userAgent: userAgent,
+		indices:   make(map[string]*IndexConstituents),
+	}
+
+	for _, feed := range cs.feeds {
+		if feed.bundled == "" {
+			continue
+		}
+		raw, err := bundledConstituents.ReadFile(feed.bundled)
+		if err != nil {
+			log.Printf("Error reading bundled constituents for %s: %v", feed.index, err)
+			continue
+		}
+		members, err := parseConstituents(bytes.NewReader(raw), feed)
+		if err != nil {
+			log.Printf("Error parsing bundled constituents for %s: %v", feed.index, err)
+			continue
+		}
+		cs.indices[feed.index] = newIndexConstituents(feed, members, "bundled", feed.bundledAsOf)
+	}
+	return cs
+}
+
+// Start downloads the live feeds and refreshes them every interval
+func (cs *ConstituentStore) Start(interval time.Duration) {
+	go func() {
+		defer recoverPanic(map[string]string{"component": "constituents"})
+		for {
+			cs.Refresh()
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Refresh downloads every feed with a URL, keeping the previous membership when a download fails
+func (cs *ConstituentStore) Refresh() {
+	for _, feed := range cs.feeds {
+		if feed.url == "" {
+			continue
+		}
+		members, err := cs.download(feed)
+		if err != nil {
+			log.Printf("Constituent refresh for %s failed: %v", feed.index, err)
+			continue
+		}
+
+		cs.mu.Lock()
+		cs.indices[feed.index] = newIndexConstituents(feed, members, "live", time.Now().Format(time.RFC3339))
+		cs.mu.Unlock()
+		cs.freshness.Touch("constituents")
+		log.Printf("Loaded %d constituents for %s", len(members), feed.index)
+	}
+}
+
+// download fetches and parses one feed's CSV
+func (cs *ConstituentStore) download(feed indexFeed) ([]IndexMember, error) {
+	req, err := http.NewRequest("GET", feed.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", cs.userAgent)
+
+	resp, err := cs.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UpstreamStatusError{Provider: feed.name + " constituents", StatusCode: resp.StatusCode}
+	}
+	return parseConstituents(resp.Body, feed)
+}
+
+// parseConstituents reads a CSV with a header row naming the feed's symbol, name and sector columns
+func parseConstituents(r io.Reader, feed indexFeed) ([]IndexMember, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading constituents: %w", err)
+	}
+	if len(rows) < 2 {
+		return nil, errors.New("constituent list is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	symbolCol, ok := columns[feed.symbolColumn]
+	if !ok {
+		return nil, fmt.Errorf("missing symbol column %q", feed.symbolColumn)
+	}
+	nameCol, hasName := columns[feed.nameColumn]
+	sectorCol, hasSector := columns[feed.sectorColumn]
+
+	seen := make(map[string]bool)
+	var members []IndexMember
+	for _, row := range rows[1:] {
+		if symbolCol >= len(row) {
+			continue
+		}
+		// Share classes are published as BRK.B in the same US notation as the Nasdaq listings
+		symbol, err := symbols.From(row[symbolCol], symbols.Nasdaq)
+		if err != nil || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+
+		member := IndexMember{Symbol: symbol}
+		if hasName && nameCol < len(row) {
+			member.Name = strings.TrimSpace(row[nameCol])
+		}
+		if hasSector && sectorCol < len(row) {
+			member.Sector = strings.TrimSpace(row[sectorCol])
+		}
+		members = append(members, member)
+	}
+	if len(members) == 0 {
+		return nil, errors.New("constituent list has no symbols")
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Symbol < members[j].Symbol })
+	return members, nil
+}
+
+// newIndexConstituents builds the response for a parsed membership list
+func newIndexConstituents(feed indexFeed, members []IndexMember, source, updatedAt string) *IndexConstituents {
+	symbols := make([]string, len(members))
+	for i, member := range members {
+		symbols[i] = member.Symbol
+	}
+	return &IndexConstituents{
+		Index:     feed.index,
+		Name:      feed.name,
+		Count:     len(members),
+		Symbols:   symbols,
+		Members:   members,
+		Source:    source,
+		UpdatedAt: updatedAt,
+	}
+}
+
+// Indices returns the supported index symbols
+func (cs *ConstituentStore) Indices() []string {
+	indices := make([]string, 0, len(cs.feeds))
+	for index := range cs.feeds {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+	return indices
+}
+
+// Get returns the current membership of index, accepting the symbol with or without its caret.
+// It returns nil without an error when the index is supported but nothing has loaded yet.
+func (cs *ConstituentStore) Get(index string) (*IndexConstituents, error) {
+	index = strings.ToUpper(strings.TrimSpace(index))
+	if !strings.HasPrefix(index, "^") {
+		index = "^" + index
+	}
+	if _, ok := cs.feeds[index]; !ok {
+		return nil, fmt.Errorf("%q: %w", index, ErrUnknownIndex)
+	}
+
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.indices[index], nil
+}
+
+// handleConstituents returns the member symbols of an index
+func (s *Server) handleConstituents(w http.ResponseWriter, r *http.Request) {
+	index := r.URL.Query().Get("index")
+	if index == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "index parameter is required")
+		return
+	}
+
+	start := time.Now()
+	data, err := s.constituents.Get(index)
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest,
+			fmt.Sprintf("%v; supported indices: %s", err, strings.Join(s.constituents.Indices(), ", ")))
+		return
+	}
+	if data == nil {
+		writeError(w, r, http.StatusServiceUnavailable, CodeUpstreamOutage, "constituents for "+index+" have not loaded yet")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
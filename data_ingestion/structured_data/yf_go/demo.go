@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"yahoo-finance-go/config"
+)
+
+// demoBlockedPaths expose operational data that should not be shown in public demos
+var demoBlockedPaths = map[string]bool{
+	"/quarantine":  true,
+	"/identifiers": true,
+}
+
+// rateLimiter is a per-client token bucket
+type rateLimiter struct {
+	rate    float64 // tokens per second
+	burst   float64
+	buckets map[string]*tokenBucket
+	mu      sync.Mutex
+}
+
+// tokenBucket tracks the remaining tokens for one client
+type tokenBucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// newRateLimiter creates a limiter allowing perMinute requests per client with a matching burst
+func newRateLimiter(perMinute int) *rateLimiter {
+	rl := &rateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(perMinute),
+		buckets: make(map[string]*tokenBucket),
+	}
+	go rl.cleanup()
+	return rl
+}
+
+// Allow consumes a token for key and reports whether the request may proceed
+func (rl *rateLimiter) Allow(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: rl.burst, lastSeen: now}
+		rl.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.lastSeen).Seconds() * rl.rate
+	if bucket.tokens > rl.burst {
+		bucket.tokens = rl.burst
+	}
+	bucket.lastSeen = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// cleanup drops buckets for clients that have been idle long enough to be full again
+func (rl *rateLimiter) cleanup() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		rl.mu.Lock()
+		for key, bucket := range rl.buckets {
+			if time.Since(bucket.lastSeen) > 10*time.Minute {
+				delete(rl.buckets, key)
+			}
+		}
+		rl.mu.Unlock()
+	}
+}
+
+// demoResponseWriter buffers a response so it can be watermarked before sending
+type demoResponseWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records the status code until the body is flushed
+func (dw *demoResponseWriter) WriteHeader(status int) {
+	dw.status = status
+}
+
+// Write buffers the response body
+func (dw *demoResponseWriter) Write(b []byte) (int, error) {
+	return dw.body.Write(b)
+}
+
+// demoMiddleware restricts the API to a symbol whitelist, rate limits per client and watermarks JSON responses
+func demoMiddleware(cfg config.DemoConfig, next http.Handler) http.Handler {
+	allowed := make(map[string]bool)
+	for _, symbol := range cfg.Symbols {
+		allowed[strings.ToUpper(strings.TrimSpace(symbol))] = true
+	}
+	limiter := newRateLimiter(cfg.RequestsPerMinute)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Demo-Mode", "true")
+
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeError(w, r, http.StatusForbidden, CodeForbidden, "demo mode is read-only")
+			return
+		}
+		if demoBlockedPaths[r.URL.Path] {
+			writeError(w, r, http.StatusForbidden, CodeForbidden, "endpoint not available in demo mode")
+			return
+		}
+		if !limiter.Allow(clientIP(r)) {
+			w.Header().Set("Retry-After", "60")
+			writeError(w, r, http.StatusTooManyRequests, CodeRateLimited, "demo rate limit exceeded")
+			return
+		}
+
+		query := r.URL.Query()
+		requested := strings.Split(query.Get("symbols"), ",")
+		requested = append(requested, query.Get("symbol"))
+		for _, symbol := range requested {
+			symbol = strings.ToUpper(strings.TrimSpace(symbol))
+			if symbol != "" && !allowed[symbol] {
+				writeErrorResponse(w, r, http.StatusForbidden, ErrorResponse{
+					Code:    CodeForbidden,
+					Message: "symbol is not available in demo mode",
+					Symbol:  symbol,
+				})
+				return
+			}
+		}
+
+		dw := &demoResponseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(dw, r)
+
+		body := dw.body.Bytes()
+		if strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+			// The watermark changes the payload, so the handler's content hash no longer applies
+			if marked := watermarkJSON(body); !bytes.Equal(marked, body) {
+				body = marked
+				w.Header().Del("ETag")
+			}
+		}
+		w.WriteHeader(dw.status)
+		w.Write(body)
+	})
+}
+
+// watermarkJSON adds "demo": true to a JSON object body, leaving other payloads untouched
+func watermarkJSON(body []byte) []byte {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return body
+	}
+	obj["demo"] = json.RawMessage("true")
+
+	marked, err := json.Marshal(obj)
+	if err != nil {
+		return body
+	}
+	return append(marked, '\n')
+}
+
+// clientIP returns the remote address without the port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
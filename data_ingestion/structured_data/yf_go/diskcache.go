@@ -0,0 +1,155 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// diskEntry is the file layout of one persisted cache entry
+type diskEntry struct {
+	Key       string          `json:"key"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DiskCache persists cache entries as JSON files below the memory cache, so a restart can serve
+// still-fresh data instead of refetching every watchlisted symbol from Yahoo at once.
+// A nil DiskCache is a disabled cache.
+type DiskCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// NewDiskCache creates dir if needed, removes the temp files a crash mid-write left
+// behind and starts removing expired entries from it
+func NewDiskCache(dir string, ttl time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	dc := &DiskCache{dir: dir, ttl: ttl}
+	dc.removeTemp()
+	go dc.cleanup()
+	return dc, nil
+}
+
+// path returns the file for key; keys are hashed because symbols may contain ^ and =
+func (dc *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dc.dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+// Load decodes an unexpired entry for key into dst and returns its expiry
+func (dc *DiskCache) Load(key string, dst interface{}) (time.Time, bool) {
+	if dc == nil {
+		return time.Time{}, false
+	}
+
+	raw, err := os.ReadFile(dc.path(key))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var entry diskEntry
+	if err := json.Unmarshal(raw, &entry); err != nil || entry.Key != key || time.Now().After(entry.ExpiresAt) {
+		return time.Time{}, false
+	}
+	if err := json.Unmarshal(entry.Data, dst); err != nil {
+		log.Printf("Error decoding disk cache entry %s: %v", key, err)
+		return time.Time{}, false
+	}
+	return entry.ExpiresAt, true
+}
+
+// Store writes value under key, expiring after the cache TTL
+func (dc *DiskCache) Store(key string, value interface{}) {
+	if dc == nil {
+		return
+	}
+	dc.StoreUntil(key, value, time.Now().Add(dc.ttl))
+}
+
+// StoreUntil writes value under key, expiring at expiresAt
+func (dc *DiskCache) StoreUntil(key string, value interface{}, expiresAt time.Time) {
+	if dc == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error encoding disk cache entry %s: %v", key, err)
+		return
+	}
+	raw, err := json.Marshal(diskEntry{Key: key, ExpiresAt: expiresAt, Data: data})
+	if err != nil {
+		log.Printf("Error encoding disk cache entry %s: %v", key, err)
+		return
+	}
+
+	// Write then rename so a crash never leaves a truncated entry behind
+	tmp, err := os.CreateTemp(dc.dir, ".entry-*")
+	if err != nil {
+		log.Printf("Error writing disk cache entry %s: %v", key, err)
+		return
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		log.Printf("Error writing disk cache entry %s: %v", key, err)
+		return
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), dc.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		log.Printf("Error writing disk cache entry %s: %v", key, err)
+	}
+}
+
+// removeTemp removes the temp files of writes that never got renamed into place
+func (dc *DiskCache) removeTemp() {
+	temps, err := filepath.Glob(filepath.Join(dc.dir, ".entry-*"))
+	if err != nil {
+		return
+	}
+	for _, path := range temps {
+		if err := os.Remove(path); err != nil {
+			log.Printf("Error removing stale disk cache file %s: %v", path, err)
+		}
+	}
+}
+
+// cleanup removes expired and unreadable entries every ten minutes
+func (dc *DiskCache) cleanup() {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		files, err := os.ReadDir(dc.dir)
+		if err != nil {
+			log.Printf("Error listing disk cache: %v", err)
+			continue
+		}
+		now := time.Now()
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), ".json") {
+				continue
+			}
+			path := filepath.Join(dc.dir, file.Name())
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var entry struct {
+				ExpiresAt time.Time `json:"expires_at"`
+			}
+			if json.Unmarshal(raw, &entry) != nil || now.After(entry.ExpiresAt) {
+				os.Remove(path)
+			}
+		}
+	}
+}
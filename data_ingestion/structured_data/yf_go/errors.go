@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Error codes returned in the error envelope
+const (
+	CodeInvalidRequest    = "invalid_request"
+	CodeInvalidSymbol     = "invalid_symbol"
+	CodeNotFound          = "not_found"
+	CodeMethodNotAllowed  = "method_not_allowed"
+	CodeConflict          = "conflict"
+	CodeForbidden         = "forbidden"
+	CodeUnauthorized      = "unauthorized"
+	CodeRateLimited       = "rate_limited"
+	CodeInsufficientData  = "insufficient_data"
+	CodeDataRejected      = "data_quality_rejected"
+	CodeNotConfigured     = "not_configured"
+	CodeUpstreamRateLimit = "upstream_rate_limited"
+	CodeUpstreamOutage    = "upstream_unavailable"
+	CodeUpstreamTimeout   = "upstream_timeout"
+	CodeInternal          = "internal_error"
+)
+
+// retryableCodes are the error codes a client may retry after backing off
+var retryableCodes = map[string]bool{
+	CodeRateLimited:       true,
+	CodeDataRejected:      true,
+	CodeUpstreamRateLimit: true,
+	CodeUpstreamOutage:    true,
+	CodeUpstreamTimeout:   true,
+}
+
+var (
+	// ErrSymbolNotFound marks lookups for symbols the upstream does not know
+	ErrSymbolNotFound = errors.New("unknown symbol")
+	// ErrInsufficientData marks symbols that exist but lack the data a calculation needs
+	ErrInsufficientData = errors.New("insufficient data")
+	// ErrQuoteRejected marks quotes refused by the outlier guard
+	ErrQuoteRejected = errors.New("quote rejected")
+)
+
+// UpstreamStatusError is returned when a provider answers with a non-200 status
+type UpstreamStatusError struct {
+	Provider   string
+	StatusCode int
+}
+
+// Error implements error
+func (e *UpstreamStatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.Provider, e.StatusCode)
+}
+
+// withoutURL drops the request URL an *url.Error carries, which for
+// providers taking their API key as a query parameter would leak the key
+// into responses and logs. Timeouts still classify as timeouts.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// ErrorResponse is the JSON envelope for every error response
+type ErrorResponse struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Symbol    string `json:"symbol,omitempty"`
+	Retryable bool   `json:"retryable"`
+	RequestID string `json:"request_id,omitempty"`
+
+	Suggestions []string `json:"suggestions,omitempty"` // "did you mean" candidates for unknown symbols
+}
+
+// requestIDKey is the context key for the request ID
+type requestIDKey struct{}
+
+// requestIDMiddleware propagates or assigns an X-Request-ID for every request
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			buf := make([]byte, 8)
+			rand.Read(buf)
+			id = hex.EncodeToString(buf)
+		}
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// requestID returns the ID assigned by requestIDMiddleware
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value(requestIDKey{}).(string)
+	return id
+}
+
+// writeError writes an error envelope with the given status and code
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeErrorResponse(w, r, status, ErrorResponse{Code: code, Message: message})
+}
+
+// writeAPIError classifies err and writes the matching error envelope
+func writeAPIError(w http.ResponseWriter, r *http.Request, err error, symbol string) {
+	status, code := classifyError(err)
+	resp := ErrorResponse{
+		Code:    code,
+		Message: err.Error(),
+		Symbol:  strings.ToUpper(symbol),
+	}
+	var unknown *UnknownSymbolError
+	if errors.As(err, &unknown) {
+		resp.Symbol = unknown.Symbol
+		resp.Suggestions = unknown.Suggestions
+	}
+	writeErrorResponse(w, r, status, resp)
+}
+
+// writeErrorResponse fills in the request ID and retryability and encodes the envelope
+func writeErrorResponse(w http.ResponseWriter, r *http.Request, status int, resp ErrorResponse) {
+	resp.Retryable = retryableCodes[resp.Code]
+	resp.RequestID = requestID(r)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// classifyError maps an error from the data layer to an HTTP status and error code
+func classifyError(err error) (int, string) {
+	var statusErr *UpstreamStatusError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, ErrInvalidSymbol):
+		return http.StatusBadRequest, CodeInvalidSymbol
+	case errors.Is(err, ErrSymbolNotFound):
+		return http.StatusNotFound, CodeInvalidSymbol
+	case errors.Is(err, ErrInsufficientData):
+		return http.StatusUnprocessableEntity, CodeInsufficientData
+	case errors.Is(err, ErrQuoteRejected):
+		return http.StatusBadGateway, CodeDataRejected
+	case errors.Is(err, ErrCircuitOpen):
+		return http.StatusServiceUnavailable, CodeUpstreamOutage
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return http.StatusServiceUnavailable, CodeUpstreamRateLimit
+		case statusErr.StatusCode == http.StatusNotFound:
+			return http.StatusNotFound, CodeInvalidSymbol
+		default:
+			return http.StatusBadGateway, CodeUpstreamOutage
+		}
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return http.StatusGatewayTimeout, CodeUpstreamTimeout
+	case errors.As(err, &netErr):
+		return http.StatusBadGateway, CodeUpstreamOutage
+	}
+	return http.StatusInternalServerError, CodeInternal
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quote event types
+const (
+	EventPriceMove   = "price_move"
+	EventVolumeSpike = "volume_spike"
+)
+
+// maxRecentEvents bounds the in-memory event history served by /events
+const maxRecentEvents = 500
+
+// avgVolumeBars is the number of daily bars averaged for volume spike detection
+const avgVolumeBars = 20
+
+// QuoteEvent is emitted when a quote moves beyond the configured thresholds
+type QuoteEvent struct {
+	ID            string  `json:"id"`
+	Type          string  `json:"type"`
+	Symbol        string  `json:"symbol"`
+	Price         float64 `json:"price"`
+	PreviousPrice float64 `json:"previous_price,omitempty"` // baseline the move is measured from
+	ChangePercent float64 `json:"change_percent,omitempty"`
+	Volume        int64   `json:"volume,omitempty"`
+	AverageVolume float64 `json:"average_volume,omitempty"`
+	Timestamp     string  `json:"timestamp"`
+}
+
+// EventBus fans quote events out to subscribers and keeps the most recent ones
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[int]chan QuoteEvent
+	nextID      int
+	recent      []QuoteEvent
+	sequence    uint64
+}
+
+// NewEventBus creates a bus without subscribers
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[int]chan QuoteEvent)}
+}
+
+// Subscribe returns a channel receiving every published event and a function that ends the
+// subscription. Events are dropped for subscribers whose buffer is full rather than blocking quotes.
+func (b *EventBus) Subscribe(buffer int) (<-chan QuoteEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan QuoteEvent, buffer)
+	b.subscribers[id] = ch
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[id]; ok {
+			delete(b.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
+// Publish assigns the event an ID and delivers it to every subscriber
+func (b *EventBus) Publish(event QuoteEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sequence++
+	event.ID = strconv.FormatUint(b.sequence, 10)
+	b.recent = append(b.recent, event)
+	if len(b.recent) > maxRecentEvents {
+		b.recent = b.recent[len(b.recent)-maxRecentEvents:]
+	}
+
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Dropping %s event for %s: subscriber is not keeping up", event.Type, event.Symbol)
+		}
+	}
+}
+
+// Recent returns up to limit of the latest events, newest first, optionally for one symbol
+func (b *EventBus) Recent(symbol string, limit int) []QuoteEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := []QuoteEvent{}
+	for i := len(b.recent) - 1; i >= 0 && len(events) < limit; i-- {
+		if symbol == "" || b.recent[i].Symbol == symbol {
+			events = append(events, b.recent[i])
+		}
+	}
+	return events
+}
+
+// quoteState is what the detector remembers per symbol
+type quoteState struct {
+	baseline      float64 // price of the last price_move event, or the first observation
+	avgVolume     float64
+	avgVolumeDay  string // day avgVolume was computed, so it refreshes daily
+	volumeAlerted string // day a volume spike was last emitted, so it fires once per day
+}
+
+// ChangeDetector compares each fresh quote with what it has seen before and publishes events
+type ChangeDetector struct {
+	priceMovePercent float64
+	volumeMultiple   float64
+	averageVolume    func(symbol string) (float64, error)
+	bus              *EventBus
+
+	mu     sync.Mutex
+	states map[string]*quoteState
+}
+
+// NewChangeDetector creates a detector publishing to bus; a zero threshold disables that event type
+func NewChangeDetector(bus *EventBus, priceMovePercent, volumeMultiple float64, averageVolume func(string) (float64, error)) *ChangeDetector {
+	return &ChangeDetector{
+		priceMovePercent: priceMovePercent,
+		volumeMultiple:   volumeMultiple,
+		averageVolume:    averageVolume,
+		bus:              bus,
+		states:           make(map[string]*quoteState),
+	}
+}
+
+// Observe checks a freshly fetched quote against the symbol's baseline price and average volume.
+// Price moves are measured from the last emitted move so that slow drifts still trigger.
+func (cd *ChangeDetector) Observe(data *FinancialData) {
+	if data.Price <= 0 || len(data.QualityFlags) > 0 {
+		return // suspect quotes should not page anyone
+	}
+	now := time.Now()
+	today := now.Format("2006-01-02")
+
+	cd.mu.Lock()
+	state, ok := cd.states[data.Symbol]
+	if !ok {
+		state = &quoteState{baseline: data.Price}
+		cd.states[data.Symbol] = state
+	}
+	var events []QuoteEvent
+	if move := (data.Price - state.baseline) / state.baseline * 100; cd.priceMovePercent > 0 && math.Abs(move) >= cd.priceMovePercent {
+		events = append(events, QuoteEvent{
+			Type:          EventPriceMove,
+			Symbol:        data.Symbol,
+			Price:         data.Price,
+			PreviousPrice: state.baseline,
+			ChangePercent: move,
+			Timestamp:     now.Format(time.RFC3339),
+		})
+		state.baseline = data.Price
+	}
+	needAverage := cd.volumeMultiple > 0 && state.avgVolumeDay != today && state.volumeAlerted != today
+	cd.mu.Unlock()
+
+	// The average comes from daily history, so fetch it outside the lock at most once a day
+	if needAverage {
+		avg, err := cd.averageVolume(data.Symbol)
+		if err != nil {
+			log.Printf("Error computing average volume for %s: %v", data.Symbol, err)
+		}
+		cd.mu.Lock()
+		if err == nil {
+			state.avgVolume = avg
+			state.avgVolumeDay = today
+		}
+		cd.mu.Unlock()
+	}
+
+	cd.mu.Lock()
+	if cd.volumeMultiple > 0 && state.avgVolume > 0 && state.volumeAlerted != today &&
+		float64(data.Volume) >= cd.volumeMultiple*state.avgVolume {
+		events = append(events, QuoteEvent{
+			Type:          EventVolumeSpike,
+			Symbol:        data.Symbol,
+			Price:         data.Price,
+			Volume:        data.Volume,
+			AverageVolume: state.avgVolume,
+			Timestamp:     now.Format(time.RFC3339),
+		})
+		state.volumeAlerted = today
+	}
+	cd.mu.Unlock()
+
+	for _, event := range events {
+		cd.bus.Publish(event)
+	}
+}
+
+// averageDailyVolume averages the volume of the last avgVolumeBars completed daily bars
+func (yf *YahooFinanceAPI) averageDailyVolume(symbol string) (float64, error) {
+	points, err := yf.GetHistory(symbol, "3mo")
+	if err != nil {
+		return 0, err
+	}
+	if len(points) > 1 {
+		points = points[:len(points)-1] // the last bar is today's, still accumulating
+	}
+	if len(points) > avgVolumeBars {
+		points = points[len(points)-avgVolumeBars:]
+	}
+
+	var total float64
+	var days int
+	for _, point := range points {
+		if point.Volume > 0 {
+			total += float64(point.Volume)
+			days++
+		}
+	}
+	if days == 0 {
+		return 0, fmt.Errorf("no volume history for %s: %w", symbol, ErrInsufficientData)
+	}
+	return total / float64(days), nil
+}
+
+// WebhookSink posts every event on the bus to a list of URLs, signing the body when a secret is set
+type WebhookSink struct {
+	urls   []string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookSink creates a sink for urls
+func NewWebhookSink(urls []string, secret string) *WebhookSink {
+	return &WebhookSink{urls: urls, secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Start delivers events from bus until the subscription is closed
+func (ws *WebhookSink) Start(bus *EventBus) {
+	events, _ := bus.Subscribe(100)
+	go func() {
+		defer recoverPanic(map[string]string{"component": "webhooks"})
+		for event := range events {
+			body, err := json.Marshal(event)
+			if err != nil {
+				log.Printf("Error encoding event %s: %v", event.ID, err)
+				continue
+			}
+			for _, url := range ws.urls {
+				ws.deliver(url, body, event)
+			}
+		}
+	}()
+}
+
+// deliver posts body to url, retrying with backoff on network errors and 5xx responses
+func (ws *WebhookSink) deliver(url string, body []byte, event QuoteEvent) {
+	backoff := time.Second
+	for attempt := 1; attempt <= 3; attempt++ {
+		err := ws.post(url, body, event)
+		if err == nil {
+			return
+		}
+		var statusErr *UpstreamStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode < 500 && statusErr.StatusCode != http.StatusTooManyRequests {
+			log.Printf("Webhook %s rejected event %s: %v", url, event.ID, err)
+			return
+		}
+		if attempt == 3 {
+			log.Printf("Giving up on webhook %s for event %s: %v", url, event.ID, err)
+			return
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// post sends one delivery attempt
+func (ws *WebhookSink) post(url string, body []byte, event QuoteEvent) error {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", event.Type)
+	if ws.secret != "" {
+		mac := hmac.New(sha256.New, []byte(ws.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return &UpstreamStatusError{Provider: "webhook", StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// handleEvents lists recent quote events, newest first
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxRecentEvents {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("limit must be between 1 and %d", maxRecentEvents))
+			return
+		}
+		limit = parsed
+	}
+	symbol := strings.ToUpper(r.URL.Query().Get("symbol"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.events.Recent(symbol, limit))
+}
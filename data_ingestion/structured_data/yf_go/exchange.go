@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gaixen/CredTech/symbols"
+	"github.com/gaixen/CredTech/symbols/calendar"
+)
+
+// Exchange describes a listing venue identified by its Yahoo ticker suffix
+type Exchange struct {
+	Code     string `json:"code"`
+	Name     string `json:"name"`
+	Suffix   string `json:"suffix"` // Yahoo ticker suffix including the dot; empty for US listings
+	Country  string `json:"country"`
+	Currency string `json:"currency"` // ISO 4217 code prices are normalized to
+	Timezone string `json:"timezone"` // IANA zone the trading hours are expressed in
+
+	// Regular session and midday break, if any, in local HH:MM
+	Open       string `json:"open"`
+	Close      string `json:"close"`
+	LunchStart string `json:"lunch_start,omitempty"`
+	LunchEnd   string `json:"lunch_end,omitempty"`
+
+	calendar *calendar.Calendar
+}
+
+// exchanges lists the supported venues. US listings carry no suffix. Hours, time zones and
+// holidays come from the shared exchange calendar.
+var exchanges = []*Exchange{
+	{Code: "US", Name: "NYSE / Nasdaq", Country: "US", Currency: "USD"},
+	{Code: "NSE", Name: "National Stock Exchange of India", Suffix: ".NS", Country: "IN", Currency: "INR"},
+	{Code: "BSE", Name: "BSE (Bombay Stock Exchange)", Suffix: ".BO", Country: "IN", Currency: "INR"},
+	{Code: "LSE", Name: "London Stock Exchange", Suffix: ".L", Country: "GB", Currency: "GBP"},
+	{Code: "TSE", Name: "Tokyo Stock Exchange", Suffix: ".T", Country: "JP", Currency: "JPY"},
+}
+
+// exchangesBySuffix indexes exchanges by ticker suffix
+var exchangesBySuffix = make(map[string]*Exchange)
+
+func init() {
+	for _, ex := range exchanges {
+		cal := calendar.ForCode(ex.Code)
+		if cal == nil {
+			panic("exchange " + ex.Code + " has no calendar")
+		}
+		ex.calendar = cal
+		ex.Timezone = cal.Timezone
+		ex.Open, ex.Close = cal.Open, cal.Close
+		ex.LunchStart, ex.LunchEnd = cal.LunchStart, cal.LunchEnd
+		exchangesBySuffix[ex.Suffix] = ex
+	}
+}
+
+// minorCurrencies maps the minor units Yahoo quotes some venues in to their ISO currency.
+// London prices most equities in pence (GBp), Johannesburg in cents and Tel Aviv in agorot.
+var minorCurrencies = map[string]string{
+	"GBp": "GBP",
+	"GBX": "GBP",
+	"ZAc": "ZAR",
+	"ILA": "ILS",
+}
+
+// ExchangeFor returns the venue a symbol trades on, or nil for suffixes without market hours
+// such as indices, FX pairs, futures and exchanges not in the table
+func ExchangeFor(symbol string) *Exchange {
+	parsed, err := symbols.Parse(symbol)
+	if err != nil || parsed.Kind != symbols.KindSecurity {
+		return nil
+	}
+	if parsed.Suffix == "" {
+		return exchangesBySuffix[""]
+	}
+	return exchangesBySuffix["."+parsed.Suffix]
+}
+
+// IsOpen reports whether the regular session is running at t, allowing for holidays and
+// early closes
+func (ex *Exchange) IsOpen(t time.Time) bool {
+	return ex.calendar.IsOpen(t)
+}
+
+// NextOpen returns the next time at or after t that the regular session (or its afternoon
+// half, after a lunch break) starts
+func (ex *Exchange) NextOpen(t time.Time) time.Time {
+	return ex.calendar.NextOpen(t)
+}
+
+// quoteSettle is how long after a session ends quotes keep changing: several venues are
+// delayed by 15 minutes on Yahoo and closing auctions print after the bell
+const quoteSettle = 20 * time.Minute
+
+// quotesMoving reports whether quotes can still change at now: the session is open or has
+// just ended. Symbols without market hours always can.
+func (ex *Exchange) quotesMoving(now time.Time) bool {
+	return ex == nil || ex.IsOpen(now) || ex.IsOpen(now.Add(-quoteSettle))
+}
+
+// QuoteExpiry returns when a quote fetched at now should leave the cache. While the session
+// is open, or has just ended, that is now plus ttl; once it has settled the price cannot move,
+// so the quote is kept until the next open.
+func (ex *Exchange) QuoteExpiry(now time.Time, ttl time.Duration) time.Time {
+	if ex.quotesMoving(now) {
+		return now.Add(ttl)
+	}
+	if next := ex.NextOpen(now); next.After(now.Add(ttl)) {
+		return next
+	}
+	return now.Add(ttl)
+}
+
+// ExchangeStatus is an exchange with its current session state
+type ExchangeStatus struct {
+	Exchange   *Exchange `json:"exchange"`
+	IsOpen     bool      `json:"is_open"`
+	TradingDay bool      `json:"trading_day"` // false on weekends and exchange holidays
+	LocalTime  string    `json:"local_time"`
+	NextOpen   string    `json:"next_open"`
+}
+
+// handleExchanges lists the supported exchanges and whether each is trading now
+func (s *Server) handleExchanges(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	statuses := make([]ExchangeStatus, 0, len(exchanges))
+	for _, ex := range exchanges {
+		statuses = append(statuses, ExchangeStatus{
+			Exchange:   ex,
+			IsOpen:     ex.IsOpen(now),
+			TradingDay: ex.calendar.IsTradingDay(now),
+			LocalTime:  now.In(ex.calendar.Location()).Format(time.RFC3339),
+			NextOpen:   ex.NextOpen(now).Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statuses)
+}
+
+// normalizeMinorCurrency converts prices quoted in minor units (e.g. pence) to the major
+// currency so that FX conversion and cross-listing comparisons see consistent values
+func normalizeMinorCurrency(data *FinancialData) {
+	major, ok := minorCurrencies[data.Currency]
+	if !ok {
+		return
+	}
+	data.Price /= 100
+	data.Change /= 100
+	data.Currency = major
+}
+
+// normalizeHistoryCurrency converts bars and dividends quoted in minor units to the major
+// currency, matching the quotes normalizeMinorCurrency converts
+func normalizeHistoryCurrency(history *PriceHistory) {
+	major, ok := minorCurrencies[history.Currency]
+	if !ok {
+		return
+	}
+	for i := range history.Points {
+		point := &history.Points[i]
+		point.Open /= 100
+		point.High /= 100
+		point.Low /= 100
+		point.Close /= 100
+		point.AdjClose /= 100
+	}
+	for i := range history.Dividends {
+		history.Dividends[i].Amount /= 100
+	}
+	history.Currency = major
+}
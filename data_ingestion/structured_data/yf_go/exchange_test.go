@@ -0,0 +1,157 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExchangeFor(t *testing.T) {
+	tests := []struct {
+		symbol string
+		code   string // empty for no exchange
+	}{
+		{"AAPL", "US"},
+		{"BRK-B", "US"},
+		{"RELIANCE.NS", "NSE"},
+		{"reliance.bo", "BSE"},
+		{"VOD.L", "LSE"},
+		{"7203.T", "TSE"},
+		{"RY.TO", ""},
+		{"^GSPC", ""},
+		{"EURUSD=X", ""},
+		{"CL=F", ""},
+	}
+	for _, tt := range tests {
+		ex := ExchangeFor(tt.symbol)
+		got := ""
+		if ex != nil {
+			got = ex.Code
+		}
+		if got != tt.code {
+			t.Errorf("ExchangeFor(%q) = %q, want %q", tt.symbol, got, tt.code)
+		}
+	}
+}
+
+func TestExchangeIsOpen(t *testing.T) {
+	tests := []struct {
+		symbol string
+		at     string // RFC 3339 instant
+		open   bool
+	}{
+		{"AAPL", "2024-06-17T13:30:00Z", true},         // 09:30 EDT
+		{"AAPL", "2024-06-17T13:29:00Z", false},        // 09:29 EDT
+		{"AAPL", "2024-06-17T20:00:00Z", false},        // 16:00 EDT close
+		{"AAPL", "2024-01-16T14:30:00Z", true},         // 09:30 EST, one hour later in UTC
+		{"RELIANCE.NS", "2024-06-17T03:45:00Z", true},  // 09:15 IST
+		{"RELIANCE.NS", "2024-06-17T10:00:00Z", false}, // 15:30 IST close
+		{"VOD.L", "2024-06-17T07:00:00Z", true},        // 08:00 BST
+		{"VOD.L", "2024-01-15T07:30:00Z", false},       // 07:30 GMT
+		{"7203.T", "2024-06-17T00:00:00Z", true},       // 09:00 JST
+		{"7203.T", "2024-06-17T03:00:00Z", false},      // 12:00 JST lunch break
+		{"7203.T", "2024-06-17T03:30:00Z", true},       // 12:30 JST afternoon session
+		{"7203.T", "2024-06-15T01:00:00Z", false},      // Saturday
+		{"AAPL", "2024-07-04T15:00:00Z", false},        // Independence Day
+		{"AAPL", "2024-12-24T17:30:00Z", true},         // 12:30 EST on Christmas Eve
+		{"AAPL", "2024-12-24T18:30:00Z", false},        // after the 13:00 early close
+		{"VOD.L", "2024-08-26T09:00:00Z", false},       // summer bank holiday
+		{"7203.T", "2024-09-23T01:00:00Z", false},      // substitute for the Sunday equinox
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := ExchangeFor(tt.symbol).IsOpen(at); got != tt.open {
+			t.Errorf("%s open at %s = %v, want %v", tt.symbol, tt.at, got, tt.open)
+		}
+	}
+}
+
+func TestExchangeNextOpen(t *testing.T) {
+	tests := []struct {
+		symbol string
+		from   string
+		want   string
+	}{
+		{"AAPL", "2024-06-14T21:00:00Z", "2024-06-17T13:30:00Z"},        // Friday evening to Monday
+		{"AAPL", "2024-03-08T21:00:00Z", "2024-03-11T13:30:00Z"},        // across the DST change
+		{"VOD.L", "2024-06-17T06:00:00Z", "2024-06-17T07:00:00Z"},       // same morning
+		{"7203.T", "2024-06-17T02:45:00Z", "2024-06-17T03:30:00Z"},      // lunch break to afternoon
+		{"RELIANCE.NS", "2024-06-17T03:45:00Z", "2024-06-17T03:45:00Z"}, // exactly at the open
+		{"AAPL", "2024-03-28T21:00:00Z", "2024-04-01T13:30:00Z"},        // over Good Friday
+		{"VOD.L", "2024-03-28T17:00:00Z", "2024-04-02T07:00:00Z"},       // over Good Friday and Easter Monday
+		{"7203.T", "2024-12-30T07:00:00Z", "2025-01-06T00:00:00Z"},      // year-end closure
+	}
+	for _, tt := range tests {
+		from, _ := time.Parse(time.RFC3339, tt.from)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := ExchangeFor(tt.symbol).NextOpen(from); !got.Equal(want) {
+			t.Errorf("%s next open after %s = %s, want %s", tt.symbol, tt.from, got.UTC().Format(time.RFC3339), tt.want)
+		}
+	}
+}
+
+func TestQuoteExpiry(t *testing.T) {
+	ttl := 5 * time.Minute
+	us := ExchangeFor("AAPL")
+
+	open, _ := time.Parse(time.RFC3339, "2024-06-17T15:00:00Z")
+	if got := us.QuoteExpiry(open, ttl); !got.Equal(open.Add(ttl)) {
+		t.Errorf("expiry during session = %s, want now plus TTL", got)
+	}
+	justClosed, _ := time.Parse(time.RFC3339, "2024-06-17T20:05:00Z")
+	if got := us.QuoteExpiry(justClosed, ttl); !got.Equal(justClosed.Add(ttl)) {
+		t.Errorf("expiry right after the close = %s, want now plus TTL", got)
+	}
+	weekend, _ := time.Parse(time.RFC3339, "2024-06-15T12:00:00Z")
+	monday, _ := time.Parse(time.RFC3339, "2024-06-17T13:30:00Z")
+	if got := us.QuoteExpiry(weekend, ttl); !got.Equal(monday) {
+		t.Errorf("expiry on the weekend = %s, want the Monday open", got)
+	}
+	if got := ExchangeFor("^GSPC").QuoteExpiry(weekend, ttl); !got.Equal(weekend.Add(ttl)) {
+		t.Errorf("expiry without an exchange = %s, want now plus TTL", got)
+	}
+}
+
+func TestLondonQuoteNormalizedToPounds(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var data FinancialData
+	resp := getJSON(t, server, "/stock?symbol=VOD.L", &data)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if data.Currency != "GBP" || data.Exchange != "LSE" {
+		t.Errorf("got %s on %s, want GBP on LSE", data.Currency, data.Exchange)
+	}
+	if data.Price != 0.7152 {
+		t.Errorf("price = %v, want 0.7152 pounds", data.Price)
+	}
+}
+
+func TestLondonHistoryNormalizedToPounds(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var history HistoryResult
+	resp := getJSON(t, server, "/history?symbol=VOD.L&period=1mo", &history)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if history.Currency != "GBP" {
+		t.Errorf("currency = %q, want GBP", history.Currency)
+	}
+	if len(history.Points) != 1 {
+		t.Fatalf("got %d bars, want 1", len(history.Points))
+	}
+	bar := history.Points[0]
+	for name, got := range map[string]float64{"open": bar.Open, "high": bar.High, "low": bar.Low, "close": bar.Close, "adj_close": bar.AdjClose} {
+		if got <= 0 || got >= 1 {
+			t.Errorf("%s = %v, want pounds rather than pence", name, got)
+		}
+	}
+	if math.Abs(bar.Close-0.7152) > 1e-6 {
+		t.Errorf("close = %v, want 0.7152 pounds", bar.Close)
+	}
+}
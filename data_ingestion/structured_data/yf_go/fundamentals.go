@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// yahooValue is the {"raw": ..., "fmt": ...} wrapper Yahoo uses for numeric fields
+type yahooValue struct {
+	Raw float64 `json:"raw"`
+	Fmt string  `json:"fmt"`
+}
+
+// BalanceSheet holds the balance sheet items used by the credit models
+type BalanceSheet struct {
+	EndDate                 yahooValue `json:"endDate"`
+	TotalCurrentAssets      yahooValue `json:"totalCurrentAssets"`
+	TotalCurrentLiabilities yahooValue `json:"totalCurrentLiabilities"`
+	TotalAssets             yahooValue `json:"totalAssets"`
+	TotalLiab               yahooValue `json:"totalLiab"`
+	RetainedEarnings        yahooValue `json:"retainedEarnings"`
+	TotalStockholderEquity  yahooValue `json:"totalStockholderEquity"`
+}
+
+// IncomeStatement holds the income statement items used by the credit models
+type IncomeStatement struct {
+	EndDate      yahooValue `json:"endDate"`
+	TotalRevenue yahooValue `json:"totalRevenue"`
+	Ebit         yahooValue `json:"ebit"`
+	NetIncome    yahooValue `json:"netIncome"`
+}
+
+// QuoteSummary is the subset of Yahoo's quoteSummary modules we consume
+type QuoteSummary struct {
+	Price struct {
+		ShortName string     `json:"shortName"`
+		LongName  string     `json:"longName"`
+		MarketCap yahooValue `json:"marketCap"`
+		Currency  string     `json:"currency"`
+	} `json:"price"`
+	SummaryDetail struct {
+		TrailingPE yahooValue `json:"trailingPE"`
+		ForwardPE  yahooValue `json:"forwardPE"`
+
+		// Funds
+		NavPrice    yahooValue `json:"navPrice"`
+		TotalAssets yahooValue `json:"totalAssets"`
+		Yield       yahooValue `json:"yield"`
+
+		// Crypto
+		MarketCap         yahooValue `json:"marketCap"`
+		CirculatingSupply yahooValue `json:"circulatingSupply"`
+		MaxSupply         yahooValue `json:"maxSupply"`
+		Volume24Hr        yahooValue `json:"volume24Hr"`
+	} `json:"summaryDetail"`
+	FundProfile struct {
+		FeesExpensesInvestment struct {
+			AnnualReportExpenseRatio yahooValue `json:"annualReportExpenseRatio"`
+		} `json:"feesExpensesInvestment"`
+	} `json:"fundProfile"`
+	AssetProfile struct {
+		Sector   string `json:"sector"`
+		Industry string `json:"industry"`
+	} `json:"assetProfile"`
+	BalanceSheetHistory struct {
+		BalanceSheetStatements []BalanceSheet `json:"balanceSheetStatements"`
+	} `json:"balanceSheetHistory"`
+	IncomeStatementHistory struct {
+		IncomeStatementHistory []IncomeStatement `json:"incomeStatementHistory"`
+	} `json:"incomeStatementHistory"`
+	DefaultKeyStatistics struct {
+		SharesShort           yahooValue `json:"sharesShort"`
+		SharesShortPriorMonth yahooValue `json:"sharesShortPriorMonth"`
+		ShortRatio            yahooValue `json:"shortRatio"`
+		ShortPercentOfFloat   yahooValue `json:"shortPercentOfFloat"`
+		FloatShares           yahooValue `json:"floatShares"`
+		DateShortInterest     yahooValue `json:"dateShortInterest"`
+	} `json:"defaultKeyStatistics"`
+	RecommendationTrend struct {
+		Trend []struct {
+			Period     string `json:"period"`
+			StrongBuy  int    `json:"strongBuy"`
+			Buy        int    `json:"buy"`
+			Hold       int    `json:"hold"`
+			Sell       int    `json:"sell"`
+			StrongSell int    `json:"strongSell"`
+		} `json:"trend"`
+	} `json:"recommendationTrend"`
+}
+
+// GetQuoteSummary fetches the requested quoteSummary modules with caching
+func (yf *YahooFinanceAPI) GetQuoteSummary(symbol string, modules ...string) (*QuoteSummary, error) {
+	symbol = canonicalSymbol(symbol)
+	cacheKey := fmt.Sprintf("summary_%s_%s", symbol, strings.Join(modules, ","))
+	if cached, found := yf.cache.Get(cacheKey); found {
+		if summary, ok := cached.(*QuoteSummary); ok {
+			return summary, nil
+		}
+	}
+
+	return coalesce(&yf.flights, cacheKey, func() (*QuoteSummary, error) {
+		return yf.loadQuoteSummary(symbol, modules, cacheKey)
+	})
+}
+
+// loadQuoteSummary validates symbol, fetches the modules from Yahoo and caches them under cacheKey
+func (yf *YahooFinanceAPI) loadQuoteSummary(symbol string, modules []string, cacheKey string) (*QuoteSummary, error) {
+	var stored QuoteSummary
+	if expiresAt, ok := yf.disk.Load(cacheKey, &stored); ok {
+		yf.cache.SetUntil(cacheKey, &stored, expiresAt)
+		return &stored, nil
+	}
+
+	if err := yf.CheckSymbol(symbol); err != nil {
+		return nil, err
+	}
+
+	summary, err := yf.fetchQuoteSummary(symbol, modules)
+	if err != nil {
+		return nil, err
+	}
+	yf.freshness.Touch("fundamentals")
+
+	yf.cache.Set(cacheKey, summary)
+	yf.disk.Store(cacheKey, summary)
+	return summary, nil
+}
+
+// fetchQuoteSummary calls Yahoo's quoteSummary API for the given modules
+func (yf *YahooFinanceAPI) fetchQuoteSummary(symbol string, modules []string) (*QuoteSummary, error) {
+	url := fmt.Sprintf("%s/v10/finance/quoteSummary/%s?modules=%s",
+		yf.queryURL, strings.ToUpper(symbol), strings.Join(modules, ","))
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", yf.userAgent)
+
+	resp, err := yf.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UpstreamStatusError{Provider: "Yahoo Finance", StatusCode: resp.StatusCode}
+	}
+
+	var summaryResp struct {
+		QuoteSummary struct {
+			Result []QuoteSummary `json:"result"`
+			Error  *struct {
+				Code        string `json:"code"`
+				Description string `json:"description"`
+			} `json:"error"`
+		} `json:"quoteSummary"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&summaryResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	if summaryResp.QuoteSummary.Error != nil {
+		return nil, fmt.Errorf("quoteSummary error: %s", summaryResp.QuoteSummary.Error.Description)
+	}
+	if len(summaryResp.QuoteSummary.Result) == 0 {
+		return nil, fmt.Errorf("no summary data found for symbol %s: %w", symbol, ErrSymbolNotFound)
+	}
+
+	return &summaryResp.QuoteSummary.Result[0], nil
+}
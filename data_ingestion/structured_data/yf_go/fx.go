@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GetFXRate returns the rate converting one unit of from into to, with caching
+func (yf *YahooFinanceAPI) GetFXRate(from, to string) (float64, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil
+	}
+
+	cacheKey := fmt.Sprintf("fx_%s%s", from, to)
+	if cached, found := yf.cache.Get(cacheKey); found {
+		if rate, ok := cached.(float64); ok {
+			return rate, nil
+		}
+	}
+
+	// Yahoo quotes currency pairs as e.g. USDEUR=X on the chart API
+	quote, err := yf.fetchFromYahoo(fmt.Sprintf("%s%s=X", from, to))
+	if err != nil {
+		return 0, fmt.Errorf("fetching %s/%s rate: %w", from, to, err)
+	}
+	if quote.Price <= 0 {
+		return 0, fmt.Errorf("invalid %s/%s rate %f", from, to, quote.Price)
+	}
+
+	yf.cache.Set(cacheKey, quote.Price)
+	return quote.Price, nil
+}
+
+// ConvertQuote returns a copy of data with monetary fields expressed in currency
+func (yf *YahooFinanceAPI) ConvertQuote(data *FinancialData, currency string) (*FinancialData, error) {
+	currency = strings.ToUpper(currency)
+	from := data.Currency
+	if from == "" {
+		from = "USD"
+	}
+	if from == currency {
+		return data, nil
+	}
+
+	rate, err := yf.GetFXRate(from, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := *data
+	converted.Price = data.Price * rate
+	converted.MarketCap = int64(float64(data.MarketCap) * rate)
+	converted.Change = data.Change * rate
+	converted.Currency = currency
+	converted.OriginalCurrency = from
+	if data.Fund != nil {
+		fund := *data.Fund
+		fund.NAV = data.Fund.NAV * rate
+		fund.AUM = int64(float64(data.Fund.AUM) * rate)
+		converted.Fund = &fund
+	}
+	converted.ConversionRate = rate
+	return &converted, nil
+}
+
+// validCurrency reports whether code looks like an ISO 4217 currency code
+func validCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range strings.ToUpper(code) {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -2,5 +2,12 @@ module yahoo-finance-go
 
 go 1.21
 
-require (
-)
+require github.com/lib/pq v1.10.9
+
+require gopkg.in/yaml.v3 v3.0.1
+
+require golang.org/x/sync v0.8.0
+
+require github.com/gaixen/CredTech/symbols v0.0.0
+
+replace github.com/gaixen/CredTech/symbols => ../../../symbols
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outlier guard actions
+const (
+	GuardActionReject = "reject" // drop the value and return an error
+	GuardActionFlag   = "flag"   // keep the value but mark it with quality flags
+)
+
+// GuardConfig holds the thresholds for market data validation
+type GuardConfig struct {
+	Action         string
+	MaxMovePercent float64 // absolute daily move beyond which a quote is suspect
+	MaxPrice       float64
+	LiquidVolume   int64 // names whose last good volume exceeded this should never print zero volume
+	QuarantineFile string
+}
+
+// QuarantinedValue records a rejected or flagged market data value
+type QuarantinedValue struct {
+	Symbol     string      `json:"symbol"`
+	Kind       string      `json:"kind"`
+	Violations []string    `json:"violations"`
+	Action     string      `json:"action"`
+	Value      interface{} `json:"value"`
+	DetectedAt time.Time   `json:"detected_at"`
+}
+
+// OutlierGuard validates quotes and price history before they reach the cache and derived features
+type OutlierGuard struct {
+	config     GuardConfig
+	lastGood   map[string]*FinancialData
+	quarantine []QuarantinedValue
+	mu         sync.Mutex
+}
+
+// maxQuarantineEntries bounds the in-memory quarantine
+const maxQuarantineEntries = 1000
+
+// NewOutlierGuard creates a guard with the given thresholds
+func NewOutlierGuard(cfg GuardConfig) *OutlierGuard {
+	if cfg.Action != GuardActionFlag {
+		cfg.Action = GuardActionReject
+	}
+	return &OutlierGuard{
+		config:   cfg,
+		lastGood: make(map[string]*FinancialData),
+	}
+}
+
+// CheckQuote validates a quote, returning an error if it must be rejected
+func (g *OutlierGuard) CheckQuote(data *FinancialData) error {
+	g.mu.Lock()
+	previous := g.lastGood[data.Symbol]
+	g.mu.Unlock()
+
+	var violations []string
+	if data.Price <= 0 || math.IsNaN(data.Price) || math.IsInf(data.Price, 0) {
+		violations = append(violations, "non_positive_price")
+	}
+	if g.config.MaxPrice > 0 && data.Price > g.config.MaxPrice {
+		violations = append(violations, "price_above_max")
+	}
+	if math.IsNaN(data.ChangePerc) || math.Abs(data.ChangePerc) > g.config.MaxMovePercent {
+		violations = append(violations, "extreme_move")
+	}
+	if data.Volume < 0 {
+		violations = append(violations, "negative_volume")
+	}
+	if data.Volume == 0 && previous != nil && previous.Volume >= g.config.LiquidVolume {
+		violations = append(violations, "zero_volume_liquid_name")
+	}
+
+	if len(violations) == 0 {
+		g.mu.Lock()
+		g.lastGood[data.Symbol] = data
+		g.mu.Unlock()
+		return nil
+	}
+
+	g.record(data.Symbol, "quote", violations, data)
+	if g.config.Action == GuardActionFlag {
+		data.QualityFlags = violations
+		return nil
+	}
+	return fmt.Errorf("%w: %s failed validation: %s", ErrQuoteRejected, data.Symbol, strings.Join(violations, ", "))
+}
+
+// FilterHistory removes bars with impossible values or unexplained jumps.
+// A jump from the last bar that passed is explained when the next bar stays
+// near the new level, so a real gap re-anchors the series instead of dropping
+// every bar after it; a bar the next one moves away from again is a spike.
+func (g *OutlierGuard) FilterHistory(symbol string, points []PricePoint) []PricePoint {
+	filtered := make([]PricePoint, 0, len(points))
+	var anchor *PricePoint // the last bar that passed, which flag mode may have kept others after
+	for i, point := range points {
+		var violations []string
+		if !validBar(point) {
+			violations = append(violations, "non_positive_price")
+		}
+		if point.High > 0 && point.Low > 0 && point.High < point.Low {
+			violations = append(violations, "high_below_low")
+		}
+		if anchor != nil && point.AdjClose > 0 && g.extremeMove(*anchor, point) {
+			next := nextValidBar(points[i+1:])
+			if next == nil || g.extremeMove(point, *next) {
+				violations = append(violations, "extreme_move")
+			}
+		}
+
+		if len(violations) == 0 {
+			anchor = &points[i]
+			filtered = append(filtered, point)
+			continue
+		}
+
+		g.record(symbol, "history_bar", violations, point)
+		// Flag mode keeps extreme-but-positive moves; impossible values are always dropped
+		if g.config.Action == GuardActionFlag && validBar(point) {
+			filtered = append(filtered, point)
+		}
+	}
+	return filtered
+}
+
+// extremeMove reports whether the adjusted close moves from prev to point by
+// more than MaxMovePercent
+func (g *OutlierGuard) extremeMove(prev, point PricePoint) bool {
+	return math.Abs(point.AdjClose/prev.AdjClose-1)*100 > g.config.MaxMovePercent
+}
+
+// validBar reports whether a bar's closes are positive
+func validBar(point PricePoint) bool {
+	return point.Close > 0 && point.AdjClose > 0
+}
+
+// nextValidBar returns the first bar of points with positive closes, or nil
+func nextValidBar(points []PricePoint) *PricePoint {
+	for i := range points {
+		if validBar(points[i]) {
+			return &points[i]
+		}
+	}
+	return nil
+}
+
+// record quarantines a value and raises an alert
+func (g *OutlierGuard) record(symbol, kind string, violations []string, value interface{}) {
+	entry := QuarantinedValue{
+		Symbol:     symbol,
+		Kind:       kind,
+		Violations: violations,
+		Action:     g.config.Action,
+		Value:      value,
+		DetectedAt: time.Now(),
+	}
+
+	g.mu.Lock()
+	g.quarantine = append(g.quarantine, entry)
+	if len(g.quarantine) > maxQuarantineEntries {
+		g.quarantine = g.quarantine[len(g.quarantine)-maxQuarantineEntries:]
+	}
+	g.mu.Unlock()
+
+	log.Printf("🚨 Outlier %s for %s (%s): %s", g.config.Action, symbol, kind, strings.Join(violations, ", "))
+
+	if g.config.QuarantineFile != "" {
+		g.appendToFile(entry)
+	}
+}
+
+// appendToFile writes a quarantine entry as a JSON line
+func (g *OutlierGuard) appendToFile(entry QuarantinedValue) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	file, err := os.OpenFile(g.config.QuarantineFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Printf("Failed to open quarantine file: %v", err)
+		return
+	}
+	defer file.Close()
+
+	if err := json.NewEncoder(file).Encode(entry); err != nil {
+		log.Printf("Failed to write quarantine entry: %v", err)
+	}
+}
+
+// Quarantined returns the most recent quarantined values
+func (g *OutlierGuard) Quarantined() []QuarantinedValue {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entries := make([]QuarantinedValue, len(g.quarantine))
+	copy(entries, g.quarantine)
+	return entries
+}
+
+// handleQuarantine lists recently quarantined market data
+func (s *Server) handleQuarantine(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.api.guard.Quarantined())
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterHistory(t *testing.T) {
+	tests := []struct {
+		name   string
+		closes []float64
+		want   []float64
+	}{
+		{"steady", []float64{10, 10.2, 10.1, 10.3}, []float64{10, 10.2, 10.1, 10.3}},
+		{"real gap up", []float64{10, 16, 16.1, 16.2, 16.3}, []float64{10, 16, 16.1, 16.2, 16.3}},
+		{"real gap down", []float64{20, 11, 11.2, 10.9}, []float64{20, 11, 11.2, 10.9}},
+		{"one bar spike", []float64{10, 100, 10.1, 10.2}, []float64{10, 10.1, 10.2}},
+		{"one bar drop", []float64{10, 0.5, 10.1}, []float64{10, 10.1}},
+		{"jump on the last bar", []float64{10, 10.1, 30}, []float64{10, 10.1}},
+		{"gap confirmed past a bad bar", []float64{10, 16, 0, 16.2}, []float64{10, 16, 16.2}},
+		{"non-positive close", []float64{10, -1, 10.1}, []float64{10, 10.1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := NewOutlierGuard(GuardConfig{Action: GuardActionReject, MaxMovePercent: 40})
+			points := make([]PricePoint, len(tt.closes))
+			for i, c := range tt.closes {
+				points[i] = PricePoint{Close: c, AdjClose: c, High: c, Low: c}
+			}
+
+			var got []float64
+			for _, point := range guard.FilterHistory("TEST", points) {
+				got = append(got, point.AdjClose)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterHistory(%v) kept %v, want %v", tt.closes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterHistoryFlagKeepsMoves(t *testing.T) {
+	guard := NewOutlierGuard(GuardConfig{Action: GuardActionFlag, MaxMovePercent: 40})
+	points := []PricePoint{{Close: 10, AdjClose: 10}, {Close: 100, AdjClose: 100}, {Close: 0, AdjClose: 0}, {Close: 10, AdjClose: 10}}
+
+	if got := guard.FilterHistory("TEST", points); len(got) != 3 {
+		t.Errorf("flag mode kept %d bars, want 3", len(got))
+	}
+	if got := len(guard.Quarantined()); got != 2 {
+		t.Errorf("quarantined %d bars, want 2", got)
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthProbeTimeout bounds each dependency probe
+const healthProbeTimeout = 3 * time.Second
+
+// healthCacheTTL keeps frequent health checks from hammering upstream providers
+const healthCacheTTL = 10 * time.Second
+
+// Dependency status values
+const (
+	depUp       = "up"
+	depDown     = "down"
+	depDisabled = "disabled"
+)
+
+// DependencyHealth is the probe result for one dependency
+type DependencyHealth struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// CacheStats summarizes the quote cache
+type CacheStats struct {
+	Entries int     `json:"entries"`
+	Hits    uint64  `json:"hits"`
+	Misses  uint64  `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
+}
+
+// HealthReport is the deep health check response
+type HealthReport struct {
+	Status       string             `json:"status"` // healthy, degraded or unhealthy
+	Timestamp    string             `json:"timestamp"`
+	Service      string             `json:"service"`
+	Version      string             `json:"version"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+	Cache        CacheStats         `json:"cache"`
+}
+
+// healthProbe checks one dependency; a nil check reports the dependency as disabled
+type healthProbe struct {
+	name     string
+	critical bool
+	check    func(ctx context.Context) error
+}
+
+// healthState caches the last dependency probe results
+type healthState struct {
+	mu        sync.Mutex
+	results   []DependencyHealth
+	checkedAt time.Time
+}
+
+// probes lists the dependencies checked by /health
+func (s *Server) probes() []healthProbe {
+	probes := []healthProbe{
+		{name: "yahoo_finance", critical: true, check: s.api.probeUpstream},
+		{name: "cache", critical: true, check: s.api.probeCache},
+	}
+
+	database := healthProbe{name: "quote_database", critical: true}
+	if s.api.quotes != nil {
+		database.check = s.api.quotes.Ping
+	}
+	probes = append(probes, database)
+
+	fred := healthProbe{name: "fred"}
+	if s.rates.Enabled() {
+		fred.check = func(ctx context.Context) error {
+			_, err := s.rates.GetLatest(ctx, "DGS10")
+			return err
+		}
+	}
+	return append(probes, fred)
+}
+
+// CheckDependencies probes every dependency concurrently, reusing results younger than healthCacheTTL
+func (s *Server) CheckDependencies(ctx context.Context) []DependencyHealth {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+
+	if s.health.results != nil && time.Since(s.health.checkedAt) < healthCacheTTL {
+		return s.health.results
+	}
+
+	probes := s.probes()
+	results := make([]DependencyHealth, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		results[i] = DependencyHealth{Name: probe.name, Critical: probe.critical, Status: depDisabled}
+		if probe.check == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func(result *DependencyHealth, check func(context.Context) error) {
+			defer wg.Done()
+			defer recoverPanic(map[string]string{"component": "health", "dependency": result.Name})
+
+			probeCtx, cancel := context.WithTimeout(ctx, healthProbeTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check(probeCtx)
+			result.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+			result.Status = depUp
+			if err != nil {
+				result.Status = depDown
+				// /health is public, so no request URL, and no API key in one
+				result.Error = withoutURL(err).Error()
+			}
+		}(&results[i], probe.check)
+	}
+	wg.Wait()
+
+	s.health.results = results
+	s.health.checkedAt = time.Now()
+	return results
+}
+
+// probeUpstream fetches a one-day chart for a liquid symbol, bypassing the cache
+func (yf *YahooFinanceAPI) probeUpstream(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		yf.chartURL+"/v8/finance/chart/SPY?range=1d&interval=1d", nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", yf.userAgent)
+
+	resp, err := yf.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &UpstreamStatusError{Provider: "Yahoo Finance", StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// probeCache round-trips a value through the cache
+func (yf *YahooFinanceAPI) probeCache(ctx context.Context) error {
+	value := time.Now().UnixNano()
+	yf.cache.Set("health_probe", value)
+	cached, found := yf.cache.Get("health_probe")
+	if !found || cached != value {
+		return fmt.Errorf("cache did not return the probe value")
+	}
+	return nil
+}
+
+// handleHealth reports per-dependency status and latency, answering 503 when a critical dependency is down
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	deps := s.CheckDependencies(r.Context())
+
+	report := HealthReport{
+		Status:       "healthy",
+		Timestamp:    time.Now().Format(time.RFC3339),
+		Service:      "yahoo-finance-go",
+		Version:      apiVersion,
+		Dependencies: deps,
+		Cache:        s.api.cache.Stats(),
+	}
+
+	code := http.StatusOK
+	for _, dep := range deps {
+		if dep.Status != depDown {
+			continue
+		}
+		if dep.Critical {
+			report.Status = "unhealthy"
+			code = http.StatusServiceUnavailable
+			break
+		}
+		report.Status = "degraded"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PricePoint represents a single daily bar
+type PricePoint struct {
+	Date     string  `json:"date"`
+	Open     float64 `json:"open"`
+	High     float64 `json:"high"`
+	Low      float64 `json:"low"`
+	Close    float64 `json:"close"`
+	AdjClose float64 `json:"adj_close"`
+	Volume   int64   `json:"volume"`
+}
+
+// Dividend is a cash distribution, keyed by its ex-date
+type Dividend struct {
+	Date   string  `json:"date"`
+	Amount float64 `json:"amount"` // per share, on the current share basis
+}
+
+// Split is a stock split or reverse split; Numerator new shares replace Denominator old ones
+type Split struct {
+	Date        string  `json:"date"`
+	Numerator   float64 `json:"numerator"`
+	Denominator float64 `json:"denominator"`
+	Ratio       string  `json:"ratio"` // as Yahoo writes it, e.g. 4:1
+}
+
+// PriceHistory is a symbol's daily bars with the corporate actions over the same range
+type PriceHistory struct {
+	Currency  string       `json:"currency,omitempty"`
+	Points    []PricePoint `json:"points"`
+	Dividends []Dividend   `json:"dividends"`
+	Splits    []Split      `json:"splits"`
+}
+
+// HistoryResult is the /history response
+type HistoryResult struct {
+	Symbol    string       `json:"symbol"`
+	Period    string       `json:"period"`
+	Adjusted  bool         `json:"adjusted"`
+	Currency  string       `json:"currency,omitempty"`
+	Points    []PricePoint `json:"points"`
+	Dividends []Dividend   `json:"dividends"`
+	Splits    []Split      `json:"splits"`
+	Timestamp string       `json:"timestamp"`
+}
+
+// historyPeriods are the chart ranges accepted by /history
+var historyPeriods = map[string]bool{
+	"1mo": true, "3mo": true, "6mo": true, "ytd": true,
+	"1y": true, "2y": true, "5y": true, "10y": true, "max": true,
+}
+
+// GetHistory fetches daily price history for the given range (e.g. "1y", "2y") with caching
+func (yf *YahooFinanceAPI) GetHistory(symbol, period string) ([]PricePoint, error) {
+	history, err := yf.GetPriceHistory(symbol, period)
+	if err != nil {
+		return nil, err
+	}
+	return history.Points, nil
+}
+
+// GetPriceHistory fetches daily bars together with dividends and splits, with caching
+func (yf *YahooFinanceAPI) GetPriceHistory(symbol, period string) (*PriceHistory, error) {
+	symbol = canonicalSymbol(symbol)
+	cacheKey := fmt.Sprintf("history_%s_%s", symbol, period)
+	if cached, found := yf.cache.Get(cacheKey); found {
+		if history, ok := cached.(*PriceHistory); ok {
+			return history, nil
+		}
+	}
+
+	return coalesce(&yf.flights, cacheKey, func() (*PriceHistory, error) {
+		return yf.loadHistory(symbol, period, cacheKey)
+	})
+}
+
+// loadHistory validates symbol, fetches its daily bars from Yahoo and caches them under cacheKey
+func (yf *YahooFinanceAPI) loadHistory(symbol, period, cacheKey string) (*PriceHistory, error) {
+	var stored PriceHistory
+	if expiresAt, ok := yf.disk.Load(cacheKey, &stored); ok {
+		yf.cache.SetUntil(cacheKey, &stored, expiresAt)
+		return &stored, nil
+	}
+
+	if err := yf.CheckSymbol(symbol); err != nil {
+		return nil, err
+	}
+
+	history, err := yf.fetchHistory(symbol, period)
+	if err != nil {
+		return nil, err
+	}
+	history.Points = yf.guard.FilterHistory(strings.ToUpper(symbol), history.Points)
+	yf.freshness.Touch("history")
+
+	yf.cache.Set(cacheKey, history)
+	yf.disk.Store(cacheKey, history)
+	return history, nil
+}
+
+// GetHistoryResult returns history for the /history endpoint, optionally adjusted for
+// splits and dividends
+func (yf *YahooFinanceAPI) GetHistoryResult(symbol, period string, adjusted bool) (*HistoryResult, error) {
+	history, err := yf.GetPriceHistory(symbol, period)
+	if err != nil {
+		return nil, err
+	}
+
+	points := history.Points
+	if adjusted {
+		points = adjustForActions(points, history.Dividends, history.Splits)
+	}
+	return &HistoryResult{
+		Symbol:    canonicalSymbol(symbol),
+		Period:    period,
+		Adjusted:  adjusted,
+		Currency:  history.Currency,
+		Points:    append([]PricePoint{}, points...),
+		Dividends: append([]Dividend{}, history.Dividends...),
+		Splits:    append([]Split{}, history.Splits...),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// adjustForActions back-adjusts bars so that returns computed across split and ex-dividend
+// dates reflect only price changes. Bars before a split are divided by the split ratio and
+// their volume multiplied by it; bars before an ex-date are scaled by 1 - dividend/prior close,
+// the usual total-return factor. The input is not modified.
+func adjustForActions(points []PricePoint, dividends []Dividend, splits []Split) []PricePoint {
+	adjusted := append([]PricePoint{}, points...)
+
+	for _, split := range splits {
+		k := barIndex(adjusted, split.Date)
+		if k <= 0 || split.Numerator <= 0 || split.Denominator <= 0 {
+			continue
+		}
+		ratio := split.Numerator / split.Denominator
+		if !splitInPrices(adjusted, k, ratio) {
+			continue // Yahoo already split-adjusts most series; do not divide twice
+		}
+		for i := 0; i < k; i++ {
+			adjusted[i].Open /= ratio
+			adjusted[i].High /= ratio
+			adjusted[i].Low /= ratio
+			adjusted[i].Close /= ratio
+			adjusted[i].Volume = int64(math.Round(float64(adjusted[i].Volume) * ratio))
+		}
+	}
+
+	// Dividend factors use split-adjusted closes that do not yet include other dividends
+	factors := make([]float64, len(adjusted))
+	for i := range factors {
+		factors[i] = 1
+	}
+	for _, dividend := range dividends {
+		k := barIndex(adjusted, dividend.Date)
+		if k <= 0 || dividend.Amount <= 0 || adjusted[k-1].Close <= dividend.Amount {
+			continue
+		}
+		factor := 1 - dividend.Amount/adjusted[k-1].Close
+		for i := 0; i < k; i++ {
+			factors[i] *= factor
+		}
+	}
+	for i := range adjusted {
+		adjusted[i].Open *= factors[i]
+		adjusted[i].High *= factors[i]
+		adjusted[i].Low *= factors[i]
+		adjusted[i].Close *= factors[i]
+		adjusted[i].AdjClose = adjusted[i].Close
+	}
+	return adjusted
+}
+
+// barIndex returns the index of the first bar on or after date, or -1 if there is none
+func barIndex(points []PricePoint, date string) int {
+	i := sort.Search(len(points), func(i int) bool { return points[i].Date >= date })
+	if i == len(points) {
+		return -1
+	}
+	return i
+}
+
+// splitInPrices reports whether the bars still show the split at index k, i.e. whether the
+// price gap between the previous close and the split-day open is nearer the split ratio than 1
+func splitInPrices(points []PricePoint, k int, ratio float64) bool {
+	before := points[k-1].Close
+	after := points[k].Open
+	if after <= 0 {
+		after = points[k].Close
+	}
+	if before <= 0 || after <= 0 || ratio == 1 {
+		return false
+	}
+	gap := math.Log(before / after)
+	return math.Abs(gap-math.Log(ratio)) < math.Abs(gap)
+}
+
+// fetchHistory calls the chart API for daily bars and corporate actions over the given range
+func (yf *YahooFinanceAPI) fetchHistory(symbol, period string) (*PriceHistory, error) {
+	url := fmt.Sprintf("%s/v8/finance/chart/%s?range=%s&interval=1d&events=div%%7Csplit",
+		yf.chartURL, strings.ToUpper(symbol), period)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", yf.userAgent)
+
+	resp, err := yf.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UpstreamStatusError{Provider: "Yahoo Finance", StatusCode: resp.StatusCode}
+	}
+
+	var chartResp struct {
+		Chart struct {
+			Result []struct {
+				Meta struct {
+					Currency string `json:"currency"`
+				} `json:"meta"`
+				Timestamp  []int64 `json:"timestamp"`
+				Indicators struct {
+					Quote []struct {
+						Open   []float64 `json:"open"`
+						Low    []float64 `json:"low"`
+						High   []float64 `json:"high"`
+						Close  []float64 `json:"close"`
+						Volume []int64   `json:"volume"`
+					} `json:"quote"`
+					AdjClose []struct {
+						AdjClose []float64 `json:"adjclose"`
+					} `json:"adjclose"`
+				} `json:"indicators"`
+				Events struct {
+					Dividends map[string]struct {
+						Amount float64 `json:"amount"`
+						Date   int64   `json:"date"`
+					} `json:"dividends"`
+					Splits map[string]struct {
+						Date        int64   `json:"date"`
+						Numerator   float64 `json:"numerator"`
+						Denominator float64 `json:"denominator"`
+						SplitRatio  string  `json:"splitRatio"`
+					} `json:"splits"`
+				} `json:"events"`
+			} `json:"result"`
+		} `json:"chart"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&chartResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	if len(chartResp.Chart.Result) == 0 || len(chartResp.Chart.Result[0].Indicators.Quote) == 0 {
+		return nil, fmt.Errorf("no history found for symbol %s: %w", symbol, ErrSymbolNotFound)
+	}
+
+	result := chartResp.Chart.Result[0]
+	quote := result.Indicators.Quote[0]
+	var adjClose []float64
+	if len(result.Indicators.AdjClose) > 0 {
+		adjClose = result.Indicators.AdjClose[0].AdjClose
+	}
+
+	points := make([]PricePoint, 0, len(result.Timestamp))
+	for i, ts := range result.Timestamp {
+		// Yahoo returns nulls for missing bars, which decode as zero
+		if i >= len(quote.Close) || quote.Close[i] == 0 {
+			continue
+		}
+		point := PricePoint{
+			Date:     time.Unix(ts, 0).UTC().Format("2006-01-02"),
+			Close:    quote.Close[i],
+			AdjClose: quote.Close[i],
+		}
+		if i < len(quote.Open) {
+			point.Open = quote.Open[i]
+		}
+		if i < len(quote.High) {
+			point.High = quote.High[i]
+		}
+		if i < len(quote.Low) {
+			point.Low = quote.Low[i]
+		}
+		if i < len(quote.Volume) {
+			point.Volume = quote.Volume[i]
+		}
+		if i < len(adjClose) && adjClose[i] != 0 {
+			point.AdjClose = adjClose[i]
+		}
+		points = append(points, point)
+	}
+
+	history := &PriceHistory{Currency: result.Meta.Currency, Points: points, Dividends: []Dividend{}, Splits: []Split{}}
+	for _, d := range result.Events.Dividends {
+		history.Dividends = append(history.Dividends, Dividend{
+			Date:   time.Unix(d.Date, 0).UTC().Format("2006-01-02"),
+			Amount: d.Amount,
+		})
+	}
+	for _, sp := range result.Events.Splits {
+		history.Splits = append(history.Splits, Split{
+			Date:        time.Unix(sp.Date, 0).UTC().Format("2006-01-02"),
+			Numerator:   sp.Numerator,
+			Denominator: sp.Denominator,
+			Ratio:       sp.SplitRatio,
+		})
+	}
+	sort.Slice(history.Dividends, func(i, j int) bool { return history.Dividends[i].Date < history.Dividends[j].Date })
+	sort.Slice(history.Splits, func(i, j int) bool { return history.Splits[i].Date < history.Splits[j].Date })
+	normalizeHistoryCurrency(history)
+	return history, nil
+}
+
+// handleHistory handles daily price history requests
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = "1y"
+	}
+	if !historyPeriods[period] {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "period must be one of 1mo, 3mo, 6mo, ytd, 1y, 2y, 5y, 10y or max")
+		return
+	}
+
+	adjusted := false
+	if value := r.URL.Query().Get("adjusted"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "adjusted must be true or false")
+			return
+		}
+		adjusted = parsed
+	}
+
+	start := time.Now()
+	data, err := s.api.GetHistoryResult(symbol, period, adjusted)
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CompanyIdentifiers holds the cross-reference identifiers for an issuer
+type CompanyIdentifiers struct {
+	Symbol         string    `json:"symbol"`
+	Name           string    `json:"name"`
+	LEI            string    `json:"lei,omitempty"`
+	LegalName      string    `json:"legal_name,omitempty"`
+	ISINs          []string  `json:"isins,omitempty"`
+	CUSIPs         []string  `json:"cusips,omitempty"`
+	FIGI           string    `json:"figi,omitempty"`
+	CompositeFIGI  string    `json:"composite_figi,omitempty"`
+	ShareClassFIGI string    `json:"share_class_figi,omitempty"`
+	EnrichedAt     time.Time `json:"enriched_at"`
+}
+
+// CompanyRegistry stores enriched issuer identifiers keyed by symbol
+type CompanyRegistry struct {
+	path      string
+	companies map[string]*CompanyIdentifiers
+	mu        sync.RWMutex
+	client    *http.Client
+	figiKey   string
+	api       *YahooFinanceAPI
+	maxAge    time.Duration
+}
+
+// NewCompanyRegistry loads the registry from path and configures the GLEIF/OpenFIGI clients
+func NewCompanyRegistry(path, openFIGIKey string, api *YahooFinanceAPI) (*CompanyRegistry, error) {
+	registry := &CompanyRegistry{
+		path:      path,
+		companies: make(map[string]*CompanyIdentifiers),
+		client:    &http.Client{Timeout: 15 * time.Second},
+		figiKey:   openFIGIKey,
+		api:       api,
+		maxAge:    30 * 24 * time.Hour,
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return registry, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading company registry: %w", err)
+	}
+
+	var companies []*CompanyIdentifiers
+	if err := json.Unmarshal(raw, &companies); err != nil {
+		return nil, fmt.Errorf("decoding company registry: %w", err)
+	}
+	for _, company := range companies {
+		registry.companies[company.Symbol] = company
+	}
+	return registry, nil
+}
+
+// Get returns identifiers for a symbol, enriching the registry when missing or stale
+func (cr *CompanyRegistry) Get(symbol string, refresh bool) (*CompanyIdentifiers, error) {
+	symbol = strings.ToUpper(symbol)
+
+	cr.mu.RLock()
+	existing, ok := cr.companies[symbol]
+	cr.mu.RUnlock()
+	if ok && !refresh && time.Since(existing.EnrichedAt) < cr.maxAge {
+		return existing, nil
+	}
+
+	enriched, err := cr.enrich(symbol)
+	if err != nil {
+		if ok {
+			log.Printf("Re-enrichment failed for %s, serving stored identifiers: %v", symbol, err)
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	cr.mu.Lock()
+	cr.companies[symbol] = enriched
+	err = cr.save()
+	cr.mu.Unlock()
+	if err != nil {
+		log.Printf("Failed to persist company registry: %v", err)
+	}
+
+	return enriched, nil
+}
+
+// enrich resolves FIGIs via OpenFIGI and LEI/ISINs via GLEIF
+func (cr *CompanyRegistry) enrich(symbol string) (*CompanyIdentifiers, error) {
+	ids := &CompanyIdentifiers{Symbol: symbol, EnrichedAt: time.Now()}
+
+	if summary, err := cr.api.GetQuoteSummary(symbol, "price"); err == nil {
+		ids.Name = summary.Price.LongName
+		if ids.Name == "" {
+			ids.Name = summary.Price.ShortName
+		}
+	}
+
+	if err := cr.lookupFIGI(ids); err != nil {
+		log.Printf("OpenFIGI lookup failed for %s: %v", symbol, err)
+	}
+	if ids.Name == "" {
+		return nil, fmt.Errorf("could not resolve company name for %s", symbol)
+	}
+
+	if err := cr.lookupLEI(ids); err != nil {
+		log.Printf("GLEIF lookup failed for %s: %v", symbol, err)
+	}
+
+	for _, isin := range ids.ISINs {
+		if cusip := cusipFromISIN(isin); cusip != "" {
+			ids.CUSIPs = append(ids.CUSIPs, cusip)
+		}
+	}
+
+	return ids, nil
+}
+
+// lookupFIGI maps a ticker to FIGIs using the OpenFIGI mapping API
+func (cr *CompanyRegistry) lookupFIGI(ids *CompanyIdentifiers) error {
+	payload, _ := json.Marshal([]map[string]string{
+		{"idType": "TICKER", "idValue": strings.ReplaceAll(ids.Symbol, "-", "/"), "exchCode": "US"},
+	})
+
+	req, err := http.NewRequest("POST", "https://api.openfigi.com/v3/mapping", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cr.figiKey != "" {
+		req.Header.Set("X-OPENFIGI-APIKEY", cr.figiKey)
+	}
+
+	resp, err := cr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &UpstreamStatusError{Provider: "OpenFIGI", StatusCode: resp.StatusCode}
+	}
+
+	var results []struct {
+		Data []struct {
+			FIGI           string `json:"figi"`
+			Name           string `json:"name"`
+			CompositeFIGI  string `json:"compositeFIGI"`
+			ShareClassFIGI string `json:"shareClassFIGI"`
+		} `json:"data"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return err
+	}
+	if len(results) == 0 || len(results[0].Data) == 0 {
+		if len(results) > 0 && results[0].Error != "" {
+			return fmt.Errorf("%s", results[0].Error)
+		}
+		return fmt.Errorf("no FIGI match")
+	}
+
+	match := results[0].Data[0]
+	ids.FIGI = match.FIGI
+	ids.CompositeFIGI = match.CompositeFIGI
+	ids.ShareClassFIGI = match.ShareClassFIGI
+	if ids.Name == "" {
+		ids.Name = match.Name
+	}
+	return nil
+}
+
+// lookupLEI finds the issuer's LEI by legal name and then its ISINs
+func (cr *CompanyRegistry) lookupLEI(ids *CompanyIdentifiers) error {
+	params := url.Values{
+		"field": {"entity.legalName"},
+		"q":     {ids.Name},
+	}
+	var completions struct {
+		Data []struct {
+			Attributes struct {
+				Value string `json:"value"`
+			} `json:"attributes"`
+			Relationships struct {
+				LEIRecords struct {
+					Data struct {
+						ID string `json:"id"`
+					} `json:"data"`
+				} `json:"lei-records"`
+			} `json:"relationships"`
+		} `json:"data"`
+	}
+	if err := cr.getJSON("https://api.gleif.org/api/v1/fuzzycompletions?"+params.Encode(), &completions); err != nil {
+		return err
+	}
+	if len(completions.Data) == 0 || completions.Data[0].Relationships.LEIRecords.Data.ID == "" {
+		return fmt.Errorf("no LEI match for %q", ids.Name)
+	}
+
+	ids.LEI = completions.Data[0].Relationships.LEIRecords.Data.ID
+	ids.LegalName = completions.Data[0].Attributes.Value
+
+	var isins struct {
+		Data []struct {
+			Attributes struct {
+				ISIN string `json:"isin"`
+			} `json:"attributes"`
+		} `json:"data"`
+	}
+	if err := cr.getJSON(fmt.Sprintf("https://api.gleif.org/api/v1/lei-records/%s/isins?page[size]=50", ids.LEI), &isins); err != nil {
+		return err
+	}
+	for _, item := range isins.Data {
+		if item.Attributes.ISIN != "" {
+			ids.ISINs = append(ids.ISINs, item.Attributes.ISIN)
+		}
+	}
+	return nil
+}
+
+// getJSON performs a GET against the GLEIF API
+func (cr *CompanyRegistry) getJSON(endpoint string, v interface{}) error {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.api+json")
+
+	resp, err := cr.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &UpstreamStatusError{Provider: "GLEIF", StatusCode: resp.StatusCode}
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// save writes the registry to disk; callers must hold the write lock
+func (cr *CompanyRegistry) save() error {
+	companies := make([]*CompanyIdentifiers, 0, len(cr.companies))
+	for _, company := range cr.companies {
+		companies = append(companies, company)
+	}
+
+	raw, err := json.MarshalIndent(companies, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cr.path), 0755); err != nil {
+		return err
+	}
+	tmp := cr.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, cr.path)
+}
+
+// cusipFromISIN extracts the CUSIP embedded in US and Canadian ISINs
+func cusipFromISIN(isin string) string {
+	if len(isin) != 12 {
+		return ""
+	}
+	if strings.HasPrefix(isin, "US") || strings.HasPrefix(isin, "CA") {
+		return isin[2:11]
+	}
+	return ""
+}
+
+// handleIdentifiers handles issuer identifier lookups
+func (s *Server) handleIdentifiers(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	start := time.Now()
+	data, err := s.registry.Get(symbol, r.URL.Query().Get("refresh") == "true")
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Insider transaction directions
+const (
+	InsiderBuy   = "buy"
+	InsiderSell  = "sell"
+	InsiderOther = "other"
+)
+
+// sellClusterInsiders is how many distinct insiders selling on the open market within
+// sellClusterWindow counts as a cluster
+const (
+	sellClusterInsiders = 3
+	sellClusterWindow   = 30 * 24 * time.Hour
+)
+
+// InsiderTransaction is one Form 4 transaction by a company insider
+type InsiderTransaction struct {
+	Name            string  `json:"name"`
+	TransactionCode string  `json:"transaction_code"` // Form 4 code, e.g. P, S, M, F
+	Type            string  `json:"type"`             // buy, sell or other
+	Change          int64   `json:"change"`           // shares acquired (positive) or disposed of (negative)
+	SharesHeld      int64   `json:"shares_held"`      // holding after the transaction
+	Price           float64 `json:"price,omitempty"`
+	Value           float64 `json:"value,omitempty"`
+	TransactionDate string  `json:"transaction_date"`
+	FilingDate      string  `json:"filing_date"`
+}
+
+// InsiderSummary aggregates open-market purchases and sales over the requested window
+type InsiderSummary struct {
+	Buys            int     `json:"buys"`
+	Sells           int     `json:"sells"`
+	SharesBought    int64   `json:"shares_bought"`
+	SharesSold      int64   `json:"shares_sold"`
+	NetValue        float64 `json:"net_value"` // purchases minus sales in dollars
+	DistinctSellers int     `json:"distinct_sellers"`
+	SellCluster     bool    `json:"sell_cluster"` // several insiders sold within a month of each other
+}
+
+// InsiderTransactionsResult lists recent insider transactions for a symbol
+type InsiderTransactionsResult struct {
+	Symbol       string               `json:"symbol"`
+	Days         int                  `json:"days"`
+	Summary      InsiderSummary       `json:"summary"`
+	Transactions []InsiderTransaction `json:"transactions"`
+	Timestamp    string               `json:"timestamp"`
+}
+
+// FinnhubClient fetches insider transactions from the Finnhub API
+type FinnhubClient struct {
+	apiKey string
+	client *http.Client
+	cache  *Cache
+
+	freshness *Freshness
+}
+
+// NewFinnhubClient creates a Finnhub client; filings arrive a few times a day at most
+func NewFinnhubClient(apiKey string) *FinnhubClient {
+	return &FinnhubClient{
+		apiKey: apiKey,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		cache: NewCache(6 * time.Hour),
+	}
+}
+
+// Enabled reports whether an API key is configured
+func (fc *FinnhubClient) Enabled() bool {
+	return fc.apiKey != ""
+}
+
+// GetInsiderTransactions returns the transactions filed for symbol in the last days, newest first
+func (fc *FinnhubClient) GetInsiderTransactions(ctx context.Context, symbol string, days int) (*InsiderTransactionsResult, error) {
+	symbol, err := validSymbol(symbol)
+	if err != nil {
+		return nil, err
+	}
+	from := time.Now().AddDate(0, 0, -days).Format("2006-01-02")
+
+	cacheKey := fmt.Sprintf("insider_%s_%s", symbol, from)
+	var transactions []InsiderTransaction
+	if cached, found := fc.cache.Get(cacheKey); found {
+		transactions, _ = cached.([]InsiderTransaction)
+	} else {
+		fetched, err := fc.fetchInsiderTransactions(ctx, symbol, from)
+		if err != nil {
+			return nil, err
+		}
+		transactions = fetched
+		fc.cache.Set(cacheKey, transactions)
+		fc.freshness.Touch("insider_transactions")
+	}
+
+	return &InsiderTransactionsResult{
+		Symbol:       symbol,
+		Days:         days,
+		Summary:      summarizeInsiders(transactions),
+		Transactions: append([]InsiderTransaction{}, transactions...),
+		Timestamp:    time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// fetchInsiderTransactions calls the Finnhub insider transactions endpoint
+func (fc *FinnhubClient) fetchInsiderTransactions(ctx context.Context, symbol, from string) ([]InsiderTransaction, error) {
+	params := url.Values{
+		"symbol": {symbol},
+		"from":   {from},
+		"to":     {time.Now().Format("2006-01-02")},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://finnhub.io/api/v1/stock/insider-transactions?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	// In a header rather than the query, where errors quoting the URL would show it
+	req.Header.Set("X-Finnhub-Token", fc.apiKey)
+
+	resp, err := fc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UpstreamStatusError{Provider: "Finnhub", StatusCode: resp.StatusCode}
+	}
+
+	var finnhubResp struct {
+		Data []struct {
+			Name            string  `json:"name"`
+			Share           int64   `json:"share"`
+			Change          int64   `json:"change"`
+			FilingDate      string  `json:"filingDate"`
+			TransactionDate string  `json:"transactionDate"`
+			TransactionCode string  `json:"transactionCode"`
+			Price           float64 `json:"transactionPrice"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&finnhubResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	transactions := make([]InsiderTransaction, 0, len(finnhubResp.Data))
+	for _, d := range finnhubResp.Data {
+		t := InsiderTransaction{
+			Name:            d.Name,
+			TransactionCode: d.TransactionCode,
+			Type:            insiderType(d.TransactionCode),
+			Change:          d.Change,
+			SharesHeld:      d.Share,
+			Price:           d.Price,
+			TransactionDate: d.TransactionDate,
+			FilingDate:      d.FilingDate,
+		}
+		if d.Price > 0 {
+			t.Value = float64(d.Change) * d.Price
+		}
+		transactions = append(transactions, t)
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].TransactionDate > transactions[j].TransactionDate
+	})
+	return transactions, nil
+}
+
+// insiderType classifies a Form 4 transaction code. Only open-market purchases (P) and sales (S)
+// are discretionary; awards, option exercises and tax withholding say little about conviction.
+func insiderType(code string) string {
+	switch code {
+	case "P":
+		return InsiderBuy
+	case "S":
+		return InsiderSell
+	default:
+		return InsiderOther
+	}
+}
+
+// summarizeInsiders totals open-market activity and flags clusters of insiders selling together
+func summarizeInsiders(transactions []InsiderTransaction) InsiderSummary {
+	var summary InsiderSummary
+	sellers := make(map[string]bool)
+	sellDates := make(map[string]time.Time) // latest sale per insider
+	for _, t := range transactions {
+		switch t.Type {
+		case InsiderBuy:
+			summary.Buys++
+			summary.SharesBought += abs64(t.Change)
+			summary.NetValue += math.Abs(t.Value)
+		case InsiderSell:
+			summary.Sells++
+			summary.SharesSold += abs64(t.Change)
+			summary.NetValue -= math.Abs(t.Value)
+			sellers[t.Name] = true
+			date, err := time.Parse("2006-01-02", t.TransactionDate)
+			if err == nil && date.After(sellDates[t.Name]) {
+				sellDates[t.Name] = date
+			}
+		}
+	}
+	summary.DistinctSellers = len(sellers)
+
+	// Slide a window over each insider's latest sale looking for enough distinct sellers
+	dates := make([]time.Time, 0, len(sellDates))
+	for _, date := range sellDates {
+		dates = append(dates, date)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+	for i := 0; i+sellClusterInsiders <= len(dates); i++ {
+		if dates[i+sellClusterInsiders-1].Sub(dates[i]) <= sellClusterWindow {
+			summary.SellCluster = true
+			break
+		}
+	}
+	return summary
+}
+
+// abs64 returns the absolute value of n
+func abs64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// handleInsiderTransactions handles insider transaction requests
+func (s *Server) handleInsiderTransactions(w http.ResponseWriter, r *http.Request) {
+	if !s.insider.Enabled() {
+		writeError(w, r, http.StatusServiceUnavailable, CodeNotConfigured, "insider transactions are unavailable: FINNHUB_API_KEY is not configured")
+		return
+	}
+
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	days := 90
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 730 {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "days must be between 1 and 730")
+			return
+		}
+		days = parsed
+	}
+
+	start := time.Now()
+	data, err := s.insider.GetInsiderTransactions(r.Context(), symbol, days)
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
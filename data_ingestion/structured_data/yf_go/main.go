@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
+
+	"golang.org/x/sync/singleflight"
+	"yahoo-finance-go/config"
 )
 
 // FinancialData represents stock information
@@ -24,6 +33,17 @@ type FinancialData struct {
 	Change     float64 `json:"change"`
 	ChangePerc float64 `json:"change_percent"`
 	Timestamp  string  `json:"timestamp"`
+	Currency   string  `json:"currency,omitempty"`
+	Exchange   string  `json:"exchange,omitempty"`
+	AssetType  string  `json:"asset_type"`
+
+	Fund   *FundDetails   `json:"fund,omitempty"`   // ETFs and mutual funds
+	Crypto *CryptoDetails `json:"crypto,omitempty"` // cryptocurrencies
+
+	OriginalCurrency string  `json:"original_currency,omitempty"`
+	ConversionRate   float64 `json:"conversion_rate,omitempty"`
+
+	QualityFlags []string `json:"quality_flags,omitempty"`
 }
 
 // CacheEntry holds cached data with expiration
@@ -37,6 +57,9 @@ type Cache struct {
 	data map[string]CacheEntry
 	mu   sync.RWMutex
 	ttl  time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
 }
 
 // NewCache creates a new cache with specified TTL
@@ -58,12 +81,27 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 
 	entry, exists := c.data[key]
 	if !exists || time.Now().After(entry.ExpiresAt) {
+		c.misses.Add(1)
 		return nil, false
 	}
 
+	c.hits.Add(1)
 	return entry.Data, true
 }
 
+// Stats returns the entry count and lookup counters
+func (c *Cache) Stats() CacheStats {
+	c.mu.RLock()
+	entries := len(c.data)
+	c.mu.RUnlock()
+
+	stats := CacheStats{Entries: entries, Hits: c.hits.Load(), Misses: c.misses.Load()}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
 // Set stores data in cache with TTL
 func (c *Cache) Set(key string, value interface{}) {
 	c.mu.Lock()
@@ -75,6 +113,40 @@ func (c *Cache) Set(key string, value interface{}) {
 	}
 }
 
+// SetUntil stores data that expires at a fixed time, e.g. an entry restored from disk
+func (c *Cache) SetUntil(key string, value interface{}, expiresAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data[key] = CacheEntry{
+		Data:      value,
+		ExpiresAt: expiresAt,
+	}
+}
+
+// Delete removes key from the cache
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.data, key)
+}
+
+// Keys returns the unexpired keys starting with prefix
+func (c *Cache) Keys(prefix string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	var keys []string
+	for key, entry := range c.data {
+		if strings.HasPrefix(key, prefix) && now.Before(entry.ExpiresAt) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
 // cleanup removes expired entries
 func (c *Cache) cleanup() {
 	ticker := time.NewTicker(time.Minute)
@@ -94,24 +166,70 @@ func (c *Cache) cleanup() {
 
 // YahooFinanceAPI handles API calls to Yahoo Finance
 type YahooFinanceAPI struct {
-	client *http.Client
-	cache  *Cache
+	client        *http.Client
+	cache         *Cache
+	detector      *ChangeDetector // nil when change events are disabled
+	disk          *DiskCache      // optional persistent layer below cache; nil when disabled
+	guard         *OutlierGuard
+	quotes        *QuoteStore        // optional quote history persistence
+	symbols       *SymbolUniverse    // nil when universe checks are disabled
+	breakers      *Breakers          // per-provider circuit breakers shared by every upstream client
+	freshness     *Freshness         // when each feature last loaded from its provider
+	lowConfidence *Cache             // scores computed from incomplete inputs, for /status
+	flights       singleflight.Group // coalesces concurrent upstream fetches for the same cache key
+	chartURL      string             // base URL of the chart API, normally https://query1.finance.yahoo.com
+	queryURL      string             // base URL of the quoteSummary and search APIs
+	userAgent     string
+	concurrency   int
 }
 
 // NewYahooFinanceAPI creates a new API client
-func NewYahooFinanceAPI() *YahooFinanceAPI {
-	return &YahooFinanceAPI{
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		cache: NewCache(5 * time.Minute), // 5-minute cache
+func NewYahooFinanceAPI(cfg *config.Config) *YahooFinanceAPI {
+	breakers := NewBreakers(cfg.Upstream.BreakerThreshold, cfg.Upstream.BreakerCooldown)
+	api := &YahooFinanceAPI{
+		client: breakers.Guard(&http.Client{
+			Timeout: cfg.Upstream.Timeout,
+		}),
+		cache: NewCache(cfg.Cache.TTL),
+		guard: NewOutlierGuard(GuardConfig{
+			Action:         cfg.Guard.Action,
+			MaxMovePercent: cfg.Guard.MaxMovePercent,
+			MaxPrice:       cfg.Guard.MaxPrice,
+			LiquidVolume:   cfg.Guard.LiquidVolume,
+			QuarantineFile: cfg.Storage.QuarantineFile,
+		}),
+		breakers:      breakers,
+		freshness:     NewFreshness(),
+		lowConfidence: NewCache(lowConfidenceTTL),
+		chartURL:      strings.TrimRight(cfg.Upstream.ChartURL, "/"),
+		queryURL:      strings.TrimRight(cfg.Upstream.QueryURL, "/"),
+		userAgent:     cfg.Upstream.UserAgent,
+		concurrency:   cfg.Upstream.Concurrency,
+	}
+
+	if cfg.Cache.DiskDir != "" {
+		disk, err := NewDiskCache(cfg.Cache.DiskDir, cfg.Cache.TTL)
+		if err != nil {
+			log.Printf("Disk cache disabled: %v", err)
+		} else {
+			api.disk = disk
+		}
+	}
+
+	if cfg.Symbols.UniverseURL != "" {
+		api.symbols = NewSymbolUniverse(cfg.Symbols.UniverseURL, cfg.Upstream.UserAgent)
+		api.symbols.freshness = api.freshness
+		breakers.Guard(api.symbols.client)
+		api.symbols.Start(cfg.Symbols.RefreshInterval)
 	}
+	return api
 }
 
 // GetStockData fetches stock data with caching
 func (yf *YahooFinanceAPI) GetStockData(symbol string) (*FinancialData, error) {
-	// Check cache first
-	cacheKey := fmt.Sprintf("stock_%s", strings.ToUpper(symbol))
+	// Check cache first; notation variants such as BRK.B share the BRK-B entry
+	symbol = canonicalSymbol(symbol)
+	cacheKey := fmt.Sprintf("stock_%s", symbol)
 	if cached, found := yf.cache.Get(cacheKey); found {
 		if data, ok := cached.(*FinancialData); ok {
 			log.Printf("Cache hit for %s", symbol)
@@ -119,23 +237,74 @@ func (yf *YahooFinanceAPI) GetStockData(symbol string) (*FinancialData, error) {
 		}
 	}
 
+	return coalesce(&yf.flights, cacheKey, func() (*FinancialData, error) {
+		return yf.loadStockData(symbol, cacheKey)
+	})
+}
+
+// loadStockData serves symbol from the disk cache, or else fetches it
+func (yf *YahooFinanceAPI) loadStockData(symbol, cacheKey string) (*FinancialData, error) {
+	var stored FinancialData
+	if expiresAt, ok := yf.disk.Load(cacheKey, &stored); ok {
+		yf.cache.SetUntil(cacheKey, &stored, expiresAt)
+		return &stored, nil
+	}
+	return yf.fetchStockData(symbol, cacheKey)
+}
+
+// fetchStockData validates symbol, fetches its quote from Yahoo and caches it under cacheKey
+// in memory and on disk, until its market next moves
+func (yf *YahooFinanceAPI) fetchStockData(symbol, cacheKey string) (*FinancialData, error) {
+	// Reject malformed and unknown symbols before calling Yahoo
+	if err := yf.CheckSymbol(symbol); err != nil {
+		return nil, err
+	}
+
 	// Fetch from Yahoo Finance API
 	data, err := yf.fetchFromYahoo(symbol)
 	if err != nil {
+		return nil, yf.symbolNotFound(symbol, err)
+	}
+
+	// Reject absurd values before they reach the cache and derived features
+	if err := yf.guard.CheckQuote(data); err != nil {
 		return nil, err
 	}
 
-	// Cache the result
-	yf.cache.Set(cacheKey, data)
+	yf.observe(data)
+
+	// Cache the result; quotes from a closed market stay cached until it reopens
+	expiresAt := ExchangeFor(symbol).QuoteExpiry(time.Now(), yf.cache.ttl)
+	yf.cache.SetUntil(cacheKey, data, expiresAt)
+	yf.disk.StoreUntil(cacheKey, data, expiresAt)
+	yf.freshness.Touch("quotes")
+	yf.persistQuote(data)
+	if yf.symbols != nil {
+		yf.symbols.MarkKnown(data.Symbol)
+	}
 	log.Printf("Fetched and cached data for %s", symbol)
 
 	return data, nil
 }
 
+// observe hands a freshly fetched quote to the change detector if events are enabled
+func (yf *YahooFinanceAPI) observe(data *FinancialData) {
+	if yf.detector != nil {
+		go yf.detector.Observe(data)
+	}
+}
+
+// persistQuote appends a freshly fetched quote to the history store if enabled
+func (yf *YahooFinanceAPI) persistQuote(data *FinancialData) {
+	if yf.quotes != nil {
+		yf.quotes.Append(data)
+	}
+}
+
 // fetchFromYahoo makes the actual API call
 func (yf *YahooFinanceAPI) fetchFromYahoo(symbol string) (*FinancialData, error) {
 	// Yahoo Finance query URL
-	url := fmt.Sprintf("https://query1.finance.yahoo.com/v8/finance/chart/%s", strings.ToUpper(symbol))
+	url := fmt.Sprintf("%s/v8/finance/chart/%s", yf.chartURL, strings.ToUpper(symbol))
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -143,7 +312,7 @@ func (yf *YahooFinanceAPI) fetchFromYahoo(symbol string) (*FinancialData, error)
 	}
 
 	// Set headers
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36")
+	req.Header.Set("User-Agent", yf.userAgent)
 
 	resp, err := yf.client.Do(req)
 	if err != nil {
@@ -152,7 +321,7 @@ func (yf *YahooFinanceAPI) fetchFromYahoo(symbol string) (*FinancialData, error)
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+		return nil, &UpstreamStatusError{Provider: "Yahoo Finance", StatusCode: resp.StatusCode}
 	}
 
 	// Parse Yahoo Finance response
@@ -161,6 +330,7 @@ func (yf *YahooFinanceAPI) fetchFromYahoo(symbol string) (*FinancialData, error)
 			Result []struct {
 				Meta struct {
 					Symbol               string  `json:"symbol"`
+					Currency             string  `json:"currency"`
 					ExchangeName         string  `json:"exchangeName"`
 					InstrumentType       string  `json:"instrumentType"`
 					FirstTradeDate       int64   `json:"firstTradeDate"`
@@ -222,7 +392,7 @@ func (yf *YahooFinanceAPI) fetchFromYahoo(symbol string) (*FinancialData, error)
 	}
 
 	if len(yahooResp.Chart.Result) == 0 {
-		return nil, fmt.Errorf("no data found for symbol %s", symbol)
+		return nil, fmt.Errorf("no data found for symbol %s: %w", symbol, ErrSymbolNotFound)
 	}
 
 	result := yahooResp.Chart.Result[0]
@@ -241,7 +411,7 @@ func (yf *YahooFinanceAPI) fetchFromYahoo(symbol string) (*FinancialData, error)
 		volume = volumes[len(volumes)-1]
 	}
 
-	return &FinancialData{
+	data := &FinancialData{
 		Symbol:     strings.ToUpper(symbol),
 		Company:    meta.Symbol, // This might need enhancement with company name lookup
 		Price:      currentPrice,
@@ -254,7 +424,18 @@ func (yf *YahooFinanceAPI) fetchFromYahoo(symbol string) (*FinancialData, error)
 		Change:     change,
 		ChangePerc: changePerc,
 		Timestamp:  time.Now().Format(time.RFC3339),
-	}, nil
+		Currency:   meta.Currency,
+		AssetType:  assetType(meta.InstrumentType),
+	}
+	if ex := ExchangeFor(symbol); ex != nil {
+		data.Exchange = ex.Code
+		if data.Currency == "" {
+			data.Currency = ex.Currency
+		}
+	}
+	normalizeMinorCurrency(data)
+	yf.addAssetDetails(data)
+	return data, nil
 }
 
 // GetMultipleStocks fetches data for multiple stocks concurrently
@@ -264,12 +445,13 @@ func (yf *YahooFinanceAPI) GetMultipleStocks(symbols []string) (map[string]*Fina
 	var wg sync.WaitGroup
 
 	// Limit concurrent requests
-	semaphore := make(chan struct{}, 5)
+	semaphore := make(chan struct{}, yf.concurrency)
 
 	for _, symbol := range symbols {
 		wg.Add(1)
 		go func(sym string) {
 			defer wg.Done()
+			defer recoverPanic(map[string]string{"component": "fetch", "symbol": sym})
 			semaphore <- struct{}{}        // Acquire semaphore
 			defer func() { <-semaphore }() // Release semaphore
 
@@ -291,18 +473,19 @@ func (yf *YahooFinanceAPI) GetMultipleStocks(symbols []string) (map[string]*Fina
 
 // CreditMetrics represents credit-relevant financial metrics
 type CreditMetrics struct {
-	Symbol         string  `json:"symbol"`
-	Company        string  `json:"company"`
-	DebtToEquity   float64 `json:"debt_to_equity"`
-	CurrentRatio   float64 `json:"current_ratio"`
-	QuickRatio     float64 `json:"quick_ratio"`
-	TotalDebt      int64   `json:"total_debt"`
-	TotalCash      int64   `json:"total_cash"`
-	ProfitMargins  float64 `json:"profit_margins"`
-	ReturnOnEquity float64 `json:"return_on_equity"`
-	OverallRisk    string  `json:"overall_risk"`
-	CreditRating   string  `json:"credit_rating"`
-	Timestamp      string  `json:"timestamp"`
+	Symbol         string         `json:"symbol"`
+	Company        string         `json:"company"`
+	DebtToEquity   float64        `json:"debt_to_equity"`
+	CurrentRatio   float64        `json:"current_ratio"`
+	QuickRatio     float64        `json:"quick_ratio"`
+	TotalDebt      int64          `json:"total_debt"`
+	TotalCash      int64          `json:"total_cash"`
+	ProfitMargins  float64        `json:"profit_margins"`
+	ReturnOnEquity float64        `json:"return_on_equity"`
+	OverallRisk    string         `json:"overall_risk"`
+	CreditRating   string         `json:"credit_rating"`
+	ShortInterest  *ShortInterest `json:"short_interest,omitempty"`
+	Timestamp      string         `json:"timestamp"`
 }
 
 // GetCreditMetrics fetches credit-relevant metrics (would need enhancement for full data)
@@ -313,6 +496,13 @@ func (yf *YahooFinanceAPI) GetCreditMetrics(symbol string) (*CreditMetrics, erro
 		return nil, err
 	}
 
+	// Short interest is supplementary; keep the metrics when it is unavailable
+	shortInterest, err := yf.GetShortInterest(symbol)
+	if err != nil {
+		log.Printf("No short interest for %s: %v", symbol, err)
+		shortInterest = nil
+	}
+
 	return &CreditMetrics{
 		Symbol:         stockData.Symbol,
 		Company:        stockData.Company,
@@ -325,19 +515,78 @@ func (yf *YahooFinanceAPI) GetCreditMetrics(symbol string) (*CreditMetrics, erro
 		ReturnOnEquity: 0,               // Would need fundamental data API
 		OverallRisk:    "Unknown",       // Would need risk assessment
 		CreditRating:   "Not Available", // Would need credit rating API
+		ShortInterest:  shortInterest,
 		Timestamp:      time.Now().Format(time.RFC3339),
 	}, nil
 }
 
 // Server represents the HTTP server for the financial API
 type Server struct {
-	api *YahooFinanceAPI
+	config       *config.Config
+	api          *YahooFinanceAPI
+	rates        *FREDClient
+	insider      *FinnhubClient
+	watchlists   *WatchlistStore
+	registry     *CompanyRegistry
+	constituents *ConstituentStore
+	events       *EventBus
+	ready        atomic.Bool // set once warm-up has finished
+	health       healthState
 }
 
 // NewServer creates a new server instance
-func NewServer() *Server {
+func NewServer(cfg *config.Config) *Server {
+	watchlists, err := NewWatchlistStore(cfg.Storage.WatchlistFile)
+	if err != nil {
+		log.Fatalf("Failed to load watchlists: %v", err)
+	}
+
+	api := NewYahooFinanceAPI(cfg)
+	if dbURL := cfg.Storage.QuotesDBURL; dbURL != "" {
+		quotes, err := NewQuoteStore(dbURL)
+		if err != nil {
+			log.Printf("Quote persistence disabled: %v", err)
+		} else {
+			api.quotes = quotes
+		}
+	}
+
+	registry, err := NewCompanyRegistry(cfg.Storage.CompanyRegistryFile, cfg.Providers.OpenFIGIAPIKey, api)
+	if err != nil {
+		log.Fatalf("Failed to load company registry: %v", err)
+	}
+	api.breakers.Guard(registry.client)
+
+	rates := NewFREDClient(cfg.Providers.FREDAPIKey)
+	api.breakers.Guard(rates.client)
+	rates.freshness = api.freshness
+
+	insider := NewFinnhubClient(cfg.Providers.FinnhubAPIKey)
+	api.breakers.Guard(insider.client)
+	insider.freshness = api.freshness
+
+	constituents := NewConstituentStore(cfg.Constituents.SP500URL, cfg.Constituents.DJIURL, cfg.Upstream.UserAgent)
+	api.breakers.Guard(constituents.client)
+	constituents.freshness = api.freshness
+	constituents.Start(cfg.Constituents.RefreshInterval)
+
+	events := NewEventBus()
+	if cfg.Events.Enabled {
+		api.detector = NewChangeDetector(events, cfg.Events.PriceMovePercent, cfg.Events.VolumeSpikeMultiple, api.averageDailyVolume)
+		if len(cfg.Events.WebhookURLs) > 0 {
+			NewWebhookSink(cfg.Events.WebhookURLs, cfg.Events.WebhookSecret).Start(events)
+		}
+	}
+
 	return &Server{
-		api: NewYahooFinanceAPI(),
+		config:       cfg,
+		api:          api,
+		rates:        rates,
+		insider:      insider,
+		watchlists:   watchlists,
+		registry:     registry,
+		constituents: constituents,
+		events:       events,
 	}
 }
 
@@ -345,27 +594,39 @@ func NewServer() *Server {
 func (s *Server) handleStock(w http.ResponseWriter, r *http.Request) {
 	symbol := r.URL.Query().Get("symbol")
 	if symbol == "" {
-		http.Error(w, "symbol parameter is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency != "" && !validCurrency(currency) {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "currency must be a 3-letter ISO code")
 		return
 	}
 
 	start := time.Now()
 	data, err := s.api.GetStockData(symbol)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, r, err, symbol)
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("X-Response-Time", time.Since(start).String())
-	json.NewEncoder(w).Encode(data)
+	if currency != "" {
+		data, err = s.api.ConvertQuote(data, currency)
+		if err != nil {
+			writeAPIError(w, r, err, symbol)
+			return
+		}
+	}
+
+	writeJSONWithETag(w, r, start, data)
 }
 
 // handleMultipleStocks handles multiple stock requests
 func (s *Server) handleMultipleStocks(w http.ResponseWriter, r *http.Request) {
 	symbolsParam := r.URL.Query().Get("symbols")
 	if symbolsParam == "" {
-		http.Error(w, "symbols parameter is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbols parameter is required")
 		return
 	}
 
@@ -374,10 +635,53 @@ func (s *Server) handleMultipleStocks(w http.ResponseWriter, r *http.Request) {
 		symbols[i] = strings.TrimSpace(symbol)
 	}
 
+	currency := r.URL.Query().Get("currency")
+	if currency != "" && !validCurrency(currency) {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "currency must be a 3-letter ISO code")
+		return
+	}
+
+	assetType, ok := checkAssetTypeParam(w, r)
+	if !ok {
+		return
+	}
+
 	start := time.Now()
 	data, err := s.api.GetMultipleStocks(symbols)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, r, err, "")
+		return
+	}
+	if assetType != "" {
+		data = filterAssetType(data, assetType)
+	}
+
+	if currency != "" {
+		for sym, quote := range data {
+			converted, err := s.api.ConvertQuote(quote, currency)
+			if err != nil {
+				writeAPIError(w, r, err, sym)
+				return
+			}
+			data[sym] = converted
+		}
+	}
+
+	writeJSONWithETag(w, r, start, data)
+}
+
+// handleCreditMetrics handles credit metrics requests
+func (s *Server) handleCreditMetrics(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	start := time.Now()
+	data, err := s.api.GetCreditMetrics(symbol)
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
 		return
 	}
 
@@ -386,18 +690,18 @@ func (s *Server) handleMultipleStocks(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// handleCreditMetrics handles credit metrics requests
-func (s *Server) handleCreditMetrics(w http.ResponseWriter, r *http.Request) {
+// handleZScore handles Altman Z-score requests
+func (s *Server) handleZScore(w http.ResponseWriter, r *http.Request) {
 	symbol := r.URL.Query().Get("symbol")
 	if symbol == "" {
-		http.Error(w, "symbol parameter is required", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
 		return
 	}
 
 	start := time.Now()
-	data, err := s.api.GetCreditMetrics(symbol)
+	data, err := s.api.GetZScore(symbol)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeAPIError(w, r, err, symbol)
 		return
 	}
 
@@ -406,62 +710,176 @@ func (s *Server) handleCreditMetrics(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(data)
 }
 
-// handleHealth handles health check requests
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	response := map[string]interface{}{
-		"status":    "healthy",
-		"timestamp": time.Now().Format(time.RFC3339),
-		"service":   "yahoo-finance-go",
-		"version":   "1.0.0",
+// handleVolatility handles historical volatility requests
+func (s *Server) handleVolatility(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	windows := []int{30, 90, 252}
+	if windowParam := r.URL.Query().Get("window"); windowParam != "" {
+		windows = windows[:0]
+		for _, part := range strings.Split(windowParam, ",") {
+			window, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || window < 2 || window > 1260 {
+				writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "window must be a comma-separated list of integers between 2 and 1260")
+				return
+			}
+			windows = append(windows, window)
+		}
+	}
+
+	start := time.Now()
+	data, err := s.api.GetVolatility(symbol, windows)
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
 }
 
-func main() {
-	server := NewServer()
-
-	// Set up routes
-	http.HandleFunc("/stock", server.handleStock)
-	http.HandleFunc("/stocks", server.handleMultipleStocks)
-	http.HandleFunc("/credit-metrics", server.handleCreditMetrics)
-	http.HandleFunc("/health", server.handleHealth)
-
-	// Root handler with API documentation
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/" {
-			http.NotFound(w, r)
+// handleSearch handles symbol search and autocomplete requests
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "q parameter is required")
+		return
+	}
+
+	limit := 10
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		parsed, err := strconv.Atoi(limitParam)
+		if err != nil || parsed < 1 || parsed > 50 {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "limit must be an integer between 1 and 50")
 			return
 		}
+		limit = parsed
+	}
+
+	start := time.Now()
+	data, err := s.api.SearchSymbols(query, limit)
+	if err != nil {
+		writeAPIError(w, r, err, "")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
+
+// handleRates handles treasury yield and corporate spread requests
+func (s *Server) handleRates(w http.ResponseWriter, r *http.Request) {
+	if !s.rates.Enabled() {
+		writeError(w, r, http.StatusServiceUnavailable, CodeNotConfigured, "rates are unavailable: FRED_API_KEY is not configured")
+		return
+	}
+
+	start := time.Now()
+	data, err := s.rates.GetRates(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusBadGateway, CodeUpstreamOutage, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
+
+// Handler builds the routed, middleware-wrapped handler for the server
+func (s *Server) Handler() http.Handler {
+	cfg := s.config
 
-		docs := map[string]interface{}{
-			"service": "Yahoo Finance Go API",
-			"version": "1.0.0",
-			"endpoints": map[string]string{
-				"GET /stock?symbol=AAPL":              "Get single stock data",
-				"GET /stocks?symbols=AAPL,GOOGL,MSFT": "Get multiple stocks data",
-				"GET /credit-metrics?symbol=AAPL":     "Get credit-relevant metrics",
-				"GET /health":                         "Health check",
-			},
-			"examples": map[string]string{
-				"single_stock":    "curl http://localhost:8080/stock?symbol=AAPL",
-				"multiple_stocks": "curl http://localhost:8080/stocks?symbols=AAPL,GOOGL,MSFT",
-				"credit_metrics":  "curl http://localhost:8080/credit-metrics?symbol=AAPL",
-			},
+	// Set up routes from the typed route table, which also drives the OpenAPI document
+	mux := http.NewServeMux()
+	routes := s.routes()
+	for _, route := range routes {
+		mux.HandleFunc(route.Pattern, route.Handler)
+	}
+	mux.HandleFunc("/openapi.json", openAPIHandler(buildOpenAPI(routes)))
+	if cfg.Console.Enabled {
+		mux.HandleFunc("/docs", requireToken(cfg.Console.Token, docsHandler))
+		mux.HandleFunc("/console", requireToken(cfg.Console.Token, consoleHandler(routes)))
+	}
+	mux.HandleFunc("/", indexHandler(routes))
+
+	var handler http.Handler = mux
+	if cfg.Demo.Enabled {
+		handler = demoMiddleware(cfg.Demo, handler)
+	}
+	handler = recoveryMiddleware(handler)
+	if cfg.Server.CompressionMinBytes > 0 {
+		handler = compressionMiddleware(cfg.Server.CompressionMinBytes, handler)
+	}
+	return requestIDMiddleware(handler)
+}
+
+func main() {
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if cfg.ErrorReporting.SentryDSN != "" {
+		sentry, err := NewSentryReporter(cfg.ErrorReporting.SentryDSN, cfg.ErrorReporting.Environment)
+		if err != nil {
+			log.Fatalf("Invalid error reporting configuration: %v", err)
 		}
+		errorReporter = sentry
+	}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(docs)
-	})
+	server := NewServer(cfg)
+	if cfg.Console.Enabled {
+		log.Printf("🧭 API console: http://localhost%s/console", cfg.Server.ListenAddr)
+	}
+
+	// Warm the top-priority tier before reporting ready, then keep watchlists fresh ahead of cache expiry
+	go func() {
+		defer recoverPanic(map[string]string{"component": "warmup"})
+		server.WarmUp(cfg.Warmup)
+		server.startWatchlistPrefetcher(cfg.Cache.PrefetchInterval)
+	}()
+
+	httpServer := &http.Server{
+		Handler:      server.Handler(),
+		Addr:         cfg.Server.ListenAddr,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+	if cfg.Demo.Enabled {
+		log.Printf("🎭 Demo mode: %d symbols, %d requests/minute per client", len(cfg.Demo.Symbols), cfg.Demo.RequestsPerMinute)
+	}
 
-	port := ":8080"
-	log.Printf("🚀 Yahoo Finance Go API starting on http://localhost%s", port)
-	log.Printf("📊 Cache TTL: 5 minutes")
-	log.Printf("⚡ Concurrent limit: 5 requests")
-	log.Printf("📖 API docs: http://localhost%s/", port)
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+		<-sigChan
 
-	if err := http.ListenAndServe(port, nil); err != nil {
+		log.Println("Shutting down...")
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(ctx); err != nil {
+			log.Printf("Error during shutdown: %v", err)
+		}
+	}()
+
+	log.Printf("🚀 Yahoo Finance Go API starting on %s", cfg.Server.ListenAddr)
+	log.Printf("📊 Cache TTL: %v", cfg.Cache.TTL)
+	log.Printf("⚡ Concurrent limit: %d requests", cfg.Upstream.Concurrency)
+	log.Printf("📖 API docs: http://localhost%s/", cfg.Server.ListenAddr)
+
+	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Fatal("Server failed to start:", err)
 	}
+
+	if server.api.quotes != nil {
+		server.api.quotes.Close()
+	}
 }
@@ -0,0 +1,478 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"yahoo-finance-go/config"
+)
+
+// fakeYahoo serves recorded Yahoo Finance responses from testdata and counts requests per path
+type fakeYahoo struct {
+	server   *httptest.Server
+	mu       sync.Mutex
+	hits     map[string]*int64
+	statuses map[string]int // forced status per symbol, for error paths
+	delay    time.Duration
+}
+
+// newFakeYahoo starts a fake upstream for the chart, quoteSummary and search endpoints
+func newFakeYahoo(t *testing.T) *fakeYahoo {
+	t.Helper()
+	fy := &fakeYahoo{hits: make(map[string]*int64), statuses: make(map[string]int)}
+	fy.server = httptest.NewServer(http.HandlerFunc(fy.serve))
+	t.Cleanup(fy.server.Close)
+	return fy
+}
+
+// serve answers one upstream request from the matching fixture
+func (fy *fakeYahoo) serve(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(fy.counter(r.URL.Path), 1)
+	if fy.delay > 0 {
+		time.Sleep(fy.delay)
+	}
+
+	var fixture string
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v8/finance/chart/"):
+		fixture = "chart_" + strings.TrimPrefix(r.URL.Path, "/v8/finance/chart/") + ".json"
+	case strings.HasPrefix(r.URL.Path, "/v10/finance/quoteSummary/"):
+		fixture = "quote_summary_" + strings.TrimPrefix(r.URL.Path, "/v10/finance/quoteSummary/") + ".json"
+	case r.URL.Path == "/v1/finance/search":
+		fixture = "search_empty.json"
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	symbol := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+	fy.mu.Lock()
+	status := fy.statuses[symbol]
+	fy.mu.Unlock()
+	if status != 0 {
+		w.WriteHeader(status)
+		return
+	}
+
+	raw, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		raw, _ = os.ReadFile(filepath.Join("testdata", "chart_not_found.json"))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(raw)
+}
+
+// counter returns the request counter for path
+func (fy *fakeYahoo) counter(path string) *int64 {
+	fy.mu.Lock()
+	defer fy.mu.Unlock()
+	if _, ok := fy.hits[path]; !ok {
+		fy.hits[path] = new(int64)
+	}
+	return fy.hits[path]
+}
+
+// Hits returns how many requests path has received
+func (fy *fakeYahoo) Hits(path string) int64 {
+	return atomic.LoadInt64(fy.counter(path))
+}
+
+// failWith makes every request for symbol answer with status
+func (fy *fakeYahoo) failWith(symbol string, status int) {
+	fy.mu.Lock()
+	defer fy.mu.Unlock()
+	fy.statuses[symbol] = status
+}
+
+// newTestServer starts the API against upstream with external feeds disabled and state in a temp dir
+func newTestServer(t *testing.T, upstream *fakeYahoo, configure ...func(*config.Config)) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(newTestAPI(t, upstream, configure...).Handler())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestAPI creates the server newTestServer starts, for tests that drive it directly
+func newTestAPI(t *testing.T, upstream *fakeYahoo, configure ...func(*config.Config)) *Server {
+	t.Helper()
+	dir := t.TempDir()
+
+	cfg := config.Default()
+	cfg.Upstream.ChartURL = upstream.server.URL
+	cfg.Upstream.QueryURL = upstream.server.URL
+	cfg.Symbols.UniverseURL = ""
+	cfg.Constituents.SP500URL = ""
+	cfg.Storage.WatchlistFile = filepath.Join(dir, "watchlists.json")
+	cfg.Storage.CompanyRegistryFile = filepath.Join(dir, "companies.json")
+	cfg.Storage.QuarantineFile = filepath.Join(dir, "quarantine.json")
+	for _, fn := range configure {
+		fn(cfg)
+	}
+	return NewServer(cfg)
+}
+
+// getJSON requests path and decodes the body into dst, returning the response
+func getJSON(t *testing.T, server *httptest.Server, path string, dst interface{}) *http.Response {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	defer resp.Body.Close()
+	if dst != nil {
+		if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+			t.Fatalf("decoding %s: %v", path, err)
+		}
+	}
+	return resp
+}
+
+func TestStockServedFromFixture(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var data FinancialData
+	resp := getJSON(t, server, "/stock?symbol=AAPL", &data)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if data.Symbol != "AAPL" || data.Price != 216.67 {
+		t.Errorf("got %s at %v, want AAPL at 216.67", data.Symbol, data.Price)
+	}
+	if data.Volume != 93728300 {
+		t.Errorf("volume = %d, want 93728300", data.Volume)
+	}
+	if resp.Header.Get("X-Response-Time") == "" {
+		t.Error("missing X-Response-Time header")
+	}
+}
+
+func TestStockCacheHit(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	for i := 0; i < 3; i++ {
+		if resp := getJSON(t, server, "/stock?symbol=AAPL", nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, resp.StatusCode)
+		}
+	}
+	if hits := upstream.Hits("/v8/finance/chart/AAPL"); hits != 1 {
+		t.Errorf("upstream chart requests = %d, want 1", hits)
+	}
+}
+
+func TestConcurrentRequestsCoalesce(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	upstream.delay = 100 * time.Millisecond
+	server := newTestServer(t, upstream)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Get(server.URL + "/stock?symbol=MSFT")
+			if err != nil {
+				errs <- err
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				errs <- fmt.Errorf("status = %d", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	if hits := upstream.Hits("/v8/finance/chart/MSFT"); hits != 1 {
+		t.Errorf("upstream chart requests = %d, want 1", hits)
+	}
+}
+
+func TestBatchStocks(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var data map[string]*FinancialData
+	resp := getJSON(t, server, "/stocks?symbols=AAPL,%20MSFT,NOPE", &data)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if len(data) != 2 || data["AAPL"] == nil || data["MSFT"] == nil {
+		t.Fatalf("got %v, want AAPL and MSFT only", data)
+	}
+	if data["MSFT"].Price != 448.37 {
+		t.Errorf("MSFT price = %v, want 448.37", data["MSFT"].Price)
+	}
+}
+
+func TestErrorPaths(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	upstream.failWith("BOOM", http.StatusInternalServerError)
+	upstream.failWith("SLOW", http.StatusTooManyRequests)
+	server := newTestServer(t, upstream)
+
+	tests := []struct {
+		name      string
+		path      string
+		status    int
+		code      string
+		retryable bool
+	}{
+		{"missing symbol", "/stock", http.StatusBadRequest, CodeInvalidRequest, false},
+		{"malformed symbol", "/stock?symbol=AA%3BPL", http.StatusBadRequest, CodeInvalidSymbol, false},
+		{"unknown symbol", "/stock?symbol=NOPE", http.StatusNotFound, CodeInvalidSymbol, false},
+		{"upstream error", "/stock?symbol=BOOM", http.StatusBadGateway, CodeUpstreamOutage, true},
+		{"upstream rate limit", "/stock?symbol=SLOW", http.StatusServiceUnavailable, CodeUpstreamRateLimit, true},
+		{"missing batch symbols", "/stocks", http.StatusBadRequest, CodeInvalidRequest, false},
+		{"bad events limit", "/events?limit=0", http.StatusBadRequest, CodeInvalidRequest, false},
+		{"unknown index", "/constituents?index=FTSE", http.StatusBadRequest, CodeInvalidRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body ErrorResponse
+			resp := getJSON(t, server, tt.path, &body)
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if body.Code != tt.code {
+				t.Errorf("code = %q, want %q", body.Code, tt.code)
+			}
+			if body.Retryable != tt.retryable {
+				t.Errorf("retryable = %v, want %v", body.Retryable, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestBreakerOpensAfterFailures(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	upstream.failWith("BOOM", http.StatusInternalServerError)
+	server := newTestServer(t, upstream, func(cfg *config.Config) {
+		cfg.Upstream.BreakerThreshold = 2
+		cfg.Upstream.BreakerCooldown = time.Minute
+	})
+
+	for i := 0; i < 2; i++ {
+		getJSON(t, server, "/stock?symbol=BOOM", nil)
+	}
+	before := upstream.Hits("/v8/finance/chart/AAPL")
+
+	var body ErrorResponse
+	resp := getJSON(t, server, "/stock?symbol=AAPL", &body)
+	if resp.StatusCode != http.StatusServiceUnavailable || body.Code != CodeUpstreamOutage {
+		t.Errorf("got %d %q, want 503 %q while the breaker is open", resp.StatusCode, body.Code, CodeUpstreamOutage)
+	}
+	if hits := upstream.Hits("/v8/finance/chart/AAPL"); hits != before {
+		t.Errorf("open breaker let %d requests through", hits-before)
+	}
+
+	var status StatusReport
+	getJSON(t, server, "/status", &status)
+	if status.Status != "degraded" {
+		t.Errorf("status = %q, want degraded", status.Status)
+	}
+}
+
+func TestZScoreFromFixture(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var score ZScoreResult
+	resp := getJSON(t, server, "/z-score?symbol=AAPL", &score)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if score.ZScore <= 0 || score.Zone == "" {
+		t.Errorf("got z-score %v in zone %q, want a positive score and a zone", score.ZScore, score.Zone)
+	}
+}
+
+func TestRequestIDEcho(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	req, _ := http.NewRequest("GET", server.URL+"/health", nil)
+	req.Header.Set("X-Request-ID", "test-123")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("X-Request-ID"); got != "test-123" {
+		t.Errorf("X-Request-ID = %q, want test-123", got)
+	}
+
+	resp = getJSON(t, server, "/health", nil)
+	if resp.Header.Get("X-Request-ID") == "" {
+		t.Error("no X-Request-ID assigned")
+	}
+}
+
+func TestUnknownRoute(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	if resp := getJSON(t, server, "/no-such-endpoint", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestRecommendationsFromFixture(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var data RecommendationsResult
+	resp := getJSON(t, server, "/recommendations?symbol=AAPL", &data)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if len(data.Trend) != 4 || data.Trend[0].Analysts != 38 {
+		t.Fatalf("got %d periods with %v analysts, want 4 periods starting with 38", len(data.Trend), data.Trend)
+	}
+	// (2*11 + 21) / (2*38)
+	if want := 43.0 / 76; data.AnalystSentiment != want {
+		t.Errorf("analyst sentiment = %v, want %v", data.AnalystSentiment, want)
+	}
+	if data.SentimentChange <= 0 {
+		t.Errorf("sentiment change = %v, want positive as ratings improved", data.SentimentChange)
+	}
+}
+
+func TestShortInterestInCreditMetrics(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var metrics CreditMetrics
+	resp := getJSON(t, server, "/credit-metrics?symbol=AAPL", &metrics)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	short := metrics.ShortInterest
+	if short == nil {
+		t.Fatal("credit metrics have no short interest")
+	}
+	if short.SharesShort != 120532200 || short.DaysToCover != 2.19 || short.SettlementDate != "2024-05-31" {
+		t.Errorf("got %+v, want the fixture's short position", short)
+	}
+	if short.ShortPercentOfFloat != 0.79 {
+		t.Errorf("short percent of float = %v, want 0.79", short.ShortPercentOfFloat)
+	}
+}
+
+func TestSymbolVariantsShareCache(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	for _, variant := range []string{"MSFT", "msft", "%24MSFT", "%20MSFT%20"} {
+		var data FinancialData
+		if resp := getJSON(t, server, "/stock?symbol="+variant, &data); resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", variant, resp.StatusCode)
+		}
+		if data.Symbol != "MSFT" {
+			t.Errorf("%s: symbol = %q, want MSFT", variant, data.Symbol)
+		}
+	}
+	if hits := upstream.Hits("/v8/finance/chart/MSFT"); hits != 1 {
+		t.Errorf("upstream chart requests = %d, want 1", hits)
+	}
+}
+
+func TestHistoryAdjustedForSplitsAndDividends(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	server := newTestServer(t, upstream)
+
+	var raw HistoryResult
+	if resp := getJSON(t, server, "/history?symbol=SPLT&period=1mo", &raw); resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if len(raw.Points) != 5 || raw.Points[0].Close != 100 {
+		t.Fatalf("got %+v, want the 5 unadjusted fixture bars", raw.Points)
+	}
+	if len(raw.Splits) != 1 || raw.Splits[0].Date != "2024-06-05" || len(raw.Dividends) != 1 {
+		t.Errorf("got splits %+v and dividends %+v, want one of each", raw.Splits, raw.Dividends)
+	}
+
+	var adjusted HistoryResult
+	getJSON(t, server, "/history?symbol=SPLT&period=1mo&adjusted=true", &adjusted)
+	// 100 halved for the 2:1 split, then scaled by 1 - 1.04/52 for the dividend
+	want := []float64{49, 49.98, 50.47, 50.96, 51}
+	for i, point := range adjusted.Points {
+		if math.Abs(point.Close-want[i]) > 1e-9 || point.AdjClose != point.Close {
+			t.Errorf("bar %d: close %v adj %v, want %v", i, point.Close, point.AdjClose, want[i])
+		}
+	}
+	if adjusted.Points[0].Volume != 2000000 {
+		t.Errorf("pre-split volume = %d, want 2000000", adjusted.Points[0].Volume)
+	}
+	if upstream.Hits("/v8/finance/chart/SPLT") != 1 {
+		t.Error("adjusted history was not served from the cached bars")
+	}
+
+	if resp := getJSON(t, server, "/history?symbol=SPLT&period=7y", nil); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("invalid period: status = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestPrefetchedSymbolEmitsEvents(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	s := newTestAPI(t, upstream, func(cfg *config.Config) {
+		cfg.Events.Enabled = true
+		cfg.Events.PriceMovePercent = 5
+		cfg.Events.VolumeSpikeMultiple = 0
+	})
+	if _, err := s.watchlists.Put("core", []string{"MSFT"}, 1); err != nil {
+		t.Fatal(err)
+	}
+	// A baseline far below the fixture price, as if the price had since doubled
+	s.api.detector.states["MSFT"] = &quoteState{baseline: 1}
+
+	s.PrefetchWatchlists()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(s.events.Recent("MSFT", 10)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("prefetching MSFT emitted no event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if event := s.events.Recent("MSFT", 10)[0]; event.Type != EventPriceMove {
+		t.Errorf("event type = %q, want %q", event.Type, EventPriceMove)
+	}
+}
+
+func TestWatchlistSymbolsPrefetchedUnderCanonicalKey(t *testing.T) {
+	upstream := newFakeYahoo(t)
+	s := newTestAPI(t, upstream)
+	list, err := s.watchlists.Put("core", []string{" $msft ", "MSFT"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Symbols) != 1 || list.Symbols[0] != "MSFT" {
+		t.Fatalf("symbols = %v, want [MSFT]", list.Symbols)
+	}
+
+	s.PrefetchWatchlists()
+	if _, err := s.api.GetStockData("msft"); err != nil {
+		t.Fatal(err)
+	}
+	if hits := upstream.Hits("/v8/finance/chart/MSFT"); hits != 1 {
+		t.Errorf("upstream chart requests = %d, want 1", hits)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiVersion is reported in the OpenAPI document and the health check
+const apiVersion = "1.0.0"
+
+// schemaBuilder converts Go types into OpenAPI schemas, collecting named structs as components
+type schemaBuilder struct {
+	components map[string]interface{}
+}
+
+// schema returns the schema for t, referencing named structs through components
+func (b *schemaBuilder) schema(t reflect.Type) map[string]interface{} {
+	if t == nil {
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+		if _, ok := b.components[t.Name()]; !ok {
+			b.components[t.Name()] = nil // placeholder guards against recursive types
+			b.components[t.Name()] = b.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return map[string]interface{}{}
+}
+
+// structSchema builds an object schema from exported fields and their json tags
+func (b *schemaBuilder) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		omitempty := false
+		if tag := field.Tag.Get("json"); tag != "" {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				omitempty = omitempty || opt == "omitempty"
+			}
+		}
+
+		properties[name] = b.schema(field.Type)
+		if !omitempty && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// buildOpenAPI generates an OpenAPI 3 document from the route definitions
+func buildOpenAPI(routes []Route) map[string]interface{} {
+	builder := &schemaBuilder{components: make(map[string]interface{})}
+	paths := make(map[string]interface{})
+	errorResponse := map[string]interface{}{
+		"description": "Error",
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{"schema": builder.schema(reflect.TypeOf(ErrorResponse{}))},
+		},
+	}
+
+	for _, route := range routes {
+		item := make(map[string]interface{})
+		for _, op := range route.Operations {
+			operation := map[string]interface{}{
+				"summary":     op.Summary,
+				"operationId": operationID(op.Method, route.Path),
+			}
+
+			if len(op.Params) > 0 {
+				var params []interface{}
+				for _, p := range op.Params {
+					params = append(params, map[string]interface{}{
+						"name":        p.Name,
+						"in":          p.In,
+						"required":    p.Required,
+						"description": p.Description,
+						"schema":      map[string]interface{}{"type": p.Type},
+					})
+				}
+				operation["parameters"] = params
+			}
+
+			if op.RequestBody != nil {
+				operation["requestBody"] = map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{"schema": builder.schema(reflect.TypeOf(op.RequestBody))},
+					},
+				}
+			}
+
+			status := op.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			success := map[string]interface{}{"description": http.StatusText(status)}
+			if op.Response != nil {
+				success["content"] = map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": builder.schema(reflect.TypeOf(op.Response))},
+				}
+			}
+			operation["responses"] = map[string]interface{}{
+				strconv.Itoa(status): success,
+				"default":            errorResponse,
+			}
+
+			item[strings.ToLower(op.Method)] = operation
+		}
+		paths[route.Path] = item
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "Yahoo Finance Go API",
+			"version": apiVersion,
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": builder.components},
+	}
+}
+
+// operationID derives a stable identifier such as getZScore from a method and path
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, part := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '-' || r == '{' || r == '}' }) {
+		id += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return id
+}
+
+// openAPIHandler serves the generated OpenAPI document
+func openAPIHandler(spec map[string]interface{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(spec)
+	}
+}
+
+// indexHandler lists the endpoints from the route table and points at the full specification
+func indexHandler(routes []Route) http.HandlerFunc {
+	endpoints := make(map[string]string)
+	for _, route := range routes {
+		for _, op := range route.Operations {
+			endpoints[op.Method+" "+route.Path] = op.Summary
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			writeError(w, r, http.StatusNotFound, CodeNotFound, "no route for "+r.URL.Path)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"service":   "Yahoo Finance Go API",
+			"version":   apiVersion,
+			"openapi":   "/openapi.json",
+			"endpoints": endpoints,
+		})
+	}
+}
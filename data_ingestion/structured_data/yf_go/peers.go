@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// gicsSectors maps Yahoo profile sectors to the GICS sector names used by the constituent lists
+var gicsSectors = map[string]string{
+	"Technology":             "Information Technology",
+	"Healthcare":             "Health Care",
+	"Financial Services":     "Financials",
+	"Consumer Cyclical":      "Consumer Discretionary",
+	"Consumer Defensive":     "Consumer Staples",
+	"Communication Services": "Communication Services",
+	"Industrials":            "Industrials",
+	"Energy":                 "Energy",
+	"Basic Materials":        "Materials",
+	"Real Estate":            "Real Estate",
+	"Utilities":              "Utilities",
+}
+
+// PeerCompany is one company in the same industry as the requested symbol
+type PeerCompany struct {
+	Symbol         string  `json:"symbol"`
+	Company        string  `json:"company"`
+	MarketCap      float64 `json:"market_cap"`
+	MarketCapRatio float64 `json:"market_cap_ratio"` // peer market cap divided by the subject's
+	PERatio        float64 `json:"pe_ratio,omitempty"`
+}
+
+// PeersResult lists comparable companies for peer-relative benchmarking
+type PeersResult struct {
+	Symbol            string        `json:"symbol"`
+	Company           string        `json:"company"`
+	Sector            string        `json:"sector"`
+	Industry          string        `json:"industry"`
+	MarketCap         float64       `json:"market_cap"`
+	Peers             []PeerCompany `json:"peers"`
+	PeerMedianPE      float64       `json:"peer_median_pe_ratio"`
+	CandidatesScanned int           `json:"candidates_scanned"`
+	Timestamp         string        `json:"timestamp"`
+}
+
+// peerCandidates returns the symbols worth profiling as peers: every known symbol plus
+// index constituents whose GICS sector matches the subject's Yahoo sector
+func (s *Server) peerCandidates(sector string) []string {
+	seen := make(map[string]bool)
+	for _, symbol := range s.knownSymbols() {
+		seen[symbol] = true
+	}
+	if gics, ok := gicsSectors[sector]; ok {
+		for _, index := range s.constituents.Indices() {
+			members, err := s.constituents.Get(index)
+			if err != nil || members == nil {
+				continue
+			}
+			for _, member := range members.Members {
+				if member.Sector == gics {
+					seen[member.Symbol] = true
+				}
+			}
+		}
+	}
+
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// GetPeers returns up to limit companies in symbol's industry whose market cap is within
+// maxRatio times the subject's in either direction, closest in size first
+func (s *Server) GetPeers(symbol string, limit int, maxRatio float64) (*PeersResult, error) {
+	symbol = strings.ToUpper(symbol)
+	subject, err := s.api.GetQuoteSummary(symbol, sectorSummaryModules...)
+	if err != nil {
+		return nil, err
+	}
+	industry := subject.AssetProfile.Industry
+	marketCap := subject.Price.MarketCap.Raw
+	if industry == "" || marketCap <= 0 {
+		return nil, fmt.Errorf("no industry or market cap in the profile for %s: %w", symbol, ErrInsufficientData)
+	}
+
+	candidates := s.peerCandidates(subject.AssetProfile.Sector)
+	var peers []PeerCompany
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// Limit concurrent requests
+	semaphore := make(chan struct{}, s.api.concurrency)
+
+	for _, candidate := range candidates {
+		if candidate == symbol {
+			continue
+		}
+		wg.Add(1)
+		go func(sym string) {
+			defer wg.Done()
+			defer recoverPanic(map[string]string{"component": "peers", "symbol": sym})
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			summary, err := s.api.GetQuoteSummary(sym, sectorSummaryModules...)
+			if err != nil || !strings.EqualFold(summary.AssetProfile.Industry, industry) {
+				return
+			}
+			ratio := summary.Price.MarketCap.Raw / marketCap
+			if ratio <= 0 || ratio > maxRatio || ratio < 1/maxRatio {
+				return
+			}
+
+			company := summary.Price.LongName
+			if company == "" {
+				company = summary.Price.ShortName
+			}
+			mu.Lock()
+			peers = append(peers, PeerCompany{
+				Symbol:         sym,
+				Company:        company,
+				MarketCap:      summary.Price.MarketCap.Raw,
+				MarketCapRatio: ratio,
+				PERatio:        summary.SummaryDetail.TrailingPE.Raw,
+			})
+			mu.Unlock()
+		}(candidate)
+	}
+	wg.Wait()
+
+	// Closest in size first: compare ratios on a log scale so 0.5x and 2x rank equally
+	sort.Slice(peers, func(i, j int) bool {
+		di, dj := math.Abs(math.Log(peers[i].MarketCapRatio)), math.Abs(math.Log(peers[j].MarketCapRatio))
+		if di != dj {
+			return di < dj
+		}
+		return peers[i].Symbol < peers[j].Symbol
+	})
+	if len(peers) > limit {
+		peers = peers[:limit]
+	}
+
+	var pes []float64
+	for _, peer := range peers {
+		if peer.PERatio > 0 {
+			pes = append(pes, peer.PERatio)
+		}
+	}
+
+	company := subject.Price.LongName
+	if company == "" {
+		company = subject.Price.ShortName
+	}
+	return &PeersResult{
+		Symbol:            symbol,
+		Company:           company,
+		Sector:            subject.AssetProfile.Sector,
+		Industry:          industry,
+		MarketCap:         marketCap,
+		Peers:             append([]PeerCompany{}, peers...),
+		PeerMedianPE:      median(pes),
+		CandidatesScanned: len(candidates),
+		Timestamp:         time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// handlePeers handles peer company requests
+func (s *Server) handlePeers(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	limit := 10
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 50 {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "limit must be between 1 and 50")
+			return
+		}
+		limit = parsed
+	}
+
+	maxRatio := 4.0
+	if value := r.URL.Query().Get("max_ratio"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 1 {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "max_ratio must be a number of at least 1")
+			return
+		}
+		maxRatio = parsed
+	}
+
+	start := time.Now()
+	data, err := s.GetPeers(symbol, limit, maxRatio)
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// QuoteStore appends fetched quotes to a Postgres (optionally TimescaleDB) time-series table
+type QuoteStore struct {
+	db         *sql.DB
+	queue      chan *FinancialData
+	hypertable bool
+	done       chan struct{}
+}
+
+// NewQuoteStore connects to Postgres, prepares the quote_history table and starts the writer
+func NewQuoteStore(dbURL string) (*QuoteStore, error) {
+	db, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("pinging database: %w", err)
+	}
+
+	store := &QuoteStore{
+		db:    db,
+		queue: make(chan *FinancialData, 1000),
+		done:  make(chan struct{}),
+	}
+
+	if err := store.createTables(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	go store.writer()
+	return store, nil
+}
+
+// createTables creates quote_history and converts it to a hypertable when Timescale is available
+func (qs *QuoteStore) createTables(ctx context.Context) error {
+	queries := []string{
+		`CREATE TABLE IF NOT EXISTS quote_history (
+			symbol         VARCHAR(32) NOT NULL,
+			price          DOUBLE PRECISION NOT NULL,
+			change         DOUBLE PRECISION,
+			change_percent DOUBLE PRECISION,
+			volume         BIGINT,
+			market_cap     BIGINT,
+			fetched_at     TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_quote_history_symbol_time ON quote_history(symbol, fetched_at DESC)`,
+	}
+
+	for _, query := range queries {
+		if _, err := qs.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("creating quote_history: %w", err)
+		}
+	}
+
+	var available bool
+	err := qs.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'timescaledb')`).Scan(&available)
+	if err != nil || !available {
+		log.Printf("TimescaleDB not available, storing quotes in a plain table")
+		return nil
+	}
+
+	if _, err := qs.db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS timescaledb`); err != nil {
+		log.Printf("Could not enable TimescaleDB extension: %v", err)
+		return nil
+	}
+	if _, err := qs.db.ExecContext(ctx,
+		`SELECT create_hypertable('quote_history', 'fetched_at', if_not_exists => TRUE, migrate_data => TRUE)`); err != nil {
+		log.Printf("Could not convert quote_history to a hypertable: %v", err)
+		return nil
+	}
+
+	qs.hypertable = true
+	log.Printf("quote_history is a TimescaleDB hypertable")
+	return nil
+}
+
+// Append queues a quote for persistence without blocking the request path
+func (qs *QuoteStore) Append(data *FinancialData) {
+	select {
+	case qs.queue <- data:
+	default:
+		log.Printf("Quote persistence queue full, dropping %s", data.Symbol)
+	}
+}
+
+// writer drains the queue into the database
+func (qs *QuoteStore) writer() {
+	defer close(qs.done)
+
+	for data := range qs.queue {
+		fetchedAt, err := time.Parse(time.RFC3339, data.Timestamp)
+		if err != nil {
+			fetchedAt = time.Now()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_, err = qs.db.ExecContext(ctx,
+			`INSERT INTO quote_history (symbol, price, change, change_percent, volume, market_cap, fetched_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			data.Symbol, data.Price, data.Change, data.ChangePerc, data.Volume, data.MarketCap, fetchedAt)
+		cancel()
+
+		if err != nil {
+			log.Printf("Failed to persist quote for %s: %v", data.Symbol, err)
+		}
+	}
+}
+
+// RecentSymbols returns the symbols persisted since the given time
+func (qs *QuoteStore) RecentSymbols(ctx context.Context, since time.Time) ([]string, error) {
+	rows, err := qs.db.QueryContext(ctx,
+		`SELECT DISTINCT symbol FROM quote_history WHERE fetched_at >= $1`, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying recent symbols: %w", err)
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("scanning symbol: %w", err)
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols, rows.Err()
+}
+
+// Ping checks the database connection
+func (qs *QuoteStore) Ping(ctx context.Context) error {
+	return qs.db.PingContext(ctx)
+}
+
+// Close flushes queued quotes and closes the database
+func (qs *QuoteStore) Close() error {
+	close(qs.queue)
+	<-qs.done
+	return qs.db.Close()
+}
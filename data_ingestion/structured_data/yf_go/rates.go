@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// treasurySeries maps response keys to FRED constant-maturity treasury series
+var treasurySeries = map[string]string{
+	"3m":  "DGS3MO",
+	"2y":  "DGS2",
+	"10y": "DGS10",
+}
+
+// spreadSeries maps response keys to FRED corporate spread series
+var spreadSeries = map[string]string{
+	"ig_oas":       "BAMLC0A0CM",   // ICE BofA US Corporate OAS
+	"hy_oas":       "BAMLH0A0HYM2", // ICE BofA US High Yield OAS
+	"baa_10y":      "BAA10Y",       // Moody's Baa yield relative to 10Y treasury
+	"aaa_10y":      "AAA10Y",       // Moody's Aaa yield relative to 10Y treasury
+	"ted_spread":   "TEDRATE",
+	"2s10s_spread": "T10Y2Y",
+}
+
+// RateObservation is the latest value of a FRED series
+type RateObservation struct {
+	SeriesID string  `json:"series_id"`
+	Value    float64 `json:"value"`
+	Date     string  `json:"date"`
+}
+
+// RatesResult represents treasury yields and corporate spreads
+type RatesResult struct {
+	Treasury  map[string]RateObservation `json:"treasury"`
+	Spreads   map[string]RateObservation `json:"spreads"`
+	Errors    map[string]string          `json:"errors,omitempty"`
+	Timestamp string                     `json:"timestamp"`
+}
+
+// FREDClient fetches series from the St. Louis Fed FRED API
+type FREDClient struct {
+	apiKey string
+	client *http.Client
+	cache  *Cache
+
+	freshness *Freshness
+}
+
+// NewFREDClient creates a FRED client with a long-lived cache; FRED series update at most daily
+func NewFREDClient(apiKey string) *FREDClient {
+	return &FREDClient{
+		apiKey: apiKey,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		cache: NewCache(6 * time.Hour),
+	}
+}
+
+// Enabled reports whether an API key is configured
+func (fc *FREDClient) Enabled() bool {
+	return fc.apiKey != ""
+}
+
+// GetRates fetches the latest treasury yields and corporate spreads concurrently
+func (fc *FREDClient) GetRates(ctx context.Context) (*RatesResult, error) {
+	if !fc.Enabled() {
+		return nil, fmt.Errorf("FRED API key not configured")
+	}
+
+	result := &RatesResult{
+		Treasury:  make(map[string]RateObservation),
+		Spreads:   make(map[string]RateObservation),
+		Errors:    make(map[string]string),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	fetch := func(target map[string]RateObservation, key, seriesID string) {
+		defer wg.Done()
+		obs, err := fc.GetLatest(ctx, seriesID)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			log.Printf("Error fetching FRED series %s: %v", seriesID, err)
+			result.Errors[key] = err.Error()
+			return
+		}
+		target[key] = *obs
+	}
+
+	for key, seriesID := range treasurySeries {
+		wg.Add(1)
+		go fetch(result.Treasury, key, seriesID)
+	}
+	for key, seriesID := range spreadSeries {
+		wg.Add(1)
+		go fetch(result.Spreads, key, seriesID)
+	}
+	wg.Wait()
+
+	if len(result.Treasury) == 0 && len(result.Spreads) == 0 {
+		return nil, fmt.Errorf("all FRED series requests failed")
+	}
+	if len(result.Errors) == 0 {
+		result.Errors = nil
+	}
+	return result, nil
+}
+
+// GetLatest returns the most recent non-missing observation for a series with caching
+func (fc *FREDClient) GetLatest(ctx context.Context, seriesID string) (*RateObservation, error) {
+	cacheKey := "fred_" + seriesID
+	if cached, found := fc.cache.Get(cacheKey); found {
+		if obs, ok := cached.(*RateObservation); ok {
+			return obs, nil
+		}
+	}
+
+	params := url.Values{
+		"series_id":  {seriesID},
+		"api_key":    {fc.apiKey},
+		"file_type":  {"json"},
+		"sort_order": {"desc"},
+		"limit":      {"10"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://api.stlouisfed.org/fred/series/observations?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", withoutURL(err))
+	}
+	resp, err := fc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", withoutURL(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UpstreamStatusError{Provider: "FRED", StatusCode: resp.StatusCode}
+	}
+
+	var fredResp struct {
+		Observations []struct {
+			Date  string `json:"date"`
+			Value string `json:"value"`
+		} `json:"observations"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&fredResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	// FRED marks missing observations (e.g. holidays) with "."
+	for _, o := range fredResp.Observations {
+		value, err := strconv.ParseFloat(o.Value, 64)
+		if err != nil {
+			continue
+		}
+		obs := &RateObservation{SeriesID: seriesID, Value: value, Date: o.Date}
+		fc.cache.Set(cacheKey, obs)
+		fc.freshness.Touch("rates")
+		return obs, nil
+	}
+
+	return nil, fmt.Errorf("no observations for series %s", seriesID)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// RecommendationPeriod is the analyst rating distribution for one month. Period is relative to
+// the current month: 0m is this month, -1m last month and so on.
+type RecommendationPeriod struct {
+	Period           string  `json:"period"`
+	StrongBuy        int     `json:"strong_buy"`
+	Buy              int     `json:"buy"`
+	Hold             int     `json:"hold"`
+	Sell             int     `json:"sell"`
+	StrongSell       int     `json:"strong_sell"`
+	Analysts         int     `json:"analysts"`
+	AnalystSentiment float64 `json:"analyst_sentiment"`
+}
+
+// RecommendationsResult lists analyst recommendation trends for a symbol
+type RecommendationsResult struct {
+	Symbol           string                 `json:"symbol"`
+	Company          string                 `json:"company"`
+	AnalystSentiment float64                `json:"analyst_sentiment"` // current month, -1 (all strong sell) to 1 (all strong buy)
+	SentimentChange  float64                `json:"sentiment_change"`  // current month minus the oldest month returned
+	Trend            []RecommendationPeriod `json:"trend"`             // newest first
+	Timestamp        string                 `json:"timestamp"`
+}
+
+// analystSentiment scores a rating distribution from -1 to 1, weighting strong ratings double
+func analystSentiment(p RecommendationPeriod) float64 {
+	if p.Analysts == 0 {
+		return 0
+	}
+	weighted := 2*p.StrongBuy + p.Buy - p.Sell - 2*p.StrongSell
+	return float64(weighted) / float64(2*p.Analysts)
+}
+
+// GetRecommendations returns the monthly analyst rating counts and sentiment scores for symbol
+func (yf *YahooFinanceAPI) GetRecommendations(symbol string) (*RecommendationsResult, error) {
+	symbol = strings.ToUpper(symbol)
+	summary, err := yf.GetQuoteSummary(symbol, "price", "recommendationTrend")
+	if err != nil {
+		return nil, err
+	}
+
+	var trend []RecommendationPeriod
+	for _, t := range summary.RecommendationTrend.Trend {
+		p := RecommendationPeriod{
+			Period:     t.Period,
+			StrongBuy:  t.StrongBuy,
+			Buy:        t.Buy,
+			Hold:       t.Hold,
+			Sell:       t.Sell,
+			StrongSell: t.StrongSell,
+		}
+		p.Analysts = p.StrongBuy + p.Buy + p.Hold + p.Sell + p.StrongSell
+		if p.Analysts == 0 {
+			continue
+		}
+		p.AnalystSentiment = analystSentiment(p)
+		trend = append(trend, p)
+	}
+	if len(trend) == 0 {
+		return nil, fmt.Errorf("no analyst recommendations for %s: %w", symbol, ErrInsufficientData)
+	}
+
+	company := summary.Price.LongName
+	if company == "" {
+		company = summary.Price.ShortName
+	}
+	return &RecommendationsResult{
+		Symbol:           symbol,
+		Company:          company,
+		AnalystSentiment: trend[0].AnalystSentiment,
+		SentimentChange:  trend[0].AnalystSentiment - trend[len(trend)-1].AnalystSentiment,
+		Trend:            trend,
+		Timestamp:        time.Now().Format(time.RFC3339),
+	}, nil
+}
+
+// handleRecommendations handles analyst recommendation trend requests
+func (s *Server) handleRecommendations(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	start := time.Now()
+	data, err := s.api.GetRecommendations(symbol)
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// ErrorReporter forwards captured panics to an error-reporting sink
+type ErrorReporter interface {
+	Report(message string, stack []byte, tags map[string]string)
+}
+
+// errorReporter is the process-wide sink; it only logs until main installs a Sentry reporter
+var errorReporter ErrorReporter = logReporter{}
+
+// logReporter writes panics and their stack traces to the log
+type logReporter struct{}
+
+// Report logs the event
+func (logReporter) Report(message string, stack []byte, tags map[string]string) {
+	log.Printf("%s %v\n%s", message, tags, stack)
+}
+
+// recoverPanic reports a panic in the calling goroutine; use it as `defer recoverPanic(tags)`
+func recoverPanic(tags map[string]string) {
+	if r := recover(); r != nil {
+		errorReporter.Report(fmt.Sprintf("panic: %v", r), debug.Stack(), tags)
+	}
+}
+
+// recoveryMiddleware turns handler panics into a reported 500 JSON response instead of a dropped connection
+func recoveryMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				errorReporter.Report(fmt.Sprintf("panic: %v", recovered), debug.Stack(), map[string]string{
+					"component": "http",
+					"method":    r.Method,
+					"path":      r.URL.Path,
+				})
+
+				writeError(w, r, http.StatusInternalServerError, CodeInternal, "internal server error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SentryReporter posts events to a Sentry-compatible store endpoint
+type SentryReporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	serverName  string
+	client      *http.Client
+}
+
+// NewSentryReporter parses a DSN of the form https://<key>@<host>/<project>
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("parsing DSN: %w", err)
+	}
+	key := u.User.Username()
+	project := strings.Trim(u.Path, "/")
+	if key == "" || project == "" {
+		return nil, fmt.Errorf("DSN is missing the key or project")
+	}
+
+	hostname, _ := os.Hostname()
+	return &SentryReporter{
+		endpoint:    fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=credtech-go/1.0, sentry_key=%s", key),
+		environment: environment,
+		serverName:  hostname,
+		client:      &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// Report logs the event and sends it asynchronously
+func (sr *SentryReporter) Report(message string, stack []byte, tags map[string]string) {
+	logReporter{}.Report(message, stack, tags)
+
+	eventID := make([]byte, 16)
+	rand.Read(eventID)
+
+	event := map[string]interface{}{
+		"event_id":    hex.EncodeToString(eventID),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"level":       "error",
+		"platform":    "go",
+		"logger":      "yf_go",
+		"server_name": sr.serverName,
+		"environment": sr.environment,
+		"message":     message,
+		"tags":        tags,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{"type": "panic", "value": message}},
+		},
+		"extra": map[string]interface{}{"stacktrace": string(stack)},
+	}
+
+	go func() {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		req, err := http.NewRequest("POST", sr.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Sentry-Auth", sr.auth)
+
+		resp, err := sr.client.Do(req)
+		if err != nil {
+			log.Printf("Error sending error report: %v", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("Error reporting endpoint returned status %d", resp.StatusCode)
+		}
+	}()
+}
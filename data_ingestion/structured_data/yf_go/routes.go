@@ -0,0 +1,251 @@
+package main
+
+import (
+	"net/http"
+)
+
+// Param describes a path or query parameter of an operation
+type Param struct {
+	Name        string
+	In          string // query or path
+	Type        string // string, integer, number or boolean
+	Required    bool
+	Description string
+}
+
+// Operation describes one HTTP method on a route
+type Operation struct {
+	Method      string
+	Summary     string
+	Params      []Param
+	RequestBody interface{} // zero value of the JSON request body, if any
+	Response    interface{} // zero value of the JSON response body, nil for no content
+	Status      int         // success status; defaults to 200
+}
+
+// Route ties a ServeMux pattern to its handler and its documented operations
+type Route struct {
+	Pattern    string // pattern registered on the ServeMux
+	Path       string // OpenAPI path template
+	Handler    http.HandlerFunc
+	Operations []Operation
+}
+
+// symbolParam is the common required symbol query parameter
+var symbolParam = Param{Name: "symbol", In: "query", Type: "string", Required: true, Description: "Ticker symbol, e.g. AAPL"}
+
+// currencyParam is the optional currency conversion parameter
+var currencyParam = Param{Name: "currency", In: "query", Type: "string", Description: "ISO 4217 currency to convert prices into"}
+
+// routes returns the typed definitions of every API endpoint
+func (s *Server) routes() []Route {
+	return []Route{
+		{
+			Pattern: "/stock", Path: "/stock", Handler: s.handleStock,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get single stock data",
+				Params:   []Param{symbolParam, currencyParam},
+				Response: FinancialData{},
+			}},
+		},
+		{
+			Pattern: "/stocks", Path: "/stocks", Handler: s.handleMultipleStocks,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get multiple stocks data",
+				Params: []Param{
+					{Name: "symbols", In: "query", Type: "string", Required: true, Description: "Comma-separated ticker symbols"},
+					currencyParam,
+					assetTypeParam,
+				},
+				Response: map[string]FinancialData{},
+			}},
+		},
+		{
+			Pattern: "/credit-metrics", Path: "/credit-metrics", Handler: s.handleCreditMetrics,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get credit-relevant metrics",
+				Params:   []Param{symbolParam},
+				Response: CreditMetrics{},
+			}},
+		},
+		{
+			Pattern: "/z-score", Path: "/z-score", Handler: s.handleZScore,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get Altman Z-score and distress zone",
+				Params:   []Param{symbolParam},
+				Response: ZScoreResult{},
+			}},
+		},
+		{
+			Pattern: "/volatility", Path: "/volatility", Handler: s.handleVolatility,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get annualized realized volatility",
+				Params: []Param{
+					symbolParam,
+					{Name: "window", In: "query", Type: "string", Description: "Comma-separated trading-day windows (default 30,90,252)"},
+				},
+				Response: VolatilityResult{},
+			}},
+		},
+		{
+			Pattern: "/history", Path: "/history", Handler: s.handleHistory,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get daily bars with dividends and splits, optionally adjusted for both",
+				Params: []Param{
+					symbolParam,
+					{Name: "period", In: "query", Type: "string", Description: "1mo, 3mo, 6mo, ytd, 1y, 2y, 5y, 10y or max (default 1y)"},
+					{Name: "adjusted", In: "query", Type: "boolean", Description: "Back-adjust prices and volume for splits and dividends"},
+				},
+				Response: HistoryResult{},
+			}},
+		},
+		{
+			Pattern: "/search", Path: "/search", Handler: s.handleSearch,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Search symbols by name or ticker",
+				Params: []Param{
+					{Name: "q", In: "query", Type: "string", Required: true, Description: "Company name or ticker fragment"},
+					{Name: "limit", In: "query", Type: "integer", Description: "Maximum matches (1-50, default 10)"},
+				},
+				Response: []SymbolMatch{},
+			}},
+		},
+		{
+			Pattern: "/watchlists", Path: "/watchlists", Handler: s.handleWatchlists,
+			Operations: []Operation{
+				{Method: http.MethodGet, Summary: "List watchlists", Response: []Watchlist{}},
+				{Method: http.MethodPost, Summary: "Create a watchlist", RequestBody: Watchlist{}, Response: Watchlist{}, Status: http.StatusCreated},
+			},
+		},
+		{
+			Pattern: "/watchlists/", Path: "/watchlists/{name}", Handler: s.handleWatchlist,
+			Operations: []Operation{
+				{Method: http.MethodGet, Summary: "Read a watchlist", Params: []Param{watchlistNameParam}, Response: Watchlist{}},
+				{Method: http.MethodPut, Summary: "Replace a watchlist", Params: []Param{watchlistNameParam}, RequestBody: Watchlist{}, Response: Watchlist{}},
+				{Method: http.MethodDelete, Summary: "Delete a watchlist", Params: []Param{watchlistNameParam}, Status: http.StatusNoContent},
+			},
+		},
+		{
+			Pattern: "/quarantine", Path: "/quarantine", Handler: s.handleQuarantine,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "List recently rejected or flagged market data",
+				Response: []QuarantinedValue{},
+			}},
+		},
+		{
+			Pattern: "/rates", Path: "/rates", Handler: s.handleRates,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get treasury yields and corporate spreads (FRED)",
+				Response: RatesResult{},
+			}},
+		},
+		{
+			Pattern: "/identifiers", Path: "/identifiers", Handler: s.handleIdentifiers,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get LEI, ISIN, CUSIP and FIGI identifiers",
+				Params: []Param{
+					symbolParam,
+					{Name: "refresh", In: "query", Type: "boolean", Description: "Re-run enrichment even if stored identifiers are fresh"},
+				},
+				Response: CompanyIdentifiers{},
+			}},
+		},
+		{
+			Pattern: "/sector-metrics", Path: "/sector-metrics", Handler: s.handleSectorMetrics,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get median P/E, average move, market cap and top movers for a sector",
+				Params:   []Param{{Name: "sector", In: "query", Type: "string", Required: true, Description: "Yahoo sector name, e.g. Technology"}},
+				Response: SectorMetrics{},
+			}},
+		},
+		{
+			Pattern: "/peers", Path: "/peers", Handler: s.handlePeers,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "List same-industry companies of comparable market cap",
+				Params: []Param{
+					symbolParam,
+					{Name: "limit", In: "query", Type: "integer", Description: "Maximum peers to return (1-50, default 10)"},
+					{Name: "max_ratio", In: "query", Type: "number", Description: "Largest market cap multiple either way (default 4)"},
+				},
+				Response: PeersResult{},
+			}},
+		},
+		{
+			Pattern: "/recommendations", Path: "/recommendations", Handler: s.handleRecommendations,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get monthly analyst rating counts and an analyst sentiment score",
+				Params:   []Param{symbolParam},
+				Response: RecommendationsResult{},
+			}},
+		},
+		{
+			Pattern: "/short-interest", Path: "/short-interest", Handler: s.handleShortInterest,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Get shares short, days to cover and short percent of float",
+				Params:   []Param{symbolParam},
+				Response: ShortInterest{},
+			}},
+		},
+		{
+			Pattern: "/insider-transactions", Path: "/insider-transactions", Handler: s.handleInsiderTransactions,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "List recent insider buys and sells with a selling-cluster flag (Finnhub)",
+				Params: []Param{
+					symbolParam,
+					{Name: "days", In: "query", Type: "integer", Description: "Look-back window in days (1-730, default 90)"},
+				},
+				Response: InsiderTransactionsResult{},
+			}},
+		},
+		{
+			Pattern: "/constituents", Path: "/constituents", Handler: s.handleConstituents,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "List the member symbols of an index",
+				Params:   []Param{{Name: "index", In: "query", Type: "string", Required: true, Description: "Index symbol: ^GSPC or ^DJI"}},
+				Response: IndexConstituents{},
+			}},
+		},
+		{
+			Pattern: "/exchanges", Path: "/exchanges", Handler: s.handleExchanges,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "List supported exchanges with trading hours and whether each is open",
+				Response: []ExchangeStatus{},
+			}},
+		},
+		{
+			Pattern: "/events", Path: "/events", Handler: s.handleEvents,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "List recent price move and volume spike events, newest first",
+				Params: []Param{
+					{Name: "symbol", In: "query", Type: "string", Description: "Only events for this symbol"},
+					{Name: "limit", In: "query", Type: "integer", Description: "Maximum events to return (default 50)"},
+				},
+				Response: []QuoteEvent{},
+			}},
+		},
+		{
+			Pattern: "/status", Path: "/status", Handler: s.handleStatus,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Report circuit-broken providers, stale features and low-confidence scores",
+				Response: StatusReport{},
+			}},
+		},
+		{
+			Pattern: "/health", Path: "/health", Handler: s.handleHealth,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Probe upstream, cache and database; 503 when a critical dependency is down",
+				Response: HealthReport{},
+			}},
+		},
+		{
+			Pattern: "/ready", Path: "/ready", Handler: s.handleReady,
+			Operations: []Operation{{
+				Method: http.MethodGet, Summary: "Readiness check; 503 until start-up warm-up has finished",
+				Response: map[string]interface{}{},
+			}},
+		},
+	}
+}
+
+// watchlistNameParam is the watchlist name path parameter
+var watchlistNameParam = Param{Name: "name", In: "path", Type: "string", Required: true, Description: "Watchlist name"}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SymbolMatch represents a single symbol search result
+type SymbolMatch struct {
+	Symbol   string  `json:"symbol"`
+	Name     string  `json:"name"`
+	Exchange string  `json:"exchange"`
+	Type     string  `json:"type"`
+	Sector   string  `json:"sector,omitempty"`
+	Industry string  `json:"industry,omitempty"`
+	Score    float64 `json:"score"`
+}
+
+// SearchSymbols looks up symbols matching a free-text query with caching
+func (yf *YahooFinanceAPI) SearchSymbols(query string, limit int) ([]SymbolMatch, error) {
+	cacheKey := fmt.Sprintf("search_%s_%d", strings.ToLower(query), limit)
+	if cached, found := yf.cache.Get(cacheKey); found {
+		if matches, ok := cached.([]SymbolMatch); ok {
+			return matches, nil
+		}
+	}
+
+	matches, err := yf.fetchSearch(query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	yf.cache.Set(cacheKey, matches)
+	return matches, nil
+}
+
+// fetchSearch calls Yahoo's search API
+func (yf *YahooFinanceAPI) fetchSearch(query string, limit int) ([]SymbolMatch, error) {
+	searchURL := fmt.Sprintf("%s/v1/finance/search?q=%s&quotesCount=%d&newsCount=0&lang=en-US",
+		yf.queryURL, url.QueryEscape(query), limit)
+
+	req, err := http.NewRequest("GET", searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", yf.userAgent)
+
+	resp, err := yf.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &UpstreamStatusError{Provider: "Yahoo Finance", StatusCode: resp.StatusCode}
+	}
+
+	var searchResp struct {
+		Quotes []struct {
+			Symbol    string  `json:"symbol"`
+			ShortName string  `json:"shortname"`
+			LongName  string  `json:"longname"`
+			Exchange  string  `json:"exchDisp"`
+			QuoteType string  `json:"quoteType"`
+			Sector    string  `json:"sector"`
+			Industry  string  `json:"industry"`
+			Score     float64 `json:"score"`
+		} `json:"quotes"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&searchResp); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	matches := make([]SymbolMatch, 0, len(searchResp.Quotes))
+	for _, q := range searchResp.Quotes {
+		if q.Symbol == "" {
+			continue
+		}
+		name := q.LongName
+		if name == "" {
+			name = q.ShortName
+		}
+		matches = append(matches, SymbolMatch{
+			Symbol:   q.Symbol,
+			Name:     name,
+			Exchange: q.Exchange,
+			Type:     strings.ToLower(q.QuoteType),
+			Sector:   q.Sector,
+			Industry: q.Industry,
+			Score:    q.Score,
+		})
+	}
+
+	return matches, nil
+}
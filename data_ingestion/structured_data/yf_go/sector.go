@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sectorSummaryModules are the quoteSummary modules needed to classify and value a constituent
+var sectorSummaryModules = []string{"price", "assetProfile", "summaryDetail"}
+
+// SectorMover is a constituent ranked by its daily move
+type SectorMover struct {
+	Symbol     string  `json:"symbol"`
+	Company    string  `json:"company"`
+	Price      float64 `json:"current_price"`
+	ChangePerc float64 `json:"change_percent"`
+}
+
+// SectorMetrics aggregates the known quotes in one sector for peer-relative analysis
+type SectorMetrics struct {
+	Sector           string        `json:"sector"`
+	Constituents     int           `json:"constituents"`
+	MedianPE         float64       `json:"median_pe_ratio"`
+	AvgChangePercent float64       `json:"avg_change_percent"`
+	TotalMarketCap   float64       `json:"total_market_cap"`
+	TopGainers       []SectorMover `json:"top_gainers"`
+	TopLosers        []SectorMover `json:"top_losers"`
+	Symbols          []string      `json:"symbols"`
+	Timestamp        string        `json:"timestamp"`
+}
+
+// sectorConstituent pairs a quote with the fundamentals used for aggregation
+type sectorConstituent struct {
+	quote     *FinancialData
+	pe        float64
+	marketCap float64
+}
+
+// knownSymbols returns every symbol we hold data for: cached quotes, watchlists and persisted history
+func (s *Server) knownSymbols() []string {
+	seen := make(map[string]bool)
+	for _, key := range s.api.cache.Keys("stock_") {
+		seen[strings.TrimPrefix(key, "stock_")] = true
+	}
+	for _, symbol := range s.watchlists.Symbols() {
+		seen[symbol] = true
+	}
+	if s.api.quotes != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		recent, err := s.api.quotes.RecentSymbols(ctx, time.Now().Add(-24*time.Hour))
+		cancel()
+		if err != nil {
+			log.Printf("Error loading persisted symbols: %v", err)
+		}
+		for _, symbol := range recent {
+			seen[symbol] = true
+		}
+	}
+
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// GetSectorMetrics aggregates valuation and performance over the known constituents of a sector
+func (s *Server) GetSectorMetrics(sector string, top int) (*SectorMetrics, error) {
+	var constituents []sectorConstituent
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	// Limit concurrent requests
+	semaphore := make(chan struct{}, s.api.concurrency)
+
+	for _, symbol := range s.knownSymbols() {
+		wg.Add(1)
+		go func(sym string) {
+			defer wg.Done()
+			defer recoverPanic(map[string]string{"component": "sector-metrics", "symbol": sym})
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			summary, err := s.api.GetQuoteSummary(sym, sectorSummaryModules...)
+			if err != nil || !strings.EqualFold(summary.AssetProfile.Sector, sector) {
+				return
+			}
+
+			quote, err := s.api.GetStockData(sym)
+			if err != nil {
+				log.Printf("Error fetching %s: %v", sym, err)
+				return
+			}
+
+			mu.Lock()
+			constituents = append(constituents, sectorConstituent{
+				quote:     quote,
+				pe:        summary.SummaryDetail.TrailingPE.Raw,
+				marketCap: summary.Price.MarketCap.Raw,
+			})
+			mu.Unlock()
+		}(symbol)
+	}
+	wg.Wait()
+
+	if len(constituents) == 0 {
+		return nil, fmt.Errorf("no known constituents for sector %s", sector)
+	}
+
+	metrics := &SectorMetrics{
+		Sector:       sector,
+		Constituents: len(constituents),
+		Timestamp:    time.Now().Format(time.RFC3339),
+	}
+
+	var pes []float64
+	var changeSum float64
+	movers := make([]SectorMover, 0, len(constituents))
+	for _, c := range constituents {
+		if c.pe > 0 {
+			pes = append(pes, c.pe)
+		}
+		changeSum += c.quote.ChangePerc
+		metrics.TotalMarketCap += c.marketCap
+		metrics.Symbols = append(metrics.Symbols, c.quote.Symbol)
+		movers = append(movers, SectorMover{
+			Symbol:     c.quote.Symbol,
+			Company:    c.quote.Company,
+			Price:      c.quote.Price,
+			ChangePerc: c.quote.ChangePerc,
+		})
+	}
+	sort.Strings(metrics.Symbols)
+	metrics.MedianPE = median(pes)
+	metrics.AvgChangePercent = changeSum / float64(len(constituents))
+
+	sort.Slice(movers, func(i, j int) bool { return movers[i].ChangePerc > movers[j].ChangePerc })
+	if top > len(movers) {
+		top = len(movers)
+	}
+	metrics.TopGainers = movers[:top]
+	metrics.TopLosers = make([]SectorMover, 0, top)
+	for i := len(movers) - 1; i >= len(movers)-top; i-- {
+		metrics.TopLosers = append(metrics.TopLosers, movers[i])
+	}
+
+	return metrics, nil
+}
+
+// median returns the median of values, or 0 when empty
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// handleSectorMetrics handles sector aggregate requests
+func (s *Server) handleSectorMetrics(w http.ResponseWriter, r *http.Request) {
+	sector := r.URL.Query().Get("sector")
+	if sector == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "sector parameter is required")
+		return
+	}
+
+	start := time.Now()
+	data, err := s.GetSectorMetrics(sector, 5)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ShortInterest is the latest exchange-reported short position in a symbol
+type ShortInterest struct {
+	Symbol              string  `json:"symbol"`
+	SharesShort         int64   `json:"shares_short"`
+	SharesShortPrior    int64   `json:"shares_short_prior_month,omitempty"`
+	ShortChangePercent  float64 `json:"short_change_percent,omitempty"` // versus the prior month's report
+	DaysToCover         float64 `json:"days_to_cover"`                  // shares short over average daily volume
+	ShortPercentOfFloat float64 `json:"short_percent_of_float"`
+	FloatShares         int64   `json:"float_shares,omitempty"`
+	SettlementDate      string  `json:"settlement_date,omitempty"` // date the short position was measured
+	Timestamp           string  `json:"timestamp"`
+}
+
+// GetShortInterest returns short interest from the quoteSummary defaultKeyStatistics module
+func (yf *YahooFinanceAPI) GetShortInterest(symbol string) (*ShortInterest, error) {
+	symbol = strings.ToUpper(symbol)
+	summary, err := yf.GetQuoteSummary(symbol, "defaultKeyStatistics")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := summary.DefaultKeyStatistics
+	if stats.SharesShort.Raw <= 0 {
+		return nil, fmt.Errorf("no short interest reported for %s: %w", symbol, ErrInsufficientData)
+	}
+
+	result := &ShortInterest{
+		Symbol:              symbol,
+		SharesShort:         int64(stats.SharesShort.Raw),
+		SharesShortPrior:    int64(stats.SharesShortPriorMonth.Raw),
+		DaysToCover:         stats.ShortRatio.Raw,
+		ShortPercentOfFloat: stats.ShortPercentOfFloat.Raw * 100,
+		FloatShares:         int64(stats.FloatShares.Raw),
+		SettlementDate:      stats.DateShortInterest.Fmt,
+		Timestamp:           time.Now().Format(time.RFC3339),
+	}
+	if result.SharesShortPrior > 0 {
+		result.ShortChangePercent = float64(result.SharesShort-result.SharesShortPrior) / float64(result.SharesShortPrior) * 100
+	}
+	return result, nil
+}
+
+// handleShortInterest handles short interest requests
+func (s *Server) handleShortInterest(w http.ResponseWriter, r *http.Request) {
+	symbol := r.URL.Query().Get("symbol")
+	if symbol == "" {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbol parameter is required")
+		return
+	}
+
+	start := time.Now()
+	data, err := s.api.GetShortInterest(symbol)
+	if err != nil {
+		writeAPIError(w, r, err, symbol)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(data)
+}
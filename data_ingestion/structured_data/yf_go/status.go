@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Feature freshness values
+const (
+	featureFresh    = "fresh"
+	featureStale    = "stale"
+	featureNever    = "never_loaded"
+	featureDisabled = "disabled"
+)
+
+// lowConfidenceTTL is how long a low-confidence score stays in the status report
+const lowConfidenceTTL = 24 * time.Hour
+
+// Freshness records when each feature last loaded successfully from its provider
+type Freshness struct {
+	mu      sync.RWMutex
+	updated map[string]time.Time
+}
+
+// NewFreshness creates an empty freshness record
+func NewFreshness() *Freshness {
+	return &Freshness{updated: make(map[string]time.Time)}
+}
+
+// Touch marks feature as updated now; a nil Freshness ignores the call
+func (f *Freshness) Touch(feature string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	f.updated[feature] = time.Now()
+	f.mu.Unlock()
+}
+
+// Updated returns when feature last loaded successfully
+func (f *Freshness) Updated(feature string) (time.Time, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	updated, ok := f.updated[feature]
+	return updated, ok
+}
+
+// FeatureStatus reports how current one feature is
+type FeatureStatus struct {
+	Name        string     `json:"name"`
+	Provider    string     `json:"provider"`
+	Status      string     `json:"status"` // fresh, stale, never_loaded or disabled
+	LastUpdated *time.Time `json:"last_updated,omitempty"`
+	MaxAge      string     `json:"max_age"`
+	CircuitOpen bool       `json:"circuit_open"`
+}
+
+// LowConfidenceScore is a recently computed score whose inputs were incomplete or out of date
+type LowConfidenceScore struct {
+	Symbol     string    `json:"symbol"`
+	Score      string    `json:"score"`
+	Reasons    []string  `json:"reasons"`
+	ComputedAt time.Time `json:"computed_at"`
+}
+
+// StatusReport is the machine-readable degradation report served by /status
+type StatusReport struct {
+	Status              string               `json:"status"` // ok or degraded
+	Timestamp           string               `json:"timestamp"`
+	Providers           []CircuitStatus      `json:"providers"`
+	Features            []FeatureStatus      `json:"features"`
+	LowConfidenceScores []LowConfidenceScore `json:"low_confidence_scores"`
+}
+
+// featureSpec ties a feature to the provider that feeds it and how old it may get
+type featureSpec struct {
+	name     string
+	provider string
+	maxAge   time.Duration
+	enabled  bool
+}
+
+// features lists the data the API serves and when each counts as stale
+func (s *Server) features() []featureSpec {
+	return []featureSpec{
+		{name: "quotes", provider: "yahoo_finance", maxAge: 2 * s.config.Cache.TTL, enabled: true},
+		{name: "fundamentals", provider: "yahoo_finance", maxAge: 24 * time.Hour, enabled: true},
+		{name: "history", provider: "yahoo_finance", maxAge: 24 * time.Hour, enabled: true},
+		{name: "rates", provider: "fred", maxAge: 48 * time.Hour, enabled: s.rates.Enabled()},
+		{name: "insider_transactions", provider: "finnhub", maxAge: 48 * time.Hour, enabled: s.insider.Enabled()},
+		{name: "constituents", provider: urlProvider(s.config.Constituents.SP500URL), maxAge: 2 * s.config.Constituents.RefreshInterval, enabled: s.config.Constituents.SP500URL != "" || s.config.Constituents.DJIURL != ""},
+		{name: "symbol_universe", provider: "nasdaq_trader", maxAge: 2 * s.config.Symbols.RefreshInterval, enabled: s.api.symbols != nil},
+	}
+}
+
+// urlProvider returns the breaker provider name for a feed URL
+func urlProvider(raw string) string {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	return providerName(parsed.Hostname())
+}
+
+// recordLowConfidence remembers a score computed from incomplete inputs
+func (yf *YahooFinanceAPI) recordLowConfidence(symbol, score string, reasons []string) {
+	yf.lowConfidence.Set(score+"_"+strings.ToUpper(symbol), LowConfidenceScore{
+		Symbol:     strings.ToUpper(symbol),
+		Score:      score,
+		Reasons:    reasons,
+		ComputedAt: time.Now(),
+	})
+}
+
+// clearLowConfidence drops a score once it has been recomputed from complete inputs
+func (yf *YahooFinanceAPI) clearLowConfidence(symbol, score string) {
+	yf.lowConfidence.Delete(score + "_" + strings.ToUpper(symbol))
+}
+
+// StatusReport assembles circuit, freshness and score-confidence state
+func (s *Server) StatusReport() StatusReport {
+	report := StatusReport{
+		Status:              "ok",
+		Timestamp:           time.Now().Format(time.RFC3339),
+		Providers:           s.api.breakers.Statuses(),
+		LowConfidenceScores: []LowConfidenceScore{},
+	}
+
+	open := make(map[string]bool)
+	for _, provider := range report.Providers {
+		if provider.State != circuitClosed {
+			open[provider.Provider] = true
+			report.Status = "degraded"
+		}
+	}
+
+	for _, spec := range s.features() {
+		feature := FeatureStatus{
+			Name:        spec.name,
+			Provider:    spec.provider,
+			MaxAge:      spec.maxAge.String(),
+			CircuitOpen: open[spec.provider],
+		}
+		updated, ok := s.api.freshness.Updated(spec.name)
+		switch {
+		case !spec.enabled:
+			feature.Status = featureDisabled
+		case !ok:
+			feature.Status = featureNever
+		case time.Since(updated) > spec.maxAge:
+			feature.Status = featureStale
+			report.Status = "degraded"
+		default:
+			feature.Status = featureFresh
+		}
+		if ok {
+			feature.LastUpdated = &updated
+		}
+		report.Features = append(report.Features, feature)
+	}
+
+	for _, key := range s.api.lowConfidence.Keys("") {
+		if cached, found := s.api.lowConfidence.Get(key); found {
+			if score, ok := cached.(LowConfidenceScore); ok {
+				report.LowConfidenceScores = append(report.LowConfidenceScores, score)
+			}
+		}
+	}
+	sort.Slice(report.LowConfidenceScores, func(i, j int) bool {
+		a, b := report.LowConfidenceScores[i], report.LowConfidenceScores[j]
+		if a.Score != b.Score {
+			return a.Score < b.Score
+		}
+		return a.Symbol < b.Symbol
+	})
+	return report
+}
+
+// handleStatus serves the degradation report; it always answers 200 so banners can render during outages
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	report := s.StatusReport()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Response-Time", time.Since(start).String())
+	json.NewEncoder(w).Encode(report)
+}
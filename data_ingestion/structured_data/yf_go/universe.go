@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// ErrInvalidSymbol marks symbols that cannot be a ticker at all
+var ErrInvalidSymbol = errors.New("invalid symbol format")
+
+// UnknownSymbolError is returned for well-formed symbols Yahoo does not list
+type UnknownSymbolError struct {
+	Symbol      string
+	Suggestions []string
+}
+
+// Error implements error
+func (e *UnknownSymbolError) Error() string {
+	if len(e.Suggestions) == 0 {
+		return fmt.Sprintf("unknown symbol %s", e.Symbol)
+	}
+	return fmt.Sprintf("unknown symbol %s; did you mean %s?", e.Symbol, strings.Join(e.Suggestions, ", "))
+}
+
+// Unwrap lets callers match unknown symbols with errors.Is(err, ErrSymbolNotFound)
+func (e *UnknownSymbolError) Unwrap() error {
+	return ErrSymbolNotFound
+}
+
+// SymbolUniverse is the cached set of known tickers, seeded from an exchange listing
+// file and extended with symbols Yahoo has confirmed since
+type SymbolUniverse struct {
+	url       string
+	client    *http.Client
+	userAgent string
+	listed    map[string]string // symbol -> security name from the listing file
+	verified  map[string]bool   // symbols confirmed by Yahoo but absent from the listing
+	unknown   *Cache            // symbols Yahoo does not know, with their suggestions
+	freshness *Freshness
+	mu        sync.RWMutex
+}
+
+// NewSymbolUniverse creates an empty universe backed by the listing file at url
+func NewSymbolUniverse(url, userAgent string) *SymbolUniverse {
+	return &SymbolUniverse{
+		url:       url,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		userAgent: userAgent,
+		listed:    make(map[string]string),
+		verified:  make(map[string]bool),
+		unknown:   NewCache(time.Hour),
+	}
+}
+
+// Start loads the listing file and reloads it every interval
+func (su *SymbolUniverse) Start(interval time.Duration) {
+	go func() {
+		defer recoverPanic(map[string]string{"component": "symbol_universe"})
+		for {
+			if err := su.Refresh(); err != nil {
+				log.Printf("Symbol universe refresh failed: %v", err)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Refresh downloads the pipe-delimited Nasdaq Trader symbol directory and replaces the listing
+func (su *SymbolUniverse) Refresh() error {
+	req, err := http.NewRequest("GET", su.url, nil)
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("User-Agent", su.userAgent)
+
+	resp, err := su.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("making request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &UpstreamStatusError{Provider: "Nasdaq Trader", StatusCode: resp.StatusCode}
+	}
+
+	listed := make(map[string]string)
+	scanner := bufio.NewScanner(resp.Body)
+	symbolCol, nameCol, testCol := -1, -1, -1
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "|")
+		if symbolCol < 0 {
+			for i, field := range fields {
+				switch field {
+				case "Symbol":
+					symbolCol = i
+				case "Security Name":
+					nameCol = i
+				case "Test Issue":
+					testCol = i
+				}
+			}
+			if symbolCol < 0 || nameCol < 0 {
+				return fmt.Errorf("unexpected listing header %q", scanner.Text())
+			}
+			continue
+		}
+		if len(fields) <= symbolCol || len(fields) <= nameCol {
+			continue // trailer line with the file creation time
+		}
+		if testCol >= 0 && testCol < len(fields) && fields[testCol] == "Y" {
+			continue
+		}
+
+		// The directory writes share classes as BRK.B where Yahoo uses BRK-B; preferreds and warrants carry $ and are skipped
+		if strings.Contains(fields[symbolCol], "$") {
+			continue
+		}
+		symbol, err := symbols.From(fields[symbolCol], symbols.Nasdaq)
+		if err != nil {
+			continue
+		}
+		listed[symbol] = fields[nameCol]
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading listing: %w", err)
+	}
+	if len(listed) == 0 {
+		return errors.New("listing contained no symbols")
+	}
+
+	su.mu.Lock()
+	su.listed = listed
+	su.mu.Unlock()
+	su.freshness.Touch("symbol_universe")
+	log.Printf("Loaded %d symbols into the symbol universe", len(listed))
+	return nil
+}
+
+// Known reports whether a symbol is listed or has been confirmed by Yahoo
+func (su *SymbolUniverse) Known(symbol string) bool {
+	su.mu.RLock()
+	defer su.mu.RUnlock()
+
+	_, listed := su.listed[symbol]
+	return listed || su.verified[symbol]
+}
+
+// MarkKnown records a symbol Yahoo returned data for
+func (su *SymbolUniverse) MarkKnown(symbol string) {
+	su.mu.Lock()
+	defer su.mu.Unlock()
+
+	if _, listed := su.listed[symbol]; !listed {
+		su.verified[symbol] = true
+	}
+}
+
+// Closest returns up to limit listed symbols within two edits of symbol, nearest first
+func (su *SymbolUniverse) Closest(symbol string, limit int) []string {
+	su.mu.RLock()
+	defer su.mu.RUnlock()
+
+	type candidate struct {
+		symbol   string
+		distance int
+	}
+	var candidates []candidate
+	for listed := range su.listed {
+		if diff := len(listed) - len(symbol); diff > 2 || diff < -2 {
+			continue
+		}
+		if d := editDistance(symbol, listed); d <= 2 {
+			candidates = append(candidates, candidate{listed, d})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].symbol < candidates[j].symbol
+	})
+
+	var result []string
+	for _, c := range candidates {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, c.symbol)
+	}
+	return result
+}
+
+// editDistance returns the Levenshtein distance between two ASCII strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// validSymbol returns the canonical Yahoo form of symbol, rejecting malformed tickers
+// and unknown exchange suffixes
+func validSymbol(symbol string) (string, error) {
+	canonical, err := symbols.Normalize(symbol)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidSymbol, err)
+	}
+	return canonical, nil
+}
+
+// canonicalSymbol maps notation variants such as BRK.B and BRK/B to BRK-B so they share cache
+// entries. Unparseable input is only upper-cased, leaving validation to report the error.
+func canonicalSymbol(symbol string) string {
+	if canonical, err := symbols.Normalize(symbol); err == nil {
+		return canonical
+	}
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+// CheckSymbol validates a symbol before any per-symbol upstream call. Well-formed symbols
+// outside the universe are confirmed through Yahoo search; if search itself fails the
+// symbol is let through so an outage there does not block quotes.
+func (yf *YahooFinanceAPI) CheckSymbol(symbol string) error {
+	symbol, err := validSymbol(symbol)
+	if err != nil {
+		return err
+	}
+	if yf.symbols == nil || yf.symbols.Known(symbol) {
+		return nil
+	}
+	if cached, found := yf.symbols.unknown.Get(symbol); found {
+		if err, ok := cached.(*UnknownSymbolError); ok {
+			return err
+		}
+	}
+
+	matches, err := yf.SearchSymbols(symbol, 6)
+	if err != nil {
+		log.Printf("Could not verify symbol %s: %v", symbol, err)
+		return nil
+	}
+	for _, match := range matches {
+		if strings.EqualFold(match.Symbol, symbol) {
+			yf.symbols.MarkKnown(symbol)
+			return nil
+		}
+	}
+	return yf.unknownSymbol(symbol, matches)
+}
+
+// unknownSymbol builds and remembers the error for a symbol Yahoo does not know,
+// suggesting search hits first and then near-miss tickers from the universe
+func (yf *YahooFinanceAPI) unknownSymbol(symbol string, matches []SymbolMatch) *UnknownSymbolError {
+	const maxSuggestions = 5
+
+	seen := make(map[string]bool)
+	var suggestions []string
+	add := func(candidate string) {
+		candidate = strings.ToUpper(candidate)
+		if candidate != symbol && !seen[candidate] && len(suggestions) < maxSuggestions {
+			seen[candidate] = true
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	for _, match := range matches {
+		add(match.Symbol)
+	}
+	if yf.symbols != nil {
+		for _, candidate := range yf.symbols.Closest(symbol, maxSuggestions) {
+			add(candidate)
+		}
+	}
+
+	err := &UnknownSymbolError{Symbol: symbol, Suggestions: suggestions}
+	if yf.symbols != nil {
+		yf.symbols.unknown.Set(symbol, err)
+	}
+	return err
+}
+
+// symbolNotFound converts an upstream not-found answer for symbol into an UnknownSymbolError
+func (yf *YahooFinanceAPI) symbolNotFound(symbol string, err error) error {
+	var statusErr *UpstreamStatusError
+	notFound := errors.Is(err, ErrSymbolNotFound) ||
+		errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+	if !notFound {
+		return err
+	}
+
+	var unknown *UnknownSymbolError
+	if errors.As(err, &unknown) {
+		return err
+	}
+	symbol = strings.ToUpper(symbol)
+	matches, _ := yf.SearchSymbols(symbol, 6)
+	return yf.unknownSymbol(symbol, matches)
+}
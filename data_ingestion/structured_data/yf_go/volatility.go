@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// tradingDaysPerYear is used to annualize daily volatility
+const tradingDaysPerYear = 252
+
+// WindowVolatility holds realized volatility for one lookback window
+type WindowVolatility struct {
+	Window       int     `json:"window"`
+	Annualized   float64 `json:"annualized_volatility"`
+	Daily        float64 `json:"daily_volatility"`
+	Observations int     `json:"observations"`
+}
+
+// VolatilityResult represents realized volatility across several windows
+type VolatilityResult struct {
+	Symbol    string             `json:"symbol"`
+	AsOf      string             `json:"as_of"`
+	Windows   []WindowVolatility `json:"windows"`
+	Timestamp string             `json:"timestamp"`
+}
+
+// GetVolatility computes annualized realized volatility from daily log returns
+func (yf *YahooFinanceAPI) GetVolatility(symbol string, windows []int) (*VolatilityResult, error) {
+	maxWindow := 0
+	for _, w := range windows {
+		if w < 2 {
+			return nil, fmt.Errorf("window must be at least 2 days, got %d", w)
+		}
+		if w > maxWindow {
+			maxWindow = w
+		}
+	}
+
+	period := "1y"
+	if maxWindow >= 240 {
+		period = "2y"
+	}
+	if maxWindow >= 500 {
+		period = "5y"
+	}
+
+	history, err := yf.GetHistory(symbol, period)
+	if err != nil {
+		return nil, err
+	}
+
+	returns := logReturns(history)
+	if len(returns) < 2 {
+		return nil, fmt.Errorf("insufficient price history for symbol %s: %w", symbol, ErrInsufficientData)
+	}
+
+	result := &VolatilityResult{
+		Symbol:    strings.ToUpper(symbol),
+		AsOf:      history[len(history)-1].Date,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	for _, w := range windows {
+		// Use the most recent w returns, or all of them if history is shorter
+		window := returns
+		if len(returns) > w {
+			window = returns[len(returns)-w:]
+		}
+		daily := stdDev(window)
+		result.Windows = append(result.Windows, WindowVolatility{
+			Window:       w,
+			Daily:        daily,
+			Annualized:   daily * math.Sqrt(tradingDaysPerYear),
+			Observations: len(window),
+		})
+	}
+
+	return result, nil
+}
+
+// logReturns computes daily log returns from adjusted closes
+func logReturns(history []PricePoint) []float64 {
+	returns := make([]float64, 0, len(history))
+	for i := 1; i < len(history); i++ {
+		prev, curr := history[i-1].AdjClose, history[i].AdjClose
+		if prev <= 0 || curr <= 0 {
+			continue
+		}
+		returns = append(returns, math.Log(curr/prev))
+	}
+	return returns
+}
+
+// stdDev returns the sample standard deviation
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+
+	var sumSq float64
+	for _, v := range values {
+		sumSq += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sumSq / float64(len(values)-1))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"yahoo-finance-go/config"
+)
+
+// WarmUp pre-fetches the top-priority watchlist tier and the FRED rates, then marks the server ready.
+// It gives up after the configured timeout so a slow upstream cannot block readiness forever.
+func (s *Server) WarmUp(cfg config.WarmupConfig) {
+	defer s.ready.Store(true)
+	if !cfg.Enabled {
+		return
+	}
+
+	start := time.Now()
+	symbols := s.watchlists.PrioritySymbols(cfg.MaxPriority)
+	if len(symbols) == 0 {
+		symbols = s.watchlists.Symbols()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.prefetchSymbols(symbols)
+		if s.rates.Enabled() {
+			if _, err := s.rates.GetRates(context.Background()); err != nil {
+				log.Printf("Warm-up rates fetch failed: %v", err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+		log.Printf("Warm-up completed for %d symbols in %v", len(symbols), time.Since(start))
+	case <-time.After(cfg.Timeout):
+		log.Printf("Warm-up timed out after %v, marking ready with a partially warm cache", cfg.Timeout)
+	}
+}
+
+// handleReady reports 200 once warm-up has finished and 503 before
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	status := "ready"
+	code := http.StatusOK
+	if !s.ready.Load() {
+		status = "warming_up"
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    status,
+		"timestamp": time.Now().Format(time.RFC3339),
+	})
+}
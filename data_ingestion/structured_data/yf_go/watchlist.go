@@ -0,0 +1,367 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Watchlist represents a named list of symbols
+type Watchlist struct {
+	Name      string    `json:"name"`
+	Symbols   []string  `json:"symbols"`
+	Priority  int       `json:"priority,omitempty"` // 1 is the highest tier; 0 is unprioritized
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WatchlistStore persists watchlists to a JSON file
+type WatchlistStore struct {
+	path  string
+	lists map[string]*Watchlist
+	mu    sync.RWMutex
+}
+
+// NewWatchlistStore loads watchlists from path, starting empty if the file does not exist
+func NewWatchlistStore(path string) (*WatchlistStore, error) {
+	store := &WatchlistStore{
+		path:  path,
+		lists: make(map[string]*Watchlist),
+	}
+
+	raw, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading watchlists: %w", err)
+	}
+
+	var lists []*Watchlist
+	if err := json.Unmarshal(raw, &lists); err != nil {
+		return nil, fmt.Errorf("decoding watchlists: %w", err)
+	}
+	for _, list := range lists {
+		store.lists[list.Name] = list
+	}
+	return store, nil
+}
+
+// List returns all watchlists sorted by name
+func (ws *WatchlistStore) List() []*Watchlist {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	lists := make([]*Watchlist, 0, len(ws.lists))
+	for _, list := range ws.lists {
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	return lists
+}
+
+// Get returns a watchlist by name
+func (ws *WatchlistStore) Get(name string) (*Watchlist, bool) {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	list, ok := ws.lists[name]
+	return list, ok
+}
+
+// Put creates or replaces a watchlist and persists the store
+func (ws *WatchlistStore) Put(name string, symbols []string, priority int) (*Watchlist, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	now := time.Now()
+	list, exists := ws.lists[name]
+	if !exists {
+		list = &Watchlist{Name: name, CreatedAt: now}
+	}
+	updated := *list
+	updated.Symbols = normalizeSymbolList(symbols)
+	updated.Priority = priority
+	updated.UpdatedAt = now
+
+	ws.lists[name] = &updated
+	if err := ws.save(); err != nil {
+		if exists {
+			ws.lists[name] = list
+		} else {
+			delete(ws.lists, name)
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// Delete removes a watchlist and persists the store
+func (ws *WatchlistStore) Delete(name string) (bool, error) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	list, exists := ws.lists[name]
+	if !exists {
+		return false, nil
+	}
+	delete(ws.lists, name)
+	if err := ws.save(); err != nil {
+		ws.lists[name] = list
+		return false, err
+	}
+	return true, nil
+}
+
+// Symbols returns the de-duplicated union of all watchlisted symbols
+func (ws *WatchlistStore) Symbols() []string {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	var all []string
+	for _, list := range ws.lists {
+		all = append(all, list.Symbols...)
+	}
+	return normalizeSymbolList(all)
+}
+
+// PrioritySymbols returns the symbols of watchlists in tiers 1 through maxPriority
+func (ws *WatchlistStore) PrioritySymbols(maxPriority int) []string {
+	ws.mu.RLock()
+	defer ws.mu.RUnlock()
+
+	var all []string
+	for _, list := range ws.lists {
+		if list.Priority >= 1 && list.Priority <= maxPriority {
+			all = append(all, list.Symbols...)
+		}
+	}
+	return normalizeSymbolList(all)
+}
+
+// save writes the store atomically; callers must hold the write lock
+func (ws *WatchlistStore) save() error {
+	lists := make([]*Watchlist, 0, len(ws.lists))
+	for _, list := range ws.lists {
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+
+	raw, err := json.MarshalIndent(lists, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding watchlists: %w", err)
+	}
+
+	if dir := filepath.Dir(ws.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("creating watchlist directory: %w", err)
+		}
+	}
+
+	tmp := ws.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return fmt.Errorf("writing watchlists: %w", err)
+	}
+	return os.Rename(tmp, ws.path)
+}
+
+// normalizeSymbolList canonicalizes and de-duplicates symbols, so BRK.B and BRK-B are one
+// entry stored under the key GetStockData reads
+func normalizeSymbolList(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = canonicalSymbol(symbol)
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		result = append(result, symbol)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// RefreshStockData fetches fresh data for a symbol and replaces the cached entry. Once the
+// symbol's market has closed and its quotes have settled, the cached quote is served instead,
+// since the price cannot move until the market reopens.
+func (yf *YahooFinanceAPI) RefreshStockData(symbol string) (*FinancialData, error) {
+	symbol = canonicalSymbol(symbol)
+	if !ExchangeFor(symbol).quotesMoving(time.Now()) {
+		return yf.GetStockData(symbol)
+	}
+	cacheKey := fmt.Sprintf("stock_%s", symbol)
+	return coalesce(&yf.flights, cacheKey, func() (*FinancialData, error) {
+		return yf.fetchStockData(symbol, cacheKey)
+	})
+}
+
+// PrefetchWatchlists refreshes every watchlisted symbol, bounded by the concurrency limit
+func (s *Server) PrefetchWatchlists() {
+	s.prefetchSymbols(s.watchlists.Symbols())
+}
+
+// prefetchSymbols refreshes the given symbols, bounded by the concurrency limit
+func (s *Server) prefetchSymbols(symbols []string) {
+	if len(symbols) == 0 {
+		return
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, s.api.concurrency)
+	for _, symbol := range symbols {
+		wg.Add(1)
+		go func(sym string) {
+			defer wg.Done()
+			defer recoverPanic(map[string]string{"component": "prefetch", "symbol": sym})
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if _, err := s.api.RefreshStockData(sym); err != nil {
+				log.Printf("Prefetch failed for %s: %v", sym, err)
+			}
+		}(symbol)
+	}
+	wg.Wait()
+	log.Printf("Prefetched %d watchlisted symbols in %v", len(symbols), time.Since(start))
+}
+
+// startWatchlistPrefetcher refreshes watchlisted symbols ahead of cache expiry.
+// The first pass skips symbols the warm-up already loaded.
+func (s *Server) startWatchlistPrefetcher(interval time.Duration) {
+	go func() {
+		var cold []string
+		for _, symbol := range s.watchlists.Symbols() {
+			if _, found := s.api.cache.Get(fmt.Sprintf("stock_%s", symbol)); !found {
+				cold = append(cold, symbol)
+			}
+		}
+		s.prefetchSymbols(cold)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.PrefetchWatchlists()
+		}
+	}()
+}
+
+// handleWatchlists handles watchlist collection requests
+func (s *Server) handleWatchlists(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.watchlists.List())
+
+	case http.MethodPost:
+		var body Watchlist
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "invalid JSON body")
+			return
+		}
+		if !validWatchlistName(body.Name) {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "name must be 1-64 characters of letters, digits, '-' or '_'")
+			return
+		}
+		if _, exists := s.watchlists.Get(body.Name); exists {
+			writeError(w, r, http.StatusConflict, CodeConflict, "watchlist already exists")
+			return
+		}
+		s.writeWatchlist(w, r, body.Name, body.Symbols, body.Priority, http.StatusCreated)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleWatchlist handles single watchlist requests at /watchlists/{name}
+func (s *Server) handleWatchlist(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/watchlists/")
+	if !validWatchlistName(name) {
+		writeError(w, r, http.StatusNotFound, CodeNotFound, "watchlist not found")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, ok := s.watchlists.Get(name)
+		if !ok {
+			writeError(w, r, http.StatusNotFound, CodeNotFound, "watchlist not found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(list)
+
+	case http.MethodPut:
+		var body Watchlist
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "invalid JSON body")
+			return
+		}
+		s.writeWatchlist(w, r, name, body.Symbols, body.Priority, http.StatusOK)
+
+	case http.MethodDelete:
+		deleted, err := s.watchlists.Delete(name)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, CodeInternal, err.Error())
+			return
+		}
+		if !deleted {
+			writeError(w, r, http.StatusNotFound, CodeNotFound, "watchlist not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		writeError(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, "method not allowed")
+	}
+}
+
+// writeWatchlist stores a watchlist, warms its symbols and writes it back
+func (s *Server) writeWatchlist(w http.ResponseWriter, r *http.Request, name string, symbols []string, priority int, status int) {
+	if len(symbols) == 0 || len(symbols) > 200 {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "symbols must contain between 1 and 200 entries")
+		return
+	}
+
+	if priority < 0 {
+		writeError(w, r, http.StatusBadRequest, CodeInvalidRequest, "priority must not be negative")
+		return
+	}
+
+	list, err := s.watchlists.Put(name, symbols, priority)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, CodeInternal, err.Error())
+		return
+	}
+
+	// Warm the cache for new symbols without blocking the response
+	go s.api.GetMultipleStocks(list.Symbols)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(list)
+}
+
+// validWatchlistName checks that a name is safe to use in paths
+func validWatchlistName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxStatementAge is how old the latest balance sheet may be before a score is low-confidence
+const maxStatementAge = 18 * 30 * 24 * time.Hour
+
+// manufacturingSectors are the Yahoo sectors scored with the original Altman Z model
+var manufacturingSectors = map[string]bool{
+	"Industrials":        true,
+	"Basic Materials":    true,
+	"Energy":             true,
+	"Consumer Cyclical":  true,
+	"Consumer Defensive": true,
+}
+
+// ZScoreComponents holds the ratio terms that feed the Altman models
+type ZScoreComponents struct {
+	WorkingCapitalToAssets   float64 `json:"working_capital_to_assets"`    // X1
+	RetainedEarningsToAssets float64 `json:"retained_earnings_to_assets"`  // X2
+	EBITToAssets             float64 `json:"ebit_to_assets"`               // X3
+	MarketEquityToLiab       float64 `json:"market_equity_to_liabilities"` // X4 (Z)
+	BookEquityToLiab         float64 `json:"book_equity_to_liabilities"`   // X4 (Z'')
+	SalesToAssets            float64 `json:"sales_to_assets"`              // X5
+}
+
+// ZScoreResult represents the Altman Z-score assessment for a company
+type ZScoreResult struct {
+	Symbol        string           `json:"symbol"`
+	Company       string           `json:"company"`
+	Sector        string           `json:"sector"`
+	Model         string           `json:"model"`
+	ZScore        float64          `json:"z_score"`
+	ZDoublePrime  *float64         `json:"z_double_prime_score,omitempty"`
+	Zone          string           `json:"zone"`
+	Components    ZScoreComponents `json:"components"`
+	FiscalYearEnd string           `json:"fiscal_year_end"`
+	Confidence    string           `json:"confidence"`               // high, or low when inputs are missing or stale
+	MissingInputs []string         `json:"missing_inputs,omitempty"` // statement items Yahoo did not report
+	Timestamp     string           `json:"timestamp"`
+}
+
+// GetZScore computes the Altman Z-score (and the Z-double-prime score for non-manufacturers)
+func (yf *YahooFinanceAPI) GetZScore(symbol string) (*ZScoreResult, error) {
+	summary, err := yf.GetQuoteSummary(symbol, "price", "assetProfile", "balanceSheetHistory", "incomeStatementHistory")
+	if err != nil {
+		return nil, err
+	}
+
+	balanceSheets := summary.BalanceSheetHistory.BalanceSheetStatements
+	incomeStatements := summary.IncomeStatementHistory.IncomeStatementHistory
+	if len(balanceSheets) == 0 || len(incomeStatements) == 0 {
+		return nil, fmt.Errorf("insufficient financial statements for symbol %s: %w", symbol, ErrInsufficientData)
+	}
+
+	// Statements are returned most recent first
+	bs := balanceSheets[0]
+	is := incomeStatements[0]
+
+	totalAssets := bs.TotalAssets.Raw
+	totalLiab := bs.TotalLiab.Raw
+	if totalAssets == 0 || totalLiab == 0 {
+		return nil, fmt.Errorf("missing total assets or liabilities for symbol %s: %w", symbol, ErrInsufficientData)
+	}
+
+	components := ZScoreComponents{
+		WorkingCapitalToAssets:   (bs.TotalCurrentAssets.Raw - bs.TotalCurrentLiabilities.Raw) / totalAssets,
+		RetainedEarningsToAssets: bs.RetainedEarnings.Raw / totalAssets,
+		EBITToAssets:             is.Ebit.Raw / totalAssets,
+		MarketEquityToLiab:       summary.Price.MarketCap.Raw / totalLiab,
+		BookEquityToLiab:         bs.TotalStockholderEquity.Raw / totalLiab,
+		SalesToAssets:            is.TotalRevenue.Raw / totalAssets,
+	}
+
+	z := 1.2*components.WorkingCapitalToAssets +
+		1.4*components.RetainedEarningsToAssets +
+		3.3*components.EBITToAssets +
+		0.6*components.MarketEquityToLiab +
+		1.0*components.SalesToAssets
+
+	company := summary.Price.LongName
+	if company == "" {
+		company = summary.Price.ShortName
+	}
+
+	result := &ZScoreResult{
+		Symbol:        strings.ToUpper(symbol),
+		Company:       company,
+		Sector:        summary.AssetProfile.Sector,
+		Model:         "altman_z",
+		ZScore:        z,
+		Zone:          classifyZScore(z),
+		Components:    components,
+		FiscalYearEnd: bs.EndDate.Fmt,
+		Timestamp:     time.Now().Format(time.RFC3339),
+	}
+
+	// Non-manufacturers drop the sales term and use book equity (Z''-score)
+	if !manufacturingSectors[summary.AssetProfile.Sector] {
+		zpp := 6.56*components.WorkingCapitalToAssets +
+			3.26*components.RetainedEarningsToAssets +
+			6.72*components.EBITToAssets +
+			1.05*components.BookEquityToLiab
+		result.Model = "altman_z_double_prime"
+		result.ZDoublePrime = &zpp
+		result.Zone = classifyZDoublePrime(zpp)
+	}
+
+	// Yahoo omits line items it has no value for, which silently zeroes their ratio terms
+	inputs := map[string]float64{
+		"total_current_assets":      bs.TotalCurrentAssets.Raw,
+		"total_current_liabilities": bs.TotalCurrentLiabilities.Raw,
+		"retained_earnings":         bs.RetainedEarnings.Raw,
+		"ebit":                      is.Ebit.Raw,
+	}
+	if result.Model == "altman_z" {
+		inputs["market_cap"] = summary.Price.MarketCap.Raw
+		inputs["total_revenue"] = is.TotalRevenue.Raw
+	} else {
+		inputs["stockholder_equity"] = bs.TotalStockholderEquity.Raw
+	}
+	for name, value := range inputs {
+		if value == 0 {
+			result.MissingInputs = append(result.MissingInputs, name)
+		}
+	}
+	sort.Strings(result.MissingInputs)
+
+	reasons := make([]string, 0, len(result.MissingInputs)+1)
+	for _, name := range result.MissingInputs {
+		reasons = append(reasons, "missing "+name)
+	}
+	if bs.EndDate.Raw > 0 && time.Since(time.Unix(int64(bs.EndDate.Raw), 0)) > maxStatementAge {
+		reasons = append(reasons, "latest balance sheet is from "+bs.EndDate.Fmt)
+	}
+
+	result.Confidence = "high"
+	if len(reasons) > 0 {
+		result.Confidence = "low"
+		yf.recordLowConfidence(symbol, result.Model, reasons)
+	} else {
+		yf.clearLowConfidence(symbol, result.Model)
+	}
+
+	return result, nil
+}
+
+// classifyZScore maps an original Altman Z-score to its zone
+func classifyZScore(z float64) string {
+	switch {
+	case z > 2.99:
+		return "safe"
+	case z >= 1.81:
+		return "grey"
+	default:
+		return "distress"
+	}
+}
+
+// classifyZDoublePrime maps a Z-double-prime score to its zone
+func classifyZDoublePrime(z float64) string {
+	switch {
+	case z > 2.6:
+		return "safe"
+	case z >= 1.1:
+		return "grey"
+	default:
+		return "distress"
+	}
+}
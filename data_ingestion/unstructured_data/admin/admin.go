@@ -0,0 +1,141 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/dedup"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/ingestion"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/pii"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/retention"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/search"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/webhooks"
+)
+
+// Server is the operator-facing HTTP API. It binds to localhost by default.
+// Without a token it serves only the redacted configuration at /config; with
+// one, every route requires the token:
+//
+//   - /config: redacted configuration
+//   - /documents, /console: document browser and console
+//   - /jobs: processing job queue
+//   - /sources: source controls
+//   - /webhooks: webhook subscriptions, when Options.Webhooks is set
+//   - /search: full-text search, when Options.Search is set
+//   - /retention: the retention purge, when Options.Retention is set
+//   - /pii: PII scrubbing counts, when Options.PII is set
+//   - /dedup: seen document counts, when Options.Seen is set
+type Server struct {
+	config    *config.Config
+	storage   storage.Storage
+	manager   *ingestion.Manager
+	webhooks  *webhooks.Notifier
+	search    *search.Indexer
+	retention *retention.Purger
+	pii       *pii.Scrubber
+	seen      *dedup.Seen
+	server    *http.Server
+}
+
+// Options are the optional features the server serves routes for; nil
+// leaves a feature's routes out.
+type Options struct {
+	Webhooks  *webhooks.Notifier
+	Search    *search.Indexer
+	Retention *retention.Purger
+	PII       *pii.Scrubber
+	Seen      *dedup.Seen
+}
+
+func NewServer(cfg *config.Config, store storage.Storage, manager *ingestion.Manager, opts Options) *Server {
+	s := &Server{
+		config:    cfg,
+		storage:   store,
+		manager:   manager,
+		webhooks:  opts.Webhooks,
+		search:    opts.Search,
+		retention: opts.Retention,
+		pii:       opts.PII,
+		seen:      opts.Seen,
+	}
+
+	mux := http.NewServeMux()
+	if cfg.Admin.Token != "" {
+		mux.HandleFunc("/config", s.requireToken(s.handleConfig))
+		mux.HandleFunc("/documents", s.requireToken(s.handleDocuments))
+		mux.HandleFunc("/documents/", s.requireToken(s.handleDocument))
+		mux.HandleFunc("/console", s.requireToken(s.handleConsole))
+		mux.HandleFunc("/jobs", s.requireToken(s.handleJobs))
+		mux.HandleFunc("/jobs/", s.requireToken(s.handleJob))
+		mux.HandleFunc("/sources", s.requireToken(s.handleSources))
+		mux.HandleFunc("/sources/", s.requireToken(s.handleSource))
+		if s.webhooks != nil {
+			mux.HandleFunc("/webhooks", s.requireToken(s.handleWebhooks))
+			mux.HandleFunc("/webhooks/", s.requireToken(s.handleWebhook))
+		}
+		if s.search != nil {
+			mux.HandleFunc("/search", s.requireToken(s.handleSearch))
+		}
+		if s.retention != nil {
+			mux.HandleFunc("/retention", s.requireToken(s.handleRetention))
+		}
+		if s.pii != nil {
+			mux.HandleFunc("/pii", s.requireToken(s.handlePII))
+		}
+		if s.seen != nil {
+			mux.HandleFunc("/dedup", s.requireToken(s.handleDedup))
+		}
+	} else {
+		mux.HandleFunc("/config", s.handleConfig)
+	}
+
+	s.server = &http.Server{
+		Addr:         cfg.Admin.Addr,
+		Handler:      reporting.Middleware(mux),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return s
+}
+
+func (s *Server) Start() error {
+	log.Printf("Starting admin API on %s", s.config.Admin.Addr)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Admin API stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// handleConfig returns the effective configuration with secrets redacted,
+// along with the minimum interval enforced for each source.
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	minimums := make(map[string]string, len(config.MinIntervals))
+	for name, min := range config.MinIntervals {
+		minimums[name] = min.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"config":        s.config.Describe(),
+		"min_intervals": minimums,
+	})
+}
@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// requireToken accepts the admin token as a bearer token or as the basic auth
+// password, so both scripts and a browser prompt can reach the console.
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if _, password, ok := r.BasicAuth(); ok {
+			token = password
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Admin.Token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="ingestion admin"`)
+			http.Error(w, "admin token required", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleDocuments lists stored documents filtered by source, type, tag,
+// near-duplicate cluster, publication window and text, one per cluster with
+// dedup=true: /documents?source=reuters&type=news&tag=banking&days=7&limit=50&q="covenant+waiver"
+func (s *Server) handleDocuments(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filters := storage.DataFilters{
+		Source:      query.Get("source"),
+		Type:        query.Get("type"),
+		ClusterID:   query.Get("cluster"),
+		Query:       query.Get("q"),
+		Deduplicate: query.Get("dedup") == "true",
+		Limit:       50,
+	}
+	if tag := query.Get("tag"); tag != "" {
+		filters.Tags = []string{tag}
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 500 {
+		filters.Limit = limit
+	}
+	if days, err := strconv.Atoi(query.Get("days")); err == nil && days > 0 {
+		from := time.Now().AddDate(0, 0, -days)
+		filters.DateFrom = &from
+	}
+
+	docs, err := s.storage.ListUnstructuredData(r.Context(), filters)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(docs) > filters.Limit {
+		docs = docs[:filters.Limit]
+	}
+
+	writeJSON(w, map[string]interface{}{"count": len(docs), "documents": docs})
+}
+
+// handleDocument returns one document, optionally as it was stored at as_of
+// (RFC 3339), together with its revision history.
+func (s *Server) handleDocument(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/documents/")
+	if id == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		at, err := time.Parse(time.RFC3339, asOf)
+		if err != nil {
+			http.Error(w, "as_of must be an RFC 3339 timestamp", http.StatusBadRequest)
+			return
+		}
+		doc, err := s.storage.GetUnstructuredDataAsOf(r.Context(), id, at)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, doc)
+		return
+	}
+
+	doc, err := s.storage.GetUnstructuredData(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	revisions, err := s.storage.ListRevisions(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"document": doc, "revisions": len(revisions)})
+}
+
+func (s *Server) handleConsole(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(consolePage))
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(v)
+}
+
+// consolePage is a small form over /documents and /documents/{id} for
+// exploring what sources store without any client tooling.
+const consolePage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>Document console</title>
+  <style>
+    body { font-family: sans-serif; margin: 2em; max-width: 72em; }
+    label { margin-right: 1em; }
+    pre { background: #f4f4f4; padding: 1em; overflow: auto; max-height: 40em; }
+    .meta { color: #666; }
+  </style>
+</head>
+<body>
+  <h1>Document console</h1>
+  <p class="meta">Browse stored documents. Effective configuration: <a href="/config">/config</a>.</p>
+  <form id="list">
+    <label>q <input name="q" placeholder="&quot;covenant waiver&quot;"></label>
+    <label>source <input name="source"></label>
+    <label>type <input name="type" placeholder="news"></label>
+    <label>tag <input name="tag"></label>
+    <label>days <input name="days" size="4"></label>
+    <label>limit <input name="limit" size="4" value="20"></label>
+    <button>List</button>
+  </form>
+  <form id="get">
+    <label>id <input name="id" size="30"></label>
+    <label>as_of <input name="as_of" placeholder="2024-01-02T15:04:05Z"></label>
+    <button>Get</button>
+  </form>
+  <p class="meta" id="status"></p>
+  <pre id="output"></pre>
+  <script>
+    async function show(url) {
+      const resp = await fetch(url);
+      const text = await resp.text();
+      document.getElementById("status").textContent = "GET " + url + " → " + resp.status;
+      try { document.getElementById("output").textContent = JSON.stringify(JSON.parse(text), null, 2); }
+      catch (e) { document.getElementById("output").textContent = text; }
+    }
+    function params(form, skip) {
+      const query = new URLSearchParams();
+      for (const [name, value] of new FormData(form)) {
+        if (value && name !== skip) query.set(name, value);
+      }
+      return query.toString();
+    }
+    document.getElementById("list").addEventListener("submit", (e) => {
+      e.preventDefault();
+      show("/documents?" + params(e.target));
+    });
+    document.getElementById("get").addEventListener("submit", (e) => {
+      e.preventDefault();
+      const id = encodeURIComponent(e.target.id.value);
+      const query = params(e.target, "id");
+      show("/documents/" + id + (query ? "?" + query : ""));
+    });
+  </script>
+</body>
+</html>`
@@ -0,0 +1,15 @@
+package admin
+
+import "net/http"
+
+// handleDedup reports, per source, how many documents were checked against
+// the seen index and skipped as already saved since startup, and the size
+// of the index.
+func (s *Server) handleDedup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, s.seen.Stats())
+}
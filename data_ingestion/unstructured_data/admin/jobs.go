@@ -0,0 +1,58 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// handleJobs lists processing jobs in one status, dead by default:
+// /jobs?status=dead&limit=100
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = "dead"
+	}
+	limit := 100
+	if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 1000 {
+		limit = n
+	}
+
+	jobs, err := s.storage.ListJobs(r.Context(), status, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"status": status, "count": len(jobs), "jobs": jobs})
+}
+
+// handleJob requeues a dead job: POST /jobs/{id}/requeue
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/jobs/"), "/")
+	if id == "" || action != "requeue" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requeued, err := s.storage.RequeueJob(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !requeued {
+		http.Error(w, "job is not dead", http.StatusConflict)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"id": id, "status": "pending"})
+}
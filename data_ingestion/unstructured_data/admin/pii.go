@@ -0,0 +1,17 @@
+package admin
+
+import "net/http"
+
+// handlePII reports, per source, how many documents were checked and
+// scrubbed and how much personal data each detector removed since startup.
+func (s *Server) handlePII(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, map[string]interface{}{
+		"mode":    s.config.PII.Mode,
+		"sources": s.pii.Stats(),
+	})
+}
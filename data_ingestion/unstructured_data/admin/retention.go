@@ -0,0 +1,45 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/retention"
+)
+
+// handleRetention shows the retention policy with the latest purge report,
+// GET /retention, or starts a purge, POST /retention?dry_run=true, which
+// without dry_run follows the configured dry run setting. The purge runs in
+// the background; its report replaces the latest one when it finishes.
+func (s *Server) handleRetention(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, map[string]interface{}{
+			"default_days": s.config.Retention.DefaultDays,
+			"days":         s.config.Retention.Days,
+			"schedule":     s.config.Retention.Schedule,
+			"dry_run":      s.config.Retention.DryRun,
+			"last_report":  s.retention.LastReport(),
+		})
+	case http.MethodPost:
+		dryRun := s.config.Retention.DryRun
+		if value := r.URL.Query().Get("dry_run"); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				http.Error(w, "dry_run must be true or false", http.StatusBadRequest)
+				return
+			}
+			dryRun = parsed
+		}
+		if err := s.retention.Trigger(dryRun); errors.Is(err, retention.ErrRunning) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		writeJSON(w, map[string]interface{}{"started": true, "dry_run": dryRun})
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/search"
+)
+
+// handleSearch runs a full-text query over the search index:
+// /search?q=covenant+breach&source=finnhub&type=news&tag=banking&symbol=JPM&days=30&limit=20&offset=0
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	q := search.Query{
+		Text:    query.Get("q"),
+		Source:  query.Get("source"),
+		Type:    query.Get("type"),
+		Tags:    query["tag"],
+		Symbols: query["symbol"],
+		Size:    20,
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit <= 500 {
+		q.Size = limit
+	}
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset >= 0 && offset <= 10000-q.Size {
+		q.Offset = offset
+	}
+	if days, err := strconv.Atoi(query.Get("days")); err == nil && days > 0 {
+		from := time.Now().AddDate(0, 0, -days)
+		q.DateFrom = &from
+	}
+
+	results, err := s.search.Search(r.Context(), q)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, results)
+}
@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/ingestion"
+)
+
+// handleSources lists the ingestion sources with whether they are running
+// and how often they poll: GET /sources
+func (s *Server) handleSources(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"sources": s.manager.Sources()})
+}
+
+// handleSource changes a source until the service restarts:
+// POST /sources/{name}/enable, /disable, /fetch or /interval?interval=15m
+func (s *Server) handleSource(w http.ResponseWriter, r *http.Request) {
+	name, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/sources/"), "/")
+	if name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var err error
+	status := http.StatusConflict
+	switch action {
+	case "enable":
+		err = s.manager.EnableSource(name)
+	case "disable":
+		err = s.manager.DisableSource(name)
+	case "fetch":
+		err = s.manager.FetchSource(name)
+	case "interval":
+		interval, parseErr := time.ParseDuration(r.FormValue("interval"))
+		if parseErr != nil {
+			http.Error(w, "interval must be a duration such as 15m", http.StatusBadRequest)
+			return
+		}
+		err = s.manager.SetSourceInterval(name, interval)
+		status = http.StatusBadRequest
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if errors.Is(err, ingestion.ErrUnknownSource) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	for _, source := range s.manager.Sources() {
+		if source.Name == name {
+			writeJSON(w, source)
+			return
+		}
+	}
+	writeJSON(w, ingestion.SourceStatus{Name: name})
+}
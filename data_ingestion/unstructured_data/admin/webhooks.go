@@ -0,0 +1,77 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/webhooks"
+)
+
+// handleWebhooks lists the webhook subscriptions, GET /webhooks, or registers
+// one from a JSON body with url, secret, sources, types, tags and symbols,
+// POST /webhooks. Only the registration response shows the secret.
+func (s *Server) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, map[string]interface{}{"subscriptions": s.webhooks.Subscriptions()})
+	case http.MethodPost:
+		var sub webhooks.Subscription
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&sub); err != nil {
+			http.Error(w, "invalid subscription: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		created, err := s.webhooks.Subscribe(sub)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, created)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleWebhook shows or removes a subscription, GET or DELETE
+// /webhooks/{id}, and lists its recent deliveries, GET
+// /webhooks/{id}/deliveries?limit=100
+func (s *Server) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/webhooks/"), "/")
+	if id == "" || (action != "" && action != "deliveries") {
+		http.NotFound(w, r)
+		return
+	}
+	sub, err := s.webhooks.Subscription(id)
+	if errors.Is(err, webhooks.ErrUnknownSubscription) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case action == "deliveries" && r.Method == http.MethodGet:
+		limit := 100
+		if n, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && n > 0 && n <= 1000 {
+			limit = n
+		}
+		deliveries := s.webhooks.Deliveries(id, limit)
+		writeJSON(w, map[string]interface{}{"subscription_id": id, "count": len(deliveries), "deliveries": deliveries})
+	case action == "" && r.Method == http.MethodGet:
+		writeJSON(w, sub)
+	case action == "" && r.Method == http.MethodDelete:
+		if err := s.webhooks.Unsubscribe(id); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case action == "":
+		w.Header().Set("Allow", "GET, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
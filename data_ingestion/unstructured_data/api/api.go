@@ -0,0 +1,211 @@
+// Package api serves stored documents read-only over REST, so dashboards
+// and the scoring service can query them by source, type, symbol, tag and
+// publication window instead of reading the storage's files themselves.
+// Analysts exploring the data query it over GraphQL instead, choosing the
+// fields, entities and sentiment they need in one request.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/tracing"
+)
+
+// Server serves GET /data, /data/search and /data/{id}, /calendar, and
+// /graphql.
+type Server struct {
+	config    config.APIConfig
+	storage   storage.Storage
+	calendars Calendars
+	schema    graphql.Schema
+	server    *http.Server
+}
+
+// ListResponse is a page of documents. NextCursor, set while more
+// documents follow, is passed as cursor to fetch the next page.
+type ListResponse struct {
+	Count      int                        `json:"count"`
+	Limit      int                        `json:"limit"`
+	Offset     int                        `json:"offset,omitempty"`
+	NextCursor string                     `json:"next_cursor,omitempty"`
+	Data       []*models.UnstructuredData `json:"data"`
+}
+
+func NewServer(cfg config.APIConfig, store storage.Storage, calendars Calendars) *Server {
+	s := &Server{config: cfg, storage: store, calendars: calendars}
+	s.schema = s.newSchema()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data", s.requireToken(s.handleList))
+	mux.HandleFunc("/data/", s.requireToken(s.handleData))
+	mux.HandleFunc("/calendar", s.requireToken(s.handleCalendar))
+	if cfg.GraphQL {
+		mux.HandleFunc("/graphql", s.requireToken(s.handleGraphQL))
+	}
+
+	s.server = &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      tracing.Middleware(reporting.Middleware(mux)),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 60 * time.Second,
+	}
+	return s
+}
+
+func (s *Server) Start() error {
+	log.Printf("Starting data API on %s", s.config.Addr)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Data API stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// requireToken checks the bearer token when one is configured.
+func (s *Server) requireToken(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.config.Token != "" {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="data api"`)
+				writeError(w, http.StatusUnauthorized, "unauthorized", "API token required")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// handleList lists documents, newest published first:
+// /data?source=reuters&type=news&symbol=JPM&tag=banking&from=2024-05-01&to=2024-05-31&limit=50
+// The next page is the same request with cursor=<next_cursor>; offset
+// still works but slows down the deeper it goes.
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+	filters, err := s.parseFilters(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_filter", err.Error())
+		return
+	}
+	s.list(w, r, filters)
+}
+
+// handleData serves /data/search and /data/{id}. Search takes the filters
+// of /data and a required q in web search syntax:
+// /data/search?q="covenant+waiver"+-rumour&symbol=JPM&days=30
+// A document may be read as it was stored at an earlier time with
+// ?as_of=<RFC 3339>.
+func (s *Server) handleData(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/data/")
+	switch {
+	case id == "":
+		s.handleList(w, r)
+		return
+	case id == "search":
+		filters, err := s.parseFilters(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_filter", err.Error())
+			return
+		}
+		if strings.TrimSpace(filters.Query) == "" {
+			writeError(w, http.StatusBadRequest, "invalid_filter", "q is required")
+			return
+		}
+		s.list(w, r, filters)
+		return
+	case strings.Contains(id, "/"):
+		writeError(w, http.StatusNotFound, "not_found", "no such endpoint")
+		return
+	}
+
+	var doc *models.UnstructuredData
+	var err error
+	if asOf := r.URL.Query().Get("as_of"); asOf != "" {
+		at, perr := time.Parse(time.RFC3339, asOf)
+		if perr != nil {
+			writeError(w, http.StatusBadRequest, "invalid_filter", "as_of must be an RFC 3339 timestamp")
+			return
+		}
+		doc, err = s.storage.GetUnstructuredDataAsOf(r.Context(), id, at)
+	} else {
+		doc, err = s.storage.GetUnstructuredData(r.Context(), id)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "not_found", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Data API failed to load %s: %v", id, err)
+		writeError(w, http.StatusInternalServerError, "storage_error", "failed to load the document")
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, filters storage.DataFilters) {
+	// One past the page tells whether another follows
+	limit := filters.Limit
+	filters.Limit++
+	docs, err := s.storage.ListUnstructuredData(r.Context(), filters)
+	if err != nil {
+		log.Printf("Data API failed to list documents: %v", err)
+		writeError(w, http.StatusInternalServerError, "storage_error", "failed to list documents")
+		return
+	}
+	resp := ListResponse{Limit: limit, Offset: filters.Offset, Data: docs}
+	if len(docs) > limit {
+		resp.Data = docs[:limit]
+		resp.NextCursor = storage.CursorOf(docs[limit-1]).String()
+	}
+	if resp.Data == nil {
+		resp.Data = []*models.UnstructuredData{}
+	}
+	resp.Count = len(resp.Data)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method+" is not supported")
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers in the shape reporting.Middleware uses for panics.
+func writeError(w http.ResponseWriter, code int, errCode, message string) {
+	writeJSON(w, code, map[string]string{"code": errCode, "message": message})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/ingestion"
+)
+
+// Calendars finds the economic calendar source, which the manager replaces
+// when its settings change.
+type Calendars interface {
+	EconomicCalendar() *ingestion.EconomicCalendarSource
+}
+
+// CalendarResponse is where a moment stands relative to scheduled major
+// releases. Anomalies in prices, volumes or sentiment inside their windows
+// are expected, and alerts on them are to be held back.
+type CalendarResponse struct {
+	At                    time.Time                 `json:"at"`
+	Flags                 ingestion.PreEventFlags   `json:"flags"`
+	SuppressAnomalyAlerts bool                      `json:"suppress_anomaly_alerts"`
+	Upcoming              []ingestion.EconomicEvent `json:"upcoming"`
+}
+
+// handleCalendar serves the release windows around now, or around
+// ?at=<RFC 3339>, and the events scheduled from then on:
+// /calendar?at=2024-06-12T12:00:00Z
+func (s *Server) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodHead) {
+		return
+	}
+	var calendar *ingestion.EconomicCalendarSource
+	if s.calendars != nil {
+		calendar = s.calendars.EconomicCalendar()
+	}
+	if calendar == nil {
+		writeError(w, http.StatusNotFound, "not_configured", "the economic calendar source is not configured")
+		return
+	}
+
+	at := time.Now().UTC()
+	if value := r.URL.Query().Get("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_filter", "at must be an RFC 3339 timestamp")
+			return
+		}
+		at = parsed
+	}
+
+	resp := CalendarResponse{
+		At:                    at,
+		Flags:                 calendar.PreEventFlags(at),
+		SuppressAnomalyAlerts: calendar.SuppressAnomalyAlerts(at),
+		Upcoming:              calendar.UpcomingEvents(at),
+	}
+	if resp.Upcoming == nil {
+		resp.Upcoming = []ingestion.EconomicEvent{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// parseFilters reads the list filters of a request. cursor continues from
+// the next_cursor of the previous page. symbol and tag may be
+// repeated or comma-separated and match documents with any of them. from
+// and to take RFC 3339 timestamps or dates, to covering its whole day;
+// days is a window ending now, used when from is not given.
+func (s *Server) parseFilters(r *http.Request) (storage.DataFilters, error) {
+	query := r.URL.Query()
+	filters := storage.DataFilters{
+		Source:      query.Get("source"),
+		Type:        query.Get("type"),
+		Symbols:     listParam(query["symbol"]),
+		Tags:        listParam(query["tag"]),
+		CompanyID:   query.Get("company"),
+		ClusterID:   query.Get("cluster"),
+		Query:       query.Get("q"),
+		Deduplicate: query.Get("dedup") == "true",
+		Limit:       s.config.DefaultLimit,
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > s.config.MaxLimit {
+			return filters, fmt.Errorf("limit must be between 1 and %d", s.config.MaxLimit)
+		}
+		filters.Limit = limit
+	}
+	if v := query.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			return filters, fmt.Errorf("offset must be a non-negative number")
+		}
+		filters.Offset = offset
+	}
+	if v := query.Get("cursor"); v != "" {
+		if filters.Offset > 0 {
+			return filters, fmt.Errorf("cursor and offset cannot be combined")
+		}
+		after, err := storage.ParseCursor(v)
+		if err != nil {
+			return filters, err
+		}
+		filters.After = after
+	}
+	if v := query.Get("from"); v != "" {
+		from, err := parseTime(v, false)
+		if err != nil {
+			return filters, fmt.Errorf("from: %w", err)
+		}
+		filters.DateFrom = &from
+	} else if v := query.Get("days"); v != "" {
+		days, err := strconv.Atoi(v)
+		if err != nil || days < 1 {
+			return filters, fmt.Errorf("days must be a positive number")
+		}
+		from := time.Now().AddDate(0, 0, -days)
+		filters.DateFrom = &from
+	}
+	if v := query.Get("to"); v != "" {
+		to, err := parseTime(v, true)
+		if err != nil {
+			return filters, fmt.Errorf("to: %w", err)
+		}
+		filters.DateTo = &to
+	}
+	if filters.DateFrom != nil && filters.DateTo != nil && filters.DateTo.Before(*filters.DateFrom) {
+		return filters, fmt.Errorf("to is before from")
+	}
+	return filters, nil
+}
+
+// parseTime reads an RFC 3339 timestamp or a UTC date, which as the end of
+// a range stands for the last moment of that day.
+func parseTime(value string, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 timestamp nor a date", value)
+	}
+	if end {
+		return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return day, nil
+}
+
+// listParam splits repeated and comma-separated values.
+func listParam(values []string) []string {
+	var list []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
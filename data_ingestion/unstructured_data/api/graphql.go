@@ -0,0 +1,436 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// maxQueryBytes bounds the body of a GraphQL request.
+const maxQueryBytes = 64 << 10
+
+// graphQLRequest is a GraphQL request as POSTed in JSON, or as the query,
+// operationName and variables parameters of a GET.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// handleGraphQL answers GraphQL queries over documents and quality stats:
+//
+//	{ documents(symbols: ["AAPL"], tags: ["negative_sentiment"], days: 7) {
+//	    nodes { title publishedAt sentiment { overall } entities(type: "ORG") { name } }
+//	    hasNextPage } }
+//
+// Errors of the query itself are answered in the errors of a 200 response,
+// as GraphQL clients expect.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if !allowMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	var req graphQLRequest
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxQueryBytes)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request", "body must be a JSON GraphQL request")
+			return
+		}
+	} else {
+		query := r.URL.Query()
+		req.Query, req.OperationName = query.Get("query"), query.Get("operationName")
+		if v := query.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				writeError(w, http.StatusBadRequest, "invalid_request", "variables must be a JSON object")
+				return
+			}
+		}
+	}
+	if strings.TrimSpace(req.Query) == "" {
+		writeError(w, http.StatusBadRequest, "invalid_request", "query is required")
+		return
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:         s.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        r.Context(),
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// documentPage is a page of documents and whether another follows it,
+// which documents(after: endCursor) returns.
+type documentPage struct {
+	Nodes       []*models.UnstructuredData
+	Offset      int
+	Limit       int
+	HasNextPage bool
+	EndCursor   string
+}
+
+// newSchema builds the GraphQL schema. Fields resolve to the struct field
+// of the same name; documents resolve their symbols, entities, companies
+// and sentiment aspects only when a query selects them.
+func (s *Server) newSchema() graphql.Schema {
+	jsonScalar := graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "JSON",
+		Description: "Any JSON value, as document metadata holds.",
+		Serialize:   func(value interface{}) interface{} { return value },
+		ParseValue:  func(value interface{}) interface{} { return value },
+		ParseLiteral: func(value ast.Value) interface{} {
+			return value.GetValue()
+		},
+	})
+
+	entityType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Entity",
+		Fields: graphql.Fields{
+			"name":       &graphql.Field{Type: graphql.String},
+			"type":       &graphql.Field{Type: graphql.String, Description: "PERSON, ORG, MONEY, DATE, ..."},
+			"confidence": &graphql.Field{Type: graphql.Float},
+			"startPos":   &graphql.Field{Type: graphql.Int},
+			"endPos":     &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	aspectType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SentimentAspect",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.String},
+			"score": &graphql.Field{Type: graphql.Float},
+		},
+	})
+
+	sentimentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Sentiment",
+		Fields: graphql.Fields{
+			"overall":   &graphql.Field{Type: graphql.Float, Description: "-1 to 1"},
+			"positive":  &graphql.Field{Type: graphql.Float},
+			"negative":  &graphql.Field{Type: graphql.Float},
+			"neutral":   &graphql.Field{Type: graphql.Float},
+			"magnitude": &graphql.Field{Type: graphql.Float},
+			"model":     &graphql.Field{Type: graphql.String, Description: "What produced the score; empty when the source supplied it"},
+			"aspects": &graphql.Field{
+				Type: graphql.NewList(aspectType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					score, _ := p.Source.(*models.SentimentScore)
+					if score == nil {
+						return nil, nil
+					}
+					aspects := make([]map[string]interface{}, 0, len(score.Aspects))
+					for name, value := range score.Aspects {
+						aspects = append(aspects, map[string]interface{}{"name": name, "score": value})
+					}
+					sort.Slice(aspects, func(i, j int) bool { return aspects[i]["name"].(string) < aspects[j]["name"].(string) })
+					return aspects, nil
+				},
+			},
+		},
+	})
+
+	companyType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Company",
+		Fields: graphql.Fields{
+			"id":      &graphql.Field{Type: graphql.ID},
+			"name":    &graphql.Field{Type: graphql.String},
+			"ticker":  &graphql.Field{Type: graphql.String},
+			"cik":     &graphql.Field{Type: graphql.String},
+			"lei":     &graphql.Field{Type: graphql.String},
+			"aliases": &graphql.Field{Type: graphql.NewList(graphql.String)},
+		},
+	})
+
+	documentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Document",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"source":      &graphql.Field{Type: graphql.String},
+			"type":        &graphql.Field{Type: graphql.String},
+			"title":       &graphql.Field{Type: graphql.String},
+			"content":     &graphql.Field{Type: graphql.String},
+			"url":         &graphql.Field{Type: graphql.String},
+			"author":      &graphql.Field{Type: graphql.String},
+			"summary":     &graphql.Field{Type: graphql.String},
+			"publishedAt": &graphql.Field{Type: graphql.DateTime},
+			"ingestedAt":  &graphql.Field{Type: graphql.DateTime},
+			"processedAt": &graphql.Field{Type: graphql.DateTime},
+			"tags":        &graphql.Field{Type: graphql.NewList(graphql.String)},
+			"metadata":    &graphql.Field{Type: jsonScalar},
+			"symbols": &graphql.Field{
+				Type:        graphql.NewList(graphql.String),
+				Description: "Symbols the document names: its primary symbol, the one it was fetched for and those it mentions.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if doc, ok := p.Source.(*models.UnstructuredData); ok && doc != nil {
+						return doc.Symbols(), nil
+					}
+					return nil, nil
+				},
+			},
+			"sentiment": &graphql.Field{Type: sentimentType},
+			"entities": &graphql.Field{
+				Type:        graphql.NewList(entityType),
+				Description: "Entities extracted from the document, optionally of one type and above a confidence.",
+				Args: graphql.FieldConfigArgument{
+					"type":          &graphql.ArgumentConfig{Type: graphql.String},
+					"minConfidence": &graphql.ArgumentConfig{Type: graphql.Float},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					doc, _ := p.Source.(*models.UnstructuredData)
+					if doc == nil {
+						return nil, nil
+					}
+					kind, _ := p.Args["type"].(string)
+					minConfidence, _ := p.Args["minConfidence"].(float64)
+					entities := make([]models.Entity, 0, len(doc.Entities))
+					for _, e := range doc.Entities {
+						if (kind == "" || strings.EqualFold(e.Type, kind)) && e.Confidence >= minConfidence {
+							entities = append(entities, e)
+						}
+					}
+					return entities, nil
+				},
+			},
+			"companies": &graphql.Field{
+				Type:        graphql.NewList(companyType),
+				Description: "Companies the document was resolved to.",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					doc, _ := p.Source.(*models.UnstructuredData)
+					if doc == nil {
+						return nil, nil
+					}
+					var companies []*models.Company
+					for _, id := range metadataStrings(doc.Metadata["company_ids"]) {
+						company, err := s.storage.GetCompany(p.Context, id)
+						if err != nil {
+							log.Printf("GraphQL failed to load company %s of %s: %v", id, doc.ID, err)
+							continue
+						}
+						companies = append(companies, company)
+					}
+					return companies, nil
+				},
+			},
+		},
+	})
+
+	pageType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "DocumentPage",
+		Fields: graphql.Fields{
+			"nodes":       &graphql.Field{Type: graphql.NewList(documentType)},
+			"offset":      &graphql.Field{Type: graphql.Int},
+			"limit":       &graphql.Field{Type: graphql.Int},
+			"hasNextPage": &graphql.Field{Type: graphql.Boolean},
+			"endCursor":   &graphql.Field{Type: graphql.String, Description: "Cursor of the last node while another page follows"},
+		},
+	})
+
+	qualityType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "QualityStats",
+		Fields: graphql.Fields{
+			"averageQuality":      &graphql.Field{Type: graphql.Float},
+			"averageCompleteness": &graphql.Field{Type: graphql.Float},
+			"averageAccuracy":     &graphql.Field{Type: graphql.Float},
+			"averageFreshness":    &graphql.Field{Type: graphql.Float},
+			"totalItems":          &graphql.Field{Type: graphql.Int},
+			"issueCount":          &graphql.Field{Type: graphql.Int},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"documents": &graphql.Field{
+				Type:        pageType,
+				Description: "Documents newest published first. from and to take RFC 3339 timestamps or dates; days is a window ending now.",
+				Args: graphql.FieldConfigArgument{
+					"source":  &graphql.ArgumentConfig{Type: graphql.String},
+					"type":    &graphql.ArgumentConfig{Type: graphql.String},
+					"symbols": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String)},
+					"tags":    &graphql.ArgumentConfig{Type: graphql.NewList(graphql.String)},
+					"from":    &graphql.ArgumentConfig{Type: graphql.String},
+					"to":      &graphql.ArgumentConfig{Type: graphql.String},
+					"days":    &graphql.ArgumentConfig{Type: graphql.Int},
+					"company": &graphql.ArgumentConfig{Type: graphql.String},
+					"cluster": &graphql.ArgumentConfig{Type: graphql.String},
+					"dedup":   &graphql.ArgumentConfig{Type: graphql.Boolean},
+					"query":   &graphql.ArgumentConfig{Type: graphql.String, Description: "Web search syntax, as /data/search takes"},
+					"first":   &graphql.ArgumentConfig{Type: graphql.Int},
+					"offset":  &graphql.ArgumentConfig{Type: graphql.Int},
+					"after":   &graphql.ArgumentConfig{Type: graphql.String, Description: "endCursor of the previous page, in place of offset"},
+				},
+				Resolve: s.resolveDocuments,
+			},
+			"document": &graphql.Field{
+				Type: documentType,
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"asOf": &graphql.ArgumentConfig{Type: graphql.DateTime, Description: "The document as it was stored at this time"},
+				},
+				Resolve: s.resolveDocument,
+			},
+			"qualityStats": &graphql.Field{
+				Type:        qualityType,
+				Description: "Quality checks of a source's documents, or of all of them, over the last days (default 7).",
+				Args: graphql.FieldConfigArgument{
+					"source": &graphql.ArgumentConfig{Type: graphql.String},
+					"days":   &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					source, _ := p.Args["source"].(string)
+					days := 7
+					if v, ok := p.Args["days"].(int); ok {
+						if v < 1 {
+							return nil, errors.New("days must be positive")
+						}
+						days = v
+					}
+					stats, err := s.storage.GetDataQualityStats(p.Context, source, time.Now().AddDate(0, 0, -days))
+					if err != nil {
+						log.Printf("GraphQL failed to load quality stats: %v", err)
+						return nil, errors.New("failed to load quality stats")
+					}
+					return stats, nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{Query: query})
+	if err != nil {
+		// The schema is fixed; it fails to build only through a change here
+		panic(fmt.Sprintf("api: invalid GraphQL schema: %v", err))
+	}
+	return schema
+}
+
+func (s *Server) resolveDocuments(p graphql.ResolveParams) (interface{}, error) {
+	filters := storage.DataFilters{Limit: s.config.DefaultLimit}
+	filters.Source, _ = p.Args["source"].(string)
+	filters.Type, _ = p.Args["type"].(string)
+	filters.Symbols = stringArgs(p.Args["symbols"])
+	filters.Tags = stringArgs(p.Args["tags"])
+	filters.CompanyID, _ = p.Args["company"].(string)
+	filters.ClusterID, _ = p.Args["cluster"].(string)
+	filters.Deduplicate, _ = p.Args["dedup"].(bool)
+	filters.Query, _ = p.Args["query"].(string)
+
+	if first, ok := p.Args["first"].(int); ok {
+		if first < 1 || first > s.config.MaxLimit {
+			return nil, fmt.Errorf("first must be between 1 and %d", s.config.MaxLimit)
+		}
+		filters.Limit = first
+	}
+	if offset, ok := p.Args["offset"].(int); ok {
+		if offset < 0 {
+			return nil, errors.New("offset must not be negative")
+		}
+		filters.Offset = offset
+	}
+	if v, ok := p.Args["after"].(string); ok {
+		if filters.Offset > 0 {
+			return nil, errors.New("after and offset cannot be combined")
+		}
+		after, err := storage.ParseCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		filters.After = after
+	}
+	if v, ok := p.Args["from"].(string); ok {
+		from, err := parseTime(v, false)
+		if err != nil {
+			return nil, fmt.Errorf("from: %w", err)
+		}
+		filters.DateFrom = &from
+	} else if days, ok := p.Args["days"].(int); ok {
+		if days < 1 {
+			return nil, errors.New("days must be positive")
+		}
+		from := time.Now().AddDate(0, 0, -days)
+		filters.DateFrom = &from
+	}
+	if v, ok := p.Args["to"].(string); ok {
+		to, err := parseTime(v, true)
+		if err != nil {
+			return nil, fmt.Errorf("to: %w", err)
+		}
+		filters.DateTo = &to
+	}
+
+	// One past the page tells whether another follows
+	limit := filters.Limit
+	filters.Limit++
+	docs, err := s.storage.ListUnstructuredData(p.Context, filters)
+	if err != nil {
+		log.Printf("GraphQL failed to list documents: %v", err)
+		return nil, errors.New("failed to list documents")
+	}
+	page := &documentPage{Nodes: docs, Offset: filters.Offset, Limit: limit}
+	if len(docs) > limit {
+		page.Nodes, page.HasNextPage = docs[:limit], true
+		page.EndCursor = storage.CursorOf(docs[limit-1]).String()
+	}
+	return page, nil
+}
+
+func (s *Server) resolveDocument(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(string)
+	var doc *models.UnstructuredData
+	var err error
+	if asOf, ok := p.Args["asOf"].(time.Time); ok {
+		doc, err = s.storage.GetUnstructuredDataAsOf(p.Context, id, asOf)
+	} else {
+		doc, err = s.storage.GetUnstructuredData(p.Context, id)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		log.Printf("GraphQL failed to load %s: %v", id, err)
+		return nil, errors.New("failed to load the document")
+	}
+	return doc, nil
+}
+
+// stringArgs reads a list argument, trimming and dropping empty values.
+func stringArgs(value interface{}) []string {
+	list, _ := value.([]interface{})
+	var out []string
+	for _, v := range list {
+		if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+			out = append(out, strings.TrimSpace(s))
+		}
+	}
+	return out
+}
+
+// metadataStrings reads a string list from metadata, which is []interface{}
+// once it has been through JSON.
+func metadataStrings(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		var out []string
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,568 @@
+// Package backfill rebuilds historical news sentiment from archived GDELT 2.0
+// Global Knowledge Graph files. Each 15-minute GKG file is scanned for articles
+// mentioning watchlist issuers; matches are stored as news records and queued
+// for the same enrichment jobs live ingestion uses. Progress is checkpointed
+// after every file so a multi-year run can be stopped and resumed.
+package backfill
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const DefaultMasterListURL = "http://data.gdeltproject.org/gdeltv2/masterfilelist.txt"
+
+// GKG 2.1 column positions (tab-delimited, no header).
+const (
+	gkgRecordID      = 0
+	gkgDate          = 1
+	gkgSourceName    = 3
+	gkgDocumentID    = 4
+	gkgV2Themes      = 8
+	gkgOrganizations = 13
+	gkgV2Tone        = 15
+	gkgExtras        = 26
+	gkgColumns       = 27
+)
+
+// enrichmentJobs are queued for every backfilled record, mirroring live ingestion.
+var enrichmentJobs = []string{"sentiment_analysis", "entity_extraction", "credit_event_classification", "summarization", "quality_check"}
+
+// backfillPriority keeps archive jobs behind live ones in the shared queue.
+const backfillPriority = -1
+
+var pageTitlePattern = regexp.MustCompile(`<PAGE_TITLE>(.*?)</PAGE_TITLE>`)
+
+// Issuer is a watchlist company and the organization names GDELT may use for it.
+type Issuer struct {
+	Symbol  string
+	Names   []string
+	matches []string
+}
+
+// Options configures a backfill run over [From, To).
+type Options struct {
+	From           time.Time
+	To             time.Time
+	Issuers        []Issuer
+	CheckpointFile string
+	MasterListURL  string
+	Every          int  // process every Nth 15-minute file; 1 processes all of them
+	Reset          bool // start over when the checkpoint was written for other options
+}
+
+// Checkpoint records progress so an interrupted run resumes after the last
+// finished file. It keeps the options that chose the files, since progress
+// through one range and list of issuers says nothing about another.
+type Checkpoint struct {
+	From          time.Time           `json:"from"`
+	To            time.Time           `json:"to"`
+	Every         int                 `json:"every"`
+	Issuers       map[string][]string `json:"issuers"` // names by symbol
+	LastFile      string              `json:"last_file"`
+	LastTimestamp time.Time           `json:"last_timestamp"`
+	FilesDone     int                 `json:"files_done"`
+	FilesMissing  int                 `json:"files_missing"` // listed but not in the archive, skipped
+	Records       int                 `json:"records"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// statusError is a non-200 answer from the archive.
+type statusError struct {
+	url  string
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.url, e.code)
+}
+
+// missing reports whether err says the archive does not have the file, which
+// no rerun will change.
+func missing(err error) bool {
+	var status *statusError
+	return errors.As(err, &status) && (status.code == http.StatusNotFound || status.code == http.StatusGone)
+}
+
+type Runner struct {
+	storage    storage.Storage
+	client     *http.Client
+	opts       Options
+	checkpoint Checkpoint
+}
+
+func NewRunner(store storage.Storage, opts Options) (*Runner, error) {
+	if opts.MasterListURL == "" {
+		opts.MasterListURL = DefaultMasterListURL
+	}
+	if opts.Every < 1 {
+		opts.Every = 1
+	}
+	if !opts.From.Before(opts.To) {
+		return nil, fmt.Errorf("backfill range is empty: %s to %s", opts.From, opts.To)
+	}
+	if len(opts.Issuers) == 0 {
+		return nil, fmt.Errorf("no issuers to backfill")
+	}
+	opts.Issuers = CompileIssuers(opts.Issuers)
+
+	r := &Runner{
+		storage: store,
+		client:  &http.Client{Timeout: 2 * time.Minute},
+		opts:    opts,
+	}
+	if err := r.loadCheckpoint(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// LoadIssuers reads a CSV of "symbol,name[,alias...]" rows.
+func LoadIssuers(path string) ([]Issuer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open issuers file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse issuers file: %w", err)
+	}
+
+	var issuers []Issuer
+	for _, row := range rows {
+		if len(row) < 2 {
+			continue
+		}
+		issuer := Issuer{Symbol: strings.ToUpper(strings.TrimSpace(row[0]))}
+		for _, name := range row[1:] {
+			if name = strings.TrimSpace(name); name != "" {
+				issuer.Names = append(issuer.Names, name)
+			}
+		}
+		issuers = append(issuers, issuer)
+	}
+	return issuers, nil
+}
+
+// Run processes every archive file in range that the checkpoint has not covered yet.
+func (r *Runner) Run(ctx context.Context) error {
+	files, err := r.listFiles(ctx)
+	if err != nil {
+		return err
+	}
+	log.Printf("GDELT backfill: %d files between %s and %s, resuming after %s",
+		len(files), r.opts.From.Format(time.RFC3339), r.opts.To.Format(time.RFC3339), r.checkpoint.LastTimestamp.Format(time.RFC3339))
+
+	for i, file := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		records, err := r.processFile(ctx, file.url)
+		switch {
+		case missing(err):
+			// The master list names some files GDELT never published
+			log.Printf("GDELT backfill: skipping missing file: %v", err)
+			r.checkpoint.FilesMissing++
+		case err != nil:
+			// Leave the checkpoint before this file so a rerun retries it
+			return fmt.Errorf("failed to process %s: %w", file.url, err)
+		default:
+			r.checkpoint.FilesDone++
+			r.checkpoint.Records += records
+		}
+
+		r.checkpoint.LastFile = file.url
+		r.checkpoint.LastTimestamp = file.timestamp
+		if err := r.saveCheckpoint(); err != nil {
+			return err
+		}
+
+		if (i+1)%50 == 0 || i == len(files)-1 {
+			log.Printf("GDELT backfill: %d/%d files, %d records so far (at %s)",
+				i+1, len(files), r.checkpoint.Records, file.timestamp.Format("2006-01-02 15:04"))
+		}
+	}
+	return nil
+}
+
+type archiveFile struct {
+	url       string
+	timestamp time.Time
+}
+
+// listFiles reads the master file list and keeps GKG files inside the range and after the checkpoint.
+func (r *Runner) listFiles(ctx context.Context) ([]archiveFile, error) {
+	body, err := r.get(ctx, r.opts.MasterListURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch master file list: %w", err)
+	}
+
+	var files []archiveFile
+	seen := 0
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		// Each line is "<size> <md5> <url>"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || !strings.HasSuffix(fields[2], ".gkg.csv.zip") {
+			continue
+		}
+		url := fields[2]
+		name := url[strings.LastIndex(url, "/")+1:]
+		timestamp, err := time.Parse("20060102150405", strings.TrimSuffix(name, ".gkg.csv.zip"))
+		if err != nil || timestamp.Before(r.opts.From) || !timestamp.Before(r.opts.To) {
+			continue
+		}
+
+		seen++
+		if (seen-1)%r.opts.Every != 0 || !timestamp.After(r.checkpoint.LastTimestamp) {
+			continue
+		}
+		files = append(files, archiveFile{url: url, timestamp: timestamp})
+	}
+	return files, scanner.Err()
+}
+
+// processFile downloads one zipped GKG file and stores the articles that mention an issuer.
+func (r *Runner) processFile(ctx context.Context, url string) (int, error) {
+	body, err := r.get(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	records := 0
+	err = ReadGKG(body, r.opts.Issuers, func(fields []string, symbols []string) {
+		data, err := buildRecord(fields, symbols)
+		if err != nil {
+			log.Printf("Skipping GKG record %s: %v", fields[gkgRecordID], err)
+			return
+		}
+		if err := r.storage.SaveUnstructuredData(ctx, data); err != nil {
+			log.Printf("Failed to save GKG record %s: %v", fields[gkgRecordID], err)
+			return
+		}
+		r.enqueueEnrichment(ctx, data.ID)
+		records++
+	})
+	return records, err
+}
+
+// ReadGKG calls handle with every row of a zipped GKG 2.1 file that mentions
+// one of issuers, which must come from CompileIssuers.
+func ReadGKG(body []byte, issuers []Issuer, handle func(fields []string, symbols []string)) error {
+	return ReadArchive(body, gkgColumns, func(fields []string) {
+		if symbols := MatchIssuers(issuers, fields[gkgOrganizations]); len(symbols) > 0 {
+			handle(fields, symbols)
+		}
+	})
+}
+
+// ReadArchive calls handle with the fields of every record in a zipped,
+// tab-delimited GDELT file, skipping rows with fewer than columns fields.
+func ReadArchive(body []byte, columns int, handle func(fields []string)) error {
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+
+	for _, entry := range archive.File {
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", entry.Name, err)
+		}
+		err = readRows(rc, columns, handle)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readRows(reader io.Reader, columns int, handle func(fields []string)) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < columns {
+			continue
+		}
+		handle(fields)
+	}
+	return scanner.Err()
+}
+
+// CompileIssuers prepares issuers for MatchIssuers by normalizing their names.
+func CompileIssuers(issuers []Issuer) []Issuer {
+	compiled := make([]Issuer, len(issuers))
+	for i, issuer := range issuers {
+		compiled[i] = Issuer{Symbol: issuer.Symbol, Names: issuer.Names}
+		for _, name := range issuer.Names {
+			if normalized := normalizeOrg(name); normalized != "" {
+				compiled[i].matches = append(compiled[i].matches, normalized)
+			}
+		}
+	}
+	return compiled
+}
+
+// MatchIssuers returns the symbols whose names appear in a GDELT organizations
+// field ("name;name;..."). Issuers must come from CompileIssuers.
+func MatchIssuers(issuers []Issuer, organizations string) []string {
+	if organizations == "" {
+		return nil
+	}
+	orgs := make(map[string]bool)
+	for _, org := range strings.Split(organizations, ";") {
+		orgs[normalizeOrg(org)] = true
+	}
+
+	var symbols []string
+	for _, issuer := range issuers {
+		for _, name := range issuer.matches {
+			if orgs[name] {
+				symbols = append(symbols, issuer.Symbol)
+				break
+			}
+		}
+	}
+	return symbols
+}
+
+func buildRecord(fields []string, symbols []string) (*models.UnstructuredData, error) {
+	data, err := GKGRecord(fields, symbols)
+	if err != nil {
+		return nil, err
+	}
+	data.Tags = append([]string{"backfill"}, data.Tags...)
+	data.Metadata["backfill"] = true
+	return data, nil
+}
+
+// GKGRecord converts a GKG 2.1 row mentioning symbols into a news record.
+func GKGRecord(fields []string, symbols []string) (*models.UnstructuredData, error) {
+	publishedAt, err := time.Parse("20060102150405", fields[gkgDate])
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", fields[gkgDate])
+	}
+
+	url := fields[gkgDocumentID]
+	hash := md5.Sum([]byte(url))
+
+	title := url
+	if match := pageTitlePattern.FindStringSubmatch(fields[gkgExtras]); match != nil {
+		title = strings.TrimSpace(match[1])
+	}
+
+	tone := ParseTone(fields[gkgV2Tone])
+
+	tags := []string{"gdelt"}
+	for _, symbol := range symbols {
+		tags = append(tags, strings.ToLower(symbol))
+	}
+
+	var entities []models.Entity
+	for _, symbol := range symbols {
+		entities = append(entities, models.Entity{Name: symbol, Type: "ORG", Confidence: 1})
+	}
+
+	return &models.UnstructuredData{
+		ID:          fmt.Sprintf("gdelt-%x", hash[:8]),
+		Source:      "gdelt",
+		Type:        "news",
+		Title:       title,
+		URL:         url,
+		Author:      fields[gkgSourceName],
+		PublishedAt: publishedAt,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"symbols":          symbols,
+			"gkg_record_id":    fields[gkgRecordID],
+			"gdelt_tone":       tone,
+			"gdelt_themes":     themes(fields[gkgV2Themes]),
+			"source_publisher": fields[gkgSourceName],
+		},
+		Tags:     tags,
+		Entities: entities,
+	}, nil
+}
+
+// ParseTone reads the V2Tone field: tone, positive score, negative score, polarity,
+// activity density, self/group density, word count.
+func ParseTone(field string) map[string]float64 {
+	names := []string{"tone", "positive", "negative", "polarity", "activity_density", "self_group_density", "word_count"}
+	tone := make(map[string]float64)
+	for i, part := range strings.Split(field, ",") {
+		if i >= len(names) {
+			break
+		}
+		if value, err := strconv.ParseFloat(part, 64); err == nil {
+			tone[names[i]] = value
+		}
+	}
+	return tone
+}
+
+// themes returns the distinct theme codes from a V2Themes field ("THEME,offset;THEME,offset;...").
+func themes(field string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, entry := range strings.Split(field, ";") {
+		theme, _, _ := strings.Cut(entry, ",")
+		if theme != "" && !seen[theme] {
+			seen[theme] = true
+			result = append(result, theme)
+		}
+	}
+	return result
+}
+
+func (r *Runner) enqueueEnrichment(ctx context.Context, dataID string) {
+	for _, jobType := range enrichmentJobs {
+		hash := md5.Sum([]byte(dataID + jobType))
+		job := &models.ProcessingJob{
+			ID:        fmt.Sprintf("job-%x", hash[:8]),
+			DataID:    dataID,
+			JobType:   jobType,
+			Status:    "pending",
+			CreatedAt: time.Now(),
+			Priority:  backfillPriority,
+		}
+		if err := r.storage.SaveProcessingJob(ctx, job); err != nil {
+			log.Printf("Failed to queue %s for %s: %v", jobType, dataID, err)
+		}
+	}
+}
+
+// normalizeOrg lower-cases a company name and drops punctuation and legal suffixes
+// so "Apple Inc." and GDELT's "apple inc" compare equal.
+func normalizeOrg(name string) string {
+	name = strings.ToLower(name)
+	name = strings.Map(func(r rune) rune {
+		if r == '.' || r == ',' || r == '\'' {
+			return -1
+		}
+		return r
+	}, name)
+
+	words := strings.Fields(name)
+	for len(words) > 1 {
+		switch words[len(words)-1] {
+		case "inc", "corp", "corporation", "co", "&", "company", "plc", "ltd", "llc", "sa", "ag", "nv", "group", "holdings":
+			words = words[:len(words)-1]
+			continue
+		}
+		break
+	}
+	return strings.Join(words, " ")
+}
+
+func (r *Runner) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{url: url, code: resp.StatusCode}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// loadCheckpoint resumes from the checkpoint file if there is one written
+// for the same options. One written for others is refused, or with Reset
+// replaced.
+func (r *Runner) loadCheckpoint() error {
+	fresh := Checkpoint{From: r.opts.From, To: r.opts.To, Every: r.opts.Every, Issuers: make(map[string][]string)}
+	for _, issuer := range r.opts.Issuers {
+		fresh.Issuers[issuer.Symbol] = append(fresh.Issuers[issuer.Symbol], issuer.Names...)
+	}
+	r.checkpoint = fresh
+	if r.opts.CheckpointFile == "" {
+		return nil
+	}
+
+	raw, err := os.ReadFile(r.opts.CheckpointFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var saved Checkpoint
+	if err := json.Unmarshal(raw, &saved); err != nil {
+		return fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+
+	var differs string
+	switch {
+	case !saved.From.Equal(fresh.From):
+		differs = "from"
+	case !saved.To.Equal(fresh.To):
+		differs = "to"
+	case saved.Every != fresh.Every:
+		differs = "every"
+	case !reflect.DeepEqual(saved.Issuers, fresh.Issuers):
+		differs = "issuers"
+	default:
+		r.checkpoint = saved
+		return nil
+	}
+	if !r.opts.Reset {
+		return fmt.Errorf("checkpoint %s was written for other options (%s differs); rerun with its options or reset it", r.opts.CheckpointFile, differs)
+	}
+	log.Printf("GDELT backfill: checkpoint was written for other options (%s differs), starting over", differs)
+	return nil
+}
+
+func (r *Runner) saveCheckpoint() error {
+	if r.opts.CheckpointFile == "" {
+		return nil
+	}
+	r.checkpoint.UpdatedAt = time.Now()
+	raw, err := json.MarshalIndent(r.checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.opts.CheckpointFile), 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	tmp := r.opts.CheckpointFile + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return os.Rename(tmp, r.opts.CheckpointFile)
+}
@@ -0,0 +1,138 @@
+// Command jobs inspects and requeues dead processing jobs through the admin
+// API of a running ingestion service.
+//
+//	go run ./cmd/jobs -token $ADMIN_TOKEN list
+//	go run ./cmd/jobs -token $ADMIN_TOKEN requeue job-0123456789abcdef
+//	go run ./cmd/jobs -token $ADMIN_TOKEN requeue-all
+//
+// -addr and -token default to ADMIN_ADDR and ADMIN_TOKEN.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+var client = &http.Client{Timeout: 30 * time.Second}
+
+func main() {
+	addr := flag.String("addr", envOr("ADMIN_ADDR", "127.0.0.1:8091"), "admin API address")
+	token := flag.String("token", os.Getenv("ADMIN_TOKEN"), "admin API token")
+	status := flag.String("status", "dead", "job status to list")
+	limit := flag.Int("limit", 100, "most jobs to list")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: jobs [flags] list | requeue <job id>... | requeue-all\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *token == "" || flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	api := &apiClient{base: "http://" + *addr, token: *token}
+
+	switch flag.Arg(0) {
+	case "list":
+		jobs, err := api.list(*status, *limit)
+		if err != nil {
+			log.Fatalf("Failed to list jobs: %v", err)
+		}
+		printJobs(jobs)
+	case "requeue":
+		if flag.NArg() < 2 {
+			flag.Usage()
+			os.Exit(2)
+		}
+		for _, id := range flag.Args()[1:] {
+			if err := api.requeue(id); err != nil {
+				log.Fatalf("Failed to requeue %s: %v", id, err)
+			}
+			fmt.Printf("Requeued %s\n", id)
+		}
+	case "requeue-all":
+		jobs, err := api.list("dead", 1000)
+		if err != nil {
+			log.Fatalf("Failed to list jobs: %v", err)
+		}
+		for _, job := range jobs {
+			if err := api.requeue(job.ID); err != nil {
+				log.Fatalf("Failed to requeue %s: %v", job.ID, err)
+			}
+		}
+		fmt.Printf("Requeued %d dead jobs\n", len(jobs))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+type apiClient struct {
+	base  string
+	token string
+}
+
+func (c *apiClient) do(method, path string, out interface{}) error {
+	req, err := http.NewRequest(method, c.base+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned %d: %s", method, path, resp.StatusCode, body)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *apiClient) list(status string, limit int) ([]*models.ProcessingJob, error) {
+	var page struct {
+		Jobs []*models.ProcessingJob `json:"jobs"`
+	}
+	query := url.Values{"status": {status}, "limit": {strconv.Itoa(limit)}}
+	if err := c.do(http.MethodGet, "/jobs?"+query.Encode(), &page); err != nil {
+		return nil, err
+	}
+	return page.Jobs, nil
+}
+
+func (c *apiClient) requeue(id string) error {
+	return c.do(http.MethodPost, "/jobs/"+url.PathEscape(id)+"/requeue", nil)
+}
+
+func printJobs(jobs []*models.ProcessingJob) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tDATA\tATTEMPTS\tCREATED\tERROR")
+	for _, job := range jobs {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", job.ID, job.JobType, job.DataID, job.RetryCount, job.CreatedAt.Format(time.RFC3339), job.Error)
+	}
+	w.Flush()
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
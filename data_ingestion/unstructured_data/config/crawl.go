@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultCrawlInterval is used for CONFIG_FILE sites without an update_interval.
+const defaultCrawlInterval = 10 * time.Minute
+
+// fileCrawlSite is a CrawlSiteConfig as written in CONFIG_FILE. Enabled defaults to true.
+type fileCrawlSite struct {
+	Name           string     `json:"name"`
+	Source         string     `json:"source"`
+	StartURLs      []string   `json:"start_urls"`
+	Follow         string     `json:"follow"`
+	Article        string     `json:"article"`
+	MaxDepth       int        `json:"max_depth"`
+	Rules          CrawlRules `json:"rules"`
+	Author         string     `json:"author"`
+	Tags           []string   `json:"tags"`
+	Enabled        *bool      `json:"enabled"`
+	UpdateInterval Duration   `json:"update_interval"`
+}
+
+// applyCrawlSites adds the sites defined in the config file, replacing a
+// default site of the same name, and then applies <NAME>_ENABLED to every site.
+func (c *Config) applyCrawlSites(sites []fileCrawlSite) error {
+	builtin := c.sourceIntervals()
+
+	for _, s := range sites {
+		if s.Name == "" {
+			return fmt.Errorf("crawl site without a name")
+		}
+		if _, ok := builtin[s.Name]; ok {
+			return fmt.Errorf("crawl site %q clashes with a built-in source", s.Name)
+		}
+		if c.hasRSSFeed(s.Name) {
+			return fmt.Errorf("crawl site %q clashes with an RSS feed", s.Name)
+		}
+		if len(s.StartURLs) == 0 {
+			return fmt.Errorf("crawl site %q has no start_urls", s.Name)
+		}
+
+		site := CrawlSiteConfig{
+			Name:           s.Name,
+			Source:         s.Source,
+			StartURLs:      s.StartURLs,
+			Follow:         s.Follow,
+			Article:        s.Article,
+			MaxDepth:       s.MaxDepth,
+			Rules:          s.Rules,
+			Author:         s.Author,
+			Tags:           s.Tags,
+			Enabled:        s.Enabled == nil || *s.Enabled,
+			UpdateInterval: time.Duration(s.UpdateInterval),
+		}
+		if site.UpdateInterval == 0 {
+			site.UpdateInterval = defaultCrawlInterval
+		}
+		if site.MaxDepth == 0 {
+			site.MaxDepth = 1
+		}
+		if len(site.Tags) == 0 {
+			site.Tags = []string{s.Name}
+		}
+
+		replaced := false
+		for i := range c.DataSources.CrawlSites {
+			if c.DataSources.CrawlSites[i].Name == site.Name {
+				c.DataSources.CrawlSites[i] = site
+				replaced = true
+			}
+		}
+		if !replaced {
+			c.DataSources.CrawlSites = append(c.DataSources.CrawlSites, site)
+		}
+	}
+
+	for i := range c.DataSources.CrawlSites {
+		site := &c.DataSources.CrawlSites[i]
+		if value := os.Getenv(strings.ToUpper(site.Name) + "_ENABLED"); value != "" {
+			site.Enabled = value == "true"
+		}
+	}
+	return nil
+}
+
+func (c *Config) hasCrawlSite(name string) bool {
+	for _, site := range c.DataSources.CrawlSites {
+		if site.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCrawlSites checks the patterns of every site.
+func (c *Config) validateCrawlSites() []error {
+	var errs []error
+	for _, site := range c.DataSources.CrawlSites {
+		for field, pattern := range map[string]string{"follow": site.Follow, "article": site.Article} {
+			if _, err := regexp.Compile(pattern); err != nil {
+				errs = append(errs, fmt.Errorf("crawl site %s %s pattern: %w", site.Name, field, err))
+			}
+		}
+	}
+	return errs
+}
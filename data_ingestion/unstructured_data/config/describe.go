@@ -0,0 +1,77 @@
+package config
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+// Describe returns the effective configuration as a JSON-friendly tree with
+// durations written as "5m0s" style strings and secrets redacted. A field
+// tagged `secret:"true"` is a secret, and so is everything in it.
+func (c *Config) Describe() map[string]interface{} {
+	return describeValue(reflect.ValueOf(*c), false).(map[string]interface{})
+}
+
+func describeValue(v reflect.Value, secret bool) interface{} {
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(v.Int()).String()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		out := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.IsExported() {
+				out[field.Name] = describeValue(v.Field(i), secret || field.Tag.Get("secret") == "true")
+			}
+		}
+		return out
+	case reflect.Slice:
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = describeValue(v.Index(i), secret)
+		}
+		return out
+	case reflect.Map:
+		out := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = describeValue(iter.Value(), secret)
+		}
+		return out
+	case reflect.String:
+		return describeString(v.String(), secret)
+	}
+	return v.Interface()
+}
+
+// describeString redacts secrets and passwords embedded in URLs.
+func describeString(value string, secret bool) string {
+	if value == "" {
+		return value
+	}
+	if secret {
+		return redacted
+	}
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return value
+	}
+	query := u.Query()
+	for key := range query {
+		lower := strings.ToLower(key)
+		if strings.Contains(lower, "key") || strings.Contains(lower, "token") || strings.Contains(lower, "secret") {
+			query.Set(key, redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.Redacted()
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// secretName matches the field names that hold credentials.
+var secretName = regexp.MustCompile(`Key$|Token$|Secret|Password|DSN|Headers|Webhook.*URL`)
+
+// fill sets every string field under v, and an entry of every string map and
+// slice of structs, to a value naming its path, and returns those values by
+// whether they sit under a field tagged secret.
+func fill(t *testing.T, v reflect.Value, path string, secret bool, values map[string]bool) {
+	t.Helper()
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			tagged := field.Tag.Get("secret") == "true"
+			if secretName.MatchString(field.Name) && field.Type.Kind() != reflect.Int && !tagged {
+				t.Errorf("%s.%s looks like a secret but is not tagged secret", path, field.Name)
+			}
+			fill(t, v.Field(i), path+"."+field.Name, secret || tagged, values)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Struct && v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(t, v.Index(0), path+"[0]", secret, values)
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		value := "value" + path
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(reflect.ValueOf("key"), reflect.ValueOf(value))
+		values[value] = secret
+	case reflect.String:
+		value := "value" + path
+		v.SetString(value)
+		values[value] = secret
+	}
+}
+
+func TestDescribeRedactsSecrets(t *testing.T) {
+	var cfg Config
+	values := make(map[string]bool)
+	fill(t, reflect.ValueOf(&cfg).Elem(), "Config", false, values)
+
+	raw, err := json.Marshal(cfg.Describe())
+	if err != nil {
+		t.Fatal(err)
+	}
+	described := string(raw)
+	for value, secret := range values {
+		shown := strings.Contains(described, `"`+value+`"`)
+		switch {
+		case secret && shown:
+			t.Errorf("secret %s is shown", strings.TrimPrefix(value, "value"))
+		case !secret && !shown:
+			t.Errorf("%s is not shown", strings.TrimPrefix(value, "value"))
+		}
+	}
+}
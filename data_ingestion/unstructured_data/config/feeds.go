@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultRSSFeedInterval is used for CONFIG_FILE feeds without an update_interval.
+const defaultRSSFeedInterval = 5 * time.Minute
+
+// fileRSSFeed is an RSSFeedConfig as written in CONFIG_FILE. Enabled defaults to true.
+type fileRSSFeed struct {
+	Name            string   `json:"name"`
+	Source          string   `json:"source"`
+	URLs            []string `json:"urls"`
+	Author          string   `json:"author"`
+	Tags            []string `json:"tags"`
+	Enabled         *bool    `json:"enabled"`
+	UpdateInterval  Duration `json:"update_interval"`
+	ExtractFullText bool     `json:"extract_full_text"`
+}
+
+// applyRSSFeeds adds the feeds defined in the config file, replacing a default
+// feed of the same name, and then applies <NAME>_ENABLED to every feed.
+func (c *Config) applyRSSFeeds(feeds []fileRSSFeed) error {
+	builtin := c.sourceIntervals()
+
+	for _, f := range feeds {
+		if f.Name == "" {
+			return fmt.Errorf("RSS feed without a name")
+		}
+		if _, ok := builtin[f.Name]; ok {
+			return fmt.Errorf("RSS feed %q clashes with a built-in source", f.Name)
+		}
+		if c.hasCrawlSite(f.Name) {
+			return fmt.Errorf("RSS feed %q clashes with a crawl site", f.Name)
+		}
+		if len(f.URLs) == 0 {
+			return fmt.Errorf("RSS feed %q has no urls", f.Name)
+		}
+
+		feed := RSSFeedConfig{
+			Name:            f.Name,
+			Source:          f.Source,
+			URLs:            f.URLs,
+			Author:          f.Author,
+			Tags:            f.Tags,
+			Enabled:         f.Enabled == nil || *f.Enabled,
+			UpdateInterval:  time.Duration(f.UpdateInterval),
+			ExtractFullText: f.ExtractFullText,
+		}
+		if feed.UpdateInterval == 0 {
+			feed.UpdateInterval = defaultRSSFeedInterval
+		}
+		if len(feed.Tags) == 0 {
+			feed.Tags = []string{f.Name}
+		}
+
+		replaced := false
+		for i := range c.DataSources.RSSFeeds {
+			if c.DataSources.RSSFeeds[i].Name == feed.Name {
+				c.DataSources.RSSFeeds[i] = feed
+				replaced = true
+			}
+		}
+		if !replaced {
+			c.DataSources.RSSFeeds = append(c.DataSources.RSSFeeds, feed)
+		}
+	}
+
+	for i := range c.DataSources.RSSFeeds {
+		feed := &c.DataSources.RSSFeeds[i]
+		if value := os.Getenv(strings.ToUpper(feed.Name) + "_ENABLED"); value != "" {
+			feed.Enabled = value == "true"
+		}
+	}
+	return nil
+}
+
+func (c *Config) hasRSSFeed(name string) bool {
+	for _, feed := range c.DataSources.RSSFeeds {
+		if feed.Name == name {
+			return true
+		}
+	}
+	return false
+}
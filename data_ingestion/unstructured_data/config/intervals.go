@@ -0,0 +1,432 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/cron"
+)
+
+// Duration is a time.Duration that reads "90s", "5m", "1h" style strings from JSON.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// MinIntervals is the shortest polling interval each source may be configured
+// with, chosen to stay inside provider rate limits and terms of use.
+var MinIntervals = map[string]time.Duration{
+	"finnhub":              15 * time.Second,
+	"reuters":              time.Minute,
+	"yahoo":                time.Minute,
+	"newsapi":              10 * time.Minute,
+	"marketwatch":          time.Minute,
+	"bloomberg":            time.Minute,
+	"fednews":              5 * time.Minute,
+	"federal_reserve":      5 * time.Minute,
+	"centralbanks":         5 * time.Minute,
+	"economic_calendar":    15 * time.Minute,
+	"index_membership":     time.Hour,
+	"stocktwits":           2 * time.Minute,
+	"earnings_transcripts": time.Hour,
+	"press_releases":       time.Minute,
+	"gdelt":                15 * time.Minute,
+	"court_filings":        30 * time.Minute,
+	"employee_reviews":     time.Hour,
+	"google_trends":        time.Hour,
+}
+
+// MinRSSFeedInterval is the minimum for RSS feeds without a MinIntervals entry.
+const MinRSSFeedInterval = time.Minute
+
+// MinCrawlSiteInterval is the minimum for crawl sites without a MinIntervals entry.
+const MinCrawlSiteInterval = 5 * time.Minute
+
+// fileConfig is the optional JSON overlay read from CONFIG_FILE, e.g.
+// {"intervals": {"reuters": "90s", "newsapi": "30m"},
+// "schedules": {"yahoo": "CRON_TZ=US */2 9-16 * * 1-5"},
+// "sources": {"newsapi": {"enabled": true, "keywords": ["default", "downgrade"]}},
+// "rss_feeds": [{"name": "ft", "urls": ["https://www.ft.com/markets?format=rss"], "tags": ["ft"]}],
+// "crawl_sites": [{"name": "kofin", "start_urls": ["https://kofin.com/market-news"], "rules": {"body": "div.article-body"}}]}
+// A file named .yaml or .yml holds the same in YAML.
+type fileConfig struct {
+	Intervals  map[string]Duration   `json:"intervals"`
+	Schedules  map[string]string     `json:"schedules"`
+	Sources    map[string]fileSource `json:"sources"`
+	RSSFeeds   []fileRSSFeed         `json:"rss_feeds"`
+	CrawlSites []fileCrawlSite       `json:"crawl_sites"`
+}
+
+// applyFile reads CONFIG_FILE, if set, and overlays its RSS feeds and crawl
+// sites, then the settings of its sources and then the polling intervals and
+// schedules of every source.
+func (c *Config) applyFile(path string) error {
+	var file fileConfig
+	if path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading config file: %w", err)
+		}
+		if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+			if raw, err = yamlToJSON(raw); err != nil {
+				return fmt.Errorf("parsing config file %s: %w", path, err)
+			}
+		}
+		if err := json.Unmarshal(raw, &file); err != nil {
+			return fmt.Errorf("parsing config file %s: %w", path, err)
+		}
+	}
+
+	if err := c.applyRSSFeeds(file.RSSFeeds); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	if err := c.applyCrawlSites(file.CrawlSites); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	if err := c.applySources(file.Sources); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	if err := c.applyIntervals(path, file.Intervals); err != nil {
+		return err
+	}
+	return c.applySchedules(path, file.Schedules)
+}
+
+// intervals maps source names, as registered by the ingestion manager, to
+// their polling interval fields.
+func (c *Config) intervals() map[string]*time.Duration {
+	fields := c.sourceIntervals()
+	for i := range c.DataSources.RSSFeeds {
+		feed := &c.DataSources.RSSFeeds[i]
+		fields[feed.Name] = &feed.UpdateInterval
+	}
+	for i := range c.DataSources.CrawlSites {
+		site := &c.DataSources.CrawlSites[i]
+		fields[site.Name] = &site.UpdateInterval
+	}
+	return fields
+}
+
+// sourceIntervals is intervals without the RSS feeds and crawl sites.
+func (c *Config) sourceIntervals() map[string]*time.Duration {
+	ds := &c.DataSources
+	return map[string]*time.Duration{
+		"finnhub":              &ds.Finnhub.UpdateInterval,
+		"yahoo":                &ds.Yahoo.UpdateInterval,
+		"newsapi":              &ds.NewsAPI.UpdateInterval,
+		"fednews":              &ds.FedNews.UpdateInterval,
+		"centralbanks":         &ds.CentralBanks.UpdateInterval,
+		"economic_calendar":    &ds.EconomicCalendar.UpdateInterval,
+		"index_membership":     &ds.IndexMembership.UpdateInterval,
+		"stocktwits":           &ds.StockTwits.UpdateInterval,
+		"earnings_transcripts": &ds.EarningsTranscripts.UpdateInterval,
+		"press_releases":       &ds.PressReleases.UpdateInterval,
+		"gdelt":                &ds.GDELT.UpdateInterval,
+		"court_filings":        &ds.CourtFilings.UpdateInterval,
+		"employee_reviews":     &ds.EmployeeReviews.UpdateInterval,
+		"google_trends":        &ds.GoogleTrends.UpdateInterval,
+	}
+}
+
+// minInterval is the shortest polling interval the named source may use.
+func (c *Config) minInterval(name string) time.Duration {
+	if min, ok := MinIntervals[name]; ok {
+		return min
+	}
+	if c.hasCrawlSite(name) {
+		return MinCrawlSiteInterval
+	}
+	return MinRSSFeedInterval
+}
+
+// schedules maps source names, as intervals does, to their cron schedules.
+// A source with a schedule polls at its times instead of every interval.
+func (c *Config) schedules() map[string]*string {
+	ds := &c.DataSources
+	fields := map[string]*string{
+		"finnhub":              &ds.Finnhub.Schedule,
+		"yahoo":                &ds.Yahoo.Schedule,
+		"newsapi":              &ds.NewsAPI.Schedule,
+		"fednews":              &ds.FedNews.Schedule,
+		"centralbanks":         &ds.CentralBanks.Schedule,
+		"economic_calendar":    &ds.EconomicCalendar.Schedule,
+		"index_membership":     &ds.IndexMembership.Schedule,
+		"stocktwits":           &ds.StockTwits.Schedule,
+		"earnings_transcripts": &ds.EarningsTranscripts.Schedule,
+		"press_releases":       &ds.PressReleases.Schedule,
+		"gdelt":                &ds.GDELT.Schedule,
+		"court_filings":        &ds.CourtFilings.Schedule,
+		"employee_reviews":     &ds.EmployeeReviews.Schedule,
+		"google_trends":        &ds.GoogleTrends.Schedule,
+	}
+	for i := range ds.RSSFeeds {
+		fields[ds.RSSFeeds[i].Name] = &ds.RSSFeeds[i].Schedule
+	}
+	for i := range ds.CrawlSites {
+		fields[ds.CrawlSites[i].Name] = &ds.CrawlSites[i].Schedule
+	}
+	return fields
+}
+
+// applySchedules overlays cron schedules from the config file and then from
+// <SOURCE>_SCHEDULE environment variables, e.g.
+// YAHOO_SCHEDULE="CRON_TZ=US */2 9-16 * * 1-5".
+func (c *Config) applySchedules(path string, schedules map[string]string) error {
+	fields := c.schedules()
+	for name, schedule := range schedules {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("config file %s: unknown source %q", path, name)
+		}
+		*field = schedule
+	}
+	for name, field := range fields {
+		if value := os.Getenv(strings.ToUpper(name) + "_SCHEDULE"); value != "" {
+			*field = value
+		}
+	}
+	return nil
+}
+
+// applyIntervals overlays intervals from the config file and then from
+// <SOURCE>_UPDATE_INTERVAL environment variables, e.g. REUTERS_UPDATE_INTERVAL=90s.
+func (c *Config) applyIntervals(path string, intervals map[string]Duration) error {
+	fields := c.intervals()
+
+	for name, interval := range intervals {
+		field, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("config file %s: unknown source %q", path, name)
+		}
+		*field = time.Duration(interval)
+	}
+
+	var errs []error
+	for name, field := range fields {
+		key := strings.ToUpper(name) + "_UPDATE_INTERVAL"
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		*field = d
+	}
+	return errors.Join(errs...)
+}
+
+// Validate rejects polling intervals below each source's minimum.
+func (c *Config) Validate() error {
+	var errs []error
+	for name, field := range c.intervals() {
+		if min := c.minInterval(name); *field < min {
+			errs = append(errs, fmt.Errorf("%s update interval %v is below the minimum of %v", name, *field, min))
+		}
+	}
+	for name, field := range c.schedules() {
+		if *field == "" {
+			continue
+		}
+		if _, err := cron.Parse(*field); err != nil {
+			errs = append(errs, fmt.Errorf("%s schedule: %w", name, err))
+		}
+	}
+	errs = append(errs, c.validateCrawlSites()...)
+	if c.Admin.Token != "" && len(c.Admin.Token) < 16 {
+		errs = append(errs, errors.New("admin token must be at least 16 characters"))
+	}
+	if c.API.Enabled {
+		if c.API.Token != "" && len(c.API.Token) < 16 {
+			errs = append(errs, errors.New("API token must be at least 16 characters"))
+		}
+		if c.API.DefaultLimit < 1 || c.API.MaxLimit < c.API.DefaultLimit {
+			errs = append(errs, errors.New("API default limit must be positive and the maximum no less than it"))
+		}
+	}
+	if c.GRPC.Enabled {
+		if c.GRPC.Token != "" && len(c.GRPC.Token) < 16 {
+			errs = append(errs, errors.New("gRPC token must be at least 16 characters"))
+		}
+		if c.GRPC.DefaultLimit < 1 || c.GRPC.MaxLimit < c.GRPC.DefaultLimit {
+			errs = append(errs, errors.New("gRPC default limit must be positive and the maximum no less than it"))
+		}
+		if c.GRPC.BatchSize < 1 {
+			errs = append(errs, errors.New("gRPC batch size must be at least 1"))
+		}
+	}
+	if fh := c.DataSources.Finnhub; fh.Enabled {
+		if fh.WebSocketMinBackoff <= 0 || fh.WebSocketMaxBackoff < fh.WebSocketMinBackoff {
+			errs = append(errs, errors.New("Finnhub WebSocket backoff must be positive and the maximum no less than it"))
+		}
+		if fh.WebSocketPingInterval <= 0 || fh.WebSocketStaleTimeout <= fh.WebSocketPingInterval {
+			errs = append(errs, errors.New("Finnhub WebSocket ping interval must be positive and the stale timeout longer"))
+		}
+	}
+	if c.SourceHealth.Enabled {
+		if c.SourceHealth.FailureThreshold < 1 || c.SourceHealth.Window < 1 {
+			errs = append(errs, errors.New("source health failure threshold and window must be at least 1"))
+		}
+		if c.SourceHealth.EmptyThreshold < 0 || c.SourceHealth.ParseErrorRate < 0 || c.SourceHealth.ParseErrorRate > 1 {
+			errs = append(errs, errors.New("source health empty threshold must not be negative and parse error rate must be between 0 and 1"))
+		}
+		if c.SourceHealth.ProbeInterval <= 0 || c.SourceHealth.MaxProbeInterval < c.SourceHealth.ProbeInterval {
+			errs = append(errs, errors.New("source health probe interval must be positive and the maximum no less than it"))
+		}
+		if c.SourceHealth.WebhookURL != "" && c.SourceHealth.WebhookTimeout <= 0 {
+			errs = append(errs, errors.New("source health webhook timeout must be positive"))
+		}
+	}
+	if c.Processing.ProcessTimeout <= 0 {
+		errs = append(errs, errors.New("processing timeout must be positive"))
+	}
+	if c.Processing.PollInterval <= 0 {
+		errs = append(errs, errors.New("job poll interval must be positive"))
+	}
+	if c.Processing.MaxAttempts < 1 {
+		errs = append(errs, errors.New("job max attempts must be at least 1"))
+	}
+	if c.Processing.RetryBaseDelay <= 0 || c.Processing.RetryMaxDelay < c.Processing.RetryBaseDelay {
+		errs = append(errs, errors.New("job retry delays must be positive, the maximum no less than the base"))
+	}
+	switch c.Sentiment.Backend {
+	case "lexicon":
+	case "http":
+		if c.Sentiment.URL == "" {
+			errs = append(errs, errors.New("sentiment service URL is required for the http backend"))
+		}
+		if c.Sentiment.Timeout <= 0 || c.Sentiment.BatchSize < 1 || c.Sentiment.MaxChars < 1 {
+			errs = append(errs, errors.New("sentiment service timeout, batch size and max chars must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown sentiment backend %q", c.Sentiment.Backend))
+	}
+	if c.RateLimit.DefaultQPS <= 0 || c.RateLimit.MaxRetries < 0 {
+		errs = append(errs, errors.New("rate limit default QPS must be positive and max retries not negative"))
+	}
+	for source, qps := range c.RateLimit.SourceQPS {
+		if qps <= 0 {
+			errs = append(errs, fmt.Errorf("rate limit QPS of %s must be positive", source))
+		}
+	}
+	if c.Dedup.Enabled && (c.Dedup.MaxDistance < 0 || c.Dedup.MaxDistance > 15 || c.Dedup.Window <= 0) {
+		errs = append(errs, errors.New("dedup max distance must be between 0 and 15 and the window positive"))
+	}
+	if c.Dedup.Seen.Enabled && (c.Dedup.Seen.Window <= 0 || c.Dedup.Seen.Capacity < 1 || c.Dedup.Seen.SaveInterval <= 0 ||
+		c.Dedup.Seen.FalsePositiveRate <= 0 || c.Dedup.Seen.FalsePositiveRate >= 1) {
+		errs = append(errs, errors.New("seen window, capacity and save interval must be positive and the false positive rate between 0 and 1"))
+	}
+	switch c.Publish.Backend {
+	case "":
+	case "kafka", "nats", "rabbitmq":
+		if c.Publish.BatchSize < 1 || c.Publish.BufferSize < 1 || c.Publish.FlushInterval <= 0 || c.Publish.Timeout <= 0 {
+			errs = append(errs, errors.New("publish batch size, buffer size, flush interval and timeout must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown publish backend %q", c.Publish.Backend))
+	}
+	switch c.Database.Type {
+	case "postgres", "file", "memory":
+	default:
+		errs = append(errs, fmt.Errorf("unknown database type %q", c.Database.Type))
+	}
+	if c.Database.WriteBatchSize < 0 || (c.Database.WriteBatchSize > 0 &&
+		(c.Database.WriteFlushInterval <= 0 || (c.Database.WriteMode != "copy" && c.Database.WriteMode != "insert"))) {
+		errs = append(errs, errors.New(`database write batch size must not be negative, and batching needs a positive flush interval and write mode "copy" or "insert"`))
+	}
+	if c.Webhooks.Enabled && (c.Webhooks.Workers < 1 || c.Webhooks.MaxAttempts < 1 || c.Webhooks.RetryDelay <= 0 ||
+		c.Webhooks.Timeout <= 0 || c.Webhooks.QueueSize < 1 || c.Webhooks.History < 1) {
+		errs = append(errs, errors.New("webhook workers, attempts, retry delay, timeout, queue size and history must be positive"))
+	}
+	if c.DataSources.IndexMembership.Enabled && len(c.DataSources.IndexMembership.Indices) > 0 && c.DataSources.IndexMembership.ConstituentsURL == "" {
+		errs = append(errs, errors.New("index membership needs the constituents URL of the market data service"))
+	}
+	if c.Search.Enabled && (c.Search.URL == "" || c.Search.Index == "" || c.Search.BatchSize < 1 ||
+		c.Search.FlushInterval <= 0 || c.Search.QueueSize < 1 || c.Search.Timeout <= 0) {
+		errs = append(errs, errors.New("search URL and index are required and batch size, flush interval, queue size and timeout must be positive"))
+	}
+	if c.Retention.Enabled {
+		if _, err := cron.Parse(c.Retention.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("retention schedule: %w", err))
+		}
+		if c.Retention.DefaultDays < 0 || c.Retention.BatchSize < 1 || c.Retention.ArchiveDir == "" {
+			errs = append(errs, errors.New("retention default days must not be negative, batch size must be positive and an archive directory is required"))
+		}
+		for key, days := range c.Retention.Days {
+			if days < 0 {
+				errs = append(errs, fmt.Errorf("retention days of %s must not be negative", key))
+			}
+		}
+		if c.Retention.S3.Bucket != "" && (c.Retention.S3.AccessKey == "" || c.Retention.S3.SecretKey == "") {
+			errs = append(errs, errors.New("retention S3 archive needs an access key and secret key"))
+		}
+	}
+	if c.Tracing.Endpoint != "" && (c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 || c.Tracing.BatchSize < 1 ||
+		c.Tracing.FlushInterval <= 0 || c.Tracing.QueueSize < 1 || c.Tracing.Timeout <= 0) {
+		errs = append(errs, errors.New("tracing sample ratio must be between 0 and 1 and batch size, flush interval, queue size and timeout positive"))
+	}
+	if c.Sharing.Enabled && c.Sharing.Epsilon > 0 && len(c.Sharing.NoiseSecret) < 16 {
+		errs = append(errs, errors.New("sharing noise needs a noise secret of at least 16 characters"))
+	}
+	if c.PII.Enabled {
+		switch c.PII.Mode {
+		case "redact":
+		case "hash":
+			if len(c.PII.HashSecret) < 16 {
+				errs = append(errs, errors.New("PII hash mode needs a hash secret of at least 16 characters"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown PII scrub mode %q", c.PII.Mode))
+		}
+	}
+	if c.Reload.Interval < 0 {
+		errs = append(errs, errors.New("config reload interval must not be negative"))
+	}
+	if c.Entities.SyncInterval <= 0 {
+		errs = append(errs, errors.New("company sync interval must be positive"))
+	}
+	if c.Summarization.URL != "" && (c.Summarization.MaxTokens < 1 || c.Summarization.Timeout <= 0 ||
+		c.Summarization.MaxChars < 1 || c.Summarization.RequestsPerMinute < 1) {
+		errs = append(errs, errors.New("summarization max tokens, timeout, max chars and requests per minute must be positive"))
+	}
+	switch c.CreditEvents.Backend {
+	case "rules":
+	case "http":
+		if c.CreditEvents.URL == "" {
+			errs = append(errs, errors.New("credit event service URL is required for the http backend"))
+		}
+		if c.CreditEvents.Timeout <= 0 || c.CreditEvents.MaxChars < 1 {
+			errs = append(errs, errors.New("credit event service timeout and max chars must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("unknown credit event backend %q", c.CreditEvents.Backend))
+	}
+	if c.CreditEvents.MinConfidence < 0 || c.CreditEvents.MinConfidence > 1 {
+		errs = append(errs, errors.New("credit event min confidence must be between 0 and 1"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
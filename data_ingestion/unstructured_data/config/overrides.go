@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// SourceNames returns the names of the sources the configuration describes,
+// enabled or not, sorted.
+func (c *Config) SourceNames() []string {
+	fields := c.intervals()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Interval returns the polling interval of the named source.
+func (c *Config) Interval(name string) (time.Duration, bool) {
+	field, ok := c.intervals()[name]
+	if !ok {
+		return 0, false
+	}
+	return *field, true
+}
+
+// Schedule returns the cron schedule of the named source, empty if it polls
+// every interval.
+func (c *Config) Schedule(name string) string {
+	if field, ok := c.schedules()[name]; ok {
+		return *field
+	}
+	return ""
+}
+
+// Enabled reports whether the named source is switched on.
+func (c *Config) Enabled(name string) (bool, bool) {
+	flag, ok := c.enabledFlags()[name]
+	if !ok {
+		return false, false
+	}
+	return *flag, true
+}
+
+// WithEnabled returns a copy of c with the named source switched on or off.
+func (c *Config) WithEnabled(name string, enabled bool) (*Config, error) {
+	next := c.clone()
+	field, ok := next.enabledFlags()[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q", name)
+	}
+	*field = enabled
+	return next, nil
+}
+
+// WithInterval returns a copy of c with the named source polling at
+// interval, which may not be below the source's minimum.
+func (c *Config) WithInterval(name string, interval time.Duration) (*Config, error) {
+	next := c.clone()
+	field, ok := next.intervals()[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q", name)
+	}
+	if min := next.minInterval(name); interval < min {
+		return nil, fmt.Errorf("%s update interval %v is below the minimum of %v", name, interval, min)
+	}
+	*field = interval
+	return next, nil
+}
+
+// clone copies c deeply enough that the source settings of the copy can
+// be changed without touching c.
+func (c *Config) clone() *Config {
+	next := *c
+	next.DataSources.RSSFeeds = slices.Clone(c.DataSources.RSSFeeds)
+	next.DataSources.CrawlSites = slices.Clone(c.DataSources.CrawlSites)
+	return &next
+}
+
+// enabledFlags maps source names, as intervals does, to their Enabled fields.
+func (c *Config) enabledFlags() map[string]*bool {
+	ds := &c.DataSources
+	flags := map[string]*bool{
+		"finnhub":              &ds.Finnhub.Enabled,
+		"yahoo":                &ds.Yahoo.Enabled,
+		"newsapi":              &ds.NewsAPI.Enabled,
+		"fednews":              &ds.FedNews.Enabled,
+		"centralbanks":         &ds.CentralBanks.Enabled,
+		"economic_calendar":    &ds.EconomicCalendar.Enabled,
+		"index_membership":     &ds.IndexMembership.Enabled,
+		"stocktwits":           &ds.StockTwits.Enabled,
+		"earnings_transcripts": &ds.EarningsTranscripts.Enabled,
+		"press_releases":       &ds.PressReleases.Enabled,
+		"gdelt":                &ds.GDELT.Enabled,
+		"court_filings":        &ds.CourtFilings.Enabled,
+		"employee_reviews":     &ds.EmployeeReviews.Enabled,
+		"google_trends":        &ds.GoogleTrends.Enabled,
+	}
+	for i := range ds.RSSFeeds {
+		flags[ds.RSSFeeds[i].Name] = &ds.RSSFeeds[i].Enabled
+	}
+	for i := range ds.CrawlSites {
+		flags[ds.CrawlSites[i].Name] = &ds.CrawlSites[i].Enabled
+	}
+	return flags
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileSource holds the settings of one source in CONFIG_FILE. They take
+// precedence over the environment; symbols apply to the sources polling
+// per symbol and keywords to NewsAPI and to Google Trends' distress terms.
+type fileSource struct {
+	Enabled  *bool    `json:"enabled"`
+	Symbols  []string `json:"symbols"`
+	Keywords []string `json:"keywords"`
+}
+
+// applySources overlays the source settings of the config file.
+func (c *Config) applySources(sources map[string]fileSource) error {
+	flags := c.enabledFlags()
+	symbols := c.symbolLists()
+	keywords := c.keywordLists()
+
+	for name, s := range sources {
+		flag, ok := flags[name]
+		if !ok {
+			return fmt.Errorf("unknown source %q", name)
+		}
+		if s.Enabled != nil {
+			*flag = *s.Enabled
+		}
+		if s.Symbols != nil {
+			list, ok := symbols[name]
+			if !ok {
+				return fmt.Errorf("source %q has no symbols", name)
+			}
+			*list = s.Symbols
+		}
+		if s.Keywords != nil {
+			list, ok := keywords[name]
+			if !ok {
+				return fmt.Errorf("source %q has no keywords", name)
+			}
+			*list = s.Keywords
+		}
+	}
+	return nil
+}
+
+// yamlToJSON converts a YAML document to JSON, so the config file is
+// decoded by one set of struct tags whichever format it is written in.
+func yamlToJSON(raw []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// tunables are the settings of a source that change while the service runs.
+type tunables struct {
+	enabled  bool
+	interval time.Duration
+	schedule string
+	symbols  []string
+	keywords []string
+}
+
+func (c *Config) tunables(name string) (tunables, bool) {
+	flag, ok := c.enabledFlags()[name]
+	if !ok {
+		return tunables{}, false
+	}
+	t := tunables{enabled: *flag}
+	if field, ok := c.intervals()[name]; ok {
+		t.interval = *field
+	}
+	if field, ok := c.schedules()[name]; ok {
+		t.schedule = *field
+	}
+	if list, ok := c.symbolLists()[name]; ok {
+		t.symbols = *list
+	}
+	if list, ok := c.keywordLists()[name]; ok {
+		t.keywords = *list
+	}
+	return t, true
+}
+
+// Retuned returns the sources whose symbols, keywords, interval, schedule or
+// enabled flag differ in next, sorted. Sources only one of the two has are left
+// out: adding or removing a source waits for a restart.
+func (c *Config) Retuned(next *Config) []string {
+	var names []string
+	for name := range c.enabledFlags() {
+		current, _ := c.tunables(name)
+		changed, ok := next.tunables(name)
+		if ok && !equalTunables(current, changed) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Retune returns a copy of c with the symbols, keywords, interval, schedule
+// and enabled flag of the named sources taken from next.
+func (c *Config) Retune(next *Config, names []string) *Config {
+	tuned := c.clone()
+	flags, intervals, schedules := tuned.enabledFlags(), tuned.intervals(), tuned.schedules()
+	symbols, keywords := tuned.symbolLists(), tuned.keywordLists()
+	for _, name := range names {
+		t, ok := next.tunables(name)
+		if _, known := flags[name]; !ok || !known {
+			continue
+		}
+		*flags[name] = t.enabled
+		if field, ok := intervals[name]; ok {
+			*field = t.interval
+		}
+		if field, ok := schedules[name]; ok {
+			*field = t.schedule
+		}
+		if list, ok := symbols[name]; ok {
+			*list = t.symbols
+		}
+		if list, ok := keywords[name]; ok {
+			*list = t.keywords
+		}
+	}
+	return tuned
+}
+
+func equalTunables(a, b tunables) bool {
+	return a.enabled == b.enabled && a.interval == b.interval && a.schedule == b.schedule &&
+		slices.Equal(a.symbols, b.symbols) && slices.Equal(a.keywords, b.keywords)
+}
+
+// symbolLists maps the sources that poll per symbol to their symbol lists.
+func (c *Config) symbolLists() map[string]*[]string {
+	ds := &c.DataSources
+	return map[string]*[]string{
+		"finnhub":              &ds.Finnhub.Symbols,
+		"yahoo":                &ds.Yahoo.Symbols,
+		"stocktwits":           &ds.StockTwits.Symbols,
+		"earnings_transcripts": &ds.EarningsTranscripts.Symbols,
+		"employee_reviews":     &ds.EmployeeReviews.Symbols,
+	}
+}
+
+// keywordLists maps the sources that search by keyword to their keywords.
+func (c *Config) keywordLists() map[string]*[]string {
+	ds := &c.DataSources
+	return map[string]*[]string{
+		"newsapi":       &ds.NewsAPI.Keywords,
+		"google_trends": &ds.GoogleTrends.DistressTerms,
+	}
+}
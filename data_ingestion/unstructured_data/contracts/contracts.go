@@ -0,0 +1,310 @@
+// Package contracts defines the data contracts ingested records must meet
+// before downstream feature extraction and scoring consume them: required
+// fields, value ranges and freshness. Violations are flagged on the record
+// or, for contracts with the reject action, stop the record from being stored.
+package contracts
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const (
+	ActionFlag   = "flag"
+	ActionReject = "reject"
+)
+
+// Duration is a time.Duration that reads "90s", "24h" style strings from JSON.
+type Duration = config.Duration
+
+// RangeRule bounds a numeric field, e.g. sentiment.overall or metadata.priority.
+type RangeRule struct {
+	Field string  `json:"field"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+// Contract is one set of expectations for records of the listed types.
+type Contract struct {
+	Name          string      `json:"name"`
+	Types         []string    `json:"types"`        // empty applies to every record type
+	ExceptTypes   []string    `json:"except_types"` // record types skipped even when Types is empty
+	Required      []string    `json:"required"`
+	Ranges        []RangeRule `json:"ranges"`
+	MaxAge        Duration    `json:"max_age"`         // published_at must be within this age at ingest
+	MaxFutureSkew Duration    `json:"max_future_skew"` // published_at may not be further in the future
+	Action        string      `json:"action"`
+}
+
+// Violation describes one failed check.
+type Violation struct {
+	Contract string `json:"contract"`
+	Field    string `json:"field"`
+	Reason   string `json:"reason"`
+	Action   string `json:"action"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s %s", v.Contract, v.Field, v.Reason)
+}
+
+// Validator checks records against a list of contracts.
+type Validator struct {
+	contracts []Contract
+}
+
+// Defaults are the contracts used when no contracts file is configured.
+func Defaults() []Contract {
+	return []Contract{
+		{
+			Name:     "document",
+			Required: []string{"id", "source", "type", "title", "published_at"},
+			Action:   ActionFlag,
+		},
+		{
+			// Scheduled events are published at the time they take place
+			Name:          "publication",
+			ExceptTypes:   []string{"economic_event", "index_event"},
+			MaxFutureSkew: Duration(time.Hour),
+			Action:        ActionFlag,
+		},
+		{
+			Name: "sentiment",
+			Ranges: []RangeRule{
+				{Field: "sentiment.overall", Min: -1, Max: 1},
+				{Field: "sentiment.positive", Min: 0, Max: 1},
+				{Field: "sentiment.negative", Min: 0, Max: 1},
+				{Field: "sentiment.neutral", Min: 0, Max: 1},
+			},
+			Action: ActionReject,
+		},
+		{
+			Name:     "news",
+			Types:    []string{"news"},
+			Required: []string{"url"},
+			MaxAge:   Duration(30 * 24 * time.Hour),
+			Action:   ActionFlag,
+		},
+		{
+			Name:     "macro_event",
+			Types:    []string{"macro_event"},
+			Required: []string{"metadata.priority"},
+			Action:   ActionFlag,
+		},
+		{
+			Name:     "economic_event",
+			Types:    []string{"economic_event"},
+			Required: []string{"metadata.event", "metadata.country"},
+			Action:   ActionFlag,
+		},
+	}
+}
+
+// Load reads contracts from a JSON file, or returns the defaults when path is empty.
+func Load(path string) (*Validator, error) {
+	if path == "" {
+		return NewValidator(Defaults())
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data contracts: %w", err)
+	}
+	var contracts []Contract
+	if err := json.Unmarshal(raw, &contracts); err != nil {
+		return nil, fmt.Errorf("failed to parse data contracts: %w", err)
+	}
+	return NewValidator(contracts)
+}
+
+// NewValidator checks the contracts themselves and builds a validator.
+func NewValidator(contracts []Contract) (*Validator, error) {
+	for i, c := range contracts {
+		if c.Name == "" {
+			return nil, fmt.Errorf("contract %d has no name", i)
+		}
+		switch c.Action {
+		case "":
+			contracts[i].Action = ActionFlag
+		case ActionFlag, ActionReject:
+		default:
+			return nil, fmt.Errorf("contract %s has unknown action %q", c.Name, c.Action)
+		}
+		for _, r := range c.Ranges {
+			if r.Min > r.Max {
+				return nil, fmt.Errorf("contract %s: range for %s has min > max", c.Name, r.Field)
+			}
+		}
+	}
+	return &Validator{contracts: contracts}, nil
+}
+
+// Validate returns every contract violation for data as of now.
+func (v *Validator) Validate(data *models.UnstructuredData, now time.Time) []Violation {
+	var violations []Violation
+	for _, c := range v.contracts {
+		if !c.appliesTo(data.Type) {
+			continue
+		}
+		add := func(field, reason string) {
+			violations = append(violations, Violation{Contract: c.Name, Field: field, Reason: reason, Action: c.Action})
+		}
+
+		for _, field := range c.Required {
+			if !present(data, field) {
+				add(field, "is required")
+			}
+		}
+		for _, r := range c.Ranges {
+			value, ok := numeric(data, r.Field)
+			if ok && (value < r.Min || value > r.Max) {
+				add(r.Field, fmt.Sprintf("%g outside [%g, %g]", value, r.Min, r.Max))
+			}
+		}
+		if !data.PublishedAt.IsZero() {
+			if c.MaxAge > 0 && now.Sub(data.PublishedAt) > time.Duration(c.MaxAge) {
+				add("published_at", fmt.Sprintf("older than %v", time.Duration(c.MaxAge)))
+			}
+			if c.MaxFutureSkew > 0 && data.PublishedAt.Sub(now) > time.Duration(c.MaxFutureSkew) {
+				add("published_at", fmt.Sprintf("more than %v in the future", time.Duration(c.MaxFutureSkew)))
+			}
+		}
+	}
+	return violations
+}
+
+func (c Contract) appliesTo(recordType string) bool {
+	if slices.Contains(c.ExceptTypes, recordType) {
+		return false
+	}
+	return len(c.Types) == 0 || slices.Contains(c.Types, recordType)
+}
+
+func present(data *models.UnstructuredData, field string) bool {
+	if key, ok := strings.CutPrefix(field, "metadata."); ok {
+		value, exists := data.Metadata[key]
+		return exists && value != nil && value != ""
+	}
+	switch field {
+	case "id":
+		return data.ID != ""
+	case "source":
+		return data.Source != ""
+	case "type":
+		return data.Type != ""
+	case "title":
+		return strings.TrimSpace(data.Title) != ""
+	case "content":
+		return strings.TrimSpace(data.Content) != ""
+	case "url":
+		return data.URL != ""
+	case "author":
+		return data.Author != ""
+	case "published_at":
+		return !data.PublishedAt.IsZero()
+	case "tags":
+		return len(data.Tags) > 0
+	case "entities":
+		return len(data.Entities) > 0
+	case "sentiment":
+		return data.Sentiment != nil
+	}
+	return false
+}
+
+func numeric(data *models.UnstructuredData, field string) (float64, bool) {
+	if key, ok := strings.CutPrefix(field, "metadata."); ok {
+		switch value := data.Metadata[key].(type) {
+		case float64:
+			return value, true
+		case int:
+			return float64(value), true
+		case int64:
+			return float64(value), true
+		}
+		return 0, false
+	}
+	if data.Sentiment == nil {
+		return 0, false
+	}
+	switch field {
+	case "sentiment.overall":
+		return data.Sentiment.Overall, true
+	case "sentiment.positive":
+		return data.Sentiment.Positive, true
+	case "sentiment.negative":
+		return data.Sentiment.Negative, true
+	case "sentiment.neutral":
+		return data.Sentiment.Neutral, true
+	case "sentiment.magnitude":
+		return data.Sentiment.Magnitude, true
+	}
+	return 0, false
+}
+
+// EnforcingStorage validates records against the contracts before saving them.
+type EnforcingStorage struct {
+	storage.Storage
+	validator *Validator
+}
+
+// Wrap returns a Storage that enforces the validator's contracts on writes.
+func Wrap(store storage.Storage, validator *Validator) *EnforcingStorage {
+	return &EnforcingStorage{Storage: store, validator: validator}
+}
+
+func (s *EnforcingStorage) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	now := time.Now()
+	violations := s.validator.Validate(data, now)
+	if len(violations) == 0 {
+		return s.Storage.SaveUnstructuredData(ctx, data)
+	}
+
+	issues := make([]string, 0, len(violations))
+	reject := false
+	for _, v := range violations {
+		issues = append(issues, v.String())
+		reject = reject || v.Action == ActionReject
+	}
+	s.recordQuality(ctx, data, issues, now)
+
+	if reject {
+		return fmt.Errorf("record %s violates data contracts: %s", data.ID, strings.Join(issues, "; "))
+	}
+
+	if data.Metadata == nil {
+		data.Metadata = make(map[string]interface{})
+	}
+	data.Metadata["contract_violations"] = issues
+	data.Tags = append(data.Tags, "contract_violation")
+	return s.Storage.SaveUnstructuredData(ctx, data)
+}
+
+func (s *EnforcingStorage) recordQuality(ctx context.Context, data *models.UnstructuredData, issues []string, now time.Time) {
+	hash := md5.Sum([]byte(data.ID + now.String()))
+	quality := &models.DataQuality{
+		ID:                fmt.Sprintf("contract-%x", hash[:8]),
+		DataID:            data.ID,
+		Source:            data.Source,
+		QualityScore:      0,
+		CompletenessScore: 0,
+		AccuracyScore:     0,
+		FreshnessScore:    0,
+		Issues:            issues,
+		CheckedAt:         now,
+	}
+	if err := s.Storage.SaveDataQuality(ctx, quality); err != nil {
+		log.Printf("Error recording contract violations for %s: %v", data.ID, err)
+	}
+}
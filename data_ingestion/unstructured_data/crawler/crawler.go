@@ -0,0 +1,290 @@
+// Package crawler reads news sites without feeds: it fetches section pages,
+// follows the article links on them through a persistent frontier, honours
+// robots.txt and a per-host request rate, and reads articles with
+// site-specific CSS selector rules.
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// maxPageBytes cuts off oversized pages
+	maxPageBytes = 5 << 20
+	// forgetAfter is how long fetched URLs are remembered
+	forgetAfter = 30 * 24 * time.Hour
+	// saveEvery is how many fetches pass between frontier saves during a run
+	saveEvery = 20
+)
+
+// ErrDisallowed is returned for URLs robots.txt keeps the crawler from.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Options configure a Crawler for one site.
+type Options struct {
+	// StartURLs are section pages, fetched on every run for new links
+	StartURLs []string
+	// Follow selects the links to queue; links on the start URLs' hosts
+	// when nil
+	Follow *regexp.Regexp
+	// Article selects the URLs read as articles; every followed URL when nil
+	Article *regexp.Regexp
+	// MaxDepth is how many links away from a start URL to follow
+	MaxDepth int
+	// MaxPages bounds the queued URLs fetched in one run
+	MaxPages int
+	Rules    Rules
+	// HostDelay is the least time between requests to a host; a longer
+	// robots.txt Crawl-delay wins
+	HostDelay time.Duration
+	UserAgent string
+	// FrontierFile persists the frontier between runs; empty keeps it in memory
+	FrontierFile string
+}
+
+// Stats count what a run did.
+type Stats struct {
+	Fetched    int
+	Articles   int
+	Disallowed int
+	Errors     int
+	Queued     int
+}
+
+// Crawler crawls one site.
+type Crawler struct {
+	opts     Options
+	client   *http.Client
+	robots   *RobotsCache
+	frontier *Frontier
+	hosts    map[string]bool
+	starts   map[string]bool
+
+	mu   sync.Mutex
+	next map[string]time.Time // host -> earliest time of the next request
+}
+
+// New creates a crawler and loads its frontier.
+func New(opts Options) (*Crawler, error) {
+	if len(opts.StartURLs) == 0 {
+		return nil, errors.New("crawler needs at least one start URL")
+	}
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = 1
+	}
+
+	hosts := make(map[string]bool)
+	starts := make(map[string]bool)
+	for _, start := range opts.StartURLs {
+		u, err := url.Parse(start)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid start URL %q", start)
+		}
+		hosts[strings.ToLower(u.Host)] = true
+		starts[start] = true
+	}
+
+	frontier, err := LoadFrontier(opts.FrontierFile)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	return &Crawler{
+		opts:     opts,
+		client:   client,
+		robots:   NewRobotsCache(client, opts.UserAgent),
+		frontier: frontier,
+		hosts:    hosts,
+		starts:   starts,
+		next:     make(map[string]time.Time),
+	}, nil
+}
+
+// Run fetches the start URLs, queues their new links and then works through
+// up to MaxPages of the frontier, handing every article to handle. The
+// frontier is saved as it goes and when the run ends.
+func (c *Crawler) Run(ctx context.Context, handle func(context.Context, *Page) error) (Stats, error) {
+	var stats Stats
+	defer func() {
+		c.frontier.Forget(time.Now().Add(-forgetAfter))
+		if err := c.frontier.Save(); err != nil {
+			log.Printf("Failed to save crawl frontier: %v", err)
+		}
+	}()
+
+	for _, start := range c.opts.StartURLs {
+		if err := c.visit(ctx, Entry{URL: start}, handle, &stats); err != nil {
+			if ctx.Err() != nil || errors.Is(err, errRobotsUnavailable) {
+				return stats, err
+			}
+			log.Printf("Error crawling %s: %v", start, err)
+		}
+	}
+
+	for fetched := 0; fetched < c.opts.MaxPages; fetched++ {
+		entry, ok := c.frontier.Pop()
+		if !ok {
+			break
+		}
+		if err := c.visit(ctx, entry, handle, &stats); err != nil {
+			// Keep the URL for the next run
+			if ctx.Err() != nil || errors.Is(err, errRobotsUnavailable) {
+				c.frontier.Requeue(entry)
+				return stats, err
+			}
+			if !errors.Is(err, ErrDisallowed) {
+				log.Printf("Error crawling %s: %v", entry.URL, err)
+			}
+		}
+		if fetched%saveEvery == saveEvery-1 {
+			if err := c.frontier.Save(); err != nil {
+				log.Printf("Failed to save crawl frontier: %v", err)
+			}
+		}
+	}
+	return stats, nil
+}
+
+func (c *Crawler) visit(ctx context.Context, entry Entry, handle func(context.Context, *Page) error, stats *Stats) error {
+	pageURL, err := url.Parse(entry.URL)
+	if err != nil {
+		stats.Errors++
+		return err
+	}
+
+	rules, err := c.robots.get(ctx, pageURL)
+	if err != nil {
+		return err
+	}
+	if !rules.allowed(pageURL.RequestURI()) {
+		stats.Disallowed++
+		return ErrDisallowed
+	}
+	if err := c.wait(ctx, pageURL.Host, rules.delay); err != nil {
+		return err
+	}
+
+	raw, err := c.fetch(ctx, pageURL)
+	if err != nil {
+		stats.Errors++
+		return err
+	}
+	stats.Fetched++
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(raw))
+	if err != nil {
+		stats.Errors++
+		return err
+	}
+
+	if entry.Depth < c.opts.MaxDepth {
+		for _, href := range c.opts.Rules.links(doc) {
+			link, ok := c.normalize(pageURL, href)
+			if ok && c.frontier.Push(link, entry.Depth+1) {
+				stats.Queued++
+			}
+		}
+	}
+
+	// Start URLs are section pages, not articles
+	if entry.Depth == 0 || (c.opts.Article != nil && !c.opts.Article.MatchString(entry.URL)) {
+		return nil
+	}
+	page, err := c.opts.Rules.extract(entry.URL, doc, raw)
+	if err != nil {
+		return nil
+	}
+	stats.Articles++
+	return handle(ctx, page)
+}
+
+// normalize resolves a link against its page and keeps it only if the site
+// follows it. Fragments and utm_ tracking parameters are dropped so one
+// article is queued once.
+func (c *Crawler) normalize(base *url.URL, href string) (string, bool) {
+	u, err := base.Parse(strings.TrimSpace(href))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	if query := u.Query(); len(query) > 0 {
+		for key := range query {
+			if strings.HasPrefix(strings.ToLower(key), "utm_") {
+				query.Del(key)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	link := u.String()
+	// Start URLs are fetched every run and never queued
+	if c.starts[link] {
+		return "", false
+	}
+	if c.opts.Follow != nil {
+		return link, c.opts.Follow.MatchString(link)
+	}
+	return link, c.hosts[u.Host]
+}
+
+// wait reserves the host's next request slot and sleeps until it comes up.
+func (c *Crawler) wait(ctx context.Context, host string, robotsDelay time.Duration) error {
+	delay := c.opts.HostDelay
+	if robotsDelay > delay {
+		delay = robotsDelay
+	}
+
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.next[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	c.next[host] = slot.Add(delay)
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(time.Until(slot)):
+		return nil
+	}
+}
+
+func (c *Crawler) fetch(ctx context.Context, pageURL *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page returned status %d", resp.StatusCode)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("page is %q, not HTML", mediaType)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+}
@@ -0,0 +1,143 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Entry is a queued URL and its link distance from the start URLs.
+type Entry struct {
+	URL   string    `json:"url"`
+	Depth int       `json:"depth"`
+	Added time.Time `json:"added"`
+}
+
+// Frontier is the queue of URLs still to fetch and the set of URLs already
+// queued or fetched, persisted between runs so a restart neither loses the
+// backlog nor refetches articles.
+type Frontier struct {
+	path string
+
+	mu    sync.Mutex
+	queue []Entry
+	seen  map[string]time.Time // URL -> when it was first queued
+}
+
+type frontierFile struct {
+	Queue     []Entry              `json:"queue"`
+	Seen      map[string]time.Time `json:"seen"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// LoadFrontier reads the frontier saved at path; a missing file gives an
+// empty frontier. An empty path keeps the frontier in memory only.
+func LoadFrontier(path string) (*Frontier, error) {
+	f := &Frontier{path: path, seen: make(map[string]time.Time)}
+	if path == "" {
+		return f, nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frontier: %w", err)
+	}
+	var file frontierFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse frontier %s: %w", path, err)
+	}
+	f.queue = file.Queue
+	if file.Seen != nil {
+		f.seen = file.Seen
+	}
+	return f, nil
+}
+
+// Push queues a URL unless it was queued before.
+func (f *Frontier) Push(url string, depth int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.seen[url]; ok {
+		return false
+	}
+	now := time.Now()
+	f.seen[url] = now
+	f.queue = append(f.queue, Entry{URL: url, Depth: depth, Added: now})
+	return true
+}
+
+// Pop takes the oldest queued URL.
+func (f *Frontier) Pop() (Entry, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.queue) == 0 {
+		return Entry{}, false
+	}
+	entry := f.queue[0]
+	f.queue = f.queue[1:]
+	return entry, true
+}
+
+// Requeue puts back a popped entry whose fetch was cut short.
+func (f *Frontier) Requeue(entry Entry) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queue = append([]Entry{entry}, f.queue...)
+}
+
+// Len is the number of queued URLs.
+func (f *Frontier) Len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.queue)
+}
+
+// Forget drops seen URLs first queued before cutoff, bounding the file; a
+// forgotten URL still linked from a section page is fetched again.
+func (f *Frontier) Forget(cutoff time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	queued := make(map[string]bool, len(f.queue))
+	for _, entry := range f.queue {
+		queued[entry.URL] = true
+	}
+	for url, added := range f.seen {
+		if added.Before(cutoff) && !queued[url] {
+			delete(f.seen, url)
+		}
+	}
+}
+
+// Save writes the frontier through a temporary file so a crash never
+// leaves it half written.
+func (f *Frontier) Save() error {
+	if f.path == "" {
+		return nil
+	}
+
+	f.mu.Lock()
+	raw, err := json.Marshal(frontierFile{Queue: f.queue, Seen: f.seen, UpdatedAt: time.Now()})
+	f.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode frontier: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("failed to create frontier directory: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0644); err != nil {
+		return fmt.Errorf("failed to write frontier: %w", err)
+	}
+	return os.Rename(tmp, f.path)
+}
@@ -0,0 +1,231 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// robotsTTL is how long a host's robots.txt is trusted before refetching
+	robotsTTL = 24 * time.Hour
+	// robotsRetry is how long a host whose robots.txt could not be fetched
+	// is left alone
+	robotsRetry = time.Hour
+	// maxRobotsBytes is the most of a robots.txt read, as RFC 9309 allows
+	maxRobotsBytes = 500 << 10
+)
+
+// robots is the group of a robots.txt that applies to one user agent.
+type robots struct {
+	rules []robotsRule
+	delay time.Duration
+}
+
+type robotsRule struct {
+	pattern *regexp.Regexp
+	length  int
+	allow   bool
+}
+
+var allowAll = &robots{}
+
+// parseRobots reads the rules for agent, the product token of the user
+// agent, falling back to the "*" group.
+func parseRobots(body []byte, agent string) *robots {
+	agent = strings.ToLower(agent)
+	var specific, wildcard *robots
+	var current []*robots
+	inAgents := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share one group
+			if !inAgents {
+				current = nil
+			}
+			inAgents = true
+			token := strings.ToLower(value)
+			switch {
+			case token == "*":
+				if wildcard == nil {
+					wildcard = &robots{}
+				}
+				current = append(current, wildcard)
+			case token != "" && strings.Contains(agent, token):
+				if specific == nil {
+					specific = &robots{}
+				}
+				current = append(current, specific)
+			}
+		case "allow", "disallow":
+			inAgents = false
+			// An empty disallow allows everything and adds no rule
+			if value == "" {
+				continue
+			}
+			// A path that is not valid UTF-8 cannot be matched; the rule is dropped
+			pattern, err := robotsPattern(value)
+			if err != nil {
+				continue
+			}
+			rule := robotsRule{pattern: pattern, length: len(value), allow: key == "allow"}
+			for _, group := range current {
+				group.rules = append(group.rules, rule)
+			}
+		case "crawl-delay":
+			inAgents = false
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+				for _, group := range current {
+					group.delay = time.Duration(seconds * float64(time.Second))
+				}
+			}
+		default:
+			inAgents = false
+		}
+	}
+
+	switch {
+	case specific != nil:
+		return specific
+	case wildcard != nil:
+		return wildcard
+	}
+	return allowAll
+}
+
+// robotsPattern compiles a path pattern, where "*" matches any run of
+// characters and a trailing "$" anchors the end.
+func robotsPattern(value string) (*regexp.Regexp, error) {
+	anchored := strings.HasSuffix(value, "$")
+	value = strings.TrimSuffix(value, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(value), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.Compile(expr)
+}
+
+// allowed applies the longest matching rule, allow winning ties; a path no
+// rule matches is allowed.
+func (r *robots) allowed(path string) bool {
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if rule.length < best || !rule.pattern.MatchString(path) {
+			continue
+		}
+		if rule.length > best || rule.allow {
+			best, allow = rule.length, rule.allow
+		}
+	}
+	return allow
+}
+
+// RobotsCache fetches and keeps each host's robots.txt, for the crawler
+// and for other clients reading pages from sites.
+type RobotsCache struct {
+	client    *http.Client
+	userAgent string
+
+	mu      sync.Mutex
+	entries map[string]robotsEntry
+}
+
+type robotsEntry struct {
+	robots  *robots
+	err     error
+	expires time.Time
+}
+
+// errRobotsUnavailable is returned while a host's robots.txt cannot be
+// fetched; RFC 9309 asks crawlers to treat the whole site as off limits.
+var errRobotsUnavailable = errors.New("robots.txt unavailable")
+
+func NewRobotsCache(client *http.Client, userAgent string) *RobotsCache {
+	return &RobotsCache{client: client, userAgent: userAgent, entries: make(map[string]robotsEntry)}
+}
+
+// Allowed reports whether u may be fetched, with the host's Crawl-delay.
+// While the host's robots.txt cannot be fetched, nothing on it is allowed
+// and the error says why.
+func (c *RobotsCache) Allowed(ctx context.Context, u *url.URL) (bool, time.Duration, error) {
+	rules, err := c.get(ctx, u)
+	if err != nil {
+		return false, 0, err
+	}
+	return rules.allowed(u.RequestURI()), rules.delay, nil
+}
+
+func (c *RobotsCache) get(ctx context.Context, u *url.URL) (*robots, error) {
+	origin := u.Scheme + "://" + u.Host
+	c.mu.Lock()
+	entry, ok := c.entries[origin]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.robots, entry.err
+	}
+
+	r, err := c.fetch(ctx, origin)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	entry = robotsEntry{robots: r, expires: time.Now().Add(robotsTTL)}
+	if err != nil {
+		entry = robotsEntry{err: fmt.Errorf("%w: %v", errRobotsUnavailable, err), expires: time.Now().Add(robotsRetry)}
+	}
+	c.mu.Lock()
+	c.entries[origin] = entry
+	c.mu.Unlock()
+	return entry.robots, entry.err
+}
+
+func (c *RobotsCache) fetch(ctx context.Context, origin string) (*robots, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsBytes))
+		if err != nil {
+			return nil, err
+		}
+		token, _, _ := strings.Cut(c.userAgent, "/")
+		return parseRobots(body, token), nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		// No robots.txt: nothing is off limits
+		return allowAll, nil
+	}
+	return nil, fmt.Errorf("status %d", resp.StatusCode)
+}
@@ -0,0 +1,132 @@
+package crawler
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
+)
+
+// Rules are the CSS selectors that read a site's pages. A selector ending
+// in "@attr" reads that attribute of the first match instead of its text.
+type Rules struct {
+	// Links selects the links to follow; "a[href]" when empty
+	Links string
+	Title string
+	// Body selects the article body; empty falls back to readability extraction
+	Body            string
+	Author          string
+	Published       string
+	PublishedLayout string // Go time layout; RFC 3339 when empty
+}
+
+// Page is an article read from a site.
+type Page struct {
+	URL       string
+	Title     string
+	Body      string
+	Author    string
+	Published time.Time
+}
+
+var (
+	defaultTitle     = []string{`meta[property="og:title"]@content`, "h1", "title"}
+	defaultAuthor    = []string{`meta[name="author"]@content`, `meta[property="article:author"]@content`, `[rel="author"]`}
+	defaultPublished = []string{`meta[property="article:published_time"]@content`, "time[datetime]@datetime"}
+)
+
+// links returns the href of every link the rules select.
+func (r Rules) links(doc *goquery.Document) []string {
+	selector := r.Links
+	if selector == "" {
+		selector = "a[href]"
+	}
+	var hrefs []string
+	doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
+		if href, ok := s.Attr("href"); ok {
+			hrefs = append(hrefs, href)
+		}
+	})
+	return hrefs
+}
+
+// extract reads an article; raw is the page's HTML, for the readability
+// fallback.
+func (r Rules) extract(pageURL string, doc *goquery.Document, raw []byte) (*Page, error) {
+	page := &Page{
+		URL:    pageURL,
+		Title:  first(doc, r.Title, defaultTitle),
+		Author: first(doc, r.Author, defaultAuthor),
+	}
+
+	if r.Body != "" {
+		var paragraphs []string
+		doc.Find(r.Body).Each(func(_ int, s *goquery.Selection) {
+			blocks := s.Find("p, h2, h3, li, blockquote")
+			if blocks.Length() == 0 {
+				blocks = s
+			}
+			blocks.Each(func(_ int, block *goquery.Selection) {
+				if text := collapse(block.Text()); text != "" {
+					paragraphs = append(paragraphs, text)
+				}
+			})
+		})
+		page.Body = strings.Join(paragraphs, "\n\n")
+	} else {
+		article, err := extraction.ExtractArticle(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+		page.Body = article.Text
+		if page.Author == "" {
+			page.Author = article.Byline
+		}
+	}
+	if page.Body == "" {
+		return nil, extraction.ErrNoArticle
+	}
+
+	layout := r.PublishedLayout
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if published := first(doc, r.Published, defaultPublished); published != "" {
+		if t, err := time.Parse(layout, published); err == nil {
+			page.Published = t
+		} else if t, err := time.Parse(time.RFC3339, published); err == nil {
+			page.Published = t
+		}
+	}
+	return page, nil
+}
+
+// first returns the value of the configured selector, or of the first
+// fallback that matches when none is configured.
+func first(doc *goquery.Document, selector string, fallbacks []string) string {
+	if selector != "" {
+		return selectValue(doc, selector)
+	}
+	for _, fallback := range fallbacks {
+		if value := selectValue(doc, fallback); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func selectValue(doc *goquery.Document, selector string) string {
+	selector, attr, hasAttr := strings.Cut(selector, "@")
+	match := doc.Find(selector).First()
+	if hasAttr {
+		return strings.TrimSpace(match.AttrOr(attr, ""))
+	}
+	return collapse(match.Text())
+}
+
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
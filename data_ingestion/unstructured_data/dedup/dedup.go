@@ -0,0 +1,235 @@
+// Package dedup links near-duplicate documents, such as one wire story
+// republished by several outlets, into clusters. Each document is given a
+// 64-bit simhash of its text at ingest time; documents whose fingerprints
+// differ in at most a few bits share the "cluster_id" metadata key, which
+// is the ID of the first of them seen.
+package dedup
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// shingleSize is the number of words hashed together. Word pairs keep some
+// word order in the fingerprint; longer shingles leave news-length texts
+// too few of them, and one edited word flips many bits.
+const shingleSize = 2
+
+// Simhash fingerprints text: every bit is the majority vote of that bit of
+// the hashes of the text's shingles, so similar texts get fingerprints a
+// few bits apart. It also returns the number of words in text.
+func Simhash(text string) (uint64, int) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	size := min(shingleSize, len(words))
+	if size == 0 {
+		return 0, 0
+	}
+
+	var votes [64]int
+	for i := 0; i+size <= len(words); i++ {
+		h := fnv.New64a()
+		h.Write([]byte(strings.Join(words[i:i+size], " ")))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				votes[bit]++
+			} else {
+				votes[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+	for bit, vote := range votes {
+		if vote > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash, len(words)
+}
+
+// Distance is the number of bits two fingerprints differ in.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+type entry struct {
+	id      string
+	cluster string
+	hash    uint64
+	at      time.Time
+}
+
+// Detector wraps a Storage and assigns each document to a cluster before
+// it is saved. Recent fingerprints are kept in memory, split into bands:
+// with more bands than the allowed distance, two fingerprints within it
+// agree on at least one whole band, so only documents sharing a band are
+// compared.
+type Detector struct {
+	storage.Storage
+	config config.DedupConfig
+	width  int // bits per band
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	bands     []map[uint64][]*entry
+	lastPrune time.Time
+}
+
+// Wrap returns a Storage that clusters documents by cfg.
+func Wrap(store storage.Storage, cfg config.DedupConfig) *Detector {
+	count := cfg.MaxDistance + 1
+	d := &Detector{
+		Storage: store,
+		config:  cfg,
+		width:   64 / count,
+		entries: make(map[string]*entry),
+		bands:   make([]map[uint64][]*entry, count),
+	}
+	for i := range d.bands {
+		d.bands[i] = make(map[uint64][]*entry)
+	}
+	return d
+}
+
+// Load indexes the fingerprinted documents published within the window, so
+// stories seen before a restart still gather their duplicates.
+func (d *Detector) Load(ctx context.Context) error {
+	from := time.Now().Add(-d.config.Window)
+	stored, err := d.Storage.ListUnstructuredData(ctx, storage.DataFilters{DateFrom: &from})
+	if err != nil {
+		return fmt.Errorf("failed to load fingerprints: %w", err)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, data := range stored {
+		raw, _ := data.Metadata["simhash"].(string)
+		hash, err := strconv.ParseUint(raw, 16, 64)
+		if err != nil {
+			continue
+		}
+		cluster, _ := data.Metadata["cluster_id"].(string)
+		if cluster == "" {
+			cluster = data.ID
+		}
+		d.add(&entry{id: data.ID, cluster: cluster, hash: hash, at: publishedAt(data)})
+	}
+	log.Printf("Loaded %d document fingerprints", len(d.entries))
+	return nil
+}
+
+func (d *Detector) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	hash, words := Simhash(data.Title + "\n" + data.Content)
+	if words >= d.config.MinWords {
+		if data.Metadata == nil {
+			data.Metadata = make(map[string]interface{})
+		}
+		data.Metadata["simhash"] = fmt.Sprintf("%016x", hash)
+		data.Metadata["cluster_id"] = d.assign(data.ID, hash, publishedAt(data))
+	}
+	return d.Storage.SaveUnstructuredData(ctx, data)
+}
+
+// assign returns the cluster of a document: the one it was put in before,
+// else that of its nearest near-duplicate within the window, else a new
+// one named after the document.
+func (d *Detector) assign(id string, hash uint64, at time.Time) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.lastPrune) > time.Hour {
+		d.prune(time.Now().Add(-d.config.Window))
+	}
+
+	if existing, ok := d.entries[id]; ok {
+		d.remove(existing)
+		existing.hash, existing.at = hash, at
+		d.add(existing)
+		return existing.cluster
+	}
+
+	var nearest *entry
+	best := d.config.MaxDistance + 1
+	for i, band := range d.bands {
+		for _, candidate := range band[d.band(hash, i)] {
+			if gap := candidate.at.Sub(at); gap > d.config.Window || -gap > d.config.Window {
+				continue
+			}
+			if distance := Distance(hash, candidate.hash); distance < best {
+				nearest, best = candidate, distance
+			}
+		}
+	}
+
+	e := &entry{id: id, cluster: id, hash: hash, at: at}
+	if nearest != nil {
+		e.cluster = nearest.cluster
+	}
+	d.add(e)
+	return e.cluster
+}
+
+// band returns the i-th band of hash.
+func (d *Detector) band(hash uint64, i int) uint64 {
+	return (hash >> (i * d.width)) & (1<<d.width - 1)
+}
+
+// add indexes e; the caller holds d.mu.
+func (d *Detector) add(e *entry) {
+	d.entries[e.id] = e
+	for i, band := range d.bands {
+		key := d.band(e.hash, i)
+		band[key] = append(band[key], e)
+	}
+}
+
+// remove drops e from the index; the caller holds d.mu.
+func (d *Detector) remove(e *entry) {
+	delete(d.entries, e.id)
+	for i, band := range d.bands {
+		key := d.band(e.hash, i)
+		kept := band[key][:0]
+		for _, other := range band[key] {
+			if other != e {
+				kept = append(kept, other)
+			}
+		}
+		if len(kept) == 0 {
+			delete(band, key)
+		} else {
+			band[key] = kept
+		}
+	}
+}
+
+// prune drops the documents published before cutoff; the caller holds d.mu.
+func (d *Detector) prune(cutoff time.Time) {
+	for _, e := range d.entries {
+		if e.at.Before(cutoff) {
+			d.remove(e)
+		}
+	}
+	d.lastPrune = time.Now()
+}
+
+func publishedAt(data *models.UnstructuredData) time.Time {
+	if data.PublishedAt.IsZero() {
+		return time.Now()
+	}
+	return data.PublishedAt
+}
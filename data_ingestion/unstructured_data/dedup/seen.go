@@ -0,0 +1,392 @@
+package dedup
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// seenMagic starts a seen index file, naming its format.
+var seenMagic = [8]byte{'C', 'T', 'S', 'E', 'E', 'N', 0, 1}
+
+// seenRecord is what the index keeps of a saved document: a hash of its
+// content and when it was saved.
+type seenRecord struct {
+	content uint64
+	at      int64
+}
+
+// SeenStats counts the documents of one source checked against the seen
+// index and those skipped as already saved.
+type SeenStats struct {
+	Source  string `json:"source"`
+	Checked int64  `json:"checked"`
+	Skipped int64  `json:"skipped"`
+}
+
+// SeenSummary describes the seen index as a whole.
+type SeenSummary struct {
+	Entries        int         `json:"entries"`
+	FilterBits     int         `json:"filter_bits"`
+	FilterHashes   int         `json:"filter_hashes"`
+	FalsePositives int64       `json:"false_positives"`
+	SavedAt        *time.Time  `json:"saved_at,omitempty"`
+	Sources        []SeenStats `json:"sources"`
+}
+
+// Seen wraps a Storage and skips saving documents whose ID and content were
+// already saved within the window, as polling the same feed again delivers
+// them. A bloom filter over ID and content answers for documents never seen
+// without a map lookup; the exact set behind it settles the rest, so a
+// false positive never drops a document. A document whose content changed
+// is saved again.
+type Seen struct {
+	storage.Storage
+	config config.SeenConfig
+
+	mu             sync.Mutex
+	records        map[uint64]seenRecord
+	filter         *bloom
+	stats          map[string]*SeenStats
+	falsePositives int64
+	dirty          bool
+	savedAt        time.Time
+	lastPrune      time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// WrapSeen returns a Storage that skips documents already saved, loading the
+// index persisted in cfg.File, and saves the index every SaveInterval until
+// Shutdown.
+func WrapSeen(store storage.Storage, cfg config.SeenConfig) (*Seen, error) {
+	s := &Seen{
+		Storage: store,
+		config:  cfg,
+		records: make(map[uint64]seenRecord),
+		stats:   make(map[string]*SeenStats),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	s.prune(time.Now())
+	log.Printf("Loaded %d seen document keys", len(s.records))
+
+	go s.saveLoop()
+	return s, nil
+}
+
+func (s *Seen) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	id, content := seenKeys(data)
+	if s.seen(data.Source, id, content) {
+		return nil
+	}
+	if err := s.Storage.SaveUnstructuredData(ctx, data); err != nil {
+		return err
+	}
+
+	// Recorded once saved, so a failed save is retried when next polled
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[id] = seenRecord{content: content, at: time.Now().Unix()}
+	s.filter.add(id ^ content)
+	s.dirty = true
+	return nil
+}
+
+// DeleteUnstructuredData forgets the deleted documents, so they are saved
+// again should a source deliver them once more.
+func (s *Seen) DeleteUnstructuredData(ctx context.Context, ids []string) error {
+	if err := s.Storage.DeleteUnstructuredData(ctx, ids); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range ids {
+		delete(s.records, hashString(id))
+	}
+	s.dirty = true
+	return nil
+}
+
+// seen reports whether the document was saved within the window, counting
+// it for source.
+func (s *Seen) seen(source string, id, content uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastPrune) > time.Hour {
+		s.prune(now)
+	}
+
+	stats, ok := s.stats[source]
+	if !ok {
+		stats = &SeenStats{Source: source}
+		s.stats[source] = stats
+	}
+	stats.Checked++
+
+	if !s.filter.has(id ^ content) {
+		return false
+	}
+	record, ok := s.records[id]
+	if !ok || record.content != content || now.Unix()-record.at > int64(s.config.Window/time.Second) {
+		s.falsePositives++
+		return false
+	}
+	stats.Skipped++
+	return true
+}
+
+// prune drops the keys saved before the window and rebuilds the filter from
+// those left, as a bloom filter cannot drop keys; the caller holds s.mu
+// unless the index is not yet shared.
+func (s *Seen) prune(now time.Time) {
+	cutoff := now.Add(-s.config.Window).Unix()
+	for id, record := range s.records {
+		if record.at < cutoff {
+			delete(s.records, id)
+			s.dirty = true
+		}
+	}
+	s.filter = newBloom(max(s.config.Capacity, len(s.records)), s.config.FalsePositiveRate)
+	for id, record := range s.records {
+		s.filter.add(id ^ record.content)
+	}
+	s.lastPrune = now
+}
+
+// Stats returns the counts of every source seen, by source, and the size of
+// the index.
+func (s *Seen) Stats() SeenSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	summary := SeenSummary{
+		Entries:        len(s.records),
+		FilterBits:     len(s.filter.bits) * 64,
+		FilterHashes:   s.filter.hashes,
+		FalsePositives: s.falsePositives,
+		Sources:        make([]SeenStats, 0, len(s.stats)),
+	}
+	if !s.savedAt.IsZero() {
+		savedAt := s.savedAt
+		summary.SavedAt = &savedAt
+	}
+	for _, stats := range s.stats {
+		summary.Sources = append(summary.Sources, *stats)
+	}
+	sort.Slice(summary.Sources, func(i, j int) bool { return summary.Sources[i].Source < summary.Sources[j].Source })
+	return summary
+}
+
+// Shutdown stops the periodic saves and saves the index a last time.
+func (s *Seen) Shutdown(ctx context.Context) error {
+	close(s.stop)
+	select {
+	case <-s.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return s.save()
+}
+
+func (s *Seen) saveLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.config.SaveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.save(); err != nil {
+				log.Printf("Failed to save seen index: %v", err)
+			}
+		}
+	}
+}
+
+// save writes the index, when it changed, to a temporary file renamed over
+// the last, so a crash part way leaves the previous one whole.
+func (s *Seen) save() error {
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	ids := make([]uint64, 0, len(s.records))
+	records := make([]seenRecord, 0, len(s.records))
+	for id, record := range s.records {
+		ids = append(ids, id)
+		records = append(records, record)
+	}
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := s.write(ids, records); err != nil {
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return err
+	}
+	s.mu.Lock()
+	s.savedAt = time.Now().UTC()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Seen) write(ids []uint64, records []seenRecord) error {
+	if err := os.MkdirAll(filepath.Dir(s.config.File), 0755); err != nil {
+		return fmt.Errorf("failed to create seen index directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.config.File), filepath.Base(s.config.File)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create seen index: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	w.Write(seenMagic[:])
+	var buf [24]byte
+	binary.LittleEndian.PutUint64(buf[:8], uint64(len(ids)))
+	w.Write(buf[:8])
+	for i, id := range ids {
+		binary.LittleEndian.PutUint64(buf[0:8], id)
+		binary.LittleEndian.PutUint64(buf[8:16], records[i].content)
+		binary.LittleEndian.PutUint64(buf[16:24], uint64(records[i].at))
+		w.Write(buf[:])
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write seen index: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync seen index: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write seen index: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.config.File); err != nil {
+		return fmt.Errorf("failed to replace seen index: %w", err)
+	}
+	return nil
+}
+
+// load reads the index saved by a previous run; a missing file starts it
+// empty.
+func (s *Seen) load() error {
+	file, err := os.Open(s.config.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open seen index: %w", err)
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var header [16]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return fmt.Errorf("failed to read seen index: %w", err)
+	}
+	if [8]byte(header[:8]) != seenMagic {
+		return fmt.Errorf("%s is not a seen index", s.config.File)
+	}
+	count := binary.LittleEndian.Uint64(header[8:])
+	var buf [24]byte
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return fmt.Errorf("failed to read seen index: %w", err)
+		}
+		s.records[binary.LittleEndian.Uint64(buf[0:8])] = seenRecord{
+			content: binary.LittleEndian.Uint64(buf[8:16]),
+			at:      int64(binary.LittleEndian.Uint64(buf[16:24])),
+		}
+	}
+	return nil
+}
+
+// seenKeys hashes a document's ID, and separately the content a source can
+// change under the same ID.
+func seenKeys(data *models.UnstructuredData) (uint64, uint64) {
+	h := fnv.New64a()
+	for _, field := range []string{data.Title, data.Summary, data.Content, data.URL, data.PublishedAt.UTC().Format(time.RFC3339)} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hashString(data.ID), h.Sum64()
+}
+
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// bloom is a bloom filter over 64-bit keys.
+type bloom struct {
+	bits   []uint64
+	hashes int
+}
+
+// newBloom sizes a filter to hold capacity keys at the given false
+// positive rate.
+func newBloom(capacity int, rate float64) *bloom {
+	n := float64(max(capacity, 1))
+	m := math.Ceil(-n * math.Log(rate) / (math.Ln2 * math.Ln2))
+	k := int(math.Round(m / n * math.Ln2))
+	return &bloom{bits: make([]uint64, (int(m)+63)/64), hashes: max(k, 1)}
+}
+
+// positions derives the filter's hash positions of key by double hashing
+// two mixes of it.
+func (b *bloom) positions(key uint64, visit func(uint64)) {
+	h1, h2 := mix(key), mix(key^0x9e3779b97f4a7c15)|1
+	size := uint64(len(b.bits)) * 64
+	for i := 0; i < b.hashes; i++ {
+		visit((h1 + uint64(i)*h2) % size)
+	}
+}
+
+func (b *bloom) add(key uint64) {
+	b.positions(key, func(p uint64) { b.bits[p/64] |= 1 << (p % 64) })
+}
+
+func (b *bloom) has(key uint64) bool {
+	found := true
+	b.positions(key, func(p uint64) {
+		if b.bits[p/64]&(1<<(p%64)) == 0 {
+			found = false
+		}
+	})
+	return found
+}
+
+// mix is the splitmix64 finalizer, spreading the bits of x.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
@@ -0,0 +1,179 @@
+// Package entities finds the companies, ticker symbols and money amounts a
+// text mentions. Tickers and company names come from a dictionary rather
+// than from the shape of words, so capitalised words such as THE, CEO or
+// USA are not read as stock symbols.
+package entities
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+//go:embed tickers.csv
+var tickersCSV string
+
+// Company is a listed issuer and the names it is written under, the
+// canonical name first.
+type Company struct {
+	Symbol string
+	Names  []string
+}
+
+// Dictionary maps ticker symbols and company names to companies.
+type Dictionary struct {
+	companies map[string]*Company
+	// names are matched longest first, so "Bank of America" wins over a
+	// shorter name inside it
+	names []nameEntry
+}
+
+type nameEntry struct {
+	name    string
+	company *Company
+}
+
+var defaultDictionary = mustParse(tickersCSV)
+
+// Default returns the dictionary shipped with the package.
+func Default() *Dictionary {
+	return defaultDictionary
+}
+
+// Load returns the default dictionary extended with the configured issuers
+// and dictionary file.
+func Load(cfg config.EntitiesConfig) (*Dictionary, error) {
+	extra := make([]Company, 0, len(cfg.Issuers))
+	for _, issuer := range cfg.Issuers {
+		extra = append(extra, Company{Symbol: issuer.Symbol, Names: issuer.Names})
+	}
+	if cfg.DictionaryFile != "" {
+		file, err := os.Open(cfg.DictionaryFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open entity dictionary: %w", err)
+		}
+		defer file.Close()
+		companies, err := ParseCompanies(file)
+		if err != nil {
+			return nil, err
+		}
+		extra = append(extra, companies...)
+	}
+	return Default().With(extra), nil
+}
+
+// ParseCompanies reads a CSV of "symbol,name[,alias...]" rows, the format
+// of the backfill issuers file. Rows with an invalid symbol are skipped.
+func ParseCompanies(r io.Reader) ([]Company, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entity dictionary: %w", err)
+	}
+
+	var companies []Company
+	for _, row := range rows {
+		symbol, err := symbols.Normalize(strings.TrimSpace(row[0]))
+		if err != nil {
+			continue
+		}
+		company := Company{Symbol: symbol}
+		for _, name := range row[1:] {
+			if name = strings.TrimSpace(name); name != "" {
+				company.Names = append(company.Names, name)
+			}
+		}
+		companies = append(companies, company)
+	}
+	return companies, nil
+}
+
+func mustParse(raw string) *Dictionary {
+	companies, err := ParseCompanies(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return NewDictionary(companies)
+}
+
+// NewDictionary builds a dictionary; names given for a symbol listed
+// earlier are added to it.
+func NewDictionary(companies []Company) *Dictionary {
+	return (&Dictionary{companies: make(map[string]*Company)}).With(companies)
+}
+
+// With returns a copy of the dictionary extended with companies.
+func (d *Dictionary) With(companies []Company) *Dictionary {
+	merged := make(map[string]*Company, len(d.companies)+len(companies))
+	for symbol, company := range d.companies {
+		copied := *company
+		copied.Names = append([]string(nil), company.Names...)
+		merged[symbol] = &copied
+	}
+	for _, company := range companies {
+		symbol, err := symbols.Normalize(company.Symbol)
+		if err != nil {
+			continue
+		}
+		existing, ok := merged[symbol]
+		if !ok {
+			existing = &Company{Symbol: symbol}
+			merged[symbol] = existing
+		}
+		for _, name := range company.Names {
+			if !containsName(existing.Names, name) {
+				existing.Names = append(existing.Names, name)
+			}
+		}
+	}
+
+	result := &Dictionary{companies: merged}
+	for _, company := range merged {
+		for _, name := range company.Names {
+			result.names = append(result.names, nameEntry{name: name, company: company})
+		}
+	}
+	sort.Slice(result.names, func(i, j int) bool {
+		if len(result.names[i].name) != len(result.names[j].name) {
+			return len(result.names[i].name) > len(result.names[j].name)
+		}
+		return result.names[i].name < result.names[j].name
+	})
+	return result
+}
+
+// Lookup returns the company listed under symbol, in any notation.
+func (d *Dictionary) Lookup(symbol string) (*Company, bool) {
+	normalized, err := symbols.Normalize(symbol)
+	if err != nil {
+		return nil, false
+	}
+	company, ok := d.companies[normalized]
+	return company, ok
+}
+
+// Name returns a company's canonical name, or its symbol when it has none.
+func (c *Company) Name() string {
+	if len(c.Names) > 0 {
+		return c.Names[0]
+	}
+	return c.Symbol
+}
+
+func containsName(names []string, name string) bool {
+	for _, existing := range names {
+		if strings.EqualFold(existing, name) {
+			return true
+		}
+	}
+	return false
+}
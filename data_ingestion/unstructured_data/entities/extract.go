@@ -0,0 +1,259 @@
+package entities
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// Entity types this package produces.
+const (
+	TypeOrg    = "ORG"
+	TypeSymbol = "STOCK_SYMBOL"
+	TypeMoney  = "MONEY"
+)
+
+var (
+	// cashtagPattern matches $AAPL and $BRK.B
+	cashtagPattern = regexp.MustCompile(`\$([A-Za-z]{1,5}(?:[./-][A-Za-z]{1,2})?)\b`)
+	// listingPattern matches a symbol given with its exchange, "(NYSE: JPM)"
+	listingPattern = regexp.MustCompile(`\b(?:NYSE(?: American| Arca)?|NASDAQ|Nasdaq(?:GS|GM|CM)?|AMEX|TSX|LSE|OTC(?:QX|QB)?)\s*:\s*([A-Z]{1,5}(?:[.-][A-Z]{1,2})?)\b`)
+	// barePattern matches an upper case word that may be a ticker
+	barePattern = regexp.MustCompile(`\b[A-Z]{1,5}\b`)
+	// moneyPattern matches amounts such as $1.2 billion, US$300m or €50 million
+	moneyPattern = regexp.MustCompile(`(?:US\$|\$|€|£)\s?\d[\d,]*(?:\.\d+)?(?:\s?(?:trillion|billion|million|thousand|bn|mn|tn|[mbk])\b)?`)
+	// suffixPattern matches a capitalised name ending in a corporate suffix
+	suffixPattern = regexp.MustCompile(`\b(?:[A-Z][\w&'-]*\s){1,4}(?:Inc|Corp|Corporation|Co|Ltd|LLC|plc|PLC|Holdings|Group|Bancorp|AG|SA|NV|LP)\b\.?`)
+)
+
+// ambiguousSymbols are tickers that are also ordinary words or common
+// abbreviations. Like one-letter tickers they are only read as symbols
+// with context: in parentheses, or next to the company's name.
+var ambiguousSymbols = map[string]bool{
+	"ALL": true, "ARE": true, "BIG": true, "CAT": true, "COST": true, "DE": true,
+	"DIS": true, "GD": true, "GE": true, "GM": true, "HD": true, "IT": true,
+	"KEY": true, "LIN": true, "LOW": true, "MA": true, "MET": true, "MO": true,
+	"NOW": true, "ON": true, "PG": true, "PM": true, "SO": true, "UPS": true,
+	"USB": true,
+}
+
+// Confidence of each kind of match.
+const (
+	listedConfidence    = 0.95 // cashtag or exchange prefix
+	namedConfidence     = 0.9  // dictionary name, or ticker next to it
+	bareConfidence      = 0.75 // dictionary ticker on its own
+	unlistedConfidence  = 0.6  // cashtag or exchange prefix not in the dictionary
+	shortNameConfidence = 0.7  // one-word dictionary name
+	suffixConfidence    = 0.6  // name with a corporate suffix, not in the dictionary
+	moneyConfidence     = 0.9
+)
+
+// Extract finds entities in text with the default dictionary.
+func Extract(text string) []models.Entity {
+	return defaultDictionary.Extract(text)
+}
+
+// Symbols returns the tickers of the companies entities refer to, with
+// the default dictionary.
+func Symbols(found []models.Entity) []string {
+	return defaultDictionary.Symbols(found)
+}
+
+// Extract finds the companies, tickers and money amounts in text. Every
+// entity is reported once, at its first position, with StartPos and EndPos
+// as byte offsets into text.
+func (d *Dictionary) Extract(text string) []models.Entity {
+	var found entitySet
+
+	// Company names first: their spans keep the tickers and suffix names
+	// inside them from matching again, and they give ambiguous tickers
+	// their context
+	named := make(map[string]bool)
+	var taken []span
+	for _, entry := range d.names {
+		for _, at := range wordIndexes(text, entry.name) {
+			s := span{at, at + len(entry.name)}
+			if s.overlaps(taken) {
+				continue
+			}
+			taken = append(taken, s)
+			named[entry.company.Symbol] = true
+			confidence := namedConfidence
+			if !strings.ContainsAny(entry.name, " .&-") && entry.name != entry.company.Symbol {
+				confidence = shortNameConfidence
+			}
+			found.add(TypeOrg, entry.company.Name(), confidence, s)
+		}
+	}
+
+	for _, pattern := range []*regexp.Regexp{cashtagPattern, listingPattern} {
+		for _, m := range pattern.FindAllStringSubmatchIndex(text, -1) {
+			symbol, err := symbols.Normalize(text[m[2]:m[3]])
+			if err != nil {
+				continue
+			}
+			confidence := unlistedConfidence
+			if _, ok := d.companies[symbol]; ok {
+				confidence = listedConfidence
+			}
+			s := span{m[0], m[1]}
+			taken = append(taken, s)
+			found.add(TypeSymbol, symbol, confidence, s)
+		}
+	}
+
+	shouting := isShouting(text)
+	for _, m := range barePattern.FindAllStringIndex(text, -1) {
+		s := span{m[0], m[1]}
+		word := text[m[0]:m[1]]
+		company, ok := d.companies[word]
+		if !ok || s.overlaps(taken) {
+			continue
+		}
+		parenthesised := m[0] > 0 && m[1] < len(text) && text[m[0]-1] == '(' && text[m[1]] == ')'
+		confidence := bareConfidence
+		if parenthesised || named[company.Symbol] {
+			confidence = namedConfidence
+		} else if shouting || len(word) == 1 || ambiguousSymbols[word] {
+			continue
+		}
+		found.add(TypeSymbol, company.Symbol, confidence, s)
+	}
+
+	for _, m := range suffixPattern.FindAllStringIndex(text, -1) {
+		s := span{m[0], m[1]}
+		if s.overlaps(taken) {
+			continue
+		}
+		name := strings.TrimPrefix(text[m[0]:m[1]], "The ")
+		found.add(TypeOrg, name, suffixConfidence, s)
+	}
+
+	for _, m := range moneyPattern.FindAllStringIndex(text, -1) {
+		found.add(TypeMoney, strings.TrimSpace(text[m[0]:m[1]]), moneyConfidence, span{m[0], m[1]})
+	}
+
+	return found.sorted()
+}
+
+// Symbols returns the tickers of the companies entities refer to: the
+// symbols found and the dictionary companies named, in order of position.
+func (d *Dictionary) Symbols(found []models.Entity) []string {
+	byName := make(map[string]string, len(d.companies))
+	for symbol, company := range d.companies {
+		byName[company.Name()] = symbol
+	}
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, entity := range found {
+		var symbol string
+		switch entity.Type {
+		case TypeSymbol:
+			symbol = entity.Name
+		case TypeOrg:
+			symbol = byName[entity.Name]
+		}
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			result = append(result, symbol)
+		}
+	}
+	return result
+}
+
+type span struct{ start, end int }
+
+func (s span) overlaps(spans []span) bool {
+	for _, other := range spans {
+		if s.start < other.end && other.start < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// entitySet keeps one entity per type and name.
+type entitySet struct {
+	entities []models.Entity
+	index    map[string]int
+}
+
+func (e *entitySet) add(kind, name string, confidence float64, s span) {
+	if e.index == nil {
+		e.index = make(map[string]int)
+	}
+	key := kind + "\x00" + name
+	if i, ok := e.index[key]; ok {
+		existing := &e.entities[i]
+		if confidence > existing.Confidence {
+			existing.Confidence = confidence
+		}
+		if s.start < existing.StartPos {
+			existing.StartPos, existing.EndPos = s.start, s.end
+		}
+		return
+	}
+	e.index[key] = len(e.entities)
+	e.entities = append(e.entities, models.Entity{Name: name, Type: kind, Confidence: confidence, StartPos: s.start, EndPos: s.end})
+}
+
+func (e *entitySet) sorted() []models.Entity {
+	sort.SliceStable(e.entities, func(i, j int) bool { return e.entities[i].StartPos < e.entities[j].StartPos })
+	return e.entities
+}
+
+// wordIndexes returns where name occurs in text as whole words.
+func wordIndexes(text, name string) []int {
+	var indexes []int
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], name)
+		if i < 0 {
+			break
+		}
+		start := offset + i
+		end := start + len(name)
+		if isBoundary(text, start, true) && isBoundary(text, end, false) {
+			indexes = append(indexes, start)
+		}
+		offset = start + 1
+	}
+	return indexes
+}
+
+// isBoundary reports whether a word may begin (before) or end at offset.
+func isBoundary(text string, offset int, before bool) bool {
+	var r rune
+	if before {
+		if offset == 0 {
+			return true
+		}
+		r, _ = utf8.DecodeLastRuneInString(text[:offset])
+	} else {
+		if offset >= len(text) {
+			return true
+		}
+		r, _ = utf8.DecodeRuneInString(text[offset:])
+	}
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// isShouting reports whether text is mostly upper case, as some headlines
+// are, where an upper case word says nothing about being a ticker.
+func isShouting(text string) bool {
+	upper, letters := 0, 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= 20 && upper*10 > letters*6
+}
@@ -0,0 +1,265 @@
+package entities
+
+import (
+	"context"
+	"crypto/md5"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// Resolver maps extracted names and tickers to the canonical company
+// records in storage, so documents about one issuer carry the same
+// company ID whichever way they name it.
+type Resolver struct {
+	store storage.Storage
+
+	mu       sync.RWMutex
+	byTicker map[string]*models.Company
+	byCIK    map[string]*models.Company
+	byLEI    map[string]*models.Company
+	byName   map[string]*models.Company
+}
+
+func NewResolver(store storage.Storage) *Resolver {
+	return &Resolver{
+		store:    store,
+		byTicker: make(map[string]*models.Company),
+		byCIK:    make(map[string]*models.Company),
+		byLEI:    make(map[string]*models.Company),
+		byName:   make(map[string]*models.Company),
+	}
+}
+
+// Load reads the stored companies.
+func (r *Resolver) Load(ctx context.Context) error {
+	companies, err := r.store.ListCompanies(ctx)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, company := range companies {
+		r.index(company)
+	}
+	return nil
+}
+
+// Resolve returns the IDs of the companies that found and symbols refer
+// to, in order of first mention.
+func (r *Resolver) Resolve(found []models.Entity, syms []string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var ids []string
+	add := func(company *models.Company) {
+		if company != nil && !seen[company.ID] {
+			seen[company.ID] = true
+			ids = append(ids, company.ID)
+		}
+	}
+	for _, entity := range found {
+		switch entity.Type {
+		case TypeSymbol:
+			add(r.byTicker[entity.Name])
+		case TypeOrg:
+			add(r.byName[normalizeName(entity.Name)])
+		}
+	}
+	for _, symbol := range syms {
+		if normalized, err := symbols.Normalize(symbol); err == nil {
+			add(r.byTicker[normalized])
+		}
+	}
+	return ids
+}
+
+// Merge folds what updates know about companies into the stored records:
+// each is matched by LEI, CIK, ticker and then name, fills in identifiers
+// the record lacks and adds its names as aliases; an update matching no
+// record creates one. A company listed under a new ticker keeps the old
+// one as an alias, and takes the ticker from any record that had it.
+func (r *Resolver) Merge(ctx context.Context, updates []*models.Company) error {
+	r.mu.Lock()
+	changed := make(map[string]*models.Company)
+	now := time.Now().UTC()
+	for _, update := range updates {
+		if update.Ticker != "" {
+			ticker, err := symbols.Normalize(update.Ticker)
+			if err != nil {
+				continue
+			}
+			update.Ticker = ticker
+		}
+
+		company := r.match(update)
+		if company == nil {
+			company = &models.Company{Name: update.Name, Ticker: update.Ticker, CIK: update.CIK, LEI: update.LEI}
+			company.ID = companyID(company)
+			// Left for apply, which takes the ticker from a record holding it
+			company.Ticker = ""
+			changed[company.ID] = company
+		}
+		if r.apply(company, update, changed) {
+			company.UpdatedAt = now
+			changed[company.ID] = company
+		}
+		r.index(company)
+	}
+
+	batch := make([]*models.Company, 0, len(changed))
+	for _, company := range changed {
+		if company.UpdatedAt.IsZero() {
+			company.UpdatedAt = now
+		}
+		copied := *company
+		batch = append(batch, &copied)
+	}
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+	return r.store.SaveCompanies(ctx, batch)
+}
+
+// match finds the record an update describes; the caller holds r.mu.
+func (r *Resolver) match(update *models.Company) *models.Company {
+	if company := r.byLEI[update.LEI]; update.LEI != "" && company != nil {
+		return company
+	}
+	if company := r.byCIK[update.CIK]; update.CIK != "" && company != nil {
+		return company
+	}
+	// A ticker or name held by a record with other identifiers belongs to
+	// a different issuer now
+	for _, company := range []*models.Company{r.byTicker[update.Ticker], r.byName[normalizeName(update.Name)]} {
+		if company != nil && !conflicts(company, update) {
+			return company
+		}
+	}
+	return nil
+}
+
+func conflicts(company, update *models.Company) bool {
+	return (company.CIK != "" && update.CIK != "" && company.CIK != update.CIK) ||
+		(company.LEI != "" && update.LEI != "" && company.LEI != update.LEI)
+}
+
+// apply copies an update into company, reporting whether it changed; the
+// caller holds r.mu.
+func (r *Resolver) apply(company, update *models.Company, changed map[string]*models.Company) bool {
+	modified := false
+	if company.CIK == "" && update.CIK != "" {
+		company.CIK = update.CIK
+		modified = true
+	}
+	if company.LEI == "" && update.LEI != "" {
+		company.LEI = update.LEI
+		modified = true
+	}
+	if company.Name == "" && update.Name != "" {
+		company.Name = update.Name
+		modified = true
+	}
+	if update.Ticker != "" && update.Ticker != company.Ticker {
+		if other := r.byTicker[update.Ticker]; other != nil && other != company {
+			other.Ticker = ""
+			other.UpdatedAt = time.Now().UTC()
+			changed[other.ID] = other
+		}
+		if company.Ticker != "" {
+			company.Aliases = addAlias(company, company.Ticker)
+		}
+		company.Ticker = update.Ticker
+		modified = true
+	}
+	for _, name := range append([]string{update.Name}, update.Aliases...) {
+		if aliases := addAlias(company, name); len(aliases) != len(company.Aliases) {
+			company.Aliases = aliases
+			modified = true
+		}
+	}
+	return modified
+}
+
+// addAlias returns company's aliases with name added, unless it already
+// goes by it.
+func addAlias(company *models.Company, name string) []string {
+	if name == "" || strings.EqualFold(name, company.Name) || strings.EqualFold(name, company.Ticker) {
+		return company.Aliases
+	}
+	for _, alias := range company.Aliases {
+		if strings.EqualFold(alias, name) {
+			return company.Aliases
+		}
+	}
+	return append(company.Aliases, name)
+}
+
+// index adds company to the lookup maps; the caller holds r.mu. Entries
+// of identifiers the company no longer holds are left to be overwritten.
+func (r *Resolver) index(company *models.Company) {
+	if company.Ticker != "" {
+		r.byTicker[company.Ticker] = company
+	}
+	if company.CIK != "" {
+		r.byCIK[company.CIK] = company
+	}
+	if company.LEI != "" {
+		r.byLEI[company.LEI] = company
+	}
+	for _, name := range append([]string{company.Name}, company.Aliases...) {
+		key := normalizeName(name)
+		if key == "" {
+			continue
+		}
+		if _, taken := r.byName[key]; !taken {
+			r.byName[key] = company
+		}
+	}
+}
+
+// companyID derives the ID of a new company from its strongest identifier.
+func companyID(company *models.Company) string {
+	key := "name:" + normalizeName(company.Name)
+	switch {
+	case company.CIK != "":
+		key = "cik:" + company.CIK
+	case company.LEI != "":
+		key = "lei:" + company.LEI
+	case company.Ticker != "":
+		key = "ticker:" + company.Ticker
+	}
+	hash := md5.Sum([]byte(key))
+	return fmt.Sprintf("company-%x", hash[:8])
+}
+
+// corporateSuffixes are dropped from the end of names before comparing.
+var corporateSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "corp": true, "corporation": true, "co": true,
+	"company": true, "ltd": true, "limited": true, "plc": true, "llc": true, "lp": true,
+	"group": true, "holdings": true, "sa": true, "ag": true, "nv": true,
+}
+
+// normalizeName reduces a company name to the words that identify it:
+// "The Goldman Sachs Group, Inc." becomes "goldman sachs".
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	for len(words) > 1 && corporateSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,155 @@
+package entities
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// secTicker is one entry of the SEC's company_tickers.json, an object keyed
+// by row number.
+type secTicker struct {
+	CIK    int64  `json:"cik_str"`
+	Ticker string `json:"ticker"`
+	Title  string `json:"title"`
+}
+
+// Sync merges the dictionary's companies into the stored ones, then adds
+// the CIKs the SEC lists for their tickers and the identifiers of the
+// companies file. A failing SEC download is reported but leaves the rest
+// of the sync in place.
+func (r *Resolver) Sync(ctx context.Context, cfg config.EntitiesConfig, dict *Dictionary) error {
+	updates := make([]*models.Company, 0, len(dict.companies))
+	for _, company := range dict.companies {
+		update := &models.Company{Ticker: company.Symbol, Name: company.Name()}
+		if len(company.Names) > 1 {
+			update.Aliases = append([]string(nil), company.Names[1:]...)
+		}
+		updates = append(updates, update)
+	}
+	if err := r.Merge(ctx, updates); err != nil {
+		return err
+	}
+
+	var secErr error
+	if cfg.SECTickersURL != "" {
+		tickers, err := fetchSECTickers(ctx, cfg.SECTickersURL, cfg.UserAgent)
+		if err != nil {
+			secErr = err
+		} else if err := r.Merge(ctx, r.known(tickers)); err != nil {
+			return err
+		}
+	}
+
+	if cfg.CompaniesFile != "" {
+		companies, err := loadCompaniesFile(cfg.CompaniesFile)
+		if err != nil {
+			return err
+		}
+		if err := r.Merge(ctx, companies); err != nil {
+			return err
+		}
+	}
+	return secErr
+}
+
+// known keeps the SEC entries for tickers the resolver already has; the
+// full list runs to ten thousand registrants, most never in the news read.
+func (r *Resolver) known(tickers []secTicker) []*models.Company {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var updates []*models.Company
+	for _, entry := range tickers {
+		ticker, err := symbols.From(entry.Ticker, symbols.SEC)
+		if err != nil || r.byTicker[ticker] == nil {
+			continue
+		}
+		updates = append(updates, &models.Company{
+			Ticker: ticker,
+			CIK:    fmt.Sprintf("%010d", entry.CIK),
+			Name:   entry.Title,
+		})
+	}
+	return updates
+}
+
+func fetchSECTickers(ctx context.Context, url, userAgent string) ([]secTicker, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch SEC tickers: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("SEC tickers returned status %d", resp.StatusCode)
+	}
+
+	var rows map[string]secTicker
+	if err := json.NewDecoder(resp.Body).Decode(&rows); err != nil {
+		return nil, fmt.Errorf("failed to decode SEC tickers: %w", err)
+	}
+	tickers := make([]secTicker, 0, len(rows))
+	for _, row := range rows {
+		tickers = append(tickers, row)
+	}
+	return tickers, nil
+}
+
+// loadCompaniesFile reads a CSV of "ticker,cik,lei,name[,alias...]" rows;
+// any of ticker, CIK and LEI may be empty.
+func loadCompaniesFile(path string) ([]*models.Company, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open companies file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse companies file: %w", err)
+	}
+
+	var companies []*models.Company
+	for _, row := range rows {
+		if len(row) < 4 {
+			continue
+		}
+		company := &models.Company{
+			Ticker: strings.TrimSpace(row[0]),
+			CIK:    strings.TrimSpace(row[1]),
+			LEI:    strings.ToUpper(strings.TrimSpace(row[2])),
+			Name:   strings.TrimSpace(row[3]),
+		}
+		if n := len(company.CIK); n > 0 && n < 10 {
+			company.CIK = strings.Repeat("0", 10-n) + company.CIK
+		}
+		for _, alias := range row[4:] {
+			if alias = strings.TrimSpace(alias); alias != "" {
+				company.Aliases = append(company.Aliases, alias)
+			}
+		}
+		companies = append(companies, company)
+	}
+	return companies, nil
+}
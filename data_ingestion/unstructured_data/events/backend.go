@@ -0,0 +1,186 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// Classifier labels a text with the credit events it reports.
+type Classifier interface {
+	Classify(ctx context.Context, text string) ([]models.CreditEvent, error)
+}
+
+// Rules classifies with the package's patterns.
+type Rules struct {
+	MinConfidence float64
+}
+
+func (r Rules) Classify(_ context.Context, text string) ([]models.CreditEvent, error) {
+	return Classify(text, r.MinConfidence), nil
+}
+
+// New builds the classifier cfg selects.
+func New(cfg config.CreditEventsConfig) (Classifier, error) {
+	rules := Rules{MinConfidence: cfg.MinConfidence}
+	switch cfg.Backend {
+	case "", "rules":
+		return rules, nil
+	case "http":
+		return &Combined{Rules: rules, Service: NewService(cfg), RetryAfter: cfg.RetryAfter}, nil
+	}
+	return nil, fmt.Errorf("unknown credit event backend %q", cfg.Backend)
+}
+
+// Service classifies with an external model. It is posted
+//
+//	{"text": "...", "labels": ["m_and_a", "refinancing", ...]}
+//
+// and answers with a probability for each label:
+//
+//	{"model": "credit-events-v2", "scores": {"downgrade": 0.93, "refinancing": 0.12, ...}}
+type Service struct {
+	url           string
+	apiKey        string
+	client        *http.Client
+	maxChars      int
+	minConfidence float64
+}
+
+type serviceRequest struct {
+	Text   string   `json:"text"`
+	Labels []string `json:"labels"`
+}
+
+type serviceResponse struct {
+	Model  string             `json:"model"`
+	Scores map[string]float64 `json:"scores"`
+}
+
+func NewService(cfg config.CreditEventsConfig) *Service {
+	return &Service{
+		url:           cfg.URL,
+		apiKey:        cfg.APIKey,
+		client:        &http.Client{Timeout: cfg.Timeout},
+		maxChars:      cfg.MaxChars,
+		minConfidence: cfg.MinConfidence,
+	}
+}
+
+func (s *Service) Classify(ctx context.Context, text string) ([]models.CreditEvent, error) {
+	body, err := json.Marshal(serviceRequest{Text: truncate(text, s.maxChars), Labels: Types})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode classification request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("classification service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("classification service returned status %d", resp.StatusCode)
+	}
+
+	var result serviceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode classification response: %w", err)
+	}
+	var found []models.CreditEvent
+	for _, event := range Types {
+		if score := result.Scores[event]; score >= s.minConfidence {
+			found = append(found, models.CreditEvent{Type: event, Confidence: round(score), Model: result.Model})
+		}
+	}
+	sortEvents(found)
+	return found, nil
+}
+
+// Combined labels with both the rules and the service, keeping the higher
+// confidence of the two for each event and the rules' evidence. While the
+// service fails the rules label alone, and the service is tried again
+// after RetryAfter.
+type Combined struct {
+	Rules      Classifier
+	Service    Classifier
+	RetryAfter time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func (c *Combined) Classify(ctx context.Context, text string) ([]models.CreditEvent, error) {
+	found, err := c.Rules.Classify(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	down := time.Now().Before(c.downUntil)
+	c.mu.Unlock()
+	if down {
+		return found, nil
+	}
+
+	scored, err := c.Service.Classify(ctx, text)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		c.mu.Lock()
+		if !time.Now().Before(c.downUntil) {
+			log.Printf("Credit event service failed, using the rules for %v: %v", c.RetryAfter, err)
+			c.downUntil = time.Now().Add(c.RetryAfter)
+		}
+		c.mu.Unlock()
+		return found, nil
+	}
+
+	index := make(map[string]int, len(found))
+	for i, event := range found {
+		index[event.Type] = i
+	}
+	for _, event := range scored {
+		i, ok := index[event.Type]
+		if !ok {
+			found = append(found, event)
+			continue
+		}
+		if event.Confidence > found[i].Confidence {
+			found[i].Confidence = event.Confidence
+			found[i].Model = event.Model
+		}
+	}
+	sortEvents(found)
+	return found, nil
+}
+
+// truncate cuts text to at most max bytes, on a rune boundary.
+func truncate(text string, max int) string {
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max]
+}
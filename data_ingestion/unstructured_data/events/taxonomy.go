@@ -0,0 +1,174 @@
+// Package events labels documents with the credit events they report, from
+// a fixed taxonomy the scoring engine reads out of the record metadata.
+package events
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// The credit event taxonomy.
+const (
+	EarningsMiss       = "earnings_miss"
+	CovenantBreach     = "covenant_breach"
+	Downgrade          = "downgrade"
+	Refinancing        = "refinancing"
+	Layoffs            = "layoffs"
+	MergerAcquisition  = "m_and_a"
+	FraudInvestigation = "fraud_investigation"
+	Default            = "default"
+)
+
+// Types lists the taxonomy in order of severity, the most severe last.
+var Types = []string{
+	MergerAcquisition, Refinancing, Layoffs, EarningsMiss,
+	Downgrade, FraudInvestigation, CovenantBreach, Default,
+}
+
+// RulesModel is the Model of events found by the rules.
+const RulesModel = "rules"
+
+// rule is one pattern signalling an event. The weights of the distinct
+// rules of an event that match combine as independent evidence, so two
+// weak signals together can pass the threshold one would not.
+type rule struct {
+	event   string
+	weight  float64
+	pattern *regexp.Regexp
+}
+
+var rules = []rule{
+	{EarningsMiss, 0.7, regexp.MustCompile(`(?i)\b(miss(es|ed)?|fell short of|falls? short of|below|trail(s|ed)?)\b[^.]{0,40}\b(estimates?|expectations|consensus|forecasts?)\b`)},
+	{EarningsMiss, 0.5, regexp.MustCompile(`(?i)\b(lower(s|ed)?|cut(s|ting)?|slash(es|ed)?|withdr(aws?|ew|awn))\b[^.]{0,30}\b(guidance|outlook|forecast)\b`)},
+	{EarningsMiss, 0.7, regexp.MustCompile(`(?i)\bprofit warning\b|\bwarns? (on|of) (lower )?(profits?|earnings)\b`)},
+	{EarningsMiss, 0.4, regexp.MustCompile(`(?i)\b(net|quarterly|operating) loss(es)?\b[^.]{0,30}\b(widen(s|ed)?|deepen(s|ed)?)\b`)},
+
+	{CovenantBreach, 0.85, regexp.MustCompile(`(?i)\b(breach(es|ed)?|violat(e|es|ed|ion)|trip(s|ped)?|fail(s|ed)? to (meet|comply with))\b[^.]{0,40}\bcovenants?\b`)},
+	{CovenantBreach, 0.6, regexp.MustCompile(`(?i)\bcovenants?\b[^.]{0,30}\b(breach|violation|waiver|relief)\b`)},
+	{CovenantBreach, 0.7, regexp.MustCompile(`(?i)\bforbearance agreement\b|\bcovenant waiver\b|\bwaiver from (its )?lenders\b`)},
+
+	{Downgrade, 0.85, regexp.MustCompile(`(?i)\b(Moody'?s|S&P|Standard & Poor'?s|Fitch|DBRS)\b[^.]{0,80}\b(downgrade[sd]?|cut(s)?|lower(s|ed)?)\b`)},
+	{Downgrade, 0.8, regexp.MustCompile(`(?i)\b(downgrade[sd]?|cut(s)?|lower(s|ed)?)\b[^.]{0,40}\bcredit ratings?\b`)},
+	{Downgrade, 0.8, regexp.MustCompile(`(?i)\b(cut|downgraded?) to junk\b|\bjunk status\b|\bfallen angel\b`)},
+	{Downgrade, 0.5, regexp.MustCompile(`(?i)\bnegative outlook\b|\b(outlook|credit ?watch) (to |on )?negative\b|\bwatch negative\b`)},
+	{Downgrade, 0.3, regexp.MustCompile(`(?i)\bdowngrade[sd]?\b`)},
+
+	{Refinancing, 0.6, regexp.MustCompile(`(?i)\brefinanc(e|es|ed|ing)\b`)},
+	{Refinancing, 0.5, regexp.MustCompile(`(?i)\b(prices?|priced|launch(es|ed)?|issu(e|es|ed)|offering of|sells?|sold)\b[^.]{0,60}\b(notes|bonds|debentures)\b`)},
+	{Refinancing, 0.5, regexp.MustCompile(`(?i)\bamend(s|ed)? and extend(s|ed)?\b|\bmaturity extension\b|\bextend(s|ed)? (the |its )?maturit(y|ies)\b|\bnew (revolving )?credit (facility|agreement)\b`)},
+	{Refinancing, 0.6, regexp.MustCompile(`(?i)\bexchange offer\b|\btender offer for (its |the )?(notes|bonds)\b`)},
+
+	{Layoffs, 0.8, regexp.MustCompile(`(?i)\blay(s|ing)? off\b|\blaid off\b|\blayoffs?\b`)},
+	{Layoffs, 0.8, regexp.MustCompile(`(?i)\b(job|workforce|staff|headcount) (cuts?|reductions?)\b|\breduction in force\b|\bcut(s|ting)? (about |nearly |some )?[\d,]+ (jobs|positions|employees|workers)\b`)},
+	{Layoffs, 0.7, regexp.MustCompile(`(?i)\bWARN notice\b`)},
+
+	{MergerAcquisition, 0.7, regexp.MustCompile(`(?i)\b(to acquire|acquires|acquired by|to be acquired|acquisition of|completes acquisition|merger|merge with|definitive agreement|business combination|take[- ]private)\b`)},
+	{MergerAcquisition, 0.6, regexp.MustCompile(`(?i)\bagree(s|d)? to (buy|acquire|sell)\b|\btakeover\b|\b(leveraged )?buyout\b|\bdivest(s|ed|iture)\b|\bspin[- ]?off\b`)},
+
+	{FraudInvestigation, 0.6, regexp.MustCompile(`(?i)\b(SEC|DOJ|Justice Department|FBI|prosecutors?|regulators?|attorneys? general)\b[^.]{0,60}\b(investigat(e|es|ed|ing|ion)|probe[sd]?|subpoena(s|ed)?|charge[sd]?|indict(s|ed|ment))\b`)},
+	{FraudInvestigation, 0.6, regexp.MustCompile(`(?i)\bfraud\b|\baccounting irregularit(y|ies)\b|\bembezzl\w+|\bmisstat(ed|ement)\b|\brestat(e|es|ed|ement) (of )?(its )?(financial|earnings|results)|\bwhistleblower\b|\binsider trading\b|\bbribery\b|\bmoney laundering\b|\bponzi\b`)},
+	{FraudInvestigation, 0.3, regexp.MustCompile(`(?i)\bshort[- ]seller\b|\bshort report\b`)},
+
+	{Default, 0.9, regexp.MustCompile(`(?i)\bchapter (7|11|15)\b|\bbankruptcy (filing|protection|petition)\b|\bfiles? for bankruptcy\b|\bfiled for bankruptcy\b|\binsolvency\b|\bliquidation\b`)},
+	{Default, 0.85, regexp.MustCompile(`(?i)\b(miss(es|ed)?|skip(s|ped)?|fail(s|ed)? to (make|pay))\b[^.]{0,40}\b(interest|coupon|principal|debt|bond|loan) payments?\b`)},
+	{Default, 0.7, regexp.MustCompile(`(?i)\bdefault(s|ed)? on\b|\bin default\b|\bpayment default\b|\bselective default\b|\bevent of default\b`)},
+	{Default, 0.5, regexp.MustCompile(`(?i)\bgoing concern\b|\bdistressed exchange\b|\bdebt restructuring\b`)},
+	{Default, 0.4, regexp.MustCompile(`(?i)\bgrace period\b`)},
+}
+
+// negation matches, just before a rule's match, the words that deny it, as
+// in "no plans for layoffs" or "avoided default".
+var negation = regexp.MustCompile(`(?i)\b(no|not|never|denie[sd]|deny(ing)?|rule[sd]? out|avoid(s|ed|ing)?|avert(s|ed|ing)?|without|rather than)\b[^.,;]{0,25}$`)
+
+// maxEvidence caps the evidence sentence kept with an event.
+const maxEvidence = 300
+
+// Classify labels text with the events whose rules give them at least
+// minConfidence, most confident first.
+func Classify(text string, minConfidence float64) []models.CreditEvent {
+	type found struct {
+		miss     float64 // chance none of the matched rules is right
+		weight   float64 // of the strongest rule, whose sentence is the evidence
+		evidence string
+	}
+	byType := make(map[string]*found)
+	for _, r := range rules {
+		for _, m := range r.pattern.FindAllStringIndex(text, -1) {
+			start := sentenceStart(text, m[0])
+			if negation.MatchString(text[start:m[0]]) {
+				continue
+			}
+			f := byType[r.event]
+			if f == nil {
+				f = &found{miss: 1}
+				byType[r.event] = f
+			}
+			f.miss *= 1 - r.weight
+			if r.weight > f.weight {
+				f.weight = r.weight
+				f.evidence = evidence(text, start, m[1])
+			}
+			// A rule counts once however often it matches
+			break
+		}
+	}
+
+	var result []models.CreditEvent
+	for event, f := range byType {
+		confidence := 1 - f.miss
+		if confidence < minConfidence {
+			continue
+		}
+		result = append(result, models.CreditEvent{Type: event, Confidence: round(confidence), Evidence: f.evidence, Model: RulesModel})
+	}
+	sortEvents(result)
+	return result
+}
+
+// EventTypes returns the types of events.
+func EventTypes(found []models.CreditEvent) []string {
+	types := make([]string, 0, len(found))
+	for _, event := range found {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func sortEvents(found []models.CreditEvent) {
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Confidence != found[j].Confidence {
+			return found[i].Confidence > found[j].Confidence
+		}
+		return found[i].Type < found[j].Type
+	})
+}
+
+// sentenceStart returns where the sentence holding offset begins.
+func sentenceStart(text string, offset int) int {
+	start := strings.LastIndexAny(text[:offset], ".!?\n")
+	if start < 0 {
+		return 0
+	}
+	return start + 1
+}
+
+// evidence returns the sentence from start that holds a match ending at end.
+func evidence(text string, start, end int) string {
+	if stop := strings.IndexAny(text[end:], ".!?\n"); stop >= 0 {
+		end += stop + 1
+	} else {
+		end = len(text)
+	}
+	sentence := strings.TrimSpace(text[start:end])
+	if len(sentence) > maxEvidence {
+		sentence = strings.TrimSpace(truncate(sentence, maxEvidence)) + "..."
+	}
+	return sentence
+}
+
+func round(v float64) float64 {
+	return float64(int(v*1000+0.5)) / 1000
+}
@@ -0,0 +1,191 @@
+// Package extraction recovers the readable text of fetched documents: the
+// body of a news article page, stripped of navigation, ads and comments.
+package extraction
+
+import (
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// MinArticleLength is the shortest text, in characters, accepted as an article body.
+const MinArticleLength = 200
+
+// ErrNoArticle is returned when a page has no block of text long enough to be an article.
+var ErrNoArticle = errors.New("no article body found")
+
+var (
+	// unlikelyCandidates mark page furniture by class or id
+	unlikelyCandidates = regexp.MustCompile(`(?i)comment|share|social|related|promo|sidebar|footer|header|masthead|nav|menu|breadcrumb|subscribe|newsletter|signup|advert|\bads?\b|sponsor|cookie|paywall|modal|popup|outbrain|taboola`)
+	// likelyCandidates keep elements that would otherwise look unlikely, e.g. "article-header"
+	likelyCandidates = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+	positiveClass    = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text|blog`)
+	negativeClass    = regexp.MustCompile(`(?i)comment|meta|footer|footnote|sidebar|widget|caption|promo|related|share`)
+)
+
+// Article is the readable content of a page.
+type Article struct {
+	Title      string
+	Byline     string
+	Text       string
+	Paragraphs int
+	WordCount  int
+}
+
+// ExtractArticle finds the element holding the most paragraph text, scored
+// readability-style on text length, commas and link density, and returns its
+// paragraphs.
+func ExtractArticle(r io.Reader) (*Article, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	article := &Article{
+		Title:  metaContent(doc, `meta[property="og:title"]`, `meta[name="twitter:title"]`),
+		Byline: metaContent(doc, `meta[name="author"]`, `meta[property="article:author"]`),
+	}
+	if article.Title == "" {
+		article.Title = strings.TrimSpace(doc.Find("title").First().Text())
+	}
+	if article.Byline == "" {
+		article.Byline = collapse(doc.Find(`[rel="author"], [itemprop="author"]`).First().Text())
+	}
+
+	doc.Find("script, style, noscript, iframe, form, nav, header, footer, aside, svg, button, select").Remove()
+	doc.Find("[class], [id]").Each(func(_ int, s *goquery.Selection) {
+		if goquery.NodeName(s) == "body" || goquery.NodeName(s) == "article" {
+			return
+		}
+		marker := s.AttrOr("class", "") + " " + s.AttrOr("id", "")
+		if unlikelyCandidates.MatchString(marker) && !likelyCandidates.MatchString(marker) {
+			s.Remove()
+		}
+	})
+
+	top := topCandidate(doc)
+	if top == nil {
+		return nil, ErrNoArticle
+	}
+
+	var paragraphs []string
+	top.Find("p, h2, h3, h4, li, blockquote, pre").Each(func(_ int, s *goquery.Selection) {
+		// Nested blocks are read through their outermost block
+		if s.ParentsFiltered("p, li, blockquote").Length() > 0 {
+			return
+		}
+		text := collapse(s.Text())
+		if text == "" || (goquery.NodeName(s) == "li" && linkDensity(s) > 0.5) {
+			return
+		}
+		paragraphs = append(paragraphs, text)
+	})
+
+	article.Text = strings.Join(paragraphs, "\n\n")
+	if len(article.Text) < MinArticleLength {
+		return nil, ErrNoArticle
+	}
+	article.Paragraphs = len(paragraphs)
+	article.WordCount = len(strings.Fields(article.Text))
+	return article, nil
+}
+
+// topCandidate scores each paragraph's parent, and half as much its
+// grandparent, and returns the best scoring container.
+func topCandidate(doc *goquery.Document) *goquery.Selection {
+	type candidate struct {
+		selection *goquery.Selection
+		score     float64
+	}
+	candidates := make(map[*html.Node]*candidate)
+	var order []*html.Node
+
+	add := func(s *goquery.Selection, score float64) {
+		if s.Length() == 0 {
+			return
+		}
+		node := s.Get(0)
+		c, ok := candidates[node]
+		if !ok {
+			c = &candidate{selection: s, score: classWeight(s)}
+			if goquery.NodeName(s) == "article" {
+				c.score += 10
+			}
+			candidates[node] = c
+			order = append(order, node)
+		}
+		c.score += score
+	}
+
+	doc.Find("p, pre, td").Each(func(_ int, p *goquery.Selection) {
+		text := collapse(p.Text())
+		if len(text) < 25 {
+			return
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+		add(p.Parent(), score)
+		add(p.Parent().Parent(), score/2)
+	})
+
+	var best *candidate
+	for _, node := range order {
+		c := candidates[node]
+		c.score *= 1 - linkDensity(c.selection)
+		if best == nil || c.score > best.score {
+			best = c
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.selection
+}
+
+// classWeight favours containers named like article bodies and penalizes
+// ones named like comments or widgets.
+func classWeight(s *goquery.Selection) float64 {
+	weight := 0.0
+	for _, marker := range []string{s.AttrOr("class", ""), s.AttrOr("id", "")} {
+		if marker == "" {
+			continue
+		}
+		if positiveClass.MatchString(marker) {
+			weight += 25
+		}
+		if negativeClass.MatchString(marker) {
+			weight -= 25
+		}
+	}
+	return weight
+}
+
+// linkDensity is the share of an element's text that sits inside links.
+func linkDensity(s *goquery.Selection) float64 {
+	total := len(collapse(s.Text()))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	s.Find("a").Each(func(_ int, a *goquery.Selection) {
+		links += len(collapse(a.Text()))
+	})
+	return float64(links) / float64(total)
+}
+
+func metaContent(doc *goquery.Document, selectors ...string) string {
+	for _, selector := range selectors {
+		if content := strings.TrimSpace(doc.Find(selector).First().AttrOr("content", "")); content != "" {
+			return content
+		}
+	}
+	return ""
+}
+
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,674 @@
+package extraction
+
+import (
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	// ErrNotPDF is returned for input without a PDF header.
+	ErrNotPDF = errors.New("not a PDF document")
+	// ErrEncryptedPDF is returned for encrypted documents, which are not decrypted.
+	ErrEncryptedPDF = errors.New("PDF document is encrypted")
+	// ErrNoPDFText is returned when no page has text, as in scanned documents.
+	ErrNoPDFText = errors.New("PDF document has no extractable text")
+	// ErrPDFTooLarge is returned for documents whose streams decode to more
+	// than maxStreamBytes each or maxDecodedBytes together.
+	ErrPDFTooLarge = errors.New("PDF document decodes to too much data")
+)
+
+const (
+	// tableMinColumns and tableMinRows bound the smallest grid read as a table
+	tableMinColumns = 3
+	tableMinRows    = 2
+	// cellGap is the horizontal gap, in font sizes, that separates table
+	// cells rather than words
+	cellGap = 1.2
+	// maxFormDepth bounds nested form XObjects
+	maxFormDepth = 5
+	// maxContentDepth bounds arrays of content streams nested in each other
+	maxContentDepth = 16
+	// maxStreamBytes bounds one decoded stream and maxDecodedBytes all those
+	// of a document, so a small compressed stream cannot inflate without end
+	maxStreamBytes  = 64 << 20
+	maxDecodedBytes = 256 << 20
+)
+
+var numericCell = regexp.MustCompile(`^[-+(]?[$€£¥]?\d[\d,.]*[%x)]?\)?$`)
+
+// PDFDocument is the text of a PDF, page by page.
+type PDFDocument struct {
+	Title string
+	// Text is the pages' text separated by blank lines
+	Text      string
+	Pages     []PDFPage
+	WordCount int
+}
+
+// PDFPage is one page's text. Start and End are its byte offsets in the
+// document's Text.
+type PDFPage struct {
+	Number    int
+	Text      string
+	Start     int
+	End       int
+	WordCount int
+	Tables    []Table
+}
+
+// Table is a grid of cells recovered from text alignment, first row first.
+type Table [][]string
+
+// ExtractPDF reads the text of every page in reading order, top to bottom
+// and left to right. Rows of three or more cells separated by wide gaps are
+// also returned as tables; their lines carry tab-separated cells in the page
+// text. Text in multi-column layouts runs across the columns.
+func ExtractPDF(r io.Reader) (*PDFDocument, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := parsePDF(data)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &PDFDocument{}
+	if info := f.dict(f.trailer["Info"]); info != nil {
+		if title, ok := f.resolve(info["Title"]).(pdfString); ok {
+			doc.Title = strings.TrimSpace(textString(title))
+		}
+	}
+
+	interp := &pdfInterpreter{file: f, fonts: make(map[pdfRef]*pdfFont)}
+	var texts []string
+	offset := 0
+	for i, page := range f.pages() {
+		interp.fragments = interp.fragments[:0]
+		interp.run(f.streamData(page["Contents"]), f.resources(page), identity, 0)
+		text, tables := layoutPage(interp.fragments)
+
+		start := offset
+		offset += len(text) + len("\n\n")
+		texts = append(texts, text)
+		words := len(strings.Fields(text))
+		doc.Pages = append(doc.Pages, PDFPage{
+			Number:    i + 1,
+			Text:      text,
+			Start:     start,
+			End:       start + len(text),
+			WordCount: words,
+			Tables:    tables,
+		})
+		doc.WordCount += words
+	}
+
+	if f.tooLarge {
+		return nil, ErrPDFTooLarge
+	}
+	if doc.WordCount == 0 {
+		return nil, ErrNoPDFText
+	}
+	doc.Text = strings.Join(texts, "\n\n")
+	return doc, nil
+}
+
+// pages walks the page tree from the catalog, falling back to every page
+// object in object order when the tree is broken.
+func (f *pdfFile) pages() []pdfDict {
+	var pages []pdfDict
+	visited := make(map[pdfRef]bool)
+	var walk func(node interface{}, depth int)
+	walk = func(node interface{}, depth int) {
+		if ref, ok := node.(pdfRef); ok {
+			if visited[ref] {
+				return
+			}
+			visited[ref] = true
+		}
+		dict := f.dict(node)
+		if dict == nil || depth > 64 {
+			return
+		}
+		kids := f.array(dict["Kids"])
+		if dict["Type"] == pdfName("Page") || kids == nil {
+			pages = append(pages, dict)
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, depth+1)
+		}
+	}
+	if root := f.dict(f.trailer["Root"]); root != nil {
+		walk(root["Pages"], 0)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	nums := make([]int, 0, len(f.objects))
+	for num := range f.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		if dict, ok := f.objects[num].(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, dict)
+		}
+	}
+	return pages
+}
+
+// resources returns a page's resources, inherited from its ancestors if the
+// page has none of its own.
+func (f *pdfFile) resources(page pdfDict) pdfDict {
+	node := page
+	for depth := 0; node != nil && depth < 64; depth++ {
+		if resources := f.dict(node["Resources"]); resources != nil {
+			return resources
+		}
+		node = f.dict(node["Parent"])
+	}
+	return nil
+}
+
+type matrix [6]float64
+
+var identity = matrix{1, 0, 0, 1, 0, 0}
+
+// mul returns m × n.
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2], m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2], m[2]*n[1] + m[3]*n[3],
+		m[4]*n[0] + m[5]*n[2] + n[4], m[4]*n[1] + m[5]*n[3] + n[5],
+	}
+}
+
+func translate(x, y float64) matrix {
+	return matrix{1, 0, 0, 1, x, y}
+}
+
+// textFragment is a run of text shown by one operator, in device space.
+type textFragment struct {
+	x, y, end, size float64
+	text            string
+}
+
+// graphicsState is the part of the PDF graphics state that places text.
+type graphicsState struct {
+	ctm, tm, lm matrix
+	font        *pdfFont
+	size        float64
+	charSpace   float64
+	wordSpace   float64
+	scale       float64
+	leading     float64
+	rise        float64
+}
+
+type pdfInterpreter struct {
+	file      *pdfFile
+	fonts     map[pdfRef]*pdfFont
+	fragments []textFragment
+}
+
+// run executes a content stream, collecting the text it shows.
+func (in *pdfInterpreter) run(content []byte, resources pdfDict, ctm matrix, depth int) {
+	f := in.file
+	st := graphicsState{ctm: ctm, tm: identity, lm: identity, scale: 1}
+	var stack []graphicsState
+	var operands []interface{}
+	l := &pdfLexer{data: content}
+
+	num := func(i int) float64 {
+		if i < len(operands) {
+			if n, ok := operands[i].(float64); ok {
+				return n
+			}
+		}
+		return 0
+	}
+	nextLine := func() {
+		st.lm = translate(0, -st.leading).mul(st.lm)
+		st.tm = st.lm
+	}
+	show := func(s pdfString) {
+		if st.font == nil {
+			return
+		}
+		text, width, codes, spaces := st.font.decode([]byte(s))
+		m := st.tm.mul(st.ctm)
+		advance := (width*st.size + st.charSpace*float64(codes) + st.wordSpace*float64(spaces)) * st.scale
+		if strings.TrimSpace(text) != "" {
+			x := st.rise*m[2] + m[4]
+			in.fragments = append(in.fragments, textFragment{
+				x:    x,
+				y:    st.rise*m[3] + m[5],
+				end:  x + advance*math.Hypot(m[0], m[1]),
+				size: st.size * math.Hypot(m[2], m[3]),
+				text: text,
+			})
+		}
+		st.tm = translate(advance, 0).mul(st.tm)
+	}
+
+	for {
+		token, err := l.next()
+		if err != nil {
+			return
+		}
+		op, ok := token.(pdfKeyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+		if op == "[" || op == "<<" {
+			v, _ := l.compose(token)
+			operands = append(operands, v)
+			continue
+		}
+
+		switch op {
+		case "BI":
+			l.skipInlineImage()
+		case "q":
+			stack = append(stack, st)
+		case "Q":
+			if n := len(stack); n > 0 {
+				st, stack = stack[n-1], stack[:n-1]
+			}
+		case "cm":
+			if len(operands) == 6 {
+				st.ctm = matrix{num(0), num(1), num(2), num(3), num(4), num(5)}.mul(st.ctm)
+			}
+		case "BT":
+			st.tm, st.lm = identity, identity
+		case "Tf":
+			if len(operands) == 2 {
+				if name, ok := operands[0].(pdfName); ok {
+					st.font = in.font(resources, name)
+				}
+				st.size = num(1)
+			}
+		case "Tc":
+			st.charSpace = num(0)
+		case "Tw":
+			st.wordSpace = num(0)
+		case "Tz":
+			st.scale = num(0) / 100
+		case "TL":
+			st.leading = num(0)
+		case "Ts":
+			st.rise = num(0)
+		case "Td":
+			st.lm = translate(num(0), num(1)).mul(st.lm)
+			st.tm = st.lm
+		case "TD":
+			st.leading = -num(1)
+			st.lm = translate(num(0), num(1)).mul(st.lm)
+			st.tm = st.lm
+		case "Tm":
+			if len(operands) == 6 {
+				st.lm = matrix{num(0), num(1), num(2), num(3), num(4), num(5)}
+				st.tm = st.lm
+			}
+		case "T*":
+			nextLine()
+		case "Tj":
+			if len(operands) > 0 {
+				if s, ok := operands[0].(pdfString); ok {
+					show(s)
+				}
+			}
+		case "'":
+			nextLine()
+			if len(operands) > 0 {
+				if s, ok := operands[0].(pdfString); ok {
+					show(s)
+				}
+			}
+		case "\"":
+			if len(operands) == 3 {
+				st.wordSpace, st.charSpace = num(0), num(1)
+				nextLine()
+				if s, ok := operands[2].(pdfString); ok {
+					show(s)
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				items, _ := operands[0].(pdfArray)
+				for _, item := range items {
+					switch item := item.(type) {
+					case pdfString:
+						show(item)
+					case float64:
+						st.tm = translate(-item/1000*st.size*st.scale, 0).mul(st.tm)
+					}
+				}
+			}
+		case "Do":
+			if len(operands) > 0 && depth < maxFormDepth {
+				name, _ := operands[0].(pdfName)
+				xobject, ok := f.resolve(f.dict(resources["XObject"])[name]).(pdfStream)
+				if ok && xobject.dict["Subtype"] == pdfName("Form") {
+					formCTM := st.ctm
+					if m := f.array(xobject.dict["Matrix"]); len(m) == 6 {
+						var fm matrix
+						for i := range fm {
+							fm[i] = f.number(m[i], 0)
+						}
+						formCTM = fm.mul(formCTM)
+					}
+					formResources := f.dict(xobject.dict["Resources"])
+					if formResources == nil {
+						formResources = resources
+					}
+					in.run(f.streamData(xobject), formResources, formCTM, depth+1)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+func (in *pdfInterpreter) font(resources pdfDict, name pdfName) *pdfFont {
+	ref, isRef := in.file.dict(resources["Font"])[name].(pdfRef)
+	if isRef {
+		if font, ok := in.fonts[ref]; ok {
+			return font
+		}
+	}
+	dict := in.file.dict(in.file.dict(resources["Font"])[name])
+	if dict == nil {
+		return nil
+	}
+	font := in.file.loadFont(dict)
+	if isRef {
+		in.fonts[ref] = font
+	}
+	return font
+}
+
+// layoutPage groups fragments into lines by baseline, orders them and finds
+// the tables among the lines.
+func layoutPage(fragments []textFragment) (string, []Table) {
+	if len(fragments) == 0 {
+		return "", nil
+	}
+	sorted := append([]textFragment{}, fragments...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].y > sorted[j].y })
+
+	type line struct {
+		y, size float64
+		parts   []textFragment
+	}
+	var lines []*line
+	for _, frag := range sorted {
+		if n := len(lines); n > 0 {
+			last := lines[n-1]
+			if math.Abs(last.y-frag.y) <= math.Max(last.size, frag.size)/2 {
+				last.parts = append(last.parts, frag)
+				last.size = math.Max(last.size, frag.size)
+				continue
+			}
+		}
+		lines = append(lines, &line{y: frag.y, size: frag.size, parts: []textFragment{frag}})
+	}
+
+	cells := make([][]string, len(lines))
+	for i, ln := range lines {
+		sort.SliceStable(ln.parts, func(a, b int) bool { return ln.parts[a].x < ln.parts[b].x })
+		var cell strings.Builder
+		for j, part := range ln.parts {
+			text := part.text
+			if j > 0 {
+				gap := part.x - ln.parts[j-1].end
+				switch {
+				case gap > cellGap*ln.size:
+					cells[i] = append(cells[i], collapse(cell.String()))
+					cell.Reset()
+				case gap > 0.1*ln.size && !strings.HasSuffix(cell.String(), " ") && !strings.HasPrefix(text, " "):
+					cell.WriteByte(' ')
+				}
+			}
+			cell.WriteString(text)
+		}
+		cells[i] = append(cells[i], collapse(cell.String()))
+	}
+
+	inTable := make([]bool, len(lines))
+	var tables []Table
+	for i := 0; i < len(lines); {
+		j := i
+		numeric := 0
+		for j < len(lines) && len(cells[j]) >= tableMinColumns {
+			for _, cell := range cells[j] {
+				if numericCell.MatchString(cell) {
+					numeric++
+					break
+				}
+			}
+			j++
+		}
+		// Rows of prose side by side are not a table; some rows must hold figures
+		if j-i >= tableMinRows && numeric*2 >= j-i {
+			table := make(Table, 0, j-i)
+			for k := i; k < j; k++ {
+				table = append(table, cells[k])
+				inTable[k] = true
+			}
+			tables = append(tables, table)
+		}
+		if j == i {
+			j++
+		}
+		i = j
+	}
+
+	var b strings.Builder
+	for i, ln := range lines {
+		if i > 0 {
+			b.WriteByte('\n')
+			// A gap wider than a line and a half starts a new paragraph
+			if lines[i-1].y-ln.y > 1.8*math.Max(ln.size, lines[i-1].size) {
+				b.WriteByte('\n')
+			}
+		}
+		separator := " "
+		if inTable[i] {
+			separator = "\t"
+		}
+		b.WriteString(strings.Join(cells[i], separator))
+	}
+	return b.String(), tables
+}
+
+// pdfFont maps a font's character codes to text and widths.
+type pdfFont struct {
+	codeBytes    int
+	toUnicode    *pdfCMap
+	differences  map[int]string
+	widths       map[int]float64
+	defaultWidth float64
+	composite    bool
+}
+
+func (f *pdfFile) loadFont(dict pdfDict) *pdfFont {
+	font := &pdfFont{codeBytes: 1, defaultWidth: 500, widths: make(map[int]float64)}
+	if stream, ok := f.resolve(dict["ToUnicode"]).(pdfStream); ok {
+		if data, err := f.decode(stream); err == nil {
+			font.toUnicode = parseCMap(data)
+		}
+	}
+
+	if dict["Subtype"] == pdfName("Type0") {
+		font.composite = true
+		font.codeBytes = 2
+		if font.toUnicode != nil && font.toUnicode.width > 0 {
+			font.codeBytes = font.toUnicode.width
+		}
+		if descendants := f.array(dict["DescendantFonts"]); len(descendants) > 0 {
+			cid := f.dict(descendants[0])
+			font.defaultWidth = f.number(cid["DW"], 1000)
+			// W holds "first [w1 w2 ...]" and "first last w" runs
+			w := f.array(cid["W"])
+			for i := 0; i+1 < len(w); {
+				first := int(f.number(w[i], 0))
+				if list := f.array(w[i+1]); list != nil {
+					for j, width := range list {
+						font.widths[first+j] = f.number(width, font.defaultWidth)
+					}
+					i += 2
+					continue
+				}
+				if i+2 >= len(w) {
+					break
+				}
+				last, width := int(f.number(w[i+1], 0)), f.number(w[i+2], font.defaultWidth)
+				for code := first; code <= last && code-first < 0x10000; code++ {
+					font.widths[code] = width
+				}
+				i += 3
+			}
+		}
+		return font
+	}
+
+	firstChar := int(f.number(dict["FirstChar"], 0))
+	for i, width := range f.array(dict["Widths"]) {
+		font.widths[firstChar+i] = f.number(width, font.defaultWidth)
+	}
+	if encoding := f.dict(dict["Encoding"]); encoding != nil {
+		font.differences = make(map[int]string)
+		code := 0
+		for _, item := range f.array(encoding["Differences"]) {
+			switch item := f.resolve(item).(type) {
+			case float64:
+				code = int(item)
+			case pdfName:
+				font.differences[code] = string(item)
+				code++
+			}
+		}
+	}
+	return font
+}
+
+// decode returns the text of a shown string, its width in thousandths of
+// the font size, and its count of codes and of single-byte spaces, to which
+// word spacing applies.
+func (font *pdfFont) decode(s []byte) (string, float64, int, int) {
+	var b strings.Builder
+	var width float64
+	codes, spaces := 0, 0
+
+	for i := 0; i < len(s); i += font.codeBytes {
+		end := i + font.codeBytes
+		if end > len(s) {
+			end = len(s)
+		}
+		code := 0
+		for _, c := range s[i:end] {
+			code = code<<8 | int(c)
+		}
+		codes++
+		if font.codeBytes == 1 && code == ' ' {
+			spaces++
+		}
+		if w, ok := font.widths[code]; ok {
+			width += w / 1000
+		} else {
+			width += font.defaultWidth / 1000
+		}
+		b.WriteString(font.text(code))
+	}
+	return b.String(), width, codes, spaces
+}
+
+func (font *pdfFont) text(code int) string {
+	if font.toUnicode != nil {
+		if text, ok := font.toUnicode.codes[uint32(code)]; ok {
+			return strings.Map(printable, text)
+		}
+	}
+	// Composite fonts without a ToUnicode map give glyph IDs, not characters
+	if font.composite {
+		return ""
+	}
+	if name, ok := font.differences[code]; ok {
+		if text := glyphText(name); text != "" {
+			return text
+		}
+	}
+	if r := printable(winAnsi(byte(code))); r >= 0 {
+		return string(r)
+	}
+	return ""
+}
+
+// printable drops control characters and turns non-breaking spaces into spaces.
+func printable(r rune) rune {
+	switch {
+	case r == ' ' || r == '\t':
+		return ' '
+	case unicode.IsControl(r) || r == unicode.ReplacementChar:
+		return -1
+	}
+	return r
+}
+
+// winAnsiHigh holds the WinAnsiEncoding characters that differ from Latin-1.
+var winAnsiHigh = map[byte]rune{
+	0x80: '€', 0x82: '‚', 0x83: 'ƒ', 0x84: '„', 0x85: '…', 0x86: '†', 0x87: '‡',
+	0x89: '‰', 0x8a: 'Š', 0x8b: '‹', 0x8c: 'Œ', 0x8e: 'Ž', 0x91: '‘', 0x92: '’',
+	0x93: '“', 0x94: '”', 0x95: '•', 0x96: '–', 0x97: '—', 0x99: '™', 0x9a: 'š',
+	0x9b: '›', 0x9c: 'œ', 0x9e: 'ž', 0x9f: 'Ÿ',
+}
+
+func winAnsi(c byte) rune {
+	if r, ok := winAnsiHigh[c]; ok {
+		return r
+	}
+	return rune(c)
+}
+
+// glyphNames maps the Adobe glyph names common in Differences arrays.
+var glyphNames = map[string]string{
+	"space": " ", "exclam": "!", "quotedbl": "\"", "numbersign": "#", "dollar": "$",
+	"percent": "%", "ampersand": "&", "quotesingle": "'", "parenleft": "(", "parenright": ")",
+	"asterisk": "*", "plus": "+", "comma": ",", "hyphen": "-", "period": ".", "slash": "/",
+	"zero": "0", "one": "1", "two": "2", "three": "3", "four": "4", "five": "5", "six": "6",
+	"seven": "7", "eight": "8", "nine": "9", "colon": ":", "semicolon": ";", "less": "<",
+	"equal": "=", "greater": ">", "question": "?", "at": "@", "bracketleft": "[",
+	"backslash": "\\", "bracketright": "]", "underscore": "_", "quoteleft": "‘",
+	"quoteright": "’", "quotedblleft": "“", "quotedblright": "”", "endash": "–",
+	"emdash": "—", "bullet": "•", "ellipsis": "…", "fi": "fi", "fl": "fl", "ff": "ff",
+	"ffi": "ffi", "ffl": "ffl", "section": "§", "paragraph": "¶", "degree": "°",
+	"copyright": "©", "registered": "®", "trademark": "™", "dagger": "†", "Euro": "€",
+	"sterling": "£", "yen": "¥", "cent": "¢", "minus": "−",
+}
+
+func glyphText(name string) string {
+	if text, ok := glyphNames[name]; ok {
+		return text
+	}
+	if len(name) == 1 {
+		return name
+	}
+	for _, prefix := range []string{"uni", "u"} {
+		if hexCode := strings.TrimPrefix(name, prefix); hexCode != name && len(hexCode) >= 4 {
+			if code, err := strconv.ParseUint(hexCode[:4], 16, 32); err == nil {
+				return string(rune(code))
+			}
+		}
+	}
+	return ""
+}
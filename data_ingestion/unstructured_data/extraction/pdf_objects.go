@@ -0,0 +1,756 @@
+package extraction
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// The PDF object model: dictionaries and arrays hold these values, numbers
+// are float64, booleans bool and null nil.
+type (
+	pdfName    string
+	pdfString  string
+	pdfKeyword string
+	pdfArray   []interface{}
+	pdfDict    map[pdfName]interface{}
+	pdfRef     struct{ num, gen int }
+	pdfStream  struct {
+		dict pdfDict
+		raw  []byte
+	}
+)
+
+var objHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// pdfFile is a parsed PDF: every indirect object by number, and the trailer.
+// decoded counts the bytes its streams inflated to, against maxDecodedBytes.
+type pdfFile struct {
+	objects  map[int]interface{}
+	trailer  pdfDict
+	decoded  int64
+	tooLarge bool
+}
+
+// parsePDF scans the file for "N G obj" headers rather than trusting the
+// cross-reference table, which is often damaged in files from the wild.
+// Later definitions of an object replace earlier ones, as incremental updates
+// do.
+func parsePDF(data []byte) (*pdfFile, error) {
+	header := data
+	if len(header) > 1024 {
+		header = header[:1024]
+	}
+	if !bytes.Contains(header, []byte("%PDF-")) {
+		return nil, ErrNotPDF
+	}
+
+	f := &pdfFile{objects: make(map[int]interface{}), trailer: pdfDict{}}
+	type trailerAt struct {
+		pos  int
+		dict pdfDict
+	}
+	var trailers []trailerAt
+
+	end := 0
+	for _, m := range objHeader.FindAllSubmatchIndex(data, -1) {
+		// Skip matches inside the stream of the previous object
+		if m[0] < end {
+			continue
+		}
+		num, err := strconv.Atoi(string(data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		l := &pdfLexer{data: data, pos: m[1]}
+		v, err := l.value()
+		if err != nil {
+			continue
+		}
+		end = l.pos
+		if dict, ok := v.(pdfDict); ok {
+			if stream, next, ok := readStream(data, l.pos, dict); ok {
+				v, end = stream, next
+			}
+			// Cross-reference streams stand in for the trailer
+			if dict["Type"] == pdfName("XRef") {
+				trailers = append(trailers, trailerAt{m[0], dict})
+			}
+		}
+		f.objects[num] = v
+	}
+
+	for pos := 0; ; {
+		i := bytes.Index(data[pos:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		pos += i + len("trailer")
+		l := &pdfLexer{data: data, pos: pos}
+		if dict, ok := mustValue(l).(pdfDict); ok {
+			trailers = append(trailers, trailerAt{pos, dict})
+		}
+	}
+	sort.SliceStable(trailers, func(i, j int) bool { return trailers[i].pos < trailers[j].pos })
+	for _, t := range trailers {
+		for key, value := range t.dict {
+			f.trailer[key] = value
+		}
+	}
+	if _, ok := f.trailer["Encrypt"]; ok {
+		return nil, ErrEncryptedPDF
+	}
+
+	f.expandObjectStreams()
+	return f, nil
+}
+
+func mustValue(l *pdfLexer) interface{} {
+	v, err := l.value()
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// readStream reads the stream following a dictionary at pos and returns it
+// with the position after "endstream".
+func readStream(data []byte, pos int, dict pdfDict) (pdfStream, int, bool) {
+	l := &pdfLexer{data: data, pos: pos}
+	l.skipSpace()
+	if !bytes.HasPrefix(data[l.pos:], []byte("stream")) {
+		return pdfStream{}, 0, false
+	}
+	start := l.pos + len("stream")
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+
+	stop := -1
+	if n, ok := dict["Length"].(float64); ok && n >= 0 && start+int(n) <= len(data) {
+		rest := bytes.TrimLeft(data[start+int(n):], "\r\n \t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			stop = start + int(n)
+		}
+	}
+	if stop < 0 {
+		// Length is indirect or wrong; fall back to the endstream keyword
+		i := bytes.Index(data[start:], []byte("endstream"))
+		if i < 0 {
+			return pdfStream{}, 0, false
+		}
+		stop = start + i
+		for stop > start && (data[stop-1] == '\n' || data[stop-1] == '\r') {
+			stop--
+		}
+	}
+	next := stop + bytes.Index(data[stop:], []byte("endstream")) + len("endstream")
+	return pdfStream{dict: dict, raw: data[start:stop]}, next, true
+}
+
+// expandObjectStreams adds the objects packed into object streams (PDF 1.5+),
+// where most page and font dictionaries of modern files live.
+func (f *pdfFile) expandObjectStreams() {
+	var containers []pdfStream
+	for _, v := range f.objects {
+		if s, ok := v.(pdfStream); ok && s.dict["Type"] == pdfName("ObjStm") {
+			containers = append(containers, s)
+		}
+	}
+
+	for _, s := range containers {
+		data, err := f.decode(s)
+		if err != nil {
+			continue
+		}
+		n, _ := f.resolve(s.dict["N"]).(float64)
+		first, _ := f.resolve(s.dict["First"]).(float64)
+		if int(first) > len(data) {
+			continue
+		}
+
+		header := &pdfLexer{data: data[:int(first)]}
+		for i := 0; i < int(n); i++ {
+			num, err1 := header.next()
+			offset, err2 := header.next()
+			objNum, ok1 := num.(float64)
+			objOffset, ok2 := offset.(float64)
+			if err1 != nil || err2 != nil || !ok1 || !ok2 {
+				break
+			}
+			if _, exists := f.objects[int(objNum)]; exists {
+				continue
+			}
+			l := &pdfLexer{data: data, pos: int(first) + int(objOffset)}
+			if l.pos >= len(data) {
+				continue
+			}
+			if v, err := l.value(); err == nil {
+				f.objects[int(objNum)] = v
+			}
+		}
+	}
+}
+
+// decode applies a stream's filters. Image filters are not supported; no text
+// lives in them. A stream decoding to more than maxStreamBytes, or past what
+// is left of maxDecodedBytes for the file, fails with ErrPDFTooLarge.
+func (f *pdfFile) decode(s pdfStream) ([]byte, error) {
+	var filters []pdfName
+	switch v := f.resolve(s.dict["Filter"]).(type) {
+	case pdfName:
+		filters = []pdfName{v}
+	case pdfArray:
+		for _, item := range v {
+			if name, ok := f.resolve(item).(pdfName); ok {
+				filters = append(filters, name)
+			}
+		}
+	}
+
+	data := s.raw
+	for _, filter := range filters {
+		switch filter {
+		case "FlateDecode", "Fl":
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			out, err := f.readLimited(r)
+			if err == ErrPDFTooLarge {
+				return nil, err
+			}
+			// Keep what inflated before a corrupt or truncated tail
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			data = out
+		case "ASCIIHexDecode", "AHx":
+			data = []byte(hexString(data))
+		case "ASCII85Decode", "A85":
+			src := bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+			if i := bytes.Index(src, []byte("~>")); i >= 0 {
+				src = src[:i]
+			}
+			out, err := f.readLimited(ascii85.NewDecoder(bytes.NewReader(src)))
+			if err == ErrPDFTooLarge {
+				return nil, err
+			}
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			data = out
+		default:
+			return nil, fmt.Errorf("unsupported filter %s", filter)
+		}
+	}
+	return data, nil
+}
+
+// readLimited reads r to the end, counting what it read against the file's
+// budget, and fails with ErrPDFTooLarge once a limit is reached.
+func (f *pdfFile) readLimited(r io.Reader) ([]byte, error) {
+	limit := min(maxStreamBytes, maxDecodedBytes-f.decoded)
+	out, err := io.ReadAll(io.LimitReader(r, limit+1))
+	f.decoded += int64(len(out))
+	if int64(len(out)) > limit {
+		f.tooLarge = true
+		return nil, ErrPDFTooLarge
+	}
+	return out, err
+}
+
+func (f *pdfFile) resolve(v interface{}) interface{} {
+	for i := 0; i < 16; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		v = f.objects[ref.num]
+	}
+	return nil
+}
+
+// dict resolves v to a dictionary, taking a stream's dictionary.
+func (f *pdfFile) dict(v interface{}) pdfDict {
+	switch v := f.resolve(v).(type) {
+	case pdfDict:
+		return v
+	case pdfStream:
+		return v.dict
+	}
+	return nil
+}
+
+func (f *pdfFile) array(v interface{}) pdfArray {
+	a, _ := f.resolve(v).(pdfArray)
+	return a
+}
+
+func (f *pdfFile) number(v interface{}, fallback float64) float64 {
+	if n, ok := f.resolve(v).(float64); ok {
+		return n
+	}
+	return fallback
+}
+
+// streamData resolves and decodes a stream, or an array of streams joined
+// as page contents may be. Arrays that refer back to themselves are read
+// once.
+func (f *pdfFile) streamData(v interface{}) []byte {
+	return f.joinStreams(v, make(map[pdfRef]bool), 0)
+}
+
+func (f *pdfFile) joinStreams(v interface{}, visited map[pdfRef]bool, depth int) []byte {
+	if ref, ok := v.(pdfRef); ok {
+		if visited[ref] {
+			return nil
+		}
+		visited[ref] = true
+	}
+	switch v := f.resolve(v).(type) {
+	case pdfStream:
+		data, err := f.decode(v)
+		if err != nil {
+			return nil
+		}
+		return data
+	case pdfArray:
+		if depth > maxContentDepth {
+			return nil
+		}
+		var out []byte
+		for _, part := range v {
+			out = append(out, f.joinStreams(part, visited, depth+1)...)
+			out = append(out, '\n')
+		}
+		return out
+	}
+	return nil
+}
+
+// textString decodes a PDF text string: UTF-16BE with a byte order mark, or
+// else PDFDocEncoding, which agrees with Latin-1 for printable text.
+func textString(s pdfString) string {
+	b := []byte(s)
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		return utf16BE(b[2:])
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func utf16BE(b []byte) string {
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func hexString(data []byte) pdfString {
+	var digits []byte
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	hex.Decode(out, digits)
+	return pdfString(out)
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// pdfLexer tokenizes both object syntax and content streams.
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func isPDFSpace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelim(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) skipSpace() {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case isPDFSpace(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// next returns the next token: a name, string or number, or a pdfKeyword for
+// operators and for the delimiters of arrays and dictionaries.
+func (l *pdfLexer) next() (interface{}, error) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return nil, io.EOF
+	}
+
+	c := l.data[l.pos]
+	switch c {
+	case '/':
+		return l.name(), nil
+	case '(':
+		return l.literal(), nil
+	case '<':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '<' {
+			l.pos += 2
+			return pdfKeyword("<<"), nil
+		}
+		l.pos++
+		s := hexString(l.data[l.pos:])
+		if i := bytes.IndexByte(l.data[l.pos:], '>'); i >= 0 {
+			l.pos += i + 1
+		} else {
+			l.pos = len(l.data)
+		}
+		return s, nil
+	case '>':
+		if l.pos+1 < len(l.data) && l.data[l.pos+1] == '>' {
+			l.pos += 2
+			return pdfKeyword(">>"), nil
+		}
+	}
+
+	start := l.pos
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		l.pos++
+	}
+	if l.pos == start {
+		l.pos++
+		return pdfKeyword([]byte{c}), nil
+	}
+
+	token := string(l.data[start:l.pos])
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if (token[0] >= '0' && token[0] <= '9') || token[0] == '-' || token[0] == '+' || token[0] == '.' {
+		if n, err := strconv.ParseFloat(token, 64); err == nil {
+			return n, nil
+		}
+	}
+	return pdfKeyword(token), nil
+}
+
+// value reads one complete value, composing arrays, dictionaries and
+// "N G R" references.
+func (l *pdfLexer) value() (interface{}, error) {
+	token, err := l.next()
+	if err != nil {
+		return nil, err
+	}
+	return l.compose(token)
+}
+
+func (l *pdfLexer) compose(token interface{}) (interface{}, error) {
+	switch token := token.(type) {
+	case float64:
+		return l.reference(token), nil
+	case pdfKeyword:
+		switch token {
+		case "[":
+			var array pdfArray
+			for {
+				item, err := l.next()
+				if err != nil {
+					return array, err
+				}
+				if item == pdfKeyword("]") {
+					return array, nil
+				}
+				v, err := l.compose(item)
+				if err != nil {
+					return array, err
+				}
+				array = append(array, v)
+			}
+		case "<<":
+			dict := pdfDict{}
+			for {
+				key, err := l.next()
+				if err != nil {
+					return dict, err
+				}
+				if key == pdfKeyword(">>") {
+					return dict, nil
+				}
+				name, ok := key.(pdfName)
+				if !ok {
+					continue
+				}
+				v, err := l.value()
+				if err != nil {
+					return dict, err
+				}
+				if v == pdfKeyword(">>") {
+					return dict, nil
+				}
+				dict[name] = v
+			}
+		}
+	}
+	return token, nil
+}
+
+// reference reads "gen R" after an object number, or leaves the lexer where
+// it was if n is a plain number.
+func (l *pdfLexer) reference(n float64) interface{} {
+	if n != float64(int(n)) || n < 0 {
+		return n
+	}
+	saved := l.pos
+	gen, err := l.next()
+	if g, ok := gen.(float64); err == nil && ok {
+		if r, err := l.next(); err == nil && r == pdfKeyword("R") {
+			return pdfRef{int(n), int(g)}
+		}
+	}
+	l.pos = saved
+	return n
+}
+
+func (l *pdfLexer) name() pdfName {
+	l.pos++
+	var b []byte
+	for l.pos < len(l.data) && !isPDFSpace(l.data[l.pos]) && !isPDFDelim(l.data[l.pos]) {
+		c := l.data[l.pos]
+		if c == '#' && l.pos+2 < len(l.data) {
+			if v, err := strconv.ParseUint(string(l.data[l.pos+1:l.pos+3]), 16, 8); err == nil {
+				b = append(b, byte(v))
+				l.pos += 3
+				continue
+			}
+		}
+		b = append(b, c)
+		l.pos++
+	}
+	return pdfName(b)
+}
+
+func (l *pdfLexer) literal() pdfString {
+	l.pos++
+	var b []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(b)
+			}
+		case '\\':
+			if l.pos >= len(l.data) {
+				return pdfString(b)
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e < '0' || e > '7' {
+					c = e
+					break
+				}
+				v := int(e - '0')
+				for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+					v = v*8 + int(l.data[l.pos]-'0')
+					l.pos++
+				}
+				c = byte(v)
+			}
+		}
+		b = append(b, c)
+	}
+	return pdfString(b)
+}
+
+// skipInlineImage moves past the data of an inline image, which follows the
+// ID operator and ends at an EI operator.
+func (l *pdfLexer) skipInlineImage() {
+	for {
+		token, err := l.next()
+		if err != nil {
+			return
+		}
+		if token == pdfKeyword("ID") {
+			break
+		}
+	}
+	for l.pos < len(l.data) {
+		i := bytes.Index(l.data[l.pos:], []byte("EI"))
+		if i < 0 {
+			l.pos = len(l.data)
+			return
+		}
+		at := l.pos + i
+		l.pos = at + 2
+		if at > 0 && isPDFSpace(l.data[at-1]) && (l.pos >= len(l.data) || isPDFSpace(l.data[l.pos])) {
+			return
+		}
+	}
+}
+
+// pdfCMap is a ToUnicode map from character codes to text.
+type pdfCMap struct {
+	width int // bytes per code, from the codespace range
+	codes map[uint32]string
+}
+
+func parseCMap(data []byte) *pdfCMap {
+	cmap := &pdfCMap{codes: make(map[uint32]string)}
+	l := &pdfLexer{data: data}
+
+	var operands []interface{}
+	for {
+		token, err := l.next()
+		if err != nil {
+			break
+		}
+		keyword, ok := token.(pdfKeyword)
+		if !ok {
+			operands = append(operands, token)
+			continue
+		}
+		if keyword == "[" || keyword == "<<" {
+			v, _ := l.compose(token)
+			operands = append(operands, v)
+			continue
+		}
+
+		switch keyword {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if lo, ok := operands[0].(pdfString); ok && len(lo) > 0 {
+					cmap.width = len(lo)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					cmap.set(src, utf16BE([]byte(dst)))
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 {
+					continue
+				}
+				first, last := codeOf(lo), codeOf(hi)
+				if last < first || last-first > 0xffff {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					units := []byte(dst)
+					for code := first; code <= last; code++ {
+						cmap.codes[code] = utf16BE(offsetLast(units, int(code-first)))
+					}
+				case pdfArray:
+					for j, item := range dst {
+						if s, ok := item.(pdfString); ok && first+uint32(j) <= last {
+							cmap.codes[first+uint32(j)] = utf16BE([]byte(s))
+						}
+					}
+				}
+				if cmap.width == 0 {
+					cmap.width = len(lo)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return cmap
+}
+
+func (c *pdfCMap) set(src pdfString, text string) {
+	c.codes[codeOf(src)] = text
+	if c.width == 0 {
+		c.width = len(src)
+	}
+}
+
+func codeOf(s pdfString) uint32 {
+	var code uint32
+	for i := 0; i < len(s); i++ {
+		code = code<<8 | uint32(s[i])
+	}
+	return code
+}
+
+// offsetLast adds n to the last UTF-16 unit of a bfrange destination.
+func offsetLast(units []byte, n int) []byte {
+	out := append([]byte{}, units...)
+	if len(out) < 2 {
+		return out
+	}
+	last := int(out[len(out)-2])<<8 | int(out[len(out)-1])
+	last += n
+	out[len(out)-2], out[len(out)-1] = byte(last>>8), byte(last)
+	return out
+}
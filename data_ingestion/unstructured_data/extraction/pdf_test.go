@@ -0,0 +1,151 @@
+package extraction
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildPDF lays out objects, numbered from 1, with a cross-reference table
+// and a trailer naming object 1 as the catalog.
+func buildPDF(objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+func stream(dict, data string) string {
+	return fmt.Sprintf("<< %s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func deflate(data []byte) string {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(data)
+	w.Close()
+	return b.String()
+}
+
+// onePage is a catalog, page tree and page whose contents are object 4 and
+// whose font is object 5.
+func onePage(contents string) []byte {
+	return buildPDF(
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		contents,
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	)
+}
+
+const helloContent = "BT /F1 12 Tf 72 720 Td (Hello credit world) Tj ET"
+
+func TestExtractPDF(t *testing.T) {
+	tests := []struct {
+		name string
+		pdf  []byte
+	}{
+		{"plain stream", onePage(stream("", helloContent))},
+		{"flate stream", onePage(stream("/Filter /FlateDecode", deflate([]byte(helloContent))))},
+		{"hex stream", onePage(stream("/Filter /ASCIIHexDecode", fmt.Sprintf("%x>", helloContent)))},
+		{"contents array", buildPDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Contents [4 0 R 6 0 R] /Resources << /Font << /F1 5 0 R >> >> >>",
+			stream("", "BT /F1 12 Tf 72 720 Td (Hello credit) Tj"),
+			"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+			stream("", "( world) Tj ET"),
+		)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := ExtractPDF(bytes.NewReader(tt.pdf))
+			if err != nil {
+				t.Fatalf("ExtractPDF: %v", err)
+			}
+			if len(doc.Pages) != 1 || doc.WordCount != 3 {
+				t.Fatalf("got %d pages and %d words, want 1 and 3: %q", len(doc.Pages), doc.WordCount, doc.Text)
+			}
+			if !strings.Contains(doc.Text, "Hello credit") || !strings.Contains(doc.Text, "world") {
+				t.Errorf("Text = %q", doc.Text)
+			}
+		})
+	}
+}
+
+func TestExtractPDFMalformed(t *testing.T) {
+	valid := onePage(stream("", helloContent))
+	bomb := deflate(make([]byte, maxStreamBytes+1))
+
+	tests := []struct {
+		name string
+		pdf  []byte
+		err  error // nil for any error or none, as long as there is no panic
+	}{
+		{"not a PDF", []byte("<html>hello</html>"), ErrNotPDF},
+		{"encrypted", append(valid, "trailer\n<< /Encrypt 9 0 R >>\n"...), ErrEncryptedPDF},
+		{"contents array referring to itself", onePage("[4 0 R]"), ErrNoPDFText},
+		{"contents arrays referring to each other", buildPDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+			"<< /Type /Page /Parent 2 0 R /Contents 4 0 R >>",
+			"[5 0 R [4 0 R]]",
+			"[4 0 R 5 0 R]",
+		), ErrNoPDFText},
+		{"page tree cycle", buildPDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [2 0 R 3 0 R] >>",
+			"<< /Type /Pages /Kids [2 0 R] >>",
+		), ErrNoPDFText},
+		{"reference chain cycle", buildPDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"3 0 R",
+			"2 0 R",
+		), ErrNoPDFText},
+		{"flate bomb", onePage(stream("/Filter /FlateDecode", bomb)), ErrPDFTooLarge},
+		{"flate bomb in object stream", buildPDF(
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			stream("/Type /ObjStm /N 1 /First 4 /Filter /FlateDecode", bomb),
+		), ErrPDFTooLarge},
+		{"truncated xref", valid[:bytes.Index(valid, []byte("xref"))+12], nil},
+		{"truncated stream", valid[:bytes.Index(valid, []byte("Hello"))], nil},
+		{"corrupt flate", onePage(stream("/Filter /FlateDecode", "not zlib at all")), ErrNoPDFText},
+		{"unsupported filter", onePage(stream("/Filter /DCTDecode", "\xff\xd8")), ErrNoPDFText},
+		{"unterminated string", onePage(stream("", "BT /F1 12 Tf (never closed")), nil},
+		{"unbalanced arrays", onePage("[[[[[[[[[[["), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ExtractPDF(bytes.NewReader(tt.pdf))
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("ExtractPDF error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestExtractPDFKeepsValidTextAfterTruncatedXref(t *testing.T) {
+	valid := onePage(stream("", helloContent))
+	truncated := valid[:bytes.Index(valid, []byte("xref"))+12]
+	doc, err := ExtractPDF(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatalf("ExtractPDF: %v", err)
+	}
+	if !strings.Contains(doc.Text, "Hello credit world") {
+		t.Errorf("Text = %q", doc.Text)
+	}
+}
@@ -0,0 +1,88 @@
+package extraction
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// leftoverMarkup is a tag or entity still in text after a pass, as when
+	// a feed escapes the HTML in its descriptions
+	leftoverMarkup = regexp.MustCompile(`<[a-zA-Z/!][^>]*>|&(?:[a-zA-Z][a-zA-Z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6});`)
+
+	// blockElements end a line of text
+	blockElements = map[string]bool{
+		"address": true, "article": true, "aside": true, "blockquote": true, "br": true, "dd": true, "div": true,
+		"dl": true, "dt": true, "figcaption": true, "figure": true, "footer": true, "h1": true, "h2": true,
+		"h3": true, "h4": true, "h5": true, "h6": true, "header": true, "hr": true, "li": true, "ol": true,
+		"p": true, "pre": true, "section": true, "table": true, "td": true, "th": true, "tr": true, "ul": true,
+	}
+
+	// hiddenElements hold no readable text
+	hiddenElements = map[string]bool{"script": true, "style": true, "noscript": true, "template": true, "iframe": true}
+
+	cdata = strings.NewReplacer("<![CDATA[", "", "]]>", "")
+	// invisible are zero-width characters and soft hyphens
+	invisible = strings.NewReplacer("\u200b", "", "\u200c", "", "\u200d", "", "\ufeff", "", "\u00ad", "")
+)
+
+// CleanText returns the readable text of an HTML or plain-text fragment, as
+// feeds and APIs deliver titles and descriptions: CDATA markers, tags,
+// scripts and styles removed, entities decoded, and whitespace collapsed
+// within lines, with block elements such as paragraphs on lines of their
+// own. Markup that was escaped once more is unwrapped the same way.
+func CleanText(fragment string) string {
+	text := fragment
+	for pass := 0; pass < 3; pass++ {
+		text = stripMarkup(text)
+		if !leftoverMarkup.MatchString(text) {
+			break
+		}
+	}
+	return normalizeSpace(text)
+}
+
+func stripMarkup(fragment string) string {
+	var b strings.Builder
+	hidden := ""
+	tokenizer := html.NewTokenizer(strings.NewReader(cdata.Replace(fragment)))
+	for {
+		tt := tokenizer.Next()
+		switch tt {
+		case html.ErrorToken:
+			return b.String()
+		case html.TextToken:
+			if hidden == "" {
+				b.Write(tokenizer.Text())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			switch {
+			case hidden != "":
+				if tag == hidden && tt == html.EndTagToken {
+					hidden = ""
+				}
+			case hiddenElements[tag] && tt == html.StartTagToken:
+				hidden = tag
+			case blockElements[tag]:
+				b.WriteByte('\n')
+			}
+		}
+	}
+}
+
+// normalizeSpace collapses runs of whitespace, non-breaking spaces
+// included, within each line and drops blank lines and invisible
+// characters.
+func normalizeSpace(text string) string {
+	var lines []string
+	for _, line := range strings.Split(invisible.Replace(text), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -4,15 +4,27 @@ go 1.24.4
 
 require (
 	github.com/Finnhub-Stock-API/finnhub-go/v2 v2.0.19
+	github.com/PuerkitoBio/goquery v1.10.3
+	github.com/gaixen/CredTech/symbols v0.0.0
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.3
+	github.com/graphql-go/graphql v0.8.1
 	github.com/lib/pq v1.10.9
+	github.com/rabbitmq/amqp091-go v1.10.0
+	golang.org/x/net v0.39.0
+	google.golang.org/grpc v1.73.0
+	google.golang.org/protobuf v1.36.12
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/golang/protobuf v1.4.2 // indirect
-	golang.org/x/net v0.0.0-20200822124328-c89045814202 // indirect
-	golang.org/x/oauth2 v0.0.0-20210218202405-ba52d332ba99 // indirect
+	github.com/andybalholm/cascadia v1.3.3 // indirect
+	github.com/golang/protobuf v1.5.4 // indirect
+	golang.org/x/oauth2 v0.28.0 // indirect
+	golang.org/x/sys v0.32.0 // indirect
+	golang.org/x/text v0.24.0 // indirect
 	google.golang.org/appengine v1.6.6 // indirect
-	google.golang.org/protobuf v1.25.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250324211829-b45e905df463 // indirect
 )
+
+replace github.com/gaixen/CredTech/symbols => ../../symbols
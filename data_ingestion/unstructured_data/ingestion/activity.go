@@ -0,0 +1,154 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// Activity records what each source has done today: when it last fetched
+// successfully, how many items it stored and its last error. Fetches are
+// the requests a source sends, and items the documents stored under the
+// context the manager runs it with, so the saves of processing jobs are
+// not counted.
+type Activity struct {
+	mu      sync.Mutex
+	day     string
+	sources map[string]*sourceActivity
+}
+
+type sourceActivity struct {
+	lastFetch   time.Time
+	lastError   string
+	lastErrorAt time.Time
+	items       int
+	fetches     int
+	errors      int
+}
+
+// NewActivity returns an empty Activity; the manager records into it once
+// given it with SetActivity.
+func NewActivity() *Activity {
+	return &Activity{sources: make(map[string]*sourceActivity)}
+}
+
+// get returns the source's record, starting the counts afresh on a new UTC
+// day; the caller holds a.mu.
+func (a *Activity) get(source string) *sourceActivity {
+	if day := time.Now().UTC().Format("2006-01-02"); day != a.day {
+		a.day = day
+		for _, act := range a.sources {
+			act.items, act.fetches, act.errors = 0, 0, 0
+		}
+	}
+	act := a.sources[source]
+	if act == nil {
+		act = &sourceActivity{}
+		a.sources[source] = act
+	}
+	return act
+}
+
+func (a *Activity) fetched(source string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	act := a.get(source)
+	act.fetches++
+	if err != nil {
+		act.errors++
+		act.lastError, act.lastErrorAt = err.Error(), time.Now()
+		return
+	}
+	act.lastFetch = time.Now()
+}
+
+func (a *Activity) stored(source string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.get(source).items++
+}
+
+// describe fills in the activity of status.Name.
+func (a *Activity) describe(status *SourceStatus) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	act := a.get(status.Name)
+	status.ItemsToday, status.FetchesToday, status.ErrorsToday = act.items, act.fetches, act.errors
+	if !act.lastFetch.IsZero() {
+		at := act.lastFetch
+		status.LastFetchAt = &at
+	}
+	if act.lastError != "" {
+		at := act.lastErrorAt
+		status.LastError, status.LastErrorAt = act.lastError, &at
+	}
+}
+
+// Wrap returns store counting the documents saved through it under a
+// source's context. Wrapped around the storage itself, only documents every
+// other wrapper let through are counted.
+func (a *Activity) Wrap(store storage.Storage) storage.Storage {
+	return &activityStorage{Storage: store, activity: a}
+}
+
+type activityStorage struct {
+	storage.Storage
+	activity *Activity
+}
+
+func (s *activityStorage) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	if err := s.Storage.SaveUnstructuredData(ctx, data); err != nil {
+		return err
+	}
+	if source := sourceFrom(ctx); source != "" {
+		s.activity.stored(source)
+	}
+	count(ctx, func(r *pollResult) { r.items++ })
+	return nil
+}
+
+type sourceKey struct{}
+
+// withSource labels ctx as that of the named source.
+func withSource(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, sourceKey{}, name)
+}
+
+func sourceFrom(ctx context.Context) string {
+	name, _ := ctx.Value(sourceKey{}).(string)
+	return name
+}
+
+// activityTransport records the outcome of every request of one source,
+// also against the poll that sent it. A response of 400 or above counts as
+// an error.
+type activityTransport struct {
+	activity *Activity
+	source   string
+	base     http.RoundTripper
+}
+
+func (t *activityTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	ctx := req.Context()
+	switch {
+	case err != nil:
+		// A source stopping is no failure of the source
+		if ctx.Err() == nil {
+			t.activity.fetched(t.source, fmt.Errorf("%s %s: %w", req.Method, req.URL.Host, err))
+			count(ctx, func(r *pollResult) { r.requests++; r.failures++ })
+		}
+	case resp.StatusCode >= 400:
+		t.activity.fetched(t.source, fmt.Errorf("%s %s%s: status %d", req.Method, req.URL.Host, req.URL.Path, resp.StatusCode))
+		count(ctx, func(r *pollResult) { r.requests++; r.failures++ })
+	default:
+		t.activity.fetched(t.source, nil)
+		count(ctx, func(r *pollResult) { r.requests++ })
+	}
+	return resp, err
+}
@@ -0,0 +1,177 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/events"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// enrich runs one analysis job: analyze reads the job's record and returns
+// the change to make, or nil to leave the record alone. The change is
+// applied to a freshly loaded copy, unless an extraction job replaced the
+// title or content meanwhile; its save queued a new analysis of the text.
+func (w *Worker) enrich(ctx context.Context, job ProcessingJob, analyze func(*models.UnstructuredData) (func(*models.UnstructuredData), error)) error {
+	store := w.manager.storage
+
+	data, err := store.GetUnstructuredData(ctx, job.DataID)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", job.DataID, err)
+	}
+	apply, err := analyze(data)
+	if err != nil || apply == nil {
+		return err
+	}
+
+	current, err := store.GetUnstructuredData(ctx, job.DataID)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", job.DataID, err)
+	}
+	if current.Title != data.Title || current.Content != data.Content {
+		return nil
+	}
+	if current.Metadata == nil {
+		current.Metadata = make(map[string]interface{})
+	}
+	apply(current)
+	if err := store.SaveUnstructuredData(ctx, current); err != nil {
+		return fmt.Errorf("failed to save %s: %w", job.DataID, err)
+	}
+	return nil
+}
+
+// processSentimentAnalysis scores a record's title and content with the
+// configured backend and saves the score on the record. Scores a source
+// supplied itself, such as GDELT tone or StockTwits labels, are kept.
+func (w *Worker) processSentimentAnalysis(ctx context.Context, job ProcessingJob) error {
+	return w.enrich(ctx, job, func(data *models.UnstructuredData) (func(*models.UnstructuredData), error) {
+		if data.Sentiment != nil && data.Sentiment.Model == "" {
+			return nil, nil
+		}
+		score, err := w.manager.sentiment.Score(ctx, data.Title+".\n"+data.Content)
+		if err != nil {
+			return nil, fmt.Errorf("sentiment scoring failed for %s: %w", job.DataID, err)
+		}
+		if score == nil {
+			return nil, nil
+		}
+		log.Printf("Scored sentiment %.2f for %s", score.Overall, job.DataID)
+		return func(current *models.UnstructuredData) {
+			now := time.Now().UTC()
+			current.Sentiment = score
+			current.ProcessedAt = &now
+		}, nil
+	})
+}
+
+// processEntityExtraction finds the companies, tickers and amounts in a
+// record's title and content, which may be full text by now where the
+// source only had a description, adds the tickers to its symbols and
+// records the canonical companies they resolve to as company_ids. Entity
+// positions are offsets into the title and content joined by a newline.
+func (w *Worker) processEntityExtraction(ctx context.Context, job ProcessingJob) error {
+	return w.enrich(ctx, job, func(data *models.UnstructuredData) (func(*models.UnstructuredData), error) {
+		found := w.manager.entities.Extract(data.Title + "\n" + data.Content)
+		symbols := w.manager.entities.Symbols(found)
+		return func(current *models.UnstructuredData) {
+			current.Entities = found
+			merged := mergeSymbols(current.Metadata["symbols"], symbols)
+			if len(merged) > 0 {
+				current.Metadata["symbols"] = merged
+			}
+			for _, key := range []string{"symbol", "primary_symbol"} {
+				if symbol, ok := current.Metadata[key].(string); ok && symbol != "" {
+					merged = append(merged, symbol)
+				}
+			}
+			if ids := w.manager.resolver.Resolve(found, merged); len(ids) > 0 {
+				current.Metadata["company_ids"] = ids
+			}
+		}, nil
+	})
+}
+
+// processCreditEvents labels a record with the credit events it reports,
+// saving them under credit_events and their types under credit_event_types
+// for the scoring engine. A record found to report none gets both empty,
+// since its text may have changed since the last classification.
+func (w *Worker) processCreditEvents(ctx context.Context, job ProcessingJob) error {
+	return w.enrich(ctx, job, func(data *models.UnstructuredData) (func(*models.UnstructuredData), error) {
+		found, err := w.manager.events.Classify(ctx, data.Title+".\n"+data.Content)
+		if err != nil {
+			return nil, fmt.Errorf("credit event classification failed for %s: %w", job.DataID, err)
+		}
+		if len(found) > 0 {
+			log.Printf("Found credit events %v in %s", events.EventTypes(found), job.DataID)
+		}
+		if found == nil {
+			found = []models.CreditEvent{}
+		}
+		return func(current *models.UnstructuredData) {
+			current.Metadata["credit_events"] = found
+			current.Metadata["credit_event_types"] = events.EventTypes(found)
+		}, nil
+	})
+}
+
+// processSummarization has the configured LLM write a short credit-relevant
+// summary of a record and saves it with the model and its cost. Without an
+// endpoint configured, or for records with no content to condense, the job
+// does nothing.
+func (w *Worker) processSummarization(ctx context.Context, job ProcessingJob) error {
+	client := w.manager.summaries
+	if client == nil {
+		return nil
+	}
+	return w.enrich(ctx, job, func(data *models.UnstructuredData) (func(*models.UnstructuredData), error) {
+		if strings.TrimSpace(data.Content) == "" {
+			return nil, nil
+		}
+		summary, usage, err := client.Summarize(ctx, data.Title, data.Content)
+		if err != nil {
+			return nil, fmt.Errorf("summarization failed for %s: %w", job.DataID, err)
+		}
+		if summary == "" {
+			return nil, nil
+		}
+		log.Printf("Summarized %s in %d tokens ($%.5f)", job.DataID, usage.InputTokens+usage.OutputTokens, usage.Cost)
+		return func(current *models.UnstructuredData) {
+			current.Summary = summary
+			current.Metadata["summary_model"] = client.Model()
+			current.Metadata["summary_cost"] = usage.Cost
+		}, nil
+	})
+}
+
+// mergeSymbols adds symbols to the symbols metadata a source set, which is
+// a []string, or a []interface{} once it has been through JSON.
+func mergeSymbols(existing interface{}, symbols []string) []string {
+	var merged []string
+	seen := make(map[string]bool)
+	add := func(symbol string) {
+		if key := strings.ToUpper(symbol); symbol != "" && !seen[key] {
+			seen[key] = true
+			merged = append(merged, symbol)
+		}
+	}
+	switch list := existing.(type) {
+	case []string:
+		for _, symbol := range list {
+			add(symbol)
+		}
+	case []interface{}:
+		for _, symbol := range list {
+			if s, ok := symbol.(string); ok {
+				add(s)
+			}
+		}
+	}
+	for _, symbol := range symbols {
+		add(symbol)
+	}
+	return merged
+}
@@ -0,0 +1,211 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/crawler"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// articleExtractionJob replaces a record's feed description with the text
+// of the article it links to.
+const articleExtractionJob = "article_extraction"
+
+// errPDFArticle is returned when an article link serves a PDF.
+var errPDFArticle = errors.New("article is a PDF document")
+
+// articleFetcher downloads article pages politely: none to denylisted
+// domains, and the rest through the rate limiter the manager wraps its
+// client in, one request per domain per DomainDelay.
+type articleFetcher struct {
+	config config.ExtractionConfig
+	client *http.Client
+}
+
+func newArticleFetcher(cfg config.ExtractionConfig) *articleFetcher {
+	return &articleFetcher{
+		config: cfg,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// denied reports whether host is a denylisted domain or one of its subdomains.
+func (a *articleFetcher) denied(host string) bool {
+	host = strings.ToLower(strings.TrimPrefix(host, "www."))
+	for _, domain := range a.config.Denylist {
+		domain = strings.ToLower(domain)
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// get requests a document and returns the response with its media type.
+// The caller closes the body.
+func (a *articleFetcher) get(ctx context.Context, docURL *url.URL, accept string) (*http.Response, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", docURL.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+	req.Header.Set("Accept", accept)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch %s: %w", docURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, "", fmt.Errorf("%s returned status %d", docURL.Hostname(), resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return resp, mediaType, nil
+}
+
+func (a *articleFetcher) fetch(ctx context.Context, pageURL *url.URL) (*extraction.Article, error) {
+	resp, mediaType, err := a.get(ctx, pageURL, "text/html")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+	case "application/pdf":
+		return nil, errPDFArticle
+	default:
+		return nil, fmt.Errorf("article is %q, not HTML", mediaType)
+	}
+
+	return extraction.ExtractArticle(io.LimitReader(resp.Body, a.config.MaxBytes))
+}
+
+// isPDFLink reports whether a link names a PDF document.
+func isPDFLink(link string) bool {
+	u, err := url.Parse(link)
+	return err == nil && strings.EqualFold(path.Ext(u.Path), ".pdf")
+}
+
+// enqueueTextExtraction queues the job that fetches the full text behind a
+// record's link: pdf_extraction for PDFs, article_extraction otherwise.
+func enqueueTextExtraction(ctx context.Context, store storage.Storage, data *models.UnstructuredData) {
+	jobType := articleExtractionJob
+	if isPDFLink(data.URL) {
+		jobType = pdfExtractionJob
+	}
+	enqueueJob(ctx, store, data, jobType)
+}
+
+func enqueueJob(ctx context.Context, store storage.Storage, data *models.UnstructuredData, jobType string) {
+	if err := store.SaveProcessingJob(ctx, storage.NewJob(data, jobType)); err != nil {
+		log.Printf("Failed to queue %s for %s: %v", jobType, data.ID, err)
+	}
+}
+
+// extractedTextKeys are the metadata keys set by the extraction jobs, which
+// a source keeps when it refreshes a record.
+var extractedTextKeys = []string{"description", "full_text", "extracted_at", "word_count", "byline", "document_format", "page_count", "pages", "tables"}
+
+// keepExtractedText carries text already extracted for a record over to
+// its refreshed copy, which only has the feed description.
+func keepExtractedText(record, existing *models.UnstructuredData) {
+	if extracted, _ := existing.Metadata["full_text"].(bool); !extracted {
+		return
+	}
+	record.Content = existing.Content
+	for _, key := range extractedTextKeys {
+		if value, ok := existing.Metadata[key]; ok {
+			record.Metadata[key] = value
+		}
+	}
+}
+
+// setExtractedText replaces a record's content with extracted text, keeping
+// the original description in metadata.
+func setExtractedText(data *models.UnstructuredData, text string, wordCount int) {
+	if data.Metadata == nil {
+		data.Metadata = make(map[string]interface{})
+	}
+	data.Metadata["description"] = data.Content
+	data.Metadata["full_text"] = true
+	data.Metadata["extracted_at"] = time.Now().UTC()
+	data.Metadata["word_count"] = wordCount
+	data.Content = text
+}
+
+// extractionTarget loads a job's record and checks that a link of it may be
+// fetched; link picks the link from the record. A nil record with a nil
+// error means there is nothing to fetch.
+func (w *Worker) extractionTarget(ctx context.Context, job ProcessingJob, link func(*models.UnstructuredData) string) (*models.UnstructuredData, *url.URL, error) {
+	data, err := w.manager.storage.GetUnstructuredData(ctx, job.DataID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load %s: %w", job.DataID, err)
+	}
+	target, err := url.Parse(link(data))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		log.Printf("%s: %s has no document URL", job.JobType, job.DataID)
+		return nil, nil, nil
+	}
+	if w.manager.articles.denied(target.Hostname()) {
+		log.Printf("%s: skipping %s, %s is denylisted", job.JobType, job.DataID, target.Hostname())
+		return nil, nil, nil
+	}
+	return data, target, nil
+}
+
+func (w *Worker) processArticleExtraction(ctx context.Context, job ProcessingJob) error {
+	fetcher := w.manager.articles
+	if fetcher == nil {
+		return nil
+	}
+
+	data, pageURL, err := w.extractionTarget(ctx, job, func(d *models.UnstructuredData) string { return d.URL })
+	if data == nil {
+		return err
+	}
+
+	article, err := fetcher.fetch(ctx, pageURL)
+	if errors.Is(err, errPDFArticle) {
+		enqueueJob(ctx, w.manager.storage, data, pdfExtractionJob)
+		return nil
+	}
+	if errors.Is(err, crawler.ErrDisallowed) {
+		log.Printf("%s: skipping %s, robots.txt disallows %s", job.JobType, job.DataID, data.URL)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("article extraction failed for %s: %w", data.URL, err)
+	}
+	// A page whose body is shorter than the feed description is a teaser or
+	// a consent wall; keep the description
+	if len(article.Text) <= len(data.Content) {
+		return nil
+	}
+
+	setExtractedText(data, article.Text, article.WordCount)
+	if article.Byline != "" {
+		data.Metadata["byline"] = article.Byline
+	}
+
+	if err := w.manager.storage.SaveUnstructuredData(ctx, data); err != nil {
+		return fmt.Errorf("failed to save %s: %w", job.DataID, err)
+	}
+	log.Printf("Extracted %d words of article text for %s", article.WordCount, job.DataID)
+	return nil
+}
@@ -0,0 +1,305 @@
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// rateDecisionKeywords mark central bank releases that announce a policy rate decision.
+var rateDecisionKeywords = []string{
+	"monetary policy decision",
+	"monetary policy statement",
+	"monetary policy summary",
+	"bank rate",
+	"policy rate",
+	"key ecb interest rates",
+	"statement on monetary policy",
+	"repo rate",
+	"resolution of the monetary policy committee",
+}
+
+// Central bank communication categories
+const (
+	CommunicationPressRelease    = "press_release"
+	CommunicationSpeech          = "speech"
+	CommunicationPolicyStatement = "policy_statement"
+)
+
+var (
+	// speechLink matches speech pages: BoE /speech/, RBI BS_SpeechesView,
+	// ECB /press/key/ and BoJ koen_ (講演) URLs
+	speechLink = regexp.MustCompile(`(?i)speech|/press/key/|/koen`)
+	// speakerName matches "Speech by Christine Lagarde, ..." and "... - speech by Andrew Bailey"
+	speakerName = regexp.MustCompile(`\b(?i:speech|remarks|keynote address|address) by (?:(?:Shri|Smt\.?|Dr\.?|Mr\.?|Ms\.?|Governor|Deputy Governor|President|Vice-President) )*([A-Z][\p{L}.'-]+(?: [A-Z][\p{L}.'-]+){0,3})`)
+)
+
+// rdfFeed covers RSS 1.0 feeds (e.g. the Bank of Japan) whose items sit at the document root.
+type rdfFeed struct {
+	XMLName xml.Name  `xml:"RDF"`
+	Items   []rdfItem `xml:"item"`
+}
+
+// rdfItem is an RSS 1.0 item, dated by dc:date rather than pubDate.
+type rdfItem struct {
+	RSSItem
+	Date string `xml:"http://purl.org/dc/elements/1.1/ date"`
+}
+
+type CentralBankSource struct {
+	storage storage.Storage
+	config  config.CentralBanksConfig
+	client  *http.Client
+	enabled bool
+}
+
+func NewCentralBankSource(store storage.Storage, cfg config.CentralBanksConfig) *CentralBankSource {
+	return &CentralBankSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+	}
+}
+
+func (c *CentralBankSource) Start(ctx context.Context) error {
+	if !c.enabled {
+		log.Println("Central bank source is disabled")
+		return nil
+	}
+
+	log.Printf("Starting central bank data source (%d feeds)...", len(c.config.Feeds))
+	go supervise(ctx, c.GetName(), c.ingestData)
+	return nil
+}
+
+func (c *CentralBankSource) Stop(ctx context.Context) error {
+	log.Println("Stopping central bank source...")
+	return nil
+}
+
+func (c *CentralBankSource) GetName() string {
+	return "centralbanks"
+}
+
+func (c *CentralBankSource) IsEnabled() bool {
+	return c.enabled
+}
+
+func (c *CentralBankSource) ingestData(ctx context.Context) {
+	c.fetchAll(ctx)
+
+	ticker := newPollTicker(c.config.UpdateInterval, c.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.fetchAll(ctx)
+		}
+	}
+}
+
+func (c *CentralBankSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, c.GetName())
+	defer poll.End()
+	for _, feed := range c.config.Feeds {
+		if err := c.fetchFeed(ctx, feed); err != nil {
+			log.Printf("Error fetching %s feed %s: %v", feed.Bank, feed.URL, err)
+		}
+	}
+}
+
+func (c *CentralBankSource) fetchFeed(ctx context.Context, feed config.CentralBankFeed) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", feed.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	items, err := decodeFeedItems(body)
+	if err != nil {
+		return parseError(ctx, err)
+	}
+
+	for _, item := range items {
+		if err := c.processItem(ctx, feed, item); err != nil {
+			log.Printf("Error saving %s item: %v", feed.Bank, err)
+		}
+	}
+
+	log.Printf("Processed %d %s items", len(items), feed.Bank)
+	return nil
+}
+
+func (c *CentralBankSource) processItem(ctx context.Context, feed config.CentralBankFeed, item RSSItem) error {
+	hash := md5.Sum([]byte(item.Link + item.Title))
+	bankKey := strings.ToLower(feed.Bank)
+	jurisdiction := strings.ToLower(feed.Jurisdiction)
+
+	id := fmt.Sprintf("%s-%x", bankKey, hash[:8])
+	existing, err := c.storage.GetUnstructuredData(ctx, id)
+	seen := err == nil && existing != nil
+
+	pubDate, err := parseFeedDate(item.PubDate)
+	switch {
+	case err == nil:
+	case seen:
+		// Undated items keep the date first stored, or every poll would be a new revision
+		pubDate = existing.PublishedAt
+	default:
+		pubDate = time.Now()
+	}
+
+	title := extraction.CleanText(item.Title)
+	content := extraction.CleanText(item.Description)
+	text := title + " " + content
+	category := classifyCommunication(feed.Category, item.Link, text)
+	tags := []string{bankKey, "central_bank", "monetary_policy", "jurisdiction_" + jurisdiction, category}
+	docType := "news"
+	if category == CommunicationPolicyStatement {
+		tags = append(tags, "rate_decision", "macro_event")
+		docType = "macro_event"
+	}
+
+	metadata := map[string]interface{}{
+		"guid":         item.GUID,
+		"bank":         feed.Bank,
+		"jurisdiction": feed.Jurisdiction,
+		"category":     category,
+	}
+	if docType == "macro_event" {
+		metadata["priority"] = macroEventPriority
+	}
+	if category == CommunicationSpeech {
+		if match := speakerName.FindStringSubmatch(title); match != nil {
+			metadata["speaker"] = strings.TrimRight(match[1], ".,")
+		}
+	}
+
+	data := &models.UnstructuredData{
+		ID:          id,
+		Source:      bankKey,
+		Type:        docType,
+		Title:       title,
+		Content:     content,
+		URL:         strings.TrimSpace(item.Link),
+		Author:      feed.Bank,
+		PublishedAt: pubDate,
+		IngestedAt:  time.Now(),
+		Metadata:    metadata,
+		Tags:        tags,
+	}
+
+	if seen {
+		keepExtractedText(data, existing)
+	}
+	if err := c.storage.SaveUnstructuredData(ctx, data); err != nil {
+		return err
+	}
+	// Statements and speeches published only as PDFs carry just a title in the feed
+	if !seen && isPDFLink(data.URL) {
+		enqueueJob(ctx, c.storage, data, pdfExtractionJob)
+	}
+	return nil
+}
+
+// classifyCommunication decides whether an item is a policy statement, a
+// speech or, failing both, the feed's own category. Feeds such as the ECB's
+// press feed mix all three.
+func classifyCommunication(feedCategory, link, text string) string {
+	switch {
+	case isRateDecision(text):
+		return CommunicationPolicyStatement
+	case feedCategory == CommunicationSpeech || speechLink.MatchString(link):
+		return CommunicationSpeech
+	case feedCategory != "":
+		return feedCategory
+	}
+	return CommunicationPressRelease
+}
+
+func isRateDecision(text string) bool {
+	text = strings.ToLower(text)
+	for _, keyword := range rateDecisionKeywords {
+		if strings.Contains(text, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeFeedItems parses both RSS 2.0 and RSS 1.0 (RDF) documents.
+func decodeFeedItems(body []byte) ([]RSSItem, error) {
+	if bytes.Contains(body, []byte("<rdf:RDF")) {
+		var feed rdfFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, err
+		}
+		items := make([]RSSItem, 0, len(feed.Items))
+		for _, item := range feed.Items {
+			if item.PubDate == "" {
+				item.PubDate = item.Date
+			}
+			items = append(items, item.RSSItem)
+		}
+		return items, nil
+	}
+
+	var feed RSSFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, err
+	}
+	return feed.Channel.Items, nil
+}
+
+func parseFeedDate(dateStr string) (time.Time, error) {
+	formats := []string{
+		time.RFC1123,
+		time.RFC1123Z,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		time.RFC3339,
+		"2006-01-02T15:04:05-07:00",
+		"2006-01-02",
+	}
+
+	dateStr = strings.TrimSpace(dateStr)
+	for _, format := range formats {
+		if t, err := time.Parse(format, dateStr); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse date: %s", dateStr)
+}
@@ -0,0 +1,32 @@
+package ingestion
+
+import (
+	"log"
+	"time"
+)
+
+// syncCompanies loads the stored companies into the resolver, then merges
+// the dictionary, SEC tickers and companies file into them at start and
+// every sync interval after.
+func (m *Manager) syncCompanies() {
+	defer m.wg.Done()
+
+	if err := m.resolver.Load(m.ctx); err != nil {
+		log.Printf("Failed to load companies: %v", err)
+	}
+
+	ticker := time.NewTicker(m.config.Entities.SyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.resolver.Sync(m.ctx, m.config.Entities, m.entities); err != nil {
+			log.Printf("Company sync failed: %v", err)
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
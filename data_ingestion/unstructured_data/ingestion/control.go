@@ -0,0 +1,271 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/tracing"
+)
+
+// ErrUnknownSource is returned for a source the manager neither runs nor
+// finds in its configuration.
+var ErrUnknownSource = errors.New("unknown source")
+
+// sourceStopTimeout bounds the Stop of a source replaced or disabled at runtime.
+const sourceStopTimeout = 10 * time.Second
+
+// runningSource is a source the manager created, with the context it runs
+// under while it is started.
+type runningSource struct {
+	kind    string
+	source  DataSource
+	cancel  context.CancelFunc // nil while stopped
+	started time.Time
+}
+
+// SourceStatus describes a source for the admin API.
+// The activity fields are only filled in once the manager has an Activity,
+// the health fields while source health is monitored and Stream for the
+// sources that hold a connection open.
+type SourceStatus struct {
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind,omitempty"`
+	Running   bool       `json:"running"`
+	Interval  string     `json:"interval,omitempty"`
+	Schedule  string     `json:"schedule,omitempty"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+
+	LastFetchAt  *time.Time `json:"last_fetch_at,omitempty"`
+	ItemsToday   int        `json:"items_today"`
+	FetchesToday int        `json:"fetches_today"`
+	ErrorsToday  int        `json:"errors_today"`
+	LastError    string     `json:"last_error,omitempty"`
+	LastErrorAt  *time.Time `json:"last_error_at,omitempty"`
+
+	Health              string     `json:"health,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures,omitempty"`
+	ConsecutiveEmpty    int        `json:"consecutive_empty,omitempty"`
+	ParseErrorRate      float64    `json:"parse_error_rate,omitempty"`
+	NextProbeAt         *time.Time `json:"next_probe_at,omitempty"`
+
+	Stream *StreamStats `json:"stream,omitempty"`
+}
+
+// Sources lists the sources the manager runs or could run, by name.
+func (m *Manager) Sources() []SourceStatus {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+
+	names := m.sourceConfig.SourceNames()
+	for name := range m.sources {
+		if _, ok := m.sourceConfig.Interval(name); !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	statuses := make([]SourceStatus, 0, len(names))
+	for _, name := range names {
+		status := SourceStatus{Name: name}
+		if interval, ok := m.sourceConfig.Interval(name); ok {
+			status.Interval = interval.String()
+		}
+		status.Schedule = m.sourceConfig.Schedule(name)
+		if run, ok := m.sources[name]; ok {
+			status.Kind = run.kind
+			if run.cancel != nil {
+				started := run.started
+				status.Running, status.StartedAt = true, &started
+			}
+			if s, ok := run.source.(streamSource); ok {
+				stats := s.streamStats()
+				status.Stream = &stats
+			}
+		}
+		if m.activity != nil {
+			m.activity.describe(&status)
+		}
+		if m.health != nil {
+			m.health.describe(&status)
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// EnableSource starts the named source if it is not running, also when
+// its health suspended it.
+func (m *Manager) EnableSource(name string) error {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	if err := m.knownSource(name); err != nil {
+		return err
+	}
+	m.health.reset(name)
+	if run, ok := m.sources[name]; ok && run.cancel != nil {
+		return nil
+	}
+	if cfg, err := m.sourceConfig.WithEnabled(name, true); err == nil {
+		m.sourceConfig = cfg
+	}
+	return m.replaceSource(name)
+}
+
+// DisableSource stops the named source until it is enabled again.
+func (m *Manager) DisableSource(name string) error {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	if err := m.knownSource(name); err != nil {
+		return err
+	}
+	m.health.reset(name)
+	if cfg, err := m.sourceConfig.WithEnabled(name, false); err == nil {
+		m.sourceConfig = cfg
+	}
+	if run, ok := m.sources[name]; ok && run.cancel != nil {
+		m.stopSource(name, run)
+	}
+	return nil
+}
+
+// FetchSource makes the named source poll now rather than at its next
+// interval, by replacing it with a fresh instance; sources poll as they
+// start. What the old instance remembered, such as the items it has seen,
+// is lost, and items it stored are saved again over themselves.
+func (m *Manager) FetchSource(name string) error {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	if err := m.knownSource(name); err != nil {
+		return err
+	}
+	if run, ok := m.sources[name]; !ok || run.cancel == nil {
+		return fmt.Errorf("source %s is not running", name)
+	}
+	return m.replaceSource(name)
+}
+
+// SetSourceInterval changes how often the named source polls, restarting
+// it if it is running. Like every runtime change, it lasts until the
+// service restarts.
+func (m *Manager) SetSourceInterval(name string, interval time.Duration) error {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	if err := m.knownSource(name); err != nil {
+		return err
+	}
+	if _, ok := m.sourceConfig.Interval(name); !ok {
+		return fmt.Errorf("source %s has no configurable interval", name)
+	}
+	cfg, err := m.sourceConfig.WithInterval(name, interval)
+	if err != nil {
+		return err
+	}
+	m.sourceConfig = cfg
+	if run, ok := m.sources[name]; ok && run.cancel != nil {
+		return m.replaceSource(name)
+	}
+	return nil
+}
+
+// knownSource checks the manager runs or can create the named source; the
+// caller holds m.sourcesMu. It also refuses changes once the manager stops.
+func (m *Manager) knownSource(name string) error {
+	if m.ctx.Err() != nil {
+		return errors.New("ingestion manager is stopped")
+	}
+	if _, ok := m.sources[name]; ok {
+		return nil
+	}
+	if _, ok := m.sourceConfig.Interval(name); ok {
+		return nil
+	}
+	return fmt.Errorf("%w %q", ErrUnknownSource, name)
+}
+
+// replaceSource stops the named source, if it runs, and starts a new
+// instance created from m.sourceConfig; the caller holds m.sourcesMu.
+func (m *Manager) replaceSource(name string) error {
+	var kind string
+	if run, ok := m.sources[name]; ok {
+		kind = run.kind
+	}
+	source, kind, err := createSource(m.storage, m.sourceConfig, name, kind)
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("source %s is not enabled by its configuration", name)
+	}
+
+	if run, ok := m.sources[name]; ok && run.cancel != nil {
+		m.stopSource(name, run)
+	}
+	run := &runningSource{kind: kind, source: source}
+	m.sources[name] = run
+	m.startSource(name, run)
+	return nil
+}
+
+// startSource routes the source's requests through the rate limiter, and
+// records and traces them as configured, and starts it under its own
+// context, which its polls report their health under; the caller holds
+// m.sourcesMu.
+func (m *Manager) startSource(name string, run *runningSource) {
+	if s, ok := run.source.(httpSource); ok {
+		client := s.httpClient()
+		m.limiter.Wrap(client, m.limiter.Policy(name))
+		if m.activity != nil {
+			client.Transport = &activityTransport{activity: m.activity, source: name, base: client.Transport}
+		}
+		if tracing.Enabled() {
+			tracing.WrapClient(client, name)
+		}
+	}
+	ctx := withSource(m.ctx, name)
+	if m.health != nil {
+		ctx = withHealth(ctx, m.health)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	run.cancel, run.started = cancel, time.Now()
+
+	log.Printf("Starting data source: %s", name)
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		if err := run.source.Start(ctx); err != nil {
+			log.Printf("Error starting source %s: %v", name, err)
+		}
+	}()
+}
+
+// stopSource cancels the source's context and stops it; the caller holds
+// m.sourcesMu.
+func (m *Manager) stopSource(name string, run *runningSource) {
+	run.cancel()
+	run.cancel = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), sourceStopTimeout)
+	defer cancel()
+	log.Printf("Stopping data source: %s", name)
+	if err := run.source.Stop(ctx); err != nil {
+		log.Printf("Error stopping source %s: %v", name, err)
+	}
+}
+
+// EconomicCalendar returns the economic calendar source, or nil when it is
+// not configured. Its events and release windows are known once it has
+// fetched the calendar.
+func (m *Manager) EconomicCalendar() *EconomicCalendarSource {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	run, ok := m.sources[economicCalendarSource]
+	if !ok {
+		return nil
+	}
+	calendar, _ := run.source.(*EconomicCalendarSource)
+	return calendar
+}
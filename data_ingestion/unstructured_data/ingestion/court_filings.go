@@ -0,0 +1,383 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/backfill"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const (
+	CaseBankruptcy = "bankruptcy"
+	CaseLitigation = "litigation"
+
+	// courtSearchPages caps the result pages followed per issuer and poll
+	courtSearchPages = 5
+)
+
+var (
+	chapterPattern = regexp.MustCompile(`(?i)\bchapter\s+(7|9|11|12|13|15)\b`)
+	inRePrefix     = regexp.MustCompile(`(?i)^in\s+re:?\s*`)
+	versusPattern  = regexp.MustCompile(`(?i)\s+v(?:s)?\.?\s+`)
+)
+
+// legalJobs are queued for dockets seen for the first time.
+var legalJobs = []string{"entity_extraction", "credit_event_classification", "summarization"}
+
+// CourtSearchResponse is a page of CourtListener RECAP search results.
+type CourtSearchResponse struct {
+	Count   int           `json:"count"`
+	Next    string        `json:"next"`
+	Results []CourtDocket `json:"results"`
+}
+
+type CourtDocket struct {
+	DocketID       int64    `json:"docket_id"`
+	CaseName       string   `json:"caseName"`
+	DocketNumber   string   `json:"docketNumber"`
+	Court          string   `json:"court"`
+	CourtID        string   `json:"court_id"`
+	Cause          string   `json:"cause"`
+	SuitNature     string   `json:"suitNature"`
+	Chapter        *int     `json:"chapter"`
+	DateFiled      string   `json:"dateFiled"`
+	DateTerminated string   `json:"dateTerminated"`
+	AssignedTo     string   `json:"assignedTo"`
+	Party          []string `json:"party"`
+	AbsoluteURL    string   `json:"absolute_url"`
+}
+
+type CourtFilingsSource struct {
+	storage storage.Storage
+	config  config.CourtFilingsConfig
+	client  *http.Client
+	enabled bool
+	issuers []backfill.Issuer
+
+	mu   sync.Mutex
+	seen map[string]bool // docket record IDs already stored
+}
+
+func NewCourtFilingsSource(store storage.Storage, cfg config.CourtFilingsConfig) *CourtFilingsSource {
+	return &CourtFilingsSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+		seen:    make(map[string]bool),
+	}
+}
+
+func (c *CourtFilingsSource) Start(ctx context.Context) error {
+	if !c.enabled {
+		log.Println("Court filings source is disabled")
+		return nil
+	}
+
+	issuers, err := c.loadIssuers()
+	if err != nil {
+		return err
+	}
+	if len(issuers) == 0 {
+		return fmt.Errorf("no court filing issuers configured")
+	}
+	c.issuers = backfill.CompileIssuers(issuers)
+
+	log.Printf("Starting court filings data source (%d issuers)...", len(c.issuers))
+	go supervise(ctx, c.GetName(), c.ingestData)
+	return nil
+}
+
+func (c *CourtFilingsSource) Stop(ctx context.Context) error {
+	log.Println("Stopping court filings source...")
+	return nil
+}
+
+func (c *CourtFilingsSource) GetName() string {
+	return "court_filings"
+}
+
+func (c *CourtFilingsSource) IsEnabled() bool {
+	return c.enabled
+}
+
+func (c *CourtFilingsSource) loadIssuers() ([]backfill.Issuer, error) {
+	if c.config.IssuersFile != "" {
+		return backfill.LoadIssuers(c.config.IssuersFile)
+	}
+	issuers := make([]backfill.Issuer, 0, len(c.config.Issuers))
+	for _, issuer := range c.config.Issuers {
+		issuers = append(issuers, backfill.Issuer{Symbol: issuer.Symbol, Names: issuer.Names})
+	}
+	return issuers, nil
+}
+
+func (c *CourtFilingsSource) ingestData(ctx context.Context) {
+	c.fetchAll(ctx)
+
+	ticker := newPollTicker(c.config.UpdateInterval, c.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.fetchAll(ctx)
+		}
+	}
+}
+
+func (c *CourtFilingsSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, c.GetName())
+	defer poll.End()
+	filedAfter := time.Now().AddDate(0, 0, -c.config.LookbackDays).Format("2006-01-02")
+	for _, issuer := range c.issuers {
+		if err := c.searchIssuer(ctx, issuer, filedAfter); err != nil {
+			log.Printf("Error searching court filings for %s: %v", issuer.Symbol, err)
+		}
+	}
+}
+
+// searchIssuer pages through the dockets filed since filedAfter whose case
+// name or parties mention one of the issuer's names.
+func (c *CourtFilingsSource) searchIssuer(ctx context.Context, issuer backfill.Issuer, filedAfter string) error {
+	quoted := make([]string, len(issuer.Names))
+	for i, name := range issuer.Names {
+		quoted[i] = strconv.Quote(name)
+	}
+	names := strings.Join(quoted, " OR ")
+
+	params := url.Values{}
+	params.Set("type", "r")
+	params.Set("q", fmt.Sprintf("caseName:(%s) OR party:(%s)", names, names))
+	params.Set("filed_after", filedAfter)
+	params.Set("order_by", "dateFiled desc")
+	next := c.config.BaseURL + "/search/?" + params.Encode()
+
+	stored := 0
+	for page := 0; next != "" && page < courtSearchPages; page++ {
+		var response CourtSearchResponse
+		if err := c.get(ctx, next, &response); err != nil {
+			return err
+		}
+		for _, docket := range response.Results {
+			symbols := backfill.MatchIssuers(c.issuers, docketOrganizations(docket))
+			if len(symbols) == 0 {
+				continue
+			}
+			if err := c.saveDocket(ctx, docket, symbols); err != nil {
+				log.Printf("Error saving docket %s: %v", docket.DocketNumber, err)
+				continue
+			}
+			stored++
+		}
+		next = response.Next
+	}
+
+	log.Printf("Processed %d court dockets for %s", stored, issuer.Symbol)
+	return nil
+}
+
+func (c *CourtFilingsSource) get(ctx context.Context, requestURL string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+	if c.config.APIToken != "" {
+		req.Header.Set("Authorization", "Token "+c.config.APIToken)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to search dockets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("CourtListener API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode search results: %w", err))
+	}
+	return nil
+}
+
+func (c *CourtFilingsSource) saveDocket(ctx context.Context, docket CourtDocket, symbols []string) error {
+	filing := buildLegalFiling(docket, symbols)
+
+	data := filing.UnstructuredData
+	data.Metadata = map[string]interface{}{
+		"docket_id":    docket.DocketID,
+		"case_number":  filing.CaseNumber,
+		"court":        filing.Court,
+		"court_id":     filing.CourtID,
+		"case_type":    filing.CaseType,
+		"chapter":      filing.Chapter,
+		"cause":        docket.Cause,
+		"nature":       docket.SuitNature,
+		"judge":        docket.AssignedTo,
+		"parties":      filing.Parties,
+		"symbols":      filing.Symbols,
+		"filed_at":     filing.FiledAt,
+		"terminated":   filing.Terminated,
+		"credit_event": filing.CaseType == CaseBankruptcy,
+	}
+	if filing.CaseType == CaseBankruptcy {
+		data.Metadata["priority"] = macroEventPriority
+	}
+
+	isNew := !c.known(ctx, data.ID)
+	if err := c.storage.SaveUnstructuredData(ctx, &data); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.seen[data.ID] = true
+	c.mu.Unlock()
+
+	if isNew {
+		c.enqueueJobs(ctx, data.ID, filing.CaseType == CaseBankruptcy)
+	}
+	return nil
+}
+
+// known reports whether a docket was already stored, by this process or an
+// earlier one. Known dockets are still saved again to pick up status changes.
+func (c *CourtFilingsSource) known(ctx context.Context, id string) bool {
+	c.mu.Lock()
+	seen := c.seen[id]
+	c.mu.Unlock()
+	if seen {
+		return true
+	}
+	existing, err := c.storage.GetUnstructuredData(ctx, id)
+	return err == nil && existing != nil
+}
+
+func (c *CourtFilingsSource) enqueueJobs(ctx context.Context, dataID string, creditEvent bool) {
+	priority := 0
+	if creditEvent {
+		priority = macroEventPriority
+	}
+	for _, jobType := range legalJobs {
+		hash := md5.Sum([]byte(dataID + jobType))
+		job := &models.ProcessingJob{
+			ID:        fmt.Sprintf("job-%x", hash[:8]),
+			DataID:    dataID,
+			JobType:   jobType,
+			Status:    "pending",
+			CreatedAt: time.Now(),
+			Priority:  priority,
+		}
+		if err := c.storage.SaveProcessingJob(ctx, job); err != nil {
+			log.Printf("Failed to queue %s for %s: %v", jobType, dataID, err)
+		}
+	}
+}
+
+// docketOrganizations lists the parties and the sides of the case name in the
+// "name;name;..." form MatchIssuers expects.
+func docketOrganizations(docket CourtDocket) string {
+	names := append([]string{}, docket.Party...)
+	caseName := inRePrefix.ReplaceAllString(strings.TrimSpace(docket.CaseName), "")
+	names = append(names, versusPattern.Split(caseName, -1)...)
+	return strings.Join(names, ";")
+}
+
+func buildLegalFiling(docket CourtDocket, symbols []string) *models.LegalFiling {
+	filedAt, err := time.Parse("2006-01-02", docket.DateFiled)
+	if err != nil {
+		filedAt = time.Now()
+	}
+	var terminated *time.Time
+	if t, err := time.Parse("2006-01-02", docket.DateTerminated); err == nil {
+		terminated = &t
+	}
+
+	caseType, chapter := classifyDocket(docket)
+
+	tags := []string{"courtlistener", "legal", caseType}
+	if chapter > 0 {
+		tags = append(tags, fmt.Sprintf("chapter_%d", chapter))
+	}
+	if caseType == CaseBankruptcy {
+		tags = append(tags, "credit_event", "high_priority")
+	}
+	for _, symbol := range symbols {
+		tags = append(tags, strings.ToLower(symbol))
+	}
+
+	title := docket.CaseName
+	if docket.DocketNumber != "" {
+		title = fmt.Sprintf("%s (%s, %s)", docket.CaseName, docket.DocketNumber, docket.Court)
+	}
+	var content []string
+	for _, line := range []string{docket.Cause, docket.SuitNature} {
+		if line != "" {
+			content = append(content, line)
+		}
+	}
+
+	var entities []models.Entity
+	for _, symbol := range symbols {
+		entities = append(entities, models.Entity{Name: symbol, Type: "ORG", Confidence: 1})
+	}
+
+	return &models.LegalFiling{
+		UnstructuredData: models.UnstructuredData{
+			ID:          fmt.Sprintf("court-%d", docket.DocketID),
+			Source:      "court_filings",
+			Type:        "legal",
+			Title:       title,
+			Content:     strings.Join(content, "\n"),
+			URL:         "https://www.courtlistener.com" + docket.AbsoluteURL,
+			Author:      docket.Court,
+			PublishedAt: filedAt,
+			IngestedAt:  time.Now(),
+			Tags:        tags,
+			Entities:    entities,
+		},
+		CaseNumber: docket.DocketNumber,
+		Court:      docket.Court,
+		CourtID:    docket.CourtID,
+		CaseType:   caseType,
+		Chapter:    chapter,
+		Symbols:    symbols,
+		Parties:    docket.Party,
+		FiledAt:    filedAt,
+		Terminated: terminated,
+	}
+}
+
+// classifyDocket separates bankruptcy cases from other litigation. Bankruptcy
+// court IDs end in "b" (nysb, deb, txsb); the chapter comes from the docket
+// or, failing that, the case name or cause.
+func classifyDocket(docket CourtDocket) (string, int) {
+	chapter := 0
+	if docket.Chapter != nil {
+		chapter = *docket.Chapter
+	} else if match := chapterPattern.FindStringSubmatch(docket.CaseName + " " + docket.Cause); match != nil {
+		chapter, _ = strconv.Atoi(match[1])
+	}
+
+	if chapter > 0 || strings.HasSuffix(docket.CourtID, "b") {
+		return CaseBankruptcy, chapter
+	}
+	return CaseLitigation, 0
+}
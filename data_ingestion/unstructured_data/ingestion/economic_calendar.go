@@ -0,0 +1,359 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const economicCalendarSource = "economic_calendar"
+
+type EconomicEvent struct {
+	Event    string    `json:"event"`
+	Country  string    `json:"country"`
+	Time     time.Time `json:"time"`
+	Impact   string    `json:"impact"`
+	Estimate *float64  `json:"estimate"`
+	Actual   *float64  `json:"actual"`
+	Previous *float64  `json:"prev"`
+	Unit     string    `json:"unit"`
+	Major    bool      `json:"major"`
+}
+
+// PreEventFlags describe how close we are to the next scheduled major release.
+// Downstream consumers use them to contextualize volatility and to suppress
+// anomaly alerts that are explained by a scheduled release.
+type PreEventFlags struct {
+	NextMajorEvent         string    `json:"next_major_event,omitempty"`
+	NextMajorEventTime     time.Time `json:"next_major_event_time,omitempty"`
+	HoursToMajorRelease    float64   `json:"hours_to_major_release"`
+	HoursSinceMajorRelease float64   `json:"hours_since_major_release"`
+	PreEventWindow         bool      `json:"pre_event_window"`
+	PostEventWindow        bool      `json:"post_event_window"`
+}
+
+type finnhubEconomicEvent struct {
+	Actual   *float64 `json:"actual"`
+	Country  string   `json:"country"`
+	Estimate *float64 `json:"estimate"`
+	Event    string   `json:"event"`
+	Impact   string   `json:"impact"`
+	Prev     *float64 `json:"prev"`
+	Time     string   `json:"time"`
+	Unit     string   `json:"unit"`
+}
+
+type EconomicCalendarSource struct {
+	storage storage.Storage
+	config  config.EconomicCalendarConfig
+	client  *http.Client
+	enabled bool
+
+	mu     sync.RWMutex
+	events []EconomicEvent
+}
+
+func NewEconomicCalendarSource(store storage.Storage, cfg config.EconomicCalendarConfig) *EconomicCalendarSource {
+	return &EconomicCalendarSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled && (cfg.Provider != "finnhub" || cfg.APIKey != ""),
+	}
+}
+
+func (e *EconomicCalendarSource) Start(ctx context.Context) error {
+	if !e.enabled {
+		log.Println("Economic calendar source is disabled")
+		return nil
+	}
+
+	log.Printf("Starting economic calendar data source (provider: %s)...", e.config.Provider)
+	go supervise(ctx, e.GetName(), e.ingestData)
+	return nil
+}
+
+func (e *EconomicCalendarSource) Stop(ctx context.Context) error {
+	log.Println("Stopping economic calendar source...")
+	return nil
+}
+
+func (e *EconomicCalendarSource) GetName() string {
+	return economicCalendarSource
+}
+
+func (e *EconomicCalendarSource) IsEnabled() bool {
+	return e.enabled
+}
+
+func (e *EconomicCalendarSource) ingestData(ctx context.Context) {
+	if err := e.fetchCalendar(ctx); err != nil {
+		log.Printf("Error in initial economic calendar fetch: %v", err)
+	}
+
+	ticker := newPollTicker(e.config.UpdateInterval, e.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.fetchCalendar(ctx); err != nil {
+				log.Printf("Error fetching economic calendar: %v", err)
+			}
+		}
+	}
+}
+
+func (e *EconomicCalendarSource) fetchCalendar(ctx context.Context) error {
+	ctx, poll := startPoll(ctx, e.GetName())
+	defer poll.End()
+	from := time.Now().AddDate(0, 0, -1)
+	to := time.Now().AddDate(0, 0, e.config.LookaheadDays)
+
+	var events []EconomicEvent
+	var err error
+	switch e.config.Provider {
+	case "finnhub":
+		events, err = e.fetchFinnhub(ctx, from, to)
+	case "url":
+		events, err = e.fetchURL(ctx)
+	default:
+		return fmt.Errorf("unknown economic calendar provider %q", e.config.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	var filtered []EconomicEvent
+	for _, event := range events {
+		if !e.countryEnabled(event.Country) {
+			continue
+		}
+		event.Major = e.isMajor(event)
+		filtered = append(filtered, event)
+
+		if err := e.saveEvent(ctx, event); err != nil {
+			log.Printf("Error saving economic event %s: %v", event.Event, err)
+		}
+	}
+
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Time.Before(filtered[j].Time) })
+
+	e.mu.Lock()
+	e.events = filtered
+	e.mu.Unlock()
+
+	log.Printf("Processed %d economic calendar events", len(filtered))
+	return nil
+}
+
+func (e *EconomicCalendarSource) fetchFinnhub(ctx context.Context, from, to time.Time) ([]EconomicEvent, error) {
+	calURL := fmt.Sprintf("%s/calendar/economic?from=%s&to=%s",
+		e.config.BaseURL, from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	var payload struct {
+		EconomicCalendar []finnhubEconomicEvent `json:"economicCalendar"`
+	}
+	if err := e.getJSON(ctx, calURL, e.config.APIKey, &payload); err != nil {
+		return nil, err
+	}
+
+	events := make([]EconomicEvent, 0, len(payload.EconomicCalendar))
+	for _, item := range payload.EconomicCalendar {
+		eventTime, err := time.ParseInLocation("2006-01-02 15:04:05", item.Time, time.UTC)
+		if err != nil {
+			continue
+		}
+		events = append(events, EconomicEvent{
+			Event:    item.Event,
+			Country:  item.Country,
+			Time:     eventTime,
+			Impact:   item.Impact,
+			Estimate: item.Estimate,
+			Actual:   item.Actual,
+			Previous: item.Prev,
+			Unit:     item.Unit,
+		})
+	}
+	return events, nil
+}
+
+// fetchURL reads events from a JSON endpoint that already serves []EconomicEvent.
+func (e *EconomicCalendarSource) fetchURL(ctx context.Context) ([]EconomicEvent, error) {
+	var events []EconomicEvent
+	if err := e.getJSON(ctx, e.config.BaseURL, "", &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// getJSON decodes the response of url into v, sending token as the Finnhub API
+// key when set.
+func (e *EconomicCalendarSource) getJSON(ctx context.Context, url, token string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if token != "" {
+		req.Header.Set(finnhubTokenHeader, token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode calendar response: %w", err))
+	}
+	return nil
+}
+
+func (e *EconomicCalendarSource) saveEvent(ctx context.Context, event EconomicEvent) error {
+	hash := md5.Sum([]byte(event.Country + event.Event + event.Time.Format(time.RFC3339)))
+
+	tags := []string{"economic_calendar", "economic_event", strings.ToLower(event.Country)}
+	if event.Major {
+		tags = append(tags, "major_release")
+	}
+	if event.Impact != "" {
+		tags = append(tags, "impact_"+strings.ToLower(event.Impact))
+	}
+
+	status := "scheduled"
+	if event.Actual != nil {
+		status = "released"
+	}
+
+	data := &models.UnstructuredData{
+		ID:          fmt.Sprintf("econcal-%x", hash[:8]),
+		Source:      "economic_calendar",
+		Type:        "economic_event",
+		Title:       fmt.Sprintf("%s %s", event.Country, event.Event),
+		Content:     describeEvent(event),
+		Author:      e.config.Provider,
+		PublishedAt: event.Time,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"event":    event.Event,
+			"country":  event.Country,
+			"impact":   event.Impact,
+			"estimate": event.Estimate,
+			"actual":   event.Actual,
+			"previous": event.Previous,
+			"unit":     event.Unit,
+			"major":    event.Major,
+			"status":   status,
+		},
+		Tags: tags,
+	}
+
+	return e.storage.SaveUnstructuredData(ctx, data)
+}
+
+func describeEvent(event EconomicEvent) string {
+	parts := []string{fmt.Sprintf("%s (%s) scheduled for %s", event.Event, event.Country, event.Time.Format(time.RFC3339))}
+	if event.Estimate != nil {
+		parts = append(parts, fmt.Sprintf("estimate %.2f%s", *event.Estimate, event.Unit))
+	}
+	if event.Previous != nil {
+		parts = append(parts, fmt.Sprintf("previous %.2f%s", *event.Previous, event.Unit))
+	}
+	if event.Actual != nil {
+		parts = append(parts, fmt.Sprintf("actual %.2f%s", *event.Actual, event.Unit))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (e *EconomicCalendarSource) countryEnabled(country string) bool {
+	if len(e.config.Countries) == 0 {
+		return true
+	}
+	for _, c := range e.config.Countries {
+		if strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *EconomicCalendarSource) isMajor(event EconomicEvent) bool {
+	name := strings.ToLower(event.Event)
+	for _, keyword := range e.config.MajorEvents {
+		if strings.Contains(name, strings.ToLower(keyword)) {
+			return true
+		}
+	}
+	return strings.EqualFold(event.Impact, "high")
+}
+
+// UpcomingEvents returns the cached events scheduled at or after t.
+func (e *EconomicCalendarSource) UpcomingEvents(t time.Time) []EconomicEvent {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var upcoming []EconomicEvent
+	for _, event := range e.events {
+		if !event.Time.Before(t) {
+			upcoming = append(upcoming, event)
+		}
+	}
+	return upcoming
+}
+
+// PreEventFlags computes pre/post-release features for time t.
+func (e *EconomicCalendarSource) PreEventFlags(t time.Time) PreEventFlags {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return computePreEventFlags(e.events, t, e.config.PreEventWindow, e.config.PostEventWindow)
+}
+
+// SuppressAnomalyAlerts reports whether t falls in the window around a major
+// scheduled release where price/volume anomalies are expected.
+func (e *EconomicCalendarSource) SuppressAnomalyAlerts(t time.Time) bool {
+	flags := e.PreEventFlags(t)
+	return flags.PreEventWindow || flags.PostEventWindow
+}
+
+func computePreEventFlags(events []EconomicEvent, t time.Time, preWindow, postWindow time.Duration) PreEventFlags {
+	flags := PreEventFlags{HoursToMajorRelease: -1, HoursSinceMajorRelease: -1}
+
+	for _, event := range events {
+		if !event.Major {
+			continue
+		}
+		if event.Time.After(t) {
+			if flags.NextMajorEvent == "" {
+				flags.NextMajorEvent = event.Event
+				flags.NextMajorEventTime = event.Time
+				flags.HoursToMajorRelease = event.Time.Sub(t).Hours()
+				flags.PreEventWindow = event.Time.Sub(t) <= preWindow
+			}
+		} else {
+			flags.HoursSinceMajorRelease = t.Sub(event.Time).Hours()
+			flags.PostEventWindow = t.Sub(event.Time) <= postWindow
+		}
+	}
+
+	return flags
+}
@@ -0,0 +1,292 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const (
+	// reviewTrendMonths is the window compared against the one before it
+	reviewTrendMonths = 3
+	// decliningRatingDelta is the drop in average rating, on the 1-5 scale,
+	// that marks employee sentiment as declining
+	decliningRatingDelta = -0.2
+	// layoffChatterShare is the share of recent reviews mentioning layoffs
+	// that marks a company as having layoff chatter
+	layoffChatterShare = 0.1
+)
+
+var layoffKeywords = []string{"layoff", "laid off", "lay off", "rif", "reduction in force", "job cuts", "restructuring", "hiring freeze", "downsizing"}
+
+// EmployeeReviewSummary is the review summary served by the configured
+// endpoint. Ratings are on a 1-5 scale and shares run from 0 to 1.
+type EmployeeReviewSummary struct {
+	Symbol            string                 `json:"symbol"`
+	Company           string                 `json:"company"`
+	Platform          string                 `json:"source"`
+	AsOf              string                 `json:"as_of"`
+	URL               string                 `json:"url"`
+	OverallRating     float64                `json:"overall_rating"`
+	ReviewCount       int                    `json:"review_count"`
+	RecommendToFriend float64                `json:"recommend_to_friend"`
+	CEOApproval       float64                `json:"ceo_approval"`
+	BusinessOutlook   float64                `json:"business_outlook"`
+	History           []EmployeeRatingMonth  `json:"history"`
+	RecentReviews     []EmployeeReviewSample `json:"recent_reviews"`
+}
+
+type EmployeeRatingMonth struct {
+	Month       string  `json:"month"` // YYYY-MM
+	Rating      float64 `json:"rating"`
+	ReviewCount int     `json:"review_count"`
+}
+
+type EmployeeReviewSample struct {
+	Title    string  `json:"title"`
+	Pros     string  `json:"pros"`
+	Cons     string  `json:"cons"`
+	Rating   float64 `json:"rating"`
+	Date     string  `json:"date"`
+	JobTitle string  `json:"job_title"`
+}
+
+type EmployeeReviewsSource struct {
+	storage storage.Storage
+	config  config.EmployeeReviewsConfig
+	client  *http.Client
+	enabled bool
+}
+
+func NewEmployeeReviewsSource(store storage.Storage, cfg config.EmployeeReviewsConfig) *EmployeeReviewsSource {
+	return &EmployeeReviewsSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+	}
+}
+
+func (e *EmployeeReviewsSource) Start(ctx context.Context) error {
+	if !e.enabled {
+		log.Println("Employee reviews source is disabled")
+		return nil
+	}
+	if e.config.BaseURL == "" {
+		return fmt.Errorf("employee reviews source has no endpoint configured")
+	}
+
+	log.Printf("Starting employee reviews data source (%d symbols)...", len(e.config.Symbols))
+	go supervise(ctx, e.GetName(), e.ingestData)
+	return nil
+}
+
+func (e *EmployeeReviewsSource) Stop(ctx context.Context) error {
+	log.Println("Stopping employee reviews source...")
+	return nil
+}
+
+func (e *EmployeeReviewsSource) GetName() string {
+	return "employee_reviews"
+}
+
+func (e *EmployeeReviewsSource) IsEnabled() bool {
+	return e.enabled
+}
+
+func (e *EmployeeReviewsSource) ingestData(ctx context.Context) {
+	e.fetchAll(ctx)
+
+	ticker := newPollTicker(e.config.UpdateInterval, e.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.fetchAll(ctx)
+		}
+	}
+}
+
+func (e *EmployeeReviewsSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, e.GetName())
+	defer poll.End()
+	for _, symbol := range e.config.Symbols {
+		if err := e.fetchSummary(ctx, symbol); err != nil {
+			log.Printf("Error fetching employee reviews for %s: %v", symbol, err)
+		}
+	}
+}
+
+func (e *EmployeeReviewsSource) fetchSummary(ctx context.Context, symbol string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", e.config.BaseURL+"?"+url.Values{"symbol": {symbol}}.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+	if e.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+e.config.APIKey)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch review summary: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("review endpoint returned status %d", resp.StatusCode)
+	}
+
+	var summary EmployeeReviewSummary
+	if err := json.NewDecoder(resp.Body).Decode(&summary); err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode review summary: %w", err))
+	}
+	if summary.Symbol == "" {
+		summary.Symbol = symbol
+	}
+
+	return e.storage.SaveUnstructuredData(ctx, buildReviewSnapshot(summary))
+}
+
+// buildReviewSnapshot stores one record per company and day; the trend and
+// layoff share carry the signal, the individual reviews only the context.
+func buildReviewSnapshot(summary EmployeeReviewSummary) *models.UnstructuredData {
+	asOf, err := time.Parse("2006-01-02", summary.AsOf)
+	if err != nil {
+		asOf = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	platform := summary.Platform
+	if platform == "" {
+		platform = "employee_reviews"
+	}
+
+	trend := ratingTrend(summary.History)
+	layoffShare, layoffQuotes := layoffMentions(summary.RecentReviews)
+
+	tags := []string{"employee_reviews", strings.ToLower(platform), strings.ToLower(summary.Symbol)}
+	if trend <= decliningRatingDelta {
+		tags = append(tags, "declining_employee_sentiment")
+	}
+	if layoffShare >= layoffChatterShare {
+		tags = append(tags, "layoff_chatter")
+	}
+
+	content := fmt.Sprintf("%s is rated %.1f/5 across %d employee reviews (%+.2f over the last %d months); %.0f%% would recommend it to a friend.",
+		summary.Company, summary.OverallRating, summary.ReviewCount, trend, reviewTrendMonths, summary.RecommendToFriend*100)
+	if len(layoffQuotes) > 0 {
+		content += "\n\n" + strings.Join(layoffQuotes, "\n")
+	}
+
+	return &models.UnstructuredData{
+		ID:          fmt.Sprintf("reviews-%s-%s", strings.ToLower(summary.Symbol), asOf.Format("2006-01-02")),
+		Source:      "employee_reviews",
+		Type:        "employee_reviews",
+		Title:       fmt.Sprintf("%s employee review trends", summary.Company),
+		Content:     content,
+		URL:         summary.URL,
+		Author:      platform,
+		PublishedAt: asOf,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"symbol":              summary.Symbol,
+			"symbols":             []string{summary.Symbol},
+			"company":             summary.Company,
+			"platform":            platform,
+			"overall_rating":      summary.OverallRating,
+			"review_count":        summary.ReviewCount,
+			"recommend_to_friend": summary.RecommendToFriend,
+			"ceo_approval":        summary.CEOApproval,
+			"business_outlook":    summary.BusinessOutlook,
+			"rating_trend":        trend,
+			"layoff_share":        layoffShare,
+			"history":             summary.History,
+		},
+		Tags:      tags,
+		Sentiment: reviewSentiment(summary, trend, layoffShare),
+	}
+}
+
+// ratingTrend compares the review-weighted average rating of the latest
+// reviewTrendMonths months with the months before them.
+func ratingTrend(history []EmployeeRatingMonth) float64 {
+	months := append([]EmployeeRatingMonth{}, history...)
+	sort.Slice(months, func(i, j int) bool { return months[i].Month < months[j].Month })
+	if len(months) < 2*reviewTrendMonths {
+		return 0
+	}
+
+	average := func(window []EmployeeRatingMonth) float64 {
+		var sum, weight float64
+		for _, m := range window {
+			w := float64(m.ReviewCount)
+			if w == 0 {
+				w = 1
+			}
+			sum += m.Rating * w
+			weight += w
+		}
+		return sum / weight
+	}
+
+	n := len(months)
+	recent := average(months[n-reviewTrendMonths:])
+	prior := average(months[n-2*reviewTrendMonths : n-reviewTrendMonths])
+	return math.Round((recent-prior)*100) / 100
+}
+
+// layoffMentions returns the share of reviews mentioning layoffs and the
+// titles of those reviews.
+func layoffMentions(reviews []EmployeeReviewSample) (float64, []string) {
+	if len(reviews) == 0 {
+		return 0, nil
+	}
+	var quotes []string
+	for _, review := range reviews {
+		text := " " + strings.ToLower(review.Title+" "+review.Pros+" "+review.Cons) + " "
+		for _, keyword := range layoffKeywords {
+			if strings.Contains(text, " "+keyword) {
+				quotes = append(quotes, strings.TrimSpace(review.Title))
+				break
+			}
+		}
+	}
+	return float64(len(quotes)) / float64(len(reviews)), quotes
+}
+
+// reviewSentiment maps the 1-5 rating onto -1..1 and keeps the trend and
+// layoff share as aspects.
+func reviewSentiment(summary EmployeeReviewSummary, trend, layoffShare float64) *models.SentimentScore {
+	if summary.OverallRating == 0 {
+		return nil
+	}
+	overall := math.Max(-1, math.Min(1, (summary.OverallRating-3)/2))
+	return &models.SentimentScore{
+		Overall:   overall,
+		Positive:  math.Max(0, overall),
+		Negative:  math.Max(0, -overall),
+		Neutral:   1 - math.Abs(overall),
+		Magnitude: math.Abs(overall),
+		Aspects: map[string]float64{
+			"rating_trend":        trend,
+			"layoff_share":        layoffShare,
+			"recommend_to_friend": summary.RecommendToFriend,
+			"business_outlook":    summary.BusinessOutlook,
+		},
+	}
+}
@@ -7,22 +7,40 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/entities"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/symbols"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// finnhubTokenHeader carries the Finnhub API key. A key in the query string
+// would end up in the *url.Error of any failed request, and from there in logs.
+const finnhubTokenHeader = "X-Finnhub-Token"
+
 type FinnhubSource struct {
 	storage storage.Storage
 	config  config.FinnhubConfig
 	client  *http.Client
-	conn    *websocket.Conn
 	enabled bool
+
+	mu          sync.Mutex
+	companyNews map[string]time.Time // date each symbol's company news was last fetched up to
+
+	// wsMu guards the trade WebSocket's connection, the symbols subscribed
+	// on it and its stats, and orders the messages written to it
+	wsMu          sync.Mutex
+	conn          *websocket.Conn
+	subscriptions map[string]bool
+	stream        streamState
 }
 
 type FinnhubNewsResponse struct {
@@ -40,6 +58,7 @@ type FinnhubNewsResponse struct {
 type FinnhubWebSocketMessage struct {
 	Data []FinnhubTradeData `json:"data"`
 	Type string             `json:"type"`
+	Msg  string             `json:"msg,omitempty"`
 }
 
 type FinnhubTradeData struct {
@@ -50,14 +69,20 @@ type FinnhubTradeData struct {
 }
 
 func NewFinnhubSource(store storage.Storage, cfg config.FinnhubConfig) *FinnhubSource {
-	return &FinnhubSource{
+	f := &FinnhubSource{
 		storage: store,
 		config:  cfg,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		enabled: cfg.Enabled && cfg.APIKey != "",
+		enabled:     cfg.Enabled && cfg.APIKey != "",
+		companyNews: make(map[string]time.Time),
 	}
+	f.subscriptions = make(map[string]bool, len(cfg.Symbols))
+	for _, symbol := range cfg.Symbols {
+		f.subscriptions[symbol] = true
+	}
+	return f
 }
 
 func (f *FinnhubSource) Start(ctx context.Context) error {
@@ -68,8 +93,9 @@ func (f *FinnhubSource) Start(ctx context.Context) error {
 
 	log.Println("Starting Finnhub data source...")
 
-	go f.ingestNews(ctx)
-	go f.startWebSocket(ctx)
+	go supervise(ctx, "finnhub-news", f.ingestNews)
+	go supervise(ctx, "finnhub-websocket", f.startWebSocket)
+	go supervise(ctx, "finnhub-fundamentals", f.ingestFundamentals)
 
 	return nil
 }
@@ -77,9 +103,11 @@ func (f *FinnhubSource) Start(ctx context.Context) error {
 func (f *FinnhubSource) Stop(ctx context.Context) error {
 	log.Println("Stopping Finnhub source...")
 
+	f.wsMu.Lock()
 	if f.conn != nil {
 		f.conn.Close()
 	}
+	f.wsMu.Unlock()
 
 	return nil
 }
@@ -93,32 +121,34 @@ func (f *FinnhubSource) IsEnabled() bool {
 }
 
 func (f *FinnhubSource) ingestNews(ctx context.Context) {
-	ticker := time.NewTicker(f.config.UpdateInterval)
-	defer ticker.Stop()
+	if err := f.fetchNews(ctx); err != nil {
+		log.Printf("Error in initial Finnhub news fetch: %v", err)
+	}
+	f.fetchAllCompanyNews(ctx)
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if err := f.fetchNews(ctx); err != nil {
-				log.Printf("Error fetching Finnhub news: %v", err)
-			}
+	schedule := newMarketSchedule(f.config.Symbols, f.config.UpdateInterval, f.config.ClosedInterval).withCron(f.config.Schedule)
+	schedule.run(ctx, "Finnhub news", func(ctx context.Context) {
+		if err := f.fetchNews(ctx); err != nil {
+			log.Printf("Error fetching Finnhub news: %v", err)
 		}
-	}
+		f.fetchAllCompanyNews(ctx)
+	})
 }
 
 func (f *FinnhubSource) fetchNews(ctx context.Context) error {
+	ctx, poll := startPoll(ctx, f.GetName())
+	defer poll.End()
 	from := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
 	to := time.Now().Format("2006-01-02")
 
-	newsURL := fmt.Sprintf("%s/news?category=general&from=%s&to=%s&token=%s",
-		f.config.RestAPIURL, from, to, f.config.APIKey)
+	newsURL := fmt.Sprintf("%s/news?category=general&from=%s&to=%s",
+		f.config.RestAPIURL, from, to)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", newsURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set(finnhubTokenHeader, f.config.APIKey)
 
 	resp, err := f.client.Do(req)
 	if err != nil {
@@ -132,11 +162,11 @@ func (f *FinnhubSource) fetchNews(ctx context.Context) error {
 
 	var newsItems []FinnhubNewsResponse
 	if err := json.NewDecoder(resp.Body).Decode(&newsItems); err != nil {
-		return fmt.Errorf("failed to decode news response: %w", err)
+		return parseError(ctx, fmt.Errorf("failed to decode news response: %w", err))
 	}
 
 	for _, item := range newsItems {
-		if err := f.processNewsItem(ctx, item); err != nil {
+		if err := f.processNewsItem(ctx, item, ""); err != nil {
 			log.Printf("Error processing news item %d: %v", item.ID, err)
 		}
 	}
@@ -145,21 +175,101 @@ func (f *FinnhubSource) fetchNews(ctx context.Context) error {
 	return nil
 }
 
-func (f *FinnhubSource) processNewsItem(ctx context.Context, item FinnhubNewsResponse) error {
-	
+func (f *FinnhubSource) fetchAllCompanyNews(ctx context.Context) {
+	ctx, poll := startPoll(ctx, f.GetName())
+	defer poll.End()
+	for _, symbol := range f.config.Symbols {
+		if err := f.fetchCompanyNews(ctx, symbol); err != nil {
+			log.Printf("Error fetching Finnhub company news for %s: %v", symbol, err)
+		}
+
+		// Stay inside the free tier's 60 calls a minute
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// fetchCompanyNews stores the news Finnhub attributes to symbol, from the day
+// it was last fetched up to (the first time, CompanyNewsLookbackDays back) to today.
+func (f *FinnhubSource) fetchCompanyNews(ctx context.Context, symbol string) error {
+	ticker, err := finnhubTicker(symbol)
+	if err != nil {
+		return err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	f.mu.Lock()
+	from, fetched := f.companyNews[symbol]
+	f.mu.Unlock()
+	if !fetched {
+		from = today.AddDate(0, 0, -f.config.CompanyNewsLookbackDays)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", ticker)
+	params.Set("from", from.Format("2006-01-02"))
+	params.Set("to", today.Format("2006-01-02"))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", f.config.RestAPIURL+"/company-news?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(finnhubTokenHeader, f.config.APIKey)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch company news: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var newsItems []FinnhubNewsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&newsItems); err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode company news response: %w", err))
+	}
+
+	for _, item := range newsItems {
+		if err := f.processNewsItem(ctx, item, symbol); err != nil {
+			log.Printf("Error processing news item %d: %v", item.ID, err)
+		}
+	}
+
+	// Re-fetch today next time: the window is whole days and today is not over
+	f.mu.Lock()
+	f.companyNews[symbol] = today
+	f.mu.Unlock()
+
+	log.Printf("Processed %d Finnhub company news items for %s", len(newsItems), symbol)
+	return nil
+}
+
+// processNewsItem stores a news item. symbol is the watchlist symbol the item
+// was fetched for, or "" for general news.
+func (f *FinnhubSource) processNewsItem(ctx context.Context, item FinnhubNewsResponse, symbol string) error {
 	hash := md5.Sum([]byte(item.URL + item.Headline))
 	dataID := fmt.Sprintf("finnhub-%x", hash[:8])
 
 	symbols := f.extractSymbols(item.Related)
+	if symbol != "" && !containsString(symbols, symbol) {
+		symbols = append([]string{symbol}, symbols...)
+	}
 
-	entities := f.extractEntities(item.Headline + " " + item.Summary)
+	title := extraction.CleanText(item.Headline)
+	summary := extraction.CleanText(item.Summary)
+	found := entities.Extract(title + " " + summary)
 
 	data := &models.UnstructuredData{
 		ID:          dataID,
 		Source:      "finnhub",
 		Type:        "news",
-		Title:       item.Headline,
-		Content:     item.Summary,
+		Title:       title,
+		Content:     summary,
 		URL:         item.URL,
 		Author:      item.Source,
 		PublishedAt: time.Unix(item.DateTime, 0),
@@ -171,7 +281,12 @@ func (f *FinnhubSource) processNewsItem(ctx context.Context, item FinnhubNewsRes
 			"finnhub_id": item.ID,
 		},
 		Tags:     f.generateTags(item),
-		Entities: entities,
+		Entities: found,
+	}
+	if symbol != "" {
+		data.Metadata["symbol"] = symbol
+		data.Metadata["attribution"] = "company_news"
+		data.Tags = append(data.Tags, "company_news", strings.ToLower(symbol))
 	}
 
 	return f.storage.SaveUnstructuredData(ctx, data)
@@ -182,37 +297,14 @@ func (f *FinnhubSource) extractSymbols(related string) []string {
 		return []string{}
 	}
 
-	symbols := strings.Split(related, ",")
-	var result []string
-	for _, symbol := range symbols {
-		symbol = strings.TrimSpace(symbol)
-		if symbol != "" {
-			result = append(result, symbol)
-		}
+	// Finnhub writes share classes as BRK.B; store the BRK-B form used everywhere else
+	result := symbols.NormalizeAll(strings.Split(related, ","))
+	if result == nil {
+		return []string{}
 	}
 	return result
 }
 
-func (f *FinnhubSource) extractEntities(text string) []models.Entity {
-	var entities []models.Entity
-
-	words := strings.Fields(text)
-	for i, word := range words {
-		word = strings.Trim(word, ".,!?;:")
-		if len(word) >= 3 && len(word) <= 5 && strings.ToUpper(word) == word {
-			entities = append(entities, models.Entity{
-				Name:       word,
-				Type:       "STOCK_SYMBOL",
-				Confidence: 0.8,
-				StartPos:   i * 5, 
-				EndPos:     i*5 + len(word),
-			})
-		}
-	}
-
-	return entities
-}
-
 func (f *FinnhubSource) generateTags(item FinnhubNewsResponse) []string {
 	tags := []string{"finnhub", "financial_news"}
 
@@ -220,87 +312,9 @@ func (f *FinnhubSource) generateTags(item FinnhubNewsResponse) []string {
 		tags = append(tags, item.Category)
 	}
 
-	headline := strings.ToLower(item.Headline)
-	summary := strings.ToLower(item.Summary)
-
-	negativeKeywords := []string{"loss", "decline", "drop", "fall", "bankruptcy", "debt", "crisis"}
-	positiveKeywords := []string{"gain", "rise", "growth", "profit", "success", "breakthrough"}
-
-	for _, keyword := range negativeKeywords {
-		if strings.Contains(headline, keyword) || strings.Contains(summary, keyword) {
-			tags = append(tags, "negative_sentiment")
-			break
-		}
-	}
-
-	for _, keyword := range positiveKeywords {
-		if strings.Contains(headline, keyword) || strings.Contains(summary, keyword) {
-			tags = append(tags, "positive_sentiment")
-			break
-		}
-	}
-
 	return tags
 }
 
-func (f *FinnhubSource) startWebSocket(ctx context.Context) {
-	if f.config.APIKey == "" {
-		log.Println("Finnhub API key not provided, skipping WebSocket connection")
-		return
-	}
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			if err := f.connectWebSocket(ctx); err != nil {
-				log.Printf("WebSocket connection error: %v", err)
-				time.Sleep(30 * time.Second) 
-			}
-		}
-	}
-}
-
-func (f *FinnhubSource) connectWebSocket(ctx context.Context) error {
-	wsURL := fmt.Sprintf("%s?token=%s", f.config.WebSocketURL, f.config.APIKey)
-
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
-	if err != nil {
-		return fmt.Errorf("failed to connect to WebSocket: %w", err)
-	}
-
-	f.conn = conn
-	defer conn.Close()
-	for _, symbol := range f.config.Symbols {
-		msg := map[string]interface{}{
-			"type":   "subscribe",
-			"symbol": symbol,
-		}
-		if err := conn.WriteJSON(msg); err != nil {
-			return fmt.Errorf("failed to subscribe to symbol %s: %w", symbol, err)
-		}
-	}
-
-	log.Printf("Connected to Finnhub WebSocket, subscribed to %d symbols", len(f.config.Symbols))
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil
-		default:
-			var msg FinnhubWebSocketMessage
-			if err := conn.ReadJSON(&msg); err != nil {
-				return fmt.Errorf("failed to read WebSocket message: %w", err)
-			}
-
-			if msg.Type == "trade" {
-				f.processTradeData(ctx, msg.Data)
-			}
-		}
-	}
-}
-
 func (f *FinnhubSource) processTradeData(ctx context.Context, trades []FinnhubTradeData) {
 	for _, trade := range trades {
 		data := &models.UnstructuredData{
@@ -0,0 +1,317 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// insiderTransactionLookback bounds the insider filings fetched per poll.
+const insiderTransactionLookback = 90 * 24 * time.Hour
+
+// earningsInlinePercent is the surprise, in percent of the estimate, within
+// which a quarter counts as in line.
+const earningsInlinePercent = 2.0
+
+// insiderCodes names the Form 4 transaction codes.
+var insiderCodes = map[string]string{
+	"P": "purchase",
+	"S": "sale",
+	"A": "award",
+	"M": "option_exercise",
+	"F": "tax_withholding",
+	"G": "gift",
+	"D": "disposition_to_issuer",
+	"C": "conversion",
+	"X": "option_exercise",
+}
+
+type FinnhubInsiderResponse struct {
+	Symbol string                      `json:"symbol"`
+	Data   []FinnhubInsiderTransaction `json:"data"`
+}
+
+type FinnhubInsiderTransaction struct {
+	Name             string  `json:"name"`
+	Share            int64   `json:"share"`
+	Change           int64   `json:"change"`
+	FilingDate       string  `json:"filingDate"`
+	TransactionDate  string  `json:"transactionDate"`
+	TransactionCode  string  `json:"transactionCode"`
+	TransactionPrice float64 `json:"transactionPrice"`
+}
+
+type FinnhubEarningsSurprise struct {
+	Actual          *float64 `json:"actual"`
+	Estimate        *float64 `json:"estimate"`
+	Period          string   `json:"period"`
+	Quarter         int      `json:"quarter"`
+	Surprise        *float64 `json:"surprise"`
+	SurprisePercent *float64 `json:"surprisePercent"`
+	Symbol          string   `json:"symbol"`
+	Year            int      `json:"year"`
+}
+
+func (f *FinnhubSource) ingestFundamentals(ctx context.Context) {
+	f.fetchAllFundamentals(ctx)
+
+	ticker := time.NewTicker(f.config.FundamentalsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.fetchAllFundamentals(ctx)
+		}
+	}
+}
+
+func (f *FinnhubSource) fetchAllFundamentals(ctx context.Context) {
+	ctx, poll := startPoll(ctx, f.GetName())
+	defer poll.End()
+	for _, symbol := range f.config.Symbols {
+		if err := f.fetchInsiderTransactions(ctx, symbol); err != nil {
+			log.Printf("Error fetching Finnhub insider transactions for %s: %v", symbol, err)
+		}
+		if err := f.fetchEarningsSurprises(ctx, symbol); err != nil {
+			log.Printf("Error fetching Finnhub earnings surprises for %s: %v", symbol, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (f *FinnhubSource) fetchInsiderTransactions(ctx context.Context, symbol string) error {
+	ticker, err := finnhubTicker(symbol)
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	params.Set("symbol", ticker)
+	params.Set("from", time.Now().Add(-insiderTransactionLookback).Format("2006-01-02"))
+	params.Set("to", time.Now().Format("2006-01-02"))
+
+	var response FinnhubInsiderResponse
+	if err := f.getJSON(ctx, "/stock/insider-transactions", params, &response); err != nil {
+		return err
+	}
+
+	for _, tx := range response.Data {
+		trade := buildInsiderTransaction(symbol, tx)
+		data := trade.UnstructuredData
+		data.Metadata = map[string]interface{}{
+			"symbol":           trade.Symbol,
+			"symbols":          []string{trade.Symbol},
+			"insider":          trade.Insider,
+			"transaction_code": trade.TransactionCode,
+			"transaction_type": insiderCodes[trade.TransactionCode],
+			"change":           trade.Change,
+			"shares_held":      trade.SharesHeld,
+			"price":            trade.Price,
+			"value":            trade.Value,
+			"transaction_date": trade.TransactionDate,
+			"filing_date":      trade.FilingDate,
+		}
+		if err := f.storage.SaveUnstructuredData(ctx, &data); err != nil {
+			log.Printf("Error saving insider transaction %s: %v", data.ID, err)
+		}
+	}
+
+	log.Printf("Processed %d Finnhub insider transactions for %s", len(response.Data), symbol)
+	return nil
+}
+
+func (f *FinnhubSource) fetchEarningsSurprises(ctx context.Context, symbol string) error {
+	ticker, err := finnhubTicker(symbol)
+	if err != nil {
+		return err
+	}
+
+	var quarters []FinnhubEarningsSurprise
+	if err := f.getJSON(ctx, "/stock/earnings", url.Values{"symbol": {ticker}}, &quarters); err != nil {
+		return err
+	}
+
+	stored := 0
+	for _, quarter := range quarters {
+		// Quarters not yet reported have no actual
+		if quarter.Actual == nil {
+			continue
+		}
+		surprise := buildEarningsSurprise(symbol, quarter)
+		data := surprise.UnstructuredData
+		data.Metadata = map[string]interface{}{
+			"symbol":           surprise.Symbol,
+			"symbols":          []string{surprise.Symbol},
+			"period":           surprise.Period,
+			"year":             surprise.Year,
+			"quarter":          surprise.Quarter,
+			"actual":           surprise.Actual,
+			"estimate":         surprise.Estimate,
+			"surprise":         surprise.Surprise,
+			"surprise_percent": surprise.SurprisePercent,
+		}
+		if err := f.storage.SaveUnstructuredData(ctx, &data); err != nil {
+			log.Printf("Error saving earnings surprise %s: %v", data.ID, err)
+			continue
+		}
+		stored++
+	}
+
+	log.Printf("Processed %d Finnhub earnings surprises for %s", stored, symbol)
+	return nil
+}
+
+func buildInsiderTransaction(symbol string, tx FinnhubInsiderTransaction) *models.InsiderTransaction {
+	hash := md5.Sum([]byte(fmt.Sprintf("%s|%s|%s|%s|%d|%d", symbol, tx.Name, tx.TransactionDate, tx.TransactionCode, tx.Change, tx.Share)))
+	transactionDate, err := time.Parse("2006-01-02", tx.TransactionDate)
+	if err != nil {
+		transactionDate = time.Now()
+	}
+	filingDate, err := time.Parse("2006-01-02", tx.FilingDate)
+	if err != nil {
+		filingDate = transactionDate
+	}
+
+	kind := insiderCodes[tx.TransactionCode]
+	if kind == "" {
+		kind = "other"
+	}
+	value := math.Abs(float64(tx.Change)) * tx.TransactionPrice
+
+	tags := []string{"finnhub", "insider_transaction", "insider_" + kind, strings.ToLower(symbol)}
+	// Open-market trades carry the signal; awards and withholding are routine
+	switch tx.TransactionCode {
+	case "P":
+		tags = append(tags, "insider_buy")
+	case "S":
+		tags = append(tags, "insider_sell")
+	}
+
+	verb := strings.ReplaceAll(kind, "_", " ")
+	return &models.InsiderTransaction{
+		UnstructuredData: models.UnstructuredData{
+			ID:          fmt.Sprintf("finnhub-insider-%x", hash[:8]),
+			Source:      "finnhub",
+			Type:        "insider_transaction",
+			Title:       fmt.Sprintf("%s insider %s: %s, %+d shares", symbol, verb, tx.Name, tx.Change),
+			Content:     fmt.Sprintf("%s reported a %s of %d %s shares at $%.2f ($%.0f) on %s, leaving %d shares held.", tx.Name, verb, absInt64(tx.Change), symbol, tx.TransactionPrice, value, tx.TransactionDate, tx.Share),
+			Author:      tx.Name,
+			PublishedAt: filingDate,
+			IngestedAt:  time.Now(),
+			Tags:        tags,
+		},
+		Symbol:          symbol,
+		Insider:         tx.Name,
+		TransactionCode: tx.TransactionCode,
+		Change:          tx.Change,
+		SharesHeld:      tx.Share,
+		Price:           tx.TransactionPrice,
+		Value:           value,
+		TransactionDate: transactionDate,
+		FilingDate:      filingDate,
+	}
+}
+
+func buildEarningsSurprise(symbol string, quarter FinnhubEarningsSurprise) *models.EarningsSurprise {
+	period, err := time.Parse("2006-01-02", quarter.Period)
+	if err != nil {
+		period = time.Now()
+	}
+
+	outcome := "earnings_inline"
+	if quarter.SurprisePercent != nil {
+		switch {
+		case *quarter.SurprisePercent > earningsInlinePercent:
+			outcome = "earnings_beat"
+		case *quarter.SurprisePercent < -earningsInlinePercent:
+			outcome = "earnings_miss"
+		}
+	}
+
+	content := fmt.Sprintf("%s reported EPS of %.2f for Q%d %d", symbol, *quarter.Actual, quarter.Quarter, quarter.Year)
+	if quarter.Estimate != nil {
+		content += fmt.Sprintf(" against an estimate of %.2f", *quarter.Estimate)
+	}
+	if quarter.SurprisePercent != nil {
+		content += fmt.Sprintf(" (%+.1f%%)", *quarter.SurprisePercent)
+	}
+
+	return &models.EarningsSurprise{
+		UnstructuredData: models.UnstructuredData{
+			ID:          fmt.Sprintf("finnhub-earnings-%s-%s", strings.ToLower(symbol), quarter.Period),
+			Source:      "finnhub",
+			Type:        "earnings_surprise",
+			Title:       fmt.Sprintf("%s Q%d %d earnings: %s", symbol, quarter.Quarter, quarter.Year, strings.TrimPrefix(outcome, "earnings_")),
+			Content:     content + ".",
+			Author:      "Finnhub",
+			PublishedAt: period,
+			IngestedAt:  time.Now(),
+			Tags:        []string{"finnhub", "earnings_surprise", outcome, strings.ToLower(symbol)},
+		},
+		Symbol:          symbol,
+		Period:          quarter.Period,
+		Year:            quarter.Year,
+		Quarter:         quarter.Quarter,
+		Actual:          *quarter.Actual,
+		Estimate:        quarter.Estimate,
+		Surprise:        quarter.Surprise,
+		SurprisePercent: quarter.SurprisePercent,
+	}
+}
+
+func finnhubTicker(symbol string) (string, error) {
+	ticker, ok, err := symbols.To(symbol, symbols.Finnhub)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", fmt.Errorf("symbol %s has no Finnhub notation", symbol)
+	}
+	return ticker, nil
+}
+
+func (f *FinnhubSource) getJSON(ctx context.Context, path string, params url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", f.config.RestAPIURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(finnhubTokenHeader, f.config.APIKey)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode %s response: %w", path, err))
+	}
+	return nil
+}
+
+func absInt64(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,237 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// finnhubWriteTimeout bounds every message and ping written to the trade
+// WebSocket.
+const finnhubWriteTimeout = 10 * time.Second
+
+// finnhubHandshakeTimeout bounds opening the trade WebSocket.
+const finnhubHandshakeTimeout = 30 * time.Second
+
+// startWebSocket keeps the trade WebSocket connected until ctx is done.
+// Failed connections are retried after a backoff that doubles up to the
+// configured maximum, with jitter so that restarts do not reconnect in
+// step. A connection that stayed up past the stale timeout starts the
+// backoff over.
+func (f *FinnhubSource) startWebSocket(ctx context.Context) {
+	if f.config.APIKey == "" {
+		log.Println("Finnhub API key not provided, skipping WebSocket connection")
+		return
+	}
+
+	backoff := f.config.WebSocketMinBackoff
+	for {
+		attempt := time.Now()
+		err := f.connectWebSocket(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		f.wsMu.Lock()
+		connectedAt := f.stream.connectedAt
+		if connectedAt.After(attempt) && time.Since(connectedAt) > f.config.WebSocketStaleTimeout {
+			backoff = f.config.WebSocketMinBackoff
+		}
+		delay := backoff/2 + rand.N(backoff/2+1)
+		f.stream.lastError, f.stream.lastErrorAt = err.Error(), time.Now()
+		f.stream.nextAttempt = time.Now().Add(delay)
+		f.wsMu.Unlock()
+		log.Printf("WebSocket connection error: %v; reconnecting in %v", err, delay.Round(time.Millisecond))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, f.config.WebSocketMaxBackoff)
+	}
+}
+
+// connectWebSocket connects, subscribes to the symbols of f.subscriptions
+// and stores the trades that arrive until the connection fails, goes stale
+// or ctx is done. It returns nil only for the last.
+func (f *FinnhubSource) connectWebSocket(ctx context.Context) error {
+	wsURL := fmt.Sprintf("%s?token=%s", f.config.WebSocketURL, f.config.APIKey)
+
+	dialer := websocket.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: finnhubHandshakeTimeout}
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		f.wsMu.Lock()
+		f.stream.dialFailures++
+		f.wsMu.Unlock()
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+	defer conn.Close()
+
+	// Any message or pong proves the connection alive; a read waiting longer
+	// than the stale timeout fails
+	stale := f.config.WebSocketStaleTimeout
+	conn.SetReadDeadline(time.Now().Add(stale))
+	conn.SetPongHandler(func(string) error {
+		f.received()
+		return conn.SetReadDeadline(time.Now().Add(stale))
+	})
+
+	subscribed, err := f.attach(conn)
+	if err != nil {
+		return err
+	}
+	defer f.detach()
+	log.Printf("Connected to Finnhub WebSocket, subscribed to %d symbols", subscribed)
+
+	done := make(chan struct{})
+	defer close(done)
+	go f.heartbeat(ctx, conn, done)
+
+	for {
+		var msg FinnhubWebSocketMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				f.wsMu.Lock()
+				f.stream.staleTimeouts++
+				f.wsMu.Unlock()
+				return fmt.Errorf("WebSocket went stale, nothing received for %v", stale)
+			}
+			return fmt.Errorf("failed to read WebSocket message: %w", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(stale))
+		f.received()
+
+		switch msg.Type {
+		case "trade":
+			f.processTradeData(ctx, msg.Data)
+		case "error":
+			log.Printf("Finnhub WebSocket error: %s", msg.Msg)
+		}
+	}
+}
+
+// attach makes conn the source's connection and subscribes it to every
+// symbol of f.subscriptions, returning how many.
+func (f *FinnhubSource) attach(conn *websocket.Conn) (int, error) {
+	f.wsMu.Lock()
+	defer f.wsMu.Unlock()
+
+	symbols := make([]string, 0, len(f.subscriptions))
+	for symbol := range f.subscriptions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		if err := writeSubscription(conn, "subscribe", symbol); err != nil {
+			return 0, fmt.Errorf("failed to subscribe to symbol %s: %w", symbol, err)
+		}
+	}
+
+	f.conn = conn
+	f.stream.connected, f.stream.connectedAt = true, time.Now()
+	f.stream.connects++
+	f.stream.subscriptions = len(symbols)
+	f.stream.nextAttempt = time.Time{}
+	return len(symbols), nil
+}
+
+// detach forgets the connection that just ended.
+func (f *FinnhubSource) detach() {
+	f.wsMu.Lock()
+	defer f.wsMu.Unlock()
+	f.conn = nil
+	f.stream.connected = false
+}
+
+func (f *FinnhubSource) received() {
+	f.wsMu.Lock()
+	f.stream.lastMessage = time.Now()
+	f.wsMu.Unlock()
+}
+
+// heartbeat pings conn every ping interval until done is closed, and closes
+// conn when ctx is done so the read waiting on it returns.
+func (f *FinnhubSource) heartbeat(ctx context.Context, conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(f.config.WebSocketPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			conn.Close()
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(finnhubWriteTimeout)); err != nil {
+				// The read deadline notices the connection is gone
+				log.Printf("Failed to ping Finnhub WebSocket: %v", err)
+				return
+			}
+		}
+	}
+}
+
+// Subscribe adds symbol to the trades streamed, on the current connection if
+// there is one and on every connection after.
+func (f *FinnhubSource) Subscribe(symbol string) error {
+	return f.setSubscription(symbol, true)
+}
+
+// Unsubscribe stops streaming the trades of symbol.
+func (f *FinnhubSource) Unsubscribe(symbol string) error {
+	return f.setSubscription(symbol, false)
+}
+
+func (f *FinnhubSource) setSubscription(symbol string, subscribed bool) error {
+	f.wsMu.Lock()
+	defer f.wsMu.Unlock()
+	if f.subscriptions[symbol] == subscribed {
+		return nil
+	}
+	action := "unsubscribe"
+	if subscribed {
+		f.subscriptions[symbol] = true
+		action = "subscribe"
+	} else {
+		delete(f.subscriptions, symbol)
+	}
+	if f.conn == nil {
+		return nil
+	}
+	f.stream.subscriptions = len(f.subscriptions)
+	if err := writeSubscription(f.conn, action, symbol); err != nil {
+		// Put right from f.subscriptions once the connection is made again
+		return fmt.Errorf("failed to send %s for symbol %s: %w", action, symbol, err)
+	}
+	return nil
+}
+
+func writeSubscription(conn *websocket.Conn, action, symbol string) error {
+	conn.SetWriteDeadline(time.Now().Add(finnhubWriteTimeout))
+	return conn.WriteJSON(map[string]interface{}{
+		"type":   action,
+		"symbol": symbol,
+	})
+}
+
+func (f *FinnhubSource) streamStats() StreamStats {
+	f.wsMu.Lock()
+	defer f.wsMu.Unlock()
+	return f.stream.stats()
+}
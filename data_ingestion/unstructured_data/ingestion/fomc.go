@@ -0,0 +1,277 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const (
+	FOMCStatement   = "statement"
+	FOMCMinutes     = "minutes"
+	FOMCProjections = "projections"
+
+	// macroEventPriority is the job priority given to FOMC events so they are
+	// enriched ahead of regular news.
+	macroEventPriority = 10
+)
+
+var (
+	paragraphRegex  = regexp.MustCompile(`(?is)<p[^>]*>(.*?)</p>`)
+	targetRateRegex = regexp.MustCompile(`(?i)target range for the federal funds rate (?:at|to) ([\d\-/ ]+?) to ([\d\-/ ]+?) percent`)
+	rateActionRegex = regexp.MustCompile(`(?i)decided to (raise|lower|maintain|reduce|increase)`)
+	dissentRegex    = regexp.MustCompile(`(?i)voting against (?:the|this) action (?:was|were):?\s*`)
+	nameListRegex   = regexp.MustCompile(`,\s*(?:and\s+)?|\s+and\s+`)
+	// dissentEnd ends the list of dissenters: the reason given for a dissent
+	// ("..., who preferred ...") or a period closing the sentence, which
+	// follows a word rather than an initial such as the W. of "Michelle W. Bowman"
+	dissentEnd = regexp.MustCompile(`,?\s+who\s|;|\p{L}{2}(\.)(?:\s|$)`)
+)
+
+// FOMCDocument is the structured view of a parsed FOMC release.
+type FOMCDocument struct {
+	DocumentType      string
+	Paragraphs        []string
+	RateAction        string
+	TargetRangeLower  string
+	TargetRangeUpper  string
+	Dissents          []string
+	AddedParagraphs   []string
+	RemovedParagraphs []string
+}
+
+func classifyFOMCDocument(title string) string {
+	t := strings.ToLower(title)
+	switch {
+	case strings.Contains(t, "minutes"):
+		return FOMCMinutes
+	case strings.Contains(t, "projections"):
+		return FOMCProjections
+	case strings.Contains(t, "fomc statement") || strings.Contains(t, "issues fomc"):
+		return FOMCStatement
+	}
+	return ""
+}
+
+func (f *FedNewsSource) fetchFOMCDocuments(ctx context.Context) error {
+	ctx, poll := startPoll(ctx, f.GetName())
+	defer poll.End()
+	req, err := http.NewRequestWithContext(ctx, "GET", f.config.MonetaryFeedURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var feed RSSFeed
+	if err := xml.NewDecoder(resp.Body).Decode(&feed); err != nil {
+		return parseError(ctx, err)
+	}
+
+	// Feeds list newest first; walk oldest first so statement diffs compare
+	// each release against the one before it.
+	items := feed.Channel.Items
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		docType := classifyFOMCDocument(item.Title)
+		if docType == "" || f.seenFOMC[item.Link] {
+			continue
+		}
+
+		if err := f.processFOMCDocument(ctx, item, docType); err != nil {
+			log.Printf("Error processing FOMC %s %s: %v", docType, item.Link, err)
+			continue
+		}
+		f.seenFOMC[item.Link] = true
+	}
+
+	return nil
+}
+
+func (f *FedNewsSource) processFOMCDocument(ctx context.Context, item RSSItem, docType string) error {
+	paragraphs, err := f.fetchParagraphs(ctx, item.Link)
+	if err != nil {
+		return err
+	}
+
+	hash := md5.Sum([]byte(item.Link))
+	id := fmt.Sprintf("fomc-%x", hash[:8])
+	pubDate, _ := time.Parse(time.RFC1123, item.PubDate)
+
+	doc := parseFOMCDocument(docType, paragraphs)
+	if docType == FOMCStatement {
+		previous, err := f.previousStatement(ctx, id, pubDate)
+		if err != nil {
+			return err
+		}
+		if previous != nil {
+			doc.AddedParagraphs, doc.RemovedParagraphs = diffParagraphs(previous, doc.Paragraphs)
+		}
+	}
+
+	metadata := map[string]interface{}{
+		"guid":               item.GUID,
+		"fomc_document_type": docType,
+		"priority":           macroEventPriority,
+		"paragraph_count":    len(doc.Paragraphs),
+	}
+	if doc.RateAction != "" {
+		metadata["rate_action"] = doc.RateAction
+	}
+	if doc.TargetRangeLower != "" {
+		metadata["target_range_lower"] = doc.TargetRangeLower
+		metadata["target_range_upper"] = doc.TargetRangeUpper
+	}
+	if len(doc.Dissents) > 0 {
+		metadata["dissents"] = doc.Dissents
+	}
+	if doc.AddedParagraphs != nil || doc.RemovedParagraphs != nil {
+		metadata["added_paragraphs"] = doc.AddedParagraphs
+		metadata["removed_paragraphs"] = doc.RemovedParagraphs
+	}
+
+	tags := []string{"federal_reserve", "monetary_policy", "central_bank", "fomc", "macro_event", "high_priority", "fomc_" + docType}
+	if doc.RateAction != "" {
+		tags = append(tags, "rate_decision")
+	}
+	if len(doc.Dissents) > 0 {
+		tags = append(tags, "fomc_dissent")
+	}
+
+	data := &models.UnstructuredData{
+		ID:          id,
+		Source:      "federal_reserve",
+		Type:        "macro_event",
+		Title:       item.Title,
+		Content:     strings.Join(doc.Paragraphs, "\n\n"),
+		URL:         item.Link,
+		Author:      "Federal Open Market Committee",
+		PublishedAt: pubDate,
+		IngestedAt:  time.Now(),
+		Metadata:    metadata,
+		Tags:        tags,
+	}
+
+	return f.storage.SaveUnstructuredData(ctx, data)
+}
+
+// previousStatement returns the paragraphs of the latest stored statement
+// published by pubDate other than id, or nil when there is none. Statements
+// are stored as they are processed, so the diff has its baseline after a
+// restart too.
+func (f *FedNewsSource) previousStatement(ctx context.Context, id string, pubDate time.Time) ([]string, error) {
+	statements, err := f.storage.ListUnstructuredData(ctx, storage.DataFilters{
+		Source: "federal_reserve",
+		Tags:   []string{"fomc_" + FOMCStatement},
+		DateTo: &pubDate,
+		Limit:  2,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load previous statement: %w", err)
+	}
+	for _, statement := range statements {
+		if statement.ID != id {
+			return strings.Split(statement.Content, "\n\n"), nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *FedNewsSource) fetchParagraphs(ctx context.Context, pageURL string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("page returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var paragraphs []string
+	for _, match := range paragraphRegex.FindAllStringSubmatch(string(body), -1) {
+		text := strings.Join(strings.Fields(extraction.CleanText(match[1])), " ")
+		if len(text) > 40 {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	return paragraphs, nil
+}
+
+func parseFOMCDocument(docType string, paragraphs []string) *FOMCDocument {
+	doc := &FOMCDocument{DocumentType: docType, Paragraphs: paragraphs}
+	text := strings.Join(paragraphs, " ")
+
+	if m := rateActionRegex.FindStringSubmatch(text); m != nil {
+		doc.RateAction = strings.ToLower(m[1])
+	}
+	if m := targetRateRegex.FindStringSubmatch(text); m != nil {
+		doc.TargetRangeLower = strings.TrimSpace(m[1])
+		doc.TargetRangeUpper = strings.TrimSpace(m[2])
+	}
+	if loc := dissentRegex.FindStringIndex(text); loc != nil {
+		names := text[loc[1]:]
+		if end := dissentEnd.FindStringSubmatchIndex(names); end != nil {
+			if end[2] >= 0 {
+				names = names[:end[2]]
+			} else {
+				names = names[:end[0]]
+			}
+		}
+		for _, name := range nameListRegex.Split(names, -1) {
+			if name = strings.TrimSpace(name); name != "" {
+				doc.Dissents = append(doc.Dissents, name)
+			}
+		}
+	}
+	return doc
+}
+
+// diffParagraphs returns the paragraphs that are new in current and those
+// that were dropped from previous.
+func diffParagraphs(previous, current []string) (added, removed []string) {
+	prevSet := make(map[string]bool, len(previous))
+	for _, p := range previous {
+		prevSet[p] = true
+	}
+	currSet := make(map[string]bool, len(current))
+	for _, p := range current {
+		currSet[p] = true
+		if !prevSet[p] {
+			added = append(added, p)
+		}
+	}
+	for _, p := range previous {
+		if !currSet[p] {
+			removed = append(removed, p)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,321 @@
+package ingestion
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/backfill"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// GDELT 2.0 event export column positions (tab-delimited, no header).
+const (
+	eventGlobalID    = 0
+	eventActor1Name  = 6
+	eventActor2Name  = 16
+	eventCode        = 26
+	eventRootCode    = 28
+	eventQuadClass   = 29
+	eventGoldstein   = 30
+	eventNumMentions = 31
+	eventAvgTone     = 34
+	eventDateAdded   = 59
+	eventSourceURL   = 60
+	eventColumns     = 61
+)
+
+// cameoRootCodes names the top-level CAMEO event categories.
+var cameoRootCodes = map[string]string{
+	"01": "makes public statement",
+	"02": "appeals",
+	"03": "expresses intent to cooperate",
+	"04": "consults",
+	"05": "engages in diplomatic cooperation",
+	"06": "engages in material cooperation",
+	"07": "provides aid",
+	"08": "yields",
+	"09": "investigates",
+	"10": "demands",
+	"11": "disapproves",
+	"12": "rejects",
+	"13": "threatens",
+	"14": "protests",
+	"15": "exhibits force posture",
+	"16": "reduces relations",
+	"17": "coerces",
+	"18": "assaults",
+	"19": "fights",
+	"20": "uses unconventional mass violence",
+}
+
+type GDELTSource struct {
+	storage storage.Storage
+	config  config.GDELTConfig
+	client  *http.Client
+	enabled bool
+	issuers []backfill.Issuer
+
+	processed map[string]bool // update files already ingested
+}
+
+func NewGDELTSource(store storage.Storage, cfg config.GDELTConfig) *GDELTSource {
+	return &GDELTSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 2 * time.Minute,
+		},
+		enabled:   cfg.Enabled,
+		processed: make(map[string]bool),
+	}
+}
+
+func (g *GDELTSource) Start(ctx context.Context) error {
+	if !g.enabled {
+		log.Println("GDELT source is disabled")
+		return nil
+	}
+
+	issuers, err := g.loadIssuers()
+	if err != nil {
+		return err
+	}
+	if len(issuers) == 0 {
+		return fmt.Errorf("no GDELT issuers configured")
+	}
+	g.issuers = backfill.CompileIssuers(issuers)
+
+	log.Printf("Starting GDELT data source (%d issuers)...", len(g.issuers))
+	go supervise(ctx, g.GetName(), g.ingestData)
+	return nil
+}
+
+func (g *GDELTSource) Stop(ctx context.Context) error {
+	log.Println("Stopping GDELT source...")
+	return nil
+}
+
+func (g *GDELTSource) GetName() string {
+	return "gdelt"
+}
+
+func (g *GDELTSource) IsEnabled() bool {
+	return g.enabled
+}
+
+func (g *GDELTSource) loadIssuers() ([]backfill.Issuer, error) {
+	if g.config.IssuersFile != "" {
+		return backfill.LoadIssuers(g.config.IssuersFile)
+	}
+	issuers := make([]backfill.Issuer, 0, len(g.config.Issuers))
+	for _, issuer := range g.config.Issuers {
+		issuers = append(issuers, backfill.Issuer{Symbol: issuer.Symbol, Names: issuer.Names})
+	}
+	return issuers, nil
+}
+
+func (g *GDELTSource) ingestData(ctx context.Context) {
+	if err := g.fetchUpdate(ctx); err != nil {
+		log.Printf("Error in initial GDELT fetch: %v", err)
+	}
+
+	ticker := newPollTicker(g.config.UpdateInterval, g.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := g.fetchUpdate(ctx); err != nil {
+				log.Printf("Error fetching GDELT update: %v", err)
+			}
+		}
+	}
+}
+
+// fetchUpdate reads the list of the latest 15-minute files and ingests the
+// GKG and, if enabled, event files not seen before.
+func (g *GDELTSource) fetchUpdate(ctx context.Context) error {
+	ctx, poll := startPoll(ctx, g.GetName())
+	defer poll.End()
+	body, err := g.get(ctx, g.config.LastUpdateURL)
+	if err != nil {
+		return fmt.Errorf("failed to fetch update list: %w", err)
+	}
+
+	// Only the current update is remembered; its files replace the previous ones
+	current := make(map[string]bool)
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		// Each line is "<size> <md5> <url>"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		url := fields[2]
+		if g.processed[url] {
+			current[url] = true
+			continue
+		}
+
+		var records int
+		switch {
+		case strings.HasSuffix(url, ".gkg.csv.zip"):
+			records, err = g.processGKG(ctx, url)
+		case strings.HasSuffix(url, ".export.CSV.zip") && g.config.IncludeEvents:
+			records, err = g.processEvents(ctx, url)
+		default:
+			continue
+		}
+		if err != nil {
+			log.Printf("Error processing GDELT file %s: %v", url, err)
+			continue
+		}
+		current[url] = true
+		log.Printf("Processed GDELT file %s: %d records", url[strings.LastIndex(url, "/")+1:], records)
+	}
+	g.processed = current
+	return scanner.Err()
+}
+
+func (g *GDELTSource) processGKG(ctx context.Context, url string) (int, error) {
+	body, err := g.get(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	records := 0
+	err = backfill.ReadGKG(body, g.issuers, func(fields []string, symbols []string) {
+		data, err := backfill.GKGRecord(fields, symbols)
+		if err != nil {
+			return
+		}
+		if tone, ok := data.Metadata["gdelt_tone"].(map[string]float64); ok {
+			data.Sentiment = gdeltToneSentiment(tone["tone"], tone["positive"], tone["negative"], tone["polarity"])
+		}
+		if err := g.storage.SaveUnstructuredData(ctx, data); err != nil {
+			log.Printf("Failed to save GDELT record %s: %v", data.ID, err)
+			return
+		}
+		records++
+	})
+	return records, err
+}
+
+func (g *GDELTSource) processEvents(ctx context.Context, url string) (int, error) {
+	body, err := g.get(ctx, url)
+	if err != nil {
+		return 0, err
+	}
+
+	records := 0
+	err = backfill.ReadArchive(body, eventColumns, func(fields []string) {
+		symbols := backfill.MatchIssuers(g.issuers, fields[eventActor1Name]+";"+fields[eventActor2Name])
+		if len(symbols) == 0 {
+			return
+		}
+		if err := g.storage.SaveUnstructuredData(ctx, buildGDELTEvent(fields, symbols)); err != nil {
+			log.Printf("Failed to save GDELT event %s: %v", fields[eventGlobalID], err)
+			return
+		}
+		records++
+	})
+	return records, err
+}
+
+func buildGDELTEvent(fields []string, symbols []string) *models.UnstructuredData {
+	publishedAt, err := time.Parse("20060102150405", fields[eventDateAdded])
+	if err != nil {
+		publishedAt = time.Now()
+	}
+	avgTone, _ := strconv.ParseFloat(fields[eventAvgTone], 64)
+	goldstein, _ := strconv.ParseFloat(fields[eventGoldstein], 64)
+	mentions, _ := strconv.Atoi(fields[eventNumMentions])
+
+	action := cameoRootCodes[fields[eventRootCode]]
+	if action == "" {
+		action = "event " + fields[eventCode]
+	}
+	title := strings.TrimSpace(fields[eventActor1Name] + " " + action)
+	if fields[eventActor2Name] != "" {
+		title += " (" + fields[eventActor2Name] + ")"
+	}
+
+	tags := []string{"gdelt", "gdelt_event", "cameo_" + fields[eventRootCode]}
+	var entities []models.Entity
+	for _, symbol := range symbols {
+		tags = append(tags, strings.ToLower(symbol))
+		entities = append(entities, models.Entity{Name: symbol, Type: "ORG", Confidence: 1})
+	}
+
+	return &models.UnstructuredData{
+		ID:          "gdelt-event-" + fields[eventGlobalID],
+		Source:      "gdelt",
+		Type:        "event",
+		Title:       title,
+		URL:         fields[eventSourceURL],
+		PublishedAt: publishedAt,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"symbols":         symbols,
+			"global_event_id": fields[eventGlobalID],
+			"actor1":          fields[eventActor1Name],
+			"actor2":          fields[eventActor2Name],
+			"cameo_code":      fields[eventCode],
+			"quad_class":      fields[eventQuadClass],
+			"goldstein_scale": goldstein,
+			"num_mentions":    mentions,
+			"gdelt_avg_tone":  avgTone,
+		},
+		Tags:      tags,
+		Entities:  entities,
+		Sentiment: gdeltToneSentiment(avgTone, 0, 0, 0),
+	}
+}
+
+// gdeltToneSentiment maps GDELT tone, which runs from -100 to 100 but rarely
+// leaves -10 to 10, and the percentages of positive and negative words onto a
+// SentimentScore.
+func gdeltToneSentiment(tone, positive, negative, polarity float64) *models.SentimentScore {
+	score := &models.SentimentScore{
+		Overall:   math.Max(-1, math.Min(1, tone/10)),
+		Positive:  positive / 100,
+		Negative:  negative / 100,
+		Magnitude: polarity,
+	}
+	if positive != 0 || negative != 0 {
+		score.Neutral = math.Max(0, 1-score.Positive-score.Negative)
+	}
+	return score
+}
+
+func (g *GDELTSource) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", url, resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,369 @@
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const (
+	// trendsMaxQueries is the most queries Google compares in one request
+	trendsMaxQueries = 5
+	// trendsSpikeZ is the z-score against the earlier points that makes the
+	// latest point a spike
+	trendsSpikeZ = 3.0
+	// trendsSpikeFloor ignores spikes in series too quiet to matter
+	trendsSpikeFloor = 10
+	// trendsRequestGap spaces requests to stay clear of Google's throttling
+	trendsRequestGap = 2 * time.Second
+)
+
+// trendsPrefix is the anti-JSON-hijacking prefix on Google Trends responses.
+var trendsPrefix = []byte(")]}'")
+
+type trendsExploreResponse struct {
+	Widgets []struct {
+		ID      string          `json:"id"`
+		Token   string          `json:"token"`
+		Request json.RawMessage `json:"request"`
+	} `json:"widgets"`
+}
+
+type trendsMultilineResponse struct {
+	Default struct {
+		TimelineData []struct {
+			Time      string `json:"time"`
+			Value     []int  `json:"value"`
+			HasData   []bool `json:"hasData"`
+			IsPartial bool   `json:"isPartial"`
+		} `json:"timelineData"`
+	} `json:"default"`
+}
+
+// TrendPoint is one search interest value, 0-100 relative to the highest
+// point among the queries compared with it.
+type TrendPoint struct {
+	Time  time.Time `json:"time"`
+	Value int       `json:"value"`
+}
+
+// TrendSeries is the interest over time in one query.
+type TrendSeries struct {
+	Query    string       `json:"query"`
+	Term     string       `json:"term"` // "" for the company name itself
+	Points   []TrendPoint `json:"points"`
+	Latest   int          `json:"latest"`
+	Baseline float64      `json:"baseline"`
+	ZScore   float64      `json:"zscore"`
+	Spike    bool         `json:"spike"`
+}
+
+type GoogleTrendsSource struct {
+	storage storage.Storage
+	config  config.GoogleTrendsConfig
+	client  *http.Client
+	enabled bool
+}
+
+func NewGoogleTrendsSource(store storage.Storage, cfg config.GoogleTrendsConfig) *GoogleTrendsSource {
+	// Google Trends rejects requests without the cookies set by its home page
+	jar, _ := cookiejar.New(nil)
+	return &GoogleTrendsSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Jar:     jar,
+		},
+		enabled: cfg.Enabled,
+	}
+}
+
+func (g *GoogleTrendsSource) Start(ctx context.Context) error {
+	if !g.enabled {
+		log.Println("Google Trends source is disabled")
+		return nil
+	}
+	if len(g.config.DistressTerms) > trendsMaxQueries-1 {
+		return fmt.Errorf("google trends compares at most %d distress terms, got %d", trendsMaxQueries-1, len(g.config.DistressTerms))
+	}
+
+	log.Printf("Starting Google Trends data source (%d issuers)...", len(g.config.Issuers))
+	go supervise(ctx, g.GetName(), g.ingestData)
+	return nil
+}
+
+func (g *GoogleTrendsSource) Stop(ctx context.Context) error {
+	log.Println("Stopping Google Trends source...")
+	return nil
+}
+
+func (g *GoogleTrendsSource) GetName() string {
+	return "google_trends"
+}
+
+func (g *GoogleTrendsSource) IsEnabled() bool {
+	return g.enabled
+}
+
+func (g *GoogleTrendsSource) ingestData(ctx context.Context) {
+	g.fetchAll(ctx)
+
+	ticker := newPollTicker(g.config.UpdateInterval, g.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.fetchAll(ctx)
+		}
+	}
+}
+
+func (g *GoogleTrendsSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, g.GetName())
+	defer poll.End()
+	if _, err := g.get(ctx, g.config.BaseURL+"/?geo="+url.QueryEscape(g.config.Geo)); err != nil {
+		log.Printf("Error fetching Google Trends cookies: %v", err)
+	}
+
+	for _, issuer := range g.config.Issuers {
+		if len(issuer.Names) == 0 {
+			continue
+		}
+		if err := g.fetchIssuer(ctx, issuer); err != nil {
+			log.Printf("Error fetching Google Trends for %s: %v", issuer.Symbol, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(trendsRequestGap):
+		}
+	}
+}
+
+func (g *GoogleTrendsSource) fetchIssuer(ctx context.Context, issuer config.Issuer) error {
+	name := issuer.Names[0]
+	queries := []string{name}
+	terms := []string{""}
+	for _, term := range g.config.DistressTerms {
+		queries = append(queries, name+" "+term)
+		terms = append(terms, term)
+	}
+
+	series, err := g.fetchSeries(ctx, queries)
+	if err != nil {
+		return err
+	}
+	for i := range series {
+		series[i].Term = terms[i]
+	}
+
+	return g.storage.SaveUnstructuredData(ctx, buildTrendsRecord(issuer, g.config, series))
+}
+
+// fetchSeries asks the explore endpoint for the time series widget of the
+// comparison and then reads the widget's data.
+func (g *GoogleTrendsSource) fetchSeries(ctx context.Context, queries []string) ([]TrendSeries, error) {
+	type comparisonItem struct {
+		Keyword string `json:"keyword"`
+		Geo     string `json:"geo"`
+		Time    string `json:"time"`
+	}
+	items := make([]comparisonItem, len(queries))
+	for i, query := range queries {
+		items[i] = comparisonItem{Keyword: query, Geo: g.config.Geo, Time: g.config.Timeframe}
+	}
+	exploreReq, err := json.Marshal(map[string]interface{}{
+		"comparisonItem": items,
+		"category":       0,
+		"property":       "",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{"hl": {"en-US"}, "tz": {"0"}, "req": {string(exploreReq)}}
+	body, err := g.get(ctx, g.config.BaseURL+"/api/explore?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	var explore trendsExploreResponse
+	if err := json.Unmarshal(body, &explore); err != nil {
+		return nil, parseError(ctx, fmt.Errorf("failed to decode explore response: %w", err))
+	}
+
+	for _, widget := range explore.Widgets {
+		if widget.ID != "TIMESERIES" {
+			continue
+		}
+		params := url.Values{"hl": {"en-US"}, "tz": {"0"}, "req": {string(widget.Request)}, "token": {widget.Token}}
+		body, err := g.get(ctx, g.config.BaseURL+"/api/widgetdata/multiline?"+params.Encode())
+		if err != nil {
+			return nil, err
+		}
+		var multiline trendsMultilineResponse
+		if err := json.Unmarshal(body, &multiline); err != nil {
+			return nil, parseError(ctx, fmt.Errorf("failed to decode time series: %w", err))
+		}
+
+		series := make([]TrendSeries, len(queries))
+		for i, query := range queries {
+			series[i].Query = query
+		}
+		for _, point := range multiline.Default.TimelineData {
+			// The last point covers an unfinished period and is revised later
+			if point.IsPartial {
+				continue
+			}
+			seconds, err := strconv.ParseInt(point.Time, 10, 64)
+			if err != nil {
+				continue
+			}
+			for i := range series {
+				if i < len(point.Value) && (i >= len(point.HasData) || point.HasData[i]) {
+					series[i].Points = append(series[i].Points, TrendPoint{Time: time.Unix(seconds, 0).UTC(), Value: point.Value[i]})
+				}
+			}
+		}
+		for i := range series {
+			scoreSpike(&series[i])
+		}
+		return series, nil
+	}
+	return nil, fmt.Errorf("explore response has no time series widget")
+}
+
+// scoreSpike compares the latest point with the mean and spread of the
+// points before it.
+func scoreSpike(series *TrendSeries) {
+	n := len(series.Points)
+	if n == 0 {
+		return
+	}
+	series.Latest = series.Points[n-1].Value
+	if n < 3 {
+		return
+	}
+
+	var sum float64
+	for _, p := range series.Points[:n-1] {
+		sum += float64(p.Value)
+	}
+	mean := sum / float64(n-1)
+	var variance float64
+	for _, p := range series.Points[:n-1] {
+		variance += math.Pow(float64(p.Value)-mean, 2)
+	}
+	std := math.Sqrt(variance / float64(n-1))
+
+	series.Baseline = math.Round(mean*100) / 100
+	if std > 0 {
+		series.ZScore = math.Round((float64(series.Latest)-mean)/std*100) / 100
+	} else if float64(series.Latest) > mean {
+		series.ZScore = trendsSpikeZ
+	}
+	series.Spike = series.ZScore >= trendsSpikeZ && series.Latest >= trendsSpikeFloor
+}
+
+func buildTrendsRecord(issuer config.Issuer, cfg config.GoogleTrendsConfig, series []TrendSeries) *models.UnstructuredData {
+	now := time.Now().UTC()
+	symbol := strings.ToLower(issuer.Symbol)
+
+	tags := []string{"google_trends", "search_interest", symbol}
+	var spikes []string
+	features := make(map[string]float64)
+	for _, s := range series {
+		key := "interest"
+		if s.Term != "" {
+			key = "interest_" + strings.ReplaceAll(s.Term, " ", "_")
+		}
+		features[key] = float64(s.Latest)
+		features[key+"_zscore"] = s.ZScore
+
+		if !s.Spike {
+			continue
+		}
+		spikes = append(spikes, s.Query)
+		if s.Term == "" {
+			tags = append(tags, "attention_spike")
+		} else {
+			tags = append(tags, "distress_search_spike", strings.ReplaceAll(s.Term, " ", "_")+"_search_spike")
+		}
+	}
+
+	content := fmt.Sprintf("Search interest in %s (%s, %s).", issuer.Names[0], cfg.Geo, cfg.Timeframe)
+	if len(spikes) > 0 {
+		content += " Spiking: " + strings.Join(spikes, ", ") + "."
+	}
+
+	return &models.UnstructuredData{
+		ID:          fmt.Sprintf("trends-%s-%s", symbol, now.Format("2006-01-02")),
+		Source:      "google_trends",
+		Type:        "search_interest",
+		Title:       fmt.Sprintf("%s search interest", issuer.Names[0]),
+		Content:     content,
+		URL:         cfg.BaseURL + "/explore?" + url.Values{"q": {issuer.Names[0]}, "geo": {cfg.Geo}}.Encode(),
+		Author:      "Google Trends",
+		PublishedAt: now,
+		IngestedAt:  now,
+		Metadata: map[string]interface{}{
+			"symbol":    issuer.Symbol,
+			"symbols":   []string{issuer.Symbol},
+			"geo":       cfg.Geo,
+			"timeframe": cfg.Timeframe,
+			"series":    series,
+			"features":  features,
+			"spikes":    spikes,
+		},
+		Tags: tags,
+	}
+}
+
+func (g *GoogleTrendsSource) get(ctx context.Context, requestURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", requestURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("google trends returned status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// Strip the ")]}'" line that precedes the JSON
+	if bytes.HasPrefix(body, trendsPrefix) {
+		if i := bytes.IndexByte(body, '\n'); i >= 0 {
+			body = body[i+1:]
+		} else {
+			body = body[len(trendsPrefix):]
+		}
+	}
+	return body, nil
+}
@@ -0,0 +1,288 @@
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// The health of a source, as its polls have gone.
+const (
+	healthHealthy   = "healthy"
+	healthFailing   = "failing"   // its last polls failed, too few to suspend it
+	healthSuspended = "suspended" // stopped until the next probe
+	healthProbing   = "probing"   // restarted, waiting for its first poll
+)
+
+// healthMonitor judges every source by the outcome of its polls, suspends
+// the ones that keep failing, probes them again with growing delays and
+// resumes them once a probe succeeds. Requests and stored documents are
+// counted by the activity transport and storage, so without the manager's
+// Activity only parse errors are seen.
+type healthMonitor struct {
+	config  config.SourceHealthConfig
+	manager *Manager
+	client  *http.Client
+
+	mu      sync.Mutex
+	sources map[string]*sourceHealth
+}
+
+type sourceHealth struct {
+	state               string
+	consecutiveFailures int
+	consecutiveEmpty    int
+	recent              []pollResult // the last Window polls
+	parseAlerted        bool
+	probeDelay          time.Duration
+	nextProbe           time.Time
+	probe               *time.Timer
+}
+
+// healthAlert is what a webhook is posted about a source.
+type healthAlert struct {
+	Source  string    `json:"source"`
+	Event   string    `json:"event"`
+	Message string    `json:"message"`
+	At      time.Time `json:"at"`
+}
+
+func newHealthMonitor(m *Manager, cfg config.SourceHealthConfig) *healthMonitor {
+	return &healthMonitor{
+		config:  cfg,
+		manager: m,
+		client:  &http.Client{Timeout: cfg.WebhookTimeout},
+		sources: make(map[string]*sourceHealth),
+	}
+}
+
+type healthKey struct{}
+
+// withHealth has the polls run under ctx report to h.
+func withHealth(ctx context.Context, h *healthMonitor) context.Context {
+	return context.WithValue(ctx, healthKey{}, h)
+}
+
+func healthFrom(ctx context.Context) *healthMonitor {
+	h, _ := ctx.Value(healthKey{}).(*healthMonitor)
+	return h
+}
+
+// get returns the source's record; the caller holds h.mu.
+func (h *healthMonitor) get(source string) *sourceHealth {
+	s := h.sources[source]
+	if s == nil {
+		s = &sourceHealth{state: healthHealthy}
+		h.sources[source] = s
+	}
+	return s
+}
+
+// record judges a finished poll of the source.
+func (h *healthMonitor) record(source string, result pollResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := h.get(source)
+	// A poll finishing while its source is being suspended
+	if s.state == healthSuspended {
+		return
+	}
+	s.recent = append(s.recent, result)
+	if len(s.recent) > h.config.Window {
+		s.recent = append(s.recent[:0], s.recent[len(s.recent)-h.config.Window:]...)
+	}
+
+	switch {
+	case result.failed():
+		s.consecutiveFailures++
+		s.consecutiveEmpty = 0
+		switch {
+		case s.state == healthProbing:
+			s.probeDelay = min(2*s.probeDelay, h.config.MaxProbeInterval)
+			h.suspend(source, s)
+			h.alert(source, "probe_failed", fmt.Sprintf("probe failed, next in %v", s.probeDelay))
+		case s.consecutiveFailures >= h.config.FailureThreshold:
+			s.probeDelay = h.config.ProbeInterval
+			h.suspend(source, s)
+			h.alert(source, "suspended", fmt.Sprintf("suspended after %d failed polls, probing in %v", s.consecutiveFailures, s.probeDelay))
+		default:
+			s.state = healthFailing
+		}
+	default:
+		if s.state == healthProbing {
+			h.alert(source, "recovered", fmt.Sprintf("resumed after %d failed polls", s.consecutiveFailures))
+		}
+		s.state, s.consecutiveFailures, s.probeDelay = healthHealthy, 0, 0
+		if result.items > 0 {
+			s.consecutiveEmpty = 0
+			break
+		}
+		s.consecutiveEmpty++
+		if s.consecutiveEmpty == h.config.EmptyThreshold {
+			h.alert(source, "empty", fmt.Sprintf("stored nothing in %d polls in a row", s.consecutiveEmpty))
+		}
+	}
+
+	if h.config.ParseErrorRate == 0 {
+		return
+	}
+	rate := s.parseErrorRate()
+	switch {
+	case rate < h.config.ParseErrorRate:
+		s.parseAlerted = false
+	case !s.parseAlerted && len(s.recent) >= h.config.Window:
+		s.parseAlerted = true
+		h.alert(source, "parse_errors", fmt.Sprintf("%.0f%% of responses could not be parsed over the last %d polls", rate*100, len(s.recent)))
+	}
+}
+
+// parseErrorRate is the share of responses of the recent polls that could
+// not be parsed.
+func (s *sourceHealth) parseErrorRate() float64 {
+	var responses, parseErrors int
+	for _, result := range s.recent {
+		responses += max(result.requests, result.parseErrors)
+		parseErrors += result.parseErrors
+	}
+	if responses == 0 {
+		return 0
+	}
+	return float64(parseErrors) / float64(responses)
+}
+
+// suspend marks the source suspended, has the manager stop it and schedules
+// its probe after s.probeDelay; the caller holds h.mu. Stopping waits for
+// the source, whose poll may be the one calling, so it happens apart.
+func (h *healthMonitor) suspend(source string, s *sourceHealth) {
+	s.state = healthSuspended
+	s.nextProbe = time.Now().Add(s.probeDelay)
+	s.probe = time.AfterFunc(s.probeDelay, func() { h.manager.probeSource(source) })
+	go h.manager.suspendSource(source)
+}
+
+// suspended reports whether the source is suspended.
+func (h *healthMonitor) suspended(source string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.sources[source]
+	return ok && s.state == healthSuspended
+}
+
+// startProbe moves a suspended source on to probing and reports whether it
+// was suspended.
+func (h *healthMonitor) startProbe(source string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.sources[source]
+	if !ok || s.state != healthSuspended {
+		return false
+	}
+	s.state, s.probe = healthProbing, nil
+	return true
+}
+
+// reset forgets the source's health, as when an operator starts or stops it
+// or its settings change. h may be nil.
+func (h *healthMonitor) reset(source string) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.sources[source]; ok && s.probe != nil {
+		s.probe.Stop()
+	}
+	delete(h.sources, source)
+}
+
+// stop cancels every probe still scheduled.
+func (h *healthMonitor) stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, s := range h.sources {
+		if s.probe != nil {
+			s.probe.Stop()
+		}
+	}
+}
+
+// describe fills in the health of status.Name, that of a source yet to
+// poll or stopped by an operator only if it runs.
+func (h *healthMonitor) describe(status *SourceStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.sources[status.Name]
+	if !ok {
+		if status.Running {
+			status.Health = healthHealthy
+		}
+		return
+	}
+	status.Health = s.state
+	status.ConsecutiveFailures, status.ConsecutiveEmpty = s.consecutiveFailures, s.consecutiveEmpty
+	status.ParseErrorRate = s.parseErrorRate()
+	if s.state == healthSuspended {
+		at := s.nextProbe
+		status.NextProbeAt = &at
+	}
+}
+
+// alert logs event of the source and posts it to the webhook if there is
+// one; the caller holds h.mu.
+func (h *healthMonitor) alert(source, event, message string) {
+	log.Printf("Source health alert for %s: %s", source, message)
+	if h.config.WebhookURL == "" {
+		return
+	}
+	body, err := json.Marshal(healthAlert{Source: source, Event: event, Message: message, At: time.Now().UTC()})
+	if err != nil {
+		log.Printf("Failed to encode source health alert: %v", err)
+		return
+	}
+	go func() {
+		resp, err := h.client.Post(h.config.WebhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("Failed to post source health alert for %s: %v", source, err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("Source health webhook returned status %d for %s", resp.StatusCode, source)
+		}
+	}()
+}
+
+// suspendSource stops a source its health suspended, unless it was resumed
+// or reset meanwhile.
+func (m *Manager) suspendSource(name string) {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	if m.ctx.Err() != nil || !m.health.suspended(name) {
+		return
+	}
+	if run, ok := m.sources[name]; ok && run.cancel != nil {
+		m.stopSource(name, run)
+	}
+}
+
+// probeSource restarts a suspended source, whose first poll then tells
+// whether it recovered.
+func (m *Manager) probeSource(name string) {
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	if m.ctx.Err() != nil || !m.health.startProbe(name) {
+		return
+	}
+	log.Printf("Probing suspended source %s", name)
+	if err := m.replaceSource(name); err != nil {
+		log.Printf("Failed to probe source %s: %v", name, err)
+		m.health.reset(name)
+	}
+}
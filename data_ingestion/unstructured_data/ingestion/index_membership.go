@@ -0,0 +1,352 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+const indexMembershipSource = "index_membership"
+
+// IndexMember is one constituent of a tracked index.
+type IndexMember struct {
+	Symbol string `json:"symbol"`
+	Name   string `json:"name"`
+	Sector string `json:"sector"`
+}
+
+// IndexChange records a constituent entering or leaving an index.
+type IndexChange struct {
+	Index         string    `json:"index"`
+	Symbol        string    `json:"symbol"`
+	Action        string    `json:"action"` // added, removed
+	EffectiveDate time.Time `json:"effective_date"`
+}
+
+// indexSnapshot is the membership of one index as of an effective date.
+type indexSnapshot struct {
+	effectiveDate time.Time
+	members       map[string]IndexMember
+}
+
+// IndexMembershipSource follows the index constituents the market data
+// service tracks and serves on /constituents, stores a dated snapshot per
+// index and emits index_event records when issuers are added or removed.
+// Lists the service serves from its bundled files until a live download
+// succeeds are skipped, as changes between them would not be real.
+type IndexMembershipSource struct {
+	storage storage.Storage
+	config  config.IndexMembershipConfig
+	client  *http.Client
+	enabled bool
+
+	mu        sync.RWMutex
+	snapshots map[string]*indexSnapshot
+}
+
+func NewIndexMembershipSource(store storage.Storage, cfg config.IndexMembershipConfig) *IndexMembershipSource {
+	return &IndexMembershipSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled:   cfg.Enabled,
+		snapshots: make(map[string]*indexSnapshot),
+	}
+}
+
+func (s *IndexMembershipSource) Start(ctx context.Context) error {
+	if !s.enabled {
+		log.Println("Index membership source is disabled")
+		return nil
+	}
+
+	log.Printf("Starting index membership source (%d indices)...", len(s.config.Indices))
+	s.restoreSnapshots(ctx)
+	go supervise(ctx, s.GetName(), s.ingestData)
+	return nil
+}
+
+func (s *IndexMembershipSource) Stop(ctx context.Context) error {
+	log.Println("Stopping index membership source...")
+	return nil
+}
+
+func (s *IndexMembershipSource) GetName() string {
+	return indexMembershipSource
+}
+
+func (s *IndexMembershipSource) IsEnabled() bool {
+	return s.enabled
+}
+
+func (s *IndexMembershipSource) ingestData(ctx context.Context) {
+	s.fetchAll(ctx)
+
+	ticker := newPollTicker(s.config.UpdateInterval, s.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.fetchAll(ctx)
+		}
+	}
+}
+
+func (s *IndexMembershipSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, s.GetName())
+	defer poll.End()
+	for _, feed := range s.config.Indices {
+		if err := s.fetchIndex(ctx, feed); err != nil {
+			log.Printf("Error fetching %s constituents: %v", feed.Index, err)
+		}
+	}
+}
+
+func (s *IndexMembershipSource) fetchIndex(ctx context.Context, feed config.IndexFeed) error {
+	members, live, err := s.fetchConstituents(ctx, feed)
+	if err != nil {
+		return err
+	}
+	if !live {
+		log.Printf("Constituents of %s are not loaded live yet, skipping", feed.Index)
+		return nil
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("constituent list is empty")
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	s.mu.RLock()
+	previous := s.snapshots[feed.Index]
+	s.mu.RUnlock()
+
+	var changes []IndexChange
+	if previous != nil {
+		changes = diffMembership(feed.Index, previous.members, members, today)
+	}
+	if previous != nil && len(changes) == 0 {
+		return nil
+	}
+
+	if err := s.saveSnapshot(ctx, feed, members, today); err != nil {
+		return err
+	}
+	for _, change := range changes {
+		var member IndexMember
+		if change.Action == "removed" {
+			member = previous.members[change.Symbol]
+		} else {
+			member = members[change.Symbol]
+		}
+		if err := s.saveChange(ctx, change, member); err != nil {
+			log.Printf("Error saving %s change for %s: %v", feed.Index, change.Symbol, err)
+		}
+	}
+
+	s.mu.Lock()
+	s.snapshots[feed.Index] = &indexSnapshot{effectiveDate: today, members: members}
+	s.mu.Unlock()
+
+	log.Printf("Updated %s membership: %d constituents, %d changes", feed.Index, len(members), len(changes))
+	return nil
+}
+
+// constituentsURL is where the market data service serves the members of
+// feed's index.
+func (s *IndexMembershipSource) constituentsURL(feed config.IndexFeed) string {
+	return s.config.ConstituentsURL + "?" + url.Values{"index": {feed.Symbol}}.Encode()
+}
+
+// fetchConstituents returns the members of feed's index, and whether the
+// market data service downloaded them rather than serving its bundled list.
+func (s *IndexMembershipSource) fetchConstituents(ctx context.Context, feed config.IndexFeed) (map[string]IndexMember, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.constituentsURL(feed), nil)
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("constituents returned status %d", resp.StatusCode)
+	}
+
+	var constituents struct {
+		Members []IndexMember `json:"members"`
+		Source  string        `json:"source"` // live or bundled
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&constituents); err != nil {
+		return nil, false, parseError(ctx, err)
+	}
+
+	members := make(map[string]IndexMember, len(constituents.Members))
+	for _, member := range constituents.Members {
+		symbol, err := symbols.Normalize(member.Symbol)
+		if err != nil {
+			continue
+		}
+		member.Symbol = symbol
+		members[symbol] = member
+	}
+	return members, constituents.Source == "live", nil
+}
+
+func diffMembership(index string, previous, current map[string]IndexMember, effective time.Time) []IndexChange {
+	var changes []IndexChange
+	for symbol := range current {
+		if _, ok := previous[symbol]; !ok {
+			changes = append(changes, IndexChange{Index: index, Symbol: symbol, Action: "added", EffectiveDate: effective})
+		}
+	}
+	for symbol := range previous {
+		if _, ok := current[symbol]; !ok {
+			changes = append(changes, IndexChange{Index: index, Symbol: symbol, Action: "removed", EffectiveDate: effective})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Symbol < changes[j].Symbol })
+	return changes
+}
+
+func (s *IndexMembershipSource) saveSnapshot(ctx context.Context, feed config.IndexFeed, members map[string]IndexMember, effective time.Time) error {
+	symbols := make([]string, 0, len(members))
+	sectors := make(map[string]interface{}, len(members))
+	names := make(map[string]interface{}, len(members))
+	for symbol, member := range members {
+		symbols = append(symbols, symbol)
+		sectors[symbol] = member.Sector
+		names[symbol] = member.Name
+	}
+	sort.Strings(symbols)
+
+	indexKey := indexTag(feed.Index)
+	hash := md5.Sum([]byte(feed.Index + effective.Format("2006-01-02")))
+
+	data := &models.UnstructuredData{
+		ID:          fmt.Sprintf("index-snapshot-%x", hash[:8]),
+		Source:      indexMembershipSource,
+		Type:        "index_snapshot",
+		Title:       fmt.Sprintf("%s constituents as of %s", feed.Index, effective.Format("2006-01-02")),
+		Content:     strings.Join(symbols, ","),
+		URL:         s.constituentsURL(feed),
+		Author:      feed.Index,
+		PublishedAt: effective,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"index":          feed.Index,
+			"effective_date": effective.Format("2006-01-02"),
+			"member_count":   len(symbols),
+			"sectors":        sectors,
+			"names":          names,
+		},
+		Tags: []string{"index_membership", indexKey},
+	}
+
+	return s.storage.SaveUnstructuredData(ctx, data)
+}
+
+func (s *IndexMembershipSource) saveChange(ctx context.Context, change IndexChange, member IndexMember) error {
+	hash := md5.Sum([]byte(change.Index + change.Symbol + change.Action + change.EffectiveDate.Format("2006-01-02")))
+
+	verb := "added to"
+	tags := []string{"index_membership", indexTag(change.Index), "index_addition"}
+	if change.Action == "removed" {
+		verb = "removed from"
+		// Removal is a credit-relevant signal (size, liquidity, index-fund selling)
+		tags = []string{"index_membership", indexTag(change.Index), "index_removal", "alert"}
+	}
+
+	data := &models.UnstructuredData{
+		ID:          fmt.Sprintf("index-event-%x", hash[:8]),
+		Source:      indexMembershipSource,
+		Type:        "index_event",
+		Title:       fmt.Sprintf("%s %s %s", change.Symbol, verb, change.Index),
+		Content:     fmt.Sprintf("%s (%s) was %s the %s index effective %s.", member.Name, change.Symbol, verb, change.Index, change.EffectiveDate.Format("2006-01-02")),
+		Author:      change.Index,
+		PublishedAt: change.EffectiveDate,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"index":          change.Index,
+			"symbol":         change.Symbol,
+			"action":         change.Action,
+			"sector":         member.Sector,
+			"effective_date": change.EffectiveDate.Format("2006-01-02"),
+		},
+		Tags: tags,
+		Entities: []models.Entity{
+			{Name: change.Symbol, Type: "ORG", Confidence: 1.0},
+		},
+	}
+
+	return s.storage.SaveUnstructuredData(ctx, data)
+}
+
+// restoreSnapshots seeds the in-memory membership from the latest stored
+// snapshot per index so restarts do not re-announce every constituent.
+func (s *IndexMembershipSource) restoreSnapshots(ctx context.Context) {
+	stored, err := s.storage.ListUnstructuredData(ctx, storage.DataFilters{
+		Source: indexMembershipSource,
+		Type:   "index_snapshot",
+	})
+	if err != nil {
+		log.Printf("Error loading index snapshots: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, data := range stored {
+		if data.Source != indexMembershipSource || data.Type != "index_snapshot" {
+			continue
+		}
+		index, _ := data.Metadata["index"].(string)
+		if index == "" {
+			continue
+		}
+		if existing, ok := s.snapshots[index]; ok && !data.PublishedAt.After(existing.effectiveDate) {
+			continue
+		}
+
+		sectors, _ := data.Metadata["sectors"].(map[string]interface{})
+		names, _ := data.Metadata["names"].(map[string]interface{})
+		members := make(map[string]IndexMember)
+		for _, symbol := range strings.Split(data.Content, ",") {
+			if symbol == "" {
+				continue
+			}
+			member := IndexMember{Symbol: symbol}
+			member.Sector, _ = sectors[symbol].(string)
+			member.Name, _ = names[symbol].(string)
+			members[symbol] = member
+		}
+		s.snapshots[index] = &indexSnapshot{effectiveDate: data.PublishedAt, members: members}
+	}
+}
+
+func indexTag(index string) string {
+	return "index_" + strings.ToLower(strings.NewReplacer(" ", "_", "&", "", "-", "_").Replace(index))
+}
@@ -2,22 +2,68 @@ package ingestion
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/entities"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/events"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/ratelimit"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/sentiment"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/summarize"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/tracing"
 )
 
 type Manager struct {
 	storage   storage.Storage
 	config    *config.Config
-	sources   map[string]DataSource
+	sources   map[string]*runningSource
 	workers   []*Worker
+	queue     chan ProcessingJob
+	wake      chan struct{}
+	jobTypes  []string
+	articles  *articleFetcher
+	limiter   *ratelimit.Limiter
+	sentiment sentiment.Scorer
+	entities  *entities.Dictionary
+	resolver  *entities.Resolver
+	summaries *summarize.Client
+	events    events.Classifier
+	observer  JobObserver
+	activity  *Activity
+	health    *healthMonitor
 	ctx       context.Context
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
+
+	// sourcesMu guards sources and sourceConfig, the configuration the
+	// sources are created from as the admin API changes it
+	sourcesMu    sync.Mutex
+	sourceConfig *config.Config
+}
+
+// JobObserver is told of every processing job that completes.
+type JobObserver interface {
+	JobCompleted(ctx context.Context, jobID, dataID, jobType string)
+}
+
+// SetJobObserver has observer told of completed jobs; call it before Start.
+func (m *Manager) SetJobObserver(observer JobObserver) {
+	m.observer = observer
+}
+
+// SetActivity has the manager record the fetches and stored items of its
+// sources in activity, which Sources then reports; call it before Start.
+// The storage must be wrapped by activity for items to be counted, by
+// activity and by source health alike.
+func (m *Manager) SetActivity(activity *Activity) {
+	m.activity = activity
 }
 
 type DataSource interface {
@@ -35,10 +81,12 @@ type Worker struct {
 }
 
 type ProcessingJob struct {
-	DataID   string
-	JobType  string
-	Priority int
-	Data     interface{}
+	ID         string
+	DataID     string
+	JobType    string
+	Priority   int
+	RetryCount int
+	Data       interface{}
 }
 
 func NewManager(store storage.Storage, cfg *config.Config) *Manager {
@@ -47,60 +95,76 @@ func NewManager(store storage.Storage, cfg *config.Config) *Manager {
 	manager := &Manager{
 		storage: store,
 		config:  cfg,
-		sources: make(map[string]DataSource),
+		sources: make(map[string]*runningSource),
 		ctx:     ctx,
 		cancel:  cancel,
 	}
 
+	// The job types polled from storage, in order
+	manager.jobTypes = []string{"sentiment_analysis", "entity_extraction", "credit_event_classification", "quality_check", "summarization"}
+	scorer, err := sentiment.New(cfg.Sentiment)
+	if err != nil {
+		log.Printf("Failed to set up sentiment backend, using the lexicon: %v", err)
+		scorer = sentiment.Lexicon{}
+	}
+	manager.sentiment = scorer
+	dictionary, err := entities.Load(cfg.Entities)
+	if err != nil {
+		log.Printf("Failed to load entity dictionary, using the default: %v", err)
+		dictionary = entities.Default()
+	}
+	manager.entities = dictionary
+	manager.resolver = entities.NewResolver(store)
+	manager.summaries = summarize.New(cfg.Summarization)
+	classifier, err := events.New(cfg.CreditEvents)
+	if err != nil {
+		log.Printf("Failed to set up credit event backend, using the rules: %v", err)
+		classifier = events.Rules{MinConfidence: cfg.CreditEvents.MinConfidence}
+	}
+	manager.events = classifier
+	manager.limiter = ratelimit.New(cfg.RateLimit)
+	if cfg.SourceHealth.Enabled {
+		manager.health = newHealthMonitor(manager, cfg.SourceHealth)
+	}
+	if cfg.Extraction.Enabled {
+		manager.articles = newArticleFetcher(cfg.Extraction)
+		policy := manager.limiter.Policy(articleExtractionJob)
+		if cfg.Extraction.DomainDelay > 0 {
+			policy.QPS = 1 / cfg.Extraction.DomainDelay.Seconds()
+		}
+		manager.limiter.Wrap(manager.articles.client, policy)
+		if tracing.Enabled() {
+			tracing.WrapClient(manager.articles.client, articleExtractionJob)
+		}
+		manager.jobTypes = append(manager.jobTypes, articleExtractionJob, pdfExtractionJob)
+	}
+
 	manager.initializeSources()
 	manager.initializeWorkers()
 
 	return manager
 }
 
+// initializeSources creates the enabled sources of the registered kinds.
+// A kind that fails is left out and the rest still run.
 func (m *Manager) initializeSources() {
-	if m.config.DataSources.Finnhub.Enabled {
-		finnhubSource := NewFinnhubSource(m.storage, m.config.DataSources.Finnhub)
-		m.sources["finnhub"] = finnhubSource
-	}
-	if m.config.DataSources.Reuters.Enabled {
-		reutersSource := NewReutersSource(m.storage, m.config.DataSources.Reuters)
-		m.sources["reuters"] = reutersSource
-	}
-	if m.config.DataSources.Yahoo.Enabled {
-		yahooSource := NewYahooSource(m.storage, m.config.DataSources.Yahoo)
-		m.sources["yahoo"] = yahooSource
-	}
-	if m.config.DataSources.NewsAPI.Enabled {
-		newsAPISource := NewNewsAPISource(m.storage, m.config.DataSources.NewsAPI)
-		m.sources["newsapi"] = newsAPISource
-	}
-	if m.config.DataSources.MarketWatch.Enabled {
-		marketWatchSource := NewMarketWatchSource(m.storage, m.config.DataSources.MarketWatch)
-		m.sources["marketwatch"] = marketWatchSource
-	}
-	if m.config.DataSources.Bloomberg.Enabled {
-		bloombergSource := NewBloombergSource(m.storage, m.config.DataSources.Bloomberg)
-		m.sources["bloomberg"] = bloombergSource
-	}
-	if m.config.DataSources.Kofin.Enabled {
-		kofinSource := NewKofinSource(m.storage, m.config.DataSources.Kofin)
-		m.sources["kofin"] = kofinSource
-	}
-	if m.config.DataSources.FedNews.Enabled {
-		fedNewsSource := NewFedNewsSource(m.storage, m.config.DataSources.FedNews)
-		m.sources["fednews"] = fedNewsSource
+	sources, err := createSources(m.storage, m.config)
+	if err != nil {
+		log.Printf("Error initializing data sources: %v", err)
 	}
+	m.sources = sources
+	m.sourceConfig = m.config
 }
 
 func (m *Manager) initializeWorkers() {
-	jobQueue := make(chan ProcessingJob, m.config.Processing.QueueSize)
+	m.queue = make(chan ProcessingJob, m.config.Processing.QueueSize)
+	m.wake = make(chan struct{}, 1)
 	
 	for i := 0; i < m.config.Processing.MaxWorkers; i++ {
 		worker := &Worker{
 			id:      i,
 			manager: m,
-			jobs:    jobQueue,
+			jobs:    m.queue,
 			quit:    make(chan bool),
 		}
 		m.workers = append(m.workers, worker)
@@ -115,20 +179,23 @@ func (m *Manager) Start() error {
 		go worker.start()
 	}
 
-	for name, source := range m.sources {
-		if source.IsEnabled() {
-			log.Printf("Starting data source: %s", name)
-			m.wg.Add(1)
-			go func(name string, source DataSource) {
-				defer m.wg.Done()
-				if err := source.Start(m.ctx); err != nil {
-					log.Printf("Error starting source %s: %v", name, err)
-				}
-			}(name, source)
+	m.sourcesMu.Lock()
+	for name, run := range m.sources {
+		if run.source.IsEnabled() {
+			m.startSource(name, run)
 		}
 	}
+	m.sourcesMu.Unlock()
 	m.wg.Add(1)
 	go m.monitor()
+	m.wg.Add(1)
+	go m.dispatch()
+	m.wg.Add(1)
+	go m.syncCompanies()
+	if m.config.Reload.File != "" && m.config.Reload.Interval > 0 {
+		m.wg.Add(1)
+		go m.watchConfig()
+	}
 
 	return nil
 }
@@ -136,17 +203,23 @@ func (m *Manager) Start() error {
 func (m *Manager) Stop(ctx context.Context) error {
 	log.Println("Stopping data ingestion manager...")
 	m.cancel()
+	if m.health != nil {
+		m.health.stop()
+	}
 
-	for name, source := range m.sources {
-		if source.IsEnabled() {
-			log.Printf("Stopping data source: %s", name)
-			if err := source.Stop(ctx); err != nil {
-				log.Printf("Error stopping source %s: %v", name, err)
-			}
+	m.sourcesMu.Lock()
+	for name, run := range m.sources {
+		if run.cancel != nil {
+			m.stopSource(name, run)
 		}
 	}
+	m.sourcesMu.Unlock()
 	for _, worker := range m.workers {
-		worker.quit <- true
+		// Workers also stop on the cancelled context and may be gone already
+		select {
+		case worker.quit <- true:
+		default:
+		}
 	}
 	done := make(chan struct{})
 	go func() {
@@ -162,9 +235,101 @@ func (m *Manager) Stop(ctx context.Context) error {
 		return ctx.Err()
 	}
 
+	m.releaseQueued(ctx)
 	return nil
 }
 
+// dispatch polls storage for pending jobs and hands them to the workers.
+// Each job is claimed before it is queued so that several ingestion
+// processes sharing one database never run the same job twice. Only as many
+// jobs are claimed ahead as there are workers, so the priority order is
+// decided afresh whenever a worker frees up and an urgent job never waits
+// behind a deep backlog; workers wake the dispatcher as they finish.
+func (m *Manager) dispatch() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.config.Processing.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep polling while there is a backlog
+		for m.dispatchPending() > 0 {
+		}
+
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-m.wake:
+		}
+	}
+}
+
+// dispatchPending claims the pending jobs of every type with the highest
+// effective priority, up to the free space in the queue, and returns how
+// many it queued. Jobs of equal priority go oldest first whatever their
+// type, so no job type starves another.
+func (m *Manager) dispatchPending() int {
+	depth := min(cap(m.queue), max(m.config.Processing.MaxWorkers, 1))
+	free := depth - len(m.queue)
+	if free <= 0 || m.ctx.Err() != nil {
+		return 0
+	}
+
+	var candidates []*models.ProcessingJob
+	for _, jobType := range m.jobTypes {
+		jobs, err := m.storage.GetPendingJobs(m.ctx, jobType, free)
+		if err != nil {
+			log.Printf("Failed to poll %s jobs: %v", jobType, err)
+			continue
+		}
+		candidates = append(candidates, jobs...)
+	}
+	now := time.Now()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return storage.EffectivePriority(candidates[i], now) > storage.EffectivePriority(candidates[j], now)
+	})
+
+	queued := 0
+	for _, job := range candidates {
+		if queued == free {
+			break
+		}
+		claimed, err := m.storage.ClaimJob(m.ctx, job.ID)
+		if err != nil {
+			log.Printf("Failed to claim job %s: %v", job.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		m.queue <- ProcessingJob{
+			ID:         job.ID,
+			DataID:     job.DataID,
+			JobType:    job.JobType,
+			Priority:   job.Priority,
+			RetryCount: job.RetryCount,
+		}
+		queued++
+	}
+	return queued
+}
+
+// releaseQueued returns claimed jobs no worker started to pending, so the
+// next run picks them up.
+func (m *Manager) releaseQueued(ctx context.Context) {
+	for {
+		select {
+		case job := <-m.queue:
+			if err := m.storage.UpdateJobStatus(ctx, job.ID, "pending", nil, ""); err != nil {
+				log.Printf("Failed to release job %s: %v", job.ID, err)
+			}
+		default:
+			return
+		}
+	}
+}
+
 func (m *Manager) monitor() {
 	defer m.wg.Done()
 	
@@ -184,7 +349,14 @@ func (m *Manager) monitor() {
 func (m *Manager) logStats() {
 	since := time.Now().Add(-24 * time.Hour)
 	
+	m.sourcesMu.Lock()
+	names := make([]string, 0, len(m.sources))
 	for name := range m.sources {
+		names = append(names, name)
+	}
+	m.sourcesMu.Unlock()
+
+	for _, name := range names {
 		stats, err := m.storage.GetDataQualityStats(context.Background(), name, since)
 		if err != nil {
 			log.Printf("Failed to get stats for source %s: %v", name, err)
@@ -194,6 +366,11 @@ func (m *Manager) logStats() {
 		log.Printf("Source %s - Quality: %.2f, Items: %d, Issues: %d", 
 			name, stats.AverageQuality, stats.TotalItems, stats.IssueCount)
 	}
+	if m.summaries != nil {
+		stats := m.summaries.Stats()
+		log.Printf("Summarization - Requests: %d, Tokens: %d in / %d out, Cost: $%.4f",
+			stats.Requests, stats.InputTokens, stats.OutputTokens, stats.Cost)
+	}
 }
 
 func (w *Worker) start() {
@@ -215,38 +392,111 @@ func (w *Worker) start() {
 	}
 }
 
+// processJob runs a claimed job and records its outcome in storage, all in
+// one span.
 func (w *Worker) processJob(job ProcessingJob) {
 	log.Printf("Worker %d processing job: %s for data %s", w.id, job.JobType, job.DataID)
-	switch job.JobType {
-	case "sentiment_analysis":
-		w.processSentimentAnalysis(job)
-	case "entity_extraction":
-		w.processEntityExtraction(job)
-	case "summarization":
-		w.processSummarization(job)
-	case "quality_check":
-		w.processQualityCheck(job)
+
+	defer w.manager.wakeDispatcher()
+
+	ctx, span := tracing.Start(w.manager.ctx, "job "+job.JobType,
+		tracing.String("job.id", job.ID), tracing.String("job.type", job.JobType),
+		tracing.String("data.id", job.DataID), tracing.Int("job.attempt", job.RetryCount+1))
+	defer span.End()
+
+	if err := w.runJob(ctx, job); err != nil {
+		span.RecordError(err)
+		w.manager.failJob(ctx, job, err)
+		return
+	}
+	// The manager's context may be cancelled by now; the outcome is still recorded
+	if err := w.manager.storage.UpdateJobStatus(context.WithoutCancel(ctx), job.ID, "completed", nil, ""); err != nil {
+		log.Printf("Failed to update job %s: %v", job.ID, err)
+		return
+	}
+	if w.manager.observer != nil {
+		w.manager.observer.JobCompleted(context.Background(), job.ID, job.DataID, job.JobType)
+	}
+}
+
+// wakeDispatcher has the dispatcher fill a freed queue slot without waiting
+// for its next poll.
+func (m *Manager) wakeDispatcher() {
+	select {
+	case m.wake <- struct{}{}:
 	default:
-		log.Printf("Unknown job type: %s", job.JobType)
 	}
 }
 
-func (w *Worker) processSentimentAnalysis(job ProcessingJob) {
-	log.Printf("Processing sentiment analysis for data %s", job.DataID)
-	time.Sleep(1 * time.Second)
+// failJob schedules the retry of a failed job, or moves it to the dead state
+// once it has run MaxAttempts times. Dead jobs stay until an operator
+// requeues them through the admin API.
+func (m *Manager) failJob(ctx context.Context, job ProcessingJob, jobErr error) {
+	ctx = context.WithoutCancel(ctx)
+	attempts := job.RetryCount + 1
+
+	if attempts >= m.config.Processing.MaxAttempts {
+		log.Printf("Job %s (%s for %s) failed %d times, giving up: %v", job.ID, job.JobType, job.DataID, attempts, jobErr)
+		if err := m.storage.UpdateJobStatus(ctx, job.ID, "dead", nil, jobErr.Error()); err != nil {
+			log.Printf("Failed to update job %s: %v", job.ID, err)
+		}
+		return
+	}
+
+	delay := retryDelay(m.config.Processing, attempts)
+	log.Printf("Job %s (%s for %s) failed, retrying in %v: %v", job.ID, job.JobType, job.DataID, delay, jobErr)
+	if err := m.storage.RetryJob(ctx, job.ID, jobErr.Error(), time.Now().Add(delay)); err != nil {
+		log.Printf("Failed to schedule retry of job %s: %v", job.ID, err)
+	}
 }
 
-func (w *Worker) processEntityExtraction(job ProcessingJob) {
-	log.Printf("Processing entity extraction for data %s", job.DataID)
-	time.Sleep(1 * time.Second)
+// retryDelay is the wait after a job's nth failed attempt: RetryBaseDelay,
+// doubled for every earlier failure, capped at RetryMaxDelay.
+func retryDelay(cfg config.ProcessingConfig, attempt int) time.Duration {
+	delay := cfg.RetryBaseDelay
+	for i := 1; i < attempt && delay < cfg.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > cfg.RetryMaxDelay {
+		delay = cfg.RetryMaxDelay
+	}
+	return delay
 }
 
-func (w *Worker) processSummarization(job ProcessingJob) {
-	log.Printf("Processing summarization for data %s", job.DataID)
-	time.Sleep(1 * time.Second)
+func (w *Worker) runJob(ctx context.Context, job ProcessingJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			reporting.CapturePanic(r, map[string]string{
+				"component": "worker",
+				"job_type":  job.JobType,
+				"data_id":   job.DataID,
+			})
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	switch job.JobType {
+	case "sentiment_analysis":
+		return w.processSentimentAnalysis(ctx, job)
+	case "entity_extraction":
+		return w.processEntityExtraction(ctx, job)
+	case "credit_event_classification":
+		return w.processCreditEvents(ctx, job)
+	case "summarization":
+		return w.processSummarization(ctx, job)
+	case "quality_check":
+		return w.processQualityCheck(ctx, job)
+	case articleExtractionJob:
+		return w.processArticleExtraction(ctx, job)
+	case pdfExtractionJob:
+		return w.processPDFExtraction(ctx, job)
+	default:
+		return fmt.Errorf("unknown job type: %s", job.JobType)
+	}
 }
 
-func (w *Worker) processQualityCheck(job ProcessingJob) {
+func (w *Worker) processQualityCheck(ctx context.Context, job ProcessingJob) error {
 	log.Printf("Processing quality check for data %s", job.DataID)
 	time.Sleep(500 * time.Millisecond)
+	return nil
 }
@@ -0,0 +1,122 @@
+package ingestion
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/cron"
+	"github.com/gaixen/CredTech/symbols/calendar"
+)
+
+// marketSettle is how long after a close a market still counts as trading, so
+// delayed quotes and closing auction prints are picked up by one more poll.
+const marketSettle = 20 * time.Minute
+
+// marketSchedule paces a poller by the trading hours of the exchanges its
+// symbols are listed on.
+type marketSchedule struct {
+	calendars []*calendar.Calendar
+	open      time.Duration  // interval while any of the markets is trading
+	closed    time.Duration  // interval while all are shut; zero waits for the next open
+	cron      *cron.Schedule // replaces the market hours when set
+}
+
+// newMarketSchedule builds a schedule for the given symbols. Symbols without a
+// calendar, such as indices and currencies, are ignored; if none has one the
+// poller runs at the open interval around the clock.
+func newMarketSchedule(syms []string, open, closed time.Duration) *marketSchedule {
+	seen := make(map[*calendar.Calendar]bool)
+	schedule := &marketSchedule{open: open, closed: closed}
+	for _, symbol := range syms {
+		if cal := calendar.ForSymbol(symbol); cal != nil && !seen[cal] {
+			seen[cal] = true
+			schedule.calendars = append(schedule.calendars, cal)
+		}
+	}
+	return schedule
+}
+
+// withCron makes the schedule poll at the times of a cron schedule instead,
+// if schedule is set.
+func (m *marketSchedule) withCron(schedule string) *marketSchedule {
+	if schedule == "" {
+		return m
+	}
+	s, err := cron.Parse(schedule)
+	if err != nil {
+		log.Printf("Polling by market hours instead of by invalid schedule: %v", err)
+		return m
+	}
+	m.cron = s
+	return m
+}
+
+// trading reports whether any of the markets is open at now or closed within
+// marketSettle of it.
+func (m *marketSchedule) trading(now time.Time) bool {
+	if len(m.calendars) == 0 {
+		return true
+	}
+	for _, cal := range m.calendars {
+		if cal.IsOpen(now) || cal.IsOpen(now.Add(-marketSettle)) {
+			return true
+		}
+	}
+	return false
+}
+
+// next returns how long to wait after now before polling again.
+func (m *marketSchedule) next(now time.Time) time.Duration {
+	if m.cron != nil {
+		if at := m.cron.Next(now); !at.IsZero() {
+			return at.Sub(now)
+		}
+	}
+	if m.trading(now) {
+		return m.open
+	}
+	wait := m.nextOpen(now).Sub(now)
+	if m.closed > 0 && m.closed < wait {
+		return max(m.closed, m.open)
+	}
+	return wait
+}
+
+// run calls poll on the schedule until ctx is cancelled.
+func (m *marketSchedule) run(ctx context.Context, name string, poll func(ctx context.Context)) {
+	wasTrading := m.trading(time.Now())
+	timer := time.NewTimer(m.next(time.Now()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			poll(ctx)
+
+			now := time.Now()
+			if trading := m.trading(now); m.cron == nil && trading != wasTrading {
+				wasTrading = trading
+				if trading {
+					log.Printf("Markets open, %s polling every %v", name, m.open)
+				} else {
+					log.Printf("Markets closed, %s polling slowed until %v", name, m.nextOpen(now).Format(time.RFC3339))
+				}
+			}
+			timer.Reset(m.next(now))
+		}
+	}
+}
+
+// nextOpen returns the earliest next open across the markets.
+func (m *marketSchedule) nextOpen(now time.Time) time.Time {
+	var earliest time.Time
+	for _, cal := range m.calendars {
+		if open := cal.NextOpen(now); earliest.IsZero() || open.Before(earliest) {
+			earliest = open
+		}
+	}
+	return earliest
+}
@@ -12,6 +12,8 @@ import (
 	"time"
 
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/entities"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
 )
@@ -63,7 +65,7 @@ func (n *NewsAPISource) Start(ctx context.Context) error {
 	log.Println("Starting NewsAPI data source...")
 
 	// Start news ingestion
-	go n.ingestNews(ctx)
+	go supervise(ctx, n.GetName(), n.ingestNews)
 
 	return nil
 }
@@ -87,7 +89,7 @@ func (n *NewsAPISource) ingestNews(ctx context.Context) {
 		log.Printf("Error in initial NewsAPI fetch: %v", err)
 	}
 
-	ticker := time.NewTicker(n.config.UpdateInterval)
+	ticker := newPollTicker(n.config.UpdateInterval, n.config.Schedule)
 	defer ticker.Stop()
 
 	for {
@@ -103,13 +105,13 @@ func (n *NewsAPISource) ingestNews(ctx context.Context) {
 }
 
 func (n *NewsAPISource) fetchNews(ctx context.Context) error {
+	ctx, poll := startPoll(ctx, n.GetName())
+	defer poll.End()
 	
 	for _, keyword := range n.config.Keywords {
 		if err := n.fetchNewsForKeyword(ctx, keyword); err != nil {
 			log.Printf("Error fetching news for keyword '%s': %v", keyword, err)
 		}
-
-		time.Sleep(2 * time.Second)
 	}
 	if len(n.config.Sources) > 0 {
 		if err := n.fetchNewsFromSources(ctx); err != nil {
@@ -149,7 +151,7 @@ func (n *NewsAPISource) fetchNewsForKeyword(ctx context.Context, keyword string)
 
 	var newsResponse NewsAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&newsResponse); err != nil {
-		return fmt.Errorf("failed to decode news response: %w", err)
+		return parseError(ctx, fmt.Errorf("failed to decode news response: %w", err))
 	}
 
 	for _, article := range newsResponse.Articles {
@@ -190,7 +192,7 @@ func (n *NewsAPISource) fetchNewsFromSources(ctx context.Context) error {
 
 	var newsResponse NewsAPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&newsResponse); err != nil {
-		return fmt.Errorf("failed to decode news response: %w", err)
+		return parseError(ctx, fmt.Errorf("failed to decode news response: %w", err))
 	}
 
 	for _, article := range newsResponse.Articles {
@@ -209,22 +211,21 @@ func (n *NewsAPISource) processNewsArticle(ctx context.Context, article NewsArti
 	dataID := fmt.Sprintf("newsapi-%x", hash[:8])
 
 	
-	entities := n.extractEntities(article.Title + " " + article.Description + " " + article.Content)
-
-	
-	symbols := n.extractFinancialSymbols(article.Title + " " + article.Description + " " + article.Content)
+	title := extraction.CleanText(article.Title)
+	description := extraction.CleanText(article.Description)
+	content := extraction.CleanText(article.Content)
+	found := entities.Extract(title + " " + description + " " + content)
 
 	
-	content := article.Content
 	if content == "" {
-		content = article.Description
+		content = description
 	}
 
 	data := &models.UnstructuredData{
 		ID:          dataID,
 		Source:      "newsapi",
 		Type:        "news",
-		Title:       article.Title,
+		Title:       title,
 		Content:     content,
 		URL:         article.URL,
 		Author:      n.getAuthor(article),
@@ -235,10 +236,10 @@ func (n *NewsAPISource) processNewsArticle(ctx context.Context, article NewsArti
 			"source_name": article.Source.Name,
 			"image_url":   article.URLToImage,
 			"search_term": searchTerm,
-			"symbols":     symbols,
+			"symbols":     entities.Symbols(found),
 		},
 		Tags:     n.generateTags(article, searchTerm),
-		Entities: entities,
+		Entities: found,
 	}
 
 	return n.storage.SaveUnstructuredData(ctx, data)
@@ -254,84 +255,6 @@ func (n *NewsAPISource) getAuthor(article NewsArticle) string {
 	return "Unknown"
 }
 
-func (n *NewsAPISource) extractEntities(text string) []models.Entity {
-	var entities []models.Entity
-
-	words := strings.Fields(text)
-
-	for i, word := range words {
-		word = strings.Trim(word, ".,!?;:()")
-
-		if len(word) > 2 && strings.Title(word) == word {
-			if n.isLikelyOrganization(word) {
-				entities = append(entities, models.Entity{
-					Name:       word,
-					Type:       "ORG",
-					Confidence: 0.7,
-					StartPos:   i * 6,
-					EndPos:     i*6 + len(word),
-				})
-			}
-		}
-		if len(word) >= 2 && len(word) <= 5 && strings.ToUpper(word) == word {
-			entities = append(entities, models.Entity{
-				Name:       word,
-				Type:       "STOCK_SYMBOL",
-				Confidence: 0.6,
-				StartPos:   i * 6,
-				EndPos:     i*6 + len(word),
-			})
-		}
-	}
-
-	content := text
-	dollarIndex := strings.Index(content, "$")
-	if dollarIndex != -1 {
-		entities = append(entities, models.Entity{
-			Name:       "MONEY_AMOUNT",
-			Type:       "MONEY",
-			Confidence: 0.8,
-			StartPos:   dollarIndex,
-			EndPos:     dollarIndex + 10, 
-		})
-	}
-
-	return entities
-}
-
-func (n *NewsAPISource) isLikelyOrganization(word string) bool {
-	orgSuffixes := []string{"Corp", "Inc", "Ltd", "LLC", "Group", "Company", "Bank", "Fund", "Trust", "Holdings"}
-
-	for _, suffix := range orgSuffixes {
-		if strings.HasSuffix(word, suffix) {
-			return true
-		}
-	}
-	commonOrgs := []string{"Apple", "Google", "Microsoft", "Amazon", "Tesla", "Meta", "Netflix", "Goldman", "Morgan", "JPMorgan", "Bank", "Federal", "Reserve", "Treasury"}
-
-	for _, org := range commonOrgs {
-		if strings.Contains(word, org) {
-			return true
-		}
-	}
-
-	return len(word) >= 4 && len(word) <= 20
-}
-
-func (n *NewsAPISource) extractFinancialSymbols(text string) []string {
-	var symbols []string
-	words := strings.Fields(text)
-
-	for _, word := range words {
-		word = strings.Trim(word, ".,!?;:()")
-		if len(word) >= 2 && len(word) <= 5 && strings.ToUpper(word) == word && strings.ToLower(word) != word {
-			symbols = append(symbols, word)
-		}
-	}
-
-	return symbols
-}
-
 func (n *NewsAPISource) generateTags(article NewsArticle, searchTerm string) []string {
 	tags := []string{"newsapi", "financial_news", searchTerm}
 
@@ -376,29 +299,6 @@ func (n *NewsAPISource) generateTags(article NewsArticle, searchTerm string) []s
 	if strings.Contains(content, "unemployment") || strings.Contains(content, "jobs") || strings.Contains(content, "employment") {
 		tags = append(tags, "employment")
 	}
-	positiveWords := []string{"gain", "rise", "growth", "profit", "success", "beat", "exceed", "strong", "positive", "bull"}
-	negativeWords := []string{"loss", "decline", "fall", "crisis", "bankruptcy", "miss", "weak", "negative", "bear", "recession"}
-
-	positiveCount := 0
-	negativeCount := 0
-
-	for _, word := range positiveWords {
-		if strings.Contains(content, word) {
-			positiveCount++
-		}
-	}
-
-	for _, word := range negativeWords {
-		if strings.Contains(content, word) {
-			negativeCount++
-		}
-	}
-
-	if positiveCount > negativeCount && positiveCount > 0 {
-		tags = append(tags, "positive_sentiment")
-	} else if negativeCount > positiveCount && negativeCount > 0 {
-		tags = append(tags, "negative_sentiment")
-	}
 	if strings.Contains(content, "tech") || strings.Contains(content, "technology") || strings.Contains(content, "software") {
 		tags = append(tags, "technology")
 	}
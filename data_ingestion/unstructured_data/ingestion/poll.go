@@ -0,0 +1,136 @@
+package ingestion
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/cron"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/tracing"
+)
+
+// poll is one poll of a source. It ends the span the poll runs in, so the
+// requests it sends and the documents it stores show up in one trace, and
+// counts what came of them for the source's health.
+type poll struct {
+	ctx    context.Context
+	span   *tracing.Span
+	source string
+	health *healthMonitor
+
+	mu     sync.Mutex
+	result pollResult
+}
+
+// pollResult counts the requests of a poll, those that failed and those
+// whose responses could not be parsed, and the documents it stored.
+type pollResult struct {
+	requests    int
+	failures    int
+	parseErrors int
+	items       int
+}
+
+// failed reports whether nothing the poll asked for came back usable. A
+// poll that sent no requests the transport saw fails only on parse errors.
+func (r pollResult) failed() bool {
+	bad := r.failures + r.parseErrors
+	return r.items == 0 && bad > 0 && bad >= r.requests
+}
+
+type pollKey struct{}
+
+// startPoll begins one poll of a source; End must be called.
+func startPoll(ctx context.Context, source string) (context.Context, *poll) {
+	ctx, span := tracing.Start(ctx, "poll "+source, tracing.String("source", source))
+	p := &poll{ctx: ctx, span: span, source: source, health: healthFrom(ctx)}
+	return context.WithValue(ctx, pollKey{}, p), p
+}
+
+func pollFrom(ctx context.Context) *poll {
+	p, _ := ctx.Value(pollKey{}).(*poll)
+	return p
+}
+
+// End ends the poll's span and reports the poll to the source's health,
+// unless the source was stopping, which cuts polls short.
+func (p *poll) End() {
+	p.span.End()
+	if p.health == nil || p.ctx.Err() != nil {
+		return
+	}
+	p.mu.Lock()
+	result := p.result
+	p.mu.Unlock()
+	p.health.record(p.source, result)
+}
+
+// count applies f to the result of the poll ctx belongs to, if any.
+func count(ctx context.Context, f func(*pollResult)) {
+	p := pollFrom(ctx)
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	f(&p.result)
+	p.mu.Unlock()
+}
+
+// parseError counts err, a response that could not be parsed, against the
+// poll of ctx and returns it.
+func parseError(ctx context.Context, err error) error {
+	count(ctx, func(r *pollResult) { r.parseErrors++ })
+	return err
+}
+
+// pollTicker paces a source loop: every interval, or at the times of the
+// source's cron schedule when it has one.
+type pollTicker struct {
+	C    <-chan time.Time
+	stop func()
+}
+
+func newPollTicker(interval time.Duration, schedule string) *pollTicker {
+	if schedule != "" {
+		s, err := cron.Parse(schedule)
+		if err == nil {
+			return newCronTicker(s)
+		}
+		log.Printf("Polling every %v instead of by invalid schedule: %v", interval, err)
+	}
+	ticker := time.NewTicker(interval)
+	return &pollTicker{C: ticker.C, stop: ticker.Stop}
+}
+
+// newCronTicker fires at the schedule's times. Like a time.Ticker it drops
+// ticks a slow receiver is not ready for.
+func newCronTicker(s *cron.Schedule) *pollTicker {
+	c := make(chan time.Time, 1)
+	done := make(chan struct{})
+	go func() {
+		for {
+			next := s.Next(time.Now())
+			if next.IsZero() {
+				return
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-done:
+				timer.Stop()
+				return
+			case at := <-timer.C:
+				select {
+				case c <- at:
+				default:
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return &pollTicker{C: c, stop: func() { once.Do(func() { close(done) }) }}
+}
+
+func (t *pollTicker) Stop() {
+	t.stop()
+}
@@ -0,0 +1,330 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// Press release types
+const (
+	ReleaseEarnings      = "earnings"
+	ReleaseMergers       = "m_and_a"
+	ReleaseGuidance      = "guidance"
+	ReleaseRestructuring = "restructuring"
+	ReleaseOther         = "other"
+)
+
+// releasePatterns classify press releases, checked in order so that an
+// earnings release mentioning its outlook stays an earnings release.
+var releasePatterns = []struct {
+	releaseType string
+	pattern     *regexp.Regexp
+}{
+	{ReleaseEarnings, regexp.MustCompile(`(?i)\b(first|second|third|fourth|q[1-4]|quarter(ly)?|full[- ]year|fiscal( year)?( 20\d\d)?|half[- ]year|annual)\b[^.]{0,60}\b(results|earnings|financial results)\b|\bearnings (release|call|conference call)\b`)},
+	{ReleaseMergers, regexp.MustCompile(`(?i)\b(to acquire|acquires|acquired by|to be acquired|acquisition of|completes acquisition|merger|merge with|definitive agreement|tender offer|business combination|take[- ]private)\b`)},
+	{ReleaseRestructuring, regexp.MustCompile(`(?i)\b(restructuring|chapter 11|bankruptcy|workforce reduction|reduction in force|layoffs?|strategic alternatives|forbearance|debt exchange|recapitalization|going concern|wind[- ]down)\b`)},
+	{ReleaseGuidance, regexp.MustCompile(`(?i)\b(guidance|outlook|forecast)\b[^.]{0,40}\b(raises?|lowers?|reaffirms?|updates?|withdraws?|cuts?|narrows?|initiates?)\b|\b(raises?|lowers?|reaffirms?|updates?|withdraws?|cuts?|narrows?|initiates?)\b[^.]{0,40}\b(guidance|outlook|forecast)\b`)},
+}
+
+var (
+	// listingTag matches the "(NYSE: ACME)" tags wires put after a company name
+	listingTag = regexp.MustCompile(`\((?:NYSE|NASDAQ|Nasdaq|NYSE American|NYSE Arca|NasdaqGS|NasdaqGM|NasdaqCM|Cboe|OTCQX|OTCQB)\s*:\s*([A-Z][A-Z0-9.\-]{0,6})\)`)
+	// datelineCompany matches the issuer in "CITY, May 1, 2024 /PRNewswire/ -- Acme Corp. (NYSE: ACME)"
+	datelineCompany = regexp.MustCompile(`(?:--|—|–)\s*([^()\n]{2,80}?)\s*\((?:NYSE|NASDAQ|Nasdaq|NYSE American|NYSE Arca|NasdaqGS|NasdaqGM|NasdaqCM|Cboe|OTCQX|OTCQB)\s*:`)
+	// dateline matches the "CITY, date /PRNewswire/ --" style lead-in of a release body
+	dateline = regexp.MustCompile(`^.{0,120}?(/PRNewswire[^/]*/|\(BUSINESS WIRE\)|\(GLOBE NEWSWIRE\))\s*(--|—|–)?\s*`)
+
+	revenueFigure = regexp.MustCompile(`(?i)\brevenues? (?:of|was|were|totaled|reached|increased [^$]{0,30}to|decreased [^$]{0,30}to)\s*\$\s?([\d,.]+)\s*(million|billion)`)
+	epsFigure     = regexp.MustCompile(`(?i)\$\s?(\d+\.\d{2}) per (?:diluted )?share`)
+	netIncome     = regexp.MustCompile(`(?i)\bnet (income|loss) (?:of|was)\s*\$\s?([\d,.]+)\s*(million|billion)`)
+)
+
+// pressReleaseItem extends RSSItem with the Dublin Core fields wires use for
+// the issuing organization.
+type pressReleaseItem struct {
+	RSSItem
+	Creator     string `xml:"creator"`
+	Contributor string `xml:"contributor"`
+}
+
+type pressReleaseFeed struct {
+	XMLName xml.Name `xml:"rss"`
+	Channel struct {
+		Items []pressReleaseItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type PressReleaseSource struct {
+	storage storage.Storage
+	config  config.PressReleasesConfig
+	client  *http.Client
+	enabled bool
+}
+
+func NewPressReleaseSource(store storage.Storage, cfg config.PressReleasesConfig) *PressReleaseSource {
+	return &PressReleaseSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+	}
+}
+
+func (p *PressReleaseSource) Start(ctx context.Context) error {
+	if !p.enabled {
+		log.Println("Press release source is disabled")
+		return nil
+	}
+
+	log.Printf("Starting press release data source (%d feeds)...", len(p.config.Feeds))
+	go supervise(ctx, p.GetName(), p.ingestData)
+	return nil
+}
+
+func (p *PressReleaseSource) Stop(ctx context.Context) error {
+	log.Println("Stopping press release source...")
+	return nil
+}
+
+func (p *PressReleaseSource) GetName() string {
+	return "press_releases"
+}
+
+func (p *PressReleaseSource) IsEnabled() bool {
+	return p.enabled
+}
+
+func (p *PressReleaseSource) ingestData(ctx context.Context) {
+	p.fetchAll(ctx)
+
+	ticker := newPollTicker(p.config.UpdateInterval, p.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.fetchAll(ctx)
+		}
+	}
+}
+
+func (p *PressReleaseSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, p.GetName())
+	defer poll.End()
+	for _, feed := range p.config.Feeds {
+		if err := p.fetchFeed(ctx, feed); err != nil {
+			log.Printf("Error fetching %s feed %s: %v", feed.Wire, feed.URL, err)
+		}
+	}
+}
+
+func (p *PressReleaseSource) fetchFeed(ctx context.Context, feed config.PressReleaseFeed) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", feed.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var parsed pressReleaseFeed
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		return parseError(ctx, err)
+	}
+
+	for _, item := range parsed.Channel.Items {
+		if err := p.processItem(ctx, feed, item); err != nil {
+			log.Printf("Error saving %s release: %v", feed.Wire, err)
+		}
+	}
+
+	log.Printf("Processed %d %s releases", len(parsed.Channel.Items), feed.Wire)
+	return nil
+}
+
+func (p *PressReleaseSource) processItem(ctx context.Context, feed config.PressReleaseFeed, item pressReleaseItem) error {
+	release := buildPressRelease(feed, item)
+
+	data := release.UnstructuredData
+	data.Metadata = map[string]interface{}{
+		"guid":           item.GUID,
+		"wire":           feed.Wire,
+		"company":        release.Company,
+		"symbol":         release.Symbol,
+		"symbols":        release.symbols,
+		"release_type":   release.ReleaseType,
+		"key_points":     release.KeyPoints,
+		"financial_data": release.FinancialData,
+		"categories":     item.Category,
+	}
+	return p.storage.SaveUnstructuredData(ctx, &data)
+}
+
+// pressRelease is a PressRelease together with every ticker it was tagged with.
+type pressRelease struct {
+	models.PressRelease
+	symbols []string
+}
+
+func buildPressRelease(feed config.PressReleaseFeed, item pressReleaseItem) *pressRelease {
+	wireKey := strings.ToLower(strings.ReplaceAll(feed.Wire, " ", ""))
+	hash := md5.Sum([]byte(item.Link + item.Title))
+	title := extraction.CleanText(item.Title)
+	content := extraction.CleanText(item.Description)
+	text := title + "\n" + content
+
+	pubDate, err := parseFeedDate(item.PubDate)
+	if err != nil {
+		pubDate = time.Now()
+	}
+
+	var tickers []string
+	for _, match := range listingTag.FindAllStringSubmatch(text, -1) {
+		tickers = append(tickers, match[1])
+	}
+	tickers = symbols.NormalizeAll(tickers)
+	symbol := ""
+	if len(tickers) > 0 {
+		symbol = tickers[0]
+	}
+
+	company := strings.TrimSpace(item.Contributor)
+	if company == "" {
+		company = strings.TrimSpace(item.Creator)
+	}
+	if match := datelineCompany.FindStringSubmatch(text); company == "" && match != nil {
+		company = strings.TrimSpace(match[1])
+	}
+
+	releaseType, matched := classifyRelease(title, content)
+	tags := []string{wireKey, "press_release", releaseType}
+	for _, t := range matched {
+		if t != releaseType {
+			tags = append(tags, t)
+		}
+	}
+	if symbol != "" {
+		tags = append(tags, strings.ToLower(symbol))
+	}
+
+	return &pressRelease{
+		PressRelease: models.PressRelease{
+			UnstructuredData: models.UnstructuredData{
+				ID:          fmt.Sprintf("%s-%x", wireKey, hash[:8]),
+				Source:      "press_releases",
+				Type:        "press_release",
+				Title:       title,
+				Content:     content,
+				URL:         strings.TrimSpace(item.Link),
+				Author:      company,
+				PublishedAt: pubDate,
+				IngestedAt:  time.Now(),
+				Tags:        tags,
+			},
+			Company:       company,
+			Symbol:        symbol,
+			ReleaseType:   releaseType,
+			KeyPoints:     keyPoints(content, 3),
+			FinancialData: releaseFigures(content),
+		},
+		symbols: tickers,
+	}
+}
+
+// classifyRelease returns the release type and every type whose pattern
+// matched. The title decides when it matches anything; the body is only
+// consulted for uninformative titles.
+func classifyRelease(title, content string) (string, []string) {
+	var matched []string
+	primary := ""
+	for _, text := range []string{title, content} {
+		for _, rp := range releasePatterns {
+			if rp.pattern.MatchString(text) {
+				if primary == "" {
+					primary = rp.releaseType
+				}
+				if !containsString(matched, rp.releaseType) {
+					matched = append(matched, rp.releaseType)
+				}
+			}
+		}
+		if primary != "" {
+			break
+		}
+	}
+	if primary == "" {
+		primary = ReleaseOther
+	}
+	return primary, matched
+}
+
+// keyPoints returns up to limit sentences that carry figures, skipping the dateline.
+func keyPoints(content string, limit int) []string {
+	var points []string
+	for _, sentence := range strings.SplitAfter(dateline.ReplaceAllString(content, ""), ". ") {
+		sentence = strings.TrimSpace(sentence)
+		if len(sentence) < 20 || !strings.ContainsAny(sentence, "0123456789%$") {
+			continue
+		}
+		points = append(points, sentence)
+		if len(points) == limit {
+			break
+		}
+	}
+	return points
+}
+
+// releaseFigures extracts headline financials stated in the release text.
+func releaseFigures(content string) map[string]interface{} {
+	figures := make(map[string]interface{})
+	if match := revenueFigure.FindStringSubmatch(content); match != nil {
+		figures["revenue"] = match[1] + " " + strings.ToLower(match[2])
+	}
+	if match := netIncome.FindStringSubmatch(content); match != nil {
+		figures["net_"+strings.ToLower(match[1])] = match[2] + " " + strings.ToLower(match[3])
+	}
+	if match := epsFigure.FindStringSubmatch(content); match != nil {
+		figures["eps"] = match[1]
+	}
+	return figures
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
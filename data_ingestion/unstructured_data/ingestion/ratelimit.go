@@ -0,0 +1,24 @@
+package ingestion
+
+import "net/http"
+
+// httpSource is a source whose requests go through the rate limiter.
+type httpSource interface {
+	httpClient() *http.Client
+}
+
+func (s *CentralBankSource) httpClient() *http.Client        { return s.client }
+func (s *CourtFilingsSource) httpClient() *http.Client       { return s.client }
+func (s *EarningsTranscriptSource) httpClient() *http.Client { return s.client }
+func (s *EconomicCalendarSource) httpClient() *http.Client   { return s.client }
+func (s *EmployeeReviewsSource) httpClient() *http.Client    { return s.client }
+func (s *FedNewsSource) httpClient() *http.Client            { return s.client }
+func (s *FinnhubSource) httpClient() *http.Client            { return s.client }
+func (s *GDELTSource) httpClient() *http.Client              { return s.client }
+func (s *GoogleTrendsSource) httpClient() *http.Client       { return s.client }
+func (s *IndexMembershipSource) httpClient() *http.Client    { return s.client }
+func (s *NewsAPISource) httpClient() *http.Client            { return s.client }
+func (s *PressReleaseSource) httpClient() *http.Client       { return s.client }
+func (s *RSSSource) httpClient() *http.Client                { return s.client }
+func (s *StockTwitsSource) httpClient() *http.Client         { return s.client }
+func (s *YahooSource) httpClient() *http.Client              { return s.client }
@@ -0,0 +1,221 @@
+package ingestion
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// Factory creates the sources of one kind that cfg enables, none when it
+// leaves them off. Most kinds have one source; RSS and crawled sites have
+// one per configured feed or site. Sources are keyed by GetName.
+type Factory func(store storage.Storage, cfg *config.Config) ([]DataSource, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a kind of source available under name. Sources in other
+// modules register from an init function and are compiled in with a blank
+// import in main; their settings come from cfg.DataSources.Settings[name].
+// Registering a name twice panics.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("ingestion: Register factory is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("ingestion: Register called twice for source " + name)
+	}
+	registry[name] = factory
+}
+
+// Registered returns the names of the registered kinds of source, sorted.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func factory(name string) (Factory, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	f, ok := registry[name]
+	return f, ok
+}
+
+// single adapts the constructor of a source that is enabled by a flag.
+func single(enabled func(*config.Config) bool, create func(storage.Storage, *config.Config) DataSource) Factory {
+	return func(store storage.Storage, cfg *config.Config) ([]DataSource, error) {
+		if !enabled(cfg) {
+			return nil, nil
+		}
+		return []DataSource{create(store, cfg)}, nil
+	}
+}
+
+func init() {
+	Register("finnhub", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.Finnhub.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewFinnhubSource(store, cfg.DataSources.Finnhub)
+		}))
+	Register("yahoo", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.Yahoo.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewYahooSource(store, cfg.DataSources.Yahoo)
+		}))
+	Register("newsapi", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.NewsAPI.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewNewsAPISource(store, cfg.DataSources.NewsAPI)
+		}))
+	Register("fednews", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.FedNews.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewFedNewsSource(store, cfg.DataSources.FedNews)
+		}))
+	Register("centralbanks", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.CentralBanks.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewCentralBankSource(store, cfg.DataSources.CentralBanks)
+		}))
+	Register(economicCalendarSource, single(
+		func(cfg *config.Config) bool { return cfg.DataSources.EconomicCalendar.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewEconomicCalendarSource(store, cfg.DataSources.EconomicCalendar)
+		}))
+	Register(indexMembershipSource, single(
+		func(cfg *config.Config) bool { return cfg.DataSources.IndexMembership.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewIndexMembershipSource(store, cfg.DataSources.IndexMembership)
+		}))
+	Register("stocktwits", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.StockTwits.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewStockTwitsSource(store, cfg.DataSources.StockTwits)
+		}))
+	Register("earnings_transcripts", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.EarningsTranscripts.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewEarningsTranscriptSource(store, cfg.DataSources.EarningsTranscripts)
+		}))
+	Register("press_releases", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.PressReleases.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewPressReleaseSource(store, cfg.DataSources.PressReleases)
+		}))
+	Register("gdelt", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.GDELT.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewGDELTSource(store, cfg.DataSources.GDELT)
+		}))
+	Register("court_filings", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.CourtFilings.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewCourtFilingsSource(store, cfg.DataSources.CourtFilings)
+		}))
+	Register("employee_reviews", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.EmployeeReviews.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewEmployeeReviewsSource(store, cfg.DataSources.EmployeeReviews)
+		}))
+	Register("google_trends", single(
+		func(cfg *config.Config) bool { return cfg.DataSources.GoogleTrends.Enabled },
+		func(store storage.Storage, cfg *config.Config) DataSource {
+			return NewGoogleTrendsSource(store, cfg.DataSources.GoogleTrends)
+		}))
+	Register("rss", func(store storage.Storage, cfg *config.Config) ([]DataSource, error) {
+		var sources []DataSource
+		for _, feed := range cfg.DataSources.RSSFeeds {
+			if feed.Enabled {
+				sources = append(sources, NewRSSSource(store, feed))
+			}
+		}
+		return sources, nil
+	})
+	Register("crawl", func(store storage.Storage, cfg *config.Config) ([]DataSource, error) {
+		var sources []DataSource
+		for _, site := range cfg.DataSources.CrawlSites {
+			if site.Enabled {
+				sources = append(sources, NewCrawlSource(store, site, cfg.DataSources.Crawler))
+			}
+		}
+		return sources, nil
+	})
+}
+
+// selectedKinds returns the registered kinds of source cfg selects.
+func selectedKinds(cfg *config.Config) []string {
+	if len(cfg.DataSources.Only) > 0 {
+		return cfg.DataSources.Only
+	}
+	return Registered()
+}
+
+// createSources runs the factories of the registered kinds cfg selects.
+func createSources(store storage.Storage, cfg *config.Config) (map[string]*runningSource, error) {
+	sources := make(map[string]*runningSource)
+	var errs []error
+	for _, kind := range selectedKinds(cfg) {
+		create, ok := factory(kind)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown source %q", kind))
+			continue
+		}
+		created, err := create(store, cfg)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to create %s sources: %w", kind, err))
+			continue
+		}
+		for _, source := range created {
+			name := source.GetName()
+			if _, dup := sources[name]; dup {
+				errs = append(errs, fmt.Errorf("source %q created twice, by %s", name, kind))
+				continue
+			}
+			sources[name] = &runningSource{kind: kind, source: source}
+		}
+	}
+	return sources, errors.Join(errs...)
+}
+
+// createSource creates the named source from cfg with the factory of kind,
+// or, when kind is empty, of whichever selected kind creates it. It returns
+// nil when cfg leaves the source off.
+func createSource(store storage.Storage, cfg *config.Config, name, kind string) (DataSource, string, error) {
+	kinds := selectedKinds(cfg)
+	if kind != "" {
+		kinds = []string{kind}
+	}
+	for _, k := range kinds {
+		create, ok := factory(k)
+		if !ok {
+			continue
+		}
+		created, err := create(store, cfg)
+		if err != nil {
+			if kind != "" {
+				return nil, kind, fmt.Errorf("failed to create %s sources: %w", kind, err)
+			}
+			continue
+		}
+		for _, source := range created {
+			if source.GetName() == name {
+				return source, k, nil
+			}
+		}
+	}
+	return nil, kind, nil
+}
@@ -0,0 +1,91 @@
+package ingestion
+
+import (
+	"log"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// watchConfig reloads the configuration whenever the config file changes
+// and applies the source settings that can change at runtime.
+func (m *Manager) watchConfig() {
+	defer m.wg.Done()
+
+	path := m.config.Reload.File
+	loaded := m.config
+	modified := modTime(path)
+
+	ticker := time.NewTicker(m.config.Reload.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		at := modTime(path)
+		if at.Equal(modified) {
+			continue
+		}
+		modified = at
+
+		next, err := config.Load()
+		if err != nil {
+			log.Printf("Ignoring changed config file: %v", err)
+			continue
+		}
+		m.applyConfig(loaded, next)
+		loaded = next
+	}
+}
+
+// applyConfig restarts, starts or stops the sources whose settings differ
+// between the loaded configuration and next. Settings changed through the
+// admin API since are kept for the sources next leaves alone.
+func (m *Manager) applyConfig(loaded, next *config.Config) {
+	names := loaded.Retuned(next)
+	if !reflect.DeepEqual(loaded.Retune(next, names), next) {
+		log.Printf("Config file changes other than source symbols, keywords, intervals, schedules and enabled flags apply after a restart")
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	m.sourcesMu.Lock()
+	defer m.sourcesMu.Unlock()
+	if m.ctx.Err() != nil {
+		return
+	}
+	m.sourceConfig = m.sourceConfig.Retune(next, names)
+
+	for _, name := range names {
+		run, running := m.sources[name]
+		running = running && run.cancel != nil
+		enabled, _ := m.sourceConfig.Enabled(name)
+		m.health.reset(name)
+		switch {
+		case enabled:
+			log.Printf("Applying reloaded settings of source %s", name)
+			if err := m.replaceSource(name); err != nil {
+				log.Printf("Failed to apply reloaded settings of source %s: %v", name, err)
+			}
+		case running:
+			m.stopSource(name, run)
+		}
+	}
+}
+
+// modTime returns when the file at path was last modified, zero if it
+// cannot be read.
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,256 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/entities"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+type RSSFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title       string    `xml:"title"`
+	Description string    `xml:"description"`
+	Link        string    `xml:"link"`
+	Items       []RSSItem `xml:"item"`
+}
+
+type RSSItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate"`
+	GUID        string        `xml:"guid"`
+	Category    []string      `xml:"category"`
+	Author      string        `xml:"author"`
+	Source      RSSItemSource `xml:"source"`
+}
+
+// RSSItemSource is the <source> element naming the publication an item came from.
+type RSSItemSource struct {
+	URL  string `xml:"url,attr"`
+	Text string `xml:",chardata"`
+}
+
+// RSSSource ingests the news items of one feed definition from config; every
+// plain news feed runs as its own RSSSource under the feed's name.
+type RSSSource struct {
+	storage storage.Storage
+	config  config.RSSFeedConfig
+	client  *http.Client
+	enabled bool
+}
+
+func NewRSSSource(store storage.Storage, cfg config.RSSFeedConfig) *RSSSource {
+	return &RSSSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+	}
+}
+
+func (r *RSSSource) Start(ctx context.Context) error {
+	if !r.enabled {
+		log.Printf("RSS feed %s is disabled", r.config.Name)
+		return nil
+	}
+
+	log.Printf("Starting RSS data source %s (%d feeds)...", r.config.Name, len(r.config.URLs))
+	go supervise(ctx, r.GetName(), r.ingestData)
+	return nil
+}
+
+func (r *RSSSource) Stop(ctx context.Context) error {
+	log.Printf("Stopping RSS source %s...", r.config.Name)
+	return nil
+}
+
+func (r *RSSSource) GetName() string {
+	return r.config.Name
+}
+
+func (r *RSSSource) IsEnabled() bool {
+	return r.enabled
+}
+
+func (r *RSSSource) ingestData(ctx context.Context) {
+	r.fetchAll(ctx)
+
+	ticker := newPollTicker(r.config.UpdateInterval, r.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.fetchAll(ctx)
+		}
+	}
+}
+
+func (r *RSSSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, r.GetName())
+	defer poll.End()
+	for _, feedURL := range r.config.URLs {
+		if err := r.fetchFeed(ctx, feedURL); err != nil {
+			log.Printf("Error fetching %s RSS from %s: %v", r.config.Name, feedURL, err)
+		}
+	}
+}
+
+func (r *RSSSource) fetchFeed(ctx context.Context, feedURL string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch RSS feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("RSS feed returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read RSS feed: %w", err)
+	}
+	items, err := decodeFeedItems(body)
+	if err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode RSS feed: %w", err))
+	}
+
+	itemCount := 0
+	for _, item := range items {
+		record := r.buildRecord(item)
+		existing, err := r.storage.GetUnstructuredData(ctx, record.ID)
+		seen := err == nil && existing != nil
+		if seen {
+			keepExtractedText(record, existing)
+		}
+		if err := r.storage.SaveUnstructuredData(ctx, record); err != nil {
+			log.Printf("Error saving %s RSS item %s: %v", r.config.Name, item.Link, err)
+			continue
+		}
+		if r.config.ExtractFullText && !seen {
+			enqueueTextExtraction(ctx, r.storage, record)
+		}
+		itemCount++
+	}
+
+	log.Printf("Processed %d %s RSS items", itemCount, r.config.Name)
+	return nil
+}
+
+func (r *RSSSource) buildRecord(item RSSItem) *models.UnstructuredData {
+	identifier := item.GUID
+	if identifier == "" {
+		identifier = item.Link
+	}
+	hash := md5.Sum([]byte(identifier))
+
+	pubDate, err := parseFeedDate(item.PubDate)
+	if err != nil {
+		pubDate = time.Now()
+	}
+
+	source := r.config.Source
+	if source == "" {
+		source = r.config.Name
+	}
+	title := extraction.CleanText(item.Title)
+	content := extraction.CleanText(item.Description)
+	found := entities.Extract(title + " " + content)
+
+	return &models.UnstructuredData{
+		ID:          fmt.Sprintf("%s-%x", r.config.Name, hash[:8]),
+		Source:      source,
+		Type:        "news",
+		Title:       title,
+		Content:     content,
+		URL:         strings.TrimSpace(item.Link),
+		Author:      r.author(item),
+		PublishedAt: pubDate,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"guid":       item.GUID,
+			"feed":       r.config.Name,
+			"categories": item.Category,
+			"symbols":    entities.Symbols(found),
+			"rss_source": item.Source.Text,
+		},
+		Tags:     r.generateTags(item),
+		Entities: found,
+	}
+}
+
+// author prefers the item's byline, then the publication it credits, then
+// the feed's configured author.
+func (r *RSSSource) author(item RSSItem) string {
+	if item.Author != "" {
+		return item.Author
+	}
+	if item.Source.Text != "" {
+		return item.Source.Text
+	}
+	return r.config.Author
+}
+
+// generateTags returns the feed's configured tags followed by the item's
+// categories and keyword-derived topic tags.
+func (r *RSSSource) generateTags(item RSSItem) []string {
+	tags := append(append([]string{}, r.config.Tags...), "rss")
+
+	for _, category := range item.Category {
+		if category != "" {
+			tags = append(tags, strings.ToLower(strings.ReplaceAll(category, " ", "_")))
+		}
+	}
+
+	content := strings.ToLower(item.Title + " " + item.Description)
+
+	if strings.Contains(content, "stock") || strings.Contains(content, "share") {
+		tags = append(tags, "stock_market")
+	}
+
+	if strings.Contains(content, "earnings") || strings.Contains(content, "profit") {
+		tags = append(tags, "earnings")
+	}
+
+	if strings.Contains(content, "merger") || strings.Contains(content, "acquisition") {
+		tags = append(tags, "m_and_a")
+	}
+
+	if strings.Contains(content, "debt") || strings.Contains(content, "credit") || strings.Contains(content, "rating") {
+		tags = append(tags, "credit_rating")
+	}
+
+	if !containsString(tags, "monetary_policy") && (strings.Contains(content, "federal reserve") || strings.Contains(content, "fed") || strings.Contains(content, "interest rate")) {
+		tags = append(tags, "monetary_policy")
+	}
+
+	return tags
+}
@@ -0,0 +1,280 @@
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// StockTwitsStreamResponse is the symbol stream returned by /streams/symbol.
+type StockTwitsStreamResponse struct {
+	Response struct {
+		Status int `json:"status"`
+	} `json:"response"`
+	Cursor struct {
+		More  bool  `json:"more"`
+		Since int64 `json:"since"`
+		Max   int64 `json:"max"`
+	} `json:"cursor"`
+	Messages []StockTwitsMessage `json:"messages"`
+}
+
+type StockTwitsMessage struct {
+	ID        int64  `json:"id"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+	User      struct {
+		ID        int64  `json:"id"`
+		Username  string `json:"username"`
+		Name      string `json:"name"`
+		Followers int64  `json:"followers"`
+		Official  bool   `json:"official"`
+	} `json:"user"`
+	Symbols []struct {
+		Symbol string `json:"symbol"`
+	} `json:"symbols"`
+	Entities struct {
+		// Sentiment is null unless the author tagged the message
+		Sentiment *struct {
+			Basic string `json:"basic"` // Bullish or Bearish
+		} `json:"sentiment"`
+	} `json:"entities"`
+	Likes struct {
+		Total int64 `json:"total"`
+	} `json:"likes"`
+	Conversation struct {
+		Replies int64 `json:"replies"`
+	} `json:"conversation"`
+	Reshares struct {
+		ResharedCount int64 `json:"reshared_count"`
+	} `json:"reshares"`
+}
+
+type StockTwitsSource struct {
+	storage storage.Storage
+	config  config.StockTwitsConfig
+	client  *http.Client
+	enabled bool
+
+	mu    sync.Mutex
+	since map[string]int64 // newest message ID seen per symbol
+}
+
+func NewStockTwitsSource(store storage.Storage, cfg config.StockTwitsConfig) *StockTwitsSource {
+	return &StockTwitsSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+		enabled: cfg.Enabled,
+		since:   make(map[string]int64),
+	}
+}
+
+func (s *StockTwitsSource) Start(ctx context.Context) error {
+	if !s.enabled {
+		log.Println("StockTwits source is disabled")
+		return nil
+	}
+
+	log.Printf("Starting StockTwits data source (%d symbols)...", len(s.config.Symbols))
+	go supervise(ctx, s.GetName(), s.ingestData)
+	return nil
+}
+
+func (s *StockTwitsSource) Stop(ctx context.Context) error {
+	log.Println("Stopping StockTwits source...")
+	return nil
+}
+
+func (s *StockTwitsSource) GetName() string {
+	return "stocktwits"
+}
+
+func (s *StockTwitsSource) IsEnabled() bool {
+	return s.enabled
+}
+
+func (s *StockTwitsSource) ingestData(ctx context.Context) {
+	s.fetchAll(ctx)
+
+	ticker := newPollTicker(s.config.UpdateInterval, s.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.fetchAll(ctx)
+		}
+	}
+}
+
+func (s *StockTwitsSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, s.GetName())
+	defer poll.End()
+	for _, symbol := range s.config.Symbols {
+		if err := s.fetchStream(ctx, symbol); err != nil {
+			log.Printf("Error fetching StockTwits stream for %s: %v", symbol, err)
+		}
+
+		// Unauthenticated clients get 200 requests an hour
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// fetchStream stores the messages posted about symbol since the last poll.
+func (s *StockTwitsSource) fetchStream(ctx context.Context, symbol string) error {
+	ticker, ok, err := symbols.To(symbol, symbols.StockTwits)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("symbol %s has no StockTwits notation", symbol)
+	}
+
+	params := url.Values{}
+	s.mu.Lock()
+	since := s.since[ticker]
+	s.mu.Unlock()
+	if since > 0 {
+		params.Set("since", fmt.Sprint(since))
+	}
+	if s.config.AccessToken != "" {
+		params.Set("access_token", s.config.AccessToken)
+	}
+	streamURL := fmt.Sprintf("%s/streams/symbol/%s.json", s.config.BaseURL, url.PathEscape(ticker))
+	if len(params) > 0 {
+		streamURL += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", streamURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("StockTwits API returned status %d", resp.StatusCode)
+	}
+
+	var stream StockTwitsStreamResponse
+	if err := json.NewDecoder(resp.Body).Decode(&stream); err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode stream: %w", err))
+	}
+
+	newest := since
+	for _, message := range stream.Messages {
+		if err := s.processMessage(ctx, symbol, message); err != nil {
+			log.Printf("Error processing StockTwits message %d: %v", message.ID, err)
+		}
+		if message.ID > newest {
+			newest = message.ID
+		}
+	}
+
+	s.mu.Lock()
+	s.since[ticker] = newest
+	s.mu.Unlock()
+
+	log.Printf("Processed %d StockTwits messages for %s", len(stream.Messages), symbol)
+	return nil
+}
+
+func (s *StockTwitsSource) processMessage(ctx context.Context, symbol string, message StockTwitsMessage) error {
+	publishedAt, err := time.Parse(time.RFC3339, message.CreatedAt)
+	if err != nil {
+		publishedAt = time.Now()
+	}
+
+	mentioned := make([]string, 0, len(message.Symbols))
+	for _, sym := range message.Symbols {
+		if normalized, err := symbols.From(sym.Symbol, symbols.StockTwits); err == nil {
+			mentioned = append(mentioned, normalized)
+		}
+	}
+
+	tags := []string{"stocktwits", "social", strings.ToLower(symbol)}
+	label := ""
+	if message.Entities.Sentiment != nil {
+		label = strings.ToLower(message.Entities.Sentiment.Basic)
+		if label != "" {
+			tags = append(tags, label)
+		}
+	}
+
+	data := &models.UnstructuredData{
+		ID:          fmt.Sprintf("stocktwits-%d", message.ID),
+		Source:      "stocktwits",
+		Type:        "social",
+		Title:       fmt.Sprintf("$%s message by @%s", symbol, message.User.Username),
+		Content:     extraction.CleanText(message.Body),
+		URL:         fmt.Sprintf("https://stocktwits.com/%s/message/%d", message.User.Username, message.ID),
+		Author:      message.User.Username,
+		PublishedAt: publishedAt,
+		IngestedAt:  time.Now(),
+		Metadata: map[string]interface{}{
+			"stocktwits_id":   message.ID,
+			"platform":        "stocktwits",
+			"symbol":          symbol,
+			"symbols":         mentioned,
+			"user_followers":  message.User.Followers,
+			"user_official":   message.User.Official,
+			"likes":           message.Likes.Total,
+			"replies":         message.Conversation.Replies,
+			"reshares":        message.Reshares.ResharedCount,
+			"sentiment_label": label,
+		},
+		Tags:      tags,
+		Sentiment: labeledSentiment(label),
+	}
+
+	return s.storage.SaveUnstructuredData(ctx, data)
+}
+
+// labeledSentiment converts the author's own bullish/bearish tag into a
+// sentiment score. Untagged messages get none, leaving them to the
+// sentiment analysis job instead of guessing from their wording.
+func labeledSentiment(label string) *models.SentimentScore {
+	switch label {
+	case "bullish":
+		return &models.SentimentScore{
+			Overall:   1,
+			Positive:  1,
+			Magnitude: 1,
+			Aspects:   map[string]float64{"user_labeled": 1},
+		}
+	case "bearish":
+		return &models.SentimentScore{
+			Overall:   -1,
+			Negative:  1,
+			Magnitude: 1,
+			Aspects:   map[string]float64{"user_labeled": 1},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package ingestion
+
+import "time"
+
+// streamSource is a source holding a connection open that data is pushed
+// over, rather than polling.
+type streamSource interface {
+	streamStats() StreamStats
+}
+
+// StreamStats describes the connection of a streaming source: whether it is
+// up, how often it had to be made again and why.
+type StreamStats struct {
+	Connected     bool       `json:"connected"`
+	ConnectedAt   *time.Time `json:"connected_at,omitempty"`
+	LastMessageAt *time.Time `json:"last_message_at,omitempty"`
+	Subscriptions int        `json:"subscriptions"`
+	Connects      int        `json:"connects"`
+	Reconnects    int        `json:"reconnects"`
+	DialFailures  int        `json:"dial_failures"`
+	StaleTimeouts int        `json:"stale_timeouts"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// streamState keeps the counts behind StreamStats; its owner guards it.
+type streamState struct {
+	connected     bool
+	connectedAt   time.Time
+	lastMessage   time.Time
+	subscriptions int
+	connects      int
+	dialFailures  int
+	staleTimeouts int
+	lastError     string
+	lastErrorAt   time.Time
+	nextAttempt   time.Time
+}
+
+func (s *streamState) stats() StreamStats {
+	stats := StreamStats{
+		Connected:     s.connected,
+		ConnectedAt:   optionalTime(s.connectedAt),
+		LastMessageAt: optionalTime(s.lastMessage),
+		Subscriptions: s.subscriptions,
+		Connects:      s.connects,
+		Reconnects:    max(s.connects-1, 0),
+		DialFailures:  s.dialFailures,
+		StaleTimeouts: s.staleTimeouts,
+		LastError:     s.lastError,
+		LastErrorAt:   optionalTime(s.lastErrorAt),
+	}
+	if !s.connected {
+		stats.NextAttemptAt = optionalTime(s.nextAttempt)
+	}
+	return stats
+}
+
+// optionalTime returns nil for the zero time, which JSON then leaves out.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
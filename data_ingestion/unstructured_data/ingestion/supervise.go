@@ -0,0 +1,41 @@
+package ingestion
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+)
+
+// panicRestartDelay is how long a source loop waits before restarting after a panic.
+const panicRestartDelay = 30 * time.Second
+
+// supervise runs a source loop, reporting and restarting it if it panics, so a
+// single malformed upstream payload cannot take the source down for good.
+func supervise(ctx context.Context, name string, loop func(ctx context.Context)) {
+	for {
+		if !runRecovered(ctx, name, loop) {
+			return
+		}
+
+		log.Printf("Restarting %s loop in %v after panic", name, panicRestartDelay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(panicRestartDelay):
+		}
+	}
+}
+
+// runRecovered runs loop once and reports whether it exited by panicking.
+func runRecovered(ctx context.Context, name string, loop func(ctx context.Context)) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			reporting.CapturePanic(r, map[string]string{"component": "source", "source": name})
+			panicked = true
+		}
+	}()
+	loop(ctx)
+	return false
+}
@@ -0,0 +1,411 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// transcriptJobs are the enrichment jobs queued for every new transcript.
+var transcriptJobs = []string{"sentiment_analysis", "credit_event_classification", "summarization"}
+
+// transcriptWordsPerMinute is the speaking pace used to estimate segment
+// times, which transcripts do not carry.
+const transcriptWordsPerMinute = 150
+
+// speakerLine matches the "Speaker: text" turns transcripts are written in.
+// The label may carry a title and firm, as in "Jane Doe -- Acme Bank -- Analyst".
+var speakerLine = regexp.MustCompile(`^([A-Z][\p{L}0-9 .,'&()\-]{0,120}?):\s+(.*)$`)
+
+// transcriptDoc is one call transcript as served by FMP and by "url" providers.
+type transcriptDoc struct {
+	Symbol  string `json:"symbol"`
+	Company string `json:"company"`
+	Quarter int    `json:"quarter"`
+	Year    int    `json:"year"`
+	Date    string `json:"date"` // "2006-01-02 15:04:05", Eastern time
+	Content string `json:"content"`
+	URL     string `json:"url"`
+}
+
+type EarningsTranscriptSource struct {
+	storage storage.Storage
+	config  config.EarningsTranscriptsConfig
+	client  *http.Client
+	enabled bool
+
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+func NewEarningsTranscriptSource(store storage.Storage, cfg config.EarningsTranscriptsConfig) *EarningsTranscriptSource {
+	return &EarningsTranscriptSource{
+		storage: store,
+		config:  cfg,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+		enabled: cfg.Enabled && (cfg.Provider != "fmp" || cfg.APIKey != ""),
+		seen:    make(map[string]bool),
+	}
+}
+
+func (e *EarningsTranscriptSource) Start(ctx context.Context) error {
+	if !e.enabled {
+		log.Println("Earnings transcript source is disabled")
+		return nil
+	}
+
+	log.Printf("Starting earnings transcript data source (provider: %s, %d symbols)...", e.config.Provider, len(e.config.Symbols))
+	go supervise(ctx, e.GetName(), e.ingestData)
+	return nil
+}
+
+func (e *EarningsTranscriptSource) Stop(ctx context.Context) error {
+	log.Println("Stopping earnings transcript source...")
+	return nil
+}
+
+func (e *EarningsTranscriptSource) GetName() string {
+	return "earnings_transcripts"
+}
+
+func (e *EarningsTranscriptSource) IsEnabled() bool {
+	return e.enabled
+}
+
+func (e *EarningsTranscriptSource) ingestData(ctx context.Context) {
+	e.fetchAll(ctx)
+
+	ticker := newPollTicker(e.config.UpdateInterval, e.config.Schedule)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.fetchAll(ctx)
+		}
+	}
+}
+
+func (e *EarningsTranscriptSource) fetchAll(ctx context.Context) {
+	ctx, poll := startPoll(ctx, e.GetName())
+	defer poll.End()
+	for _, symbol := range e.config.Symbols {
+		if err := e.fetchSymbol(ctx, symbol); err != nil {
+			log.Printf("Error fetching earnings transcripts for %s: %v", symbol, err)
+		}
+	}
+}
+
+func (e *EarningsTranscriptSource) fetchSymbol(ctx context.Context, symbol string) error {
+	var docs []transcriptDoc
+	var err error
+	switch e.config.Provider {
+	case "fmp":
+		docs, err = e.fetchFMP(ctx, symbol)
+	case "url":
+		err = e.getJSON(ctx, e.config.BaseURL+"?"+url.Values{"symbol": {symbol}}.Encode(), &docs)
+	default:
+		return fmt.Errorf("unknown earnings transcript provider %q", e.config.Provider)
+	}
+	if err != nil {
+		return err
+	}
+
+	stored := 0
+	for _, doc := range docs {
+		if doc.Content == "" {
+			continue
+		}
+		if doc.Symbol == "" {
+			doc.Symbol = symbol
+		}
+		id := transcriptID(doc)
+		if e.known(ctx, id) {
+			continue
+		}
+		if err := e.saveTranscript(ctx, id, doc); err != nil {
+			log.Printf("Error saving transcript %s: %v", id, err)
+			continue
+		}
+		stored++
+	}
+
+	if stored > 0 {
+		log.Printf("Stored %d new earnings transcripts for %s", stored, symbol)
+	}
+	return nil
+}
+
+// fetchFMP lists the calls FMP has transcribed for symbol and downloads the
+// most recent MaxPerSymbol of them.
+func (e *EarningsTranscriptSource) fetchFMP(ctx context.Context, symbol string) ([]transcriptDoc, error) {
+	var available [][]interface{} // [quarter, year, date]
+	listURL := fmt.Sprintf("%s/v4/earning_call_transcript?%s", e.config.BaseURL,
+		url.Values{"symbol": {symbol}, "apikey": {e.config.APIKey}}.Encode())
+	if err := e.getJSON(ctx, listURL, &available); err != nil {
+		return nil, err
+	}
+
+	type call struct{ quarter, year int }
+	var calls []call
+	for _, entry := range available {
+		if len(entry) < 2 {
+			continue
+		}
+		quarter, qok := entry[0].(float64)
+		year, yok := entry[1].(float64)
+		if qok && yok {
+			calls = append(calls, call{int(quarter), int(year)})
+		}
+	}
+	sort.Slice(calls, func(i, j int) bool {
+		if calls[i].year != calls[j].year {
+			return calls[i].year > calls[j].year
+		}
+		return calls[i].quarter > calls[j].quarter
+	})
+
+	var docs []transcriptDoc
+	for i, c := range calls {
+		if i == e.config.MaxPerSymbol {
+			break
+		}
+		if e.known(ctx, transcriptID(transcriptDoc{Symbol: symbol, Quarter: c.quarter, Year: c.year})) {
+			continue
+		}
+
+		var fetched []transcriptDoc
+		callURL := fmt.Sprintf("%s/v3/earning_call_transcript/%s?%s", e.config.BaseURL, url.PathEscape(symbol),
+			url.Values{"quarter": {fmt.Sprint(c.quarter)}, "year": {fmt.Sprint(c.year)}, "apikey": {e.config.APIKey}}.Encode())
+		if err := e.getJSON(ctx, callURL, &fetched); err != nil {
+			return docs, err
+		}
+		docs = append(docs, fetched...)
+	}
+	return docs, nil
+}
+
+func (e *EarningsTranscriptSource) getJSON(ctx context.Context, requestURL string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "CredTech-DataIngestion/1.0")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch transcripts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("transcript provider returned status %d", resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return parseError(ctx, fmt.Errorf("failed to decode transcripts: %w", err))
+	}
+	return nil
+}
+
+// known reports whether a transcript was already stored, by this process or
+// an earlier one.
+func (e *EarningsTranscriptSource) known(ctx context.Context, id string) bool {
+	e.mu.Lock()
+	seen := e.seen[id]
+	e.mu.Unlock()
+	if seen {
+		return true
+	}
+	if existing, err := e.storage.GetUnstructuredData(ctx, id); err == nil && existing != nil {
+		e.markSeen(id)
+		return true
+	}
+	return false
+}
+
+func (e *EarningsTranscriptSource) markSeen(id string) {
+	e.mu.Lock()
+	e.seen[id] = true
+	e.mu.Unlock()
+}
+
+func (e *EarningsTranscriptSource) saveTranscript(ctx context.Context, id string, doc transcriptDoc) error {
+	transcript := buildTranscript(id, doc)
+
+	data := transcript.UnstructuredData
+	data.Metadata = map[string]interface{}{
+		"company":       transcript.Company,
+		"symbol":        transcript.Symbol,
+		"symbols":       []string{transcript.Symbol},
+		"quarter":       transcript.Quarter,
+		"year":          transcript.Year,
+		"call_date":     transcript.CallDate,
+		"speakers":      transcript.Speakers,
+		"speaker_count": len(transcript.Speakers),
+		"provider":      e.config.Provider,
+		"segment_times": "estimated",
+	}
+	if err := e.storage.SaveUnstructuredData(ctx, &data); err != nil {
+		return err
+	}
+	e.markSeen(id)
+	e.enqueueJobs(ctx, id)
+	return nil
+}
+
+func (e *EarningsTranscriptSource) enqueueJobs(ctx context.Context, dataID string) {
+	for _, jobType := range transcriptJobs {
+		hash := md5.Sum([]byte(dataID + jobType))
+		job := &models.ProcessingJob{
+			ID:        fmt.Sprintf("job-%x", hash[:8]),
+			DataID:    dataID,
+			JobType:   jobType,
+			Status:    "pending",
+			CreatedAt: time.Now(),
+		}
+		if err := e.storage.SaveProcessingJob(ctx, job); err != nil {
+			log.Printf("Failed to queue %s for %s: %v", jobType, dataID, err)
+		}
+	}
+}
+
+// transcriptID identifies a call by symbol and fiscal quarter so that
+// re-published transcripts update the same record.
+func transcriptID(doc transcriptDoc) string {
+	return fmt.Sprintf("transcript-%s-%dq%d", strings.ToLower(doc.Symbol), doc.Year, doc.Quarter)
+}
+
+// buildTranscript splits a transcript into speaker turns. Segment times are
+// estimated from the call start and a typical speaking pace.
+func buildTranscript(id string, doc transcriptDoc) *models.EarningsTranscript {
+	callDate := parseCallDate(doc.Date)
+	company := doc.Company
+	if company == "" {
+		company = doc.Symbol
+	}
+
+	var speakers []models.Speaker
+	index := make(map[string]int) // speaker name -> position in speakers
+	at := callDate
+	for _, line := range strings.Split(doc.Content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		match := speakerLine.FindStringSubmatch(line)
+		if match == nil {
+			// Continuation of the previous turn
+			if len(speakers) > 0 {
+				last := &speakers[len(speakers)-1]
+				if n := len(last.Segments); n > 0 {
+					segment := &last.Segments[n-1]
+					segment.Text += "\n" + line
+					segment.EndTime = segment.EndTime.Add(speakingTime(line))
+					at = segment.EndTime
+				}
+			}
+			continue
+		}
+
+		name, title, firm := parseSpeakerLabel(match[1], company)
+		i, ok := index[name]
+		if !ok {
+			i = len(speakers)
+			index[name] = i
+			speakers = append(speakers, models.Speaker{Name: name, Title: title, Company: firm})
+		}
+		end := at.Add(speakingTime(match[2]))
+		speakers[i].Segments = append(speakers[i].Segments, models.TranscriptSegment{
+			Speaker:   name,
+			StartTime: at,
+			EndTime:   end,
+			Text:      match[2],
+		})
+		at = end
+	}
+
+	period := fmt.Sprintf("Q%d %d", doc.Quarter, doc.Year)
+	return &models.EarningsTranscript{
+		UnstructuredData: models.UnstructuredData{
+			ID:          id,
+			Source:      "earnings_transcripts",
+			Type:        "earnings_transcript",
+			Title:       fmt.Sprintf("%s %s Earnings Call Transcript", company, period),
+			Content:     doc.Content,
+			URL:         doc.URL,
+			Author:      company,
+			PublishedAt: callDate,
+			IngestedAt:  time.Now(),
+			Tags:        []string{"earnings_transcript", "earnings", strings.ToLower(doc.Symbol), strings.ToLower(strings.ReplaceAll(period, " ", "_"))},
+		},
+		Company:    company,
+		Symbol:     doc.Symbol,
+		Quarter:    fmt.Sprintf("Q%d", doc.Quarter),
+		Year:       doc.Year,
+		CallDate:   callDate,
+		Speakers:   speakers,
+		Transcript: doc.Content,
+	}
+}
+
+// parseSpeakerLabel splits labels such as "Jane Doe -- Chief Financial Officer"
+// or "John Roe -- Acme Bank -- Analyst". Speakers without a firm are taken to
+// work for the issuer, except the operator.
+func parseSpeakerLabel(label, company string) (name, title, firm string) {
+	parts := strings.Split(label, " -- ")
+	if len(parts) == 1 {
+		parts = strings.Split(label, " - ")
+	}
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	name = parts[0]
+	switch len(parts) {
+	case 1:
+	case 2:
+		title = parts[1]
+	default:
+		firm, title = parts[1], parts[len(parts)-1]
+	}
+	if firm == "" && !strings.EqualFold(name, "operator") {
+		firm = company
+	}
+	return name, title, firm
+}
+
+func speakingTime(text string) time.Duration {
+	words := len(strings.Fields(text))
+	return time.Duration(words) * time.Minute / transcriptWordsPerMinute
+}
+
+// parseCallDate reads provider dates, which are US Eastern wall-clock times.
+func parseCallDate(value string) time.Time {
+	eastern, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		eastern = time.UTC
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), eastern); err == nil {
+			return t
+		}
+	}
+	return time.Now()
+}
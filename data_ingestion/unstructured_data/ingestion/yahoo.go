@@ -8,11 +8,12 @@ import (
 	"log"
 	"net/http"
 	"net/url"
-	"regexp"
 	"strings"
 	"time"
 
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/entities"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/extraction"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
 	"github.com/google/uuid"
@@ -94,10 +95,10 @@ func (y *YahooSource) Start(ctx context.Context) error {
 	log.Println("Starting Yahoo Finance data source...")
 
 	// Start news ingestion
-	go y.ingestNews(ctx)
+	go supervise(ctx, "yahoo-news", y.ingestNews)
 
 	// Start financial data ingestion
-	go y.ingestFinancialData(ctx)
+	go supervise(ctx, "yahoo-financial", y.ingestFinancialData)
 
 	return nil
 }
@@ -121,45 +122,33 @@ func (y *YahooSource) ingestNews(ctx context.Context) {
 		log.Printf("Error in initial Yahoo news fetch: %v", err)
 	}
 
-	ticker := time.NewTicker(y.config.UpdateInterval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if err := y.fetchNews(ctx); err != nil {
-				log.Printf("Error fetching Yahoo news: %v", err)
-			}
+	schedule := newMarketSchedule(y.config.Symbols, y.config.UpdateInterval, y.config.ClosedInterval).withCron(y.config.Schedule)
+	schedule.run(ctx, "Yahoo news", func(ctx context.Context) {
+		if err := y.fetchNews(ctx); err != nil {
+			log.Printf("Error fetching Yahoo news: %v", err)
 		}
-	}
+	})
 }
 
+// ingestFinancialData polls quotes while the markets are trading; prices
+// cannot move while they are shut, so polling waits for the next open.
 func (y *YahooSource) ingestFinancialData(ctx context.Context) {
-	ticker := time.NewTicker(y.config.UpdateInterval * 2)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			if err := y.fetchFinancialData(ctx); err != nil {
-				log.Printf("Error fetching Yahoo financial data: %v", err)
-			}
+	schedule := newMarketSchedule(y.config.Symbols, y.config.UpdateInterval*2, 0)
+	schedule.run(ctx, "Yahoo financial data", func(ctx context.Context) {
+		if err := y.fetchFinancialData(ctx); err != nil {
+			log.Printf("Error fetching Yahoo financial data: %v", err)
 		}
-	}
+	})
 }
 
 func (y *YahooSource) fetchNews(ctx context.Context) error {
+	ctx, poll := startPoll(ctx, y.GetName())
+	defer poll.End()
 	
 	for _, symbol := range y.config.Symbols {
 		if err := y.fetchNewsForSymbol(ctx, symbol); err != nil {
 			log.Printf("Error fetching news for symbol %s: %v", symbol, err)
 		}
-		
-		time.Sleep(1 * time.Second)
 	}
 
 	return nil
@@ -188,7 +177,7 @@ func (y *YahooSource) fetchNewsForSymbol(ctx context.Context, symbol string) err
 	}
 	var searchResponse map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&searchResponse); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+		return parseError(ctx, fmt.Errorf("failed to decode response: %w", err))
 	}
 
 	if news, ok := searchResponse["news"].([]interface{}); ok {
@@ -224,6 +213,8 @@ func (y *YahooSource) processYahooNewsItem(ctx context.Context, item map[string]
 	}
 
 	summary, _ := item["summary"].(string)
+	title = extraction.CleanText(title)
+	summary = extraction.CleanText(summary)
 
 	var relatedTickers []string
 	if tickers, ok := item["relatedTickers"].([]interface{}); ok {
@@ -234,7 +225,7 @@ func (y *YahooSource) processYahooNewsItem(ctx context.Context, item map[string]
 		}
 	}
 
-	entities := y.extractEntities(title + " " + summary)
+	found := entities.Extract(title + " " + summary)
 
 	data := &models.UnstructuredData{
 		ID:          dataID,
@@ -252,13 +243,15 @@ func (y *YahooSource) processYahooNewsItem(ctx context.Context, item map[string]
 			"publisher":       publisher,
 		},
 		Tags:     y.generateTags(title, summary, symbol),
-		Entities: entities,
+		Entities: found,
 	}
 
 	return y.storage.SaveUnstructuredData(ctx, data)
 }
 
 func (y *YahooSource) fetchFinancialData(ctx context.Context) error {
+	ctx, poll := startPoll(ctx, y.GetName())
+	defer poll.End()
 	
 	symbolsStr := strings.Join(y.config.Symbols, ",")
 	
@@ -284,7 +277,7 @@ func (y *YahooSource) fetchFinancialData(ctx context.Context) error {
 
 	var quoteResponse YahooQuoteResponse
 	if err := json.NewDecoder(resp.Body).Decode(&quoteResponse); err != nil {
-		return fmt.Errorf("failed to decode quote response: %w", err)
+		return parseError(ctx, fmt.Errorf("failed to decode quote response: %w", err))
 	}
 
 	for _, quote := range quoteResponse.QuoteResponse.Result {
@@ -365,38 +358,6 @@ func (y *YahooSource) processFinancialData(ctx context.Context, quote YahooQuote
 	return y.storage.SaveUnstructuredData(ctx, data)
 }
 
-func (y *YahooSource) extractEntities(text string) []models.Entity {
-	var entities []models.Entity
-	symbolRegex := regexp.MustCompile(`\b[A-Z]{1,5}\b`)
-	symbols := symbolRegex.FindAllString(text, -1)
-	
-	for _, symbol := range symbols {
-		if len(symbol) >= 2 && len(symbol) <= 5 {
-			entities = append(entities, models.Entity{
-				Name:       symbol,
-				Type:       "STOCK_SYMBOL",
-				Confidence: 0.8,
-				StartPos:   strings.Index(text, symbol),
-				EndPos:     strings.Index(text, symbol) + len(symbol),
-			})
-		}
-	}
-	moneyRegex := regexp.MustCompile(`\$[\d,]+(?:\.\d{2})?`)
-	amounts := moneyRegex.FindAllString(text, -1)
-	
-	for _, amount := range amounts {
-		entities = append(entities, models.Entity{
-			Name:       amount,
-			Type:       "MONEY",
-			Confidence: 0.9,
-			StartPos:   strings.Index(text, amount),
-			EndPos:     strings.Index(text, amount) + len(amount),
-		})
-	}
-	
-	return entities
-}
-
 func (y *YahooSource) generateTags(title, summary, symbol string) []string {
 	tags := []string{"yahoo_finance", "financial_news", symbol}
 	
@@ -418,14 +379,6 @@ func (y *YahooSource) generateTags(title, summary, symbol string) []string {
 		tags = append(tags, "analyst_rating")
 	}
 	
-	if strings.Contains(content, "beat") || strings.Contains(content, "exceed") || strings.Contains(content, "strong") {
-		tags = append(tags, "positive_sentiment")
-	}
-	
-	if strings.Contains(content, "miss") || strings.Contains(content, "weak") || strings.Contains(content, "decline") {
-		tags = append(tags, "negative_sentiment")
-	}
-	
 	return tags
 }
 
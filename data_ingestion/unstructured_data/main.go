@@ -8,20 +8,123 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/admin"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/api"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/contracts"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/dedup"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/ingestion"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/pii"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/priority"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/publish"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/quota"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/retention"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/rpc"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/search"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/sharing"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/status"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/tracing"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/webhooks"
 )
 
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := reporting.Init(cfg.ErrorReporting); err != nil {
+		log.Printf("Error reporting disabled: %v", err)
+	}
+	if err := tracing.Init(cfg.Tracing); err != nil {
+		log.Printf("Tracing disabled: %v", err)
+	}
+
 	store, err := storage.NewStorage(cfg.Database)
 	if err != nil {
 		log.Fatalf("Failed to initialize storage: %v", err)
 	}
 	defer store.Close()
+	// Next to the storage, so its spans time the storage alone
+	if tracing.Enabled() {
+		store = tracing.WrapStorage(store)
+	}
+	// Counts what sources stored, after every wrapper had its say
+	activity := ingestion.NewActivity()
+	store = activity.Wrap(store)
+
+	// Innermost, so only documents every other wrapper let through are published
+	var publisher *publish.Publisher
+	if cfg.Publish.Backend != "" {
+		publisher, err = publish.Wrap(store, cfg.Publish)
+		if err != nil {
+			log.Fatalf("Failed to set up %s publisher: %v", cfg.Publish.Backend, err)
+		}
+		store = publisher
+	}
+	var notifier *webhooks.Notifier
+	if cfg.Webhooks.Enabled {
+		notifier, err = webhooks.Wrap(store, cfg.Webhooks)
+		if err != nil {
+			log.Fatalf("Failed to set up webhooks: %v", err)
+		}
+		store = notifier
+	}
+	var indexer *search.Indexer
+	if cfg.Search.Enabled {
+		indexer = search.Wrap(store, cfg.Search)
+		store = indexer
+	}
+
+	if cfg.Contracts.Enabled {
+		validator, err := contracts.Load(cfg.Contracts.File)
+		if err != nil {
+			log.Fatalf("Failed to load data contracts: %v", err)
+		}
+		store = contracts.Wrap(store, validator)
+	}
+	if cfg.Quota.Enabled {
+		if err := quota.Validate(cfg.Quota); err != nil {
+			log.Fatalf("Invalid ingestion quota configuration: %v", err)
+		}
+		store = quota.Wrap(store, cfg.Quota)
+	}
+	// Ranked before the quota sees a document, which keeps important ones over the limit
+	if cfg.Priority.Enabled {
+		store = priority.Wrap(store, cfg.Priority)
+	}
+	if cfg.Dedup.Enabled {
+		detector := dedup.Wrap(store, cfg.Dedup)
+		if err := detector.Load(context.Background()); err != nil {
+			log.Printf("Near-duplicate detection starts without history: %v", err)
+		}
+		store = detector
+	}
+	// Repeats from re-polled sources stop here, before any other wrapper sees them
+	var seen *dedup.Seen
+	if cfg.Dedup.Seen.Enabled {
+		seen, err = dedup.WrapSeen(store, cfg.Dedup.Seen)
+		if err != nil {
+			log.Fatalf("Failed to load seen document index: %v", err)
+		}
+		store = seen
+	}
+	// Outermost, so neither the other wrappers nor the storage see personal data
+	var scrubber *pii.Scrubber
+	if cfg.PII.Enabled {
+		scrubber, err = pii.Wrap(store, cfg.PII)
+		if err != nil {
+			log.Fatalf("Failed to set up PII scrubbing: %v", err)
+		}
+		store = scrubber
+	}
 
 	manager := ingestion.NewManager(store, cfg)
+	manager.SetActivity(activity)
+	if publisher != nil {
+		manager.SetJobObserver(publisher)
+	}
 
 	if err := manager.Start(); err != nil {
 		log.Fatalf("Failed to start ingestion manager: %v", err)
@@ -29,6 +132,53 @@ func main() {
 
 	log.Println("Unstructured data ingestion started")
 
+	var purger *retention.Purger
+	if cfg.Retention.Enabled {
+		purger, err = retention.New(store, cfg.Retention)
+		if err != nil {
+			log.Fatalf("Failed to set up retention: %v", err)
+		}
+		purger.Start()
+	}
+
+	var adminServer *admin.Server
+	if cfg.Admin.Enabled {
+		adminServer = admin.NewServer(cfg, store, manager, admin.Options{
+			Webhooks:  notifier,
+			Search:    indexer,
+			Retention: purger,
+			PII:       scrubber,
+			Seen:      seen,
+		})
+		adminServer.Start()
+	}
+
+	var statusServer *status.Server
+	if cfg.Status.Enabled {
+		statusServer = status.NewServer(cfg.Status, store, manager)
+		statusServer.Start()
+	}
+
+	var apiServer *api.Server
+	if cfg.API.Enabled {
+		apiServer = api.NewServer(cfg.API, store, manager)
+		apiServer.Start()
+	}
+
+	var grpcServer *rpc.Server
+	if cfg.GRPC.Enabled {
+		grpcServer = rpc.NewServer(cfg.GRPC, store)
+		if err := grpcServer.Start(); err != nil {
+			log.Fatalf("Failed to start gRPC API: %v", err)
+		}
+	}
+
+	var shareServer *sharing.Server
+	if cfg.Sharing.Enabled {
+		shareServer = sharing.NewServer(store, cfg.Sharing)
+		shareServer.Start()
+	}
+
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	<-sigChan
@@ -36,9 +186,64 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if shareServer != nil {
+		if err := shareServer.Stop(ctx); err != nil {
+			log.Printf("Error stopping sharing API: %v", err)
+		}
+	}
+	if adminServer != nil {
+		if err := adminServer.Stop(ctx); err != nil {
+			log.Printf("Error stopping admin API: %v", err)
+		}
+	}
+	if statusServer != nil {
+		if err := statusServer.Stop(ctx); err != nil {
+			log.Printf("Error stopping status API: %v", err)
+		}
+	}
+	if apiServer != nil {
+		if err := apiServer.Stop(ctx); err != nil {
+			log.Printf("Error stopping data API: %v", err)
+		}
+	}
+	if grpcServer != nil {
+		if err := grpcServer.Stop(ctx); err != nil {
+			log.Printf("Error stopping gRPC API: %v", err)
+		}
+	}
+
 	if err := manager.Stop(ctx); err != nil {
 		log.Printf("Error during shutdown: %v", err)
 	}
+	if publisher != nil {
+		if err := publisher.Shutdown(ctx); err != nil {
+			log.Printf("Error flushing publisher: %v", err)
+		}
+	}
+	if notifier != nil {
+		if err := notifier.Shutdown(ctx); err != nil {
+			log.Printf("Error stopping webhook deliveries: %v", err)
+		}
+	}
+	if purger != nil {
+		if err := purger.Shutdown(ctx); err != nil {
+			log.Printf("Error stopping retention purge: %v", err)
+		}
+	}
+	if seen != nil {
+		if err := seen.Shutdown(ctx); err != nil {
+			log.Printf("Error saving seen document index: %v", err)
+		}
+	}
+	if indexer != nil {
+		if err := indexer.Shutdown(ctx); err != nil {
+			log.Printf("Error flushing search indexer: %v", err)
+		}
+	}
+
+	if err := tracing.Shutdown(ctx); err != nil {
+		log.Printf("Error exporting spans: %v", err)
+	}
 
 	log.Println("Service stopped")
 }
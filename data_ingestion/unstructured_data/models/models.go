@@ -7,7 +7,7 @@ import (
 type UnstructuredData struct {
 	ID          string                 `json:"id" db:"id"`
 	Source      string                 `json:"source" db:"source"`
-	Type        string                 `json:"type" db:"type"` // news, social, earnings_transcript, press_release
+	Type        string                 `json:"type" db:"type"` // news, social, earnings_transcript, press_release, legal, insider_transaction, earnings_surprise
 	Title       string                 `json:"title" db:"title"`
 	Content     string                 `json:"content" db:"content"`
 	URL         string                 `json:"url" db:"url"`
@@ -18,6 +18,7 @@ type UnstructuredData struct {
 	Tags        []string               `json:"tags" db:"tags"`
 	Entities    []Entity               `json:"entities" db:"entities"`
 	Sentiment   *SentimentScore        `json:"sentiment,omitempty" db:"sentiment"`
+	Summary     string                 `json:"summary,omitempty" db:"summary"` // credit-relevant summary from the summarization job
 	ProcessedAt *time.Time             `json:"processed_at,omitempty" db:"processed_at"`
 }
 
@@ -31,12 +32,22 @@ type Entity struct {
 
 // SentimentScore represents sentiment analysis results
 type SentimentScore struct {
-	Overall   float64            `json:"overall"`   // -1 to 1
-	Positive  float64            `json:"positive"`  // 0 to 1
-	Negative  float64            `json:"negative"`  // 0 to 1
-	Neutral   float64            `json:"neutral"`   // 0 to 1
-	Magnitude float64            `json:"magnitude"` // 0 to inf
-	Aspects   map[string]float64 `json:"aspects"`   // aspect-based sentiment
+	Overall   float64            `json:"overall"`         // -1 to 1
+	Positive  float64            `json:"positive"`        // 0 to 1
+	Negative  float64            `json:"negative"`        // 0 to 1
+	Neutral   float64            `json:"neutral"`         // 0 to 1
+	Magnitude float64            `json:"magnitude"`       // 0 to inf
+	Model     string             `json:"model,omitempty"` // what produced the score; empty when the source supplied it
+	Aspects   map[string]float64 `json:"aspects"`         // aspect-based sentiment
+}
+
+// CreditEvent is a credit event a document reports, from the taxonomy of
+// the credit_event_classification job
+type CreditEvent struct {
+	Type       string  `json:"type"`               // earnings_miss, covenant_breach, downgrade, refinancing, layoffs, m_and_a, fraud_investigation, default
+	Confidence float64 `json:"confidence"`         // 0 to 1
+	Evidence   string  `json:"evidence,omitempty"` // sentence the event was found in
+	Model      string  `json:"model"`              // rules, or the classification service's model
 }
 
 // NewsArticle represents a news article from various sources
@@ -138,12 +149,53 @@ type PressRelease struct {
 	FinancialData map[string]interface{} `json:"financial_data" db:"financial_data"`
 }
 
+// LegalFiling represents a bankruptcy or litigation docket naming a watchlist company
+type LegalFiling struct {
+	UnstructuredData
+	CaseNumber string     `json:"case_number" db:"case_number"`
+	Court      string     `json:"court" db:"court"`
+	CourtID    string     `json:"court_id" db:"court_id"`
+	CaseType   string     `json:"case_type" db:"case_type"` // bankruptcy, litigation
+	Chapter    int        `json:"chapter,omitempty" db:"chapter"`
+	Symbols    []string   `json:"symbols" db:"symbols"`
+	Parties    []string   `json:"parties" db:"parties"`
+	FiledAt    time.Time  `json:"filed_at" db:"filed_at"`
+	Terminated *time.Time `json:"terminated,omitempty" db:"terminated"`
+}
+
+// InsiderTransaction represents a Form 4 trade by a company insider
+type InsiderTransaction struct {
+	UnstructuredData
+	Symbol          string    `json:"symbol" db:"symbol"`
+	Insider         string    `json:"insider" db:"insider"`
+	TransactionCode string    `json:"transaction_code" db:"transaction_code"` // P purchase, S sale, A award, M exercise, ...
+	Change          int64     `json:"change" db:"change"`
+	SharesHeld      int64     `json:"shares_held" db:"shares_held"`
+	Price           float64   `json:"price" db:"price"`
+	Value           float64   `json:"value" db:"value"`
+	TransactionDate time.Time `json:"transaction_date" db:"transaction_date"`
+	FilingDate      time.Time `json:"filing_date" db:"filing_date"`
+}
+
+// EarningsSurprise represents reported against consensus EPS for one quarter
+type EarningsSurprise struct {
+	UnstructuredData
+	Symbol          string   `json:"symbol" db:"symbol"`
+	Period          string   `json:"period" db:"period"`
+	Year            int      `json:"year" db:"year"`
+	Quarter         int      `json:"quarter" db:"quarter"`
+	Actual          float64  `json:"actual" db:"actual"`
+	Estimate        *float64 `json:"estimate,omitempty" db:"estimate"`
+	Surprise        *float64 `json:"surprise,omitempty" db:"surprise"`
+	SurprisePercent *float64 `json:"surprise_percent,omitempty" db:"surprise_percent"`
+}
+
 // ProcessingJob represents a job for processing unstructured data
 type ProcessingJob struct {
 	ID         string                 `json:"id" db:"id"`
 	DataID     string                 `json:"data_id" db:"data_id"`
 	JobType    string                 `json:"job_type" db:"job_type"` // sentiment, entity_extraction, summarization
-	Status     string                 `json:"status" db:"status"`     // pending, processing, completed, failed
+	Status     string                 `json:"status" db:"status"`     // pending, processing, completed, dead
 	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
 	StartedAt  *time.Time             `json:"started_at,omitempty" db:"started_at"`
 	CompletedAt *time.Time            `json:"completed_at,omitempty" db:"completed_at"`
@@ -151,6 +203,8 @@ type ProcessingJob struct {
 	Error      string                 `json:"error" db:"error"`
 	RetryCount int                    `json:"retry_count" db:"retry_count"`
 	Priority   int                    `json:"priority" db:"priority"`
+	// NextAttemptAt holds back a pending job that failed until its retry is due
+	NextAttemptAt *time.Time          `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
 }
 
 // DataQuality represents quality metrics for ingested data
@@ -165,3 +219,24 @@ type DataQuality struct {
 	Issues          []string  `json:"issues" db:"issues"`
 	CheckedAt       time.Time `json:"checked_at" db:"checked_at"`
 }
+
+// DocumentRevision is an immutable snapshot of a document as it was stored at RecordedAt
+type DocumentRevision struct {
+	DataID      string            `json:"data_id" db:"data_id"`
+	Revision    int               `json:"revision" db:"revision"`
+	ContentHash string            `json:"content_hash" db:"content_hash"`
+	RecordedAt  time.Time         `json:"recorded_at" db:"recorded_at"`
+	Data        *UnstructuredData `json:"data" db:"document"`
+}
+
+// Company is the canonical record of an issuer, which the news, quotes and
+// filings that mention it are resolved to by its ID
+type Company struct {
+	ID        string    `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Ticker    string    `json:"ticker" db:"ticker"`
+	CIK       string    `json:"cik,omitempty" db:"cik"` // SEC Central Index Key, 10 digits
+	LEI       string    `json:"lei,omitempty" db:"lei"` // ISO 17442 Legal Entity Identifier
+	Aliases   []string  `json:"aliases" db:"aliases"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// Symbols collects the symbols sources record in a document's metadata: the
+// primary symbol, the symbol it was fetched for and those it mentions, in
+// that order and possibly repeated.
+func (d *UnstructuredData) Symbols() []string {
+	var symbols []string
+	for _, key := range []string{"primary_symbol", "symbol"} {
+		if symbol, ok := d.Metadata[key].(string); ok && symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	switch list := d.Metadata["symbols"].(type) {
+	case []string:
+		symbols = append(symbols, list...)
+	case []interface{}:
+		for _, v := range list {
+			if symbol, ok := v.(string); ok {
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+	return symbols
+}
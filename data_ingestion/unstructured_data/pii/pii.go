@@ -0,0 +1,236 @@
+// Package pii scrubs personal data, such as email addresses and phone
+// numbers, from documents before they are stored, published or indexed.
+package pii
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// detector finds one kind of personal data. valid, when set, confirms a
+// match the pattern alone cannot tell from other numbers.
+type detector struct {
+	name    string
+	pattern *regexp.Regexp
+	valid   func(string) bool
+}
+
+// builtins are the detectors Detectors may name. Phone numbers need
+// separators or a country code, so plain figures in financial text are
+// left alone.
+var builtins = map[string]detector{
+	"email": {name: "email", pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)},
+	"phone": {name: "phone", pattern: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{3}\) ?|\b\d{3}[.-])\d{3}[.-]\d{4}\b|\+\d{1,3} \d{2,4}(?: \d{3,4}){2}\b`)},
+	"ssn":   {name: "ssn", pattern: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)},
+	"card":  {name: "card", pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhn},
+}
+
+// SourceStats counts what was scrubbed from one source's documents.
+type SourceStats struct {
+	Source     string           `json:"source"`
+	Documents  int64            `json:"documents"`
+	Scrubbed   int64            `json:"scrubbed"`
+	Redactions map[string]int64 `json:"redactions"`
+}
+
+// Scrubber wraps a Storage and scrubs documents saved through it.
+type Scrubber struct {
+	storage.Storage
+	config    config.PIIConfig
+	types     map[string]bool
+	detectors []detector
+
+	mu    sync.Mutex
+	stats map[string]*SourceStats
+}
+
+// Wrap returns a Storage that scrubs by cfg, or an error for an unknown
+// detector or an unreadable dictionary.
+func Wrap(store storage.Storage, cfg config.PIIConfig) (*Scrubber, error) {
+	s := &Scrubber{
+		Storage: store,
+		config:  cfg,
+		types:   make(map[string]bool),
+		stats:   make(map[string]*SourceStats),
+	}
+	for _, t := range cfg.Types {
+		s.types[t] = true
+	}
+	for _, name := range cfg.Detectors {
+		d, ok := builtins[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown PII detector %q", name)
+		}
+		s.detectors = append(s.detectors, d)
+	}
+	if cfg.DictionaryFile != "" {
+		d, err := loadDictionary(cfg.DictionaryFile)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			s.detectors = append(s.detectors, *d)
+		}
+	}
+	return s, nil
+}
+
+// loadDictionary reads one term per line, skipping blank lines and those
+// starting with #, into a detector matching any of them as a whole word.
+func loadDictionary(path string) (*detector, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open PII dictionary: %w", err)
+	}
+	defer file.Close()
+
+	var terms []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		term := strings.TrimSpace(scanner.Text())
+		if term != "" && !strings.HasPrefix(term, "#") {
+			terms = append(terms, regexp.QuoteMeta(term))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read PII dictionary: %w", err)
+	}
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	// Longer terms first, so a full name wins over a part of it
+	sort.Slice(terms, func(i, j int) bool { return len(terms[i]) > len(terms[j]) })
+	pattern, err := regexp.Compile(`(?i)\b(?:` + strings.Join(terms, "|") + `)\b`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PII dictionary: %w", err)
+	}
+	return &detector{name: "dictionary", pattern: pattern}, nil
+}
+
+func (s *Scrubber) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	if len(s.types) == 0 || s.types[data.Type] {
+		s.scrub(data)
+	}
+	return s.Storage.SaveUnstructuredData(ctx, data)
+}
+
+// scrub replaces personal data in data's text fields, records the count per
+// detector in its "pii_redactions" metadata key and adds it to the stats.
+func (s *Scrubber) scrub(data *models.UnstructuredData) {
+	counts := make(map[string]int)
+	for _, field := range []*string{&data.Title, &data.Content, &data.Summary, &data.Author} {
+		*field = s.scrubText(*field, counts)
+	}
+
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	if total > 0 {
+		if data.Metadata == nil {
+			data.Metadata = make(map[string]interface{})
+		}
+		data.Metadata["pii_redactions"] = counts
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats, ok := s.stats[data.Source]
+	if !ok {
+		stats = &SourceStats{Source: data.Source, Redactions: make(map[string]int64)}
+		s.stats[data.Source] = stats
+	}
+	stats.Documents++
+	if total > 0 {
+		stats.Scrubbed++
+	}
+	for name, n := range counts {
+		stats.Redactions[name] += int64(n)
+	}
+}
+
+func (s *Scrubber) scrubText(text string, counts map[string]int) string {
+	if text == "" {
+		return text
+	}
+	for _, d := range s.detectors {
+		text = d.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+			counts[d.name]++
+			return s.replacement(d.name, match)
+		})
+	}
+	return text
+}
+
+// replacement is "[EMAIL]" in redact mode and "[EMAIL:1a2b3c4d5e6f]" in hash
+// mode, the hash taken over the match lower-cased with separators removed
+// so that formatting does not change it.
+func (s *Scrubber) replacement(name, match string) string {
+	label := strings.ToUpper(name)
+	if s.config.Mode != "hash" {
+		return "[" + label + "]"
+	}
+	normalized := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(match))
+	mac := hmac.New(sha256.New, []byte(s.config.HashSecret))
+	mac.Write([]byte(name + ":" + normalized))
+	return "[" + label + ":" + hex.EncodeToString(mac.Sum(nil))[:12] + "]"
+}
+
+// Stats returns the counts of every source seen, by source.
+func (s *Scrubber) Stats() []SourceStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]SourceStats, 0, len(s.stats))
+	for _, stats := range s.stats {
+		copied := *stats
+		copied.Redactions = make(map[string]int64, len(stats.Redactions))
+		for name, n := range stats.Redactions {
+			copied.Redactions[name] = n
+		}
+		out = append(out, copied)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Source < out[j].Source })
+	return out
+}
+
+// luhn reports whether the digits in number pass the card checksum.
+func luhn(number string) bool {
+	sum, double := 0, false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		digit := int(c - '0')
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+		sum += digit
+		double = !double
+	}
+	return sum%10 == 0
+}
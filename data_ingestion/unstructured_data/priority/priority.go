@@ -0,0 +1,98 @@
+// Package priority ranks documents for enrichment. It sets the "priority"
+// metadata key that the processing jobs queued for a document inherit, so
+// credit rating actions and news about watchlist issuers are enriched ahead
+// of routine items.
+package priority
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+var (
+	ratingAgencies = []string{"moody's", "moodys", "s&p", "standard & poor's", "fitch", "dbrs", "kbra", "kroll bond"}
+	ratingTerms    = []string{"credit rating", "rating", "outlook", "creditwatch", "credit watch", "junk", "investment grade", "investment-grade"}
+	ratingActions  = []string{"downgrad", "upgrad", "cuts", "cut to", "lowers", "lowered", "raises", "raised", "affirm", "review for", "on watch", "to negative", "to positive", "to stable", "default"}
+)
+
+// Ranker wraps a Storage and sets each document's priority before it is saved.
+type Ranker struct {
+	storage.Storage
+	config    config.PriorityConfig
+	watchlist map[string]bool
+}
+
+// Wrap returns a Storage that ranks documents by cfg.
+func Wrap(store storage.Storage, cfg config.PriorityConfig) *Ranker {
+	watchlist := make(map[string]bool, len(cfg.Symbols))
+	for _, symbol := range cfg.Symbols {
+		watchlist[strings.ToUpper(symbol)] = true
+	}
+	return &Ranker{Storage: store, config: cfg, watchlist: watchlist}
+}
+
+func (r *Ranker) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	if score := r.Score(data); score != 0 {
+		if data.Metadata == nil {
+			data.Metadata = make(map[string]interface{})
+		}
+		data.Metadata["priority"] = score
+	}
+	return r.Storage.SaveUnstructuredData(ctx, data)
+}
+
+// Score is the document's priority: the priority its source assigned, raised
+// to RatingAction for news of a rating action, plus Watchlist when it
+// mentions a watchlist symbol.
+func (r *Ranker) Score(data *models.UnstructuredData) int {
+	score := storage.JobPriority(data)
+	if IsRatingAction(data.Title + " " + data.Content) {
+		score = max(score, r.config.RatingAction)
+	}
+	if r.mentionsWatchlist(data) {
+		score += r.config.Watchlist
+	}
+	return score
+}
+
+// IsRatingAction reports whether text reads as a credit rating action: an
+// agency or rating term together with an action such as a downgrade.
+func IsRatingAction(text string) bool {
+	text = strings.ToLower(text)
+	return (containsAny(text, ratingAgencies) || containsAny(text, ratingTerms)) && containsAny(text, ratingActions)
+}
+
+// mentionsWatchlist checks the symbol and symbols metadata keys sources set.
+func (r *Ranker) mentionsWatchlist(data *models.UnstructuredData) bool {
+	if symbol, ok := data.Metadata["symbol"].(string); ok && r.watchlist[strings.ToUpper(symbol)] {
+		return true
+	}
+	switch symbols := data.Metadata["symbols"].(type) {
+	case []string:
+		for _, symbol := range symbols {
+			if r.watchlist[strings.ToUpper(symbol)] {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, symbol := range symbols {
+			if s, ok := symbol.(string); ok && r.watchlist[strings.ToUpper(s)] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsAny(text string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// kafkaBroker sends through a Kafka REST Proxy (the Confluent v2 API), which
+// keeps a Kafka client out of the service.
+type kafkaBroker struct {
+	config config.KafkaConfig
+	client *http.Client
+}
+
+func newKafkaBroker(cfg config.KafkaConfig, timeout time.Duration) *kafkaBroker {
+	return &kafkaBroker{config: cfg, client: &http.Client{Timeout: timeout}}
+}
+
+type record struct {
+	Key   *string         `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Send posts one request per topic; the proxy partitions them by key.
+func (b *kafkaBroker) Send(ctx context.Context, messages []Message) error {
+	var topics []string
+	byTopic := make(map[string][]record)
+	for _, msg := range messages {
+		rec := record{Value: msg.Value}
+		if msg.Key != "" {
+			key := msg.Key
+			rec.Key = &key
+		}
+		if _, ok := byTopic[msg.Topic]; !ok {
+			topics = append(topics, msg.Topic)
+		}
+		byTopic[msg.Topic] = append(byTopic[msg.Topic], rec)
+	}
+	for _, topic := range topics {
+		if err := b.send(ctx, topic, byTopic[topic]); err != nil {
+			return fmt.Errorf("topic %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+func (b *kafkaBroker) send(ctx context.Context, topic string, records []record) error {
+	body, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(b.config.RESTURL, "/") + "/topics/" + url.PathEscape(topic)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("REST proxy returned status %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+
+	// The proxy answers 200 even when single records fail
+	var result struct {
+		Offsets []struct {
+			Error string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode REST proxy response: %w", err)
+	}
+	for _, offset := range result.Offsets {
+		if offset.Error != "" {
+			return fmt.Errorf("kafka rejected a record: %s", offset.Error)
+		}
+	}
+	return nil
+}
+
+func (b *kafkaBroker) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,287 @@
+package publish
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// natsBroker publishes to NATS JetStream over the plain text protocol, which
+// keeps a NATS client out of the service. Every message is published with a
+// reply subject, on which JetStream acknowledges it once a stream has stored
+// it. The connection is opened on the first Send and again after any error.
+type natsBroker struct {
+	address  string
+	user     *url.Userinfo
+	token    string
+	timeout  time.Duration
+	conn     net.Conn
+	reader   *bufio.Reader
+	inbox    string
+	inboxSeq int
+}
+
+func newNATSBroker(cfg config.NATSConfig, timeout time.Duration) (*natsBroker, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("NATS URL %q must look like nats://host:port", cfg.URL)
+	}
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &natsBroker{address: address, user: u.User, token: cfg.Token, timeout: timeout}, nil
+}
+
+// Send publishes the messages in one write and waits for an acknowledgement
+// of each.
+func (b *natsBroker) Send(ctx context.Context, messages []Message) error {
+	if b.conn == nil {
+		if err := b.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if err := b.send(ctx, messages); err != nil {
+		b.Close()
+		return err
+	}
+	return nil
+}
+
+func (b *natsBroker) send(ctx context.Context, messages []Message) error {
+	deadline := time.Now().Add(b.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	b.conn.SetDeadline(deadline)
+
+	// Replies are told apart by the batch and index in their subject, so a
+	// late one for an earlier batch is not taken for this one
+	b.inboxSeq++
+	prefix := b.inbox + "." + strconv.Itoa(b.inboxSeq) + "-"
+	var buf bytes.Buffer
+	for i, msg := range messages {
+		fmt.Fprintf(&buf, "PUB %s %s%d %d\r\n", routingKey(msg), prefix, i, len(msg.Value))
+		buf.Write(msg.Value)
+		buf.WriteString("\r\n")
+	}
+	if _, err := b.conn.Write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	acked := make([]bool, len(messages))
+	for remaining := len(messages); remaining > 0; {
+		subject, headers, payload, err := b.next()
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(subject, prefix) {
+			continue
+		}
+		i, err := strconv.Atoi(strings.TrimPrefix(subject, prefix))
+		if err != nil || i < 0 || i >= len(messages) || acked[i] {
+			continue
+		}
+		if strings.Contains(headers, " 503") {
+			return fmt.Errorf("no JetStream stream captures %s", routingKey(messages[i]))
+		}
+		var ack struct {
+			Stream string `json:"stream"`
+			Error  *struct {
+				Description string `json:"description"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(payload, &ack); err != nil {
+			return fmt.Errorf("invalid JetStream acknowledgement: %w", err)
+		}
+		if ack.Error != nil {
+			return fmt.Errorf("JetStream rejected %s: %s", routingKey(messages[i]), ack.Error.Description)
+		}
+		acked[i] = true
+		remaining--
+	}
+	return nil
+}
+
+// next reads the protocol until the next message, answering the server's
+// pings on the way. headers is the header block of an HMSG, empty for a MSG.
+func (b *natsBroker) next() (subject, headers string, payload []byte, err error) {
+	for {
+		line, err := b.readLine()
+		if err != nil {
+			return "", "", nil, err
+		}
+		op, args, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(op) {
+		case "PING":
+			if _, err := io.WriteString(b.conn, "PONG\r\n"); err != nil {
+				return "", "", nil, err
+			}
+		case "-ERR":
+			return "", "", nil, fmt.Errorf("NATS error: %s", args)
+		case "MSG":
+			// MSG <subject> <sid> [reply-to] <size>
+			fields := strings.Fields(args)
+			if len(fields) < 3 {
+				return "", "", nil, fmt.Errorf("malformed NATS message %q", line)
+			}
+			body, err := b.readPayload(fields[len(fields)-1])
+			if err != nil {
+				return "", "", nil, err
+			}
+			return fields[0], "", body, nil
+		case "HMSG":
+			// HMSG <subject> <sid> [reply-to] <header size> <total size>
+			fields := strings.Fields(args)
+			if len(fields) < 4 {
+				return "", "", nil, fmt.Errorf("malformed NATS message %q", line)
+			}
+			headerSize, err := strconv.Atoi(fields[len(fields)-2])
+			if err != nil {
+				return "", "", nil, fmt.Errorf("malformed NATS message %q", line)
+			}
+			body, err := b.readPayload(fields[len(fields)-1])
+			if err != nil {
+				return "", "", nil, err
+			}
+			if headerSize > len(body) {
+				return "", "", nil, fmt.Errorf("malformed NATS message %q", line)
+			}
+			return fields[0], string(body[:headerSize]), body[headerSize:], nil
+		}
+	}
+}
+
+func (b *natsBroker) readLine() (string, error) {
+	line, err := b.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (b *natsBroker) readPayload(sizeText string) ([]byte, error) {
+	size, err := strconv.Atoi(sizeText)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("invalid NATS payload size %q", sizeText)
+	}
+	body := make([]byte, size+2)
+	if _, err := io.ReadFull(b.reader, body); err != nil {
+		return nil, err
+	}
+	return body[:size], nil
+}
+
+// connect opens the connection, authenticates and subscribes to the inbox
+// that acknowledgements arrive on.
+func (b *natsBroker) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: b.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", b.address)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(b.timeout))
+	b.conn, b.reader = conn, bufio.NewReader(conn)
+
+	if err := b.handshake(); err != nil {
+		b.Close()
+		return fmt.Errorf("NATS handshake with %s: %w", b.address, err)
+	}
+	return nil
+}
+
+func (b *natsBroker) handshake() error {
+	line, err := b.readLine()
+	if err != nil {
+		return err
+	}
+	infoText, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+		Headers     bool `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(infoText), &info); err != nil {
+		return err
+	}
+	if info.TLSRequired {
+		return errors.New("server requires TLS, which is not supported")
+	}
+
+	connect := map[string]interface{}{
+		"verbose":       false,
+		"pedantic":      false,
+		"name":          "credtech-unstructured-ingestion",
+		"lang":          "go",
+		"version":       "1.0.0",
+		"protocol":      1,
+		"headers":       info.Headers,
+		"no_responders": info.Headers,
+	}
+	if b.user != nil {
+		connect["user"] = b.user.Username()
+		if password, ok := b.user.Password(); ok {
+			connect["pass"] = password
+		}
+	}
+	if b.token != "" {
+		connect["auth_token"] = b.token
+	}
+	options, err := json.Marshal(connect)
+	if err != nil {
+		return err
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	b.inbox = "_INBOX." + hex.EncodeToString(id)
+	b.inboxSeq = 0
+	if _, err := fmt.Fprintf(b.conn, "CONNECT %s\r\nSUB %s.* 1\r\nPING\r\n", options, b.inbox); err != nil {
+		return err
+	}
+	for {
+		line, err := b.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := io.WriteString(b.conn, "PONG\r\n"); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (b *natsBroker) Close() error {
+	if b.conn == nil {
+		return nil
+	}
+	err := b.conn.Close()
+	b.conn, b.reader = nil, nil
+	return err
+}
@@ -0,0 +1,341 @@
+// Package publish streams saved documents and completed processing jobs to a
+// message bus, so downstream scoring and analytics services consume a stream
+// instead of polling storage. Messages are keyed by symbol, which keeps the
+// messages about one symbol in order, and sent by a Broker for the configured
+// backend: Kafka through its REST Proxy, NATS JetStream or RabbitMQ over AMQP. Delivery
+// is at least once: a batch is kept until the broker acknowledges it and is
+// sent again after a failure, while a save never fails because its message
+// could not be sent.
+package publish
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const (
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// JobEvent is the message sent when a processing job completes.
+type JobEvent struct {
+	JobID       string    `json:"job_id"`
+	DataID      string    `json:"data_id"`
+	JobType     string    `json:"job_type"`
+	Symbol      string    `json:"symbol,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Message is one encoded document or job event bound for a topic.
+type Message struct {
+	Topic string          `json:"topic"`
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Broker sends messages to a message bus. Send returns nil only once the bus
+// has acknowledged every message; after an error all of them are sent again,
+// so a broker may deliver some twice but must not report one it lost.
+type Broker interface {
+	Send(ctx context.Context, messages []Message) error
+	Close() error
+}
+
+// New returns the broker for cfg.Backend.
+func New(cfg config.PublishConfig) (Broker, error) {
+	switch cfg.Backend {
+	case "kafka":
+		return newKafkaBroker(cfg.Kafka, cfg.Timeout), nil
+	case "nats":
+		return newNATSBroker(cfg.NATS, cfg.Timeout)
+	case "rabbitmq":
+		return newRabbitMQBroker(cfg.RabbitMQ, cfg.BatchSize, cfg.Timeout), nil
+	}
+	return nil, fmt.Errorf("unknown publish backend %q", cfg.Backend)
+}
+
+// Publisher wraps a Storage and publishes every document saved through it.
+type Publisher struct {
+	storage.Storage
+	config config.PublishConfig
+	broker Broker
+
+	queue chan Message
+	// pending holds the messages taken off the queue and not yet
+	// acknowledged; only run touches it
+	pending []Message
+
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// Wrap returns a Storage that publishes by cfg and starts its sender, which
+// first sends the messages the last run left in cfg.BufferFile.
+func Wrap(store storage.Storage, cfg config.PublishConfig) (*Publisher, error) {
+	broker, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	p := &Publisher{
+		Storage: store,
+		config:  cfg,
+		broker:  broker,
+		queue:   make(chan Message, cfg.BufferSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if err := p.loadBuffer(); err != nil {
+		log.Printf("Failed to load unsent messages from %s: %v", cfg.BufferFile, err)
+	}
+	go p.run()
+	return p, nil
+}
+
+func (p *Publisher) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	if err := p.Storage.SaveUnstructuredData(ctx, data); err != nil {
+		return err
+	}
+	p.enqueue(p.config.DocumentsTopic, Symbol(data), data)
+	return nil
+}
+
+// JobCompleted publishes the completion of a processing job, keyed by the
+// symbol of its document.
+func (p *Publisher) JobCompleted(ctx context.Context, jobID, dataID, jobType string) {
+	event := JobEvent{JobID: jobID, DataID: dataID, JobType: jobType, CompletedAt: time.Now().UTC()}
+	if data, err := p.Storage.GetUnstructuredData(ctx, dataID); err == nil {
+		event.Symbol = Symbol(data)
+	}
+	p.enqueue(p.config.JobsTopic, event.Symbol, event)
+}
+
+// Shutdown sends the messages still waiting, saves those the broker does not
+// take to the buffer file and closes the broker, waiting until ctx is done
+// at most.
+func (p *Publisher) Shutdown(ctx context.Context) error {
+	p.once.Do(func() { close(p.stop) })
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if dropped := p.dropped.Load(); dropped > 0 {
+		log.Printf("Publisher dropped %d messages while its buffer was full", dropped)
+	}
+	return p.broker.Close()
+}
+
+// Symbol is the key of a document's messages: its primary symbol, else the
+// symbol its source fetched it for, else the first symbol it mentions. A
+// document without one is keyed by nothing.
+func Symbol(data *models.UnstructuredData) string {
+	for _, key := range []string{"primary_symbol", "symbol"} {
+		if symbol, ok := data.Metadata[key].(string); ok && symbol != "" {
+			return strings.ToUpper(symbol)
+		}
+	}
+	switch symbols := data.Metadata["symbols"].(type) {
+	case []string:
+		if len(symbols) > 0 {
+			return strings.ToUpper(symbols[0])
+		}
+	case []interface{}:
+		if len(symbols) > 0 {
+			if symbol, ok := symbols[0].(string); ok {
+				return strings.ToUpper(symbol)
+			}
+		}
+	}
+	return ""
+}
+
+// enqueue encodes value now, before its caller changes it, and queues it
+// unless the queue is full.
+func (p *Publisher) enqueue(topic, key string, value interface{}) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Failed to encode %s message: %v", topic, err)
+		return
+	}
+	select {
+	case p.queue <- Message{Topic: topic, Key: key, Value: raw}:
+	default:
+		p.dropped.Add(1)
+	}
+}
+
+// run sends the queue in batches until Shutdown. While the broker is down it
+// retries with a growing backoff and stops taking from the queue once
+// BufferSize messages wait, so the queue fills and new messages are dropped
+// rather than memory growing without bound.
+func (p *Publisher) run() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	var retryAt time.Time
+	backoff := minBackoff
+	for {
+		queue := p.queue
+		if len(p.pending) >= p.config.BufferSize {
+			queue = nil
+		}
+		select {
+		case msg := <-queue:
+			p.pending = append(p.pending, msg)
+			if len(p.pending) < p.config.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-p.stop:
+			p.shutdown()
+			return
+		}
+		if len(p.pending) == 0 || time.Now().Before(retryAt) {
+			continue
+		}
+
+		if err := p.flush(); err != nil {
+			if backoff == minBackoff {
+				log.Printf("Failed to publish to %s, keeping %d messages: %v", p.config.Backend, len(p.pending), err)
+			}
+			retryAt = time.Now().Add(backoff)
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		if backoff > minBackoff {
+			log.Printf("Publishing to %s resumed", p.config.Backend)
+		}
+		retryAt, backoff = time.Time{}, minBackoff
+	}
+}
+
+// flush sends the pending messages in batches of BatchSize, dropping each
+// batch once the broker acknowledges it.
+func (p *Publisher) flush() error {
+	for len(p.pending) > 0 {
+		batch := p.pending[:min(p.config.BatchSize, len(p.pending))]
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.Timeout)
+		err := p.broker.Send(ctx, batch)
+		cancel()
+		if err != nil {
+			return err
+		}
+		p.pending = p.pending[len(batch):]
+	}
+	p.pending = nil
+	return nil
+}
+
+// shutdown makes one last attempt to send everything still waiting and saves
+// what the broker does not take.
+func (p *Publisher) shutdown() {
+	for len(p.queue) > 0 {
+		p.pending = append(p.pending, <-p.queue)
+	}
+	if len(p.pending) == 0 {
+		return
+	}
+	if err := p.flush(); err == nil {
+		return
+	} else if p.config.BufferFile == "" {
+		log.Printf("Failed to publish to %s, dropping %d messages: %v", p.config.Backend, len(p.pending), err)
+		return
+	}
+	if err := p.saveBuffer(); err != nil {
+		log.Printf("Failed to save %d unsent messages to %s: %v", len(p.pending), p.config.BufferFile, err)
+		return
+	}
+	log.Printf("Saved %d unsent messages to %s", len(p.pending), p.config.BufferFile)
+}
+
+// saveBuffer writes the pending messages to the buffer file, one JSON object
+// per line.
+func (p *Publisher) saveBuffer() error {
+	if err := os.MkdirAll(filepath.Dir(p.config.BufferFile), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(p.config.BufferFile)
+	if err != nil {
+		return err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, msg := range p.pending {
+		if err := encoder.Encode(msg); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// loadBuffer takes the messages the last run saved as pending and removes
+// the file, so they are sent once more at most.
+func (p *Publisher) loadBuffer() error {
+	if p.config.BufferFile == "" {
+		return nil
+	}
+	file, err := os.Open(p.config.BufferFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg Message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		p.pending = append(p.pending, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(p.pending) > 0 {
+		log.Printf("Loaded %d unsent messages from %s", len(p.pending), p.config.BufferFile)
+	}
+	return os.Remove(p.config.BufferFile)
+}
+
+// routingKey is the subject or routing key of msg, <topic>.<symbol>, with the
+// symbol reduced to one token of letters, digits, "-" and "_".
+func routingKey(msg Message) string {
+	if msg.Key == "" {
+		return msg.Topic + "._"
+	}
+	token := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, msg.Key)
+	return msg.Topic + "." + token
+}
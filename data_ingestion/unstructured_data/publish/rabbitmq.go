@@ -0,0 +1,112 @@
+package publish
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// rabbitMQBroker publishes over AMQP 0-9-1 on a channel in confirm mode, so
+// a message counts as sent only once the broker confirms it, which for a
+// persistent message routed to a durable queue means once it is on disk.
+// Messages are published mandatory: one no queue takes comes back and is
+// reported as failed. The connection is opened on the first Send and again
+// after any error.
+type rabbitMQBroker struct {
+	config    config.RabbitMQConfig
+	timeout   time.Duration
+	batchSize int
+	conn      *amqp.Connection
+	channel   *amqp.Channel
+	returns   chan amqp.Return
+}
+
+func newRabbitMQBroker(cfg config.RabbitMQConfig, batchSize int, timeout time.Duration) *rabbitMQBroker {
+	return &rabbitMQBroker{config: cfg, timeout: timeout, batchSize: batchSize}
+}
+
+// Send publishes the messages, persistent, to the exchange and waits for
+// the broker to confirm each.
+func (b *rabbitMQBroker) Send(ctx context.Context, messages []Message) error {
+	if b.channel == nil {
+		if err := b.connect(); err != nil {
+			return err
+		}
+	}
+	if err := b.send(ctx, messages); err != nil {
+		b.Close()
+		return err
+	}
+	return nil
+}
+
+func (b *rabbitMQBroker) connect() error {
+	conn, err := amqp.DialConfig(b.config.URL, amqp.Config{
+		SASL:  []amqp.Authentication{&amqp.PlainAuth{Username: b.config.User, Password: b.config.Password}},
+		Vhost: b.config.VHost,
+		Dial:  amqp.DefaultDial(b.timeout),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+	}
+	channel, err := conn.Channel()
+	if err == nil {
+		err = channel.Confirm(false)
+	}
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to open RabbitMQ confirm channel: %w", err)
+	}
+	b.conn, b.channel = conn, channel
+	// The broker returns an unroutable message before confirming it, and the
+	// client hands it over before reading on, so every message of a batch
+	// may need room here
+	b.returns = channel.NotifyReturn(make(chan amqp.Return, b.batchSize))
+	return nil
+}
+
+func (b *rabbitMQBroker) send(ctx context.Context, messages []Message) error {
+	ctx, cancel := context.WithTimeout(ctx, b.timeout)
+	defer cancel()
+
+	confirms := make([]*amqp.DeferredConfirmation, len(messages))
+	for i, msg := range messages {
+		confirm, err := b.channel.PublishWithDeferredConfirmWithContext(ctx, b.config.Exchange, routingKey(msg), true, false, amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         msg.Value,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to publish to RabbitMQ: %w", err)
+		}
+		confirms[i] = confirm
+	}
+	for i, confirm := range confirms {
+		acked, err := confirm.WaitContext(ctx)
+		if err != nil {
+			return fmt.Errorf("RabbitMQ did not confirm the batch: %w", err)
+		}
+		if !acked {
+			return errors.New("RabbitMQ refused the message for " + routingKey(messages[i]))
+		}
+	}
+	select {
+	case returned := <-b.returns:
+		return errors.New("no queue is bound for " + returned.RoutingKey)
+	default:
+	}
+	return nil
+}
+
+func (b *rabbitMQBroker) Close() error {
+	if b.conn == nil {
+		return nil
+	}
+	err := b.conn.Close()
+	b.conn, b.channel, b.returns = nil, nil, nil
+	return err
+}
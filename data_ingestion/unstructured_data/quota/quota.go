@@ -0,0 +1,257 @@
+// Package quota limits how many documents each source and tenant may store per
+// day, so a runaway feed or an over-broad keyword cannot flood shared storage.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+const (
+	// PolicyDrop discards everything over the limit.
+	PolicyDrop = "drop"
+	// PolicySample keeps one in SampleEvery documents over the limit.
+	PolicySample = "sample"
+	// PolicyDropLowImportance keeps only important documents over the limit, up to a hard cap.
+	PolicyDropLowImportance = "drop_low_importance"
+
+	// DefaultTenant is used for documents without a tenant metadata key.
+	DefaultTenant = "default"
+)
+
+// importantTags raise a document's importance when present.
+var importantTags = map[string]float64{
+	"macro_event":   5,
+	"rate_decision": 5,
+	"alert":         5,
+	"index_removal": 4,
+	"earnings":      3,
+}
+
+// Usage is the current day's count for one quota key.
+type Usage struct {
+	Key      string `json:"key"`
+	Limit    int    `json:"limit"`
+	Stored   int    `json:"stored"`
+	Overflow int    `json:"overflow"` // stored above the limit by the overflow policy
+	Dropped  int    `json:"dropped"`
+}
+
+// Enforcer wraps a Storage and applies daily quotas to SaveUnstructuredData.
+type Enforcer struct {
+	storage.Storage
+	config config.QuotaConfig
+
+	mu     sync.Mutex
+	day    string
+	usage  map[string]*Usage
+	stored map[string]bool // IDs counted today, which saving again does not count
+}
+
+// limit is one daily quota a document is held to.
+type limit struct {
+	key   string
+	limit int
+}
+
+// counted is a document counted against a quota, as stored or as overflow.
+type counted struct {
+	usage    *Usage
+	overflow bool
+}
+
+// Wrap returns a Storage that enforces cfg on writes.
+func Wrap(store storage.Storage, cfg config.QuotaConfig) *Enforcer {
+	return &Enforcer{
+		Storage: store,
+		config:  cfg,
+		usage:   make(map[string]*Usage),
+		stored:  make(map[string]bool),
+	}
+}
+
+func (e *Enforcer) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	tenant := DefaultTenant
+	if t, ok := data.Metadata["tenant"].(string); ok && t != "" {
+		tenant = t
+	}
+
+	counts, ok := e.admit(data, []limit{
+		{key: "source:" + data.Source, limit: e.limitFor(e.config.SourceLimits, data.Source)},
+		{key: "tenant:" + tenant, limit: e.limitFor(e.config.TenantLimits, tenant)},
+	})
+	if !ok {
+		return nil
+	}
+	if err := e.Storage.SaveUnstructuredData(ctx, data); err != nil {
+		// nil when counted by an earlier save, which stays counted
+		if counts != nil {
+			e.release(data.ID, counts)
+		}
+		return err
+	}
+	return nil
+}
+
+// Usage returns today's counters for every quota key.
+func (e *Enforcer) Usage() []Usage {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rollover()
+	usage := make([]Usage, 0, len(e.usage))
+	for _, u := range e.usage {
+		usage = append(usage, *u)
+	}
+	return usage
+}
+
+func (e *Enforcer) limitFor(limits map[string]int, name string) int {
+	if limit, ok := limits[name]; ok {
+		return limit
+	}
+	return e.config.DefaultDailyLimit
+}
+
+// admit reports whether data may be stored under every one of limits, and
+// only then counts it against them, returning the counts made. A document
+// already counted today is admitted without counting it again. Limits of
+// zero or less are unlimited.
+func (e *Enforcer) admit(data *models.UnstructuredData, limits []limit) ([]counted, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.rollover()
+	if e.stored[data.ID] {
+		return nil, true
+	}
+
+	counts := make([]counted, 0, len(limits))
+	var refused []*Usage
+	for _, l := range limits {
+		if l.limit <= 0 {
+			continue
+		}
+		u, ok := e.usage[l.key]
+		if !ok {
+			u = &Usage{Key: l.key, Limit: l.limit}
+			e.usage[l.key] = u
+		}
+		switch {
+		case u.Stored < l.limit:
+			counts = append(counts, counted{usage: u})
+		case e.keepOverflow(data, u):
+			counts = append(counts, counted{usage: u, overflow: true})
+		default:
+			refused = append(refused, u)
+		}
+	}
+	if len(refused) > 0 {
+		for _, u := range refused {
+			u.Dropped++
+		}
+		return nil, false
+	}
+
+	for _, c := range counts {
+		if c.overflow {
+			c.usage.Overflow++
+		} else {
+			c.usage.Stored++
+		}
+	}
+	if data.ID != "" {
+		e.stored[data.ID] = true
+	}
+	return counts, true
+}
+
+// keepOverflow applies the overflow policy to data over the limit of u;
+// the caller holds the lock.
+func (e *Enforcer) keepOverflow(data *models.UnstructuredData, u *Usage) bool {
+	if u.Overflow == 0 && u.Dropped == 0 {
+		log.Printf("Ingestion quota reached for %s (%d/day), applying %s policy", u.Key, u.Limit, e.config.Policy)
+	}
+
+	switch e.config.Policy {
+	case PolicySample:
+		return e.config.SampleEvery > 0 && (u.Overflow+u.Dropped)%e.config.SampleEvery == 0
+	case PolicyDropLowImportance:
+		hardCap := int(float64(u.Limit) * e.config.HardCapFactor)
+		return Importance(data) >= e.config.ImportanceFloor && u.Stored+u.Overflow < hardCap
+	}
+	return false
+}
+
+// release takes back the counts admit made for a document that then failed
+// to save.
+func (e *Enforcer) release(id string, counts []counted) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, c := range counts {
+		if c.overflow {
+			c.usage.Overflow--
+		} else {
+			c.usage.Stored--
+		}
+	}
+	delete(e.stored, id)
+}
+
+// rollover resets the counters at the start of each UTC day; callers hold the lock.
+func (e *Enforcer) rollover() {
+	today := time.Now().UTC().Format("2006-01-02")
+	if e.day == today {
+		return
+	}
+	for _, u := range e.usage {
+		if u.Dropped > 0 {
+			log.Printf("Ingestion quota for %s on %s: stored %d, overflow %d, dropped %d", u.Key, e.day, u.Stored, u.Overflow, u.Dropped)
+		}
+	}
+	e.day = today
+	e.usage = make(map[string]*Usage)
+	e.stored = make(map[string]bool)
+}
+
+// Importance scores a document for overflow decisions from its metadata
+// priority and tags. Higher is more important.
+func Importance(data *models.UnstructuredData) float64 {
+	score := 0.0
+	switch p := data.Metadata["priority"].(type) {
+	case int:
+		score = float64(p)
+	case float64:
+		score = p
+	}
+	for _, tag := range data.Tags {
+		if boost, ok := importantTags[tag]; ok && boost > score {
+			score = boost
+		}
+	}
+	return score
+}
+
+// Validate checks a quota configuration.
+func Validate(cfg config.QuotaConfig) error {
+	switch cfg.Policy {
+	case PolicyDrop, PolicySample, PolicyDropLowImportance:
+	default:
+		return fmt.Errorf("unknown quota overflow policy %q", cfg.Policy)
+	}
+	if cfg.Policy == PolicySample && cfg.SampleEvery < 1 {
+		return fmt.Errorf("quota sample policy needs SampleEvery >= 1")
+	}
+	if cfg.Policy == PolicyDropLowImportance && cfg.HardCapFactor < 1 {
+		return fmt.Errorf("quota hard cap factor must be at least 1")
+	}
+	return nil
+}
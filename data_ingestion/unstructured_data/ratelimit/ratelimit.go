@@ -0,0 +1,200 @@
+// Package ratelimit keeps the sources polite to the hosts they read. One
+// Limiter is shared by every source, so sources reading the same host share
+// its request rate; a 429 or 503 with Retry-After holds the whole host back
+// for as long as it asks, and sources that scrape pages check robots.txt.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/crawler"
+)
+
+// defaultBackoff is how long a host is held back after a 429 without
+// Retry-After, doubled on every further one.
+const defaultBackoff = 5 * time.Second
+
+// Policy is how the requests of one source are limited.
+type Policy struct {
+	// QPS is the most requests per second to any one host
+	QPS float64
+	// Robots makes the source skip URLs robots.txt disallows and keep to
+	// its Crawl-delay
+	Robots bool
+}
+
+// Limiter spaces the requests to each host.
+type Limiter struct {
+	config config.RateLimitConfig
+	robots *crawler.RobotsCache
+
+	mu    sync.Mutex
+	hosts map[string]*host
+}
+
+type host struct {
+	next time.Time // earliest time of the next request
+}
+
+func New(cfg config.RateLimitConfig) *Limiter {
+	return &Limiter{
+		config: cfg,
+		robots: crawler.NewRobotsCache(&http.Client{Timeout: 30 * time.Second}, cfg.UserAgent),
+		hosts:  make(map[string]*host),
+	}
+}
+
+// Policy returns the configured policy of source.
+func (l *Limiter) Policy(source string) Policy {
+	qps, ok := l.config.SourceQPS[source]
+	if !ok {
+		qps = l.config.DefaultQPS
+	}
+	policy := Policy{QPS: qps}
+	for _, name := range l.config.RobotsSources {
+		if name == source {
+			policy.Robots = true
+		}
+	}
+	return policy
+}
+
+// Wrap routes client's requests through the limiter under policy.
+func (l *Limiter) Wrap(client *http.Client, policy Policy) {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &transport{limiter: l, policy: policy, base: base}
+}
+
+// wait reserves the host's next request slot, interval after the one
+// before, and sleeps until it comes up.
+func (l *Limiter) wait(ctx context.Context, name string, interval time.Duration) error {
+	l.mu.Lock()
+	h := l.hosts[name]
+	if h == nil {
+		h = &host{}
+		l.hosts[name] = h
+	}
+	now := time.Now()
+	slot := h.next
+	if slot.Before(now) {
+		slot = now
+	}
+	h.next = slot.Add(interval)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(slot))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// holdBack keeps every source off the host until at least until.
+func (l *Limiter) holdBack(name string, until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h := l.hosts[name]
+	if h == nil {
+		h = &host{}
+		l.hosts[name] = h
+	}
+	if until.After(h.next) {
+		h.next = until
+	}
+}
+
+type transport struct {
+	limiter *Limiter
+	policy  Policy
+	base    http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	name := strings.ToLower(req.URL.Host)
+	cfg := t.limiter.config
+
+	var interval time.Duration
+	if t.policy.QPS > 0 {
+		interval = time.Duration(float64(time.Second) / t.policy.QPS)
+	}
+	if t.policy.Robots {
+		allowed, delay, err := t.limiter.robots.Allowed(ctx, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, crawler.ErrDisallowed
+		}
+		if delay > interval {
+			interval = delay
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.limiter.wait(ctx, name, interval); err != nil {
+			return nil, err
+		}
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		delay, limited := retryDelay(resp, attempt)
+		if !limited {
+			return resp, nil
+		}
+		t.limiter.holdBack(name, time.Now().Add(delay))
+		// A request whose body cannot be sent again gets the response
+		replayable := req.Body == nil || req.GetBody != nil
+		if attempt >= cfg.MaxRetries || delay > cfg.MaxRetryAfter || !replayable {
+			return resp, nil
+		}
+		log.Printf("%s limited requests with status %d, retrying in %v", name, resp.StatusCode, delay)
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to replay request body: %w", err)
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+	}
+}
+
+// retryDelay reports whether resp says the host is limiting requests, and
+// for how long to hold back: a 429, or a 503 with Retry-After.
+func retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	header := resp.Header.Get("Retry-After")
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+	case resp.StatusCode == http.StatusServiceUnavailable && header != "":
+	default:
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(strings.TrimSpace(header)); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return defaultBackoff << attempt, true
+}
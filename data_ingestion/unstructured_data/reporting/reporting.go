@@ -0,0 +1,167 @@
+// Package reporting captures panics and forwards them, with stack traces, to a
+// Sentry-compatible error-reporting endpoint. Without a DSN events are only logged.
+package reporting
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// Reporter receives captured errors.
+type Reporter interface {
+	Report(message string, stack []byte, tags map[string]string)
+}
+
+var (
+	mu       sync.RWMutex
+	reporter Reporter = logReporter{}
+)
+
+// Init installs the reporter described by cfg as the process-wide default.
+func Init(cfg config.ErrorReportingConfig) error {
+	if cfg.DSN == "" {
+		return nil
+	}
+	sentry, err := NewSentryReporter(cfg.DSN, cfg.Environment)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	reporter = sentry
+	mu.Unlock()
+	return nil
+}
+
+// CapturePanic logs and reports a recovered panic value. Call it from a
+// deferred function: defer func() { if r := recover(); r != nil { reporting.CapturePanic(r, tags) } }()
+func CapturePanic(recovered interface{}, tags map[string]string) {
+	stack := debug.Stack()
+	message := fmt.Sprintf("panic: %v", recovered)
+
+	mu.RLock()
+	r := reporter
+	mu.RUnlock()
+	r.Report(message, stack, tags)
+}
+
+// Middleware recovers panics in HTTP handlers, reports them and answers with a 500 JSON body.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+				CapturePanic(recovered, map[string]string{
+					"component": "http",
+					"method":    r.Method,
+					"path":      r.URL.Path,
+				})
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(map[string]interface{}{
+					"code":    "internal_error",
+					"message": "internal server error",
+				})
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+type logReporter struct{}
+
+func (logReporter) Report(message string, stack []byte, tags map[string]string) {
+	log.Printf("%s %v\n%s", message, tags, stack)
+}
+
+// SentryReporter posts events to a Sentry-compatible store endpoint.
+type SentryReporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	serverName  string
+	client      *http.Client
+}
+
+// NewSentryReporter parses a DSN of the form https://<key>@<host>/<project>.
+func NewSentryReporter(dsn, environment string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid error reporting DSN: %w", err)
+	}
+	key := u.User.Username()
+	project := strings.Trim(u.Path, "/")
+	if key == "" || project == "" {
+		return nil, fmt.Errorf("invalid error reporting DSN: missing key or project")
+	}
+
+	hostname, _ := os.Hostname()
+	return &SentryReporter{
+		endpoint:    fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=credtech-go/1.0, sentry_key=%s", key),
+		environment: environment,
+		serverName:  hostname,
+		client:      &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// Report logs the event locally and sends it asynchronously.
+func (s *SentryReporter) Report(message string, stack []byte, tags map[string]string) {
+	logReporter{}.Report(message, stack, tags)
+
+	eventID := make([]byte, 16)
+	rand.Read(eventID)
+
+	event := map[string]interface{}{
+		"event_id":    hex.EncodeToString(eventID),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"level":       "error",
+		"platform":    "go",
+		"logger":      "unstructured_data",
+		"server_name": s.serverName,
+		"environment": s.environment,
+		"message":     message,
+		"tags":        tags,
+		"exception": map[string]interface{}{
+			"values": []map[string]interface{}{{"type": "panic", "value": message}},
+		},
+		"extra": map[string]interface{}{"stacktrace": string(stack)},
+	}
+
+	go func() {
+		body, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		req, err := http.NewRequest("POST", s.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Sentry-Auth", s.auth)
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			log.Printf("Error sending error report: %v", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			log.Printf("Error reporting endpoint returned status %d", resp.StatusCode)
+		}
+	}()
+}
@@ -0,0 +1,287 @@
+// Package retention purges documents older than the retention configured
+// for their source and type, archiving each before it is deleted.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/cron"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// ErrRunning is returned when a purge is asked for while one runs.
+var ErrRunning = errors.New("a purge is already running")
+
+// Report describes one purge. In a dry run, Purged counts the documents it
+// would have removed.
+type Report struct {
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	DryRun     bool           `json:"dry_run"`
+	Scanned    int            `json:"scanned"`
+	Purged     int            `json:"purged"`
+	ByPolicy   map[string]int `json:"by_policy"`
+	Archive    string         `json:"archive,omitempty"`
+	Uploaded   string         `json:"uploaded,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// Purger runs purges on the configured schedule or on request.
+type Purger struct {
+	storage  storage.Storage
+	config   config.RetentionConfig
+	schedule *cron.Schedule
+	client   *http.Client
+
+	running sync.Mutex
+	mu      sync.Mutex
+	last    *Report
+
+	// ctx is cancelled at shutdown, stopping the schedule and any purge
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New returns a Purger that has not started its schedule.
+func New(store storage.Storage, cfg config.RetentionConfig) (*Purger, error) {
+	schedule, err := cron.Parse(cfg.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Purger{
+		storage:  store,
+		config:   cfg,
+		schedule: schedule,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+		ctx:      ctx,
+		cancel:   cancel,
+	}, nil
+}
+
+// Start runs a purge at each time of the schedule, as a dry run when the
+// configuration asks for one.
+func (p *Purger) Start() {
+	log.Printf("Retention purge scheduled by %q (dry run: %v)", p.config.Schedule, p.config.DryRun)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			next := p.schedule.Next(time.Now())
+			if next.IsZero() {
+				return
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-p.ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			if _, err := p.Run(p.ctx, p.config.DryRun); err != nil {
+				log.Printf("Retention purge failed: %v", err)
+			}
+		}
+	}()
+}
+
+// Trigger starts a purge in the background, for requests that cannot wait
+// for it; its report becomes the LastReport.
+func (p *Purger) Trigger(dryRun bool) error {
+	if !p.running.TryLock() {
+		return ErrRunning
+	}
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.running.Unlock()
+		if _, err := p.run(p.ctx, dryRun); err != nil {
+			log.Printf("Retention purge failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops the schedule, cancelling a purge in progress, and waits
+// for it to return.
+func (p *Purger) Shutdown(ctx context.Context) error {
+	p.cancel()
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LastReport returns the report of the latest purge, or nil before the first.
+func (p *Purger) LastReport() *Report {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last
+}
+
+// Policy returns the configuration key that sets how long data is kept,
+// "default" when none does, and the days it is kept; 0 keeps it forever.
+func (p *Purger) Policy(data *models.UnstructuredData) (string, int) {
+	for _, key := range []string{data.Source + "/" + data.Type, data.Source, data.Type} {
+		if days, ok := p.config.Days[key]; ok {
+			return key, days
+		}
+	}
+	return "default", p.config.DefaultDays
+}
+
+// Run purges the documents past their retention. Each page of them is
+// archived and the archive synced to disk before the page is deleted, so a
+// failure part way leaves every removed document in the archive. A
+// document is aged from its publication, or its ingestion when it has no
+// publication time.
+func (p *Purger) Run(ctx context.Context, dryRun bool) (*Report, error) {
+	if !p.running.TryLock() {
+		return nil, ErrRunning
+	}
+	defer p.running.Unlock()
+	return p.run(ctx, dryRun)
+}
+
+func (p *Purger) run(ctx context.Context, dryRun bool) (*Report, error) {
+	now := time.Now().UTC()
+	report := &Report{StartedAt: now, DryRun: dryRun, ByPolicy: make(map[string]int)}
+	err := p.purge(ctx, now, report)
+	report.FinishedAt = time.Now().UTC()
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	p.mu.Lock()
+	p.last = report
+	p.mu.Unlock()
+
+	verb := "Purged"
+	if dryRun {
+		verb = "Would purge"
+	}
+	log.Printf("Retention: %s %d of %d documents scanned %v", verb, report.Purged, report.Scanned, report.ByPolicy)
+	return report, err
+}
+
+func (p *Purger) purge(ctx context.Context, now time.Time, report *Report) error {
+	// Nothing younger than the shortest retention can be due
+	shortest := p.config.DefaultDays
+	for _, days := range p.config.Days {
+		if days > 0 && (shortest == 0 || days < shortest) {
+			shortest = days
+		}
+	}
+	if shortest == 0 {
+		return nil
+	}
+	before := now.AddDate(0, 0, -shortest)
+
+	var out *archive
+	defer func() {
+		if out != nil {
+			out.abort()
+		}
+	}()
+
+	seen := make(map[string]bool)
+	var after *storage.Cursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := p.storage.ListUnstructuredData(ctx, storage.DataFilters{
+			DateTo: &before,
+			After:  after,
+			Limit:  p.config.BatchSize,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list documents: %w", err)
+		}
+
+		// A backend that ignores the page returns documents already seen
+		fresh := false
+		var expired []*models.UnstructuredData
+		for _, data := range page {
+			if seen[data.ID] {
+				continue
+			}
+			seen[data.ID] = true
+			fresh = true
+			report.Scanned++
+
+			policy, days := p.Policy(data)
+			at := data.PublishedAt
+			if at.IsZero() {
+				at = data.IngestedAt
+			}
+			if days > 0 && !at.IsZero() && at.Before(now.AddDate(0, 0, -days)) {
+				expired = append(expired, data)
+				report.ByPolicy[policy]++
+			}
+		}
+		if !fresh {
+			break
+		}
+
+		// A cursor keeps its place however many documents before it are deleted
+		after = storage.CursorOf(page[len(page)-1])
+		if report.DryRun || len(expired) == 0 {
+			report.Purged += len(expired)
+		} else {
+			if out == nil {
+				if out, err = createArchive(p.config.ArchiveDir, now); err != nil {
+					return err
+				}
+				report.Archive = out.path
+			}
+			if err := out.write(expired); err != nil {
+				return err
+			}
+			ids := make([]string, len(expired))
+			for i, data := range expired {
+				ids[i] = data.ID
+			}
+			if err := p.storage.DeleteUnstructuredData(ctx, ids); err != nil {
+				return fmt.Errorf("failed to delete documents: %w", err)
+			}
+			report.Purged += len(expired)
+		}
+		if len(page) < p.config.BatchSize {
+			break
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := out.close(); err != nil {
+		return err
+	}
+	archived := out
+	out = nil
+	if p.config.S3.Bucket != "" {
+		location, err := p.upload(ctx, archived.path)
+		if err != nil {
+			return fmt.Errorf("archive %s kept locally, upload failed: %w", archived.path, err)
+		}
+		report.Uploaded = location
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	pb "github.com/gaixen/CredTech/data_ingestion/unstructured_data/rpc/unstructuredv1"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+func toProto(data *models.UnstructuredData) (*pb.UnstructuredData, error) {
+	msg := &pb.UnstructuredData{
+		Id:          data.ID,
+		Source:      data.Source,
+		Type:        data.Type,
+		Title:       data.Title,
+		Content:     data.Content,
+		Url:         data.URL,
+		Author:      data.Author,
+		PublishedAt: timestamp(data.PublishedAt),
+		IngestedAt:  timestamp(data.IngestedAt),
+		Tags:        data.Tags,
+		Summary:     data.Summary,
+	}
+	if data.ProcessedAt != nil {
+		msg.ProcessedAt = timestamp(*data.ProcessedAt)
+	}
+	if len(data.Metadata) > 0 {
+		// Metadata holds whatever sources decoded, []string and time.Time
+		// among it, which structpb takes only as JSON
+		raw, err := json.Marshal(data.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		msg.Metadata = &structpb.Struct{}
+		if err := protojson.Unmarshal(raw, msg.Metadata); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range data.Entities {
+		msg.Entities = append(msg.Entities, &pb.Entity{
+			Name:       e.Name,
+			Type:       e.Type,
+			Confidence: e.Confidence,
+			StartPos:   int32(e.StartPos),
+			EndPos:     int32(e.EndPos),
+		})
+	}
+	if s := data.Sentiment; s != nil {
+		msg.Sentiment = &pb.SentimentScore{
+			Overall:   s.Overall,
+			Positive:  s.Positive,
+			Negative:  s.Negative,
+			Neutral:   s.Neutral,
+			Magnitude: s.Magnitude,
+			Model:     s.Model,
+			Aspects:   s.Aspects,
+		}
+	}
+	return msg, nil
+}
+
+func fromProto(msg *pb.UnstructuredData) *models.UnstructuredData {
+	data := &models.UnstructuredData{
+		ID:          msg.GetId(),
+		Source:      msg.GetSource(),
+		Type:        msg.GetType(),
+		Title:       msg.GetTitle(),
+		Content:     msg.GetContent(),
+		URL:         msg.GetUrl(),
+		Author:      msg.GetAuthor(),
+		PublishedAt: fromTimestamp(msg.GetPublishedAt()),
+		IngestedAt:  fromTimestamp(msg.GetIngestedAt()),
+		Tags:        msg.GetTags(),
+		Summary:     msg.GetSummary(),
+	}
+	if msg.GetProcessedAt() != nil {
+		at := fromTimestamp(msg.GetProcessedAt())
+		data.ProcessedAt = &at
+	}
+	if msg.GetMetadata() != nil {
+		data.Metadata = msg.GetMetadata().AsMap()
+	}
+	for _, e := range msg.GetEntities() {
+		data.Entities = append(data.Entities, models.Entity{
+			Name:       e.GetName(),
+			Type:       e.GetType(),
+			Confidence: e.GetConfidence(),
+			StartPos:   int(e.GetStartPos()),
+			EndPos:     int(e.GetEndPos()),
+		})
+	}
+	if s := msg.GetSentiment(); s != nil {
+		data.Sentiment = &models.SentimentScore{
+			Overall:   s.GetOverall(),
+			Positive:  s.GetPositive(),
+			Negative:  s.GetNegative(),
+			Neutral:   s.GetNeutral(),
+			Magnitude: s.GetMagnitude(),
+			Model:     s.GetModel(),
+			Aspects:   s.GetAspects(),
+		}
+	}
+	return data
+}
+
+// toFilters reads the filters of a list request; the limit is settled by
+// the caller.
+func toFilters(req *pb.ListUnstructuredDataRequest) storage.DataFilters {
+	filters := storage.DataFilters{
+		Source:      req.GetSource(),
+		Type:        req.GetType(),
+		Symbols:     req.GetSymbols(),
+		Tags:        req.GetTags(),
+		CompanyID:   req.GetCompanyId(),
+		ClusterID:   req.GetClusterId(),
+		Deduplicate: req.GetDeduplicate(),
+		Query:       req.GetQuery(),
+		Offset:      int(req.GetOffset()),
+	}
+	if req.GetDateFrom() != nil {
+		from := fromTimestamp(req.GetDateFrom())
+		filters.DateFrom = &from
+	}
+	if req.GetDateTo() != nil {
+		to := fromTimestamp(req.GetDateTo())
+		filters.DateTo = &to
+	}
+	return filters
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
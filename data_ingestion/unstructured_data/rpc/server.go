@@ -0,0 +1,295 @@
+// Package rpc serves stored documents over gRPC to the services that
+// consume them, the scoring engine and research, with the service of
+// proto/unstructured/v1 at the repository root. Lists stream their
+// documents in batches, so a large window never has to fit in one message.
+package rpc
+
+//go:generate protoc -I ../../../proto --go_out=. --go_opt=module=github.com/gaixen/CredTech/data_ingestion/unstructured_data/rpc --go-grpc_out=. --go-grpc_opt=module=github.com/gaixen/CredTech/data_ingestion/unstructured_data/rpc unstructured/v1/unstructured_data.proto
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+	pb "github.com/gaixen/CredTech/data_ingestion/unstructured_data/rpc/unstructuredv1"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/tracing"
+)
+
+// Server serves UnstructuredDataService.
+type Server struct {
+	pb.UnimplementedUnstructuredDataServiceServer
+
+	config  config.GRPCConfig
+	storage storage.Storage
+	server  *grpc.Server
+}
+
+func NewServer(cfg config.GRPCConfig, store storage.Storage) *Server {
+	s := &Server{config: cfg, storage: store}
+	s.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.unaryInterceptor),
+		grpc.ChainStreamInterceptor(s.streamInterceptor),
+	)
+	pb.RegisterUnstructuredDataServiceServer(s.server, s)
+	return s
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.config.Addr)
+	if err != nil {
+		return err
+	}
+	log.Printf("Starting gRPC API on %s", s.config.Addr)
+	go func() {
+		if err := s.server.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			log.Printf("gRPC API stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+// Stop lets calls in progress finish until ctx is done, then cuts them off.
+func (s *Server) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
+
+// ListUnstructuredData streams the matching documents in messages of the
+// requested batch size, up to the configured one. While more follow, the
+// last message carries the cursor to list them with.
+func (s *Server) ListUnstructuredData(req *pb.ListUnstructuredDataRequest, stream pb.UnstructuredDataService_ListUnstructuredDataServer) error {
+	filters := toFilters(req)
+	filters.Limit = s.config.DefaultLimit
+	if req.GetLimit() < 0 || int(req.GetLimit()) > s.config.MaxLimit {
+		return status.Errorf(codes.InvalidArgument, "limit must be between 1 and %d", s.config.MaxLimit)
+	}
+	if req.GetLimit() > 0 {
+		filters.Limit = int(req.GetLimit())
+	}
+	if filters.Offset < 0 || req.GetBatchSize() < 0 {
+		return status.Error(codes.InvalidArgument, "offset and batch size must not be negative")
+	}
+	if filters.DateFrom != nil && filters.DateTo != nil && filters.DateTo.Before(*filters.DateFrom) {
+		return status.Error(codes.InvalidArgument, "date_to is before date_from")
+	}
+	if req.GetCursor() != "" {
+		if filters.Offset > 0 {
+			return status.Error(codes.InvalidArgument, "cursor and offset cannot be combined")
+		}
+		after, err := storage.ParseCursor(req.GetCursor())
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		filters.After = after
+	}
+	batch := s.config.BatchSize
+	if n := int(req.GetBatchSize()); n > 0 && n < batch {
+		batch = n
+	}
+
+	// One past the limit tells whether more follow
+	limit := filters.Limit
+	filters.Limit++
+	docs, err := s.storage.ListUnstructuredData(stream.Context(), filters)
+	if err != nil {
+		log.Printf("gRPC API failed to list documents: %v", err)
+		return status.Error(codes.Internal, "failed to list documents")
+	}
+	var next string
+	if len(docs) > limit {
+		docs = docs[:limit]
+		next = storage.CursorOf(docs[limit-1]).String()
+	}
+	for start := 0; start < len(docs); start += batch {
+		end := min(start+batch, len(docs))
+		resp := &pb.ListUnstructuredDataResponse{Data: make([]*pb.UnstructuredData, 0, end-start)}
+		if end == len(docs) {
+			resp.NextCursor = next
+		}
+		for _, doc := range docs[start:end] {
+			msg, err := toProto(doc)
+			if err != nil {
+				log.Printf("gRPC API failed to encode %s: %v", doc.ID, err)
+				return status.Errorf(codes.Internal, "failed to encode %s", doc.ID)
+			}
+			resp.Data = append(resp.Data, msg)
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) GetUnstructuredData(ctx context.Context, req *pb.GetUnstructuredDataRequest) (*pb.UnstructuredData, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	var doc *models.UnstructuredData
+	var err error
+	if req.GetAsOf() != nil {
+		doc, err = s.storage.GetUnstructuredDataAsOf(ctx, req.GetId(), req.GetAsOf().AsTime())
+	} else {
+		doc, err = s.storage.GetUnstructuredData(ctx, req.GetId())
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		log.Printf("gRPC API failed to load %s: %v", req.GetId(), err)
+		return nil, status.Error(codes.Internal, "failed to load the document")
+	}
+	msg, err := toProto(doc)
+	if err != nil {
+		log.Printf("gRPC API failed to encode %s: %v", doc.ID, err)
+		return nil, status.Errorf(codes.Internal, "failed to encode %s", doc.ID)
+	}
+	return msg, nil
+}
+
+// SaveUnstructuredData stores a document through the same storage sources
+// save to, so it is checked, deduplicated and processed like theirs. A
+// document without an ID is given one from its source and URL, or its
+// title and content, which makes saving it again an update.
+func (s *Server) SaveUnstructuredData(ctx context.Context, req *pb.SaveUnstructuredDataRequest) (*pb.SaveUnstructuredDataResponse, error) {
+	if s.config.Token == "" {
+		return nil, status.Error(codes.PermissionDenied, "saves need GRPC_TOKEN to be configured")
+	}
+	if req.GetData() == nil {
+		return nil, status.Error(codes.InvalidArgument, "data is required")
+	}
+	data := fromProto(req.GetData())
+	if data.Source == "" || data.Type == "" {
+		return nil, status.Error(codes.InvalidArgument, "source and type are required")
+	}
+	if strings.TrimSpace(data.Title) == "" && strings.TrimSpace(data.Content) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title or content is required")
+	}
+	if data.ID == "" {
+		key := data.URL
+		if key == "" {
+			key = data.Title + "\n" + data.Content
+		}
+		hash := sha256.Sum256([]byte(key))
+		data.ID = fmt.Sprintf("%s-%x", data.Source, hash[:8])
+	}
+	if data.IngestedAt.IsZero() {
+		data.IngestedAt = time.Now()
+	}
+	if data.PublishedAt.IsZero() {
+		data.PublishedAt = data.IngestedAt
+	}
+
+	if err := s.storage.SaveUnstructuredData(ctx, data); err != nil {
+		log.Printf("gRPC API failed to save %s: %v", data.ID, err)
+		return nil, status.Errorf(codes.Internal, "save %s: %v", data.ID, err)
+	}
+	return &pb.SaveUnstructuredDataResponse{Id: data.ID}, nil
+}
+
+func (s *Server) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+	ctx, span := startCall(ctx, info.FullMethod)
+	defer func() { endCall(span, info.FullMethod, recover(), &err) }()
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx, span := startCall(stream.Context(), info.FullMethod)
+	defer func() { endCall(span, info.FullMethod, recover(), &err) }()
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+	return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+}
+
+// authorize checks the bearer token of the call's metadata when one is
+// configured.
+func (s *Server) authorize(ctx context.Context) error {
+	if s.config.Token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token := strings.TrimPrefix(value, "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "gRPC token required")
+}
+
+// startCall begins the server span of a call, continuing the trace of the
+// caller's traceparent metadata.
+func startCall(ctx context.Context, method string) (context.Context, *tracing.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		header := make(http.Header)
+		for _, value := range md.Get("traceparent") {
+			header.Add("traceparent", value)
+		}
+		ctx = tracing.Extract(ctx, header)
+	}
+	service, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return tracing.StartServer(ctx, strings.TrimPrefix(method, "/"),
+		tracing.String("rpc.system", "grpc"),
+		tracing.String("rpc.service", service),
+		tracing.String("rpc.method", name),
+	)
+}
+
+// endCall reports a panic of the call as an internal error and ends its
+// span with the status the call returned.
+func endCall(span *tracing.Span, method string, recovered interface{}, err *error) {
+	if recovered != nil {
+		reporting.CapturePanic(recovered, map[string]string{
+			"component": "grpc",
+			"method":    method,
+		})
+		*err = status.Error(codes.Internal, "internal server error")
+	}
+	code := status.Code(*err)
+	span.SetAttributes(tracing.Int("rpc.grpc.status_code", int(code)))
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss:
+		span.RecordError(*err)
+	}
+	span.End()
+}
+
+// contextStream hands the handler the context carrying the call's span.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
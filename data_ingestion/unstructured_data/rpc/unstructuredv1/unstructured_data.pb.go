@@ -0,0 +1,861 @@
+// Documents of the unstructured data ingestion service, for the services
+// that consume them over gRPC: the scoring engine and research. The Go
+// server lives in data_ingestion/unstructured_data/rpc; other languages
+// generate their clients from this file.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: unstructured/v1/unstructured_data.proto
+
+package unstructuredv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type UnstructuredData struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Id     string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Source string                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	// news, social, earnings_transcript, press_release, legal, ...
+	Type        string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Title       string                 `protobuf:"bytes,4,opt,name=title,proto3" json:"title,omitempty"`
+	Content     string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	Url         string                 `protobuf:"bytes,6,opt,name=url,proto3" json:"url,omitempty"`
+	Author      string                 `protobuf:"bytes,7,opt,name=author,proto3" json:"author,omitempty"`
+	PublishedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	IngestedAt  *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=ingested_at,json=ingestedAt,proto3" json:"ingested_at,omitempty"`
+	Metadata    *structpb.Struct       `protobuf:"bytes,10,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	Tags        []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	Entities    []*Entity              `protobuf:"bytes,12,rep,name=entities,proto3" json:"entities,omitempty"`
+	Sentiment   *SentimentScore        `protobuf:"bytes,13,opt,name=sentiment,proto3" json:"sentiment,omitempty"`
+	// Credit-relevant summary from the summarization job
+	Summary       string                 `protobuf:"bytes,14,opt,name=summary,proto3" json:"summary,omitempty"`
+	ProcessedAt   *timestamppb.Timestamp `protobuf:"bytes,15,opt,name=processed_at,json=processedAt,proto3" json:"processed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnstructuredData) Reset() {
+	*x = UnstructuredData{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnstructuredData) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnstructuredData) ProtoMessage() {}
+
+func (x *UnstructuredData) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnstructuredData.ProtoReflect.Descriptor instead.
+func (*UnstructuredData) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *UnstructuredData) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+func (x *UnstructuredData) GetIngestedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.IngestedAt
+	}
+	return nil
+}
+
+func (x *UnstructuredData) GetMetadata() *structpb.Struct {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *UnstructuredData) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *UnstructuredData) GetEntities() []*Entity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+func (x *UnstructuredData) GetSentiment() *SentimentScore {
+	if x != nil {
+		return x.Sentiment
+	}
+	return nil
+}
+
+func (x *UnstructuredData) GetSummary() string {
+	if x != nil {
+		return x.Summary
+	}
+	return ""
+}
+
+func (x *UnstructuredData) GetProcessedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProcessedAt
+	}
+	return nil
+}
+
+type Entity struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// PERSON, ORG, MONEY, DATE, ...
+	Type          string  `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Confidence    float64 `protobuf:"fixed64,3,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	StartPos      int32   `protobuf:"varint,4,opt,name=start_pos,json=startPos,proto3" json:"start_pos,omitempty"`
+	EndPos        int32   `protobuf:"varint,5,opt,name=end_pos,json=endPos,proto3" json:"end_pos,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Entity) Reset() {
+	*x = Entity{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Entity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Entity) ProtoMessage() {}
+
+func (x *Entity) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Entity.ProtoReflect.Descriptor instead.
+func (*Entity) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Entity) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Entity) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Entity) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Entity) GetStartPos() int32 {
+	if x != nil {
+		return x.StartPos
+	}
+	return 0
+}
+
+func (x *Entity) GetEndPos() int32 {
+	if x != nil {
+		return x.EndPos
+	}
+	return 0
+}
+
+type SentimentScore struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// -1 to 1
+	Overall   float64 `protobuf:"fixed64,1,opt,name=overall,proto3" json:"overall,omitempty"`
+	Positive  float64 `protobuf:"fixed64,2,opt,name=positive,proto3" json:"positive,omitempty"`
+	Negative  float64 `protobuf:"fixed64,3,opt,name=negative,proto3" json:"negative,omitempty"`
+	Neutral   float64 `protobuf:"fixed64,4,opt,name=neutral,proto3" json:"neutral,omitempty"`
+	Magnitude float64 `protobuf:"fixed64,5,opt,name=magnitude,proto3" json:"magnitude,omitempty"`
+	// What produced the score; empty when the source supplied it
+	Model         string             `protobuf:"bytes,6,opt,name=model,proto3" json:"model,omitempty"`
+	Aspects       map[string]float64 `protobuf:"bytes,7,rep,name=aspects,proto3" json:"aspects,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SentimentScore) Reset() {
+	*x = SentimentScore{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SentimentScore) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SentimentScore) ProtoMessage() {}
+
+func (x *SentimentScore) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SentimentScore.ProtoReflect.Descriptor instead.
+func (*SentimentScore) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SentimentScore) GetOverall() float64 {
+	if x != nil {
+		return x.Overall
+	}
+	return 0
+}
+
+func (x *SentimentScore) GetPositive() float64 {
+	if x != nil {
+		return x.Positive
+	}
+	return 0
+}
+
+func (x *SentimentScore) GetNegative() float64 {
+	if x != nil {
+		return x.Negative
+	}
+	return 0
+}
+
+func (x *SentimentScore) GetNeutral() float64 {
+	if x != nil {
+		return x.Neutral
+	}
+	return 0
+}
+
+func (x *SentimentScore) GetMagnitude() float64 {
+	if x != nil {
+		return x.Magnitude
+	}
+	return 0
+}
+
+func (x *SentimentScore) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *SentimentScore) GetAspects() map[string]float64 {
+	if x != nil {
+		return x.Aspects
+	}
+	return nil
+}
+
+type ListUnstructuredDataRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Source string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Type   string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// Documents with any of the symbols or tags
+	Symbols   []string               `protobuf:"bytes,3,rep,name=symbols,proto3" json:"symbols,omitempty"`
+	Tags      []string               `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
+	DateFrom  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=date_from,json=dateFrom,proto3" json:"date_from,omitempty"`
+	DateTo    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=date_to,json=dateTo,proto3" json:"date_to,omitempty"`
+	CompanyId string                 `protobuf:"bytes,7,opt,name=company_id,json=companyId,proto3" json:"company_id,omitempty"`
+	ClusterId string                 `protobuf:"bytes,8,opt,name=cluster_id,json=clusterId,proto3" json:"cluster_id,omitempty"`
+	// One document per near-duplicate cluster, its first published
+	Deduplicate bool `protobuf:"varint,9,opt,name=deduplicate,proto3" json:"deduplicate,omitempty"`
+	// Web search syntax: words are all required, "quoted phrases" match in
+	// order, or alternates and -word excludes
+	Query string `protobuf:"bytes,10,opt,name=query,proto3" json:"query,omitempty"`
+	// Zero lists up to the server's default
+	Limit int32 `protobuf:"varint,11,opt,name=limit,proto3" json:"limit,omitempty"`
+	// Slows down the deeper it goes; prefer cursor
+	Offset int32 `protobuf:"varint,12,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Documents per response message; zero takes the server's default
+	BatchSize int32 `protobuf:"varint,13,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	// next_cursor of a previous list, to continue after its last document
+	Cursor        string `protobuf:"bytes,14,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUnstructuredDataRequest) Reset() {
+	*x = ListUnstructuredDataRequest{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUnstructuredDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUnstructuredDataRequest) ProtoMessage() {}
+
+func (x *ListUnstructuredDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUnstructuredDataRequest.ProtoReflect.Descriptor instead.
+func (*ListUnstructuredDataRequest) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListUnstructuredDataRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *ListUnstructuredDataRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ListUnstructuredDataRequest) GetSymbols() []string {
+	if x != nil {
+		return x.Symbols
+	}
+	return nil
+}
+
+func (x *ListUnstructuredDataRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListUnstructuredDataRequest) GetDateFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateFrom
+	}
+	return nil
+}
+
+func (x *ListUnstructuredDataRequest) GetDateTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTo
+	}
+	return nil
+}
+
+func (x *ListUnstructuredDataRequest) GetCompanyId() string {
+	if x != nil {
+		return x.CompanyId
+	}
+	return ""
+}
+
+func (x *ListUnstructuredDataRequest) GetClusterId() string {
+	if x != nil {
+		return x.ClusterId
+	}
+	return ""
+}
+
+func (x *ListUnstructuredDataRequest) GetDeduplicate() bool {
+	if x != nil {
+		return x.Deduplicate
+	}
+	return false
+}
+
+func (x *ListUnstructuredDataRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ListUnstructuredDataRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListUnstructuredDataRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListUnstructuredDataRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *ListUnstructuredDataRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type ListUnstructuredDataResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Data  []*UnstructuredData    `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+	// Set on the last message while more documents follow the listed ones
+	NextCursor    string `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListUnstructuredDataResponse) Reset() {
+	*x = ListUnstructuredDataResponse{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListUnstructuredDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListUnstructuredDataResponse) ProtoMessage() {}
+
+func (x *ListUnstructuredDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListUnstructuredDataResponse.ProtoReflect.Descriptor instead.
+func (*ListUnstructuredDataResponse) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListUnstructuredDataResponse) GetData() []*UnstructuredData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ListUnstructuredDataResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type GetUnstructuredDataRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The document as it was stored at this time, if set
+	AsOf          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=as_of,json=asOf,proto3" json:"as_of,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUnstructuredDataRequest) Reset() {
+	*x = GetUnstructuredDataRequest{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUnstructuredDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUnstructuredDataRequest) ProtoMessage() {}
+
+func (x *GetUnstructuredDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUnstructuredDataRequest.ProtoReflect.Descriptor instead.
+func (*GetUnstructuredDataRequest) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetUnstructuredDataRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetUnstructuredDataRequest) GetAsOf() *timestamppb.Timestamp {
+	if x != nil {
+		return x.AsOf
+	}
+	return nil
+}
+
+type SaveUnstructuredDataRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          *UnstructuredData      `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveUnstructuredDataRequest) Reset() {
+	*x = SaveUnstructuredDataRequest{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveUnstructuredDataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveUnstructuredDataRequest) ProtoMessage() {}
+
+func (x *SaveUnstructuredDataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveUnstructuredDataRequest.ProtoReflect.Descriptor instead.
+func (*SaveUnstructuredDataRequest) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SaveUnstructuredDataRequest) GetData() *UnstructuredData {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SaveUnstructuredDataResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveUnstructuredDataResponse) Reset() {
+	*x = SaveUnstructuredDataResponse{}
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveUnstructuredDataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveUnstructuredDataResponse) ProtoMessage() {}
+
+func (x *SaveUnstructuredDataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_unstructured_v1_unstructured_data_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveUnstructuredDataResponse.ProtoReflect.Descriptor instead.
+func (*SaveUnstructuredDataResponse) Descriptor() ([]byte, []int) {
+	return file_unstructured_v1_unstructured_data_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SaveUnstructuredDataResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+var File_unstructured_v1_unstructured_data_proto protoreflect.FileDescriptor
+
+const file_unstructured_v1_unstructured_data_proto_rawDesc = "" +
+	"\n" +
+	"'unstructured/v1/unstructured_data.proto\x12\x18credtech.unstructured.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcc\x04\n" +
+	"\x10UnstructuredData\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06source\x18\x02 \x01(\tR\x06source\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05title\x18\x04 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x12\x10\n" +
+	"\x03url\x18\x06 \x01(\tR\x03url\x12\x16\n" +
+	"\x06author\x18\a \x01(\tR\x06author\x12=\n" +
+	"\fpublished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x12;\n" +
+	"\vingested_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"ingestedAt\x123\n" +
+	"\bmetadata\x18\n" +
+	" \x01(\v2\x17.google.protobuf.StructR\bmetadata\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12<\n" +
+	"\bentities\x18\f \x03(\v2 .credtech.unstructured.v1.EntityR\bentities\x12F\n" +
+	"\tsentiment\x18\r \x01(\v2(.credtech.unstructured.v1.SentimentScoreR\tsentiment\x12\x18\n" +
+	"\asummary\x18\x0e \x01(\tR\asummary\x12=\n" +
+	"\fprocessed_at\x18\x0f \x01(\v2\x1a.google.protobuf.TimestampR\vprocessedAt\"\x86\x01\n" +
+	"\x06Entity\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x03 \x01(\x01R\n" +
+	"confidence\x12\x1b\n" +
+	"\tstart_pos\x18\x04 \x01(\x05R\bstartPos\x12\x17\n" +
+	"\aend_pos\x18\x05 \x01(\x05R\x06endPos\"\xbd\x02\n" +
+	"\x0eSentimentScore\x12\x18\n" +
+	"\aoverall\x18\x01 \x01(\x01R\aoverall\x12\x1a\n" +
+	"\bpositive\x18\x02 \x01(\x01R\bpositive\x12\x1a\n" +
+	"\bnegative\x18\x03 \x01(\x01R\bnegative\x12\x18\n" +
+	"\aneutral\x18\x04 \x01(\x01R\aneutral\x12\x1c\n" +
+	"\tmagnitude\x18\x05 \x01(\x01R\tmagnitude\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05model\x12O\n" +
+	"\aaspects\x18\a \x03(\v25.credtech.unstructured.v1.SentimentScore.AspectsEntryR\aaspects\x1a:\n" +
+	"\fAspectsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"\xc0\x03\n" +
+	"\x1bListUnstructuredDataRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x18\n" +
+	"\asymbols\x18\x03 \x03(\tR\asymbols\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\x127\n" +
+	"\tdate_from\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bdateFrom\x123\n" +
+	"\adate_to\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x06dateTo\x12\x1d\n" +
+	"\n" +
+	"company_id\x18\a \x01(\tR\tcompanyId\x12\x1d\n" +
+	"\n" +
+	"cluster_id\x18\b \x01(\tR\tclusterId\x12 \n" +
+	"\vdeduplicate\x18\t \x01(\bR\vdeduplicate\x12\x14\n" +
+	"\x05query\x18\n" +
+	" \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\v \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\f \x01(\x05R\x06offset\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\r \x01(\x05R\tbatchSize\x12\x16\n" +
+	"\x06cursor\x18\x0e \x01(\tR\x06cursor\"\x7f\n" +
+	"\x1cListUnstructuredDataResponse\x12>\n" +
+	"\x04data\x18\x01 \x03(\v2*.credtech.unstructured.v1.UnstructuredDataR\x04data\x12\x1f\n" +
+	"\vnext_cursor\x18\x02 \x01(\tR\n" +
+	"nextCursor\"]\n" +
+	"\x1aGetUnstructuredDataRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12/\n" +
+	"\x05as_of\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04asOf\"]\n" +
+	"\x1bSaveUnstructuredDataRequest\x12>\n" +
+	"\x04data\x18\x01 \x01(\v2*.credtech.unstructured.v1.UnstructuredDataR\x04data\".\n" +
+	"\x1cSaveUnstructuredDataResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id2\xa4\x03\n" +
+	"\x17UnstructuredDataService\x12\x87\x01\n" +
+	"\x14ListUnstructuredData\x125.credtech.unstructured.v1.ListUnstructuredDataRequest\x1a6.credtech.unstructured.v1.ListUnstructuredDataResponse0\x01\x12w\n" +
+	"\x13GetUnstructuredData\x124.credtech.unstructured.v1.GetUnstructuredDataRequest\x1a*.credtech.unstructured.v1.UnstructuredData\x12\x85\x01\n" +
+	"\x14SaveUnstructuredData\x125.credtech.unstructured.v1.SaveUnstructuredDataRequest\x1a6.credtech.unstructured.v1.SaveUnstructuredDataResponseB_Z]github.com/gaixen/CredTech/data_ingestion/unstructured_data/rpc/unstructuredv1;unstructuredv1b\x06proto3"
+
+var (
+	file_unstructured_v1_unstructured_data_proto_rawDescOnce sync.Once
+	file_unstructured_v1_unstructured_data_proto_rawDescData []byte
+)
+
+func file_unstructured_v1_unstructured_data_proto_rawDescGZIP() []byte {
+	file_unstructured_v1_unstructured_data_proto_rawDescOnce.Do(func() {
+		file_unstructured_v1_unstructured_data_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_unstructured_v1_unstructured_data_proto_rawDesc), len(file_unstructured_v1_unstructured_data_proto_rawDesc)))
+	})
+	return file_unstructured_v1_unstructured_data_proto_rawDescData
+}
+
+var file_unstructured_v1_unstructured_data_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_unstructured_v1_unstructured_data_proto_goTypes = []any{
+	(*UnstructuredData)(nil),             // 0: credtech.unstructured.v1.UnstructuredData
+	(*Entity)(nil),                       // 1: credtech.unstructured.v1.Entity
+	(*SentimentScore)(nil),               // 2: credtech.unstructured.v1.SentimentScore
+	(*ListUnstructuredDataRequest)(nil),  // 3: credtech.unstructured.v1.ListUnstructuredDataRequest
+	(*ListUnstructuredDataResponse)(nil), // 4: credtech.unstructured.v1.ListUnstructuredDataResponse
+	(*GetUnstructuredDataRequest)(nil),   // 5: credtech.unstructured.v1.GetUnstructuredDataRequest
+	(*SaveUnstructuredDataRequest)(nil),  // 6: credtech.unstructured.v1.SaveUnstructuredDataRequest
+	(*SaveUnstructuredDataResponse)(nil), // 7: credtech.unstructured.v1.SaveUnstructuredDataResponse
+	nil,                                  // 8: credtech.unstructured.v1.SentimentScore.AspectsEntry
+	(*timestamppb.Timestamp)(nil),        // 9: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),              // 10: google.protobuf.Struct
+}
+var file_unstructured_v1_unstructured_data_proto_depIdxs = []int32{
+	9,  // 0: credtech.unstructured.v1.UnstructuredData.published_at:type_name -> google.protobuf.Timestamp
+	9,  // 1: credtech.unstructured.v1.UnstructuredData.ingested_at:type_name -> google.protobuf.Timestamp
+	10, // 2: credtech.unstructured.v1.UnstructuredData.metadata:type_name -> google.protobuf.Struct
+	1,  // 3: credtech.unstructured.v1.UnstructuredData.entities:type_name -> credtech.unstructured.v1.Entity
+	2,  // 4: credtech.unstructured.v1.UnstructuredData.sentiment:type_name -> credtech.unstructured.v1.SentimentScore
+	9,  // 5: credtech.unstructured.v1.UnstructuredData.processed_at:type_name -> google.protobuf.Timestamp
+	8,  // 6: credtech.unstructured.v1.SentimentScore.aspects:type_name -> credtech.unstructured.v1.SentimentScore.AspectsEntry
+	9,  // 7: credtech.unstructured.v1.ListUnstructuredDataRequest.date_from:type_name -> google.protobuf.Timestamp
+	9,  // 8: credtech.unstructured.v1.ListUnstructuredDataRequest.date_to:type_name -> google.protobuf.Timestamp
+	0,  // 9: credtech.unstructured.v1.ListUnstructuredDataResponse.data:type_name -> credtech.unstructured.v1.UnstructuredData
+	9,  // 10: credtech.unstructured.v1.GetUnstructuredDataRequest.as_of:type_name -> google.protobuf.Timestamp
+	0,  // 11: credtech.unstructured.v1.SaveUnstructuredDataRequest.data:type_name -> credtech.unstructured.v1.UnstructuredData
+	3,  // 12: credtech.unstructured.v1.UnstructuredDataService.ListUnstructuredData:input_type -> credtech.unstructured.v1.ListUnstructuredDataRequest
+	5,  // 13: credtech.unstructured.v1.UnstructuredDataService.GetUnstructuredData:input_type -> credtech.unstructured.v1.GetUnstructuredDataRequest
+	6,  // 14: credtech.unstructured.v1.UnstructuredDataService.SaveUnstructuredData:input_type -> credtech.unstructured.v1.SaveUnstructuredDataRequest
+	4,  // 15: credtech.unstructured.v1.UnstructuredDataService.ListUnstructuredData:output_type -> credtech.unstructured.v1.ListUnstructuredDataResponse
+	0,  // 16: credtech.unstructured.v1.UnstructuredDataService.GetUnstructuredData:output_type -> credtech.unstructured.v1.UnstructuredData
+	7,  // 17: credtech.unstructured.v1.UnstructuredDataService.SaveUnstructuredData:output_type -> credtech.unstructured.v1.SaveUnstructuredDataResponse
+	15, // [15:18] is the sub-list for method output_type
+	12, // [12:15] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_unstructured_v1_unstructured_data_proto_init() }
+func file_unstructured_v1_unstructured_data_proto_init() {
+	if File_unstructured_v1_unstructured_data_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_unstructured_v1_unstructured_data_proto_rawDesc), len(file_unstructured_v1_unstructured_data_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_unstructured_v1_unstructured_data_proto_goTypes,
+		DependencyIndexes: file_unstructured_v1_unstructured_data_proto_depIdxs,
+		MessageInfos:      file_unstructured_v1_unstructured_data_proto_msgTypes,
+	}.Build()
+	File_unstructured_v1_unstructured_data_proto = out.File
+	file_unstructured_v1_unstructured_data_proto_goTypes = nil
+	file_unstructured_v1_unstructured_data_proto_depIdxs = nil
+}
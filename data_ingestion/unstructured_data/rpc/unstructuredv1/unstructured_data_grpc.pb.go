@@ -0,0 +1,217 @@
+// Documents of the unstructured data ingestion service, for the services
+// that consume them over gRPC: the scoring engine and research. The Go
+// server lives in data_ingestion/unstructured_data/rpc; other languages
+// generate their clients from this file.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: unstructured/v1/unstructured_data.proto
+
+package unstructuredv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	UnstructuredDataService_ListUnstructuredData_FullMethodName = "/credtech.unstructured.v1.UnstructuredDataService/ListUnstructuredData"
+	UnstructuredDataService_GetUnstructuredData_FullMethodName  = "/credtech.unstructured.v1.UnstructuredDataService/GetUnstructuredData"
+	UnstructuredDataService_SaveUnstructuredData_FullMethodName = "/credtech.unstructured.v1.UnstructuredDataService/SaveUnstructuredData"
+)
+
+// UnstructuredDataServiceClient is the client API for UnstructuredDataService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type UnstructuredDataServiceClient interface {
+	// ListUnstructuredData streams the documents matching the request, newest
+	// published first, in messages of at most batch_size documents.
+	ListUnstructuredData(ctx context.Context, in *ListUnstructuredDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListUnstructuredDataResponse], error)
+	// GetUnstructuredData returns one document; NOT_FOUND if there is none.
+	GetUnstructuredData(ctx context.Context, in *GetUnstructuredDataRequest, opts ...grpc.CallOption) (*UnstructuredData, error)
+	// SaveUnstructuredData stores a document as a source would, so it is
+	// processed like one. An empty id is assigned by the service.
+	SaveUnstructuredData(ctx context.Context, in *SaveUnstructuredDataRequest, opts ...grpc.CallOption) (*SaveUnstructuredDataResponse, error)
+}
+
+type unstructuredDataServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewUnstructuredDataServiceClient(cc grpc.ClientConnInterface) UnstructuredDataServiceClient {
+	return &unstructuredDataServiceClient{cc}
+}
+
+func (c *unstructuredDataServiceClient) ListUnstructuredData(ctx context.Context, in *ListUnstructuredDataRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ListUnstructuredDataResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UnstructuredDataService_ServiceDesc.Streams[0], UnstructuredDataService_ListUnstructuredData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ListUnstructuredDataRequest, ListUnstructuredDataResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UnstructuredDataService_ListUnstructuredDataClient = grpc.ServerStreamingClient[ListUnstructuredDataResponse]
+
+func (c *unstructuredDataServiceClient) GetUnstructuredData(ctx context.Context, in *GetUnstructuredDataRequest, opts ...grpc.CallOption) (*UnstructuredData, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnstructuredData)
+	err := c.cc.Invoke(ctx, UnstructuredDataService_GetUnstructuredData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *unstructuredDataServiceClient) SaveUnstructuredData(ctx context.Context, in *SaveUnstructuredDataRequest, opts ...grpc.CallOption) (*SaveUnstructuredDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveUnstructuredDataResponse)
+	err := c.cc.Invoke(ctx, UnstructuredDataService_SaveUnstructuredData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UnstructuredDataServiceServer is the server API for UnstructuredDataService service.
+// All implementations must embed UnimplementedUnstructuredDataServiceServer
+// for forward compatibility.
+type UnstructuredDataServiceServer interface {
+	// ListUnstructuredData streams the documents matching the request, newest
+	// published first, in messages of at most batch_size documents.
+	ListUnstructuredData(*ListUnstructuredDataRequest, grpc.ServerStreamingServer[ListUnstructuredDataResponse]) error
+	// GetUnstructuredData returns one document; NOT_FOUND if there is none.
+	GetUnstructuredData(context.Context, *GetUnstructuredDataRequest) (*UnstructuredData, error)
+	// SaveUnstructuredData stores a document as a source would, so it is
+	// processed like one. An empty id is assigned by the service.
+	SaveUnstructuredData(context.Context, *SaveUnstructuredDataRequest) (*SaveUnstructuredDataResponse, error)
+	mustEmbedUnimplementedUnstructuredDataServiceServer()
+}
+
+// UnimplementedUnstructuredDataServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedUnstructuredDataServiceServer struct{}
+
+func (UnimplementedUnstructuredDataServiceServer) ListUnstructuredData(*ListUnstructuredDataRequest, grpc.ServerStreamingServer[ListUnstructuredDataResponse]) error {
+	return status.Error(codes.Unimplemented, "method ListUnstructuredData not implemented")
+}
+func (UnimplementedUnstructuredDataServiceServer) GetUnstructuredData(context.Context, *GetUnstructuredDataRequest) (*UnstructuredData, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetUnstructuredData not implemented")
+}
+func (UnimplementedUnstructuredDataServiceServer) SaveUnstructuredData(context.Context, *SaveUnstructuredDataRequest) (*SaveUnstructuredDataResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SaveUnstructuredData not implemented")
+}
+func (UnimplementedUnstructuredDataServiceServer) mustEmbedUnimplementedUnstructuredDataServiceServer() {
+}
+func (UnimplementedUnstructuredDataServiceServer) testEmbeddedByValue() {}
+
+// UnsafeUnstructuredDataServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to UnstructuredDataServiceServer will
+// result in compilation errors.
+type UnsafeUnstructuredDataServiceServer interface {
+	mustEmbedUnimplementedUnstructuredDataServiceServer()
+}
+
+func RegisterUnstructuredDataServiceServer(s grpc.ServiceRegistrar, srv UnstructuredDataServiceServer) {
+	// If the following call panics, it indicates UnimplementedUnstructuredDataServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&UnstructuredDataService_ServiceDesc, srv)
+}
+
+func _UnstructuredDataService_ListUnstructuredData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ListUnstructuredDataRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UnstructuredDataServiceServer).ListUnstructuredData(m, &grpc.GenericServerStream[ListUnstructuredDataRequest, ListUnstructuredDataResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UnstructuredDataService_ListUnstructuredDataServer = grpc.ServerStreamingServer[ListUnstructuredDataResponse]
+
+func _UnstructuredDataService_GetUnstructuredData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUnstructuredDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UnstructuredDataServiceServer).GetUnstructuredData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UnstructuredDataService_GetUnstructuredData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UnstructuredDataServiceServer).GetUnstructuredData(ctx, req.(*GetUnstructuredDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UnstructuredDataService_SaveUnstructuredData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveUnstructuredDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UnstructuredDataServiceServer).SaveUnstructuredData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UnstructuredDataService_SaveUnstructuredData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UnstructuredDataServiceServer).SaveUnstructuredData(ctx, req.(*SaveUnstructuredDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// UnstructuredDataService_ServiceDesc is the grpc.ServiceDesc for UnstructuredDataService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var UnstructuredDataService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "credtech.unstructured.v1.UnstructuredDataService",
+	HandlerType: (*UnstructuredDataServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUnstructuredData",
+			Handler:    _UnstructuredDataService_GetUnstructuredData_Handler,
+		},
+		{
+			MethodName: "SaveUnstructuredData",
+			Handler:    _UnstructuredDataService_SaveUnstructuredData_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ListUnstructuredData",
+			Handler:       _UnstructuredDataService_ListUnstructuredData_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "unstructured/v1/unstructured_data.proto",
+}
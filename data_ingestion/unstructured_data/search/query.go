@@ -0,0 +1,206 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Query selects documents by relevance to Text, which may be empty to list
+// by recency, within the filters that are set.
+type Query struct {
+	Text     string
+	Source   string
+	Type     string
+	Tags     []string
+	Symbols  []string
+	DateFrom *time.Time
+	DateTo   *time.Time
+	Size     int
+	Offset   int
+}
+
+// Hit is a matching document with the passages that matched.
+type Hit struct {
+	ID          string              `json:"id"`
+	Score       float64             `json:"score"`
+	Source      string              `json:"source"`
+	Type        string              `json:"type"`
+	Title       string              `json:"title"`
+	URL         string              `json:"url"`
+	PublishedAt time.Time           `json:"published_at"`
+	Symbols     []string            `json:"symbols,omitempty"`
+	Sentiment   *float64            `json:"sentiment,omitempty"`
+	Highlights  map[string][]string `json:"highlights,omitempty"`
+}
+
+// Bucket is one value of an aggregation and how many matches have it.
+type Bucket struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// Results are a page of hits and aggregations over all matches.
+type Results struct {
+	Total            int64               `json:"total"`
+	Hits             []Hit               `json:"hits"`
+	Aggregations     map[string][]Bucket `json:"aggregations"`
+	AverageSentiment *float64            `json:"average_sentiment,omitempty"`
+}
+
+// termAggregations are the keyword fields counted over every match.
+var termAggregations = map[string]string{
+	"sources":  "source",
+	"types":    "type",
+	"tags":     "tags",
+	"symbols":  "symbols",
+	"entities": "entities",
+}
+
+// Search runs q against the index. Title matches weigh three times and
+// summary matches twice as much as content matches, and matched passages of
+// each are highlighted. Matches are also counted per source, type, tag,
+// symbol, entity and day, and their sentiment averaged.
+func (ix *Indexer) Search(ctx context.Context, q Query) (*Results, error) {
+	var filters []interface{}
+	if q.Source != "" {
+		filters = append(filters, term("source", q.Source))
+	}
+	if q.Type != "" {
+		filters = append(filters, term("type", q.Type))
+	}
+	if len(q.Tags) > 0 {
+		filters = append(filters, terms("tags", q.Tags))
+	}
+	if len(q.Symbols) > 0 {
+		filters = append(filters, terms("symbols", q.Symbols))
+	}
+	if q.DateFrom != nil || q.DateTo != nil {
+		bounds := map[string]interface{}{}
+		if q.DateFrom != nil {
+			bounds["gte"] = q.DateFrom.UTC().Format(time.RFC3339)
+		}
+		if q.DateTo != nil {
+			bounds["lte"] = q.DateTo.UTC().Format(time.RFC3339)
+		}
+		filters = append(filters, map[string]interface{}{"range": map[string]interface{}{"published_at": bounds}})
+	}
+
+	boolQuery := map[string]interface{}{"filter": filters}
+	var sort []interface{}
+	if text := strings.TrimSpace(q.Text); text != "" {
+		boolQuery["must"] = map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":     text,
+				"fields":    []string{"title^3", "summary^2", "content"},
+				"type":      "best_fields",
+				"fuzziness": "AUTO",
+			},
+		}
+		// An exact phrase in the title ranks above a stemmed match
+		boolQuery["should"] = map[string]interface{}{
+			"match_phrase": map[string]interface{}{"title.exact": map[string]interface{}{"query": text, "boost": 2}},
+		}
+	} else {
+		sort = []interface{}{map[string]interface{}{"published_at": "desc"}}
+	}
+
+	aggs := map[string]interface{}{
+		"per_day":   map[string]interface{}{"date_histogram": map[string]interface{}{"field": "published_at", "calendar_interval": "day", "min_doc_count": 1}},
+		"sentiment": map[string]interface{}{"avg": map[string]interface{}{"field": "sentiment"}},
+	}
+	for name, field := range termAggregations {
+		aggs[name] = map[string]interface{}{"terms": map[string]interface{}{"field": field, "size": 20}}
+	}
+
+	request := map[string]interface{}{
+		"query":            map[string]interface{}{"bool": boolQuery},
+		"from":             q.Offset,
+		"size":             q.Size,
+		"track_total_hits": true,
+		"_source":          []string{"id", "source", "type", "title", "url", "published_at", "symbols", "sentiment"},
+		"highlight": map[string]interface{}{
+			"pre_tags":  []string{"<em>"},
+			"post_tags": []string{"</em>"},
+			"fields": map[string]interface{}{
+				"title":   map[string]interface{}{"number_of_fragments": 0},
+				"summary": map[string]interface{}{"fragment_size": 150, "number_of_fragments": 2},
+				"content": map[string]interface{}{"fragment_size": 150, "number_of_fragments": 3},
+			},
+		},
+		"aggs": aggs,
+	}
+	if sort != nil {
+		request["sort"] = sort
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				Score     *float64            `json:"_score"`
+				Source    Hit                 `json:"_source"`
+				Highlight map[string][]string `json:"highlight"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations map[string]struct {
+			Buckets []struct {
+				Key         interface{} `json:"key"`
+				KeyAsString string      `json:"key_as_string"`
+				DocCount    int64       `json:"doc_count"`
+			} `json:"buckets"`
+			Value *float64 `json:"value"`
+		} `json:"aggregations"`
+	}
+	path := "/" + url.PathEscape(ix.config.Index) + "/_search"
+	if err := ix.do(ctx, "POST", path, "application/json", bytes.NewReader(body), &response); err != nil {
+		return nil, err
+	}
+
+	results := &Results{
+		Total:        response.Hits.Total.Value,
+		Hits:         make([]Hit, 0, len(response.Hits.Hits)),
+		Aggregations: make(map[string][]Bucket),
+	}
+	for _, h := range response.Hits.Hits {
+		hit := h.Source
+		if h.Score != nil {
+			hit.Score = *h.Score
+		}
+		hit.Highlights = h.Highlight
+		results.Hits = append(results.Hits, hit)
+	}
+	for name, agg := range response.Aggregations {
+		if name == "sentiment" {
+			results.AverageSentiment = agg.Value
+			continue
+		}
+		buckets := make([]Bucket, 0, len(agg.Buckets))
+		for _, b := range agg.Buckets {
+			key := b.KeyAsString
+			if key == "" {
+				key, _ = b.Key.(string)
+			}
+			buckets = append(buckets, Bucket{Key: key, Count: b.DocCount})
+		}
+		results.Aggregations[name] = buckets
+	}
+	return results, nil
+}
+
+func term(field, value string) interface{} {
+	return map[string]interface{}{"term": map[string]interface{}{field: value}}
+}
+
+func terms(field string, values []string) interface{} {
+	return map[string]interface{}{"terms": map[string]interface{}{field: values}}
+}
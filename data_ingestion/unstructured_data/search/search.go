@@ -0,0 +1,479 @@
+// Package search mirrors stored documents into an Elasticsearch or
+// OpenSearch index for relevance-ranked full-text queries, highlighting and
+// aggregations, which the LIKE filters of the primary storage cannot give.
+// The index is a copy: documents are indexed after they are stored, in bulk
+// and best effort, and can always be rebuilt from storage.
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// backfillPage is how many stored documents a backfill reads at a time.
+const backfillPage = 500
+
+// document is the indexed form of a stored document. Metadata is kept
+// whole but not indexed, so mapping it cannot explode on free-form keys.
+type document struct {
+	ID          string                 `json:"id"`
+	Source      string                 `json:"source"`
+	Type        string                 `json:"type"`
+	Title       string                 `json:"title"`
+	Content     string                 `json:"content"`
+	Summary     string                 `json:"summary,omitempty"`
+	URL         string                 `json:"url"`
+	Author      string                 `json:"author"`
+	PublishedAt time.Time              `json:"published_at"`
+	IngestedAt  time.Time              `json:"ingested_at"`
+	Tags        []string               `json:"tags"`
+	Symbols     []string               `json:"symbols"`
+	Entities    []string               `json:"entities"`
+	Sentiment   *float64               `json:"sentiment,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// mapping analyzes title, summary and content with English stemming and
+// stop words, keeps an unstemmed copy of the title for exact phrases, and
+// stores offsets for highlighting. The rest are keywords for filters and
+// aggregations.
+var mapping = map[string]interface{}{
+	"settings": map[string]interface{}{
+		"analysis": map[string]interface{}{
+			"filter": map[string]interface{}{
+				"english_possessive": map[string]interface{}{"type": "stemmer", "language": "possessive_english"},
+				"english_stop":       map[string]interface{}{"type": "stop", "stopwords": "_english_"},
+				"english_stemmer":    map[string]interface{}{"type": "stemmer", "language": "english"},
+			},
+			"analyzer": map[string]interface{}{
+				"credtech_english": map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "standard",
+					"filter":    []string{"english_possessive", "lowercase", "asciifolding", "english_stop", "english_stemmer"},
+				},
+				"credtech_exact": map[string]interface{}{
+					"type":      "custom",
+					"tokenizer": "standard",
+					"filter":    []string{"lowercase", "asciifolding"},
+				},
+			},
+			// symbols match whatever case a filter uses
+			"normalizer": map[string]interface{}{
+				"lowercase": map[string]interface{}{"type": "custom", "filter": []string{"lowercase"}},
+			},
+		},
+	},
+	"mappings": map[string]interface{}{
+		"dynamic": "strict",
+		"properties": map[string]interface{}{
+			"id":     map[string]interface{}{"type": "keyword"},
+			"source": map[string]interface{}{"type": "keyword"},
+			"type":   map[string]interface{}{"type": "keyword"},
+			"title": map[string]interface{}{
+				"type":          "text",
+				"analyzer":      "credtech_english",
+				"index_options": "offsets",
+				"fields": map[string]interface{}{
+					"exact": map[string]interface{}{"type": "text", "analyzer": "credtech_exact"},
+				},
+			},
+			"content":      map[string]interface{}{"type": "text", "analyzer": "credtech_english", "index_options": "offsets"},
+			"summary":      map[string]interface{}{"type": "text", "analyzer": "credtech_english", "index_options": "offsets"},
+			"url":          map[string]interface{}{"type": "keyword", "index": false},
+			"author":       map[string]interface{}{"type": "keyword"},
+			"published_at": map[string]interface{}{"type": "date"},
+			"ingested_at":  map[string]interface{}{"type": "date"},
+			"tags":         map[string]interface{}{"type": "keyword"},
+			"symbols":      map[string]interface{}{"type": "keyword", "normalizer": "lowercase"},
+			"entities":     map[string]interface{}{"type": "keyword"},
+			"sentiment":    map[string]interface{}{"type": "float"},
+			"metadata":     map[string]interface{}{"type": "object", "enabled": false},
+		},
+	},
+}
+
+// Indexer wraps a Storage and indexes every document saved through it.
+type Indexer struct {
+	storage.Storage
+	config config.SearchConfig
+	client *http.Client
+
+	queue chan indexed
+	// unavailable is set while the index cannot be reached; only run
+	// touches it
+	unavailable bool
+
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	wg      sync.WaitGroup
+	dropped atomic.Int64
+}
+
+// Wrap returns a Storage that indexes by cfg and starts its indexer.
+func Wrap(store storage.Storage, cfg config.SearchConfig) *Indexer {
+	ix := &Indexer{
+		Storage: store,
+		config:  cfg,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		queue:   make(chan indexed, cfg.QueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go ix.run()
+	return ix
+}
+
+func (ix *Indexer) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	if err := ix.Storage.SaveUnstructuredData(ctx, data); err != nil {
+		return err
+	}
+	doc, err := encode(data)
+	if err != nil {
+		log.Printf("Failed to encode %s for the search index: %v", data.ID, err)
+		return nil
+	}
+	select {
+	case ix.queue <- doc:
+	default:
+		ix.dropped.Add(1)
+	}
+	return nil
+}
+
+// DeleteUnstructuredData also removes the documents from the index.
+func (ix *Indexer) DeleteUnstructuredData(ctx context.Context, ids []string) error {
+	if err := ix.Storage.DeleteUnstructuredData(ctx, ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		select {
+		case ix.queue <- indexed{id: id}:
+		default:
+			ix.dropped.Add(1)
+		}
+	}
+	return nil
+}
+
+// Shutdown stops a running backfill and indexes the documents still queued,
+// waiting until ctx is done at most.
+func (ix *Indexer) Shutdown(ctx context.Context) error {
+	ix.once.Do(func() { close(ix.stop) })
+	select {
+	case <-ix.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if dropped := ix.dropped.Load(); dropped > 0 {
+		log.Printf("Search indexer dropped %d documents; recreating the index with backfill restores them", dropped)
+	}
+	return nil
+}
+
+// indexed is a document encoded when it was saved, before its caller could
+// change it, or without a body one to delete.
+type indexed struct {
+	id   string
+	body json.RawMessage
+}
+
+func encode(data *models.UnstructuredData) (indexed, error) {
+	body, err := json.Marshal(toDocument(data))
+	return indexed{id: data.ID, body: body}, err
+}
+
+func toDocument(data *models.UnstructuredData) document {
+	doc := document{
+		ID:          data.ID,
+		Source:      data.Source,
+		Type:        data.Type,
+		Title:       data.Title,
+		Content:     data.Content,
+		Summary:     data.Summary,
+		URL:         data.URL,
+		Author:      data.Author,
+		PublishedAt: data.PublishedAt,
+		IngestedAt:  data.IngestedAt,
+		Tags:        data.Tags,
+		Metadata:    data.Metadata,
+	}
+	seen := make(map[string]bool)
+	for _, symbol := range data.Symbols() {
+		symbol = strings.ToUpper(symbol)
+		if !seen[symbol] {
+			seen[symbol] = true
+			doc.Symbols = append(doc.Symbols, symbol)
+		}
+	}
+	for _, entity := range data.Entities {
+		doc.Entities = append(doc.Entities, entity.Name)
+	}
+	if data.Sentiment != nil {
+		overall := data.Sentiment.Overall
+		doc.Sentiment = &overall
+	}
+	return doc
+}
+
+// run indexes the queue in batches until Shutdown, then indexes what is left.
+// Nothing is indexed before the index exists with its mapping, which keeps
+// the cluster from creating it with guessed field types; until then the
+// batch grows to QueueSize and the queue behind it fills.
+func (ix *Indexer) run() {
+	defer close(ix.done)
+	ticker := time.NewTicker(ix.config.FlushInterval)
+	defer ticker.Stop()
+
+	ready := false
+	var batch []indexed
+	for {
+		queue := ix.queue
+		if len(batch) >= ix.config.QueueSize {
+			queue = nil
+		}
+		select {
+		case doc := <-queue:
+			batch = append(batch, doc)
+			if len(batch) < ix.config.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-ix.stop:
+			// the backfill may still be queueing
+			ix.wg.Wait()
+			for len(ix.queue) > 0 {
+				batch = append(batch, <-ix.queue)
+			}
+			if len(batch) > 0 && (ready || ix.prepare(false)) {
+				ix.flush(batch)
+			} else {
+				ix.dropped.Add(int64(len(batch)))
+			}
+			return
+		}
+		if len(batch) == 0 {
+			continue
+		}
+		if !ready {
+			if ready = ix.prepare(true); !ready {
+				continue
+			}
+		}
+		ix.flush(batch)
+		batch = batch[:0]
+	}
+}
+
+// prepare creates the index when it does not exist and, with Backfill and
+// backfill set, starts indexing the documents already stored.
+func (ix *Indexer) prepare(backfill bool) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), ix.config.Timeout)
+	defer cancel()
+	created, err := ix.ensureIndex(ctx)
+	if err != nil {
+		if !ix.unavailable {
+			log.Printf("Search index %s unavailable, holding documents: %v", ix.config.Index, err)
+		}
+		ix.unavailable = true
+		return false
+	}
+	ix.unavailable = false
+	if created {
+		log.Printf("Created search index %s", ix.config.Index)
+		if backfill && ix.config.Backfill {
+			ix.wg.Add(1)
+			go ix.backfill()
+		}
+	}
+	return true
+}
+
+// flush indexes documents in bulk requests of up to BatchSize.
+func (ix *Indexer) flush(docs []indexed) {
+	for len(docs) > 0 {
+		n := min(ix.config.BatchSize, len(docs))
+		ix.bulk(docs[:n])
+		docs = docs[n:]
+	}
+}
+
+// bulk indexes a batch, replacing earlier versions of its documents, and
+// removes the deleted ones.
+func (ix *Indexer) bulk(batch []indexed) {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, doc := range batch {
+		if doc.body == nil {
+			encoder.Encode(map[string]interface{}{"delete": map[string]string{"_index": ix.config.Index, "_id": doc.id}})
+			continue
+		}
+		encoder.Encode(map[string]interface{}{"index": map[string]string{"_index": ix.config.Index, "_id": doc.id}})
+		body.Write(doc.body)
+		body.WriteByte('\n')
+	}
+
+	var result struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID    string `json:"_id"`
+			Error *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		} `json:"items"`
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ix.config.Timeout)
+	defer cancel()
+	if err := ix.do(ctx, "POST", "/_bulk", "application/x-ndjson", &body, &result); err != nil {
+		ix.dropped.Add(int64(len(batch)))
+		log.Printf("Failed to index %d documents: %v", len(batch), err)
+		return
+	}
+	if !result.Errors {
+		return
+	}
+	failed := 0
+	for _, item := range result.Items {
+		for _, action := range item {
+			if action.Error != nil {
+				if failed == 0 {
+					log.Printf("Failed to index document %s: %s: %s", action.ID, action.Error.Type, action.Error.Reason)
+				}
+				failed++
+			}
+		}
+	}
+	ix.dropped.Add(int64(failed))
+	log.Printf("Search index rejected %d of %d documents", failed, len(batch))
+}
+
+// ensureIndex creates the index with its mapping unless it exists.
+func (ix *Indexer) ensureIndex(ctx context.Context) (bool, error) {
+	err := ix.do(ctx, "HEAD", "/"+url.PathEscape(ix.config.Index), "", nil, nil)
+	if err == nil {
+		return false, nil
+	}
+	var status *statusError
+	if !errors.As(err, &status) || status.code != http.StatusNotFound {
+		return false, err
+	}
+	body, err := json.Marshal(mapping)
+	if err != nil {
+		return false, err
+	}
+	if err := ix.do(ctx, "PUT", "/"+url.PathEscape(ix.config.Index), "application/json", bytes.NewReader(body), nil); err != nil {
+		return false, fmt.Errorf("failed to create search index %s: %w", ix.config.Index, err)
+	}
+	return true, nil
+}
+
+// backfill queues every stored document, a page at a time, until done or
+// Shutdown.
+func (ix *Indexer) backfill() {
+	defer ix.wg.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-ix.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// Storage that ignores paging returns everything every time, which the
+	// second page then adds nothing to
+	seen := make(map[string]bool)
+	queued := 0
+	var after *storage.Cursor
+	for {
+		docs, err := ix.Storage.ListUnstructuredData(ctx, storage.DataFilters{Limit: backfillPage, After: after})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Search backfill stopped after %d documents: %v", queued, err)
+			}
+			return
+		}
+		fresh := 0
+		for _, data := range docs {
+			if seen[data.ID] {
+				continue
+			}
+			seen[data.ID] = true
+			fresh++
+			doc, err := encode(data)
+			if err != nil {
+				continue
+			}
+			select {
+			case ix.queue <- doc:
+				queued++
+			case <-ctx.Done():
+				return
+			}
+		}
+		if len(docs) < backfillPage || fresh == 0 {
+			log.Printf("Search backfill queued %d documents", queued)
+			return
+		}
+		after = storage.CursorOf(docs[len(docs)-1])
+	}
+}
+
+// statusError is a response outside 2xx.
+type statusError struct {
+	code   int
+	detail string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("search cluster returned status %d: %s", e.code, e.detail)
+}
+
+// do sends one request to the cluster and decodes the response into out
+// unless it is nil.
+func (ix *Indexer) do(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(ix.config.URL, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	switch {
+	case ix.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+ix.config.APIKey)
+	case ix.config.Username != "":
+		req.SetBasicAuth(ix.config.Username, ix.config.Password)
+	}
+
+	resp, err := ix.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{code: resp.StatusCode, detail: string(bytes.TrimSpace(detail))}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
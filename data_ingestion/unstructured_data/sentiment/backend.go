@@ -0,0 +1,69 @@
+package sentiment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// Scorer scores a text; a nil score means it had nothing to score.
+type Scorer interface {
+	Score(ctx context.Context, text string) (*models.SentimentScore, error)
+}
+
+// Lexicon scores with the Loughran-McDonald word lists.
+type Lexicon struct{}
+
+func (Lexicon) Score(_ context.Context, text string) (*models.SentimentScore, error) {
+	return Score(text), nil
+}
+
+// New builds the scorer cfg selects.
+func New(cfg config.SentimentConfig) (Scorer, error) {
+	switch cfg.Backend {
+	case "", "lexicon":
+		return Lexicon{}, nil
+	case "http":
+		return &Fallback{Primary: NewService(cfg), Secondary: Lexicon{}, RetryAfter: cfg.RetryAfter}, nil
+	}
+	return nil, fmt.Errorf("unknown sentiment backend %q", cfg.Backend)
+}
+
+// Fallback scores with Primary and, once it fails, with Secondary until
+// RetryAfter has passed, so an unavailable service costs one timeout per
+// RetryAfter rather than one per record.
+type Fallback struct {
+	Primary    Scorer
+	Secondary  Scorer
+	RetryAfter time.Duration
+
+	mu        sync.Mutex
+	downUntil time.Time
+}
+
+func (f *Fallback) Score(ctx context.Context, text string) (*models.SentimentScore, error) {
+	f.mu.Lock()
+	down := time.Now().Before(f.downUntil)
+	f.mu.Unlock()
+	if down {
+		return f.Secondary.Score(ctx, text)
+	}
+
+	score, err := f.Primary.Score(ctx, text)
+	if err == nil || ctx.Err() != nil {
+		return score, err
+	}
+
+	f.mu.Lock()
+	if !time.Now().Before(f.downUntil) {
+		log.Printf("Sentiment service failed, using the lexicon for %v: %v", f.RetryAfter, err)
+		f.downUntil = time.Now().Add(f.RetryAfter)
+	}
+	f.mu.Unlock()
+	return f.Secondary.Score(ctx, text)
+}
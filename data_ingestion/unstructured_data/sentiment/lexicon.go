@@ -0,0 +1,178 @@
+package sentiment
+
+import "strings"
+
+// The word lists are a curated subset of the Loughran-McDonald master
+// dictionary, which classifies words by their meaning in financial
+// filings: "liability" or "tax" are neutral there, unlike in general
+// purpose lexicons. Only base forms are listed; lookup strips common
+// inflections.
+
+const negativeWords = `
+abandon abandonment abdicate aberration abnormal abolish abrupt absence
+abuse accident accusation accuse acquit adulterate adverse adversely
+adversity aftermath aggravate alienate allegation allege annul
+anomaly anticompetitive antitrust argue argument arrearage arrears
+arrest artificially assault attrition aversely backdate bad bail bailout
+balk bankrupt bankruptcy barrier bottleneck boycott breach break
+breakdown bribe bribery burden calamity cancel cancellation careless
+catastrophe caution cautionary cease censure challenge chargeoff
+closure collapse collision collude collusion complain complaint
+complicate complication concede condemn confiscate conflict
+confront confusion conspiracy constrain contempt contend contention
+contraction controversy convict conviction corruption
+costly counterfeit crime criminal crisis critical
+criticism criticize curtail cut cutback damage danger dangerous
+deadlock death debarment deceive deception decline decrease default
+defect defective defendant defer deficiency deficit defraud degrade
+delay delinquency delinquent delist demise demolish demote denial deny
+deplete depreciate depress depression deprive derail destabilize destroy
+destruction detain deteriorate deterioration detriment devalue
+devastate deviate difficult difficulty diminish disadvantage disagree
+disappoint disappointing disappointment disaster disclaim
+discontinue discourage discrepancy discriminate dismal dismiss
+disproportionate dispute disqualify disrupt disruption dissatisfied
+dissolution distort distress disturb divest doubt downgrade downsize
+downturn downward drastic drop dysfunction egregious embargo
+embezzle emergency encroach encumber endanger erode erosion error
+escalate evict exacerbate excessive exclude exhaust expose expropriate
+fail failure fallout false falsify fatality fault felony
+flaw fluctuate forbid foreclose foreclosure forfeit forfeiture
+fraud fraudulent frivolous fugitive guilty halt hamper harm harsh
+hazard hinder hostile hurt idle illegal illicit impair impairment
+impasse impede imperil implicate impossible impound improper
+inability inaccurate inadequate inadvertent incapable incompatible
+incompetent incomplete inconsistent inconvenience incorrect indict
+indictment ineffective inefficiency inefficient ineligible inferior
+infraction infringe infringement injunction injure injury insolvency
+insolvent instability insufficient interrupt interruption investigate
+investigation involuntary irregular irregularity jeopardize lack lag
+lapse late layoff liquidate liquidation litigation lose loss
+malfeasance malfunction manipulate misappropriate misconduct
+mislead misrepresent misrepresentation miss mistake misuse monopoly
+negative negatively neglect negligence negligent nonpayment
+nonperformance nonperforming nonrecoverable objection obsolete
+obstacle offend omission oppose opposition outage overburden overcharge
+overdue overestimate overload overrun overstate overvalue panic penalty
+peril perpetrate plaintiff plea plunge poor poorly postpone
+preclude prejudice problem prohibit prolong prosecute
+prosecution protest punitive recall recession reckless
+redress refusal refuse reject rejection relinquish reluctant renounce
+repossess repudiate resign resignation restate restatement restrict
+restructure restructuring retaliate revoke ruin sabotage sanction scandal
+scarce scrutiny serious setback severe severance shortage shortfall
+shrink shut shutdown slow slowdown slump slippage sluggish squeeze
+stagnant stagnation stop strain strike subpoena suffer
+suspend suspension terminate termination theft threat threaten
+trouble turbulence turmoil unable unanticipated unauthorized
+unavailable uncollectible uncompetitive underfund underperform
+undermine understate undesirable unfavorable unfavourable unfortunate
+unjust unlawful unpaid unprofitable unrecoverable unresolved unsafe
+unsold unstable unsuccessful untrue unwanted unwarranted upset urgent
+violate violation volatile vulnerability vulnerable warn warning weak
+weaken weakness worse worsen worst writedown writeoff wrong wrongdoing
+`
+
+const positiveWords = `
+able abundance accomplish accomplishment achieve achievement adequately
+advance advancement advantage advantageous alliance assure attain
+attractive beautiful beneficial benefit best better bolster boom boost
+breakthrough brilliant collaborate compliment conclusive constructive
+courteous creative delight dependable desirable
+diligent distinction distinctive dream easy efficiency efficient
+empower enable encourage enhance enhancement enjoy enthusiasm
+enthusiastic excellence excellent exceptional excited exciting
+exclusive exemplary fantastic favorable favourable favorite friendly
+gain good great greater greatest happy highest honor ideal impress
+impressive improve improvement incredible influential ingenuity
+innovate innovation innovative insightful inspiration integrity invent
+inventive leadership leading lucrative meritorious opportunity optimistic
+outperform outstanding perfect pleasant pleasure popular positive
+positively premier prestige prestigious proactive proficiency profitable
+profitability progress prominent prosper prosperity rebound receptive
+regain resolve revolutionize reward rewarding satisfaction satisfactory
+satisfy smooth solve spectacular stability stabilize stable strength
+strengthen strong stronger strongest succeed success successful superior
+surpass transparency tremendous unmatched unparalleled unsurpassed upturn
+valuable versatile vibrant win winner worthy
+`
+
+const uncertaintyWords = `
+almost ambiguity ambiguous anomalous anticipate appear apparently
+approximate arbitrarily assume assumption believe cautious conceivable
+conditional confuse contingency contingent could crossroad depend
+deviation doubtful exposure fluctuation hidden imprecise improbable
+indefinite indefinitely indeterminate inexact instability intangible
+likelihood may maybe might nearly nonassessable occasionally pending
+perhaps possible possibility possibly precaution predict prediction
+preliminary presumably probable probably random reassess recalculate
+reconsider reexamine reinterpret revise risk risky roughly rumor seem
+seldom sometime somewhat speculate speculative sporadic sudden suggest
+susceptible tentative turbulence uncertain uncertainty unclear
+unconfirmed undecided undefined undetermined unexpected unforecasted
+unknown unplanned unpredictable unproven unquantifiable unsettled
+unspecified untested unusual vagary vague variability variable variance
+variation volatility
+`
+
+const litigiousWords = `
+abrogate absolve acquittal adjudicate adjudication affidavit allegation
+amend amicus appeal appellant appellate arbitration arbitrator attorney
+claimant codify complainant constitutional contractual counterclaim
+court damages decree defendant deposition dictum enforceable
+indemnification indemnify injunction interrogatory judicial judiciary
+jurisdiction jurisprudence jury lawsuit lawyer legal legislation
+legislative liability litigant litigate litigation magistrate
+nonjudicial notarize overrule petition plaintiff prosecute prosecutor
+regulator remand rescind settlement statute statutory subpoena sue
+summons testify testimony tort tribunal verdict
+`
+
+// negators turn the sentiment of the few words after them around.
+var negators = wordSet(`no not none neither never nobody nor cannot without`)
+
+var (
+	negative    = wordSet(negativeWords)
+	positive    = wordSet(positiveWords)
+	uncertainty = wordSet(uncertaintyWords)
+	litigious   = wordSet(litigiousWords)
+)
+
+func wordSet(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
+
+// inflections are stripped, longest first, to find a listed base form.
+var inflections = []string{"ations", "ation", "ings", "ing", "ies", "ied", "ed", "es", "ly", "s", "d"}
+
+// lookup reports whether word, or a base form of it, is in set.
+func lookup(set map[string]bool, word string) bool {
+	if set[word] {
+		return true
+	}
+	for _, suffix := range inflections {
+		stem, ok := strings.CutSuffix(word, suffix)
+		if !ok || len(stem) < 3 {
+			continue
+		}
+		if set[stem] || set[stem+"e"] {
+			return true
+		}
+		switch suffix {
+		case "ies", "ied":
+			if set[stem+"y"] {
+				return true
+			}
+		case "ing", "ed":
+			// Doubled final consonant: "slipped", "cutting"
+			if n := len(stem); n > 3 && stem[n-1] == stem[n-2] && set[stem[:n-1]] {
+				return true
+			}
+		}
+	}
+	return false
+}
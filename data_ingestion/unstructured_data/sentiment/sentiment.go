@@ -0,0 +1,127 @@
+// Package sentiment scores financial text with the Loughran-McDonald word
+// lists, counting positive and negative words with simple negation
+// handling.
+package sentiment
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// Model names the scores this package produces.
+const Model = "loughran_mcdonald"
+
+// negationWindow is how many words after a negator it applies to, as in
+// Loughran and McDonald (2011).
+const negationWindow = 3
+
+// Counts are the lexicon hits in a text.
+type Counts struct {
+	Words       int
+	Positive    int
+	Negative    int
+	Uncertainty int
+	Litigious   int
+}
+
+// Count tokenizes text and counts its lexicon words. A positive word
+// within three words after a negator counts as negative ("did not
+// improve"); a negated negative word counts as neither, since "not
+// impaired" is not good news so much as the absence of bad news. Negation
+// ends at the end of a sentence.
+func Count(text string) Counts {
+	var counts Counts
+	for _, sentence := range sentences(text) {
+		negated := 0
+		for _, word := range words(sentence) {
+			counts.Words++
+			if negators[word] || strings.HasSuffix(word, "n't") {
+				negated = negationWindow
+				continue
+			}
+
+			switch {
+			case lookup(positive, word):
+				if negated > 0 {
+					counts.Negative++
+				} else {
+					counts.Positive++
+				}
+			case lookup(negative, word):
+				if negated == 0 {
+					counts.Negative++
+				}
+			}
+			if lookup(uncertainty, word) {
+				counts.Uncertainty++
+			}
+			if lookup(litigious, word) {
+				counts.Litigious++
+			}
+			if negated > 0 {
+				negated--
+			}
+		}
+	}
+	return counts
+}
+
+// Score scores text, nil when it has no words. Overall is the net tone
+// (positive-negative)/(positive+negative); Positive, Negative and Neutral
+// are the shares of words in each class; Magnitude is the number of
+// sentiment words, so longer emphatic texts weigh more. The uncertainty
+// and litigious aspects are the shares of words in those lists.
+func Score(text string) *models.SentimentScore {
+	counts := Count(text)
+	if counts.Words == 0 {
+		return nil
+	}
+
+	words := float64(counts.Words)
+	polar := float64(counts.Positive + counts.Negative)
+	score := &models.SentimentScore{
+		Positive:  float64(counts.Positive) / words,
+		Negative:  float64(counts.Negative) / words,
+		Neutral:   1 - polar/words,
+		Magnitude: polar,
+		Model:     Model,
+		Aspects: map[string]float64{
+			"uncertainty": float64(counts.Uncertainty) / words,
+			"litigious":   float64(counts.Litigious) / words,
+		},
+	}
+	if polar > 0 {
+		score.Overall = float64(counts.Positive-counts.Negative) / polar
+	}
+	return score
+}
+
+func sentences(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		switch r {
+		case '.', '!', '?', ';', '\n':
+			return true
+		}
+		return false
+	})
+}
+
+// words lowercases a sentence and splits it into words, keeping inner
+// apostrophes and hyphens so "isn't" stays one word and "write-off"
+// becomes "writeoff".
+func words(sentence string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(sentence), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\'' && r != '’' && r != '-'
+	})
+	result := fields[:0]
+	for _, field := range fields {
+		field = strings.ReplaceAll(field, "’", "'")
+		field = strings.ReplaceAll(strings.Trim(field, "'-"), "-", "")
+		if field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
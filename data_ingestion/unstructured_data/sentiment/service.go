@@ -0,0 +1,196 @@
+package sentiment
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// Service scores with an external FinBERT or LLM inference service. Texts
+// scored at about the same time are sent together: the service is posted
+//
+//	{"texts": ["...", "..."]}
+//
+// and answers with class probabilities for every text, in order:
+//
+//	{"model": "finbert", "results": [{"positive": 0.91, "negative": 0.02, "neutral": 0.07}, ...]}
+type Service struct {
+	url       string
+	apiKey    string
+	client    *http.Client
+	batchSize int
+	batchWait time.Duration
+	maxChars  int
+
+	mu      sync.Mutex
+	pending *batch
+}
+
+// batch is a request being gathered; the first caller sends it once it is
+// full or BatchWait has passed.
+type batch struct {
+	texts  []string
+	scores []*models.SentimentScore
+	err    error
+	full   chan struct{}
+	done   chan struct{}
+}
+
+type serviceRequest struct {
+	Texts []string `json:"texts"`
+}
+
+type serviceResponse struct {
+	Model   string `json:"model"`
+	Results []struct {
+		Positive float64            `json:"positive"`
+		Negative float64            `json:"negative"`
+		Neutral  float64            `json:"neutral"`
+		Aspects  map[string]float64 `json:"aspects"`
+	} `json:"results"`
+}
+
+func NewService(cfg config.SentimentConfig) *Service {
+	batchSize := cfg.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return &Service{
+		url:       cfg.URL,
+		apiKey:    cfg.APIKey,
+		client:    &http.Client{Timeout: cfg.Timeout},
+		batchSize: batchSize,
+		batchWait: cfg.BatchWait,
+		maxChars:  cfg.MaxChars,
+	}
+}
+
+func (s *Service) Score(ctx context.Context, text string) (*models.SentimentScore, error) {
+	s.mu.Lock()
+	b := s.pending
+	leader := b == nil
+	if leader {
+		b = &batch{full: make(chan struct{}), done: make(chan struct{})}
+		s.pending = b
+	}
+	index := len(b.texts)
+	b.texts = append(b.texts, truncate(text, s.maxChars))
+	if len(b.texts) >= s.batchSize {
+		s.pending = nil
+		close(b.full)
+	}
+	s.mu.Unlock()
+
+	if leader {
+		s.send(ctx, b)
+	}
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.scores[index], nil
+}
+
+// send waits for the batch to fill, then posts it.
+func (s *Service) send(ctx context.Context, b *batch) {
+	defer close(b.done)
+
+	timer := time.NewTimer(s.batchWait)
+	defer timer.Stop()
+	select {
+	case <-b.full:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	// Close the batch to newcomers; after this it is only read
+	s.mu.Lock()
+	if s.pending == b {
+		s.pending = nil
+	}
+	s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		b.err = err
+		return
+	}
+	b.scores, b.err = s.post(ctx, b.texts)
+}
+
+func (s *Service) post(ctx context.Context, texts []string) ([]*models.SentimentScore, error) {
+	body, err := json.Marshal(serviceRequest{Texts: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode sentiment request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sentiment service request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		return nil, fmt.Errorf("sentiment service returned status %d", resp.StatusCode)
+	}
+
+	var result serviceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode sentiment response: %w", err)
+	}
+	if len(result.Results) != len(texts) {
+		return nil, fmt.Errorf("sentiment service scored %d of %d texts", len(result.Results), len(texts))
+	}
+
+	model := result.Model
+	if model == "" {
+		model = "sentiment_service"
+	}
+	scores := make([]*models.SentimentScore, len(texts))
+	for i, r := range result.Results {
+		if texts[i] == "" {
+			continue
+		}
+		scores[i] = &models.SentimentScore{
+			Overall:   r.Positive - r.Negative,
+			Positive:  r.Positive,
+			Negative:  r.Negative,
+			Neutral:   r.Neutral,
+			Magnitude: r.Positive + r.Negative,
+			Model:     model,
+			Aspects:   r.Aspects,
+		}
+	}
+	return scores, nil
+}
+
+// truncate cuts text to at most max bytes on a rune boundary; models such
+// as FinBERT only read the first 512 tokens anyway.
+func truncate(text string, max int) string {
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max]
+}
@@ -0,0 +1,280 @@
+package sharing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// sectorTags maps the sector tags sources attach to a display sector name.
+var sectorTags = map[string]string{
+	"technology": "Technology",
+	"banking":    "Financials",
+	"energy":     "Energy",
+	"healthcare": "Healthcare",
+}
+
+var distributionBuckets = []float64{-1, -0.6, -0.2, 0.2, 0.6, 1}
+
+// windowSizes are the only windows, in days, a response covers. Windows of
+// any length would let a caller difference adjacent ones down to single days.
+var windowSizes = []int{7, 30, 90}
+
+// Server exposes aggregate-only statistics over ingested data. Raw documents,
+// titles and URLs never leave this package: every response is a group-level
+// aggregate with Laplace noise added to counts and means, and groups whose
+// noisy count is small suppressed (k-anonymity). The noise of a statistic is
+// fixed for its window, so repeating a request returns the same answer
+// rather than a fresh sample to average away.
+type Server struct {
+	storage storage.Storage
+	config  config.SharingConfig
+	server  *http.Server
+}
+
+type SectorSentiment struct {
+	Sector           string  `json:"sector"`
+	Documents        int     `json:"documents"`
+	AverageSentiment float64 `json:"average_sentiment"`
+	NegativeShare    float64 `json:"negative_share"`
+}
+
+type DistributionBucket struct {
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+	Count int     `json:"count"`
+}
+
+type Response struct {
+	GeneratedAt  time.Time   `json:"generated_at"`
+	WindowDays   int         `json:"window_days"`
+	MinGroupSize int         `json:"min_group_size"`
+	Epsilon      float64     `json:"epsilon"`
+	Suppressed   int         `json:"suppressed_groups"`
+	Data         interface{} `json:"data"`
+}
+
+func NewServer(store storage.Storage, cfg config.SharingConfig) *Server {
+	s := &Server{
+		storage: store,
+		config:  cfg,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/share/sector-sentiment", s.handleSectorSentiment)
+	mux.HandleFunc("/share/sentiment-distribution", s.handleSentimentDistribution)
+
+	s.server = &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      reporting.Middleware(mux),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return s
+}
+
+func (s *Server) Start() error {
+	log.Printf("Starting aggregate-only sharing API on %s", s.config.Addr)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Sharing API stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+func (s *Server) handleSectorSentiment(w http.ResponseWriter, r *http.Request) {
+	win := currentWindow(windowDays(r))
+	items, err := s.loadWindow(r.Context(), win)
+	if err != nil {
+		http.Error(w, "failed to load data", http.StatusInternalServerError)
+		return
+	}
+
+	type group struct {
+		count    int
+		sum      float64
+		negative int
+	}
+	groups := make(map[string]*group)
+	for _, item := range items {
+		score := sentimentOf(item)
+		for _, tag := range item.Tags {
+			sector, ok := sectorTags[tag]
+			if !ok {
+				continue
+			}
+			g, ok := groups[sector]
+			if !ok {
+				g = &group{}
+				groups[sector] = g
+			}
+			g.count++
+			g.sum += score
+			if score < 0 {
+				g.negative++
+			}
+		}
+	}
+
+	// Each sector releases three statistics, which share the budget
+	epsilon := s.config.Epsilon / 3
+	var result []SectorSentiment
+	suppressed := 0
+	for sector, g := range groups {
+		documents := s.noisyCount(win, sector+"/documents", g.count, epsilon)
+		if documents < s.config.MinGroupSize {
+			suppressed++
+			continue
+		}
+		// Sentiment is bounded in [-1, 1] so the sensitivity of the mean is 2/n.
+		n := float64(g.count)
+		result = append(result, SectorSentiment{
+			Sector:           sector,
+			Documents:        documents,
+			AverageSentiment: clamp(g.sum/n+s.laplace(win, sector+"/average", 2/n, epsilon), -1, 1),
+			NegativeShare:    clamp(float64(g.negative)/n+s.laplace(win, sector+"/negative", 1/n, epsilon), 0, 1),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Sector < result[j].Sector })
+
+	s.writeResponse(w, win, suppressed, result)
+}
+
+func (s *Server) handleSentimentDistribution(w http.ResponseWriter, r *http.Request) {
+	win := currentWindow(windowDays(r))
+	items, err := s.loadWindow(r.Context(), win)
+	if err != nil {
+		http.Error(w, "failed to load data", http.StatusInternalServerError)
+		return
+	}
+
+	counts := make([]int, len(distributionBuckets)-1)
+	for _, item := range items {
+		if item.Sentiment == nil {
+			continue
+		}
+		counts[bucketIndex(item.Sentiment.Overall)]++
+	}
+
+	// A document falls in one bucket only, so every bucket spends the whole budget
+	var buckets []DistributionBucket
+	suppressed := 0
+	for i, count := range counts {
+		bucket := DistributionBucket{Lower: distributionBuckets[i], Upper: distributionBuckets[i+1]}
+		if noisy := s.noisyCount(win, fmt.Sprintf("distribution/%d", i), count, s.config.Epsilon); noisy < s.config.MinGroupSize {
+			suppressed++
+		} else {
+			bucket.Count = noisy
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	s.writeResponse(w, win, suppressed, buckets)
+}
+
+// window is the span a response aggregates: the given number of whole UTC
+// days before today. Today's documents wait for the day to end, so one
+// window, with one draw of noise, is served all day.
+type window struct {
+	days     int
+	from, to time.Time
+}
+
+func currentWindow(days int) window {
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	return window{days: days, from: to.AddDate(0, 0, -days), to: to}
+}
+
+func (s *Server) loadWindow(ctx context.Context, win window) ([]*models.UnstructuredData, error) {
+	return s.storage.ListUnstructuredData(ctx, storage.DataFilters{DateFrom: &win.from, DateTo: &win.to})
+}
+
+func (s *Server) writeResponse(w http.ResponseWriter, win window, suppressed int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		GeneratedAt:  time.Now(),
+		WindowDays:   win.days,
+		MinGroupSize: s.config.MinGroupSize,
+		Epsilon:      s.config.Epsilon,
+		Suppressed:   suppressed,
+		Data:         data,
+	})
+}
+
+func (s *Server) noisyCount(win window, statistic string, count int, epsilon float64) int {
+	return max(int(math.Round(float64(count)+s.laplace(win, statistic, 1, epsilon))), 0)
+}
+
+// laplace returns the Laplace noise, calibrated to the given sensitivity, of
+// the statistic in win. It is drawn from a hash of both keyed by the noise
+// secret, so the statistic gets the same noise every time. A zero or
+// negative epsilon disables noise and leaves only k-anonymity suppression.
+func (s *Server) laplace(win window, statistic string, sensitivity, epsilon float64) float64 {
+	if epsilon <= 0 {
+		return 0
+	}
+	mac := hmac.New(sha256.New, []byte(s.config.NoiseSecret))
+	fmt.Fprintf(mac, "%s/%d/%s", win.to.Format("2006-01-02"), win.days, statistic)
+	// The top 53 bits make a uniform draw in (-0.5, 0.5)
+	bits := binary.BigEndian.Uint64(mac.Sum(nil)) >> 11
+	u := (float64(bits)+0.5)/(1<<53) - 0.5
+	b := sensitivity / epsilon
+	return -b * math.Copysign(1, u) * math.Log(1-2*math.Abs(u))
+}
+
+func sentimentOf(item *models.UnstructuredData) float64 {
+	if item.Sentiment != nil {
+		return item.Sentiment.Overall
+	}
+	for _, tag := range item.Tags {
+		switch tag {
+		case "positive_sentiment":
+			return 1
+		case "negative_sentiment":
+			return -1
+		}
+	}
+	return 0
+}
+
+func bucketIndex(score float64) int {
+	for i := 1; i < len(distributionBuckets)-1; i++ {
+		if score < distributionBuckets[i] {
+			return i - 1
+		}
+	}
+	return len(distributionBuckets) - 2
+}
+
+// windowDays is the window the request asks for, 30 days unless it names
+// one of windowSizes.
+func windowDays(r *http.Request) int {
+	if days, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && slices.Contains(windowSizes, days) {
+		return days
+	}
+	return 30
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
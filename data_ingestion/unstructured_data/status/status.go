@@ -0,0 +1,152 @@
+// Package status serves the operations endpoints of the ingestion service:
+// a liveness check, the activity of every source and the processing job
+// queues. They hold no documents or secrets and need no token, so load
+// balancers and dashboards can poll them.
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/ingestion"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/reporting"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// queueTimeout bounds the job counts of one /queues request.
+const queueTimeout = 10 * time.Second
+
+// Server serves /health, /status and /queues.
+type Server struct {
+	config  config.StatusConfig
+	storage storage.Storage
+	manager *ingestion.Manager
+	started time.Time
+	server  *http.Server
+}
+
+func NewServer(cfg config.StatusConfig, store storage.Storage, manager *ingestion.Manager) *Server {
+	s := &Server{config: cfg, storage: store, manager: manager, started: time.Now()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/queues", s.handleQueues)
+
+	s.server = &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      reporting.Middleware(mux),
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 30 * time.Second,
+	}
+	return s
+}
+
+func (s *Server) Start() error {
+	log.Printf("Starting status API on %s", s.config.Addr)
+	go func() {
+		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Status API stopped: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+// handleHealth answers as long as the process serves requests.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":     "ok",
+		"started_at": s.started.UTC(),
+		"uptime":     time.Since(s.started).Round(time.Second).String(),
+	})
+}
+
+// handleStatus lists every source with its last successful fetch, the
+// items it stored today and its last error.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	sources := s.manager.Sources()
+	running, failing := 0, 0
+	for _, source := range sources {
+		if source.Running {
+			running++
+		}
+		if source.LastErrorAt != nil && (source.LastFetchAt == nil || source.LastErrorAt.After(*source.LastFetchAt)) {
+			failing++
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"generated_at": time.Now().UTC(),
+		"running":      running,
+		"failing":      failing,
+		"sources":      sources,
+	})
+}
+
+// handleQueues counts the processing jobs of each type by status, with the
+// totals over every type.
+func (s *Server) handleQueues(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), queueTimeout)
+	defer cancel()
+	counts, err := s.storage.CountJobs(ctx)
+	if err != nil {
+		log.Printf("Failed to count processing jobs: %v", err)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "failed to count processing jobs"})
+		return
+	}
+
+	types := make([]string, 0, len(counts))
+	for jobType := range counts {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	var total storage.JobCounts
+	byType := make([]map[string]interface{}, 0, len(types))
+	for _, jobType := range types {
+		c := counts[jobType]
+		total.Pending += c.Pending
+		total.Retrying += c.Retrying
+		total.Processing += c.Processing
+		total.Completed += c.Completed
+		total.Dead += c.Dead
+		byType = append(byType, map[string]interface{}{"job_type": jobType, "counts": c})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"generated_at": time.Now().UTC(),
+		"total":        total,
+		"job_types":    byType,
+	})
+}
+
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
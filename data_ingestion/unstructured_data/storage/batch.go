@@ -0,0 +1,465 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/lib/pq"
+)
+
+// insertChunk keeps a multi-row insert under PostgreSQL's 65535 parameters.
+const insertChunk = 1000
+
+// documentColumns are the unstructured_data columns a document is written to.
+var documentColumns = []string{
+	"id", "source", "type", "title", "content", "url", "author", "published_at", "ingested_at",
+	"metadata", "tags", "entities", "sentiment", "processed_at", "summary", "symbols",
+}
+
+// WriteStats reports the throughput of batched document writes.
+type WriteStats struct {
+	Queued  int   `json:"queued"`
+	Batches int64 `json:"batches"`
+	Rows    int64 `json:"rows"`
+	// FallbackRows were written one by one after their batch failed
+	FallbackRows  int64         `json:"fallback_rows"`
+	FailedRows    int64         `json:"failed_rows"`
+	WriteTime     time.Duration `json:"write_time"`
+	RowsPerSecond float64       `json:"rows_per_second"`
+	LastBatch     time.Time     `json:"last_batch"`
+}
+
+// writeBuffer queues documents for PostgresStorage and writes them in
+// batches, one transaction each instead of one per document, with the rows
+// sent by COPY or multi-row inserts. Callers block once four batches are
+// queued, which holds sources back while the database falls behind.
+type writeBuffer struct {
+	storage *PostgresStorage
+	config  config.DatabaseConfig
+
+	mu     sync.Mutex
+	cond   *sync.Cond
+	queued []*models.UnstructuredData
+	// byID holds the queued documents and those being written, for reads
+	byID   map[string]*models.UnstructuredData
+	stats  WriteStats
+	closed bool
+
+	// flushMu lets one batch be written at a time
+	flushMu sync.Mutex
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newWriteBuffer(s *PostgresStorage) *writeBuffer {
+	b := &writeBuffer{
+		storage: s,
+		config:  s.config,
+		byID:    make(map[string]*models.UnstructuredData),
+		wake:    make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	b.cond = sync.NewCond(&b.mu)
+	go b.run()
+	return b
+}
+
+// WriteStats returns the throughput of batched writes, or zero stats
+// without write batching.
+func (s *PostgresStorage) WriteStats() WriteStats {
+	if s.buffer == nil {
+		return WriteStats{}
+	}
+	b := s.buffer
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stats := b.stats
+	stats.Queued = len(b.queued)
+	if stats.WriteTime > 0 {
+		stats.RowsPerSecond = float64(stats.Rows) / stats.WriteTime.Seconds()
+	}
+	return stats
+}
+
+// writeQueued writes the batch holding a document before rows referencing it.
+func (s *PostgresStorage) writeQueued(ctx context.Context, id string) {
+	if s.buffer == nil || s.buffer.get(id) == nil {
+		return
+	}
+	s.buffer.flush(ctx)
+}
+
+// add queues a copy of data, so the caller may go on changing it.
+func (b *writeBuffer) add(ctx context.Context, data *models.UnstructuredData) error {
+	queued := copyDocument(data)
+
+	b.mu.Lock()
+	for len(b.queued) >= 4*b.config.WriteBatchSize && !b.closed {
+		b.cond.Wait()
+	}
+	if b.closed {
+		b.mu.Unlock()
+		return b.storage.saveRow(ctx, data)
+	}
+	b.queued = append(b.queued, queued)
+	b.byID[queued.ID] = queued
+	full := len(b.queued) >= b.config.WriteBatchSize
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.wake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// get returns a copy of a document waiting to be written, or nil.
+func (b *writeBuffer) get(id string) *models.UnstructuredData {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if data, ok := b.byID[id]; ok {
+		return copyDocument(data)
+	}
+	return nil
+}
+
+// close writes what is queued and stops the buffer; later saves are
+// written one by one.
+func (b *writeBuffer) close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+	b.logStats()
+}
+
+func (b *writeBuffer) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.config.WriteFlushInterval)
+	defer ticker.Stop()
+	statsTicker := time.NewTicker(time.Minute)
+	defer statsTicker.Stop()
+
+	var logged int64
+	for {
+		select {
+		case <-b.wake:
+		case <-ticker.C:
+		case <-statsTicker.C:
+			if rows := b.storage.WriteStats().Rows; rows != logged {
+				logged = rows
+				b.logStats()
+			}
+			continue
+		case <-b.stop:
+			b.flush(context.Background())
+			return
+		}
+		b.flush(context.Background())
+	}
+}
+
+func (b *writeBuffer) logStats() {
+	stats := b.storage.WriteStats()
+	log.Printf("Storage writes - Rows: %d in %d batches, %.0f rows/s, Queued: %d, Fallback: %d, Failed: %d",
+		stats.Rows, stats.Batches, stats.RowsPerSecond, stats.Queued, stats.FallbackRows, stats.FailedRows)
+}
+
+// flush writes everything queued in batches of WriteBatchSize. A batch that
+// fails is written again one document at a time, so one bad document loses
+// only itself.
+func (b *writeBuffer) flush(ctx context.Context) {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	queued := b.queued
+	b.queued = nil
+	b.cond.Broadcast()
+	b.mu.Unlock()
+
+	for len(queued) > 0 {
+		n := min(b.config.WriteBatchSize, len(queued))
+		batch := queued[:n]
+		queued = queued[n:]
+
+		start := time.Now()
+		err := b.write(ctx, batch)
+		elapsed := time.Since(start)
+
+		var fallback, failed int64
+		if err != nil {
+			log.Printf("Failed to write batch of %d documents, writing them one by one: %v", len(batch), err)
+			for _, data := range batch {
+				if err := b.storage.saveRow(ctx, data); err != nil {
+					log.Printf("Failed to save %s: %v", data.ID, err)
+					failed++
+				} else {
+					fallback++
+				}
+			}
+		}
+
+		b.mu.Lock()
+		b.stats.Batches++
+		b.stats.Rows += int64(len(batch)) - failed
+		b.stats.FallbackRows += fallback
+		b.stats.FailedRows += failed
+		b.stats.WriteTime += elapsed
+		b.stats.LastBatch = time.Now()
+		for _, data := range batch {
+			// A newer version queued meanwhile stays readable
+			if b.byID[data.ID] == data {
+				delete(b.byID, data.ID)
+			}
+		}
+		b.mu.Unlock()
+	}
+}
+
+// write stores a batch in one transaction, along with the revisions and
+// analysis jobs SaveUnstructuredData records for each document.
+func (b *writeBuffer) write(ctx context.Context, batch []*models.UnstructuredData) error {
+	docs := mergeDuplicates(batch)
+
+	tx, err := b.storage.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	existing, err := lockExisting(ctx, tx, docs)
+	if err != nil {
+		return err
+	}
+	analyze := make([]bool, len(docs))
+	rows := make([][]interface{}, len(docs))
+	for i, data := range docs {
+		analyze[i] = needsAnalysis(data, existing[data.ID])
+		if rows[i], err = documentRow(data); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", data.ID, err)
+		}
+	}
+
+	if b.config.WriteMode == "copy" {
+		err = copyRows(ctx, tx, rows)
+	} else {
+		err = insertRows(ctx, tx, rows)
+	}
+	if err != nil {
+		return err
+	}
+
+	for i, data := range docs {
+		if err := b.storage.recordRevision(ctx, tx, data); err != nil {
+			return err
+		}
+		if !analyze[i] {
+			continue
+		}
+		for _, jobType := range AnalysisJobTypes {
+			if err := saveProcessingJob(ctx, tx, NewJob(data, jobType)); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// mergeDuplicates keeps the last version of each document in a batch, since
+// one statement cannot upsert a row twice, carrying forward the analysis
+// results an earlier version had and the last lacks.
+func mergeDuplicates(batch []*models.UnstructuredData) []*models.UnstructuredData {
+	index := make(map[string]int, len(batch))
+	docs := make([]*models.UnstructuredData, 0, len(batch))
+	for _, data := range batch {
+		i, ok := index[data.ID]
+		if !ok {
+			index[data.ID] = len(docs)
+			docs = append(docs, data)
+			continue
+		}
+		earlier := docs[i]
+		if data.Sentiment == nil {
+			data.Sentiment = earlier.Sentiment
+		}
+		if data.ProcessedAt == nil {
+			data.ProcessedAt = earlier.ProcessedAt
+		}
+		if data.Summary == "" {
+			data.Summary = earlier.Summary
+		}
+		docs[i] = data
+	}
+	return docs
+}
+
+// lockExisting loads and locks the stored versions of a batch's documents
+// that needsAnalysis compares against.
+func lockExisting(ctx context.Context, tx *sql.Tx, docs []*models.UnstructuredData) (map[string]*models.UnstructuredData, error) {
+	ids := make([]string, len(docs))
+	for i, data := range docs {
+		ids[i] = data.ID
+	}
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, title, content, metadata, entities FROM unstructured_data WHERE id = ANY($1) FOR UPDATE`,
+		pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load existing data: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]*models.UnstructuredData)
+	for rows.Next() {
+		var id string
+		var title, content sql.NullString
+		var metadata, entities []byte
+		if err := rows.Scan(&id, &title, &content, &metadata, &entities); err != nil {
+			return nil, fmt.Errorf("failed to load existing data: %w", err)
+		}
+		data := &models.UnstructuredData{ID: id, Title: title.String, Content: content.String}
+		// Unreadable analysis results are left to be recomputed
+		json.Unmarshal(metadata, &data.Metadata)
+		json.Unmarshal(entities, &data.Entities)
+		existing[id] = data
+	}
+	return existing, rows.Err()
+}
+
+// documentRow is a document's values for documentColumns. A document without
+// sentiment or summary writes NULL, which the upsert keeps the stored one for.
+func documentRow(data *models.UnstructuredData) ([]interface{}, error) {
+	metadata, err := json.Marshal(data.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	entities, err := json.Marshal(data.Entities)
+	if err != nil {
+		return nil, err
+	}
+	var sentiment, summary interface{}
+	if data.Sentiment != nil {
+		raw, err := json.Marshal(data.Sentiment)
+		if err != nil {
+			return nil, err
+		}
+		sentiment = string(raw)
+	}
+	if data.Summary != "" {
+		summary = data.Summary
+	}
+	var processedAt interface{}
+	if data.ProcessedAt != nil {
+		processedAt = *data.ProcessedAt
+	}
+	return []interface{}{
+		data.ID, data.Source, data.Type, data.Title, data.Content, data.URL, data.Author,
+		data.PublishedAt, data.IngestedAt, string(metadata), pq.Array(data.Tags), string(entities),
+		sentiment, processedAt, summary, pq.Array(documentSymbols(data)),
+	}, nil
+}
+
+// copyRows streams rows into a staging table with COPY and upserts them
+// from there, since COPY itself cannot resolve conflicts.
+func copyRows(ctx context.Context, tx *sql.Tx, rows [][]interface{}) error {
+	_, err := tx.ExecContext(ctx, `CREATE TEMP TABLE IF NOT EXISTS unstructured_data_incoming
+		(LIKE unstructured_data INCLUDING DEFAULTS) ON COMMIT DELETE ROWS`)
+	if err != nil {
+		return fmt.Errorf("failed to create staging table: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("unstructured_data_incoming", documentColumns...))
+	if err != nil {
+		return fmt.Errorf("failed to start copy: %w", err)
+	}
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row...); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy row: %w", err)
+		}
+	}
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to copy rows: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to copy rows: %w", err)
+	}
+
+	columns := strings.Join(documentColumns, ", ")
+	query := `INSERT INTO unstructured_data (` + columns + `)
+		SELECT ` + columns + ` FROM unstructured_data_incoming` + unstructuredDataUpsert
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to save unstructured data: %w", err)
+	}
+	return nil
+}
+
+// insertRows upserts rows with multi-row inserts of up to insertChunk rows.
+func insertRows(ctx context.Context, tx *sql.Tx, rows [][]interface{}) error {
+	for len(rows) > 0 {
+		n := min(insertChunk, len(rows))
+		var query strings.Builder
+		query.WriteString("INSERT INTO unstructured_data (" + strings.Join(documentColumns, ", ") + ") VALUES ")
+		args := make([]interface{}, 0, n*len(documentColumns))
+		for i, row := range rows[:n] {
+			if i > 0 {
+				query.WriteString(", ")
+			}
+			query.WriteString("(")
+			for j := range row {
+				if j > 0 {
+					query.WriteString(", ")
+				}
+				fmt.Fprintf(&query, "$%d", len(args)+j+1)
+			}
+			query.WriteString(")")
+			args = append(args, row...)
+		}
+		query.WriteString(unstructuredDataUpsert)
+
+		if _, err := tx.ExecContext(ctx, query.String(), args...); err != nil {
+			return fmt.Errorf("failed to save unstructured data: %w", err)
+		}
+		rows = rows[n:]
+	}
+	return nil
+}
+
+// copyDocument copies a document deep enough that changes the caller makes
+// to its fields, metadata, tags or entities do not reach the copy.
+func copyDocument(data *models.UnstructuredData) *models.UnstructuredData {
+	c := *data
+	if data.Metadata != nil {
+		c.Metadata = make(map[string]interface{}, len(data.Metadata))
+		for k, v := range data.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	c.Tags = append([]string(nil), data.Tags...)
+	c.Entities = append([]models.Entity(nil), data.Entities...)
+	if data.Sentiment != nil {
+		sentiment := *data.Sentiment
+		c.Sentiment = &sentiment
+	}
+	return &c
+}
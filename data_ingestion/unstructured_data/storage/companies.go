@@ -0,0 +1,192 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/lib/pq"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// companyTable holds the companies of the in-memory and file backends.
+// With a path, the whole table is written through to one JSON file.
+type companyTable struct {
+	path      string
+	mu        sync.RWMutex
+	companies map[string]*models.Company
+}
+
+func newCompanyTable(path string) (*companyTable, error) {
+	t := &companyTable{path: path, companies: make(map[string]*models.Company)}
+	if path == "" {
+		return t, nil
+	}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read companies: %w", err)
+	}
+	var companies []*models.Company
+	if err := json.Unmarshal(raw, &companies); err != nil {
+		return nil, fmt.Errorf("failed to parse companies %s: %w", path, err)
+	}
+	for _, company := range companies {
+		t.companies[company.ID] = company
+	}
+	return t, nil
+}
+
+func (t *companyTable) save(companies []*models.Company) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, company := range companies {
+		stored := *company
+		stored.Aliases = append([]string(nil), company.Aliases...)
+		t.companies[company.ID] = &stored
+	}
+	if t.path == "" {
+		return nil
+	}
+
+	raw, err := json.Marshal(t.sorted())
+	if err != nil {
+		return fmt.Errorf("failed to marshal companies: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return fmt.Errorf("failed to create companies directory: %w", err)
+	}
+	if err := os.WriteFile(t.path+".tmp", raw, 0644); err != nil {
+		return fmt.Errorf("failed to write companies: %w", err)
+	}
+	return os.Rename(t.path+".tmp", t.path)
+}
+
+func (t *companyTable) get(id string) (*models.Company, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	company, ok := t.companies[id]
+	if !ok {
+		return nil, fmt.Errorf("company not found")
+	}
+	copied := *company
+	return &copied, nil
+}
+
+func (t *companyTable) list() []*models.Company {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.sorted()
+}
+
+// sorted returns copies of the companies by ID; the caller holds t.mu.
+func (t *companyTable) sorted() []*models.Company {
+	companies := make([]*models.Company, 0, len(t.companies))
+	for _, company := range t.companies {
+		copied := *company
+		companies = append(companies, &copied)
+	}
+	sort.Slice(companies, func(i, j int) bool { return companies[i].ID < companies[j].ID })
+	return companies
+}
+
+func (s *InMemoryStorage) SaveCompanies(ctx context.Context, companies []*models.Company) error {
+	return s.companies.save(companies)
+}
+
+func (s *InMemoryStorage) GetCompany(ctx context.Context, id string) (*models.Company, error) {
+	return s.companies.get(id)
+}
+
+func (s *InMemoryStorage) ListCompanies(ctx context.Context) ([]*models.Company, error) {
+	return s.companies.list(), nil
+}
+
+func (fs *FileStorage) SaveCompanies(ctx context.Context, companies []*models.Company) error {
+	return fs.companies.save(companies)
+}
+
+func (fs *FileStorage) GetCompany(ctx context.Context, id string) (*models.Company, error) {
+	return fs.companies.get(id)
+}
+
+func (fs *FileStorage) ListCompanies(ctx context.Context) ([]*models.Company, error) {
+	return fs.companies.list(), nil
+}
+
+func (s *PostgresStorage) SaveCompanies(ctx context.Context, companies []*models.Company) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Empty identifiers are stored as NULL so the unique indexes skip them
+	query := `
+		INSERT INTO companies (id, name, ticker, cik, lei, aliases, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			ticker = EXCLUDED.ticker,
+			cik = EXCLUDED.cik,
+			lei = EXCLUDED.lei,
+			aliases = EXCLUDED.aliases,
+			updated_at = EXCLUDED.updated_at
+	`
+	for _, c := range companies {
+		if _, err := tx.ExecContext(ctx, query, c.ID, c.Name, c.Ticker, c.CIK, c.LEI, pq.Array(c.Aliases), c.UpdatedAt); err != nil {
+			return fmt.Errorf("failed to save company %s: %w", c.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+const companyColumns = `id, name, COALESCE(ticker, ''), COALESCE(cik, ''), COALESCE(lei, ''), aliases, updated_at`
+
+func scanCompany(row interface{ Scan(...interface{}) error }) (*models.Company, error) {
+	var c models.Company
+	if err := row.Scan(&c.ID, &c.Name, &c.Ticker, &c.CIK, &c.LEI, pq.Array(&c.Aliases), &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (s *PostgresStorage) GetCompany(ctx context.Context, id string) (*models.Company, error) {
+	company, err := scanCompany(s.db.QueryRowContext(ctx, `SELECT `+companyColumns+` FROM companies WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("company not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get company: %w", err)
+	}
+	return company, nil
+}
+
+func (s *PostgresStorage) ListCompanies(ctx context.Context) ([]*models.Company, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+companyColumns+` FROM companies ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query companies: %w", err)
+	}
+	defer rows.Close()
+
+	var companies []*models.Company
+	for rows.Next() {
+		company, err := scanCompany(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan company: %w", err)
+		}
+		companies = append(companies, company)
+	}
+	return companies, rows.Err()
+}
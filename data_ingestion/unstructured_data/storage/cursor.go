@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// ErrInvalidCursor is returned by ParseCursor for a token it did not make.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor marks a document in the listing order, newest published first
+// and by descending ID within the same time. Listing after it continues
+// with the next document however many were stored in front of it since,
+// and costs the same on the millionth page as on the first, where an
+// offset makes PostgreSQL read and drop every row before the page.
+type Cursor struct {
+	PublishedAt time.Time
+	ID          string
+}
+
+// CursorOf returns the cursor of a listed document.
+func CursorOf(data *models.UnstructuredData) *Cursor {
+	return &Cursor{PublishedAt: data.PublishedAt, ID: data.ID}
+}
+
+// String encodes the cursor as an opaque URL-safe token.
+func (c *Cursor) String() string {
+	raw := c.PublishedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseCursor decodes a token made by Cursor.String.
+func ParseCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	publishedAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{PublishedAt: publishedAt, ID: id}, nil
+}
+
+// before reports whether data lists before the cursor's document.
+func (c *Cursor) before(data *models.UnstructuredData) bool {
+	if !data.PublishedAt.Equal(c.PublishedAt) {
+		return data.PublishedAt.After(c.PublishedAt)
+	}
+	return data.ID >= c.ID
+}
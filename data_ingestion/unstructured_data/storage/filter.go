@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// filterDocuments applies filters to documents held in memory, in the order
+// PostgreSQL lists them: newest published first, then by descending ID. Query is matched loosely,
+// each of its words appearing somewhere in the title, summary or content.
+func filterDocuments(docs []*models.UnstructuredData, filters DataFilters) []*models.UnstructuredData {
+	words := strings.FieldsFunc(strings.ToLower(filters.Query), func(r rune) bool {
+		return r == ' ' || r == '"' || r == '\t'
+	})
+
+	var result []*models.UnstructuredData
+	for _, data := range docs {
+		if matchesFilters(data, filters, words) {
+			result = append(result, data)
+		}
+	}
+
+	// Documents without a cluster stand for themselves
+	if filters.Deduplicate {
+		sort.Slice(result, func(i, j int) bool { return result[i].PublishedAt.Before(result[j].PublishedAt) })
+		seen := make(map[string]bool)
+		first := result[:0]
+		for _, data := range result {
+			cluster, _ := data.Metadata["cluster_id"].(string)
+			if cluster == "" {
+				cluster = data.ID
+			}
+			if !seen[cluster] {
+				seen[cluster] = true
+				first = append(first, data)
+			}
+		}
+		result = first
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].PublishedAt.Equal(result[j].PublishedAt) {
+			return result[i].PublishedAt.After(result[j].PublishedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	if filters.After != nil {
+		start := sort.Search(len(result), func(i int) bool { return !filters.After.before(result[i]) })
+		result = result[start:]
+	}
+	if filters.Offset > 0 {
+		result = result[min(filters.Offset, len(result)):]
+	}
+	if filters.Limit > 0 && len(result) > filters.Limit {
+		result = result[:filters.Limit]
+	}
+	return result
+}
+
+func matchesFilters(data *models.UnstructuredData, filters DataFilters, words []string) bool {
+	if filters.Source != "" && data.Source != filters.Source {
+		return false
+	}
+	if filters.Type != "" && data.Type != filters.Type {
+		return false
+	}
+	if filters.DateFrom != nil && data.PublishedAt.Before(*filters.DateFrom) {
+		return false
+	}
+	if filters.DateTo != nil && data.PublishedAt.After(*filters.DateTo) {
+		return false
+	}
+	if len(filters.Tags) > 0 && !sharesAny(data.Tags, filters.Tags) {
+		return false
+	}
+	if len(filters.Symbols) > 0 && !namesAnySymbol(data, filters.Symbols) {
+		return false
+	}
+	if filters.CompanyID != "" && !sharesAny(metadataStrings(data.Metadata["company_ids"]), []string{filters.CompanyID}) {
+		return false
+	}
+	if filters.ClusterID != "" {
+		if cluster, _ := data.Metadata["cluster_id"].(string); cluster != filters.ClusterID {
+			return false
+		}
+	}
+	if len(words) > 0 {
+		text := strings.ToLower(data.Title + " " + data.Summary + " " + data.Content)
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func sharesAny(have, want []string) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// metadataStrings reads a string list from metadata, which is []interface{}
+// once it has been through JSON.
+func metadataStrings(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		var out []string
+		for _, v := range list {
+			if s, ok := v.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
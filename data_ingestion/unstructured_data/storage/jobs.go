@@ -0,0 +1,503 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// AnalysisJobTypes are the jobs queued by SaveUnstructuredData for every new
+// record, and again whenever a record's title or content changes.
+var AnalysisJobTypes = []string{"sentiment_analysis", "entity_extraction", "credit_event_classification", "quality_check"}
+
+// PriorityAgingStep is how long a pending job waits to gain one point of
+// priority, so that a steady stream of urgent jobs cannot starve the rest.
+const PriorityAgingStep = time.Minute
+
+// NewJob returns a pending job for a record, at the record's priority. The
+// ID is derived from the record and the job type, so queueing a job that
+// already exists resets it instead of adding a second one.
+func NewJob(data *models.UnstructuredData, jobType string) *models.ProcessingJob {
+	hash := md5.Sum([]byte(data.ID + jobType))
+	return &models.ProcessingJob{
+		ID:        fmt.Sprintf("job-%x", hash[:8]),
+		DataID:    data.ID,
+		JobType:   jobType,
+		Status:    "pending",
+		CreatedAt: time.Now(),
+		Priority:  JobPriority(data),
+	}
+}
+
+// JobPriority is the priority a record's jobs run at: its "priority"
+// metadata key, set by sources and the priority package, or 0.
+func JobPriority(data *models.UnstructuredData) int {
+	switch p := data.Metadata["priority"].(type) {
+	case int:
+		return p
+	case int64:
+		return int(p)
+	case float64:
+		return int(p)
+	}
+	return 0
+}
+
+// EffectivePriority is a pending job's priority raised by one point for
+// every PriorityAgingStep it has waited; GetPendingJobs returns jobs in
+// descending order of it.
+func EffectivePriority(job *models.ProcessingJob, now time.Time) float64 {
+	return float64(job.Priority) + float64(now.Sub(job.CreatedAt))/float64(PriorityAgingStep)
+}
+
+// analysisHash fingerprints the fields the analysis jobs read.
+func analysisHash(data *models.UnstructuredData) [32]byte {
+	return sha256.Sum256([]byte(data.Title + "\x00" + data.Content))
+}
+
+// analysisMetadataKeys are the metadata keys the analysis jobs set.
+var analysisMetadataKeys = []string{
+	"company_ids", "credit_events", "credit_event_types", "summary_model", "summary_cost",
+}
+
+// needsAnalysis reports whether saving data over existing, nil for a new
+// record, should queue the analysis jobs. When it should not, the analysis
+// results already stored are carried over to data, since sources re-save
+// their records without them.
+func needsAnalysis(data, existing *models.UnstructuredData) bool {
+	if existing == nil || analysisHash(data) != analysisHash(existing) {
+		return true
+	}
+	if data.Sentiment == nil {
+		data.Sentiment = existing.Sentiment
+	}
+	if data.ProcessedAt == nil {
+		data.ProcessedAt = existing.ProcessedAt
+	}
+	if data.Summary == "" {
+		data.Summary = existing.Summary
+	}
+	// Sources extract entities from their short text; the stored ones may
+	// come from the entity_extraction job's pass over the full text
+	if len(existing.Entities) > 0 {
+		data.Entities = existing.Entities
+	}
+	for _, key := range analysisMetadataKeys {
+		value, ok := existing.Metadata[key]
+		if !ok {
+			continue
+		}
+		if data.Metadata == nil {
+			data.Metadata = make(map[string]interface{})
+		}
+		if _, set := data.Metadata[key]; !set {
+			data.Metadata[key] = value
+		}
+	}
+	return false
+}
+
+// jobTable holds the processing jobs of the in-memory and file backends.
+// With a directory, every change is written through to one JSON file per
+// job so queued work survives a restart.
+type jobTable struct {
+	dir  string
+	mu   sync.Mutex
+	jobs map[string]*models.ProcessingJob
+}
+
+func newJobTable(dir string) (*jobTable, error) {
+	t := &jobTable{dir: dir, jobs: make(map[string]*models.ProcessingJob)}
+	if dir == "" {
+		return t, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create jobs directory: %w", err)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job: %w", err)
+		}
+		var job models.ProcessingJob
+		if err := json.Unmarshal(raw, &job); err != nil {
+			return nil, fmt.Errorf("failed to parse job %s: %w", path, err)
+		}
+		// A job claimed when the process stopped never finished
+		if job.Status == "processing" {
+			job.Status = "pending"
+			job.StartedAt = nil
+		}
+		t.jobs[job.ID] = &job
+	}
+	return t, nil
+}
+
+// write persists a job; the caller holds t.mu.
+func (t *jobTable) write(job *models.ProcessingJob) error {
+	if t.dir == "" {
+		return nil
+	}
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job: %w", err)
+	}
+	path := filepath.Join(t.dir, job.ID+".json")
+	if err := os.WriteFile(path+".tmp", raw, 0644); err != nil {
+		return fmt.Errorf("failed to write job: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+func (t *jobTable) save(job *models.ProcessingJob) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stored := *job
+	t.jobs[job.ID] = &stored
+	return t.write(&stored)
+}
+
+// pending returns copies of the pending jobs of a type that are due, in
+// descending order of effective priority.
+func (t *jobTable) pending(jobType string, limit int) []*models.ProcessingJob {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	var jobs []*models.ProcessingJob
+	for _, job := range t.jobs {
+		if job.Status == "pending" && job.JobType == jobType && (job.NextAttemptAt == nil || !job.NextAttemptAt.After(now)) {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return EffectivePriority(jobs[i], now) > EffectivePriority(jobs[j], now)
+	})
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs
+}
+
+func (t *jobTable) claim(jobID string) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[jobID]
+	if !ok || job.Status != "pending" {
+		return false, nil
+	}
+	now := time.Now()
+	job.Status = "processing"
+	job.StartedAt = &now
+	return true, t.write(job)
+}
+
+// list returns copies of the jobs in a status, oldest first.
+func (t *jobTable) list(status string, limit int) []*models.ProcessingJob {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var jobs []*models.ProcessingJob
+	for _, job := range t.jobs {
+		if job.Status == status {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.Before(jobs[j].CreatedAt) })
+	if limit > 0 && len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs
+}
+
+func (t *jobTable) retry(jobID, errorMsg string, at time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[jobID]
+	if !ok {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+	job.Status = "pending"
+	job.Error = errorMsg
+	job.RetryCount++
+	job.StartedAt = nil
+	job.NextAttemptAt = &at
+	return t.write(job)
+}
+
+func (t *jobTable) requeue(jobID string) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[jobID]
+	if !ok || job.Status != "dead" {
+		return false, nil
+	}
+	job.Status = "pending"
+	job.RetryCount = 0
+	job.StartedAt = nil
+	job.NextAttemptAt = nil
+	return true, t.write(job)
+}
+
+// update applies a status change the way PostgresStorage.UpdateJobStatus does.
+func (t *jobTable) update(jobID, status string, result map[string]interface{}, errorMsg string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	job, ok := t.jobs[jobID]
+	if !ok {
+		return fmt.Errorf("job %s not found", jobID)
+	}
+	now := time.Now()
+	job.Status = status
+	switch status {
+	case "completed":
+		job.CompletedAt = &now
+		job.Result = result
+		job.Error = errorMsg
+	case "processing":
+		job.StartedAt = &now
+	case "pending":
+		job.StartedAt = nil
+	default:
+		job.Error = errorMsg
+		job.RetryCount++
+	}
+	return t.write(job)
+}
+
+// execer is satisfied by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// ClaimJob moves a pending job to processing. It returns false when the job
+// is no longer pending, e.g. because another worker claimed it first.
+func (s *InMemoryStorage) ClaimJob(ctx context.Context, jobID string) (bool, error) {
+	return s.jobs.claim(jobID)
+}
+
+func (fs *FileStorage) ClaimJob(ctx context.Context, jobID string) (bool, error) {
+	return fs.jobs.claim(jobID)
+}
+
+// RetryJob records a failed attempt and returns the job to pending, to be
+// picked up again from at.
+func (s *InMemoryStorage) RetryJob(ctx context.Context, jobID string, errorMsg string, at time.Time) error {
+	return s.jobs.retry(jobID, errorMsg, at)
+}
+
+func (fs *FileStorage) RetryJob(ctx context.Context, jobID string, errorMsg string, at time.Time) error {
+	return fs.jobs.retry(jobID, errorMsg, at)
+}
+
+func (s *PostgresStorage) RetryJob(ctx context.Context, jobID string, errorMsg string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE processing_jobs
+		SET status = 'pending', error = $1, retry_count = retry_count + 1,
+			started_at = NULL, next_attempt_at = $2
+		WHERE id = $3
+	`, errorMsg, at, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to schedule job retry: %w", err)
+	}
+	return nil
+}
+
+// ListJobs returns up to limit jobs in a status, oldest first.
+func (s *InMemoryStorage) ListJobs(ctx context.Context, status string, limit int) ([]*models.ProcessingJob, error) {
+	return s.jobs.list(status, limit), nil
+}
+
+func (fs *FileStorage) ListJobs(ctx context.Context, status string, limit int) ([]*models.ProcessingJob, error) {
+	return fs.jobs.list(status, limit), nil
+}
+
+func (s *PostgresStorage) ListJobs(ctx context.Context, status string, limit int) ([]*models.ProcessingJob, error) {
+	query := `
+		SELECT id, data_id, job_type, status, created_at, started_at, completed_at,
+			   result, error, retry_count, priority, next_attempt_at
+		FROM processing_jobs
+		WHERE status = $1
+		ORDER BY created_at ASC
+		LIMIT $2
+	`
+	rows, err := s.db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query jobs: %w", err)
+	}
+	defer rows.Close()
+	return scanJobs(rows)
+}
+
+// JobCounts counts the jobs of one type by status. Retrying are the pending
+// jobs that failed before and wait for another attempt; they are also
+// counted as pending.
+type JobCounts struct {
+	Pending    int `json:"pending"`
+	Retrying   int `json:"retrying"`
+	Processing int `json:"processing"`
+	Completed  int `json:"completed"`
+	Dead       int `json:"dead"`
+}
+
+// add counts n jobs in status, retried ones among them.
+func (c *JobCounts) add(status string, n, retried int) {
+	switch status {
+	case "pending":
+		c.Pending += n
+		c.Retrying += retried
+	case "processing":
+		c.Processing += n
+	case "completed":
+		c.Completed += n
+	case "dead":
+		c.Dead += n
+	}
+}
+
+func (t *jobTable) count() map[string]*JobCounts {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	counts := make(map[string]*JobCounts)
+	for _, job := range t.jobs {
+		c := counts[job.JobType]
+		if c == nil {
+			c = &JobCounts{}
+			counts[job.JobType] = c
+		}
+		retried := 0
+		if job.RetryCount > 0 {
+			retried = 1
+		}
+		c.add(job.Status, 1, retried)
+	}
+	return counts
+}
+
+func (s *InMemoryStorage) CountJobs(ctx context.Context) (map[string]*JobCounts, error) {
+	return s.jobs.count(), nil
+}
+
+func (fs *FileStorage) CountJobs(ctx context.Context) (map[string]*JobCounts, error) {
+	return fs.jobs.count(), nil
+}
+
+func (s *PostgresStorage) CountJobs(ctx context.Context) (map[string]*JobCounts, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT job_type, status, COUNT(*), COUNT(*) FILTER (WHERE retry_count > 0)
+		FROM processing_jobs
+		GROUP BY job_type, status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count jobs: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]*JobCounts)
+	for rows.Next() {
+		var jobType, status string
+		var n, retried int
+		if err := rows.Scan(&jobType, &status, &n, &retried); err != nil {
+			return nil, fmt.Errorf("failed to scan job count: %w", err)
+		}
+		c := counts[jobType]
+		if c == nil {
+			c = &JobCounts{}
+			counts[jobType] = c
+		}
+		c.add(status, n, retried)
+	}
+	return counts, rows.Err()
+}
+
+// RequeueJob returns a dead job to pending with a fresh set of attempts. It
+// returns false when the job is not dead.
+func (s *InMemoryStorage) RequeueJob(ctx context.Context, jobID string) (bool, error) {
+	return s.jobs.requeue(jobID)
+}
+
+func (fs *FileStorage) RequeueJob(ctx context.Context, jobID string) (bool, error) {
+	return fs.jobs.requeue(jobID)
+}
+
+func (s *PostgresStorage) RequeueJob(ctx context.Context, jobID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE processing_jobs
+		SET status = 'pending', retry_count = 0, started_at = NULL, next_attempt_at = NULL
+		WHERE id = $1 AND status = 'dead'
+	`, jobID)
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue job: %w", err)
+	}
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue job: %w", err)
+	}
+	return requeued == 1, nil
+}
+
+// scanJobs reads processing_jobs rows selected with every column.
+func scanJobs(rows *sql.Rows) ([]*models.ProcessingJob, error) {
+	var jobs []*models.ProcessingJob
+	for rows.Next() {
+		var job models.ProcessingJob
+		var resultJSON []byte
+
+		err := rows.Scan(
+			&job.ID, &job.DataID, &job.JobType, &job.Status, &job.CreatedAt,
+			&job.StartedAt, &job.CompletedAt, &resultJSON, &job.Error,
+			&job.RetryCount, &job.Priority, &job.NextAttemptAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job row: %w", err)
+		}
+
+		if len(resultJSON) > 0 {
+			if err := json.Unmarshal(resultJSON, &job.Result); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+			}
+		}
+
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
+
+func (s *PostgresStorage) ClaimJob(ctx context.Context, jobID string) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE processing_jobs
+		SET status = 'processing', started_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+	`, jobID)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	claimed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return claimed == 1, nil
+}
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS data_quality;
+DROP TABLE IF EXISTS processing_jobs;
+DROP TABLE IF EXISTS unstructured_data;
@@ -0,0 +1,57 @@
+-- The schema createTables created before migrations were versioned. Every
+-- statement tolerates existing objects, so databases it set up adopt this
+-- version without changes.
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE IF NOT EXISTS unstructured_data (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	source VARCHAR(100) NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	title TEXT,
+	content TEXT,
+	url TEXT,
+	author VARCHAR(255),
+	published_at TIMESTAMP WITH TIME ZONE,
+	ingested_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+	metadata JSONB,
+	tags TEXT[],
+	entities JSONB,
+	sentiment JSONB,
+	processed_at TIMESTAMP WITH TIME ZONE,
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS processing_jobs (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	data_id UUID REFERENCES unstructured_data(id),
+	job_type VARCHAR(50) NOT NULL,
+	status VARCHAR(20) DEFAULT 'pending',
+	created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+	started_at TIMESTAMP WITH TIME ZONE,
+	completed_at TIMESTAMP WITH TIME ZONE,
+	result JSONB,
+	error TEXT,
+	retry_count INTEGER DEFAULT 0,
+	priority INTEGER DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS data_quality (
+	id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+	data_id UUID REFERENCES unstructured_data(id),
+	source VARCHAR(100) NOT NULL,
+	quality_score DECIMAL(3,2),
+	completeness_score DECIMAL(3,2),
+	accuracy_score DECIMAL(3,2),
+	freshness_score DECIMAL(3,2),
+	issues TEXT[],
+	checked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_source ON unstructured_data(source);
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_type ON unstructured_data(type);
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_published_at ON unstructured_data(published_at);
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_tags ON unstructured_data USING GIN(tags);
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_status ON processing_jobs(status);
+CREATE INDEX IF NOT EXISTS idx_processing_jobs_type ON processing_jobs(job_type);
+CREATE INDEX IF NOT EXISTS idx_data_quality_source ON data_quality(source);
@@ -0,0 +1,2 @@
+ALTER TABLE unstructured_data DROP COLUMN IF EXISTS summary;
+ALTER TABLE processing_jobs DROP COLUMN IF EXISTS next_attempt_at;
@@ -0,0 +1,2 @@
+ALTER TABLE processing_jobs ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE unstructured_data ADD COLUMN IF NOT EXISTS summary TEXT;
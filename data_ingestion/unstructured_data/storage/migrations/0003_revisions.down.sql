@@ -0,0 +1 @@
+DROP TABLE IF EXISTS unstructured_data_revisions;
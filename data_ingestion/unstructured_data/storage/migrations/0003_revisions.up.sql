@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS unstructured_data_revisions (
+	data_id UUID NOT NULL REFERENCES unstructured_data(id),
+	revision INTEGER NOT NULL,
+	content_hash VARCHAR(64) NOT NULL,
+	document JSONB NOT NULL,
+	recorded_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+	PRIMARY KEY (data_id, revision)
+);
+
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_revisions_recorded ON unstructured_data_revisions(data_id, recorded_at);
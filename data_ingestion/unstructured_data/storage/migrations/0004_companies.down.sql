@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_unstructured_data_company_ids;
+DROP TABLE IF EXISTS companies;
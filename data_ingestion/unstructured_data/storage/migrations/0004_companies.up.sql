@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS companies (
+	id VARCHAR(64) PRIMARY KEY,
+	name TEXT NOT NULL,
+	ticker VARCHAR(20),
+	cik VARCHAR(10),
+	lei VARCHAR(20),
+	aliases TEXT[],
+	updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_companies_ticker ON companies(ticker);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_companies_cik ON companies(cik);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_companies_lei ON companies(lei);
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_company_ids ON unstructured_data USING GIN((metadata->'company_ids'));
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_unstructured_data_cluster_id;
+DROP INDEX IF EXISTS idx_unstructured_data_credit_event_types;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_credit_event_types ON unstructured_data USING GIN((metadata->'credit_event_types'));
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_cluster_id ON unstructured_data ((metadata->>'cluster_id'));
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_unstructured_data_search_vector;
+ALTER TABLE unstructured_data DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,7 @@
+-- Titles weigh most, then summaries, then content, for ranking matches.
+ALTER TABLE unstructured_data ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(summary, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(content, '')), 'C')
+) STORED;
+CREATE INDEX idx_unstructured_data_search_vector ON unstructured_data USING GIN(search_vector);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_unstructured_data_symbols;
+ALTER TABLE unstructured_data DROP COLUMN IF EXISTS symbols;
@@ -0,0 +1,14 @@
+-- The symbols a document's metadata names, upper-cased, kept in a column so
+-- they can be filtered through an index. Documents stored before it are
+-- filled in from their metadata.
+ALTER TABLE unstructured_data ADD COLUMN IF NOT EXISTS symbols TEXT[];
+UPDATE unstructured_data SET symbols = ARRAY(
+    SELECT DISTINCT UPPER(symbol) FROM (
+        SELECT metadata->>'primary_symbol' AS symbol
+        UNION ALL SELECT metadata->>'symbol'
+        UNION ALL SELECT jsonb_array_elements_text(
+            CASE WHEN jsonb_typeof(metadata->'symbols') = 'array' THEN metadata->'symbols' ELSE '[]'::jsonb END)
+    ) named
+    WHERE symbol <> ''
+);
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_symbols ON unstructured_data USING GIN(symbols);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_unstructured_data_published_at_id;
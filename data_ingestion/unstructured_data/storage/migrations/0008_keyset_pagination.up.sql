@@ -0,0 +1,3 @@
+-- Keyset pagination walks documents newest first by (published_at, id),
+-- which the index serves without sorting.
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_published_at_id ON unstructured_data (published_at DESC, id DESC);
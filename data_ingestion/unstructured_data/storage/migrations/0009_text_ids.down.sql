@@ -0,0 +1,15 @@
+-- Fails while any ID stored is not a UUID; those rows have to go first.
+ALTER TABLE processing_jobs DROP CONSTRAINT IF EXISTS processing_jobs_data_id_fkey;
+ALTER TABLE data_quality DROP CONSTRAINT IF EXISTS data_quality_data_id_fkey;
+ALTER TABLE unstructured_data_revisions DROP CONSTRAINT IF EXISTS unstructured_data_revisions_data_id_fkey;
+
+ALTER TABLE unstructured_data_revisions ALTER COLUMN data_id TYPE UUID USING data_id::uuid;
+ALTER TABLE data_quality ALTER COLUMN data_id TYPE UUID USING data_id::uuid;
+ALTER TABLE data_quality ALTER COLUMN id DROP DEFAULT, ALTER COLUMN id TYPE UUID USING id::uuid, ALTER COLUMN id SET DEFAULT uuid_generate_v4();
+ALTER TABLE processing_jobs ALTER COLUMN data_id TYPE UUID USING data_id::uuid;
+ALTER TABLE processing_jobs ALTER COLUMN id DROP DEFAULT, ALTER COLUMN id TYPE UUID USING id::uuid, ALTER COLUMN id SET DEFAULT uuid_generate_v4();
+ALTER TABLE unstructured_data ALTER COLUMN id DROP DEFAULT, ALTER COLUMN id TYPE UUID USING id::uuid, ALTER COLUMN id SET DEFAULT uuid_generate_v4();
+
+ALTER TABLE processing_jobs ADD CONSTRAINT processing_jobs_data_id_fkey FOREIGN KEY (data_id) REFERENCES unstructured_data(id);
+ALTER TABLE data_quality ADD CONSTRAINT data_quality_data_id_fkey FOREIGN KEY (data_id) REFERENCES unstructured_data(id);
+ALTER TABLE unstructured_data_revisions ADD CONSTRAINT unstructured_data_revisions_data_id_fkey FOREIGN KEY (data_id) REFERENCES unstructured_data(id);
@@ -0,0 +1,17 @@
+-- Documents and jobs are stored under IDs of their own, such as
+-- "reuters-3f9a..." and "job-12ab...", which a UUID column refuses. The
+-- foreign keys are dropped while the columns they join change type.
+ALTER TABLE processing_jobs DROP CONSTRAINT IF EXISTS processing_jobs_data_id_fkey;
+ALTER TABLE data_quality DROP CONSTRAINT IF EXISTS data_quality_data_id_fkey;
+ALTER TABLE unstructured_data_revisions DROP CONSTRAINT IF EXISTS unstructured_data_revisions_data_id_fkey;
+
+ALTER TABLE unstructured_data ALTER COLUMN id TYPE TEXT, ALTER COLUMN id SET DEFAULT uuid_generate_v4()::text;
+ALTER TABLE processing_jobs ALTER COLUMN id TYPE TEXT, ALTER COLUMN id SET DEFAULT uuid_generate_v4()::text;
+ALTER TABLE processing_jobs ALTER COLUMN data_id TYPE TEXT;
+ALTER TABLE data_quality ALTER COLUMN id TYPE TEXT, ALTER COLUMN id SET DEFAULT uuid_generate_v4()::text;
+ALTER TABLE data_quality ALTER COLUMN data_id TYPE TEXT;
+ALTER TABLE unstructured_data_revisions ALTER COLUMN data_id TYPE TEXT;
+
+ALTER TABLE processing_jobs ADD CONSTRAINT processing_jobs_data_id_fkey FOREIGN KEY (data_id) REFERENCES unstructured_data(id);
+ALTER TABLE data_quality ADD CONSTRAINT data_quality_data_id_fkey FOREIGN KEY (data_id) REFERENCES unstructured_data(id);
+ALTER TABLE unstructured_data_revisions ADD CONSTRAINT unstructured_data_revisions_data_id_fkey FOREIGN KEY (data_id) REFERENCES unstructured_data(id);
@@ -0,0 +1,11 @@
+DROP INDEX IF EXISTS idx_unstructured_data_revisions_seq;
+
+ALTER TABLE unstructured_data_revisions ADD COLUMN revision INTEGER;
+UPDATE unstructured_data_revisions r SET revision = o.n
+FROM (SELECT seq, row_number() OVER (PARTITION BY data_id ORDER BY seq) AS n FROM unstructured_data_revisions) o
+WHERE r.seq = o.seq;
+ALTER TABLE unstructured_data_revisions ALTER COLUMN revision SET NOT NULL;
+
+ALTER TABLE unstructured_data_revisions DROP CONSTRAINT unstructured_data_revisions_pkey;
+ALTER TABLE unstructured_data_revisions DROP COLUMN seq;
+ALTER TABLE unstructured_data_revisions ADD PRIMARY KEY (data_id, revision);
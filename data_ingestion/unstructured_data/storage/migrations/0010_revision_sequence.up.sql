@@ -0,0 +1,15 @@
+-- Revisions were numbered by the highest number stored plus one, so two
+-- saves of a document racing would both take the same number and one of
+-- them was dropped. They are now keyed by a sequence instead, and numbered
+-- in its order when read. Existing revisions keep their order.
+ALTER TABLE unstructured_data_revisions ADD COLUMN seq BIGSERIAL;
+UPDATE unstructured_data_revisions r SET seq = o.n
+FROM (SELECT data_id, revision, row_number() OVER (ORDER BY data_id, revision) AS n FROM unstructured_data_revisions) o
+WHERE r.data_id = o.data_id AND r.revision = o.revision;
+SELECT setval(pg_get_serial_sequence('unstructured_data_revisions', 'seq'), COALESCE(MAX(seq), 0) + 1, false) FROM unstructured_data_revisions;
+
+ALTER TABLE unstructured_data_revisions DROP CONSTRAINT unstructured_data_revisions_pkey;
+ALTER TABLE unstructured_data_revisions ADD PRIMARY KEY (seq);
+ALTER TABLE unstructured_data_revisions DROP COLUMN revision;
+
+CREATE INDEX IF NOT EXISTS idx_unstructured_data_revisions_seq ON unstructured_data_revisions(data_id, seq);
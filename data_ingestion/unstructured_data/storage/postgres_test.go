@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// testPostgres opens and migrates the database TEST_DATABASE_URL points at,
+// skipping the test without one.
+func testPostgres(t *testing.T) *PostgresStorage {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	s, err := NewPostgresStorage(config.DatabaseConfig{Type: "postgres", URL: url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// saveTestDocuments saves docs under IDs of their own and deletes them when
+// the test ends.
+func saveTestDocuments(t *testing.T, s *PostgresStorage, docs ...*models.UnstructuredData) {
+	t.Helper()
+	ctx := context.Background()
+	var ids []string
+	for _, doc := range docs {
+		if err := s.SaveUnstructuredData(ctx, doc); err != nil {
+			t.Fatalf("saving %s: %v", doc.ID, err)
+		}
+		ids = append(ids, doc.ID)
+	}
+	t.Cleanup(func() {
+		if err := s.DeleteUnstructuredData(context.Background(), ids); err != nil {
+			t.Errorf("deleting test documents: %v", err)
+		}
+	})
+}
+
+func TestPostgresDocuments(t *testing.T) {
+	s := testPostgres(t)
+	ctx := context.Background()
+	prefix := fmt.Sprintf("test-%d-", time.Now().UnixNano())
+	published := time.Now().UTC().Truncate(time.Second)
+	newer := &models.UnstructuredData{
+		ID: prefix + "newer", Source: "test", Type: "news",
+		Title: "Acme bonds rally", Content: "Credit spreads tighten after earnings",
+		PublishedAt: published, IngestedAt: published,
+		Tags:     []string{"credit", "earnings"},
+		Metadata: map[string]interface{}{"symbol": "acme"},
+	}
+	older := &models.UnstructuredData{
+		ID: prefix + "older", Source: "test", Type: "news",
+		Title: "Treasury yields climb", Content: "Rates move higher ahead of the auction",
+		PublishedAt: published.Add(-time.Hour), IngestedAt: published,
+		Tags:     []string{"rates"},
+		Metadata: map[string]interface{}{"symbols": []string{"BRK-B"}},
+	}
+	saveTestDocuments(t, s, newer, older)
+
+	got, err := s.GetUnstructuredData(ctx, newer.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Tags, newer.Tags) {
+		t.Errorf("tags = %q, want %q", got.Tags, newer.Tags)
+	}
+
+	tests := []struct {
+		name    string
+		filters DataFilters
+		want    []*models.UnstructuredData
+	}{
+		{"all", DataFilters{}, []*models.UnstructuredData{newer, older}},
+		{"tags", DataFilters{Tags: []string{"rates", "unused"}}, []*models.UnstructuredData{older}},
+		{"symbols", DataFilters{Symbols: []string{"acme"}}, []*models.UnstructuredData{newer}},
+		{"query", DataFilters{Query: "spreads -auction"}, []*models.UnstructuredData{newer}},
+		{"cursor", DataFilters{After: CursorOf(newer)}, []*models.UnstructuredData{older}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.Source = "test"
+			listed, err := s.ListUnstructuredData(ctx, tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids, want []string
+			for _, doc := range listed {
+				if strings.HasPrefix(doc.ID, prefix) {
+					ids = append(ids, doc.ID)
+				}
+			}
+			for _, doc := range tt.want {
+				want = append(want, doc.ID)
+			}
+			if !reflect.DeepEqual(ids, want) {
+				t.Errorf("listed %q, want %q", ids, want)
+			}
+		})
+	}
+}
+
+func TestPostgresRevisions(t *testing.T) {
+	s := testPostgres(t)
+	ctx := context.Background()
+	id := fmt.Sprintf("test-%d", time.Now().UnixNano())
+	doc := &models.UnstructuredData{
+		ID: id, Source: "test", Type: "news", Title: "First title",
+		PublishedAt: time.Now().UTC(), IngestedAt: time.Now().UTC(),
+	}
+	saveTestDocuments(t, s, doc)
+
+	revised := *doc
+	revised.Title = "Second title"
+	for _, save := range []*models.UnstructuredData{&revised, &revised} {
+		if err := s.SaveUnstructuredData(ctx, save); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revisions, err := s.ListRevisions(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("revisions = %d, want 2, the unchanged save adding none", len(revisions))
+	}
+	for i, rev := range revisions {
+		if rev.Revision != i+1 {
+			t.Errorf("revision %d numbered %d", i+1, rev.Revision)
+		}
+	}
+	if revisions[1].Data.Title != revised.Title {
+		t.Errorf("latest revision title = %q, want %q", revisions[1].Data.Title, revised.Title)
+	}
+
+	asOf, err := s.GetUnstructuredDataAsOf(ctx, id, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asOf.Title != revised.Title {
+		t.Errorf("title as of now = %q, want %q", asOf.Title, revised.Title)
+	}
+}
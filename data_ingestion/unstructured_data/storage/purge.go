@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lib/pq"
+)
+
+func (s *InMemoryStorage) DeleteUnstructuredData(ctx context.Context, ids []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.data, id)
+		delete(s.revisions, id)
+	}
+	return nil
+}
+
+func (fs *FileStorage) DeleteUnstructuredData(ctx context.Context, ids []string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	for _, id := range ids {
+		matches, err := filepath.Glob(filepath.Join(fs.dataDir, "*", fmt.Sprintf("%s_*.json", id)))
+		if err != nil {
+			return fmt.Errorf("failed to find %s: %w", id, err)
+		}
+		for _, path := range matches {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete %s: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteUnstructuredData removes documents along with their jobs, quality
+// checks and revisions, which reference them.
+func (s *PostgresStorage) DeleteUnstructuredData(ctx context.Context, ids []string) error {
+	// A newer version still queued would otherwise be written after the delete
+	for _, id := range ids {
+		s.writeQueued(ctx, id)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"processing_jobs", "data_quality", "unstructured_data_revisions"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE data_id = ANY($1)`, pq.Array(ids)); err != nil {
+			return fmt.Errorf("failed to delete from %s: %w", table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM unstructured_data WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to delete unstructured data: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// revisionSnapshot returns a deep copy of data and the hash that identifies its stored
+// state. IngestedAt is left out of the hash so re-polling an unchanged item does not
+// create a new revision.
+func revisionSnapshot(data *models.UnstructuredData) (*models.UnstructuredData, string, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal revision: %w", err)
+	}
+
+	var snapshot models.UnstructuredData
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return nil, "", fmt.Errorf("failed to copy revision: %w", err)
+	}
+
+	hashed := snapshot
+	hashed.IngestedAt = time.Time{}
+	hashRaw, err := json.Marshal(hashed)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal revision: %w", err)
+	}
+	sum := sha256.Sum256(hashRaw)
+	return &snapshot, hex.EncodeToString(sum[:]), nil
+}
+
+// revisionAsOf returns the latest revision recorded at or before asOf
+func revisionAsOf(revisions []*models.DocumentRevision, asOf time.Time) *models.DocumentRevision {
+	var found *models.DocumentRevision
+	for _, rev := range revisions {
+		if rev.RecordedAt.After(asOf) {
+			break
+		}
+		found = rev
+	}
+	return found
+}
+
+func (s *InMemoryStorage) recordRevision(data *models.UnstructuredData) error {
+	snapshot, hash, err := revisionSnapshot(data)
+	if err != nil {
+		return err
+	}
+
+	history := s.revisions[data.ID]
+	if n := len(history); n > 0 && history[n-1].ContentHash == hash {
+		return nil
+	}
+	s.revisions[data.ID] = append(history, &models.DocumentRevision{
+		DataID:      data.ID,
+		Revision:    len(history) + 1,
+		ContentHash: hash,
+		RecordedAt:  time.Now(),
+		Data:        snapshot,
+	})
+	return nil
+}
+
+func (s *InMemoryStorage) GetUnstructuredDataAsOf(ctx context.Context, id string, asOf time.Time) (*models.UnstructuredData, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rev := revisionAsOf(s.revisions[id], asOf)
+	if rev == nil {
+		return nil, fmt.Errorf("%w as of %s", ErrNotFound, asOf.Format(time.RFC3339))
+	}
+	return rev.Data, nil
+}
+
+func (s *InMemoryStorage) ListRevisions(ctx context.Context, id string) ([]*models.DocumentRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]*models.DocumentRevision(nil), s.revisions[id]...), nil
+}
+
+// File storage never overwrites a document, so its single version is the only revision
+func (fs *FileStorage) GetUnstructuredDataAsOf(ctx context.Context, id string, asOf time.Time) (*models.UnstructuredData, error) {
+	data, err := fs.GetUnstructuredData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if data.IngestedAt.After(asOf) {
+		return nil, fmt.Errorf("%w as of %s", ErrNotFound, asOf.Format(time.RFC3339))
+	}
+	return data, nil
+}
+
+func (fs *FileStorage) ListRevisions(ctx context.Context, id string) ([]*models.DocumentRevision, error) {
+	data, err := fs.GetUnstructuredData(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return []*models.DocumentRevision{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, hash, err := revisionSnapshot(data)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.DocumentRevision{{
+		DataID:      data.ID,
+		Revision:    1,
+		ContentHash: hash,
+		RecordedAt:  data.IngestedAt,
+		Data:        data,
+	}}, nil
+}
+
+// recordRevision appends a revision inside the save transaction unless the latest
+// revision already has the same content hash. Revisions are keyed by a sequence and
+// numbered in its order when read, so two saves racing both keep their revision.
+func (s *PostgresStorage) recordRevision(ctx context.Context, tx *sql.Tx, data *models.UnstructuredData) error {
+	snapshot, hash, err := revisionSnapshot(data)
+	if err != nil {
+		return err
+	}
+	document, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to marshal revision: %w", err)
+	}
+
+	query := `
+		INSERT INTO unstructured_data_revisions (data_id, content_hash, document)
+		SELECT $1, $2, $3
+		WHERE COALESCE((
+			SELECT content_hash FROM unstructured_data_revisions
+			WHERE data_id = $1 ORDER BY seq DESC LIMIT 1
+		), '') <> $2
+	`
+	if _, err := tx.ExecContext(ctx, query, data.ID, hash, string(document)); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStorage) GetUnstructuredDataAsOf(ctx context.Context, id string, asOf time.Time) (*models.UnstructuredData, error) {
+	query := `
+		SELECT document FROM unstructured_data_revisions
+		WHERE data_id = $1 AND recorded_at <= $2
+		ORDER BY seq DESC
+		LIMIT 1
+	`
+
+	var document []byte
+	err := s.db.QueryRowContext(ctx, query, id, asOf).Scan(&document)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w as of %s", ErrNotFound, asOf.Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	var data models.UnstructuredData
+	if err := json.Unmarshal(document, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal revision: %w", err)
+	}
+	return &data, nil
+}
+
+func (s *PostgresStorage) ListRevisions(ctx context.Context, id string) ([]*models.DocumentRevision, error) {
+	query := `
+		SELECT data_id, ROW_NUMBER() OVER (ORDER BY seq), content_hash, recorded_at, document
+		FROM unstructured_data_revisions
+		WHERE data_id = $1
+		ORDER BY seq ASC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*models.DocumentRevision
+	for rows.Next() {
+		var rev models.DocumentRevision
+		var document []byte
+		if err := rows.Scan(&rev.DataID, &rev.Revision, &rev.ContentHash, &rev.RecordedAt, &document); err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		if err := json.Unmarshal(document, &rev.Data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal revision: %w", err)
+		}
+		revisions = append(revisions, &rev)
+	}
+	return revisions, rows.Err()
+}
@@ -4,27 +4,50 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
 	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// ErrNotFound is returned for a document that is not stored.
+var ErrNotFound = errors.New("data not found")
+
 type Storage interface {
 	SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error
 	GetUnstructuredData(ctx context.Context, id string) (*models.UnstructuredData, error)
+	// GetUnstructuredDataAsOf returns the document as it was stored at asOf
+	GetUnstructuredDataAsOf(ctx context.Context, id string, asOf time.Time) (*models.UnstructuredData, error)
+	ListRevisions(ctx context.Context, id string) ([]*models.DocumentRevision, error)
 	ListUnstructuredData(ctx context.Context, filters DataFilters) ([]*models.UnstructuredData, error)
+	// DeleteUnstructuredData removes documents for good; missing IDs are skipped
+	DeleteUnstructuredData(ctx context.Context, ids []string) error
 	SaveProcessingJob(ctx context.Context, job *models.ProcessingJob) error
 	GetPendingJobs(ctx context.Context, jobType string, limit int) ([]*models.ProcessingJob, error)
+	// ClaimJob atomically moves a pending job to processing, returning false if it was not pending
+	ClaimJob(ctx context.Context, jobID string) (bool, error)
+	// RetryJob returns a failed job to pending, not to be picked up before at
+	RetryJob(ctx context.Context, jobID string, errorMsg string, at time.Time) error
+	ListJobs(ctx context.Context, status string, limit int) ([]*models.ProcessingJob, error)
+	// CountJobs counts the jobs of each type by status
+	CountJobs(ctx context.Context) (map[string]*JobCounts, error)
+	// RequeueJob returns a dead job to pending, returning false if it was not dead
+	RequeueJob(ctx context.Context, jobID string) (bool, error)
 	UpdateJobStatus(ctx context.Context, jobID string, status string, result map[string]interface{}, errorMsg string) error
 	SaveDataQuality(ctx context.Context, quality *models.DataQuality) error
 	GetDataQualityStats(ctx context.Context, source string, since time.Time) (*DataQualityStats, error)
+	// SaveCompanies inserts or replaces canonical company records by ID
+	SaveCompanies(ctx context.Context, companies []*models.Company) error
+	GetCompany(ctx context.Context, id string) (*models.Company, error)
+	ListCompanies(ctx context.Context) ([]*models.Company, error)
 	Close() error
 }
 
@@ -35,8 +58,20 @@ type DataFilters struct {
 	DateTo   *time.Time
 	Tags     []string
 	Symbols  []string
-	Limit    int
-	Offset   int
+	// CompanyID selects the documents resolved to a company
+	CompanyID string
+	// ClusterID selects the near-duplicates of one story
+	ClusterID string
+	// Deduplicate returns one document per cluster, its first published
+	Deduplicate bool
+	// Query matches titles, summaries and content in web search syntax:
+	// words are all required, "quoted phrases" match in order, or
+	// alternates and -word excludes
+	Query string
+	// After lists the documents following a cursor, in place of Offset
+	After  *Cursor
+	Limit  int
+	Offset int
 }
 
 type DataQualityStats struct {
@@ -51,16 +86,26 @@ type DataQualityStats struct {
 type PostgresStorage struct {
 	db     *sql.DB
 	config config.DatabaseConfig
+	// buffer batches document writes; nil writes each as it is saved
+	buffer *writeBuffer
 }
 
 type InMemoryStorage struct {
-	data map[string]*models.UnstructuredData
-	mu   sync.RWMutex
+	data      map[string]*models.UnstructuredData
+	revisions map[string][]*models.DocumentRevision
+	jobs      *jobTable
+	companies *companyTable
+	mu        sync.RWMutex
 }
 
 func NewInMemoryStorage() *InMemoryStorage {
+	jobs, _ := newJobTable("")
+	companies, _ := newCompanyTable("")
 	return &InMemoryStorage{
-		data: make(map[string]*models.UnstructuredData),
+		data:      make(map[string]*models.UnstructuredData),
+		revisions: make(map[string][]*models.DocumentRevision),
+		jobs:      jobs,
+		companies: companies,
 	}
 }
 
@@ -68,7 +113,18 @@ func (s *InMemoryStorage) SaveUnstructuredData(ctx context.Context, data *models
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	analyze := needsAnalysis(data, s.data[data.ID])
 	s.data[data.ID] = data
+	if err := s.recordRevision(data); err != nil {
+		return err
+	}
+	if analyze {
+		for _, jobType := range AnalysisJobTypes {
+			if err := s.jobs.save(NewJob(data, jobType)); err != nil {
+				return err
+			}
+		}
+	}
 
 	log.Printf("Saved data with ID: %s, Title: %s", data.ID, data.Title)
 	return nil
@@ -80,7 +136,7 @@ func (s *InMemoryStorage) GetUnstructuredData(ctx context.Context, id string) (*
 
 	data, exists := s.data[id]
 	if !exists {
-		return nil, fmt.Errorf("data not found")
+		return nil, ErrNotFound
 	}
 	return data, nil
 }
@@ -89,34 +145,32 @@ func (s *InMemoryStorage) ListUnstructuredData(ctx context.Context, filters Data
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	var result []*models.UnstructuredData
+	docs := make([]*models.UnstructuredData, 0, len(s.data))
 	for _, data := range s.data {
-		result = append(result, data)
+		docs = append(docs, data)
 	}
-	return result, nil
+	return filterDocuments(docs, filters), nil
 }
 
 func (s *InMemoryStorage) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.data = make(map[string]*models.UnstructuredData)
+	s.revisions = make(map[string][]*models.DocumentRevision)
 	log.Println("In-memory storage closed")
 	return nil
 }
 
 func (s *InMemoryStorage) SaveProcessingJob(ctx context.Context, job *models.ProcessingJob) error {
-	
-	log.Printf("Processing job saved (in-memory): %s - %s", job.ID, job.JobType)
-	return nil
+	return s.jobs.save(job)
 }
 
 func (s *InMemoryStorage) GetPendingJobs(ctx context.Context, jobType string, limit int) ([]*models.ProcessingJob, error) {
-	return []*models.ProcessingJob{}, nil
+	return s.jobs.pending(jobType, limit), nil
 }
 
 func (s *InMemoryStorage) UpdateJobStatus(ctx context.Context, jobID string, status string, result map[string]interface{}, errorMsg string) error {
-	log.Printf("Job status updated (in-memory): %s -> %s", jobID, status)
-	return nil
+	return s.jobs.update(jobID, status, result, errorMsg)
 }
 
 func (s *InMemoryStorage) SaveDataQuality(ctx context.Context, quality *models.DataQuality) error {
@@ -137,8 +191,10 @@ func (s *InMemoryStorage) GetDataQualityStats(ctx context.Context, source string
 }
 
 type FileStorage struct {
-	dataDir string
-	mu      sync.RWMutex
+	dataDir   string
+	jobs      *jobTable
+	companies *companyTable
+	mu        sync.RWMutex
 }
 
 func NewFileStorage(dataDir string) (*FileStorage, error) {
@@ -146,11 +202,36 @@ func NewFileStorage(dataDir string) (*FileStorage, error) {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
+	// Jobs live beside the per-source directories
+	jobs, err := newJobTable(filepath.Join(dataDir, "_jobs"))
+	if err != nil {
+		return nil, err
+	}
+	companies, err := newCompanyTable(filepath.Join(dataDir, "_companies.json"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &FileStorage{
-		dataDir: dataDir,
+		dataDir:   dataDir,
+		jobs:      jobs,
+		companies: companies,
 	}, nil
 }
 
+// readFile loads the stored record in path.
+func (fs *FileStorage) readFile(path string) (*models.UnstructuredData, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	var data models.UnstructuredData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return &data, nil
+}
+
 func (fs *FileStorage) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
 	fs.mu.Lock()
 	defer fs.mu.Unlock()
@@ -159,15 +240,34 @@ func (fs *FileStorage) SaveUnstructuredData(ctx context.Context, data *models.Un
 		return fmt.Errorf("failed to create source directory: %w", err)
 	}
 
+	filename := fmt.Sprintf("%s_%s.json", data.ID, time.Now().Format("20060102_150405"))
+	filePath := filepath.Join(sourceDir, filename)
+
+	// A record already on file is rewritten in place only when it changed
+	var existing *models.UnstructuredData
 	pattern := filepath.Join(sourceDir, fmt.Sprintf("%s_*.json", data.ID))
 	matches, err := filepath.Glob(pattern)
 	if err == nil && len(matches) > 0 {
-		log.Printf("     Skipping duplicate: %s - %s", data.Source, data.Title)
-		return nil
+		filePath = matches[0]
+		if existing, err = fs.readFile(filePath); err != nil {
+			return err
+		}
+	}
+	analyze := needsAnalysis(data, existing)
+	if existing != nil {
+		_, oldHash, err := revisionSnapshot(existing)
+		if err != nil {
+			return err
+		}
+		_, newHash, err := revisionSnapshot(data)
+		if err != nil {
+			return err
+		}
+		if oldHash == newHash {
+			log.Printf("     Skipping duplicate: %s - %s", data.Source, data.Title)
+			return nil
+		}
 	}
-
-	filename := fmt.Sprintf("%s_%s.json", data.ID, time.Now().Format("20060102_150405"))
-	filePath := filepath.Join(sourceDir, filename)
 
 	file, err := os.Create(filePath)
 	if err != nil {
@@ -181,30 +281,70 @@ func (fs *FileStorage) SaveUnstructuredData(ctx context.Context, data *models.Un
 		return fmt.Errorf("failed to encode data: %w", err)
 	}
 
+	if analyze {
+		for _, jobType := range AnalysisJobTypes {
+			if err := fs.jobs.save(NewJob(data, jobType)); err != nil {
+				return err
+			}
+		}
+	}
+
 	log.Printf("✅ Saved to file: %s - %s", data.Source, data.Title)
 	return nil
 }
 
 func (fs *FileStorage) GetUnstructuredData(ctx context.Context, id string) (*models.UnstructuredData, error) {
-	
-	return nil, fmt.Errorf("GetUnstructuredData not implemented for file storage")
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	matches, err := filepath.Glob(filepath.Join(fs.dataDir, "*", fmt.Sprintf("%s_*.json", id)))
+	if err != nil || len(matches) == 0 {
+		return nil, ErrNotFound
+	}
+	return fs.readFile(matches[0])
 }
 
+// ListUnstructuredData reads every stored record to filter it, which suits
+// the data volumes file storage is meant for.
 func (fs *FileStorage) ListUnstructuredData(ctx context.Context, filters DataFilters) ([]*models.UnstructuredData, error) {
-	
-	return []*models.UnstructuredData{}, nil
+	fs.mu.RLock()
+	defer fs.mu.RUnlock()
+
+	pattern := filepath.Join(fs.dataDir, "*", "*.json")
+	if filters.Source != "" {
+		pattern = filepath.Join(fs.dataDir, filters.Source, "*.json")
+	}
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	docs := make([]*models.UnstructuredData, 0, len(matches))
+	for _, path := range matches {
+		// Jobs and other bookkeeping live in directories starting with _
+		if strings.HasPrefix(filepath.Base(filepath.Dir(path)), "_") {
+			continue
+		}
+		data, err := fs.readFile(path)
+		if err != nil {
+			log.Printf("Skipping unreadable record: %v", err)
+			continue
+		}
+		docs = append(docs, data)
+	}
+	return filterDocuments(docs, filters), nil
 }
 
 func (fs *FileStorage) SaveProcessingJob(ctx context.Context, job *models.ProcessingJob) error {
-	return nil 
+	return fs.jobs.save(job)
 }
 
 func (fs *FileStorage) GetPendingJobs(ctx context.Context, jobType string, limit int) ([]*models.ProcessingJob, error) {
-	return []*models.ProcessingJob{}, nil
+	return fs.jobs.pending(jobType, limit), nil
 }
 
 func (fs *FileStorage) UpdateJobStatus(ctx context.Context, jobID string, status string, result map[string]interface{}, errorMsg string) error {
-	return nil 
+	return fs.jobs.update(jobID, status, result, errorMsg)
 }
 
 func (fs *FileStorage) SaveDataQuality(ctx context.Context, quality *models.DataQuality) error {
@@ -220,23 +360,38 @@ func (fs *FileStorage) Close() error {
 	return nil
 }
 
+// NewStorage opens the storage cfg.Type selects. A PostgreSQL database that
+// cannot be reached or migrated is an error rather than a fallback, since
+// documents saved anywhere else would not be found there later.
 func NewStorage(cfg config.DatabaseConfig) (Storage, error) {
-	dataDir := "./data"
-	if fileStore, err := NewFileStorage(dataDir); err == nil {
-		log.Printf("Using file storage in directory: %s", dataDir)
+	switch cfg.Type {
+	case "postgres":
+		return NewPostgresStorage(cfg)
+	case "file":
+		fileStore, err := NewFileStorage(cfg.DataDir)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Using file storage in directory: %s", cfg.DataDir)
 		return fileStore, nil
+	case "memory":
+		log.Println("Using in-memory storage")
+		return NewInMemoryStorage(), nil
 	}
+	return nil, fmt.Errorf("unknown database type %q", cfg.Type)
+}
 
+// NewPostgresStorage connects to the database at cfg.URL and migrates it to
+// the newest schema.
+func NewPostgresStorage(cfg config.DatabaseConfig) (*PostgresStorage, error) {
 	db, err := sql.Open("postgres", cfg.URL)
 	if err != nil {
-		log.Printf("Failed to open database connection, falling back to in-memory storage: %v", err)
-		return NewInMemoryStorage(), nil
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
 
 	if err := db.Ping(); err != nil {
-		log.Printf("Failed to ping database, falling back to in-memory storage: %v", err)
-		db.Close() // close failed connections
-		return NewInMemoryStorage(), nil
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	storage := &PostgresStorage{
@@ -245,101 +400,26 @@ func NewStorage(cfg config.DatabaseConfig) (Storage, error) {
 	}
 
 	if err := storage.createTables(); err != nil {
-		log.Printf("Failed to create tables, falling back to in-memory storage: %v", err)
 		db.Close()
-		return NewInMemoryStorage(), nil
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	if cfg.WriteBatchSize > 0 {
+		storage.buffer = newWriteBuffer(storage)
 	}
 
 	log.Println("Successfully connected to PostgreSQL database")
 	return storage, nil
 }
 
+// createTables brings the schema up to the newest embedded migration.
 func (s *PostgresStorage) createTables() error {
-	queries := []string{
-		`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`,
-		`CREATE TABLE IF NOT EXISTS unstructured_data (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			source VARCHAR(100) NOT NULL,
-			type VARCHAR(50) NOT NULL,
-			title TEXT,
-			content TEXT,
-			url TEXT,
-			author VARCHAR(255),
-			published_at TIMESTAMP WITH TIME ZONE,
-			ingested_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			metadata JSONB,
-			tags TEXT[],
-			entities JSONB,
-			sentiment JSONB,
-			processed_at TIMESTAMP WITH TIME ZONE,
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		)`,
-		`CREATE TABLE IF NOT EXISTS processing_jobs (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			data_id UUID REFERENCES unstructured_data(id),
-			job_type VARCHAR(50) NOT NULL,
-			status VARCHAR(20) DEFAULT 'pending',
-			created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-			started_at TIMESTAMP WITH TIME ZONE,
-			completed_at TIMESTAMP WITH TIME ZONE,
-			result JSONB,
-			error TEXT,
-			retry_count INTEGER DEFAULT 0,
-			priority INTEGER DEFAULT 0
-		)`,
-		`CREATE TABLE IF NOT EXISTS data_quality (
-			id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-			data_id UUID REFERENCES unstructured_data(id),
-			source VARCHAR(100) NOT NULL,
-			quality_score DECIMAL(3,2),
-			completeness_score DECIMAL(3,2),
-			accuracy_score DECIMAL(3,2),
-			freshness_score DECIMAL(3,2),
-			issues TEXT[],
-			checked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_unstructured_data_source ON unstructured_data(source)`,
-		`CREATE INDEX IF NOT EXISTS idx_unstructured_data_type ON unstructured_data(type)`,
-		`CREATE INDEX IF NOT EXISTS idx_unstructured_data_published_at ON unstructured_data(published_at)`,
-		`CREATE INDEX IF NOT EXISTS idx_unstructured_data_tags ON unstructured_data USING GIN(tags)`,
-		`CREATE INDEX IF NOT EXISTS idx_processing_jobs_status ON processing_jobs(status)`,
-		`CREATE INDEX IF NOT EXISTS idx_processing_jobs_type ON processing_jobs(job_type)`,
-		`CREATE INDEX IF NOT EXISTS idx_data_quality_source ON data_quality(source)`,
-	}
-
-	for _, query := range queries {
-		if _, err := s.db.Exec(query); err != nil {
-			return fmt.Errorf("failed to execute query %s: %w", query, err)
-		}
-	}
-
-	return nil
+	return Migrate(context.Background(), s.db)
 }
 
-func (s *PostgresStorage) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
-	metadataJSON, err := json.Marshal(data.Metadata)
-	if err != nil {
-		return fmt.Errorf("failed to marshal metadata: %w", err)
-	}
-
-	entitiesJSON, err := json.Marshal(data.Entities)
-	if err != nil {
-		return fmt.Errorf("failed to marshal entities: %w", err)
-	}
-
-	var sentimentJSON []byte
-	if data.Sentiment != nil {
-		sentimentJSON, err = json.Marshal(data.Sentiment)
-		if err != nil {
-			return fmt.Errorf("failed to marshal sentiment: %w", err)
-		}
-	}
-
-	query := `
-		INSERT INTO unstructured_data 
-		(id, source, type, title, content, url, author, published_at, ingested_at, metadata, tags, entities, sentiment, processed_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+// unstructuredDataUpsert updates a stored document from EXCLUDED, keeping
+// its analysis results when the new version has none.
+const unstructuredDataUpsert = `
 		ON CONFLICT (id) DO UPDATE SET
 			source = EXCLUDED.source,
 			type = EXCLUDED.type,
@@ -350,28 +430,111 @@ func (s *PostgresStorage) SaveUnstructuredData(ctx context.Context, data *models
 			published_at = EXCLUDED.published_at,
 			metadata = EXCLUDED.metadata,
 			tags = EXCLUDED.tags,
+			symbols = EXCLUDED.symbols,
 			entities = EXCLUDED.entities,
-			sentiment = EXCLUDED.sentiment,
-			processed_at = EXCLUDED.processed_at,
+			sentiment = COALESCE(EXCLUDED.sentiment, unstructured_data.sentiment),
+			processed_at = COALESCE(EXCLUDED.processed_at, unstructured_data.processed_at),
+			summary = COALESCE(EXCLUDED.summary, unstructured_data.summary),
 			updated_at = NOW()
-	`
+`
+
+// SaveUnstructuredData writes a document, or with write batching queues it
+// for the next batch; a queued document is readable through
+// GetUnstructuredData at once but listed only once written.
+func (s *PostgresStorage) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	if s.buffer != nil {
+		return s.buffer.add(ctx, data)
+	}
+	return s.saveRow(ctx, data)
+}
+
+func (s *PostgresStorage) saveRow(ctx context.Context, data *models.UnstructuredData) error {
+	// A record without sentiment is stored as NULL so the upsert keeps an earlier score
+	var sentimentJSON interface{}
+	if data.Sentiment != nil {
+		raw, err := json.Marshal(data.Sentiment)
+		if err != nil {
+			return fmt.Errorf("failed to marshal sentiment: %w", err)
+		}
+		sentimentJSON = string(raw)
+	}
+
+	query := `
+		INSERT INTO unstructured_data 
+		(id, source, type, title, content, url, author, published_at, ingested_at, metadata, tags, entities, sentiment, processed_at, summary, symbols)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), $16)
+	` + unstructuredDataUpsert
 
-	_, err = s.db.ExecContext(ctx, query,
+	// The upsert overwrites the row, so the revision and the analysis jobs are
+	// written in the same transaction
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing *models.UnstructuredData
+	var title, content sql.NullString
+	var existingMetadata, existingEntities []byte
+	err = tx.QueryRowContext(ctx, `SELECT title, content, metadata, entities FROM unstructured_data WHERE id = $1 FOR UPDATE`, data.ID).
+		Scan(&title, &content, &existingMetadata, &existingEntities)
+	switch {
+	case err == nil:
+		existing = &models.UnstructuredData{Title: title.String, Content: content.String}
+		// Unreadable analysis results are left to be recomputed
+		json.Unmarshal(existingMetadata, &existing.Metadata)
+		json.Unmarshal(existingEntities, &existing.Entities)
+	case err != sql.ErrNoRows:
+		return fmt.Errorf("failed to load existing data: %w", err)
+	}
+	// Sentiment, processed_at and summary are also kept by the upsert itself
+	analyze := needsAnalysis(data, existing)
+
+	metadataJSON, err := json.Marshal(data.Metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	entitiesJSON, err := json.Marshal(data.Entities)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entities: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, query,
 		data.ID, data.Source, data.Type, data.Title, data.Content, data.URL,
 		data.Author, data.PublishedAt, data.IngestedAt, string(metadataJSON),
-		data.Tags, string(entitiesJSON), string(sentimentJSON), data.ProcessedAt)
+		pq.Array(data.Tags), string(entitiesJSON), sentimentJSON, data.ProcessedAt, data.Summary,
+		pq.Array(documentSymbols(data)))
 
 	if err != nil {
 		return fmt.Errorf("failed to save unstructured data: %w", err)
 	}
 
-	return nil
+	if err := s.recordRevision(ctx, tx, data); err != nil {
+		return err
+	}
+
+	if analyze {
+		for _, jobType := range AnalysisJobTypes {
+			if err := saveProcessingJob(ctx, tx, NewJob(data, jobType)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (s *PostgresStorage) GetUnstructuredData(ctx context.Context, id string) (*models.UnstructuredData, error) {
+	if s.buffer != nil {
+		if data := s.buffer.get(id); data != nil {
+			return data, nil
+		}
+	}
+
 	query := `
 		SELECT id, source, type, title, content, url, author, published_at, ingested_at, 
-			   metadata, tags, entities, sentiment, processed_at
+			   metadata, tags, entities, sentiment, processed_at, COALESCE(summary, '')
 		FROM unstructured_data 
 		WHERE id = $1
 	`
@@ -385,12 +548,12 @@ func (s *PostgresStorage) GetUnstructuredData(ctx context.Context, id string) (*
 	err := row.Scan(
 		&data.ID, &data.Source, &data.Type, &data.Title, &data.Content, &data.URL,
 		&data.Author, &data.PublishedAt, &data.IngestedAt, &metadataJSON,
-		&tags, &entitiesJSON, &sentimentJSON, &data.ProcessedAt,
+		pq.Array(&tags), &entitiesJSON, &sentimentJSON, &data.ProcessedAt, &data.Summary,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("data not found")
+			return nil, ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get unstructured data: %w", err)
 	}
@@ -417,7 +580,7 @@ func (s *PostgresStorage) GetUnstructuredData(ctx context.Context, id string) (*
 func (s *PostgresStorage) ListUnstructuredData(ctx context.Context, filters DataFilters) ([]*models.UnstructuredData, error) {
 	query := `
 		SELECT id, source, type, title, content, url, author, published_at, ingested_at, 
-			   metadata, tags, entities, sentiment, processed_at
+			   metadata, tags, entities, sentiment, processed_at, COALESCE(summary, '')
 		FROM unstructured_data 
 		WHERE 1=1
 	`
@@ -450,11 +613,50 @@ func (s *PostgresStorage) ListUnstructuredData(ctx context.Context, filters Data
 
 	if len(filters.Tags) > 0 {
 		query += fmt.Sprintf(" AND tags && $%d", argIndex)
-		args = append(args, filters.Tags)
+		args = append(args, pq.Array(filters.Tags))
+		argIndex++
+	}
+
+	if len(filters.Symbols) > 0 {
+		query += fmt.Sprintf(" AND symbols && $%d", argIndex)
+		args = append(args, pq.Array(upperSymbols(filters.Symbols)))
+		argIndex++
+	}
+
+	if filters.CompanyID != "" {
+		query += fmt.Sprintf(" AND metadata->'company_ids' ? $%d", argIndex)
+		args = append(args, filters.CompanyID)
+		argIndex++
+	}
+
+	if filters.ClusterID != "" {
+		query += fmt.Sprintf(" AND metadata->>'cluster_id' = $%d", argIndex)
+		args = append(args, filters.ClusterID)
 		argIndex++
 	}
 
-	query += " ORDER BY published_at DESC"
+	if strings.TrimSpace(filters.Query) != "" {
+		query += fmt.Sprintf(" AND search_vector @@ websearch_to_tsquery('english', $%d)", argIndex)
+		args = append(args, filters.Query)
+		argIndex++
+	}
+
+	// Documents without a cluster stand for themselves
+	if filters.Deduplicate {
+		query = `SELECT * FROM (SELECT DISTINCT ON (COALESCE(metadata->>'cluster_id', id::text))` +
+			strings.TrimPrefix(strings.TrimSpace(query), "SELECT") +
+			` ORDER BY COALESCE(metadata->>'cluster_id', id::text), published_at) AS clusters WHERE 1=1`
+	}
+
+	// Outside the deduplication, so a cursor never changes which document
+	// stands for a cluster
+	if filters.After != nil {
+		query += fmt.Sprintf(" AND (published_at, id) < ($%d, $%d)", argIndex, argIndex+1)
+		args = append(args, filters.After.PublishedAt, filters.After.ID)
+		argIndex += 2
+	}
+
+	query += " ORDER BY published_at DESC, id DESC"
 
 	if filters.Limit > 0 {
 		query += fmt.Sprintf(" LIMIT $%d", argIndex)
@@ -483,7 +685,7 @@ func (s *PostgresStorage) ListUnstructuredData(ctx context.Context, filters Data
 		err := rows.Scan(
 			&data.ID, &data.Source, &data.Type, &data.Title, &data.Content, &data.URL,
 			&data.Author, &data.PublishedAt, &data.IngestedAt, &metadataJSON,
-			&tags, &entitiesJSON, &sentimentJSON, &data.ProcessedAt,
+			pq.Array(&tags), &entitiesJSON, &sentimentJSON, &data.ProcessedAt, &data.Summary,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan row: %w", err)
@@ -511,6 +713,12 @@ func (s *PostgresStorage) ListUnstructuredData(ctx context.Context, filters Data
 }
 
 func (s *PostgresStorage) SaveProcessingJob(ctx context.Context, job *models.ProcessingJob) error {
+	// The job references its document, which must be written first
+	s.writeQueued(ctx, job.DataID)
+	return saveProcessingJob(ctx, s.db, job)
+}
+
+func saveProcessingJob(ctx context.Context, db execer, job *models.ProcessingJob) error {
 	resultJSON, err := json.Marshal(job.Result)
 	if err != nil {
 		return fmt.Errorf("failed to marshal result: %w", err)
@@ -518,21 +726,24 @@ func (s *PostgresStorage) SaveProcessingJob(ctx context.Context, job *models.Pro
 
 	query := `
 		INSERT INTO processing_jobs 
-		(id, data_id, job_type, status, created_at, started_at, completed_at, result, error, retry_count, priority)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		(id, data_id, job_type, status, created_at, started_at, completed_at, result, error, retry_count, priority, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (id) DO UPDATE SET
 			status = EXCLUDED.status,
 			started_at = EXCLUDED.started_at,
 			completed_at = EXCLUDED.completed_at,
 			result = EXCLUDED.result,
 			error = EXCLUDED.error,
-			retry_count = EXCLUDED.retry_count
+			retry_count = EXCLUDED.retry_count,
+			priority = EXCLUDED.priority,
+			created_at = EXCLUDED.created_at,
+			next_attempt_at = EXCLUDED.next_attempt_at
 	`
 
-	_, err = s.db.ExecContext(ctx, query,
+	_, err = db.ExecContext(ctx, query,
 		job.ID, job.DataID, job.JobType, job.Status, job.CreatedAt,
 		job.StartedAt, job.CompletedAt, string(resultJSON), job.Error,
-		job.RetryCount, job.Priority)
+		job.RetryCount, job.Priority, job.NextAttemptAt)
 
 	if err != nil {
 		return fmt.Errorf("failed to save processing job: %w", err)
@@ -544,43 +755,21 @@ func (s *PostgresStorage) SaveProcessingJob(ctx context.Context, job *models.Pro
 func (s *PostgresStorage) GetPendingJobs(ctx context.Context, jobType string, limit int) ([]*models.ProcessingJob, error) {
 	query := `
 		SELECT id, data_id, job_type, status, created_at, started_at, completed_at, 
-			   result, error, retry_count, priority
+			   result, error, retry_count, priority, next_attempt_at
 		FROM processing_jobs 
 		WHERE status = 'pending' AND job_type = $1
-		ORDER BY priority DESC, created_at ASC
+			AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+		ORDER BY priority + EXTRACT(EPOCH FROM NOW() - created_at) / $3 DESC
 		LIMIT $2
 	`
 
-	rows, err := s.db.QueryContext(ctx, query, jobType, limit)
+	rows, err := s.db.QueryContext(ctx, query, jobType, limit, PriorityAgingStep.Seconds())
 	if err != nil {
 		return nil, fmt.Errorf("failed to query pending jobs: %w", err)
 	}
 	defer rows.Close()
 
-	var jobs []*models.ProcessingJob
-	for rows.Next() {
-		var job models.ProcessingJob
-		var resultJSON []byte
-
-		err := rows.Scan(
-			&job.ID, &job.DataID, &job.JobType, &job.Status, &job.CreatedAt,
-			&job.StartedAt, &job.CompletedAt, &resultJSON, &job.Error,
-			&job.RetryCount, &job.Priority,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan job row: %w", err)
-		}
-
-		if len(resultJSON) > 0 {
-			if err := json.Unmarshal(resultJSON, &job.Result); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal result: %w", err)
-			}
-		}
-
-		jobs = append(jobs, &job)
-	}
-
-	return jobs, nil
+	return scanJobs(rows)
 }
 
 func (s *PostgresStorage) UpdateJobStatus(ctx context.Context, jobID string, status string, result map[string]interface{}, errorMsg string) error {
@@ -606,6 +795,13 @@ func (s *PostgresStorage) UpdateJobStatus(ctx context.Context, jobID string, sta
 			WHERE id = $2
 		`
 		args = []interface{}{status, jobID}
+	} else if status == "pending" {
+		query = `
+			UPDATE processing_jobs 
+			SET status = $1, started_at = NULL
+			WHERE id = $2
+		`
+		args = []interface{}{status, jobID}
 	} else {
 		query = `
 			UPDATE processing_jobs 
@@ -624,6 +820,8 @@ func (s *PostgresStorage) UpdateJobStatus(ctx context.Context, jobID string, sta
 }
 
 func (s *PostgresStorage) SaveDataQuality(ctx context.Context, quality *models.DataQuality) error {
+	s.writeQueued(ctx, quality.DataID)
+
 	query := `
 		INSERT INTO data_quality 
 		(id, data_id, source, quality_score, completeness_score, accuracy_score, freshness_score, issues, checked_at)
@@ -671,5 +869,8 @@ func (s *PostgresStorage) GetDataQualityStats(ctx context.Context, source string
 }
 
 func (s *PostgresStorage) Close() error {
+	if s.buffer != nil {
+		s.buffer.close()
+	}
 	return s.db.Close()
 }
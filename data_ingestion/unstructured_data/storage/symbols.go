@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"strings"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+)
+
+// documentSymbols are the distinct symbols a document names, upper-cased as
+// they are stored and filtered.
+func documentSymbols(data *models.UnstructuredData) []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, symbol := range data.Symbols() {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol != "" && !seen[symbol] {
+			seen[symbol] = true
+			symbols = append(symbols, symbol)
+		}
+	}
+	return symbols
+}
+
+// upperSymbols normalizes filter symbols the way documentSymbols stores them.
+func upperSymbols(symbols []string) []string {
+	upper := make([]string, len(symbols))
+	for i, symbol := range symbols {
+		upper[i] = strings.ToUpper(strings.TrimSpace(symbol))
+	}
+	return upper
+}
+
+// namesAnySymbol reports whether the document names one of symbols.
+func namesAnySymbol(data *models.UnstructuredData, symbols []string) bool {
+	for _, named := range documentSymbols(data) {
+		for _, symbol := range upperSymbols(symbols) {
+			if named == symbol {
+				return true
+			}
+		}
+	}
+	return false
+}
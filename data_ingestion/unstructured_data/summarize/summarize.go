@@ -0,0 +1,208 @@
+// Package summarize writes short credit-relevant summaries of documents
+// with an LLM behind an OpenAI-compatible chat completions API.
+package summarize
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// prompt asks for the summary; the document follows as the user message.
+const prompt = `You summarize documents for credit analysts. In two or three sentences, state what the document means for the creditworthiness of the companies it concerns: earnings, leverage, liquidity, refinancing, rating actions, defaults, litigation, management changes or guidance. Use only facts from the document, name the companies, and keep figures exact. Reply with the summary alone.`
+
+// Usage is what one request consumed.
+type Usage struct {
+	InputTokens  int
+	OutputTokens int
+	Cost         float64 // dollars
+}
+
+// Stats are the totals of every request sent.
+type Stats struct {
+	Requests     int
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+}
+
+// Client sends documents to the chat completions endpoint, spacing the
+// requests to stay within the configured rate.
+type Client struct {
+	url        string
+	apiKey     string
+	model      string
+	maxTokens  int
+	maxChars   int
+	inputCost  float64
+	outputCost float64
+	client     *http.Client
+	interval   time.Duration
+
+	mu    sync.Mutex
+	next  time.Time // earliest time of the next request
+	stats Stats
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	MaxTokens   int           `json:"max_tokens"`
+	Temperature float64       `json:"temperature"` // left at 0 for repeatable summaries
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// New returns a client for cfg, or nil when no endpoint is configured.
+func New(cfg config.SummarizationConfig) *Client {
+	if cfg.URL == "" {
+		return nil
+	}
+	rate := cfg.RequestsPerMinute
+	if rate < 1 {
+		rate = 1
+	}
+	return &Client{
+		url:        cfg.URL,
+		apiKey:     cfg.APIKey,
+		model:      cfg.Model,
+		maxTokens:  cfg.MaxTokens,
+		maxChars:   cfg.MaxChars,
+		inputCost:  cfg.InputCost,
+		outputCost: cfg.OutputCost,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		interval:   time.Minute / time.Duration(rate),
+	}
+}
+
+// Model returns the model the summaries are written by.
+func (c *Client) Model() string {
+	return c.model
+}
+
+// Summarize returns a summary of the document with title and content.
+func (c *Client) Summarize(ctx context.Context, title, content string) (string, Usage, error) {
+	if err := c.wait(ctx); err != nil {
+		return "", Usage{}, err
+	}
+
+	body, err := json.Marshal(chatRequest{
+		Model: c.model,
+		Messages: []chatMessage{
+			{Role: "system", Content: prompt},
+			{Role: "user", Content: truncate(title+"\n\n"+content, c.maxChars)},
+		},
+		MaxTokens: c.maxTokens,
+	})
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to encode summarization request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(body))
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", Usage{}, fmt.Errorf("summarization request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", Usage{}, fmt.Errorf("summarization service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+
+	var result chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", Usage{}, fmt.Errorf("failed to decode summarization response: %w", err)
+	}
+	usage := c.record(result.Usage.PromptTokens, result.Usage.CompletionTokens)
+	if len(result.Choices) == 0 {
+		return "", usage, fmt.Errorf("summarization response has no choices")
+	}
+	return strings.TrimSpace(result.Choices[0].Message.Content), usage, nil
+}
+
+// Stats returns the totals so far.
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// wait blocks until the client may send its next request.
+func (c *Client) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	at := c.next
+	if at.Before(now) {
+		at = now
+	}
+	c.next = at.Add(c.interval)
+	c.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) record(input, output int) Usage {
+	usage := Usage{
+		InputTokens:  input,
+		OutputTokens: output,
+		Cost:         (float64(input)*c.inputCost + float64(output)*c.outputCost) / 1e6,
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Requests++
+	c.stats.InputTokens += input
+	c.stats.OutputTokens += output
+	c.stats.Cost += usage.Cost
+	return usage
+}
+
+// truncate cuts text to at most max bytes, on a rune boundary.
+func truncate(text string, max int) string {
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+	for max > 0 && !utf8.RuneStart(text[max]) {
+		max--
+	}
+	return text[:max]
+}
@@ -0,0 +1,218 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// scopeName names the instrumentation in exported spans.
+const scopeName = "github.com/gaixen/CredTech/data_ingestion/unstructured_data"
+
+// exporter posts finished spans to an OTLP/HTTP collector as JSON, in
+// batches, from its own goroutine.
+type exporter struct {
+	config   config.TracingConfig
+	endpoint string
+	client   *http.Client
+	resource map[string]interface{}
+
+	queue   chan map[string]interface{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+func newExporter(cfg config.TracingConfig) (*exporter, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q", cfg.Endpoint)
+	}
+	// Like the OTel SDKs, the endpoint is the collector's base URL
+	if !strings.HasSuffix(u.Path, "/v1/traces") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/v1/traces"
+	}
+
+	hostname, _ := os.Hostname()
+	e := &exporter{
+		config:   cfg,
+		endpoint: u.String(),
+		client:   &http.Client{Timeout: cfg.Timeout},
+		resource: map[string]interface{}{
+			"attributes": encodeAttributes([]Attribute{
+				String("service.name", cfg.ServiceName),
+				String("host.name", hostname),
+				String("telemetry.sdk.language", "go"),
+			}),
+		},
+		queue: make(chan map[string]interface{}, cfg.QueueSize),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go e.run()
+	return e, nil
+}
+
+// enqueue encodes the ended span and queues it, dropping it if the queue
+// is full rather than holding up the traced code.
+func (e *exporter) enqueue(s *Span, end time.Time) {
+	s.mu.Lock()
+	encoded := map[string]interface{}{
+		"traceId":           hex.EncodeToString(s.context.TraceID[:]),
+		"spanId":            hex.EncodeToString(s.context.SpanID[:]),
+		"name":              s.name,
+		"kind":              int(s.kind),
+		"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(end.UnixNano(), 10),
+		"attributes":        encodeAttributes(s.attrs),
+	}
+	if s.parent != [8]byte{} {
+		encoded["parentSpanId"] = hex.EncodeToString(s.parent[:])
+	}
+	if len(s.events) > 0 {
+		events := make([]map[string]interface{}, len(s.events))
+		for i, ev := range s.events {
+			events[i] = map[string]interface{}{
+				"name":         ev.name,
+				"timeUnixNano": strconv.FormatInt(ev.at.UnixNano(), 10),
+				"attributes":   encodeAttributes(ev.attrs),
+			}
+		}
+		encoded["events"] = events
+	}
+	if s.failed {
+		encoded["status"] = map[string]interface{}{"code": 2, "message": s.message}
+	}
+	s.mu.Unlock()
+
+	select {
+	case e.queue <- encoded:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+// encodeAttributes renders attributes as OTLP JSON key-values; int64
+// values are strings there.
+func encodeAttributes(attrs []Attribute) []map[string]interface{} {
+	encoded := make([]map[string]interface{}, 0, len(attrs))
+	for _, attr := range attrs {
+		var value map[string]interface{}
+		switch v := attr.Value.(type) {
+		case string:
+			value = map[string]interface{}{"stringValue": v}
+		case int64:
+			value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+		case float64:
+			value = map[string]interface{}{"doubleValue": v}
+		case bool:
+			value = map[string]interface{}{"boolValue": v}
+		default:
+			value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+		}
+		encoded = append(encoded, map[string]interface{}{"key": attr.Key, "value": value})
+	}
+	return encoded
+}
+
+// run exports the queue in batches until shutdown, then exports what is left.
+func (e *exporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.config.FlushInterval)
+	defer ticker.Stop()
+
+	var batch []map[string]interface{}
+	for {
+		select {
+		case span := <-e.queue:
+			batch = append(batch, span)
+			if len(batch) < e.config.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-e.stop:
+			for len(e.queue) > 0 {
+				batch = append(batch, <-e.queue)
+				if len(batch) >= e.config.BatchSize {
+					e.export(batch)
+					batch = nil
+				}
+			}
+			e.export(batch)
+			return
+		}
+		e.export(batch)
+		batch = nil
+	}
+}
+
+// export posts one batch. A batch the collector cannot take is dropped:
+// traces are for looking into latency, and holding them back would only
+// crowd out newer ones.
+func (e *exporter) export(batch []map[string]interface{}) {
+	if len(batch) == 0 {
+		return
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"resourceSpans": []map[string]interface{}{{
+			"resource": e.resource,
+			"scopeSpans": []map[string]interface{}{{
+				"scope": map[string]interface{}{"name": scopeName},
+				"spans": batch,
+			}},
+		}},
+	})
+	if err != nil {
+		log.Printf("Failed to encode %d spans: %v", len(batch), err)
+		return
+	}
+	req, err := http.NewRequest("POST", e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		log.Printf("Failed to export %d spans: %v", len(batch), err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range e.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		log.Printf("Failed to export %d spans: %v", len(batch), err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		log.Printf("OTLP collector rejected %d spans with status %d: %s", len(batch), resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+}
+
+// shutdown exports the spans still queued, waiting until ctx is done at most.
+func (e *exporter) shutdown(ctx context.Context) error {
+	e.once.Do(func() { close(e.stop) })
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if dropped := e.dropped.Load(); dropped > 0 {
+		log.Printf("Tracing dropped %d spans while the export queue was full", dropped)
+	}
+	return nil
+}
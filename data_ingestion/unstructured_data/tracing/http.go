@@ -0,0 +1,85 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// WrapClient records a client span for every request client sends on
+// behalf of source, and passes the span on to the server in a traceparent
+// header. Wrapped outside the rate limiter, a span includes the time the
+// request waited for its turn.
+func WrapClient(client *http.Client, source string) {
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &transport{source: source, base: base}
+}
+
+type transport struct {
+	source string
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := start(req.Context(), KindClient, "HTTP "+req.Method, []Attribute{
+		String("source", t.source),
+		String("http.request.method", req.Method),
+		String("server.address", req.URL.Host),
+		// the query is left out, as it often holds an API key
+		String("url.path", req.URL.Path),
+	})
+	if span == nil {
+		return t.base.RoundTrip(req)
+	}
+	defer span.End()
+
+	// RoundTrip must not change the caller's request
+	req = req.Clone(ctx)
+	Inject(ctx, req.Header)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	span.SetAttributes(Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.RecordError(fmt.Errorf("status %d", resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// Middleware records a server span for every request next serves,
+// continuing the trace of a caller that sent a traceparent header.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := start(Extract(r.Context(), r.Header), KindServer, "HTTP "+r.Method, []Attribute{
+			String("http.request.method", r.Method),
+			String("url.path", r.URL.Path),
+		})
+		if span == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		span.SetAttributes(Int("http.response.status_code", rec.status))
+		if rec.status >= 500 {
+			span.RecordError(fmt.Errorf("status %d", rec.status))
+		}
+	})
+}
+
+// statusRecorder remembers the status a handler wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
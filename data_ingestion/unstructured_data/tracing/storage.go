@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// Storage wraps a Storage and records a span for each document and job
+// call made through it; the rest, and the polls for pending jobs, which
+// would bury the other traces, pass straight through.
+type Storage struct {
+	storage.Storage
+}
+
+// WrapStorage returns store with its calls traced. Wrapped around the
+// storage itself, the spans time the storage calls and not the wrappers
+// around them.
+func WrapStorage(store storage.Storage) *Storage {
+	return &Storage{Storage: store}
+}
+
+// traced runs call in a span named "storage.<op>".
+func traced(ctx context.Context, op string, call func(ctx context.Context) error, attrs ...Attribute) error {
+	ctx, span := Start(ctx, "storage."+op, attrs...)
+	defer span.End()
+	err := call(ctx)
+	span.RecordError(err)
+	return err
+}
+
+func (s *Storage) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	return traced(ctx, "save", func(ctx context.Context) error {
+		return s.Storage.SaveUnstructuredData(ctx, data)
+	}, String("data.id", data.ID), String("source", data.Source), String("data.type", data.Type))
+}
+
+func (s *Storage) GetUnstructuredData(ctx context.Context, id string) (data *models.UnstructuredData, err error) {
+	err = traced(ctx, "get", func(ctx context.Context) error {
+		data, err = s.Storage.GetUnstructuredData(ctx, id)
+		return err
+	}, String("data.id", id))
+	return data, err
+}
+
+func (s *Storage) GetUnstructuredDataAsOf(ctx context.Context, id string, asOf time.Time) (data *models.UnstructuredData, err error) {
+	err = traced(ctx, "get_as_of", func(ctx context.Context) error {
+		data, err = s.Storage.GetUnstructuredDataAsOf(ctx, id, asOf)
+		return err
+	}, String("data.id", id))
+	return data, err
+}
+
+func (s *Storage) ListUnstructuredData(ctx context.Context, filters storage.DataFilters) (docs []*models.UnstructuredData, err error) {
+	err = traced(ctx, "list", func(ctx context.Context) error {
+		docs, err = s.Storage.ListUnstructuredData(ctx, filters)
+		return err
+	}, String("source", filters.Source), Int("limit", filters.Limit))
+	return docs, err
+}
+
+func (s *Storage) DeleteUnstructuredData(ctx context.Context, ids []string) error {
+	return traced(ctx, "delete", func(ctx context.Context) error {
+		return s.Storage.DeleteUnstructuredData(ctx, ids)
+	}, Int("count", len(ids)))
+}
+
+func (s *Storage) SaveProcessingJob(ctx context.Context, job *models.ProcessingJob) error {
+	return traced(ctx, "save_job", func(ctx context.Context) error {
+		return s.Storage.SaveProcessingJob(ctx, job)
+	}, String("job.id", job.ID), String("job.type", job.JobType))
+}
+
+func (s *Storage) ClaimJob(ctx context.Context, jobID string) (claimed bool, err error) {
+	err = traced(ctx, "claim_job", func(ctx context.Context) error {
+		claimed, err = s.Storage.ClaimJob(ctx, jobID)
+		return err
+	}, String("job.id", jobID))
+	return claimed, err
+}
+
+func (s *Storage) UpdateJobStatus(ctx context.Context, jobID string, status string, result map[string]interface{}, errorMsg string) error {
+	return traced(ctx, "update_job", func(ctx context.Context) error {
+		return s.Storage.UpdateJobStatus(ctx, jobID, status, result, errorMsg)
+	}, String("job.id", jobID), String("job.status", status))
+}
+
+func (s *Storage) RetryJob(ctx context.Context, jobID string, errorMsg string, at time.Time) error {
+	return traced(ctx, "retry_job", func(ctx context.Context) error {
+		return s.Storage.RetryJob(ctx, jobID, errorMsg, at)
+	}, String("job.id", jobID))
+}
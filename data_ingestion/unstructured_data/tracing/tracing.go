@@ -0,0 +1,262 @@
+// Package tracing records OpenTelemetry spans of source fetches, processing
+// jobs and storage calls and exports them over OTLP/HTTP, so the time an
+// article spends between its fetch and its availability can be followed.
+// Contexts carry the current span into the calls it makes, and outgoing
+// requests carry it on to other services in a W3C traceparent header.
+// Until Init is given an endpoint, spans cost next to nothing and go
+// nowhere.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+)
+
+// Kind says what side of a call a span is on, with OTLP's numbering.
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// SpanContext identifies a span across processes.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Sampled bool
+}
+
+// IsValid reports whether sc names a span at all.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// Attribute is a key and a string, int64, float64 or bool value.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Attribute    { return Attribute{key, value} }
+func Int(key string, value int) Attribute   { return Attribute{key, int64(value)} }
+func Bool(key string, value bool) Attribute { return Attribute{key, value} }
+
+// Span is one timed operation. A nil Span, which Start returns while
+// tracing is off, takes every call and does nothing.
+type Span struct {
+	tracer  *tracer
+	context SpanContext
+	parent  [8]byte
+	name    string
+	kind    Kind
+	start   time.Time
+
+	mu      sync.Mutex
+	attrs   []Attribute
+	events  []event
+	failed  bool
+	message string
+	ended   bool
+}
+
+type event struct {
+	name  string
+	at    time.Time
+	attrs []Attribute
+}
+
+type tracer struct {
+	ratio    float64
+	exporter *exporter
+}
+
+var (
+	mu      sync.RWMutex
+	current *tracer
+)
+
+// Init starts exporting spans as cfg describes; without an endpoint it does
+// nothing.
+func Init(cfg config.TracingConfig) error {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+	exp, err := newExporter(cfg)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	current = &tracer{ratio: cfg.SampleRatio, exporter: exp}
+	mu.Unlock()
+	return nil
+}
+
+// Enabled reports whether Init set up an exporter.
+func Enabled() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current != nil
+}
+
+// Shutdown stops tracing and exports the spans still queued, waiting until
+// ctx is done at most.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	t := current
+	current = nil
+	mu.Unlock()
+	if t == nil {
+		return nil
+	}
+	return t.exporter.shutdown(ctx)
+}
+
+// Start begins an internal span named name as a child of the span in ctx,
+// or of a new trace, and returns a context carrying it. End must be called.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return start(ctx, KindInternal, name, attrs)
+}
+
+// StartServer begins a server span for a call this process answers, under
+// the caller's span if Extract put one in ctx.
+func StartServer(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return start(ctx, KindServer, name, attrs)
+}
+
+func start(ctx context.Context, kind Kind, name string, attrs []Attribute) (context.Context, *Span) {
+	mu.RLock()
+	t := current
+	mu.RUnlock()
+	if t == nil {
+		return ctx, nil
+	}
+
+	span := &Span{tracer: t, name: name, kind: kind, start: time.Now(), attrs: attrs}
+	if parent := SpanContextFrom(ctx); parent.IsValid() {
+		span.context.TraceID = parent.TraceID
+		span.context.Sampled = parent.Sampled
+		span.parent = parent.SpanID
+	} else {
+		binary.BigEndian.PutUint64(span.context.TraceID[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(span.context.TraceID[8:], rand.Uint64())
+		span.context.Sampled = t.sample(span.context.TraceID)
+	}
+	binary.BigEndian.PutUint64(span.context.SpanID[:], rand.Uint64()|1)
+	return context.WithValue(ctx, spanKey{}, span.context), span
+}
+
+// sample keeps ratio of new traces, deciding by the trace ID as OTel's
+// TraceIDRatioBased sampler does, so every process agrees on a trace.
+func (t *tracer) sample(traceID [16]byte) bool {
+	if t.ratio >= 1 {
+		return true
+	}
+	bound := uint64(t.ratio * (1 << 63))
+	return binary.BigEndian.Uint64(traceID[8:])>>1 < bound
+}
+
+// SetAttributes adds attributes to the span, replacing those of the same key.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attr := range attrs {
+		replaced := false
+		for i := range s.attrs {
+			if s.attrs[i].Key == attr.Key {
+				s.attrs[i], replaced = attr, true
+			}
+		}
+		if !replaced {
+			s.attrs = append(s.attrs, attr)
+		}
+	}
+}
+
+// RecordError marks the span failed with err, which may be nil.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed, s.message = true, err.Error()
+	s.events = append(s.events, event{
+		name:  "exception",
+		at:    time.Now(),
+		attrs: []Attribute{String("exception.message", err.Error())},
+	})
+}
+
+// End finishes the span and queues it for export if its trace is sampled.
+// Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.mu.Unlock()
+	if s.context.Sampled {
+		s.tracer.exporter.enqueue(s, end)
+	}
+}
+
+type spanKey struct{}
+
+// SpanContextFrom returns the span ctx carries, local or from a traceparent
+// header, or the zero SpanContext.
+func SpanContextFrom(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanKey{}).(SpanContext)
+	return sc
+}
+
+// Inject writes the span ctx carries into header as a W3C traceparent.
+func Inject(ctx context.Context, header http.Header) {
+	sc := SpanContextFrom(ctx)
+	if !sc.IsValid() {
+		return
+	}
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	header.Set("traceparent", fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags))
+}
+
+// Extract returns ctx carrying the remote span of header's traceparent, so
+// the spans started under it continue the caller's trace. A missing or
+// malformed header leaves ctx as it is.
+func Extract(ctx context.Context, header http.Header) context.Context {
+	parts := strings.Split(strings.TrimSpace(header.Get("traceparent")), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return ctx
+	}
+	var sc SpanContext
+	flags, err1 := hex.DecodeString(parts[3])
+	_, err2 := hex.Decode(sc.TraceID[:], []byte(parts[1]))
+	_, err3 := hex.Decode(sc.SpanID[:], []byte(parts[2]))
+	if err1 != nil || err2 != nil || err3 != nil || !sc.IsValid() {
+		return ctx
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return context.WithValue(ctx, spanKey{}, sc)
+}
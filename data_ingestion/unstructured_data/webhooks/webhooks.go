@@ -0,0 +1,448 @@
+// Package webhooks posts newly saved documents to subscribers. A subscription
+// names a URL, optional filters on source, type, tags and symbols, and a
+// secret. Every delivery is signed with it: X-Webhook-Signature is
+// "sha256=" and the hex HMAC-SHA256 of X-Webhook-Timestamp, a dot and the
+// body, which lets the subscriber check where the post came from and reject
+// replays. Failed posts are retried with backoff, and the status of recent
+// deliveries is kept for the admin API.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/config"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/models"
+	"github.com/gaixen/CredTech/data_ingestion/unstructured_data/storage"
+)
+
+// ErrUnknownSubscription is returned for an ID no subscription has.
+var ErrUnknownSubscription = errors.New("unknown webhook subscription")
+
+// Delivery statuses
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+const maxRetryDelay = 5 * time.Minute
+
+// Subscription is a webhook registration. Each non-empty filter must match a
+// document for it to be delivered, by any of its values.
+type Subscription struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Sources   []string  `json:"sources,omitempty"`
+	Types     []string  `json:"types,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Symbols   []string  `json:"symbols,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Delivery is the status of posting one document to one subscription.
+type Delivery struct {
+	ID             string    `json:"id"`
+	SubscriptionID string    `json:"subscription_id"`
+	DataID         string    `json:"data_id"`
+	Status         string    `json:"status"`
+	Attempts       int       `json:"attempts"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// event is the body of a delivery.
+type event struct {
+	Event string                   `json:"event"`
+	Data  *models.UnstructuredData `json:"data"`
+}
+
+type job struct {
+	delivery *Delivery
+	sub      Subscription
+	body     []byte
+}
+
+// Notifier wraps a Storage and delivers every document saved through it to
+// the subscriptions it matches.
+type Notifier struct {
+	storage.Storage
+	config config.WebhooksConfig
+	client *http.Client
+
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+	history       []*Delivery
+
+	queue chan job
+	stop  chan struct{}
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// Wrap returns a Storage that delivers to the subscriptions in cfg.File and
+// starts its workers.
+func Wrap(store storage.Storage, cfg config.WebhooksConfig) (*Notifier, error) {
+	n := &Notifier{
+		Storage:       store,
+		config:        cfg,
+		client:        &http.Client{Timeout: cfg.Timeout},
+		subscriptions: make(map[string]Subscription),
+		queue:         make(chan job, cfg.QueueSize),
+		stop:          make(chan struct{}),
+	}
+	if err := n.load(); err != nil {
+		return nil, fmt.Errorf("failed to load webhook subscriptions from %s: %w", cfg.File, err)
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		n.wg.Add(1)
+		go n.work()
+	}
+	return n, nil
+}
+
+func (n *Notifier) SaveUnstructuredData(ctx context.Context, data *models.UnstructuredData) error {
+	if err := n.Storage.SaveUnstructuredData(ctx, data); err != nil {
+		return err
+	}
+	n.notify(data)
+	return nil
+}
+
+// Subscribe registers sub and returns it with its ID. A secret is generated
+// when sub has none; the returned subscription is the only place it is shown.
+func (n *Notifier) Subscribe(sub Subscription) (Subscription, error) {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, fmt.Errorf("webhook URL %q must be an absolute http or https URL", sub.URL)
+	}
+	sub.ID = randomHex(8)
+	if sub.Secret == "" {
+		sub.Secret = randomHex(32)
+	}
+	sub.CreatedAt = time.Now().UTC()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subscriptions[sub.ID] = sub
+	if err := n.save(); err != nil {
+		delete(n.subscriptions, sub.ID)
+		return Subscription{}, err
+	}
+	return sub, nil
+}
+
+// Unsubscribe removes a subscription; deliveries already queued still go out.
+func (n *Notifier) Unsubscribe(id string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sub, ok := n.subscriptions[id]
+	if !ok {
+		return ErrUnknownSubscription
+	}
+	delete(n.subscriptions, id)
+	if err := n.save(); err != nil {
+		n.subscriptions[id] = sub
+		return err
+	}
+	return nil
+}
+
+// Subscriptions lists the subscriptions by creation, without their secrets.
+func (n *Notifier) Subscriptions() []Subscription {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	subs := make([]Subscription, 0, len(n.subscriptions))
+	for _, sub := range n.subscriptions {
+		sub.Secret = ""
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs
+}
+
+// Subscription returns a subscription without its secret.
+func (n *Notifier) Subscription(id string) (Subscription, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sub, ok := n.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrUnknownSubscription
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// Deliveries returns up to limit of the most recent deliveries to a
+// subscription, newest first.
+func (n *Notifier) Deliveries(id string, limit int) []Delivery {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var deliveries []Delivery
+	for i := len(n.history) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if n.history[i].SubscriptionID == id {
+			deliveries = append(deliveries, *n.history[i])
+		}
+	}
+	return deliveries
+}
+
+// Shutdown stops the workers, waiting until ctx is done at most. Deliveries
+// still queued or waiting for a retry are marked failed.
+func (n *Notifier) Shutdown(ctx context.Context) error {
+	n.once.Do(func() { close(n.stop) })
+	done := make(chan struct{})
+	go func() {
+		n.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, d := range n.history {
+		if d.Status == StatusPending {
+			d.Status, d.Error, d.UpdatedAt = StatusFailed, "service stopped before delivery", time.Now().UTC()
+		}
+	}
+	n.client.CloseIdleConnections()
+	return nil
+}
+
+// notify queues a delivery of data to each subscription it matches.
+func (n *Notifier) notify(data *models.UnstructuredData) {
+	n.mu.Lock()
+	var matched []Subscription
+	for _, sub := range n.subscriptions {
+		if sub.matches(data) {
+			matched = append(matched, sub)
+		}
+	}
+	n.mu.Unlock()
+	if len(matched) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(event{Event: "data.ingested", Data: data})
+	if err != nil {
+		log.Printf("Failed to encode webhook body for %s: %v", data.ID, err)
+		return
+	}
+	now := time.Now().UTC()
+	for _, sub := range matched {
+		d := &Delivery{
+			ID:             randomHex(8),
+			SubscriptionID: sub.ID,
+			DataID:         data.ID,
+			Status:         StatusPending,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		n.record(d)
+		select {
+		case n.queue <- job{delivery: d, sub: sub, body: body}:
+		default:
+			n.finish(d, StatusFailed, 0, "delivery queue full")
+		}
+	}
+}
+
+func (sub Subscription) matches(data *models.UnstructuredData) bool {
+	return matchAny(sub.Sources, []string{data.Source}) &&
+		matchAny(sub.Types, []string{data.Type}) &&
+		matchAny(sub.Tags, data.Tags) &&
+		matchAny(sub.Symbols, data.Symbols())
+}
+
+// matchAny reports whether filter is empty or shares a value with values.
+func matchAny(filter, values []string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, want := range filter {
+		for _, value := range values {
+			if strings.EqualFold(want, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// work delivers queued jobs until Shutdown.
+func (n *Notifier) work() {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-n.stop:
+			return
+		case j := <-n.queue:
+			n.deliver(j)
+		}
+	}
+}
+
+// deliver posts a job until the subscriber accepts it, rejects it with a
+// client error other than 408 or 429, or MaxAttempts are used.
+func (n *Notifier) deliver(j job) {
+	delay := n.config.RetryDelay
+	for attempt := 1; ; attempt++ {
+		code, retry, err := n.post(j)
+		n.mu.Lock()
+		j.delivery.Attempts = attempt
+		n.mu.Unlock()
+		if err == nil {
+			n.finish(j.delivery, StatusDelivered, code, "")
+			return
+		}
+		if !retry || attempt >= n.config.MaxAttempts {
+			n.finish(j.delivery, StatusFailed, code, err.Error())
+			return
+		}
+		n.update(j.delivery, code, err.Error())
+
+		select {
+		case <-n.stop:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxRetryDelay)
+	}
+}
+
+// post sends one attempt, reporting the status code and whether a failure is
+// worth retrying.
+func (n *Notifier) post(j job) (int, bool, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequest("POST", j.sub.URL, bytes.NewReader(j.body))
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CredTech-Webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", "data.ingested")
+	req.Header.Set("X-Webhook-Subscription", j.sub.ID)
+	req.Header.Set("X-Webhook-Delivery", j.delivery.ID)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+Sign(j.sub.Secret, timestamp, j.body))
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, false, nil
+	}
+	retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusRequestTimeout ||
+		resp.StatusCode == http.StatusTooManyRequests
+	return resp.StatusCode, retry, fmt.Errorf("subscriber returned status %d", resp.StatusCode)
+}
+
+// Sign returns the hex HMAC-SHA256 of timestamp, a dot and body under secret,
+// which subscribers compute to check X-Webhook-Signature.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// record adds a delivery to the history, forgetting the oldest past History.
+func (n *Notifier) record(d *Delivery) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.history = append(n.history, d)
+	if extra := len(n.history) - n.config.History; extra > 0 {
+		n.history = append(n.history[:0:0], n.history[extra:]...)
+	}
+}
+
+func (n *Notifier) update(d *Delivery, code int, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	d.StatusCode, d.Error, d.UpdatedAt = code, message, time.Now().UTC()
+}
+
+func (n *Notifier) finish(d *Delivery, status string, code int, message string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	d.Status, d.StatusCode, d.Error, d.UpdatedAt = status, code, message, time.Now().UTC()
+	if status == StatusFailed {
+		log.Printf("Webhook delivery %s of %s to subscription %s failed after %d attempts: %s",
+			d.ID, d.DataID, d.SubscriptionID, d.Attempts, message)
+	}
+}
+
+// load reads the subscriptions file, which need not exist yet.
+func (n *Notifier) load() error {
+	raw, err := os.ReadFile(n.config.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var subs []Subscription
+	if err := json.Unmarshal(raw, &subs); err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		n.subscriptions[sub.ID] = sub
+	}
+	return nil
+}
+
+// save writes the subscriptions file through a temporary file, readable by
+// its owner only since it holds the secrets. The caller holds n.mu.
+func (n *Notifier) save() error {
+	subs := make([]Subscription, 0, len(n.subscriptions))
+	for _, sub := range n.subscriptions {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	raw, err := json.MarshalIndent(subs, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(n.config.File), 0755); err != nil {
+		return err
+	}
+	tmp := n.config.File + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, n.config.File)
+}
+
+func randomHex(size int) string {
+	b := make([]byte, size)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
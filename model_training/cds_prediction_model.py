@@ -1,8 +1,9 @@
 import pandas as pd
 import numpy as np
-from typing import Dict, List, Tuple, Optional, Union
+from typing import Callable, Dict, List, Tuple, Optional, Union
 import warnings
 from datetime import datetime
+import json
 import logging
 
 # Statistics and econometrics
@@ -27,6 +28,28 @@ logging.basicConfig(level=logging.INFO)
 logger = logging.getLogger(__name__)
 
 
+def linear_contributions(model: 'CDSPredictionModel', X: pd.DataFrame) -> pd.DataFrame:
+    """
+    Exact per-feature contributions for the linear models: coefficient times value
+    
+    Args:
+        model: Fitted CDSPredictionModel
+        X: Features for prediction
+        
+    Returns:
+        DataFrame with one column per feature, the intercept in 'const'; rows sum to the prediction
+    """
+    if isinstance(model.results, dict):  # Fama-MacBeth results
+        params = pd.Series({feature: stats['coefficient'] for feature, stats in model.results.items()})
+    else:
+        params = model.results.params
+    
+    features = [feature for feature in X.columns if feature in params.index and feature != 'const']
+    contributions = X[features].astype(float).mul(params[features], axis=1)
+    contributions.insert(0, 'const', params.get('const', 0.0))
+    return contributions
+
+
 
 class CDSPredictionModel:
     """
@@ -37,17 +60,25 @@ class CDSPredictionModel:
     - Bharath, S. T., & Shumway, T. (2008) for distance to default
     """
     
-    def __init__(self, model_type: str = 'panel_fe')-> None:
+    def __init__(self, model_type: str = 'panel_fe',
+                 explainer: Optional[Callable[['CDSPredictionModel', pd.DataFrame], pd.DataFrame]] = None,
+                 explanation_log: Optional[str] = None)-> None:
         """
         Initialize the CDS prediction model
         
         Args:
             model_type: Type of model ('panel_fe', 'pooled_ols', 'fama_macbeth')
+            explainer: Per-prediction contribution function, linear_contributions by default;
+                       a SHAP-style explainer for non-linear models plugs in here
+            explanation_log: JSON lines file explanations are appended to, kept in memory only if unset
         """
         self.model_type = model_type
         self.model = None
         self.results = None
         self.feature_importance = None
+        self.explainer = explainer or linear_contributions
+        self.explanation_log = explanation_log
+        self.explanations = {}
         
     def prepare_panel_data(self, df: pd.DataFrame, entity_col: str = 'symbol', 
                           time_col: str = 'date') -> pd.DataFrame:
@@ -349,12 +380,14 @@ class CDSPredictionModel:
         self.feature_importance = importance_df
         return importance_df
     
-    def predict(self, X: pd.DataFrame) -> np.ndarray:
+    def predict(self, X: pd.DataFrame, score_ids: Optional[List[str]] = None) -> np.ndarray:
         """
         Make predictions using fitted model
         
         Args:
             X: Features for prediction
+            score_ids: One ID per row; when given, each prediction's feature
+                       contributions are logged under its ID
             
         Returns:
             Array of predictions
@@ -363,10 +396,81 @@ class CDSPredictionModel:
             raise ValueError("Model must be fitted before making predictions")
         
         if hasattr(self.model, 'predict'):
-            return self.model.predict(X)
+            predictions = self.model.predict(X)
         else:
             logger.warning("Direct prediction not available for this model type")
-            return np.array([])
+            predictions = np.array([])
+        
+        if score_ids is not None:
+            self.explain(X, score_ids)
+        return predictions
+    
+    def explain(self, X: pd.DataFrame, score_ids: List[str]) -> pd.DataFrame:
+        """
+        Log the feature vector and per-feature contributions of each prediction
+        
+        Args:
+            X: Features for prediction
+            score_ids: One ID per row of X
+            
+        Returns:
+            DataFrame of contributions indexed by score ID
+        """
+        if self.results is None:
+            raise ValueError("Model must be fitted before explaining predictions")
+        if len(score_ids) != len(X):
+            raise ValueError(f"Got {len(score_ids)} score IDs for {len(X)} predictions")
+        
+        contributions = self.explainer(self, X)
+        contributions.index = score_ids
+        timestamp = datetime.utcnow().isoformat()
+        
+        records = []
+        for (score_id, row), (_, features) in zip(contributions.iterrows(), X.iterrows()):
+            record = {
+                'score_id': score_id,
+                'model_type': self.model_type,
+                'explainer': getattr(self.explainer, '__name__', type(self.explainer).__name__),
+                'timestamp': timestamp,
+                'prediction': float(row.sum()),
+                'features': {feature: float(value) for feature, value in features.items()},
+                'contributions': {feature: float(value) for feature, value in row.items()}
+            }
+            self.explanations[score_id] = record
+            records.append(record)
+        
+        if self.explanation_log:
+            with open(self.explanation_log, 'a') as f:
+                for record in records:
+                    f.write(json.dumps(record) + '\n')
+        
+        logger.info(f"Logged feature contributions for {len(records)} predictions")
+        return contributions
+    
+    def get_explanation(self, score_id: str) -> Optional[Dict]:
+        """
+        Look up the logged feature vector and contributions of a prediction
+        
+        Args:
+            score_id: ID the prediction was logged under
+            
+        Returns:
+            Explanation record, or None if the score ID was never logged
+        """
+        if score_id in self.explanations:
+            return self.explanations[score_id]
+        
+        found = None
+        if self.explanation_log:
+            try:
+                with open(self.explanation_log) as f:
+                    for line in f:
+                        record = json.loads(line)
+                        if record['score_id'] == score_id:
+                            found = record  # a re-logged ID keeps its latest record
+            except FileNotFoundError:
+                pass
+        return found
     
     def generate_model_summary(self) -> str:
         """
@@ -3,12 +3,18 @@ module research
 go 1.24.4
 
 require (
-	github.com/PuerkitoBio/goquery v1.10.3 // indirect
+	github.com/PuerkitoBio/goquery v1.10.3
+	github.com/gaixen/CredTech/symbols v0.0.0
+	github.com/lib/pq v1.10.9
+	github.com/tidwall/gjson v1.18.0
+	gonum.org/v1/gonum v0.16.0
+)
+
+require (
 	github.com/andybalholm/cascadia v1.3.3 // indirect
-	github.com/lib/pq v1.10.9 // indirect
-	github.com/tidwall/gjson v1.18.0 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
 	golang.org/x/net v0.39.0 // indirect
-	gonum.org/v1/gonum v0.16.0 // indirect
 )
+
+replace github.com/gaixen/CredTech/symbols => ../symbols
@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/gaixen/CredTech/symbols"
 	_ "github.com/lib/pq"
 	"github.com/tidwall/gjson"
 	// "gonum.org/v1/gonum/floats"
@@ -232,10 +233,19 @@ func (de *DataExtractor) extractMacroData() (map[string]float64, error) {
 
 // Web scraping for CDS data (using alternative sources)
 func (de *DataExtractor) scrapeCDSData(symbol string) (float64, error) {
+	// MarketWatch writes share classes with a dot and lists US securities only
+	ticker, ok, err := symbols.To(symbol, symbols.Nasdaq)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, fmt.Errorf("no MarketWatch page for %s", symbol)
+	}
+
 	<-de.rateLimiter
 
 	// Use MarketWatch's CDS section (less monitored than Bloomberg)
-	url := fmt.Sprintf("https://www.marketwatch.com/investing/stock/%s/financials/cash-flow/quarter", strings.ToLower(symbol))
+	url := fmt.Sprintf("https://www.marketwatch.com/investing/stock/%s/financials/cash-flow/quarter", strings.ToLower(ticker))
 	
 	resp, err := de.httpClient.Get(url)
 	if err != nil {
@@ -355,7 +365,7 @@ func (de *DataExtractor) calculateRollingAverage(values []float64, window int) [
 }
 
 // Main extraction pipeline
-func (de *DataExtractor) ExtractAllFeatures(symbols []string) error {
+func (de *DataExtractor) ExtractAllFeatures(tickers []string) error {
 	allData := make([]FinancialData, 0)
 	
 	// Get macro data once
@@ -364,8 +374,8 @@ func (de *DataExtractor) ExtractAllFeatures(symbols []string) error {
 		log.Printf("Failed to extract macro data: %v", err)
 	}
 	
-	// Process each company
-	for _, symbol := range symbols {
+	// Process each company, once however its symbol was written
+	for _, symbol := range symbols.NormalizeAll(tickers) {
 		de.wg.Add(1)
 		go func(sym string) {
 			defer de.wg.Done()
@@ -560,7 +570,7 @@ func convertRatingToNumerical(rating string) float64 {
 
 func main() {
 	// List of companies to analyze (non-financial DJIA components)
-	symbols := []string{
+	tickers := []string{
 		"AAPL", "MSFT", "UNH", "JNJ", "V", "WMT", "PG", "HD",
 		"MA", "DIS", "ADBE", "CRM", "VZ", "KO", "PFE", "PEP",
 		"TMO", "ABT", "COST", "AVGO", "XOM", "NKE",
@@ -568,7 +578,7 @@ func main() {
 
 	extractor := NewDataExtractor()
 	
-	if err := extractor.ExtractAllFeatures(symbols); err != nil {
+	if err := extractor.ExtractAllFeatures(tickers); err != nil {
 		log.Fatalf("Feature extraction failed: %v", err)
 	}
 	
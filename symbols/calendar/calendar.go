@@ -0,0 +1,269 @@
+// Package calendar models exchange trading sessions: local hours, midday
+// breaks, weekends, public holidays and early closes.
+package calendar
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	_ "time/tzdata" // exchange time zones must resolve in minimal containers too
+
+	"github.com/gaixen/CredTech/symbols"
+)
+
+// Calendar is the trading calendar of one exchange.
+type Calendar struct {
+	Code     string
+	Name     string
+	Suffix   string // Yahoo suffix without the dot; empty for US listings
+	Timezone string // IANA zone the session times are expressed in
+
+	// Regular session and midday break, if any, in local HH:MM.
+	Open       string
+	Close      string
+	LunchStart string
+	LunchEnd   string
+
+	location             *time.Location
+	open, close          int // minutes after local midnight
+	lunchStart, lunchEnd int
+	rules                func(year int) yearSchedule
+
+	mu    sync.Mutex
+	years map[int]yearSchedule
+	extra map[civilDate]bool
+}
+
+// civilDate is a calendar day independent of time zone.
+type civilDate struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// yearSchedule lists the exceptions to the regular session in one year:
+// full-day holidays and days that close early, with their closing minute.
+type yearSchedule struct {
+	holidays   map[civilDate]bool
+	earlyClose map[civilDate]int
+}
+
+// calendars lists the supported exchanges. NSE and BSE holidays follow the
+// Hindu lunar calendar and are published yearly, so they must be loaded with
+// AddHolidays; without them a holiday counts as a trading day.
+var calendars = []*Calendar{
+	{Code: "US", Name: "NYSE / Nasdaq", Timezone: "America/New_York", Open: "09:30", Close: "16:00", rules: nyseYear},
+	{Code: "NSE", Name: "National Stock Exchange of India", Suffix: "NS", Timezone: "Asia/Kolkata", Open: "09:15", Close: "15:30"},
+	{Code: "BSE", Name: "BSE (Bombay Stock Exchange)", Suffix: "BO", Timezone: "Asia/Kolkata", Open: "09:15", Close: "15:30"},
+	{Code: "LSE", Name: "London Stock Exchange", Suffix: "L", Timezone: "Europe/London", Open: "08:00", Close: "16:30", rules: lseYear},
+	{Code: "TSE", Name: "Tokyo Stock Exchange", Suffix: "T", Timezone: "Asia/Tokyo", Open: "09:00", Close: "15:30", LunchStart: "11:30", LunchEnd: "12:30", rules: tseYear},
+}
+
+var (
+	calendarsByCode   = make(map[string]*Calendar)
+	calendarsBySuffix = make(map[string]*Calendar)
+)
+
+func init() {
+	for _, c := range calendars {
+		location, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			panic("calendar " + c.Code + ": " + err.Error())
+		}
+		c.location = location
+		c.open, c.close = clockMinutes(c.Open), clockMinutes(c.Close)
+		c.lunchStart, c.lunchEnd = clockMinutes(c.LunchStart), clockMinutes(c.LunchEnd)
+		calendarsByCode[c.Code] = c
+		calendarsBySuffix[c.Suffix] = c
+	}
+}
+
+// clockMinutes parses a local HH:MM time into minutes after midnight; empty
+// is zero.
+func clockMinutes(clock string) int {
+	if clock == "" {
+		return 0
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		panic("invalid session clock " + clock)
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+// All returns the calendars of every supported exchange.
+func All() []*Calendar {
+	return append([]*Calendar(nil), calendars...)
+}
+
+// ForCode returns the calendar for an exchange code such as "US" or "LSE",
+// or nil if it is unknown.
+func ForCode(code string) *Calendar {
+	return calendarsByCode[code]
+}
+
+// ForSymbol returns the calendar of the exchange a ticker trades on, or nil
+// for indices, currencies, futures and exchanges without a calendar.
+func ForSymbol(symbol string) *Calendar {
+	parsed, err := symbols.Parse(symbol)
+	if err != nil || parsed.Kind != symbols.KindSecurity {
+		return nil
+	}
+	return calendarsBySuffix[parsed.Suffix]
+}
+
+// Location returns the exchange's time zone.
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+// AddHolidays marks extra full-day closures, given as YYYY-MM-DD local dates.
+// It is used for exchanges whose holidays are not rule based and for one-off
+// closures such as state funerals.
+func (c *Calendar) AddHolidays(dates ...string) error {
+	parsed := make([]civilDate, 0, len(dates))
+	for _, date := range dates {
+		t, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			return fmt.Errorf("holiday %q: %w", date, err)
+		}
+		parsed = append(parsed, dateOf(t))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.extra == nil {
+		c.extra = make(map[civilDate]bool)
+	}
+	for _, d := range parsed {
+		c.extra[d] = true
+	}
+	return nil
+}
+
+// IsHoliday reports whether the local day containing t is a weekday on
+// which the exchange is closed.
+func (c *Calendar) IsHoliday(t time.Time) bool {
+	local := t.In(c.location)
+	if isWeekend(local.Weekday()) {
+		return false
+	}
+	_, closed := c.closeMinute(dateOf(local))
+	return closed
+}
+
+// IsTradingDay reports whether the exchange holds a session on the local day
+// containing t.
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	local := t.In(c.location)
+	if isWeekend(local.Weekday()) {
+		return false
+	}
+	_, closed := c.closeMinute(dateOf(local))
+	return !closed
+}
+
+// IsOpen reports whether the regular session is running at t.
+func (c *Calendar) IsOpen(t time.Time) bool {
+	local := t.In(c.location)
+	if isWeekend(local.Weekday()) {
+		return false
+	}
+	closeAt, closed := c.closeMinute(dateOf(local))
+	if closed {
+		return false
+	}
+	minute := local.Hour()*60 + local.Minute()
+	if minute < c.open || minute >= closeAt {
+		return false
+	}
+	return c.lunchStart == 0 || minute < c.lunchStart || minute >= c.lunchEnd
+}
+
+// NextOpen returns the next time at or after t that the regular session (or
+// its afternoon half, after a midday break) starts.
+func (c *Calendar) NextOpen(t time.Time) time.Time {
+	local := t.In(c.location)
+	// The longest closures, such as Golden Week in Tokyo, span well under a month
+	for day := 0; day < 31; day++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, c.location)
+		if isWeekend(date.Weekday()) {
+			continue
+		}
+		closeAt, closed := c.closeMinute(dateOf(date))
+		if closed {
+			continue
+		}
+		starts := []int{c.open}
+		if c.lunchStart != 0 && c.lunchEnd < closeAt {
+			starts = append(starts, c.lunchEnd)
+		}
+		for _, start := range starts {
+			// Build from the wall clock rather than adding minutes, which drifts on DST days
+			open := time.Date(date.Year(), date.Month(), date.Day(), start/60, start%60, 0, 0, c.location)
+			if !open.Before(t) {
+				return open
+			}
+		}
+	}
+	return t // unreachable for any calendar with regular trading days
+}
+
+// NextClose returns the next time at or after t that the session ends for the
+// day, taking early closes into account.
+func (c *Calendar) NextClose(t time.Time) time.Time {
+	local := t.In(c.location)
+	for day := 0; day < 31; day++ {
+		date := time.Date(local.Year(), local.Month(), local.Day()+day, 0, 0, 0, 0, c.location)
+		if isWeekend(date.Weekday()) {
+			continue
+		}
+		closeAt, closed := c.closeMinute(dateOf(date))
+		if closed {
+			continue
+		}
+		end := time.Date(date.Year(), date.Month(), date.Day(), closeAt/60, closeAt%60, 0, 0, c.location)
+		if !end.Before(t) {
+			return end
+		}
+	}
+	return t
+}
+
+// closeMinute returns the closing minute of a weekday and whether the
+// exchange is shut all day.
+func (c *Calendar) closeMinute(d civilDate) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.extra[d] {
+		return 0, true
+	}
+	if c.rules == nil {
+		return c.close, false
+	}
+	if c.years == nil {
+		c.years = make(map[int]yearSchedule)
+	}
+	schedule, ok := c.years[d.year]
+	if !ok {
+		schedule = c.rules(d.year)
+		c.years[d.year] = schedule
+	}
+	if schedule.holidays[d] {
+		return 0, true
+	}
+	if minute, ok := schedule.earlyClose[d]; ok {
+		return minute, false
+	}
+	return c.close, false
+}
+
+// dateOf returns the calendar day of t in its own location.
+func dateOf(t time.Time) civilDate {
+	return civilDate{t.Year(), t.Month(), t.Day()}
+}
+
+func isWeekend(day time.Weekday) bool {
+	return day == time.Saturday || day == time.Sunday
+}
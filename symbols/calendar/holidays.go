@@ -0,0 +1,203 @@
+package calendar
+
+import (
+	"math"
+	"time"
+)
+
+func newYearSchedule() yearSchedule {
+	return yearSchedule{
+		holidays:   make(map[civilDate]bool),
+		earlyClose: make(map[civilDate]int),
+	}
+}
+
+// shut marks a full-day closure.
+func (s yearSchedule) shut(t time.Time) {
+	s.holidays[dateOf(t)] = true
+}
+
+// closeEarly marks a shortened session, unless the day is not traded anyway.
+func (s yearSchedule) closeEarly(t time.Time, minute int) {
+	if isWeekend(t.Weekday()) || s.holidays[dateOf(t)] {
+		return
+	}
+	s.earlyClose[dateOf(t)] = minute
+}
+
+// substitute returns t, or the first following weekday that is not already a
+// holiday when t falls on a weekend.
+func (s yearSchedule) substitute(t time.Time) time.Time {
+	if !isWeekend(t.Weekday()) {
+		return t
+	}
+	for isWeekend(t.Weekday()) || s.holidays[dateOf(t)] {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// nyseYear returns the NYSE and Nasdaq holidays and 13:00 early closes.
+func nyseYear(year int) yearSchedule {
+	s := newYearSchedule()
+
+	// New Year's Day on a Saturday is not observed on the preceding Friday,
+	// which would fall in the previous year
+	if newYear := date(year, time.January, 1); newYear.Weekday() != time.Saturday {
+		s.shut(observedUS(newYear))
+	}
+	s.shut(nthWeekday(year, time.January, time.Monday, 3))  // Martin Luther King Jr. Day
+	s.shut(nthWeekday(year, time.February, time.Monday, 3)) // Washington's Birthday
+	s.shut(easter(year).AddDate(0, 0, -2))                  // Good Friday
+	s.shut(nthWeekday(year, time.May, time.Monday, -1))     // Memorial Day
+	if year >= 2022 {
+		s.shut(observedUS(date(year, time.June, 19))) // Juneteenth
+	}
+	s.shut(observedUS(date(year, time.July, 4)))
+	s.shut(nthWeekday(year, time.September, time.Monday, 1)) // Labor Day
+	thanksgiving := nthWeekday(year, time.November, time.Thursday, 4)
+	s.shut(thanksgiving)
+	s.shut(observedUS(date(year, time.December, 25)))
+
+	s.closeEarly(date(year, time.July, 3), 13*60)
+	s.closeEarly(thanksgiving.AddDate(0, 0, 1), 13*60)
+	s.closeEarly(date(year, time.December, 24), 13*60)
+	return s
+}
+
+// observedUS moves a Saturday holiday to Friday and a Sunday one to Monday.
+func observedUS(t time.Time) time.Time {
+	switch t.Weekday() {
+	case time.Saturday:
+		return t.AddDate(0, 0, -1)
+	case time.Sunday:
+		return t.AddDate(0, 0, 1)
+	}
+	return t
+}
+
+// lseYear returns the London Stock Exchange holidays, which follow the bank
+// holidays of England and Wales, and the 12:30 closes on Christmas Eve and
+// New Year's Eve. One-off bank holidays such as coronations must be added
+// with AddHolidays.
+func lseYear(year int) yearSchedule {
+	s := newYearSchedule()
+
+	s.shut(s.substitute(date(year, time.January, 1)))
+	easterSunday := easter(year)
+	s.shut(easterSunday.AddDate(0, 0, -2))                 // Good Friday
+	s.shut(easterSunday.AddDate(0, 0, 1))                  // Easter Monday
+	s.shut(nthWeekday(year, time.May, time.Monday, 1))     // Early May bank holiday
+	s.shut(nthWeekday(year, time.May, time.Monday, -1))    // Spring bank holiday
+	s.shut(nthWeekday(year, time.August, time.Monday, -1)) // Summer bank holiday
+	// Boxing Day goes first so that a Sunday Christmas moves past a Monday Boxing Day
+	s.shut(s.substitute(date(year, time.December, 26)))
+	s.shut(s.substitute(date(year, time.December, 25)))
+
+	s.closeEarly(date(year, time.December, 24), 12*60+30)
+	s.closeEarly(date(year, time.December, 31), 12*60+30)
+	return s
+}
+
+// tseYear returns the Tokyo Stock Exchange holidays: Japanese national
+// holidays plus the year-end closure from December 31 to January 3.
+func tseYear(year int) yearSchedule {
+	s := newYearSchedule()
+	for d := range japaneseHolidays(year) {
+		s.holidays[d] = true
+	}
+	for day := 1; day <= 3; day++ {
+		s.shut(date(year, time.January, day))
+	}
+	s.shut(date(year, time.December, 31))
+	return s
+}
+
+// japaneseHolidays returns the national holidays of a year under the rules in
+// force since 2020, including substitute and citizens' holidays. One-off
+// moves such as the 2020 and 2021 Olympic reshuffles are not modelled.
+func japaneseHolidays(year int) map[civilDate]bool {
+	days := []time.Time{
+		date(year, time.January, 1),
+		nthWeekday(year, time.January, time.Monday, 2), // Coming of Age Day
+		date(year, time.February, 11),
+		date(year, time.February, 23), // Emperor's Birthday
+		date(year, time.March, equinoxDay(year, 20.8431)),
+		date(year, time.April, 29),
+		date(year, time.May, 3),
+		date(year, time.May, 4),
+		date(year, time.May, 5),
+		nthWeekday(year, time.July, time.Monday, 3), // Marine Day
+		date(year, time.August, 11),
+		nthWeekday(year, time.September, time.Monday, 3), // Respect for the Aged Day
+		date(year, time.September, equinoxDay(year, 23.2488)),
+		nthWeekday(year, time.October, time.Monday, 2), // Sports Day
+		date(year, time.November, 3),
+		date(year, time.November, 23),
+	}
+	holidays := make(map[civilDate]bool, len(days)+2)
+	for _, t := range days {
+		holidays[dateOf(t)] = true
+	}
+
+	// A day sandwiched between two holidays is itself a holiday
+	for _, t := range days {
+		between, after := t.AddDate(0, 0, 1), t.AddDate(0, 0, 2)
+		if holidays[dateOf(after)] && !holidays[dateOf(between)] && between.Weekday() != time.Sunday {
+			holidays[dateOf(between)] = true
+		}
+	}
+	// A holiday on a Sunday moves to the next day that is not already one
+	for _, t := range days {
+		if t.Weekday() != time.Sunday {
+			continue
+		}
+		for holidays[dateOf(t)] {
+			t = t.AddDate(0, 0, 1)
+		}
+		holidays[dateOf(t)] = true
+	}
+	return holidays
+}
+
+// equinoxDay approximates the day of March or September on which the equinox
+// falls in Japan; base is the 1980 value. The formula holds until 2099.
+func equinoxDay(year int, base float64) int {
+	years := float64(year - 1980)
+	return int(math.Floor(base + 0.242194*years - math.Floor(years/4)))
+}
+
+// date returns midnight UTC on a calendar day; holiday rules only use its
+// year, month, day and weekday.
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// nthWeekday returns the n-th given weekday of a month, or the last one when
+// n is negative.
+func nthWeekday(year int, month time.Month, weekday time.Weekday, n int) time.Time {
+	if n < 0 {
+		last := date(year, month+1, 0)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+	}
+	first := date(year, month, 1)
+	offset := (int(weekday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+7*(n-1))
+}
+
+// easter returns Easter Sunday in the Gregorian calendar using the anonymous
+// Gregorian algorithm.
+func easter(year int) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return date(year, time.Month(month), day)
+}
@@ -0,0 +1,75 @@
+package symbols
+
+// Exchange is a listing venue identified by its Yahoo suffix.
+type Exchange struct {
+	Suffix   string // without the dot; empty for US listings
+	Name     string
+	Country  string // ISO 3166 alpha-2
+	Currency string // ISO 4217 trading currency
+}
+
+// exchanges lists the Yahoo Finance suffixes accepted by Parse.
+var exchanges = []Exchange{
+	{"", "NYSE / Nasdaq", "US", "USD"},
+	{"NS", "National Stock Exchange of India", "IN", "INR"},
+	{"BO", "BSE", "IN", "INR"},
+	{"L", "London Stock Exchange", "GB", "GBP"},
+	{"IL", "London Stock Exchange IOB", "GB", "USD"},
+	{"T", "Tokyo Stock Exchange", "JP", "JPY"},
+	{"TO", "Toronto Stock Exchange", "CA", "CAD"},
+	{"V", "TSX Venture Exchange", "CA", "CAD"},
+	{"NE", "Cboe Canada", "CA", "CAD"},
+	{"AX", "Australian Securities Exchange", "AU", "AUD"},
+	{"NZ", "New Zealand Exchange", "NZ", "NZD"},
+	{"HK", "Hong Kong Stock Exchange", "HK", "HKD"},
+	{"SS", "Shanghai Stock Exchange", "CN", "CNY"},
+	{"SZ", "Shenzhen Stock Exchange", "CN", "CNY"},
+	{"KS", "Korea Exchange", "KR", "KRW"},
+	{"KQ", "KOSDAQ", "KR", "KRW"},
+	{"TW", "Taiwan Stock Exchange", "TW", "TWD"},
+	{"SI", "Singapore Exchange", "SG", "SGD"},
+	{"JK", "Indonesia Stock Exchange", "ID", "IDR"},
+	{"BK", "Stock Exchange of Thailand", "TH", "THB"},
+	{"KL", "Bursa Malaysia", "MY", "MYR"},
+	{"DE", "XETRA", "DE", "EUR"},
+	{"F", "Frankfurt Stock Exchange", "DE", "EUR"},
+	{"PA", "Euronext Paris", "FR", "EUR"},
+	{"AS", "Euronext Amsterdam", "NL", "EUR"},
+	{"BR", "Euronext Brussels", "BE", "EUR"},
+	{"LS", "Euronext Lisbon", "PT", "EUR"},
+	{"IR", "Euronext Dublin", "IE", "EUR"},
+	{"MI", "Borsa Italiana", "IT", "EUR"},
+	{"MC", "Bolsa de Madrid", "ES", "EUR"},
+	{"SW", "SIX Swiss Exchange", "CH", "CHF"},
+	{"VI", "Vienna Stock Exchange", "AT", "EUR"},
+	{"ST", "Nasdaq Stockholm", "SE", "SEK"},
+	{"OL", "Oslo Bors", "NO", "NOK"},
+	{"CO", "Nasdaq Copenhagen", "DK", "DKK"},
+	{"HE", "Nasdaq Helsinki", "FI", "EUR"},
+	{"WA", "Warsaw Stock Exchange", "PL", "PLN"},
+	{"IS", "Borsa Istanbul", "TR", "TRY"},
+	{"TA", "Tel Aviv Stock Exchange", "IL", "ILS"},
+	{"JO", "Johannesburg Stock Exchange", "ZA", "ZAR"},
+	{"SA", "B3", "BR", "BRL"},
+	{"MX", "Bolsa Mexicana de Valores", "MX", "MXN"},
+}
+
+var exchangesBySuffix = make(map[string]*Exchange)
+
+func init() {
+	for i := range exchanges {
+		exchangesBySuffix[exchanges[i].Suffix] = &exchanges[i]
+	}
+}
+
+// ExchangeForSuffix returns the exchange for a Yahoo suffix given with or
+// without its dot, or nil if it is unknown.
+func ExchangeForSuffix(suffix string) *Exchange {
+	if len(suffix) > 0 && suffix[0] == '.' {
+		suffix = suffix[1:]
+	}
+	if suffix == "" {
+		return nil
+	}
+	return exchangesBySuffix[suffix]
+}
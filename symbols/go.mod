@@ -0,0 +1,3 @@
+module github.com/gaixen/CredTech/symbols
+
+go 1.21
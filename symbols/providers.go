@@ -0,0 +1,66 @@
+package symbols
+
+import "strings"
+
+// Provider identifies a symbol notation.
+type Provider string
+
+const (
+	Yahoo      Provider = "yahoo"      // BRK-B, VOD.L, ^GSPC
+	Finnhub    Provider = "finnhub"    // BRK.B, VOD.L
+	Nasdaq     Provider = "nasdaq"     // BRK.B in the Nasdaq Trader listing files; US only
+	SEC        Provider = "sec"        // BRK-B in EDGAR company tickers; US only
+	StockTwits Provider = "stocktwits" // BRK.B cashtags; US only
+)
+
+// To renders raw in provider's notation. It returns ok=false when provider
+// has no notation for the symbol, such as a London listing in the Nasdaq files.
+func To(raw string, provider Provider) (string, bool, error) {
+	sym, err := Parse(raw)
+	if err != nil {
+		return "", false, err
+	}
+
+	switch provider {
+	case Yahoo:
+		return sym.String(), true, nil
+	case Finnhub:
+		if sym.Kind != KindSecurity {
+			return "", false, nil
+		}
+		return strings.Replace(sym.String(), "-", ".", 1), true, nil
+	case Nasdaq, StockTwits:
+		if sym.Kind != KindSecurity || sym.Suffix != "" {
+			return "", false, nil
+		}
+		if sym.Class != "" {
+			return sym.Base + "." + sym.Class, true, nil
+		}
+		return sym.Base, true, nil
+	case SEC:
+		if sym.Kind != KindSecurity || sym.Suffix != "" {
+			return "", false, nil
+		}
+		return sym.String(), true, nil
+	}
+	return "", false, nil
+}
+
+// From normalizes raw written in provider's notation. Nasdaq, SEC and
+// StockTwits symbols are always US listings, so a dot there separates a share
+// class even when the class letter matches an exchange suffix (a hypothetical
+// XYZ.V is class V, not the TSX Venture listing that Parse would read).
+func From(raw string, provider Provider) (string, error) {
+	switch provider {
+	case Nasdaq, SEC, StockTwits:
+		raw = strings.ReplaceAll(strings.TrimSpace(raw), ".", "-")
+	}
+	return Normalize(raw)
+}
+
+// Equal reports whether a and b name the same security in any notation.
+func Equal(a, b string) bool {
+	na, errA := Normalize(a)
+	nb, errB := Normalize(b)
+	return errA == nil && errB == nil && na == nb
+}
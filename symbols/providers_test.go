@@ -0,0 +1,79 @@
+package symbols
+
+import "testing"
+
+func TestTo(t *testing.T) {
+	tests := []struct {
+		raw      string
+		provider Provider
+		want     string
+		ok       bool
+	}{
+		{"brk.b", Yahoo, "BRK-B", true},
+		{"^GSPC", Yahoo, "^GSPC", true},
+		{"BRK-B", Finnhub, "BRK.B", true},
+		{"RDS-A.L", Finnhub, "RDS.A.L", true},
+		{"VOD.L", Finnhub, "VOD.L", true},
+		{"^GSPC", Finnhub, "", false},
+		{"BRK-B", Nasdaq, "BRK.B", true},
+		{"AAPL", Nasdaq, "AAPL", true},
+		{"VOD.L", Nasdaq, "", false},
+		{"EURUSD=X", Nasdaq, "", false},
+		{"BRK.B", SEC, "BRK-B", true},
+		{"VOD.L", SEC, "", false},
+		{"$brk/b", StockTwits, "BRK.B", true},
+		{"CL=F", StockTwits, "", false},
+		{"AAPL", Provider("bloomberg"), "", false},
+	}
+	for _, tt := range tests {
+		got, ok, err := To(tt.raw, tt.provider)
+		if err != nil || got != tt.want || ok != tt.ok {
+			t.Errorf("To(%q, %s) = %q, %v, %v; want %q, %v", tt.raw, tt.provider, got, ok, err, tt.want, tt.ok)
+		}
+	}
+
+	if _, _, err := To("BRK-BBB", Finnhub); err == nil {
+		t.Error("To with an invalid symbol succeeded")
+	}
+}
+
+func TestFrom(t *testing.T) {
+	tests := []struct {
+		raw      string
+		provider Provider
+		want     string
+	}{
+		{"BRK.B", Finnhub, "BRK-B"},
+		{"VOD.L", Finnhub, "VOD.L"},
+		{"BRK.B", Nasdaq, "BRK-B"},
+		// US-only notations read a dot as a share class even where it
+		// matches an exchange suffix
+		{"XYZ.V", Nasdaq, "XYZ-V"},
+		{"XYZ.L", SEC, "XYZ-L"},
+		{"ABC.TO", StockTwits, "ABC-TO"},
+		{"XYZ.V", Finnhub, "XYZ.V"},
+		{"brk-b", Yahoo, "BRK-B"},
+	}
+	for _, tt := range tests {
+		got, err := From(tt.raw, tt.provider)
+		if err != nil || got != tt.want {
+			t.Errorf("From(%q, %s) = %q, %v; want %q", tt.raw, tt.provider, got, err, tt.want)
+		}
+	}
+
+	if got, err := From("BRK.XYZ", Nasdaq); err == nil {
+		t.Errorf("From(%q, nasdaq) = %q, want an error", "BRK.XYZ", got)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	if !Equal("BRK.B", "$brk/b") {
+		t.Error("BRK.B and $brk/b should be equal")
+	}
+	if Equal("BRK.L", "BRK-L") {
+		t.Error("the London listing BRK.L should not equal class L of BRK")
+	}
+	if Equal("bad!", "bad!") {
+		t.Error("invalid symbols should never be equal")
+	}
+}
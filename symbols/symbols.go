@@ -0,0 +1,158 @@
+// Package symbols normalizes ticker symbols across the data providers used by
+// CredTech. The canonical form is Yahoo Finance's: upper case, share classes
+// joined with a dash (BRK-B) and listing venues as a dot suffix (VOD.L).
+//
+// The same security arrives as BRK.B from Finnhub and the Nasdaq listing
+// files, BRK/B from some news feeds, $BRK.B as a cashtag and brk-b from user
+// input; Normalize maps all of them to BRK-B so lookups agree everywhere.
+//
+// A dot followed by a known exchange suffix is read as a venue, so BRK.L is a
+// London listing rather than class L. Feeds that only carry US listings should
+// go through From, which reads every dot as a share class.
+package symbols
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ErrInvalid marks input that cannot be a ticker symbol.
+var ErrInvalid = errors.New("invalid symbol")
+
+// ErrUnknownSuffix marks a dot suffix that is neither a share class nor a
+// known exchange.
+var ErrUnknownSuffix = errors.New("unknown exchange suffix")
+
+// Kind distinguishes the instrument families that use different notations.
+type Kind string
+
+const (
+	KindSecurity Kind = "security" // equities, funds and other listed securities
+	KindIndex    Kind = "index"    // ^GSPC
+	KindCurrency Kind = "currency" // EURUSD=X
+	KindFuture   Kind = "future"   // CL=F
+)
+
+// Symbol is a parsed ticker.
+type Symbol struct {
+	Base   string // root ticker without class or venue, e.g. BRK or VOD
+	Class  string // share class, e.g. B in BRK-B
+	Suffix string // Yahoo exchange suffix without the dot, e.g. L in VOD.L; empty for US listings
+	Kind   Kind
+}
+
+// String returns the canonical Yahoo form.
+func (s Symbol) String() string {
+	switch s.Kind {
+	case KindIndex:
+		return "^" + s.Base
+	case KindCurrency:
+		return s.Base + "=X"
+	case KindFuture:
+		return s.Base + "=F"
+	}
+	out := s.Base
+	if s.Class != "" {
+		out += "-" + s.Class
+	}
+	if s.Suffix != "" {
+		out += "." + s.Suffix
+	}
+	return out
+}
+
+// Exchange returns the listing exchange, or nil for indices, currencies,
+// futures and suffixes not in the table.
+func (s Symbol) Exchange() *Exchange {
+	if s.Kind != KindSecurity {
+		return nil
+	}
+	return exchangesBySuffix[s.Suffix]
+}
+
+var (
+	basePattern  = regexp.MustCompile(`^[A-Z0-9][A-Z0-9&]{0,11}$`)
+	classPattern = regexp.MustCompile(`^[A-Z]{1,2}$`)
+)
+
+// Parse splits raw into its parts. It accepts cashtags ($AAPL), any case,
+// and share classes written with a dash, dot, slash or space.
+func Parse(raw string) (Symbol, error) {
+	s := strings.ToUpper(strings.TrimSpace(raw))
+	s = strings.TrimPrefix(s, "$")
+	if s == "" || len(s) > 20 {
+		return Symbol{}, fmt.Errorf("%q: %w", raw, ErrInvalid)
+	}
+
+	switch {
+	case strings.HasPrefix(s, "^"):
+		return parseWhole(raw, s[1:], KindIndex)
+	case strings.HasSuffix(s, "=X"):
+		return parseWhole(raw, strings.TrimSuffix(s, "=X"), KindCurrency)
+	case strings.HasSuffix(s, "=F"):
+		return parseWhole(raw, strings.TrimSuffix(s, "=F"), KindFuture)
+	}
+
+	sym := Symbol{Kind: KindSecurity}
+	if dot := strings.LastIndex(s, "."); dot > 0 {
+		suffix := s[dot+1:]
+		if _, ok := exchangesBySuffix[suffix]; ok {
+			sym.Suffix = suffix
+			s = s[:dot]
+		}
+	}
+
+	// Whatever separator remains joins a share class
+	if i := strings.LastIndexAny(s, "-./ "); i > 0 {
+		class := s[i+1:]
+		if !classPattern.MatchString(class) {
+			if s[i] == '.' {
+				return Symbol{}, fmt.Errorf("%q: %w", raw, ErrUnknownSuffix)
+			}
+			return Symbol{}, fmt.Errorf("%q: %w", raw, ErrInvalid)
+		}
+		sym.Class = class
+		s = s[:i]
+	}
+	if !basePattern.MatchString(s) {
+		return Symbol{}, fmt.Errorf("%q: %w", raw, ErrInvalid)
+	}
+	sym.Base = s
+	return sym, nil
+}
+
+// parseWhole parses an index, currency pair or future, which have no class
+// or venue.
+func parseWhole(raw, base string, kind Kind) (Symbol, error) {
+	if !basePattern.MatchString(base) {
+		return Symbol{}, fmt.Errorf("%q: %w", raw, ErrInvalid)
+	}
+	return Symbol{Base: base, Kind: kind}, nil
+}
+
+// Normalize returns the canonical Yahoo form of raw.
+func Normalize(raw string) (string, error) {
+	sym, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return sym.String(), nil
+}
+
+// NormalizeAll normalizes each symbol, dropping invalid ones and duplicates
+// while keeping the original order.
+func NormalizeAll(raw []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, r := range raw {
+		s, err := Normalize(r)
+		if err != nil || seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out
+}
@@ -0,0 +1,108 @@
+package symbols
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Symbol
+	}{
+		{"AAPL", Symbol{Base: "AAPL", Kind: KindSecurity}},
+		{"BRK-B", Symbol{Base: "BRK", Class: "B", Kind: KindSecurity}},
+		{"BRK.B", Symbol{Base: "BRK", Class: "B", Kind: KindSecurity}},
+		{"BRK/B", Symbol{Base: "BRK", Class: "B", Kind: KindSecurity}},
+		{"BRK B", Symbol{Base: "BRK", Class: "B", Kind: KindSecurity}},
+		{"$brk-b", Symbol{Base: "BRK", Class: "B", Kind: KindSecurity}},
+		{"  vod.l ", Symbol{Base: "VOD", Suffix: "L", Kind: KindSecurity}},
+		{"RDS-A.L", Symbol{Base: "RDS", Class: "A", Suffix: "L", Kind: KindSecurity}},
+		{"RELIANCE.NS", Symbol{Base: "RELIANCE", Suffix: "NS", Kind: KindSecurity}},
+		{"7203.T", Symbol{Base: "7203", Suffix: "T", Kind: KindSecurity}},
+		{"M&M.NS", Symbol{Base: "M&M", Suffix: "NS", Kind: KindSecurity}},
+		// A dot before a known exchange suffix is a venue, not a class
+		{"BRK.L", Symbol{Base: "BRK", Suffix: "L", Kind: KindSecurity}},
+		{"XYZ.V", Symbol{Base: "XYZ", Suffix: "V", Kind: KindSecurity}},
+		{"^gspc", Symbol{Base: "GSPC", Kind: KindIndex}},
+		{"EURUSD=X", Symbol{Base: "EURUSD", Kind: KindCurrency}},
+		{"cl=f", Symbol{Base: "CL", Kind: KindFuture}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.raw)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.raw, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want error
+	}{
+		{"", ErrInvalid},
+		{"   ", ErrInvalid},
+		{"$", ErrInvalid},
+		{"^", ErrInvalid},
+		{"=X", ErrInvalid},
+		{"AAPL!", ErrInvalid},
+		{"-B", ErrInvalid},
+		{"BRK-", ErrInvalid},
+		{"BRK-BBB", ErrInvalid},
+		{"BRK/123", ErrInvalid},
+		{"ABCDEFGHIJKLM", ErrInvalid},
+		{"AAAAAAAAAAAAAAAAAAAAA", ErrInvalid},
+		{"VOD.XYZ", ErrUnknownSuffix},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.raw)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("Parse(%q) = %+v, %v; want %v", tt.raw, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"aapl", "AAPL"},
+		{"BRK.B", "BRK-B"},
+		{"BRK/B", "BRK-B"},
+		{"$brk-b", "BRK-B"},
+		{"$BRK.B", "BRK-B"},
+		{"vod.l", "VOD.L"},
+		{"rds.a.l", "RDS-A.L"},
+		{"^GSPC", "^GSPC"},
+		{"eurusd=x", "EURUSD=X"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q", tt.raw, got, err, tt.want)
+		}
+	}
+
+	if got, err := Normalize("AAPL!"); err == nil {
+		t.Errorf("Normalize(%q) = %q, want an error", "AAPL!", got)
+	}
+}
+
+func TestNormalizeAll(t *testing.T) {
+	got := NormalizeAll([]string{"brk.b", "AAPL", "BRK/B", "bad!", "$aapl", "VOD.L"})
+	want := []string{"BRK-B", "AAPL", "VOD.L"}
+	if len(got) != len(want) {
+		t.Fatalf("NormalizeAll = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("NormalizeAll = %v, want %v", got, want)
+		}
+	}
+}